@@ -4,19 +4,25 @@ import (
 	"bufio"
 	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/veriglob/veriglob-core/internal/cli"
 	"github.com/veriglob/veriglob-core/internal/crypto"
 	"github.com/veriglob/veriglob-core/internal/did"
 	"github.com/veriglob/veriglob-core/internal/presentation"
+	"github.com/veriglob/veriglob-core/internal/revocation"
 	"github.com/veriglob/veriglob-core/internal/storage"
+	"github.com/veriglob/veriglob-core/internal/vc"
 
+	"github.com/skip2/go-qrcode"
 	"golang.org/x/term"
 )
 
@@ -29,65 +35,105 @@ func getDefaultWalletPath() string {
 }
 
 func main() {
-	credentialFile := flag.String("credential", "", "Path to credential JSON file")
-	credentialID := flag.String("cred-id", "", "Credential ID to use from wallet")
-	walletPath := flag.String("wallet", getDefaultWalletPath(), "Path to wallet file")
-	audience := flag.String("audience", "", "Verifier DID (audience for the presentation)")
-	nonce := flag.String("nonce", "", "Challenge nonce from verifier (optional, will generate if not provided)")
-	output := flag.String("output", "", "Output file for the presentation (optional)")
-	generateNonce := flag.Bool("generate-nonce", false, "Generate and print a nonce for challenge-response")
-	flag.Parse()
+	cli.Main(run)
+}
+
+// app carries the writers the command reports to
+type app struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	a := &app{stdout: stdout, stderr: stderr}
+	flags := flag.NewFlagSet("holder", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	credentialFile := flags.String("credential", "", "Path to credential JSON file")
+	var credentialIDs listFlag
+	flags.Var(&credentialIDs, "cred-id", "Credential ID to use from wallet; repeat or comma-separate to present several")
+	var statusProofFiles listFlag
+	flags.Var(&statusProofFiles, "status-proof", "Issuer-signed status proof file to attach for offline verifiers; repeat or comma-separate for several")
+	byType := flags.String("by-type", "", "Use the newest unexpired wallet credential of this type (e.g. EducationCredential)")
+	walletPath := flags.String("wallet", getDefaultWalletPath(), "Path to wallet file")
+	audience := flags.String("audience", "", "Verifier DID (audience for the presentation)")
+	nonce := flags.String("nonce", "", "Challenge nonce from verifier (optional, will generate if not provided)")
+	output := flags.String("output", "", "Output file for the presentation (optional)")
+	showQR := flags.Bool("qr", false, "Print the presentation as a QR code instead of JSON")
+	qrPNG := flags.String("qr-png", "", "Also write the presentation QR code to this PNG file")
+	generateNonce := flags.Bool("generate-nonce", false, "Generate and print a nonce for challenge-response")
+	if err := cli.ParseFlags(flags, args); err != nil {
+		return err
+	}
 
 	// Generate nonce command
 	if *generateNonce {
 		nonce, err := presentation.GenerateNonce()
 		if err != nil {
-			log.Fatalf("Failed to generate nonce: %v", err)
+			return fmt.Errorf("failed to generate nonce: %w", err)
 		}
-		fmt.Println(nonce)
-		return
+		fmt.Fprintln(a.stdout, nonce)
+		return nil
 	}
 
-	if *credentialFile == "" && *credentialID == "" {
-		printUsage()
-		os.Exit(1)
+	if *credentialFile == "" && len(credentialIDs) == 0 && *byType == "" {
+		a.printUsage()
+		return cli.ErrFailed
 	}
 
 	var holderPub ed25519.PublicKey
 	var holderPriv ed25519.PrivateKey
 	var holderDIDStr string
-	var credToken string
-	var credID string
+	var credTokens []string
+	var credIDs []string
 
 	// Try to use wallet
-	wallet, walletErr := tryOpenWallet(*walletPath)
+	wallet, walletErr := a.tryOpenWallet(*walletPath)
 
-	if *credentialID != "" {
-		// Load credential from wallet
+	if len(credentialIDs) > 0 || *byType != "" {
+		// Load credentials from wallet
 		if walletErr != nil {
-			log.Fatalf("Cannot use -cred-id without a wallet: %v", walletErr)
+			return fmt.Errorf("cannot use -cred-id or -by-type without a wallet: %w", walletErr)
 		}
 
-		cred, err := wallet.GetCredential(*credentialID)
-		if err != nil {
-			log.Fatalf("Credential not found in wallet: %v", err)
+		var selected []*storage.StoredCredential
+		for _, id := range credentialIDs {
+			cred, err := wallet.GetCredential(id)
+			if err != nil {
+				return fmt.Errorf("credential %s not found in wallet: %w", id, err)
+			}
+			selected = append(selected, cred)
+		}
+		if *byType != "" {
+			cred := firstUnexpired(wallet.GetCredentialsByType(*byType))
+			if cred == nil {
+				return fmt.Errorf("no unexpired %s found in wallet", *byType)
+			}
+			fmt.Fprintf(a.stdout, "Using credential: %s\n", cred.ID)
+			selected = append(selected, cred)
 		}
 
-		credToken = cred.Token
-		credID = cred.ID
+		holderDIDStr = presentingDID(wallet, selected)
+		for _, cred := range selected {
+			if err := checkSubject(cred, holderDIDStr); err != nil {
+				return fmt.Errorf("cannot present credential %s: %w", cred.ID, err)
+			}
+			credTokens = append(credTokens, cred.Token)
+			credIDs = append(credIDs, cred.ID)
+		}
 
-		// Use wallet keys
-		holderPub, holderPriv, err = wallet.GetKeys()
+		// Use the wallet key for that DID
+		var err error
+		holderPub, holderPriv, err = wallet.GetKeyForDID(holderDIDStr)
 		if err != nil {
-			log.Fatalf("Failed to get keys from wallet: %v", err)
+			return fmt.Errorf("failed to get keys from wallet: %w", err)
 		}
-		holderDIDStr = wallet.GetDID()
-		fmt.Printf("Using wallet identity: %s\n", holderDIDStr)
+		fmt.Fprintf(a.stdout, "Using wallet identity: %s\n", holderDIDStr)
 	} else {
 		// Load credential from file
 		credData, err := os.ReadFile(*credentialFile)
 		if err != nil {
-			log.Fatalf("Failed to read credential file: %v", err)
+			return fmt.Errorf("failed to read credential file: %w", err)
 		}
 
 		var credential struct {
@@ -99,18 +145,18 @@ func main() {
 		}
 
 		if err := json.Unmarshal(credData, &credential); err != nil {
-			log.Fatalf("Failed to parse credential file: %v", err)
+			return fmt.Errorf("failed to parse credential file: %w", err)
 		}
 
-		credToken = credential.Token
-		credID = credential.CredentialID
+		credTokens = []string{credential.Token}
+		credIDs = []string{credential.CredentialID}
 
 		// Try to use wallet keys if available
 		if wallet != nil {
 			holderPub, holderPriv, err = wallet.GetKeys()
 			if err == nil {
 				holderDIDStr = wallet.GetDID()
-				fmt.Printf("Using wallet identity: %s\n", holderDIDStr)
+				fmt.Fprintf(a.stdout, "Using wallet identity: %s\n", holderDIDStr)
 			}
 		}
 
@@ -118,15 +164,15 @@ func main() {
 		if holderPriv == nil {
 			holderPub, holderPriv, err = crypto.GenerateEd25519Keypair()
 			if err != nil {
-				log.Fatalf("Failed to generate holder keypair: %v", err)
+				return fmt.Errorf("failed to generate holder keypair: %w", err)
 			}
 
 			holderDID, err := did.CreateDIDKey(holderPub)
 			if err != nil {
-				log.Fatalf("Failed to create holder DID: %v", err)
+				return fmt.Errorf("failed to create holder DID: %w", err)
 			}
 			holderDIDStr = holderDID.DID
-			fmt.Println("Generated temporary holder identity")
+			fmt.Fprintln(a.stdout, "Generated temporary holder identity")
 		}
 	}
 
@@ -136,7 +182,7 @@ func main() {
 		var err error
 		challengeNonce, err = presentation.GenerateNonce()
 		if err != nil {
-			log.Fatalf("Failed to generate nonce: %v", err)
+			return fmt.Errorf("failed to generate nonce: %w", err)
 		}
 	}
 
@@ -146,16 +192,38 @@ func main() {
 		aud = "did:key:verifier"
 	}
 
+	statusProofs, err := loadStatusProofs(statusProofFiles)
+	if err != nil {
+		return fmt.Errorf("failed to load status proof: %w", err)
+	}
+
 	// Create the presentation
-	vpToken, err := presentation.CreatePresentation(
+	vpToken, err := presentation.CreatePresentationWithOptions(
 		holderDIDStr,
 		holderPriv,
-		[]string{credToken},
+		credTokens,
 		aud,
 		challengeNonce,
+		presentation.CreateOptions{ValidateCredentials: true, StatusProofs: statusProofs},
 	)
 	if err != nil {
-		log.Fatalf("Failed to create presentation: %v", err)
+		return fmt.Errorf("failed to create presentation: %w", err)
+	}
+
+	// Record the presentation in the wallet's history
+	if wallet != nil {
+		vpClaims, err := presentation.VerifyPresentation(vpToken, holderPub, aud, challengeNonce)
+		if err != nil {
+			return fmt.Errorf("failed to read back presentation: %w", err)
+		}
+		record := storage.PresentationRecord{
+			ID:            vpClaims.VP.ID,
+			Audience:      aud,
+			CredentialIDs: credIDs,
+		}
+		if err := wallet.RecordPresentation(record); err != nil {
+			fmt.Fprintf(a.stdout, "Warning: failed to record presentation in wallet: %v\n", err)
+		}
 	}
 
 	// Prepare output
@@ -164,36 +232,175 @@ func main() {
 			"did":       holderDIDStr,
 			"publicKey": fmt.Sprintf("%x", holderPub),
 		},
-		"audience": aud,
-		"nonce":    challengeNonce,
-		"credentials": []string{
-			credID,
-		},
+		"audience":     aud,
+		"nonce":        challengeNonce,
+		"credentials":  credIDs,
 		"presentation": vpToken,
 	}
 
 	jsonOutput, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
-		log.Fatalf("Failed to marshal output: %v", err)
+		return fmt.Errorf("failed to marshal output: %w", err)
 	}
 
 	// Output to file or stdout
 	if *output != "" {
 		if err := os.WriteFile(*output, jsonOutput, 0644); err != nil {
-			log.Fatalf("Failed to write output file: %v", err)
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		fmt.Fprintf(a.stdout, "Presentation written to %s\n", *output)
+	} else if !*showQR {
+		fmt.Fprintln(a.stdout, string(jsonOutput))
+	}
+
+	if *showQR || *qrPNG != "" {
+		frames, err := qrFrames(vpToken)
+		if err != nil {
+			return fmt.Errorf("failed to encode QR payload: %w", err)
+		}
+		if *showQR {
+			if err := a.printQR(frames); err != nil {
+				return fmt.Errorf("failed to render QR code: %w", err)
+			}
+		}
+		if *qrPNG != "" {
+			if err := a.writeQRPNG(*qrPNG, frames); err != nil {
+				return fmt.Errorf("failed to write QR code: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// qrFrames returns the QR payloads for a presentation token: the compact
+// payload as a single code when it fits, otherwise a sequence of frames the
+// verifier scans in turn and reassembles with presentation.JoinQRFrames
+func qrFrames(vpToken string) ([]string, error) {
+	payload, err := presentation.EncodeQRPayload(vpToken)
+	if err == nil {
+		return []string{payload}, nil
+	}
+	if !errors.Is(err, presentation.ErrQRPayloadTooLarge) {
+		return nil, err
+	}
+
+	payload, err = presentation.EncodeCompact(vpToken)
+	if err != nil {
+		return nil, err
+	}
+	return presentation.SplitQRFrames(payload, presentation.DefaultQRFrameLength)
+}
+
+// printQR renders each frame as a QR code in the terminal
+func (a *app) printQR(frames []string) error {
+	for i, frame := range frames {
+		code, err := qrcode.New(frame, qrcode.Low)
+		if err != nil {
+			return err
+		}
+		if len(frames) > 1 {
+			fmt.Fprintf(a.stdout, "QR frame %d of %d\n", i+1, len(frames))
+		}
+		fmt.Fprintln(a.stdout, code.ToSmallString(false))
+	}
+	return nil
+}
+
+// writeQRPNG writes each frame as a PNG; multiple frames are numbered
+// path-1.png, path-2.png, ...
+func (a *app) writeQRPNG(path string, frames []string) error {
+	for i, frame := range frames {
+		framePath := path
+		if len(frames) > 1 {
+			ext := filepath.Ext(path)
+			framePath = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), i+1, ext)
+		}
+		if err := qrcode.WriteFile(frame, qrcode.Low, -4, framePath); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stderr, "QR code written to %s\n", framePath)
+	}
+	return nil
+}
+
+// listFlag collects the values of a repeatable flag; each may hold a comma-separated list
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			*l = append(*l, id)
+		}
+	}
+	return nil
+}
+
+// loadStatusProofs reads issuer-signed status proofs written by the issuer
+// CLI. They are checked by the verifier, not here.
+func loadStatusProofs(paths []string) ([]*revocation.StatusProof, error) {
+	var proofs []*revocation.StatusProof
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var proof revocation.StatusProof
+		if err := json.Unmarshal(data, &proof); err != nil || proof.Format != revocation.StatusProofFormat {
+			return nil, fmt.Errorf("%s: %w", path, revocation.ErrInvalidStatusProof)
+		}
+		proofs = append(proofs, &proof)
+	}
+	return proofs, nil
+}
+
+// checkSubject ensures a wallet credential was issued to the holder, since a
+// presentation signed by the wallet key only proves control of that DID
+func checkSubject(cred *storage.StoredCredential, holderDID string) error {
+	claims, err := vc.UnverifiedClaims(cred.Token)
+	if err != nil {
+		return err
+	}
+	if claims.Subject != holderDID {
+		return fmt.Errorf("issued to %s, not the wallet identity %s", claims.Subject, holderDID)
+	}
+	return nil
+}
+
+// presentingDID picks the wallet DID to present under: the one the first
+// credential was issued to if the wallet holds its key, which may have been
+// retired by a key rotation, or else the wallet's current DID
+func presentingDID(wallet *storage.Wallet, creds []*storage.StoredCredential) string {
+	if len(creds) > 0 {
+		if claims, err := vc.UnverifiedClaims(creds[0].Token); err == nil {
+			if _, _, err := wallet.GetKeyForDID(claims.Subject); err == nil {
+				return claims.Subject
+			}
+		}
+	}
+	return wallet.GetDID()
+}
+
+// firstUnexpired returns the first credential that has not expired
+func firstUnexpired(creds []storage.StoredCredential) *storage.StoredCredential {
+	now := time.Now()
+	for i := range creds {
+		if !creds[i].Expired(now) {
+			return &creds[i]
 		}
-		fmt.Printf("Presentation written to %s\n", *output)
-	} else {
-		fmt.Println(string(jsonOutput))
 	}
+	return nil
 }
 
-func tryOpenWallet(path string) (*storage.Wallet, error) {
+func (a *app) tryOpenWallet(path string) (*storage.Wallet, error) {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return nil, storage.ErrWalletNotFound
 	}
 
-	pass := readPassword("Enter wallet passphrase (or press Enter to skip): ")
+	pass := a.readPassword("Enter wallet passphrase (or press Enter to skip): ")
 	if pass == "" {
 		return nil, storage.ErrWalletNotFound
 	}
@@ -201,10 +408,10 @@ func tryOpenWallet(path string) (*storage.Wallet, error) {
 	return storage.OpenWallet(path, pass)
 }
 
-func readPassword(prompt string) string {
-	fmt.Print(prompt)
+func (a *app) readPassword(prompt string) string {
+	fmt.Fprint(a.stdout, prompt)
 	password, err := term.ReadPassword(int(syscall.Stdin))
-	fmt.Println()
+	fmt.Fprintln(a.stdout)
 	if err != nil {
 		reader := bufio.NewReader(os.Stdin)
 		line, _ := reader.ReadString('\n')
@@ -213,20 +420,25 @@ func readPassword(prompt string) string {
 	return string(password)
 }
 
-func printUsage() {
-	fmt.Println("Holder CLI - Create Verifiable Presentations")
-	fmt.Println()
-	fmt.Println("Usage:")
-	fmt.Println("  holder -credential <cred.json> -audience <verifier_did> [-nonce <challenge>]")
-	fmt.Println("  holder -cred-id <id> -audience <verifier_did> [-nonce <challenge>]")
-	fmt.Println("  holder -generate-nonce")
-	fmt.Println()
-	fmt.Println("Options:")
-	fmt.Println("  -credential    Path to credential JSON file from issuer")
-	fmt.Println("  -cred-id       Credential ID to use from wallet")
-	fmt.Println("  -wallet        Path to wallet file (default: ~/.veriglob/wallet.json)")
-	fmt.Println("  -audience      Verifier's DID (who the presentation is for)")
-	fmt.Println("  -nonce         Challenge nonce from verifier")
-	fmt.Println("  -output        Output file for presentation JSON")
-	fmt.Println("  -generate-nonce  Generate a random nonce")
+func (a *app) printUsage() {
+	fmt.Fprintln(a.stdout, "Holder CLI - Create Verifiable Presentations")
+	fmt.Fprintln(a.stdout)
+	fmt.Fprintln(a.stdout, "Usage:")
+	fmt.Fprintln(a.stdout, "  holder -credential <cred.json> -audience <verifier_did> [-nonce <challenge>]")
+	fmt.Fprintln(a.stdout, "  holder -cred-id <id>[,<id>...] -audience <verifier_did> [-nonce <challenge>]")
+	fmt.Fprintln(a.stdout, "  holder -by-type <type> -audience <verifier_did> [-nonce <challenge>]")
+	fmt.Fprintln(a.stdout, "  holder -generate-nonce")
+	fmt.Fprintln(a.stdout)
+	fmt.Fprintln(a.stdout, "Options:")
+	fmt.Fprintln(a.stdout, "  -credential    Path to credential JSON file from issuer")
+	fmt.Fprintln(a.stdout, "  -cred-id       Credential ID to use from wallet; repeat or comma-separate to bundle several")
+	fmt.Fprintln(a.stdout, "  -status-proof  Status proof file from the issuer to attach for offline verifiers; repeatable")
+	fmt.Fprintln(a.stdout, "  -by-type       Credential type to use from wallet (newest unexpired match)")
+	fmt.Fprintln(a.stdout, "  -wallet        Path to wallet file (default: ~/.veriglob/wallet.json)")
+	fmt.Fprintln(a.stdout, "  -audience      Verifier's DID (who the presentation is for)")
+	fmt.Fprintln(a.stdout, "  -nonce         Challenge nonce from verifier")
+	fmt.Fprintln(a.stdout, "  -output        Output file for presentation JSON")
+	fmt.Fprintln(a.stdout, "  -qr            Print the presentation as a terminal QR code instead of JSON")
+	fmt.Fprintln(a.stdout, "  -qr-png        Write the presentation QR code to a PNG file")
+	fmt.Fprintln(a.stdout, "  -generate-nonce  Generate a random nonce")
 }
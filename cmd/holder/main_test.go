@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/veriglob/veriglob-core/internal/cli"
+	"github.com/veriglob/veriglob-core/pkg/veriglob"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	noWallet := filepath.Join(dir, "wallet.json")
+
+	h := veriglob.NewTestHarness()
+	token, err := h.IssueIdentity(veriglob.IdentitySubject{GivenName: "Alice", FamilyName: "Doe", DateOfBirth: "1990-01-01"})
+	if err != nil {
+		t.Fatalf("IssueIdentity failed: %v", err)
+	}
+	data, _ := json.Marshal(veriglob.CredentialFile{Issuer: veriglob.PartyKey{DID: h.Issuer.DID}, Token: token})
+	credPath := filepath.Join(dir, "credential.json")
+	if err := os.WriteFile(credPath, data, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{"no credential", []string{"-wallet", noWallet}, 1, "Usage:", ""},
+		{"generate nonce", []string{"-generate-nonce"}, 0, "", ""},
+		{"present", []string{"-wallet", noWallet, "-credential", credPath, "-audience", h.Verifier.DID}, 0, `"presentation"`, ""},
+		{"missing credential", []string{"-wallet", noWallet, "-credential", filepath.Join(dir, "missing.json")}, 1, "", "Error: failed to read credential file"},
+		{"cred-id without wallet", []string{"-wallet", noWallet, "-cred-id", "urn:uuid:1"}, 1, "", "Error: cannot use -cred-id or -by-type without a wallet"},
+		{"missing status proof", []string{"-wallet", noWallet, "-credential", credPath, "-status-proof", filepath.Join(dir, "missing.json")}, 1, "", "Error: failed to load status proof"},
+		{"unknown flag", []string{"-bogus"}, 2, "", "flag provided but not defined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := cli.Run(run, tt.args, &stdout, &stderr)
+			if code != tt.code {
+				t.Errorf("Expected exit code %d, got %d (stdout %q, stderr %q)", tt.code, code, stdout.String(), stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.stdout) {
+				t.Errorf("Expected stdout to contain %q, got %q", tt.stdout, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("Expected stderr to contain %q, got %q", tt.stderr, stderr.String())
+			}
+		})
+	}
+}
@@ -1,151 +1,231 @@
 package main
 
 import (
+	"bufio"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
+	"strings"
+	"syscall"
 
+	"github.com/veriglob/veriglob-core/internal/cli"
 	"github.com/veriglob/veriglob-core/internal/crypto"
 	"github.com/veriglob/veriglob-core/internal/did"
+	"github.com/veriglob/veriglob-core/internal/resolver"
 	"github.com/veriglob/veriglob-core/internal/revocation"
+	"github.com/veriglob/veriglob-core/internal/storage"
 	"github.com/veriglob/veriglob-core/internal/vc"
+
+	"golang.org/x/term"
 )
 
 const defaultRegistryPath = "revocation_registry.json"
 
+// credentialTypes maps the -type flag values to credential types
+var credentialTypes = map[string]string{
+	"identity":   vc.CredentialTypeIdentity,
+	"education":  vc.CredentialTypeEducation,
+	"employment": vc.CredentialTypeEmployment,
+	"membership": vc.CredentialTypeMembership,
+}
+
 func main() {
-	credType := flag.String("type", "identity", "Credential type: identity, education, employment, membership")
-	output := flag.String("output", "", "Output file for the credential (optional)")
-	registryPath := flag.String("registry", defaultRegistryPath, "Path to revocation registry file")
-	revokeID := flag.String("revoke", "", "Credential ID to revoke (instead of issuing)")
-	revokeReason := flag.String("reason", "", "Reason for revocation")
-	listRevoked := flag.Bool("list", false, "List all credentials in registry")
-	flag.Parse()
+	cli.Main(run)
+}
+
+// app carries the writers the command reports to
+type app struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	a := &app{stdout: stdout, stderr: stderr}
+	flags := flag.NewFlagSet("issuer", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	credType := flags.String("type", "identity", "Credential type: identity, education, employment, membership")
+	output := flags.String("output", "", "Output file for the credential (optional)")
+	registryPath := flags.String("registry", defaultRegistryPath, "Path to revocation registry file")
+	revokeID := flags.String("revoke", "", "Credential ID to revoke (instead of issuing)")
+	revokeReason := flags.String("reason", "", "Reason for revocation or suspension")
+	suspendID := flags.String("suspend", "", "Credential ID to suspend (reversible)")
+	reactivateID := flags.String("reactivate", "", "Credential ID to reactivate after suspension")
+	listRevoked := flags.Bool("list", false, "List all credentials in registry")
+	subjectFile := flags.String("subject", "", "JSON file with the credential subject claims (default: sample data)")
+	walletPath := flags.String("wallet", "", "Wallet holding the issuer identity (default: an ephemeral key)")
+	exportSigned := flags.String("export-signed", "", "Write the registry, signed with the -wallet issuer key, to this file")
+	statusProofID := flags.String("status-proof", "", "Credential ID to sign a status proof for with the -wallet issuer key, for offline verifiers")
+	dryRun := flags.Bool("dry-run", false, "Validate the credential and print what would be issued, without writing it or touching the registry")
+	if err := cli.ParseFlags(flags, args); err != nil {
+		return err
+	}
+
+	// A dry run only checks an issuance, so it never loads or saves the registry
+	if *dryRun {
+		if *revokeID != "" || *suspendID != "" || *reactivateID != "" || *listRevoked || *exportSigned != "" || *statusProofID != "" {
+			return errors.New("-dry-run only applies to issuing a credential")
+		}
+		if *output != "" {
+			return errors.New("-dry-run prints to stdout and cannot be combined with -output")
+		}
+	}
 
 	// Load or create revocation registry
-	registry, err := revocation.NewRegistryWithFile(*registryPath)
-	if err != nil {
-		log.Fatalf("Failed to load revocation registry: %v", err)
+	var registry *revocation.Registry
+	if !*dryRun {
+		var err error
+		if registry, err = revocation.NewRegistryWithFile(*registryPath); err != nil {
+			return fmt.Errorf("failed to load revocation registry: %w", err)
+		}
 	}
 
 	// Handle revocation command
 	if *revokeID != "" {
 		if err := registry.Revoke(*revokeID, *revokeReason); err != nil {
-			log.Fatalf("Failed to revoke credential: %v", err)
+			return fmt.Errorf("failed to revoke credential: %w", err)
 		}
-		fmt.Printf("Credential %s has been revoked\n", *revokeID)
-		return
+		fmt.Fprintf(a.stdout, "Credential %s has been revoked\n", *revokeID)
+		return nil
+	}
+
+	// Handle suspension commands
+	if *suspendID != "" {
+		if err := registry.Suspend(*suspendID, *revokeReason); err != nil {
+			return fmt.Errorf("failed to suspend credential: %w", err)
+		}
+		fmt.Fprintf(a.stdout, "Credential %s has been suspended\n", *suspendID)
+		return nil
+	}
+
+	if *reactivateID != "" {
+		if err := registry.Reactivate(*reactivateID); err != nil {
+			return fmt.Errorf("failed to reactivate credential: %w", err)
+		}
+		fmt.Fprintf(a.stdout, "Credential %s has been reactivated\n", *reactivateID)
+		return nil
 	}
 
 	// Handle list command
 	if *listRevoked {
 		data, err := registry.Export()
 		if err != nil {
-			log.Fatalf("Failed to export registry: %v", err)
+			return fmt.Errorf("failed to export registry: %w", err)
 		}
-		fmt.Println(string(data))
-		return
+		fmt.Fprintln(a.stdout, string(data))
+		return nil
 	}
 
-	// Generate issuer keypair and DID
-	issuerPub, issuerPriv, err := crypto.GenerateEd25519Keypair()
-	if err != nil {
-		log.Fatalf("Failed to generate issuer keypair: %v", err)
-	}
-
-	issuerDID, err := did.CreateDIDKey(issuerPub)
-	if err != nil {
-		log.Fatalf("Failed to create issuer DID: %v", err)
+	// Handle signed export; an ephemeral key would make the signature meaningless
+	if *exportSigned != "" {
+		if *walletPath == "" {
+			return errors.New("-export-signed requires -wallet with the issuer identity")
+		}
+		_, priv, _, err := a.loadIssuerIdentity(*walletPath)
+		if err != nil {
+			return fmt.Errorf("failed to load issuer identity: %w", err)
+		}
+		data, err := registry.ExportSigned(priv)
+		if err != nil {
+			return fmt.Errorf("failed to sign registry: %w", err)
+		}
+		if err := os.WriteFile(*exportSigned, data, 0644); err != nil {
+			return fmt.Errorf("failed to write signed registry: %w", err)
+		}
+		fmt.Fprintf(a.stdout, "Signed registry written to %s\n", *exportSigned)
+		return nil
 	}
 
-	// Generate subject keypair and DID
-	subjectPub, _, err := crypto.GenerateEd25519Keypair()
-	if err != nil {
-		log.Fatalf("Failed to generate subject keypair: %v", err)
+	// Handle status proofs, which the holder attaches to presentations
+	if *statusProofID != "" {
+		if *walletPath == "" {
+			return errors.New("-status-proof requires -wallet with the issuer identity")
+		}
+		_, priv, _, err := a.loadIssuerIdentity(*walletPath)
+		if err != nil {
+			return fmt.Errorf("failed to load issuer identity: %w", err)
+		}
+		proof, err := registry.StatusProof(*statusProofID, priv)
+		if err != nil {
+			return fmt.Errorf("failed to sign status proof: %w", err)
+		}
+		data, err := json.MarshalIndent(proof, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode status proof: %w", err)
+		}
+		if *output == "" {
+			fmt.Fprintln(a.stdout, string(data))
+			return nil
+		}
+		if err := os.WriteFile(*output, data, 0644); err != nil {
+			return fmt.Errorf("failed to write status proof: %w", err)
+		}
+		fmt.Fprintf(a.stdout, "Status proof written to %s\n", *output)
+		return nil
 	}
 
-	subjectDID, err := did.CreateDIDKey(subjectPub)
+	// Load the issuer identity from the wallet, or generate an ephemeral one
+	issuerPub, issuerPriv, issuerDID, err := a.loadIssuerIdentity(*walletPath)
 	if err != nil {
-		log.Fatalf("Failed to create subject DID: %v", err)
+		return fmt.Errorf("failed to load issuer identity: %w", err)
 	}
 
 	// Generate credential ID for revocation tracking
 	credentialID, err := revocation.GenerateCredentialID()
 	if err != nil {
-		log.Fatalf("Failed to generate credential ID: %v", err)
+		return fmt.Errorf("failed to generate credential ID: %w", err)
 	}
 
-	// Create credential subject based on type
+	typeName, ok := credentialTypes[*credType]
+	if !ok {
+		return fmt.Errorf("unknown credential type: %s. Use: identity, education, employment, membership", *credType)
+	}
+
+	// Load the subject claims, or fall back to sample data
 	var subject vc.CredentialSubject
-	switch *credType {
-	case "identity":
-		subject = vc.IdentitySubject{
-			ID:            subjectDID.DID,
-			GivenName:     "John",
-			FamilyName:    "Doe",
-			DateOfBirth:   "1990-01-15",
-			Nationality:   "US",
-			DocumentType:  "passport",
-			DocumentID:    "AB1234567",
-			VerifiedAt:    "2024-01-15T10:30:00Z",
-			VerifiedLevel: "high",
-		}
-	case "education":
-		subject = vc.EducationSubject{
-			ID:              subjectDID.DID,
-			InstitutionName: "University of Technology",
-			Degree:          "Bachelor of Science",
-			FieldOfStudy:    "Computer Science",
-			GraduationDate:  "2020-05-15",
-			Grade:           "3.8 GPA",
-		}
-	case "employment":
-		subject = vc.EmploymentSubject{
-			ID:              subjectDID.DID,
-			EmployerName:    "Tech Corp Inc.",
-			JobTitle:        "Software Engineer",
-			Department:      "Engineering",
-			StartDate:       "2021-06-01",
-			EmploymentType:  "full-time",
-			CurrentEmployee: true,
+	if *subjectFile != "" {
+		subject, err = loadSubject(*subjectFile, typeName)
+		if err != nil {
+			return fmt.Errorf("failed to load subject: %w", err)
 		}
-	case "membership":
-		subject = vc.MembershipSubject{
-			ID:               subjectDID.DID,
-			OrganizationName: "Professional Developers Association",
-			MembershipID:     "PDA-2024-001234",
-			MembershipType:   "premium",
-			Role:             "member",
-			AccessLevel:      "full",
-			StartDate:        "2024-01-01",
-			ActiveMember:     true,
+	} else {
+		fmt.Fprintln(a.stderr, "WARNING: no -subject file given; issuing a credential with sample data for demonstration only")
+		subjectDID, err := newSubjectDID()
+		if err != nil {
+			return fmt.Errorf("failed to create subject DID: %w", err)
 		}
-	default:
-		log.Fatalf("Unknown credential type: %s. Use: identity, education, employment, membership", *credType)
+		subject = sampleSubject(typeName, subjectDID)
+	}
+	subjectDID := subject.GetID()
+
+	if *dryRun {
+		return a.dryRun(issuerDID, subjectDID, issuerPub, issuerPriv, subject, credentialID)
 	}
 
 	// Issue the credential with ID
-	token, err := vc.IssueVCWithID(issuerDID.DID, subjectDID.DID, issuerPriv, subject, credentialID)
+	token, err := vc.IssueVCWithID(issuerDID, subjectDID, issuerPriv, subject, credentialID)
 	if err != nil {
-		log.Fatalf("Failed to issue credential: %v", err)
+		return fmt.Errorf("failed to issue credential: %w", err)
 	}
 
 	// Register credential in revocation registry
-	if err := registry.Register(credentialID, issuerDID.DID, subjectDID.DID); err != nil {
-		log.Fatalf("Failed to register credential: %v", err)
+	if err := registry.Register(credentialID, issuerDID, subjectDID); err != nil {
+		return fmt.Errorf("failed to register credential: %w", err)
 	}
 
 	// Prepare output
 	result := map[string]interface{}{
 		"credentialId": credentialID,
 		"issuer": map[string]string{
-			"did":       issuerDID.DID,
+			"did":       issuerDID,
 			"publicKey": fmt.Sprintf("%x", issuerPub),
 		},
 		"subject": map[string]string{
-			"did": subjectDID.DID,
+			"did": subjectDID,
 		},
 		"credentialType": subject.CredentialType(),
 		"token":          token,
@@ -153,16 +233,187 @@ func main() {
 
 	jsonOutput, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
-		log.Fatalf("Failed to marshal output: %v", err)
+		return fmt.Errorf("failed to marshal output: %w", err)
 	}
 
 	// Output to file or stdout
 	if *output != "" {
 		if err := os.WriteFile(*output, jsonOutput, 0644); err != nil {
-			log.Fatalf("Failed to write output file: %v", err)
+			return fmt.Errorf("failed to write output file: %w", err)
 		}
-		fmt.Printf("Credential written to %s\n", *output)
+		fmt.Fprintf(a.stdout, "Credential written to %s\n", *output)
 	} else {
-		fmt.Println(string(jsonOutput))
+		fmt.Fprintln(a.stdout, string(jsonOutput))
+	}
+	return nil
+}
+
+// dryRun issues the credential in memory, so every check a real issuance
+// makes runs, and prints its claims in place of the token. The token is
+// discarded and the credential is not registered. A credential that would
+// not issue returns an error, so the command exits non-zero.
+func (a *app) dryRun(issuerDID, subjectDID string, issuerPub ed25519.PublicKey, issuerPriv ed25519.PrivateKey, subject vc.CredentialSubject, credentialID string) error {
+	token, err := vc.IssueVCWithID(issuerDID, subjectDID, issuerPriv, subject, credentialID)
+	if err != nil {
+		return fmt.Errorf("credential would not be issued: %w", err)
+	}
+	claims, err := vc.VerifyVC(token, issuerPub)
+	if err != nil {
+		return fmt.Errorf("credential would not verify: %w", err)
+	}
+
+	result := map[string]interface{}{
+		"dryRun":       true,
+		"credentialId": credentialID,
+		"issuer": map[string]string{
+			"did":       issuerDID,
+			"publicKey": fmt.Sprintf("%x", issuerPub),
+		},
+		"subject": map[string]string{
+			"did": subjectDID,
+		},
+		"credentialType": subject.CredentialType(),
+		"claims":         claims,
+	}
+	jsonOutput, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+	fmt.Fprintln(a.stdout, string(jsonOutput))
+	fmt.Fprintln(a.stderr, "Dry run: the credential is valid; nothing was written and the registry was not changed")
+	return nil
+}
+
+// loadIssuerIdentity returns the keys and DID of the wallet's default
+// identity, so credentials are issued under a stable DID. Without a wallet
+// path, a new key is generated for this run only.
+func (a *app) loadIssuerIdentity(path string) (ed25519.PublicKey, ed25519.PrivateKey, string, error) {
+	if path == "" {
+		fmt.Fprintln(a.stderr, "WARNING: no -wallet given; issuing under an ephemeral DID that verifiers cannot trust across runs")
+
+		pub, priv, err := crypto.GenerateEd25519Keypair()
+		if err != nil {
+			return nil, nil, "", err
+		}
+		issuerDID, err := did.CreateDIDKey(pub)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		return pub, priv, issuerDID.DID, nil
+	}
+
+	pass := a.readPassword("Enter wallet passphrase: ")
+	wallet, err := storage.OpenWallet(path, pass)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	pub, priv, err := wallet.GetKeys()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return pub, priv, wallet.GetDID(), nil
+}
+
+// readPassword prompts on stderr so stdout carries only the credential
+func (a *app) readPassword(prompt string) string {
+	fmt.Fprint(a.stderr, prompt)
+	password, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Fprintln(a.stderr)
+	if err != nil {
+		reader := bufio.NewReader(os.Stdin)
+		line, _ := reader.ReadString('\n')
+		return strings.TrimSpace(line)
+	}
+	return string(password)
+}
+
+// loadSubject reads the subject claims of a credential type from a JSON file.
+// Unknown fields and missing required fields are rejected, and so is a
+// missing or malformed "id": the credential must name its holder's DID.
+func loadSubject(path, typeName string) (vc.CredentialSubject, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if fields == nil {
+		return nil, fmt.Errorf("%s: subject must be a JSON object", path)
+	}
+
+	id, _ := fields["id"].(string)
+	if id == "" {
+		return nil, fmt.Errorf("%s: missing subject \"id\": the holder's DID is required", path)
+	}
+	if err := resolver.ValidateDID(id); err != nil {
+		return nil, fmt.Errorf("%s: subject id %q: %w", path, id, err)
+	}
+
+	return vc.NewSubjectFromMap(typeName, fields)
+}
+
+// newSubjectDID generates a did:key for a subject without one
+func newSubjectDID() (string, error) {
+	subjectPub, _, err := crypto.GenerateEd25519Keypair()
+	if err != nil {
+		return "", err
+	}
+
+	subjectDID, err := did.CreateDIDKey(subjectPub)
+	if err != nil {
+		return "", err
+	}
+	return subjectDID.DID, nil
+}
+
+// sampleSubject returns demonstration claims for a credential type
+func sampleSubject(typeName, subjectDID string) vc.CredentialSubject {
+	switch typeName {
+	case vc.CredentialTypeEducation:
+		return vc.EducationSubject{
+			ID:              subjectDID,
+			InstitutionName: "University of Technology",
+			Degree:          "Bachelor of Science",
+			FieldOfStudy:    "Computer Science",
+			GraduationDate:  "2020-05-15",
+			Grade:           "3.8 GPA",
+		}
+	case vc.CredentialTypeEmployment:
+		return vc.EmploymentSubject{
+			ID:              subjectDID,
+			EmployerName:    "Tech Corp Inc.",
+			JobTitle:        "Software Engineer",
+			Department:      "Engineering",
+			StartDate:       "2021-06-01",
+			EmploymentType:  "full-time",
+			CurrentEmployee: true,
+		}
+	case vc.CredentialTypeMembership:
+		return vc.MembershipSubject{
+			ID:               subjectDID,
+			OrganizationName: "Professional Developers Association",
+			MembershipID:     "PDA-2024-001234",
+			MembershipType:   "premium",
+			Role:             "member",
+			AccessLevel:      "full",
+			StartDate:        "2024-01-01",
+			ActiveMember:     true,
+		}
+	default:
+		return vc.IdentitySubject{
+			ID:            subjectDID,
+			GivenName:     "John",
+			FamilyName:    "Doe",
+			DateOfBirth:   "1990-01-15",
+			Nationality:   "US",
+			DocumentType:  "passport",
+			DocumentID:    "AB1234567",
+			VerifiedAt:    "2024-01-15T10:30:00Z",
+			VerifiedLevel: "high",
+		}
 	}
 }
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/veriglob/veriglob-core/internal/cli"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	registryPath := filepath.Join(dir, "registry.json")
+	credPath := filepath.Join(dir, "credential.json")
+
+	// Issue a credential first so the revocation cases have an ID to act on
+	var stdout, stderr bytes.Buffer
+	if code := cli.Run(run, []string{"-registry", registryPath, "-output", credPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0 issuing, got %d: %s", code, stderr.String())
+	}
+	data, err := os.ReadFile(credPath)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	var cred struct {
+		CredentialID string `json:"credentialId"`
+	}
+	if err := json.Unmarshal(data, &cred); err != nil || cred.CredentialID == "" {
+		t.Fatalf("Expected a credential ID in the output, got %s", data)
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{"issue to stdout", []string{"-registry", registryPath, "-type", "education"}, 0, `"token"`, "sample data"},
+		{"unknown type", []string{"-registry", registryPath, "-type", "bogus"}, 1, "", "Error: unknown credential type: bogus"},
+		{"missing subject", []string{"-registry", registryPath, "-subject", filepath.Join(dir, "missing.json")}, 1, "", "Error: failed to load subject"},
+		{"suspend", []string{"-registry", registryPath, "-suspend", cred.CredentialID}, 0, "has been suspended", ""},
+		{"reactivate", []string{"-registry", registryPath, "-reactivate", cred.CredentialID}, 0, "has been reactivated", ""},
+		{"revoke", []string{"-registry", registryPath, "-revoke", cred.CredentialID, "-reason", "test"}, 0, "has been revoked", ""},
+		{"revoke unknown", []string{"-registry", registryPath, "-revoke", "urn:uuid:unknown"}, 1, "", "Error: failed to revoke credential"},
+		{"list", []string{"-registry", registryPath, "-list"}, 0, cred.CredentialID, ""},
+		{"export without wallet", []string{"-registry", registryPath, "-export-signed", filepath.Join(dir, "signed.json")}, 1, "", "Error: -export-signed requires -wallet"},
+		{"unknown flag", []string{"-bogus"}, 2, "", "flag provided but not defined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := cli.Run(run, tt.args, &stdout, &stderr)
+			if code != tt.code {
+				t.Errorf("Expected exit code %d, got %d (stdout %q, stderr %q)", tt.code, code, stdout.String(), stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.stdout) {
+				t.Errorf("Expected stdout to contain %q, got %q", tt.stdout, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("Expected stderr to contain %q, got %q", tt.stderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	dir := t.TempDir()
+	registryPath := filepath.Join(dir, "registry.json")
+	valid := filepath.Join(dir, "valid.json")
+	os.WriteFile(valid, []byte(`{"id":"did:key:z6MkEmployee","employerName":"Tech Corp Inc.","jobTitle":"Software Engineer","startDate":"2021-06-01"}`), 0644)
+	missingField := filepath.Join(dir, "missing-field.json")
+	os.WriteFile(missingField, []byte(`{"id":"did:key:z6MkEmployee","jobTitle":"Software Engineer"}`), 0644)
+	unknownField := filepath.Join(dir, "unknown-field.json")
+	os.WriteFile(unknownField, []byte(`{"id":"did:key:z6MkEmployee","employerName":"Tech Corp Inc.","salary":100000}`), 0644)
+	missingID := filepath.Join(dir, "missing-id.json")
+	os.WriteFile(missingID, []byte(`{"employerName":"Tech Corp Inc.","jobTitle":"Software Engineer","startDate":"2021-06-01"}`), 0644)
+	invalidID := filepath.Join(dir, "invalid-id.json")
+	os.WriteFile(invalidID, []byte(`{"id":"employee-42","employerName":"Tech Corp Inc.","jobTitle":"Software Engineer","startDate":"2021-06-01"}`), 0644)
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{"valid subject", []string{"-registry", registryPath, "-dry-run", "-type", "employment", "-subject", valid}, 0, `"dryRun": true`, "nothing was written"},
+		{"sample data", []string{"-registry", registryPath, "-dry-run"}, 0, `"claims"`, "sample data"},
+		{"missing required field", []string{"-registry", registryPath, "-dry-run", "-type", "employment", "-subject", missingField}, 1, "", "Error: failed to load subject"},
+		{"unknown field", []string{"-registry", registryPath, "-dry-run", "-type", "employment", "-subject", unknownField}, 1, "", "Error: failed to load subject"},
+		{"missing id", []string{"-registry", registryPath, "-dry-run", "-type", "employment", "-subject", missingID}, 1, "", `missing subject "id"`},
+		{"invalid id", []string{"-registry", registryPath, "-dry-run", "-type", "employment", "-subject", invalidID}, 1, "", "invalid DID"},
+		{"unknown type", []string{"-registry", registryPath, "-dry-run", "-type", "bogus"}, 1, "", "Error: unknown credential type"},
+		{"with revoke", []string{"-registry", registryPath, "-dry-run", "-revoke", "urn:uuid:x"}, 1, "", "Error: -dry-run only applies to issuing"},
+		{"with output", []string{"-registry", registryPath, "-dry-run", "-output", filepath.Join(dir, "cred.json")}, 1, "", "Error: -dry-run prints to stdout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := cli.Run(run, tt.args, &stdout, &stderr)
+			if code != tt.code {
+				t.Errorf("Expected exit code %d, got %d (stdout %q, stderr %q)", tt.code, code, stdout.String(), stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.stdout) {
+				t.Errorf("Expected stdout to contain %q, got %q", tt.stdout, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("Expected stderr to contain %q, got %q", tt.stderr, stderr.String())
+			}
+			if strings.Contains(stdout.String(), `"token"`) {
+				t.Errorf("Expected no token in a dry run, got %q", stdout.String())
+			}
+		})
+	}
+
+	if _, err := os.Stat(registryPath); !os.IsNotExist(err) {
+		t.Errorf("Expected a dry run not to write the registry, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cred.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected a dry run not to write the credential, got %v", err)
+	}
+}
@@ -1,322 +1,362 @@
 package main
 
 import (
-	"crypto/ed25519"
-	"encoding/hex"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"strings"
 
+	"github.com/veriglob/veriglob-core/internal/cli"
+	"github.com/veriglob/veriglob-core/internal/httpclient"
 	"github.com/veriglob/veriglob-core/internal/presentation"
-	"github.com/veriglob/veriglob-core/internal/resolver"
 	"github.com/veriglob/veriglob-core/internal/revocation"
 	"github.com/veriglob/veriglob-core/internal/vc"
+	"github.com/veriglob/veriglob-core/pkg/veriglob"
 )
 
 const defaultRegistryPath = "revocation_registry.json"
 
 func main() {
+	cli.Main(run)
+}
+
+// app carries the writers the command reports to
+type app struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	a := &app{stdout: stdout, stderr: stderr}
+	flags := flag.NewFlagSet("verifier", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
 	// Credential verification flags
-	tokenFlag := flag.String("token", "", "PASETO token to verify")
-	publicKeyFlag := flag.String("pubkey", "", "Issuer's public key (hex encoded)")
-	issuerDID := flag.String("issuer", "", "Issuer's DID (will auto-resolve public key)")
-	inputFile := flag.String("input", "", "Input file containing credential JSON (from issuer)")
-	registryPath := flag.String("registry", defaultRegistryPath, "Path to revocation registry file")
-	skipRevocation := flag.Bool("skip-revocation", false, "Skip revocation check")
+	tokenFlag := flags.String("token", "", "PASETO token to verify")
+	publicKeyFlag := flags.String("pubkey", "", "Issuer's public key (hex encoded)")
+	issuerDID := flags.String("issuer", "", "Issuer's DID (will auto-resolve public key)")
+	inputFile := flags.String("input", "", "Input file containing credential JSON (from issuer)")
+	registryPath := flags.String("registry", defaultRegistryPath, "Path to revocation registry file")
+	skipRevocation := flags.Bool("skip-revocation", false, "Skip revocation check")
+	verificationMethod := flags.String("verification-method", "", "Pin verification to a specific verification method ID (e.g. did:key:z...#key-1)")
+	var trusted listFlag
+	flags.Var(&trusted, "trust", "Only accept credentials from this issuer DID, optionally followed by the credential types it may issue (e.g. \"did:web:uni.example EducationCredential\"); repeatable")
+	trustFile := flags.String("trust-file", "", "File of trusted issuer DIDs, one per line, each optionally followed by the credential types it may issue")
 
 	// Presentation verification flags
-	presentationFile := flag.String("presentation", "", "Input file containing presentation JSON (from holder)")
-	expectedNonce := flag.String("nonce", "", "Expected nonce for presentation verification")
-	expectedAudience := flag.String("audience", "", "Expected audience (verifier DID) for presentation")
+	presentationFile := flags.String("presentation", "", "Input file containing presentation JSON (from holder)")
+	expectedNonce := flags.String("nonce", "", "Expected nonce for presentation verification")
+	expectedAudience := flags.String("audience", "", "Expected audience (verifier DID) for presentation")
+	requireStatusProof := flags.Bool("require-status-proof", false, "Reject credentials the presentation carries no issuer-signed status proof for")
+	statusProofMaxAge := flags.Duration("status-proof-max-age", revocation.DefaultStatusProofMaxAge, "Oldest status proof accepted")
+	fetchReferences := flags.Bool("fetch-references", false, "Download credentials the presentation references by URL (over HTTPS)")
+
+	if err := cli.ParseFlags(flags, args); err != nil {
+		return err
+	}
 
-	flag.Parse()
+	trustList, err := loadTrustList(trusted, *trustFile)
+	if err != nil {
+		return err
+	}
 
 	// Handle presentation verification
 	if *presentationFile != "" {
-		verifyPresentation(*presentationFile, *expectedNonce, *expectedAudience, *registryPath, *skipRevocation)
-		return
+		opts := presentation.CredentialCheckOptions{
+			RequireHolderBinding: true,
+			RequireStatusProof:   *requireStatusProof,
+			StatusProofMaxAge:    *statusProofMaxAge,
+			TrustedIssuers:       trustList,
+		}
+		if *fetchReferences {
+			opts.FetchCredential = presentation.NewHTTPCredentialFetcher(context.Background(), httpclient.Options{})
+		}
+		return a.verifyPresentation(*presentationFile, *expectedNonce, *expectedAudience, *registryPath, *skipRevocation, opts)
 	}
 
 	// Handle credential verification
-	verifyCredential(*inputFile, *tokenFlag, *publicKeyFlag, *issuerDID, *registryPath, *skipRevocation)
+	opts := veriglob.CredentialFileOptions{VerificationMethod: *verificationMethod, TrustedIssuers: trustList}
+	return a.verifyCredential(*inputFile, *tokenFlag, *publicKeyFlag, *issuerDID, *registryPath, *skipRevocation, opts)
 }
 
-func verifyPresentation(presentationFile, expectedNonce, expectedAudience, registryPath string, skipRevocation bool) {
-	data, err := os.ReadFile(presentationFile)
-	if err != nil {
-		log.Fatalf("Failed to read presentation file: %v", err)
-	}
+// listFlag collects the values of a repeatable flag
+type listFlag []string
 
-	var pres struct {
-		Holder struct {
-			DID       string `json:"did"`
-			PublicKey string `json:"publicKey"`
-		} `json:"holder"`
-		Audience     string `json:"audience"`
-		Nonce        string `json:"nonce"`
-		Presentation string `json:"presentation"`
-	}
+func (l *listFlag) String() string {
+	return strings.Join(*l, ", ")
+}
 
-	if err := json.Unmarshal(data, &pres); err != nil {
-		log.Fatalf("Failed to parse presentation file: %v", err)
-	}
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
 
-	// Try to resolve holder public key from DID first, fall back to hex-encoded key
-	var holderPubKey ed25519.PublicKey
-	if pres.Holder.DID != "" {
-		resolved, err := resolver.ResolveDID(pres.Holder.DID)
-		if err == nil {
-			holderPubKey = resolved
-			fmt.Printf("🔑 Resolved holder public key from DID\n")
-		}
+// loadTrustList builds the issuer allowlist from -trust values and a
+// -trust-file, each in the trust list line format. Without either, every
+// issuer with a valid signature is accepted and nil is returned.
+func loadTrustList(trusted []string, path string) (*veriglob.TrustList, error) {
+	if len(trusted) == 0 && path == "" {
+		return nil, nil
 	}
 
-	// Fall back to hex-encoded public key if DID resolution failed
-	if holderPubKey == nil && pres.Holder.PublicKey != "" {
-		holderPubBytes, err := hex.DecodeString(pres.Holder.PublicKey)
+	lines := strings.Join(trusted, "\n")
+	if path != "" {
+		data, err := os.ReadFile(path)
 		if err != nil {
-			log.Fatalf("Failed to decode holder public key: %v", err)
+			return nil, fmt.Errorf("failed to read trust file: %w", err)
 		}
-		holderPubKey = ed25519.PublicKey(holderPubBytes)
+		lines += "\n" + string(data)
 	}
-
-	if holderPubKey == nil {
-		log.Fatalf("Could not determine holder public key")
+	list, err := veriglob.ParseTrustList([]byte(lines))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse trusted issuers: %w", err)
 	}
+	return list, nil
+}
 
-	// Use file values if not overridden
-	if expectedNonce == "" {
-		expectedNonce = pres.Nonce
+func (a *app) verifyPresentation(presentationFile, expectedNonce, expectedAudience, registryPath string, skipRevocation bool, opts presentation.CredentialCheckOptions) error {
+	data, err := os.ReadFile(presentationFile)
+	if err != nil {
+		return fmt.Errorf("failed to read presentation file: %w", err)
 	}
-	if expectedAudience == "" {
-		expectedAudience = pres.Audience
+
+	if !skipRevocation {
+		registry, err := revocation.NewRegistryWithFile(registryPath)
+		if err != nil {
+			fmt.Fprintf(a.stdout, "⚠️  Warning: Could not load revocation registry: %v\n", err)
+		} else {
+			opts.Status = registry
+		}
 	}
 
-	// Verify the presentation
-	vpClaims, err := presentation.VerifyPresentation(pres.Presentation, holderPubKey, expectedAudience, expectedNonce)
+	// Verify the presentation and every embedded credential; the audience
+	// and nonce default to the values in the file
+	vpClaims, results, err := veriglob.VerifyPresentationFile(data, veriglob.PresentationFileOptions{
+		ExpectedAudience: expectedAudience,
+		ExpectedNonce:    expectedNonce,
+		Credentials:      opts,
+	})
 	if err != nil {
-		fmt.Println("❌ PRESENTATION VERIFICATION FAILED")
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintln(a.stdout, "❌ PRESENTATION VERIFICATION FAILED")
+		fmt.Fprintf(a.stdout, "Error: %v\n", err)
+		return cli.ErrFailed
 	}
 
-	fmt.Println("✅ PRESENTATION VERIFIED")
-	fmt.Println(strings.Repeat("─", 50))
-	fmt.Printf("Presentation ID: %s\n", vpClaims.VP.ID)
-	fmt.Printf("Holder:          %s\n", vpClaims.VP.Holder)
-	fmt.Printf("Audience:        %s\n", vpClaims.Audience)
-	fmt.Printf("Nonce:           %s\n", vpClaims.Nonce)
-	fmt.Printf("Issued At:       %s\n", vpClaims.IssuedAt.Format("2006-01-02 15:04:05 UTC"))
-	fmt.Printf("Expires At:      %s\n", vpClaims.ExpiresAt.Format("2006-01-02 15:04:05 UTC"))
-	fmt.Printf("Credentials:     %d\n", len(vpClaims.VP.VerifiableCredential))
+	fmt.Fprintln(a.stdout, "✅ PRESENTATION VERIFIED")
+	fmt.Fprintln(a.stdout, strings.Repeat("─", 50))
+	fmt.Fprintf(a.stdout, "Presentation ID: %s\n", vpClaims.VP.ID)
+	fmt.Fprintf(a.stdout, "Holder:          %s\n", vpClaims.VP.Holder)
+	fmt.Fprintf(a.stdout, "Audience:        %s\n", vpClaims.Audience)
+	fmt.Fprintf(a.stdout, "Nonce:           %s\n", vpClaims.Nonce)
+	fmt.Fprintf(a.stdout, "Issued At:       %s\n", vpClaims.IssuedAt.Format("2006-01-02 15:04:05 UTC"))
+	fmt.Fprintf(a.stdout, "Expires At:      %s\n", vpClaims.ExpiresAt.Format("2006-01-02 15:04:05 UTC"))
+	fmt.Fprintf(a.stdout, "Credentials:     %d\n", len(vpClaims.VP.VerifiableCredential))
+	if len(vpClaims.VP.References) > 0 {
+		fmt.Fprintf(a.stdout, "References:      %d\n", len(vpClaims.VP.References))
+	}
 
-	fmt.Println(strings.Repeat("─", 50))
-	fmt.Println("Embedded Credentials:")
+	fmt.Fprintln(a.stdout, strings.Repeat("─", 50))
+	fmt.Fprintln(a.stdout, "Embedded Credentials:")
 
-	// Verify each embedded credential using DID resolution
-	for i, credToken := range vpClaims.VP.VerifiableCredential {
-		fmt.Printf("\n[Credential %d]\n", i+1)
-		verifyEmbeddedCredential(credToken, registryPath, skipRevocation)
+	for _, result := range results {
+		fmt.Fprintf(a.stdout, "\n[Credential %d]\n", result.Index+1)
+		a.printCredentialResult(result)
 	}
-}
 
-func verifyEmbeddedCredential(token, registryPath string, skipRevocation bool) {
-	// First, we need to decode the token to get the issuer DID
-	// PASETO tokens are base64url encoded, we can parse the payload
-	// For now, we'll try to verify by resolving the issuer from the token claims
+	fmt.Fprintln(a.stdout, strings.Repeat("─", 50))
+	fmt.Fprintf(a.stdout, "%d of %d credentials valid\n", results.ValidCount(), len(results))
 
-	// Try common issuer DIDs or extract from token
-	// Since PASETO is encrypted, we need to try verification with resolved keys
-	// This is a simplified approach - in production, you'd have issuer metadata
+	if !results.AllValid() {
+		return cli.ErrFailed
+	}
+	return nil
+}
 
-	// Parse the token to extract issuer (PASETO v4 public tokens have readable payload)
-	parts := strings.Split(token, ".")
-	if len(parts) < 3 {
-		fmt.Println("  ⚠️  Invalid token format")
-		return
+func (a *app) printCredentialResult(result presentation.CredentialResult) {
+	if result.Valid {
+		fmt.Fprintln(a.stdout, "  ✅ Valid")
+	} else if result.Revoked() {
+		fmt.Fprintln(a.stdout, "  ❌ Revoked")
+	} else if result.Status == revocation.StatusSuspended {
+		fmt.Fprintln(a.stdout, "  ⏸️  Suspended")
+	} else if errors.Is(result.Err, veriglob.ErrUntrustedIssuer) {
+		fmt.Fprintln(a.stdout, "  ❌ Untrusted issuer")
+	} else {
+		fmt.Fprintln(a.stdout, "  ❌ Invalid")
 	}
 
-	// For demonstration, show token prefix
-	fmt.Printf("  Token: %s...\n", token[:min(60, len(token))])
-	fmt.Println("  ℹ️  To verify embedded credentials, issuer DID resolution is needed")
-	fmt.Println("  ℹ️  Use: verifier -token <token> -issuer <issuer_did>")
+	if result.Reference != nil {
+		fmt.Fprintf(a.stdout, "  Reference:     %s\n", result.Reference.ID)
+	}
+	if result.CredentialID != "" {
+		fmt.Fprintf(a.stdout, "  Credential ID: %s\n", result.CredentialID)
+	}
+	if result.Issuer != "" {
+		fmt.Fprintf(a.stdout, "  Issuer:        %s\n", result.Issuer)
+	}
+	if result.Subject != "" {
+		fmt.Fprintf(a.stdout, "  Subject:       %s\n", result.Subject)
+	}
+	if result.Claims != nil {
+		fmt.Fprintf(a.stdout, "  Type:          %s\n", result.Claims.CredentialType())
+		status := string(result.Status)
+		if status == "" {
+			status = "not tracked"
+		} else if result.StatusFromProof {
+			status += " (from status proof)"
+		}
+		fmt.Fprintf(a.stdout, "  Status:        %s\n", status)
+		fmt.Fprintf(a.stdout, "  Timing:        resolve %s, signature %s, status %s\n",
+			result.Timings.Resolution, result.Timings.Signature, result.Timings.Revocation)
+	}
+	if result.Err != nil {
+		fmt.Fprintf(a.stdout, "  Error:         %v\n", result.Err)
+	}
 }
 
-func verifyCredential(inputFile, tokenFlag, publicKeyFlag, issuerDIDFlag, registryPath string, skipRevocation bool) {
-	var token string
-	var publicKey ed25519.PublicKey
-	var issuerDIDResolved string
-
-	// Load from file if provided
+func (a *app) verifyCredential(inputFile, tokenFlag, publicKeyFlag, issuerDIDFlag, registryPath string, skipRevocation bool, opts veriglob.CredentialFileOptions) error {
+	var data []byte
 	if inputFile != "" {
-		data, err := os.ReadFile(inputFile)
+		var err error
+		data, err = os.ReadFile(inputFile)
 		if err != nil {
-			log.Fatalf("Failed to read input file: %v", err)
-		}
-
-		var credential struct {
-			CredentialID string `json:"credentialId"`
-			Issuer       struct {
-				DID       string `json:"did"`
-				PublicKey string `json:"publicKey"`
-			} `json:"issuer"`
-			Token string `json:"token"`
-		}
-
-		if err := json.Unmarshal(data, &credential); err != nil {
-			log.Fatalf("Failed to parse credential file: %v", err)
-		}
-
-		token = credential.Token
-
-		// Try to resolve public key from issuer DID first
-		if credential.Issuer.DID != "" {
-			resolved, err := resolver.ResolveDID(credential.Issuer.DID)
-			if err == nil {
-				publicKey = resolved
-				issuerDIDResolved = credential.Issuer.DID
-				fmt.Printf("🔑 Resolved issuer public key from DID\n")
-			}
-		}
-
-		// Fall back to hex-encoded public key
-		if publicKey == nil && credential.Issuer.PublicKey != "" {
-			pubKeyBytes, err := hex.DecodeString(credential.Issuer.PublicKey)
-			if err != nil {
-				log.Fatalf("Failed to decode public key: %v", err)
-			}
-			publicKey = ed25519.PublicKey(pubKeyBytes)
+			return fmt.Errorf("failed to read input file: %w", err)
 		}
 	} else if tokenFlag != "" {
-		token = tokenFlag
-
-		// Try DID resolution first
-		if issuerDIDFlag != "" {
-			resolved, err := resolver.ResolveDID(issuerDIDFlag)
-			if err != nil {
-				log.Fatalf("Failed to resolve issuer DID: %v", err)
-			}
-			publicKey = resolved
-			issuerDIDResolved = issuerDIDFlag
-			fmt.Printf("🔑 Resolved issuer public key from DID\n")
-		} else if publicKeyFlag != "" {
-			// Fall back to hex-encoded public key
-			pubKeyBytes, err := hex.DecodeString(publicKeyFlag)
-			if err != nil {
-				log.Fatalf("Failed to decode public key: %v", err)
-			}
-			publicKey = ed25519.PublicKey(pubKeyBytes)
-		} else {
-			printUsage()
-			os.Exit(1)
-		}
+		// The flags describe the same credential a file would
+		data, _ = json.Marshal(veriglob.CredentialFile{
+			Issuer: veriglob.PartyKey{DID: issuerDIDFlag, PublicKey: publicKeyFlag},
+			Token:  tokenFlag,
+		})
 	} else {
-		printUsage()
-		os.Exit(1)
+		a.printUsage()
+		return cli.ErrFailed
 	}
 
-	if publicKey == nil {
-		log.Fatalf("Could not determine issuer public key")
+	file, err := veriglob.ParseCredentialFile(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse credential file: %w", err)
 	}
 
-	// Verify the credential signature
-	claims, err := vc.VerifyVC(token, publicKey)
+	// Show what the credential claims before any key is trusted
+	md, err := vc.InspectVC(file.Token)
 	if err != nil {
-		fmt.Println("❌ VERIFICATION FAILED")
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintln(a.stdout, "❌ VERIFICATION FAILED")
+		fmt.Fprintf(a.stdout, "Error: %v\n", err)
+		return cli.ErrFailed
 	}
+	fmt.Fprintln(a.stdout, "Credential (unverified):")
+	fmt.Fprintf(a.stdout, "  ID:         %s\n", md.ID)
+	fmt.Fprintf(a.stdout, "  Type:       %s\n", md.Type)
+	fmt.Fprintf(a.stdout, "  Issuer:     %s\n", md.Issuer)
+	fmt.Fprintf(a.stdout, "  Expires At: %s\n", md.ExpiresAt.Format("2006-01-02 15:04:05 UTC"))
 
-	// Check revocation status
-	credentialID := claims.GetCredentialID()
-	revocationStatus := "not tracked"
-	isRevoked := false
-
-	if credentialID != "" && !skipRevocation {
+	if !skipRevocation {
 		registry, err := revocation.NewRegistryWithFile(registryPath)
 		if err != nil {
-			fmt.Printf("⚠️  Warning: Could not load revocation registry: %v\n", err)
+			fmt.Fprintf(a.stdout, "⚠️  Warning: Could not load revocation registry: %v\n", err)
 		} else {
-			entry, err := registry.CheckStatus(credentialID)
-			if err == nil {
-				revocationStatus = string(entry.Status)
-				isRevoked = entry.Status == revocation.StatusRevoked
-			} else if err == revocation.ErrCredentialNotFound {
-				revocationStatus = "not in registry"
-			}
+			opts.Status = registry
 		}
 	}
 
+	// Resolve the issuer key, verify the signature, and check revocation
+	info, err := veriglob.VerifyCredentialFile(data, opts)
+	if errors.Is(err, veriglob.ErrUntrustedIssuer) {
+		fmt.Fprintln(a.stdout, "❌ UNTRUSTED ISSUER")
+		fmt.Fprintf(a.stdout, "Error: %v\n", err)
+		return cli.ErrFailed
+	}
+	if err != nil && !errors.Is(err, presentation.ErrCredentialNotActive) {
+		fmt.Fprintln(a.stdout, "❌ VERIFICATION FAILED")
+		fmt.Fprintf(a.stdout, "Error: %v\n", err)
+		return cli.ErrFailed
+	}
+
+	isRevoked := info.Status == string(revocation.StatusRevoked)
+	isSuspended := info.Status == string(revocation.StatusSuspended)
 	if isRevoked {
-		fmt.Println("❌ CREDENTIAL REVOKED")
+		fmt.Fprintln(a.stdout, "❌ CREDENTIAL REVOKED")
+	} else if isSuspended {
+		fmt.Fprintln(a.stdout, "⏸️  CREDENTIAL SUSPENDED")
 	} else {
-		fmt.Println("✅ VERIFICATION SUCCESSFUL")
+		fmt.Fprintln(a.stdout, "✅ VERIFICATION SUCCESSFUL")
 	}
-	fmt.Println(strings.Repeat("─", 50))
+	fmt.Fprintln(a.stdout, strings.Repeat("─", 50))
 
 	// Display claims
-	if credentialID != "" {
-		fmt.Printf("Credential ID: %s\n", credentialID)
+	if info.ID != "" {
+		fmt.Fprintf(a.stdout, "Credential ID: %s\n", info.ID)
 	}
-	if issuerDIDResolved != "" {
-		fmt.Printf("Issuer:        %s (resolved)\n", claims.Issuer)
+	if info.IssuerResolved {
+		fmt.Fprintf(a.stdout, "Issuer:        %s (resolved)\n", info.IssuerDID)
 	} else {
-		fmt.Printf("Issuer:        %s\n", claims.Issuer)
+		fmt.Fprintf(a.stdout, "Issuer:        %s (unverified: checked against the supplied key only)\n", info.Claims.Issuer)
+	}
+	status := info.Status
+	if status == "" {
+		status = "not tracked"
+	}
+	fmt.Fprintf(a.stdout, "Subject:       %s\n", info.SubjectDID)
+	fmt.Fprintf(a.stdout, "Issued At:     %s\n", info.IssuedAt.Format("2006-01-02 15:04:05 UTC"))
+	fmt.Fprintf(a.stdout, "Expires At:    %s\n", info.ExpiresAt.Format("2006-01-02 15:04:05 UTC"))
+	fmt.Fprintf(a.stdout, "Status:        %s\n", status)
+	if info.RevocationReason != "" {
+		fmt.Fprintf(a.stdout, "Reason:        %s\n", info.RevocationReason)
 	}
-	fmt.Printf("Subject:       %s\n", claims.Subject)
-	fmt.Printf("Issued At:     %s\n", claims.IssuedAt.Format("2006-01-02 15:04:05 UTC"))
-	fmt.Printf("Expires At:    %s\n", claims.ExpiresAt.Format("2006-01-02 15:04:05 UTC"))
-	fmt.Printf("Status:        %s\n", revocationStatus)
 
-	fmt.Println(strings.Repeat("─", 50))
-	fmt.Println("Credential Types:")
-	for _, t := range claims.VC.Type {
-		fmt.Printf("  • %s\n", t)
+	fmt.Fprintln(a.stdout, strings.Repeat("─", 50))
+	fmt.Fprintln(a.stdout, "Credential Types:")
+	for _, t := range info.Claims.VC.Type {
+		fmt.Fprintf(a.stdout, "  • %s\n", t)
 	}
 
-	fmt.Println(strings.Repeat("─", 50))
-	fmt.Println("Credential Subject:")
+	fmt.Fprintln(a.stdout, strings.Repeat("─", 50))
+	fmt.Fprintln(a.stdout, "Credential Subject:")
 
-	subjectJSON, err := json.MarshalIndent(claims.VC.CredentialSubject, "  ", "  ")
+	subjectJSON, err := json.MarshalIndent(info.Claims.VC.CredentialSubject, "  ", "  ")
 	if err != nil {
-		log.Fatalf("Failed to marshal subject: %v", err)
+		return fmt.Errorf("failed to marshal subject: %w", err)
 	}
-	fmt.Printf("  %s\n", subjectJSON)
+	fmt.Fprintf(a.stdout, "  %s\n", subjectJSON)
 
-	// Exit with error code if revoked
-	if isRevoked {
-		os.Exit(1)
+	// Exit with error code if revoked or suspended
+	if isRevoked || isSuspended {
+		return cli.ErrFailed
 	}
+	return nil
 }
 
-func printUsage() {
-	fmt.Println("Verifier CLI - Verify Credentials and Presentations")
-	fmt.Println()
-	fmt.Println("Usage:")
-	fmt.Println("  Verify credential:")
-	fmt.Println("    verifier -input <credential.json>")
-	fmt.Println("    verifier -token <paseto_token> -issuer <issuer_did>")
-	fmt.Println("    verifier -token <paseto_token> -pubkey <hex_public_key>")
-	fmt.Println()
-	fmt.Println("  Verify presentation:")
-	fmt.Println("    verifier -presentation <presentation.json>")
-	fmt.Println("    verifier -presentation <presentation.json> -nonce <expected_nonce> -audience <verifier_did>")
-	fmt.Println()
-	fmt.Println("Options:")
-	fmt.Println("  -issuer <did>       Issuer's DID (auto-resolves public key)")
-	fmt.Println("  -pubkey <hex>       Issuer's public key (hex encoded)")
-	fmt.Println("  -registry <path>    Path to revocation registry (default: revocation_registry.json)")
-	fmt.Println("  -skip-revocation    Skip revocation status check")
-	fmt.Println("  -nonce              Expected nonce for presentation verification")
-	fmt.Println("  -audience           Expected audience for presentation verification")
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
+func (a *app) printUsage() {
+	fmt.Fprintln(a.stdout, "Verifier CLI - Verify Credentials and Presentations")
+	fmt.Fprintln(a.stdout)
+	fmt.Fprintln(a.stdout, "Usage:")
+	fmt.Fprintln(a.stdout, "  Verify credential:")
+	fmt.Fprintln(a.stdout, "    verifier -input <credential.json>")
+	fmt.Fprintln(a.stdout, "    verifier -token <paseto_token>   (resolves the issuer the credential names)")
+	fmt.Fprintln(a.stdout, "    verifier -token <paseto_token> -issuer <issuer_did>")
+	fmt.Fprintln(a.stdout, "    verifier -token <paseto_token> -pubkey <hex_public_key>")
+	fmt.Fprintln(a.stdout, "    verifier -token <paseto_token> -verification-method <did#key-id>")
+	fmt.Fprintln(a.stdout)
+	fmt.Fprintln(a.stdout, "  Verify presentation:")
+	fmt.Fprintln(a.stdout, "    verifier -presentation <presentation.json>")
+	fmt.Fprintln(a.stdout, "    verifier -presentation <presentation.json> -nonce <expected_nonce> -audience <verifier_did>")
+	fmt.Fprintln(a.stdout)
+	fmt.Fprintln(a.stdout, "Options:")
+	fmt.Fprintln(a.stdout, "  -issuer <did>       Issuer's DID (auto-resolves public key)")
+	fmt.Fprintln(a.stdout, "  -pubkey <hex>       Issuer's public key (hex encoded)")
+	fmt.Fprintln(a.stdout, "  -verification-method <id>  Only accept signatures from this verification method")
+	fmt.Fprintln(a.stdout, "  -trust <did> [types]  Only accept credentials from this issuer, optionally only of these comma-separated types; repeatable")
+	fmt.Fprintln(a.stdout, "  -trust-file <path>  File of trusted issuers, one \"<did> [types]\" per line")
+	fmt.Fprintln(a.stdout, "  -registry <path>    Path to revocation registry (default: revocation_registry.json)")
+	fmt.Fprintln(a.stdout, "  -skip-revocation    Skip revocation status check")
+	fmt.Fprintln(a.stdout, "  -nonce              Expected nonce for presentation verification")
+	fmt.Fprintln(a.stdout, "  -audience           Expected audience for presentation verification")
+	fmt.Fprintln(a.stdout, "  -require-status-proof  Require an issuer-signed status proof for each credential (offline checks)")
+	fmt.Fprintln(a.stdout, "  -status-proof-max-age  Oldest status proof accepted (default: 24h)")
+	fmt.Fprintln(a.stdout, "  -fetch-references   Download credentials a presentation references by URL (HTTPS only)")
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/veriglob/veriglob-core/internal/cli"
+	"github.com/veriglob/veriglob-core/internal/revocation"
+	"github.com/veriglob/veriglob-core/internal/vc"
+	"github.com/veriglob/veriglob-core/pkg/veriglob"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	h := veriglob.NewTestHarness()
+
+	token, err := h.IssueIdentity(veriglob.IdentitySubject{GivenName: "Alice", FamilyName: "Doe", DateOfBirth: "1990-01-01"})
+	if err != nil {
+		t.Fatalf("IssueIdentity failed: %v", err)
+	}
+	md, err := vc.InspectVC(token)
+	if err != nil {
+		t.Fatalf("InspectVC failed: %v", err)
+	}
+	credFile := writeJSON(t, dir, "credential.json", veriglob.CredentialFile{
+		CredentialID: md.ID,
+		Issuer:       veriglob.PartyKey{DID: h.Issuer.DID},
+		Token:        token,
+	})
+
+	registryPath := filepath.Join(dir, "registry.json")
+	registry, err := revocation.NewRegistryWithFile(registryPath)
+	if err != nil {
+		t.Fatalf("NewRegistryWithFile failed: %v", err)
+	}
+	if err := registry.Register(md.ID, h.Issuer.DID, h.Holder.DID); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Revoke(md.ID, "test"); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+
+	vpToken, nonce, err := h.Present(token)
+	if err != nil {
+		t.Fatalf("Present failed: %v", err)
+	}
+	presFile := writeJSON(t, dir, "presentation.json", veriglob.PresentationFile{
+		Holder:       veriglob.PartyKey{DID: h.Holder.DID},
+		Audience:     h.Verifier.DID,
+		Nonce:        nonce,
+		Presentation: vpToken,
+	})
+
+	trustFile := filepath.Join(dir, "trusted.txt")
+	if err := os.WriteFile(trustFile, []byte("# Issuers we accept\n"+h.Issuer.DID+" IdentityCredential\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{"no input", nil, 1, "Usage:", ""},
+		{"help", []string{"-h"}, 0, "", "-presentation"},
+		{"unknown flag", []string{"-bogus"}, 2, "", "flag provided but not defined"},
+		{"missing file", []string{"-input", filepath.Join(dir, "missing.json")}, 1, "", "Error: failed to read input file"},
+		{"valid credential", []string{"-input", credFile, "-skip-revocation"}, 0, "VERIFICATION SUCCESSFUL", ""},
+		{"bare token", []string{"-token", token, "-skip-revocation"}, 0, "VERIFICATION SUCCESSFUL", ""},
+		{"revoked credential", []string{"-input", credFile, "-registry", registryPath}, 1, "CREDENTIAL REVOKED", ""},
+		{"malformed token", []string{"-token", "v4.public.bogus", "-skip-revocation"}, 1, "VERIFICATION FAILED", ""},
+		{"valid presentation", []string{"-presentation", presFile, "-skip-revocation"}, 0, "PRESENTATION VERIFIED", ""},
+		{"revoked presentation", []string{"-presentation", presFile, "-registry", registryPath}, 1, "0 of 1 credentials valid", ""},
+		{"trusted issuer", []string{"-input", credFile, "-skip-revocation", "-trust", "did:key:zOther", "-trust", h.Issuer.DID}, 0, "VERIFICATION SUCCESSFUL", ""},
+		{"trusted issuer from file", []string{"-input", credFile, "-skip-revocation", "-trust-file", trustFile}, 0, "VERIFICATION SUCCESSFUL", ""},
+		{"untrusted issuer", []string{"-input", credFile, "-skip-revocation", "-trust", "did:key:zOther"}, 1, "UNTRUSTED ISSUER", ""},
+		{"issuer trusted for another type", []string{"-input", credFile, "-skip-revocation", "-trust", h.Issuer.DID + " EducationCredential"}, 1, "not trusted to issue IdentityCredential", ""},
+		{"untrusted issuer in presentation", []string{"-presentation", presFile, "-skip-revocation", "-trust", "did:key:zOther"}, 1, "Untrusted issuer", ""},
+		{"invalid trust list", []string{"-input", credFile, "-trust", "example.com"}, 1, "", "Error: failed to parse trusted issuers"},
+		{"wrong nonce", []string{"-presentation", presFile, "-nonce", "other", "-skip-revocation"}, 1, "PRESENTATION VERIFICATION FAILED", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := cli.Run(run, tt.args, &stdout, &stderr)
+			if code != tt.code {
+				t.Errorf("Expected exit code %d, got %d (stdout %q, stderr %q)", tt.code, code, stdout.String(), stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.stdout) {
+				t.Errorf("Expected stdout to contain %q, got %q", tt.stdout, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("Expected stderr to contain %q, got %q", tt.stderr, stderr.String())
+			}
+		})
+	}
+}
+
+func writeJSON(t *testing.T, dir, name string, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	return path
+}
@@ -2,18 +2,23 @@ package main
 
 import (
 	"bufio"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/veriglob/veriglob-core/internal/cli"
 	"github.com/veriglob/veriglob-core/internal/crypto"
 	"github.com/veriglob/veriglob-core/internal/did"
 	"github.com/veriglob/veriglob-core/internal/storage"
+	"github.com/veriglob/veriglob-core/internal/vc"
 
 	"golang.org/x/term"
 )
@@ -27,52 +32,104 @@ func getDefaultWalletPath() string {
 }
 
 func main() {
-	walletPath := flag.String("wallet", getDefaultWalletPath(), "Path to wallet file")
-	createCmd := flag.Bool("create", false, "Create a new wallet")
-	showCmd := flag.Bool("show", false, "Show wallet DID and info")
-	listCreds := flag.Bool("list", false, "List stored credentials")
-	addCred := flag.String("add", "", "Add credential from file")
-	exportCmd := flag.Bool("export", false, "Export wallet data (unencrypted)")
-	flag.Parse()
+	cli.Main(run)
+}
+
+// app carries the writers the command reports to
+type app struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	a := &app{stdout: stdout, stderr: stderr}
+	flags := flag.NewFlagSet("wallet", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	walletPath := flags.String("wallet", getDefaultWalletPath(), "Path to wallet file")
+	createCmd := flags.Bool("create", false, "Create a new wallet")
+	showCmd := flags.Bool("show", false, "Show wallet DID and info")
+	listCreds := flags.Bool("list", false, "List stored credentials")
+	addCred := flags.String("add", "", "Add credential from file (issuer JSON or a bare token)")
+	exportCmd := flags.Bool("export", false, "Export an encrypted wallet backup")
+	plaintextFlag := flags.Bool("unsafe-plaintext", false, "With -export, print the wallet data, private keys included, unencrypted; with -import, restore from such an export")
+	importFile := flags.String("import", "", "Restore a wallet from an encrypted backup file")
+	historyCmd := flags.Bool("history", false, "List presentation history")
+	changePassCmd := flags.Bool("change-passphrase", false, "Change the wallet passphrase")
+	rotateKeyCmd := flags.Bool("rotate-key", false, "Replace the wallet's signing key, keeping the old one for credentials issued to it")
+	phraseFlag := flags.Bool("recovery-phrase", false, "With -create, derive keys from a printed 24-word recovery phrase")
+	recoverCmd := flags.Bool("recover", false, "Recreate a wallet from its recovery phrase")
+	pruneCmd := flags.Bool("prune", false, "Remove expired credentials")
+	dryRunFlag := flags.Bool("dry-run", false, "With -prune, list expired credentials without removing them")
+	unlockTime := flags.Duration("unlock-time", 0, "With -create or -recover, tune the key derivation so unlocking takes about this long here (e.g. 500ms)")
+	if err := cli.ParseFlags(flags, args); err != nil {
+		return err
+	}
 
 	// Create wallet
 	if *createCmd {
-		createWallet(*walletPath)
-		return
+		return a.createWallet(*walletPath, *phraseFlag, *unlockTime)
+	}
+
+	// Recover wallet
+	if *recoverCmd {
+		return a.recoverWallet(*walletPath, *unlockTime)
 	}
 
 	// Show wallet info
 	if *showCmd {
-		showWallet(*walletPath)
-		return
+		return a.showWallet(*walletPath)
 	}
 
 	// List credentials
 	if *listCreds {
-		listCredentials(*walletPath)
-		return
+		return a.listCredentials(*walletPath)
 	}
 
 	// Add credential
 	if *addCred != "" {
-		addCredential(*walletPath, *addCred)
-		return
+		return a.addCredential(*walletPath, *addCred)
 	}
 
 	// Export wallet
 	if *exportCmd {
-		exportWallet(*walletPath)
-		return
+		return a.exportWallet(*walletPath, *plaintextFlag)
+	}
+
+	// Import wallet backup
+	if *importFile != "" {
+		return a.importWallet(*walletPath, *importFile, *plaintextFlag)
+	}
+
+	// Presentation history
+	if *historyCmd {
+		return a.listPresentations(*walletPath)
+	}
+
+	// Remove expired credentials
+	if *pruneCmd {
+		return a.pruneExpired(*walletPath, *dryRunFlag)
+	}
+
+	// Change passphrase
+	if *changePassCmd {
+		return a.changePassphrase(*walletPath)
+	}
+
+	// Rotate signing key
+	if *rotateKeyCmd {
+		return a.rotateKey(*walletPath)
 	}
 
 	// Default: show usage
-	printUsage()
+	a.printUsage()
+	return nil
 }
 
-func readPassword(prompt string) string {
-	fmt.Print(prompt)
+func (a *app) readPassword(prompt string) string {
+	fmt.Fprint(a.stdout, prompt)
 	password, err := term.ReadPassword(int(syscall.Stdin))
-	fmt.Println()
+	fmt.Fprintln(a.stdout)
 	if err != nil {
 		// Fallback for non-terminal input
 		reader := bufio.NewReader(os.Stdin)
@@ -82,147 +139,227 @@ func readPassword(prompt string) string {
 	return string(password)
 }
 
-func createWallet(path string) {
+func (a *app) createWallet(path string, withPhrase bool, unlockTime time.Duration) error {
+	if withPhrase {
+		phrase, err := crypto.GenerateMnemonic(crypto.RecoveryPhraseBits)
+		if err != nil {
+			return fmt.Errorf("failed to generate recovery phrase: %w", err)
+		}
+		pub, priv, err := crypto.KeypairFromMnemonic(phrase, "")
+		if err != nil {
+			return fmt.Errorf("failed to derive keypair: %w", err)
+		}
+		created, err := a.initWallet(path, pub, priv, unlockTime)
+		if err != nil || !created {
+			return err
+		}
+
+		fmt.Fprintln(a.stdout)
+		fmt.Fprintln(a.stdout, "Recovery phrase:")
+		fmt.Fprintln(a.stdout)
+		fmt.Fprintln(a.stdout, "  "+phrase)
+		fmt.Fprintln(a.stdout)
+		fmt.Fprintln(a.stdout, "IMPORTANT: Write down the recovery phrase and keep it offline.")
+		fmt.Fprintln(a.stdout, "Anyone with it can recreate your DID and keys with -recover.")
+		return nil
+	}
+
+	// Generate keypair
+	pub, priv, err := crypto.GenerateEd25519Keypair()
+	if err != nil {
+		return fmt.Errorf("failed to generate keypair: %w", err)
+	}
+	created, err := a.initWallet(path, pub, priv, unlockTime)
+	if err != nil || !created {
+		return err
+	}
+
+	fmt.Fprintln(a.stdout)
+	fmt.Fprintln(a.stdout, "IMPORTANT: Remember your passphrase. It cannot be recovered.")
+	return nil
+}
+
+func (a *app) recoverWallet(path string, unlockTime time.Duration) error {
+	fmt.Fprint(a.stdout, "Enter recovery phrase: ")
+	reader := bufio.NewReader(os.Stdin)
+	line, _ := reader.ReadString('\n')
+	phrase := strings.Join(strings.Fields(strings.ToLower(line)), " ")
+
+	pub, priv, err := crypto.KeypairFromMnemonic(phrase, "")
+	if err != nil {
+		return fmt.Errorf("failed to recover keys: %w", err)
+	}
+	created, err := a.initWallet(path, pub, priv, unlockTime)
+	if err != nil || !created {
+		return err
+	}
+
+	fmt.Fprintln(a.stdout)
+	fmt.Fprintln(a.stdout, "Credentials are not part of the recovery phrase; add them again with -add.")
+	return nil
+}
+
+// initWallet creates a wallet at path holding the given keys, prompting for
+// the passphrase. A positive unlockTime calibrates the key derivation cost
+// to it. It returns false if the user declined to overwrite.
+func (a *app) initWallet(path string, pub ed25519.PublicKey, priv ed25519.PrivateKey, unlockTime time.Duration) (bool, error) {
 	// Check if wallet exists
 	if _, err := os.Stat(path); err == nil {
-		fmt.Println("Wallet already exists at:", path)
-		fmt.Print("Overwrite? (y/N): ")
+		fmt.Fprintln(a.stdout, "Wallet already exists at:", path)
+		fmt.Fprint(a.stdout, "Overwrite? (y/N): ")
 		reader := bufio.NewReader(os.Stdin)
 		response, _ := reader.ReadString('\n')
 		if strings.ToLower(strings.TrimSpace(response)) != "y" {
-			fmt.Println("Aborted.")
-			return
+			fmt.Fprintln(a.stdout, "Aborted.")
+			return false, nil
 		}
 		os.Remove(path)
 	}
 
 	// Get passphrase
-	pass1 := readPassword("Enter passphrase: ")
-	pass2 := readPassword("Confirm passphrase: ")
+	pass1 := a.readPassword("Enter passphrase: ")
+	pass2 := a.readPassword("Confirm passphrase: ")
 
 	if pass1 != pass2 {
-		log.Fatal("Passphrases do not match")
+		return false, errors.New("passphrases do not match")
 	}
 
-	if len(pass1) < 8 {
-		log.Fatal("Passphrase must be at least 8 characters")
+	if len(pass1) < storage.MinPassphraseLength {
+		return false, fmt.Errorf("passphrase must be at least %d characters", storage.MinPassphraseLength)
 	}
 
-	// Create wallet
-	wallet, err := storage.CreateWallet(path, pass1)
-	if err != nil {
-		log.Fatalf("Failed to create wallet: %v", err)
+	var opts storage.WalletOptions
+	if unlockTime > 0 {
+		params, err := storage.CalibrateKDF(unlockTime)
+		if err != nil {
+			return false, fmt.Errorf("failed to calibrate key derivation: %w", err)
+		}
+		fmt.Fprintf(a.stdout, "Key derivation: Argon2id, %d passes, %d MiB, %d threads\n", params.Time, params.Memory/1024, params.Threads)
+		opts.KDFParams = params
 	}
 
-	// Generate keypair
-	pub, priv, err := crypto.GenerateEd25519Keypair()
+	// Create wallet
+	wallet, err := storage.CreateWalletWithOptions(path, pass1, opts)
 	if err != nil {
-		log.Fatalf("Failed to generate keypair: %v", err)
+		return false, fmt.Errorf("failed to create wallet: %w", err)
 	}
 
 	// Create DID
 	didKey, err := did.CreateDIDKey(pub)
 	if err != nil {
-		log.Fatalf("Failed to create DID: %v", err)
+		return false, fmt.Errorf("failed to create DID: %w", err)
 	}
 
 	// Store in wallet
 	if err := wallet.SetKeys(pub, priv, didKey.DID); err != nil {
-		log.Fatalf("Failed to save keys: %v", err)
+		return false, fmt.Errorf("failed to save keys: %w", err)
 	}
 
-	fmt.Println("Wallet created successfully!")
-	fmt.Println()
-	fmt.Println("DID:", didKey.DID)
-	fmt.Println("Wallet:", path)
-	fmt.Println()
-	fmt.Println("IMPORTANT: Remember your passphrase. It cannot be recovered.")
+	fmt.Fprintln(a.stdout, "Wallet created successfully!")
+	fmt.Fprintln(a.stdout)
+	fmt.Fprintln(a.stdout, "DID:", didKey.DID)
+	fmt.Fprintln(a.stdout, "Wallet:", path)
+	return true, nil
 }
 
-func showWallet(path string) {
-	pass := readPassword("Enter passphrase: ")
+func (a *app) showWallet(path string) error {
+	pass := a.readPassword("Enter passphrase: ")
 
 	wallet, err := storage.OpenWallet(path, pass)
 	if err != nil {
 		if err == storage.ErrWalletNotFound {
-			fmt.Println("Wallet not found. Create one with: wallet -create")
-			return
+			fmt.Fprintln(a.stdout, "Wallet not found. Create one with: wallet -create")
+			return cli.ErrFailed
 		}
 		if err == storage.ErrInvalidPassword {
-			fmt.Println("Invalid passphrase")
-			return
+			fmt.Fprintln(a.stdout, "Invalid passphrase")
+			return cli.ErrFailed
 		}
-		log.Fatalf("Failed to open wallet: %v", err)
+		return fmt.Errorf("failed to open wallet: %w", err)
 	}
 
 	pub, _, err := wallet.GetKeys()
 	if err != nil {
-		log.Fatalf("Failed to get keys: %v", err)
+		return fmt.Errorf("failed to get keys: %w", err)
 	}
 
 	didKey, err := did.CreateDIDKey(pub)
 	if err != nil {
-		log.Fatalf("Failed to create DID: %v", err)
+		return fmt.Errorf("failed to create DID: %w", err)
 	}
 
-	fmt.Println("DID:")
-	fmt.Println(wallet.GetDID())
-	fmt.Println()
-	fmt.Println("DID Document:")
+	fmt.Fprintln(a.stdout, "DID:")
+	fmt.Fprintln(a.stdout, wallet.GetDID())
+	fmt.Fprintln(a.stdout)
+	if id, err := wallet.GetIdentity(wallet.DefaultIdentity()); err == nil && len(id.PreviousKeys) > 0 {
+		fmt.Fprintln(a.stdout, "Previous DIDs:")
+		for _, k := range id.PreviousKeys {
+			fmt.Fprintf(a.stdout, "%s (retired %s)\n", k.DID, k.RetiredAt.Format("2006-01-02"))
+		}
+		fmt.Fprintln(a.stdout)
+	}
+	fmt.Fprintln(a.stdout, "DID Document:")
 	doc, _ := didKey.PrettyPrint()
-	fmt.Println(doc)
-	fmt.Println()
-	fmt.Printf("Stored Credentials: %d\n", len(wallet.ListCredentials()))
+	fmt.Fprintln(a.stdout, doc)
+	fmt.Fprintln(a.stdout)
+	fmt.Fprintf(a.stdout, "Stored Credentials: %d\n", len(wallet.ListCredentials()))
+	return nil
 }
 
-func listCredentials(path string) {
-	pass := readPassword("Enter passphrase: ")
+func (a *app) listCredentials(path string) error {
+	pass := a.readPassword("Enter passphrase: ")
 
 	wallet, err := storage.OpenWallet(path, pass)
 	if err != nil {
 		if err == storage.ErrInvalidPassword {
-			fmt.Println("Invalid passphrase")
-			return
+			fmt.Fprintln(a.stdout, "Invalid passphrase")
+			return cli.ErrFailed
 		}
-		log.Fatalf("Failed to open wallet: %v", err)
+		return fmt.Errorf("failed to open wallet: %w", err)
 	}
 
 	creds := wallet.ListCredentials()
 	if len(creds) == 0 {
-		fmt.Println("No credentials stored.")
-		return
+		fmt.Fprintln(a.stdout, "No credentials stored.")
+		return nil
 	}
 
-	fmt.Printf("Stored Credentials (%d):\n\n", len(creds))
+	fmt.Fprintf(a.stdout, "Stored Credentials (%d):\n\n", len(creds))
 	for i, c := range creds {
-		fmt.Printf("[%d] %s\n", i+1, c.ID)
-		fmt.Printf("    Type:      %s\n", c.Type)
-		fmt.Printf("    Issuer:    %s\n", c.IssuerDID)
-		fmt.Printf("    Issued:    %s\n", c.IssuedAt.Format("2006-01-02 15:04:05"))
-		fmt.Printf("    Expires:   %s\n", c.ExpiresAt.Format("2006-01-02 15:04:05"))
-		fmt.Printf("    Stored:    %s\n", c.StoredAt.Format("2006-01-02 15:04:05"))
-		fmt.Println()
+		fmt.Fprintf(a.stdout, "[%d] %s\n", i+1, c.ID)
+		fmt.Fprintf(a.stdout, "    Type:      %s\n", c.Type)
+		if c.IssuerDisplay != nil {
+			fmt.Fprintf(a.stdout, "    Issuer:    %s (%s)\n", c.IssuerDisplay.Name, c.IssuerDID)
+		} else {
+			fmt.Fprintf(a.stdout, "    Issuer:    %s\n", c.IssuerDID)
+		}
+		fmt.Fprintf(a.stdout, "    Issued:    %s\n", c.IssuedAt.Format("2006-01-02 15:04:05"))
+		fmt.Fprintf(a.stdout, "    Expires:   %s\n", c.ExpiresAt.Format("2006-01-02 15:04:05"))
+		fmt.Fprintf(a.stdout, "    Stored:    %s\n", c.StoredAt.Format("2006-01-02 15:04:05"))
+		fmt.Fprintln(a.stdout)
 	}
+	return nil
 }
 
-func addCredential(walletPath, credPath string) {
-	pass := readPassword("Enter passphrase: ")
+func (a *app) addCredential(walletPath, credPath string) error {
+	pass := a.readPassword("Enter passphrase: ")
 
 	wallet, err := storage.OpenWallet(walletPath, pass)
 	if err != nil {
 		if err == storage.ErrInvalidPassword {
-			fmt.Println("Invalid passphrase")
-			return
+			fmt.Fprintln(a.stdout, "Invalid passphrase")
+			return cli.ErrFailed
 		}
-		log.Fatalf("Failed to open wallet: %v", err)
+		return fmt.Errorf("failed to open wallet: %w", err)
 	}
 
 	// Read credential file
 	data, err := os.ReadFile(credPath)
 	if err != nil {
-		log.Fatalf("Failed to read credential file: %v", err)
+		return fmt.Errorf("failed to read credential file: %w", err)
 	}
 
+	// The file is the issuer's credential JSON or a bare token
 	var cred struct {
 		CredentialID   string `json:"credentialId"`
 		CredentialType string `json:"credentialType"`
@@ -232,9 +369,14 @@ func addCredential(walletPath, credPath string) {
 		} `json:"issuer"`
 		Token string `json:"token"`
 	}
+	if err := json.Unmarshal(data, &cred); err != nil || cred.Token == "" {
+		cred.Token = strings.TrimSpace(string(data))
+	}
 
-	if err := json.Unmarshal(data, &cred); err != nil {
-		log.Fatalf("Failed to parse credential: %v", err)
+	// The signature is not checked here: the wallet may not have the issuer key
+	md, err := vc.InspectVC(cred.Token)
+	if err != nil {
+		return fmt.Errorf("failed to parse credential: %w", err)
 	}
 
 	storedCred := storage.StoredCredential{
@@ -244,50 +386,297 @@ func addCredential(walletPath, credPath string) {
 		IssuerPublicKey: cred.Issuer.PublicKey,
 		Token:           cred.Token,
 	}
+	if storedCred.ID == "" {
+		storedCred.ID = md.ID
+	}
+	if storedCred.Type == "" {
+		storedCred.Type = md.Type
+	}
+	if storedCred.IssuerDID == "" {
+		storedCred.IssuerDID = md.Issuer
+	}
 
+	duplicates := wallet.FindDuplicates(cred.Token)
 	if err := wallet.AddCredential(storedCred); err != nil {
 		if err == storage.ErrCredentialExists {
-			fmt.Println("Credential already exists in wallet")
-			return
+			fmt.Fprintln(a.stdout, "Credential already exists in wallet")
+			return cli.ErrFailed
+		}
+		return fmt.Errorf("failed to add credential: %w", err)
+	}
+
+	fmt.Fprintln(a.stdout, "Credential added to wallet (details not yet verified):")
+	fmt.Fprintf(a.stdout, "  ID:      %s\n", storedCred.ID)
+	fmt.Fprintf(a.stdout, "  Type:    %s\n", storedCred.Type)
+	fmt.Fprintf(a.stdout, "  Issuer:  %s\n", storedCred.IssuerDID)
+	fmt.Fprintf(a.stdout, "  Subject: %s\n", md.Subject)
+	fmt.Fprintf(a.stdout, "  Expires: %s\n", md.ExpiresAt.Format("2006-01-02 15:04:05"))
+	if md.Expired(time.Now()) {
+		fmt.Fprintln(a.stdout, "Warning: this credential has already expired")
+	}
+	for _, dup := range duplicates {
+		fmt.Fprintf(a.stdout, "Warning: same content as stored credential %s\n", dup.ID)
+	}
+	return nil
+}
+
+func (a *app) pruneExpired(path string, dryRun bool) error {
+	pass := a.readPassword("Enter passphrase: ")
+
+	wallet, err := storage.OpenWallet(path, pass)
+	if err != nil {
+		if err == storage.ErrInvalidPassword {
+			fmt.Fprintln(a.stdout, "Invalid passphrase")
+			return cli.ErrFailed
+		}
+		return fmt.Errorf("failed to open wallet: %w", err)
+	}
+
+	now := time.Now()
+	expired := wallet.ListExpired(now)
+	if len(expired) == 0 {
+		fmt.Fprintln(a.stdout, "No expired credentials.")
+		return nil
+	}
+
+	fmt.Fprintf(a.stdout, "Expired Credentials (%d):\n\n", len(expired))
+	for _, c := range expired {
+		fmt.Fprintf(a.stdout, "  %s (%s, expired %s)\n", c.ID, c.Type, c.ExpiresAt.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Fprintln(a.stdout)
+
+	if dryRun {
+		fmt.Fprintln(a.stdout, "Dry run: no credentials were removed.")
+		return nil
+	}
+
+	removed, err := wallet.PruneExpired(now)
+	if err != nil {
+		return fmt.Errorf("failed to prune credentials: %w", err)
+	}
+	fmt.Fprintf(a.stdout, "Removed %d expired credential(s).\n", removed)
+	return nil
+}
+
+func (a *app) exportWallet(path string, plaintext bool) error {
+	pass := a.readPassword("Enter passphrase: ")
+
+	wallet, err := storage.OpenWallet(path, pass)
+	if err != nil {
+		if err == storage.ErrInvalidPassword {
+			fmt.Fprintln(a.stdout, "Invalid passphrase")
+			return cli.ErrFailed
+		}
+		return fmt.Errorf("failed to open wallet: %w", err)
+	}
+
+	if plaintext {
+		fmt.Fprintln(a.stderr, "WARNING: the export contains your private keys unencrypted.")
+		data, err := wallet.ExportUnsafePlaintext()
+		if err != nil {
+			return fmt.Errorf("failed to export wallet: %w", err)
+		}
+		fmt.Fprintln(a.stdout, string(data))
+		return nil
+	}
+
+	backupPass1 := a.readPassword("Enter backup passphrase: ")
+	backupPass2 := a.readPassword("Confirm backup passphrase: ")
+	if backupPass1 != backupPass2 {
+		return errors.New("passphrases do not match")
+	}
+
+	data, err := wallet.ExportEncrypted(backupPass1)
+	if err != nil {
+		if err == storage.ErrPassphraseLength {
+			return fmt.Errorf("backup passphrase must be at least %d characters", storage.MinPassphraseLength)
+		}
+		return fmt.Errorf("failed to export wallet: %w", err)
+	}
+
+	fmt.Fprintln(a.stdout, string(data))
+	return nil
+}
+
+func (a *app) importWallet(path, backupFile string, plaintext bool) error {
+	blob, err := os.ReadFile(backupFile)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	if plaintext {
+		return a.importPlaintextWallet(path, blob)
+	}
+
+	pass := a.readPassword("Enter backup passphrase: ")
+
+	wallet, err := storage.ImportWallet(path, blob, pass)
+	if err != nil {
+		switch err {
+		case storage.ErrWalletExists:
+			fmt.Fprintln(a.stdout, "Wallet already exists at:", path)
+			return cli.ErrFailed
+		case storage.ErrInvalidPassword:
+			fmt.Fprintln(a.stdout, "Invalid passphrase, or the backup has been modified")
+			return cli.ErrFailed
+		}
+		return fmt.Errorf("failed to restore wallet: %w", err)
+	}
+
+	fmt.Fprintln(a.stdout, "Wallet restored successfully!")
+	fmt.Fprintln(a.stdout)
+	fmt.Fprintln(a.stdout, "DID:", wallet.GetDID())
+	fmt.Fprintln(a.stdout, "Wallet:", path)
+	fmt.Fprintln(a.stdout, "The wallet passphrase is the backup passphrase; change it with -change-passphrase.")
+	return nil
+}
+
+func (a *app) importPlaintextWallet(path string, data []byte) error {
+	pass1 := a.readPassword("Enter new wallet passphrase: ")
+	pass2 := a.readPassword("Confirm passphrase: ")
+	if pass1 != pass2 {
+		return errors.New("passphrases do not match")
+	}
+
+	wallet, err := storage.ImportWalletData(path, pass1, data)
+	if err != nil {
+		switch err {
+		case storage.ErrWalletExists:
+			fmt.Fprintln(a.stdout, "Wallet already exists at:", path)
+			return cli.ErrFailed
+		case storage.ErrPassphraseLength:
+			return fmt.Errorf("passphrase must be at least %d characters", storage.MinPassphraseLength)
+		case storage.ErrInvalidBackup:
+			return errors.New("the file is not a plaintext wallet export")
 		}
-		log.Fatalf("Failed to add credential: %v", err)
+		return fmt.Errorf("failed to restore wallet: %w", err)
 	}
 
-	fmt.Println("Credential added to wallet:")
-	fmt.Printf("  ID:   %s\n", storedCred.ID)
-	fmt.Printf("  Type: %s\n", storedCred.Type)
+	fmt.Fprintln(a.stdout, "Wallet restored successfully!")
+	fmt.Fprintln(a.stdout)
+	fmt.Fprintln(a.stdout, "DID:", wallet.GetDID())
+	fmt.Fprintln(a.stdout, "Wallet:", path)
+	fmt.Fprintln(a.stdout, "The export holds your private keys unencrypted; delete it once the wallet is restored.")
+	return nil
 }
 
-func exportWallet(path string) {
-	pass := readPassword("Enter passphrase: ")
+func (a *app) listPresentations(path string) error {
+	pass := a.readPassword("Enter passphrase: ")
 
 	wallet, err := storage.OpenWallet(path, pass)
 	if err != nil {
 		if err == storage.ErrInvalidPassword {
-			fmt.Println("Invalid passphrase")
-			return
+			fmt.Fprintln(a.stdout, "Invalid passphrase")
+			return cli.ErrFailed
+		}
+		return fmt.Errorf("failed to open wallet: %w", err)
+	}
+
+	records := wallet.ListPresentations()
+	if len(records) == 0 {
+		fmt.Fprintln(a.stdout, "No presentations recorded.")
+		return nil
+	}
+
+	fmt.Fprintf(a.stdout, "Presentation History (%d):\n\n", len(records))
+	for i, r := range records {
+		fmt.Fprintf(a.stdout, "[%d] %s\n", i+1, r.ID)
+		fmt.Fprintf(a.stdout, "    Audience:    %s\n", r.Audience)
+		fmt.Fprintf(a.stdout, "    Credentials: %s\n", strings.Join(r.CredentialIDs, ", "))
+		fmt.Fprintf(a.stdout, "    Presented:   %s\n", r.PresentedAt.Format("2006-01-02 15:04:05"))
+		fmt.Fprintln(a.stdout)
+	}
+	return nil
+}
+
+func (a *app) changePassphrase(path string) error {
+	oldPass := a.readPassword("Enter current passphrase: ")
+	wallet, err := storage.OpenWallet(path, oldPass)
+	if err != nil {
+		if err == storage.ErrWalletNotFound {
+			fmt.Fprintln(a.stdout, "Wallet not found. Create one with: wallet -create")
+			return cli.ErrFailed
+		}
+		if err == storage.ErrInvalidPassword {
+			fmt.Fprintln(a.stdout, "Invalid passphrase")
+			return cli.ErrFailed
+		}
+		return fmt.Errorf("failed to open wallet: %w", err)
+	}
+
+	pass1 := a.readPassword("Enter new passphrase: ")
+	pass2 := a.readPassword("Confirm new passphrase: ")
+
+	if pass1 != pass2 {
+		return errors.New("passphrases do not match")
+	}
+
+	if len(pass1) < storage.MinPassphraseLength {
+		return fmt.Errorf("passphrase must be at least %d characters", storage.MinPassphraseLength)
+	}
+
+	if err := wallet.ChangePassphrase(oldPass, pass1); err != nil {
+		return fmt.Errorf("failed to change passphrase: %w", err)
+	}
+
+	fmt.Fprintln(a.stdout, "Passphrase changed.")
+	return nil
+}
+
+func (a *app) rotateKey(path string) error {
+	pass := a.readPassword("Enter passphrase: ")
+	wallet, err := storage.OpenWallet(path, pass)
+	if err != nil {
+		if err == storage.ErrWalletNotFound {
+			fmt.Fprintln(a.stdout, "Wallet not found. Create one with: wallet -create")
+			return cli.ErrFailed
+		}
+		if err == storage.ErrInvalidPassword {
+			fmt.Fprintln(a.stdout, "Invalid passphrase")
+			return cli.ErrFailed
 		}
-		log.Fatalf("Failed to open wallet: %v", err)
+		return fmt.Errorf("failed to open wallet: %w", err)
 	}
 
-	data, err := wallet.Export()
+	oldDID := wallet.GetDID()
+	newDID, err := wallet.RotateKey()
 	if err != nil {
-		log.Fatalf("Failed to export wallet: %v", err)
+		return fmt.Errorf("failed to rotate key: %w", err)
 	}
 
-	fmt.Println(string(data))
+	fmt.Fprintln(a.stdout, "Signing key rotated.")
+	fmt.Fprintln(a.stdout, "Old DID:", oldDID)
+	fmt.Fprintln(a.stdout, "New DID:", newDID)
+	fmt.Fprintln(a.stdout)
+	fmt.Fprintln(a.stdout, "Credentials issued to the old DID are kept and can still be presented.")
+	fmt.Fprintln(a.stdout, "Ask their issuers to reissue them to the new DID.")
+	return nil
 }
 
-func printUsage() {
-	fmt.Println("Wallet CLI - Manage your decentralized identity")
-	fmt.Println()
-	fmt.Println("Usage:")
-	fmt.Println("  wallet -create              Create a new wallet")
-	fmt.Println("  wallet -show                Show wallet DID and info")
-	fmt.Println("  wallet -list                List stored credentials")
-	fmt.Println("  wallet -add <cred.json>     Add credential to wallet")
-	fmt.Println("  wallet -export              Export wallet data")
-	fmt.Println()
-	fmt.Println("Options:")
-	fmt.Println("  -wallet <path>    Path to wallet file (default: ~/.veriglob/wallet.json)")
+func (a *app) printUsage() {
+	fmt.Fprintln(a.stdout, "Wallet CLI - Manage your decentralized identity")
+	fmt.Fprintln(a.stdout)
+	fmt.Fprintln(a.stdout, "Usage:")
+	fmt.Fprintln(a.stdout, "  wallet -create              Create a new wallet")
+	fmt.Fprintln(a.stdout, "  wallet -create -recovery-phrase")
+	fmt.Fprintln(a.stdout, "                              Create a wallet backed by a 24-word recovery phrase")
+	fmt.Fprintln(a.stdout, "  wallet -recover             Recreate a wallet from its recovery phrase")
+	fmt.Fprintln(a.stdout, "  wallet -create -unlock-time 500ms")
+	fmt.Fprintln(a.stdout, "                              Tune the key derivation cost to this machine (also with -recover)")
+	fmt.Fprintln(a.stdout, "  wallet -show                Show wallet DID and info")
+	fmt.Fprintln(a.stdout, "  wallet -list                List stored credentials")
+	fmt.Fprintln(a.stdout, "  wallet -add <cred.json>     Add credential to wallet")
+	fmt.Fprintln(a.stdout, "  wallet -export              Export an encrypted wallet backup")
+	fmt.Fprintln(a.stdout, "  wallet -export -unsafe-plaintext")
+	fmt.Fprintln(a.stdout, "                              Export wallet data unencrypted (includes private keys)")
+	fmt.Fprintln(a.stdout, "  wallet -import <backup>     Restore a wallet from an encrypted backup")
+	fmt.Fprintln(a.stdout, "  wallet -import <export> -unsafe-plaintext")
+	fmt.Fprintln(a.stdout, "                              Restore a wallet from a plaintext export under a new passphrase")
+	fmt.Fprintln(a.stdout, "  wallet -prune [-dry-run]    Remove expired credentials (-dry-run only lists them)")
+	fmt.Fprintln(a.stdout, "  wallet -history             List presentation history")
+	fmt.Fprintln(a.stdout, "  wallet -change-passphrase   Change the wallet passphrase")
+	fmt.Fprintln(a.stdout, "  wallet -rotate-key          Replace the signing key and DID, keeping the old key")
+	fmt.Fprintln(a.stdout)
+	fmt.Fprintln(a.stdout, "Options:")
+	fmt.Fprintln(a.stdout, "  -wallet <path>    Path to wallet file (default: ~/.veriglob/wallet.json)")
 }
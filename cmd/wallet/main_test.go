@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/veriglob/veriglob-core/internal/cli"
+)
+
+// Commands that prompt for a passphrase read the terminal, so only the paths
+// that fail or finish before prompting are covered here
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	walletPath := filepath.Join(dir, "wallet.json")
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{"usage", []string{"-wallet", walletPath}, 0, "Usage:", ""},
+		{"help", []string{"-h"}, 0, "", "-change-passphrase"},
+		{"unknown flag", []string{"-bogus"}, 2, "", "flag provided but not defined"},
+		{"missing backup", []string{"-wallet", walletPath, "-import", filepath.Join(dir, "missing.json")}, 1, "", "Error: failed to read backup"},
+		{"missing plaintext export", []string{"-wallet", walletPath, "-import", filepath.Join(dir, "missing.json"), "-unsafe-plaintext"}, 1, "", "Error: failed to read backup"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := cli.Run(run, tt.args, &stdout, &stderr)
+			if code != tt.code {
+				t.Errorf("Expected exit code %d, got %d (stdout %q, stderr %q)", tt.code, code, stdout.String(), stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.stdout) {
+				t.Errorf("Expected stdout to contain %q, got %q", tt.stdout, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("Expected stderr to contain %q, got %q", tt.stderr, stderr.String())
+			}
+		})
+	}
+}
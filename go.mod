@@ -6,11 +6,16 @@ require github.com/mr-tron/base58 v1.2.0
 
 require (
 	aidanwoods.dev/go-paseto v1.6.0
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.4.1
+	github.com/fxamacker/cbor/v2 v2.9.0
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
+	github.com/tyler-smith/go-bip39 v1.1.0
 	golang.org/x/crypto v0.46.0
 	golang.org/x/term v0.38.0
 )
 
 require (
 	aidanwoods.dev/go-result v0.3.1 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	golang.org/x/sys v0.39.0 // indirect
 )
@@ -0,0 +1,66 @@
+// Package cli runs the veriglob commands. A command's logic returns an
+// error instead of exiting, so it can be embedded and tested; Main turns
+// that error into a message and an exit status.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ExitError ends a command with an exit status once the command has
+// reported why, e.g. after printing a failed verification
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ErrFailed ends a command with status 1 without printing anything further
+var ErrFailed = &ExitError{Code: 1}
+
+// Command is the logic of a command: it parses args and writes to stdout
+// and stderr, returning an error rather than exiting
+type Command func(args []string, stdout, stderr io.Writer) error
+
+// Main runs cmd with the process arguments and exits with its status
+func Main(cmd Command) {
+	os.Exit(Run(cmd, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// Run runs cmd and returns its exit status: 0 on success, the code of an
+// ExitError, or 1 for any other error, which is printed to stderr
+func Run(cmd Command, args []string, stdout, stderr io.Writer) int {
+	err := cmd(args, stdout, stderr)
+	var exit *ExitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exit):
+		return exit.Code
+	default:
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+}
+
+// ParseFlags parses args into fs, which should use flag.ContinueOnError.
+// The flag package has already reported a bad flag, so the error returned
+// only sets the status: 0 for -h, as with the default flag set, and 2
+// otherwise.
+func ParseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, flag.ErrHelp):
+		return &ExitError{Code: 0}
+	default:
+		return &ExitError{Code: 2}
+	}
+}
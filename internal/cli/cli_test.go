@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   int
+		stderr string
+	}{
+		{"success", nil, 0, ""},
+		{"error", errors.New("failed to read input file"), 1, "Error: failed to read input file\n"},
+		{"already reported", ErrFailed, 1, ""},
+		{"exit code", &ExitError{Code: 3}, 3, ""},
+		{"wrapped exit code", fmt.Errorf("verify: %w", &ExitError{Code: 4}), 4, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := Run(func(args []string, stdout, stderr io.Writer) error {
+				return tt.err
+			}, nil, &stdout, &stderr)
+			if code != tt.code {
+				t.Errorf("Expected exit code %d, got %d", tt.code, code)
+			}
+			if stderr.String() != tt.stderr {
+				t.Errorf("Expected stderr %q, got %q", tt.stderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{"valid", []string{"-name", "x"}, -1, ""},
+		{"help", []string{"-h"}, 0, "-name"},
+		{"unknown flag", []string{"-bogus"}, 2, "flag provided but not defined: -bogus"},
+		{"missing value", []string{"-name"}, 2, "flag needs an argument"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(&stderr)
+			fs.String("name", "", "a name")
+
+			err := ParseFlags(fs, tt.args)
+			var exit *ExitError
+			switch {
+			case tt.code < 0 && err != nil:
+				t.Errorf("Expected no error, got %v", err)
+			case tt.code >= 0 && !errors.As(err, &exit):
+				t.Errorf("Expected an ExitError, got %v", err)
+			case tt.code >= 0 && exit.Code != tt.code:
+				t.Errorf("Expected exit code %d, got %d", tt.code, exit.Code)
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("Expected stderr to contain %q, got %q", tt.stderr, stderr.String())
+			}
+		})
+	}
+}
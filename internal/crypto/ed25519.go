@@ -2,7 +2,15 @@ package crypto
 
 import (
 	"crypto/ed25519"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+)
+
+var (
+	ErrInvalidSeed      = errors.New("seed must be 32 bytes")
+	ErrInvalidMasterKey = errors.New("master key must be an Ed25519 private key")
 )
 
 // GenerateEd25519Keypair creates a new Ed25519 keypair
@@ -13,3 +21,26 @@ func GenerateEd25519Keypair() (ed25519.PublicKey, ed25519.PrivateKey, error) {
 	}
 	return pub, priv, nil
 }
+
+// KeypairFromSeed deterministically derives an Ed25519 keypair from a 32-byte seed
+func KeypairFromSeed(seed []byte) (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, nil, ErrInvalidSeed
+	}
+	priv := ed25519.NewKeyFromSeed(seed)
+	return priv.Public().(ed25519.PublicKey), priv, nil
+}
+
+// DeriveKeypair deterministically derives a child keypair from a master key
+// and a context such as a verifier DID, so a holder can use a different key
+// with each verifier. Keys for different contexts cannot be linked without
+// the master key.
+func DeriveKeypair(master ed25519.PrivateKey, context string) (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	if len(master) != ed25519.PrivateKeySize {
+		return nil, nil, ErrInvalidMasterKey
+	}
+
+	mac := hmac.New(sha512.New, master.Seed())
+	mac.Write([]byte("veriglob derived key:" + context))
+	return KeypairFromSeed(mac.Sum(nil)[:ed25519.SeedSize])
+}
@@ -26,3 +26,28 @@ func TestGenerateEd25519Keypair(t *testing.T) {
 		t.Error("Failed to verify signature with generated keypair")
 	}
 }
+
+func TestDeriveKeypair(t *testing.T) {
+	_, master, _ := GenerateEd25519Keypair()
+
+	pubA1, _, err := DeriveKeypair(master, "did:key:zVerifierA")
+	if err != nil {
+		t.Fatalf("DeriveKeypair() error = %v", err)
+	}
+	pubA2, _, _ := DeriveKeypair(master, "did:key:zVerifierA")
+	pubB, _, _ := DeriveKeypair(master, "did:key:zVerifierB")
+
+	if !pubA1.Equal(pubA2) {
+		t.Error("DeriveKeypair() returned different keys for the same context")
+	}
+	if pubA1.Equal(pubB) {
+		t.Error("DeriveKeypair() returned the same key for different contexts")
+	}
+	if pubA1.Equal(master.Public()) {
+		t.Error("DeriveKeypair() returned the master key")
+	}
+
+	if _, _, err := DeriveKeypair(master[:16], "ctx"); err != ErrInvalidMasterKey {
+		t.Errorf("DeriveKeypair() short key error = %v, want %v", err, ErrInvalidMasterKey)
+	}
+}
@@ -0,0 +1,57 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"errors"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+var (
+	ErrInvalidEntropyBits = errors.New("mnemonic entropy must be 128, 160, 192, 224 or 256 bits")
+	ErrInvalidMnemonic    = errors.New("invalid mnemonic phrase")
+)
+
+// RecoveryPhraseBits is the entropy of a 24-word recovery phrase
+const RecoveryPhraseBits = 256
+
+// GenerateMnemonic creates a BIP39 English mnemonic with the given entropy;
+// 256 bits gives 24 words
+func GenerateMnemonic(bits int) (string, error) {
+	switch bits {
+	case 128, 160, 192, 224, 256:
+	default:
+		return "", ErrInvalidEntropyBits
+	}
+
+	entropy, err := bip39.NewEntropy(bits)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// MnemonicToSeed checks the mnemonic's checksum and returns its 64-byte BIP39
+// seed. The passphrase is the optional BIP39 "25th word", not the wallet passphrase.
+func MnemonicToSeed(phrase, passphrase string) ([]byte, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(phrase, passphrase)
+	if err != nil {
+		return nil, ErrInvalidMnemonic
+	}
+	return seed, nil
+}
+
+// KeypairFromMnemonic derives the Ed25519 keypair for a recovery phrase. The
+// key is the SLIP-0010 Ed25519 master key of the BIP39 seed.
+func KeypairFromMnemonic(phrase, passphrase string) (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	seed, err := MnemonicToSeed(phrase, passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mac := hmac.New(sha512.New, []byte("ed25519 seed"))
+	mac.Write(seed)
+	return KeypairFromSeed(mac.Sum(nil)[:ed25519.SeedSize])
+}
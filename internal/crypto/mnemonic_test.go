@@ -0,0 +1,84 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestGenerateMnemonic(t *testing.T) {
+	phrase, err := GenerateMnemonic(RecoveryPhraseBits)
+	if err != nil {
+		t.Fatalf("GenerateMnemonic() error = %v", err)
+	}
+	if words := len(strings.Fields(phrase)); words != 24 {
+		t.Errorf("GenerateMnemonic(256) words = %d, want 24", words)
+	}
+
+	if _, err := MnemonicToSeed(phrase, ""); err != nil {
+		t.Errorf("MnemonicToSeed() of generated phrase error = %v", err)
+	}
+
+	if _, err := GenerateMnemonic(100); err != ErrInvalidEntropyBits {
+		t.Errorf("GenerateMnemonic(100) error = %v, want %v", err, ErrInvalidEntropyBits)
+	}
+}
+
+func TestMnemonicToSeedVector(t *testing.T) {
+	// BIP39 test vector (passphrase "TREZOR")
+	phrase := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	want := "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"
+
+	seed, err := MnemonicToSeed(phrase, "TREZOR")
+	if err != nil {
+		t.Fatalf("MnemonicToSeed() error = %v", err)
+	}
+	if got := hex.EncodeToString(seed); got != want {
+		t.Errorf("MnemonicToSeed() = %s, want %s", got, want)
+	}
+
+	if _, err := MnemonicToSeed("abandon abandon abandon", ""); err != ErrInvalidMnemonic {
+		t.Errorf("MnemonicToSeed() short phrase error = %v, want %v", err, ErrInvalidMnemonic)
+	}
+	// Valid words, bad checksum
+	if _, err := MnemonicToSeed(strings.Repeat("abandon ", 11)+"abandon", ""); err != ErrInvalidMnemonic {
+		t.Errorf("MnemonicToSeed() bad checksum error = %v, want %v", err, ErrInvalidMnemonic)
+	}
+}
+
+func TestKeypairFromSeedDeterministic(t *testing.T) {
+	seed := bytes.Repeat([]byte{7}, 32)
+
+	pub1, priv1, err := KeypairFromSeed(seed)
+	if err != nil {
+		t.Fatalf("KeypairFromSeed() error = %v", err)
+	}
+	pub2, priv2, _ := KeypairFromSeed(seed)
+
+	if !pub1.Equal(pub2) || !priv1.Equal(priv2) {
+		t.Error("KeypairFromSeed() returned different keys for the same seed")
+	}
+
+	if _, _, err := KeypairFromSeed(seed[:16]); err != ErrInvalidSeed {
+		t.Errorf("KeypairFromSeed() short seed error = %v, want %v", err, ErrInvalidSeed)
+	}
+}
+
+func TestKeypairFromMnemonicRoundTrip(t *testing.T) {
+	phrase, _ := GenerateMnemonic(RecoveryPhraseBits)
+
+	pub1, _, err := KeypairFromMnemonic(phrase, "")
+	if err != nil {
+		t.Fatalf("KeypairFromMnemonic() error = %v", err)
+	}
+	pub2, _, _ := KeypairFromMnemonic(phrase, "")
+	if !pub1.Equal(pub2) {
+		t.Error("KeypairFromMnemonic() returned different keys for the same phrase")
+	}
+
+	pub3, _, _ := KeypairFromMnemonic(phrase, "extra")
+	if pub1.Equal(pub3) {
+		t.Error("KeypairFromMnemonic() should depend on the BIP39 passphrase")
+	}
+}
@@ -0,0 +1,17 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+)
+
+// GenerateP256Keypair creates a new ECDSA P-256 keypair, the key type used
+// by WebAuthn platform authenticators (ES256)
+func GenerateP256Keypair() (*ecdsa.PublicKey, *ecdsa.PrivateKey, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &priv.PublicKey, priv, nil
+}
@@ -0,0 +1,22 @@
+package crypto
+
+import (
+	"crypto/elliptic"
+	"testing"
+)
+
+func TestGenerateP256Keypair(t *testing.T) {
+	pub, priv, err := GenerateP256Keypair()
+	if err != nil {
+		t.Fatalf("GenerateP256Keypair() error = %v", err)
+	}
+
+	if pub.Curve != elliptic.P256() {
+		t.Errorf("Curve = %s, want P-256", pub.Curve.Params().Name)
+	}
+
+	// Verify keys belong together
+	if !priv.PublicKey.Equal(pub) {
+		t.Error("Public key does not match private key")
+	}
+}
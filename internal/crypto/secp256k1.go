@@ -0,0 +1,15 @@
+package crypto
+
+import (
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// GenerateSecp256k1Keypair creates a new secp256k1 keypair, returning the
+// compressed public key and the 32-byte private scalar
+func GenerateSecp256k1Keypair() ([]byte, []byte, error) {
+	priv, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return priv.PubKey().SerializeCompressed(), priv.Serialize(), nil
+}
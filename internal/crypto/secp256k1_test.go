@@ -0,0 +1,29 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestGenerateSecp256k1Keypair(t *testing.T) {
+	pub, priv, err := GenerateSecp256k1Keypair()
+	if err != nil {
+		t.Fatalf("GenerateSecp256k1Keypair() error = %v", err)
+	}
+
+	if len(pub) != secp256k1.PubKeyBytesLenCompressed {
+		t.Errorf("PublicKey length = %d, want %d", len(pub), secp256k1.PubKeyBytesLenCompressed)
+	}
+
+	if len(priv) != secp256k1.PrivKeyBytesLen {
+		t.Errorf("PrivateKey length = %d, want %d", len(priv), secp256k1.PrivKeyBytesLen)
+	}
+
+	// Verify keys belong together
+	derived := secp256k1.PrivKeyFromBytes(priv).PubKey().SerializeCompressed()
+	if !bytes.Equal(derived, pub) {
+		t.Error("Public key does not match private key")
+	}
+}
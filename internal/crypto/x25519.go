@@ -0,0 +1,95 @@
+package crypto
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+)
+
+var ErrInvalidX25519Key = errors.New("invalid key for X25519 key agreement")
+
+// SharedKeySize is the length of a key from DeriveSharedKey, the key size
+// of PASETO v4 local
+const SharedKeySize = 32
+
+// curve25519P is the field prime 2^255 - 19 shared by Ed25519 and X25519
+var curve25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// GenerateX25519Keypair creates a new X25519 key agreement keypair
+func GenerateX25519Keypair() (*ecdh.PublicKey, *ecdh.PrivateKey, error) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return priv.PublicKey(), priv, nil
+}
+
+// X25519PrivateKeyFromEd25519 converts an Ed25519 private key to the X25519
+// key with the same secret scalar, so a DID's signing key can also agree
+// keys. Its public key is X25519PublicKeyFromEd25519 of the Ed25519 public key.
+func X25519PrivateKeyFromEd25519(priv ed25519.PrivateKey) (*ecdh.PrivateKey, error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, ErrInvalidX25519Key
+	}
+	// The Ed25519 scalar is the first half of SHA-512 of the seed; X25519
+	// clamps it the same way
+	h := sha512.Sum512(priv.Seed())
+	defer clear(h[:])
+	return ecdh.X25519().NewPrivateKey(h[:32])
+}
+
+// X25519PublicKeyFromEd25519 converts an Ed25519 public key, e.g. one
+// resolved from a did:key, to its X25519 form: the birational map from the
+// Edwards y-coordinate to the Montgomery u = (1 + y) / (1 - y)
+func X25519PublicKeyFromEd25519(pub ed25519.PublicKey) (*ecdh.PublicKey, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, ErrInvalidX25519Key
+	}
+
+	// y is little-endian with the sign of x in the top bit
+	be := make([]byte, len(pub))
+	for i, b := range pub {
+		be[len(pub)-1-i] = b
+	}
+	be[0] &= 0x7f
+	y := new(big.Int).SetBytes(be)
+	if y.Cmp(curve25519P) >= 0 {
+		return nil, ErrInvalidX25519Key
+	}
+
+	oneMinusY := new(big.Int).Sub(big.NewInt(1), y)
+	oneMinusY.Mod(oneMinusY, curve25519P)
+	if oneMinusY.Sign() == 0 {
+		// y = 1 is the identity point, which has no Montgomery form
+		return nil, ErrInvalidX25519Key
+	}
+	u := new(big.Int).Add(big.NewInt(1), y)
+	u.Mul(u, new(big.Int).ModInverse(oneMinusY, curve25519P))
+	u.Mod(u, curve25519P)
+
+	out := make([]byte, 32)
+	u.FillBytes(out)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return ecdh.X25519().NewPublicKey(out)
+}
+
+// DeriveSharedKey agrees a SharedKeySize symmetric key by X25519 between
+// priv and peer, expanded with HKDF-SHA256 under info. Each side derives the
+// same key from its own private key and the other's public key, so info must
+// not depend on which side computes it. A low-order peer key returns
+// ErrInvalidX25519Key.
+func DeriveSharedKey(priv *ecdh.PrivateKey, peer *ecdh.PublicKey, info string) ([]byte, error) {
+	secret, err := priv.ECDH(peer)
+	if err != nil {
+		return nil, ErrInvalidX25519Key
+	}
+	defer clear(secret)
+	return hkdf.Key(sha256.New, secret, nil, info, SharedKeySize)
+}
@@ -0,0 +1,68 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestX25519FromEd25519(t *testing.T) {
+	pub, priv, _ := GenerateEd25519Keypair()
+
+	xPriv, err := X25519PrivateKeyFromEd25519(priv)
+	if err != nil {
+		t.Fatalf("X25519PrivateKeyFromEd25519() error = %v", err)
+	}
+	xPub, err := X25519PublicKeyFromEd25519(pub)
+	if err != nil {
+		t.Fatalf("X25519PublicKeyFromEd25519() error = %v", err)
+	}
+
+	// Both conversions land on the same X25519 keypair
+	if !xPriv.PublicKey().Equal(xPub) {
+		t.Error("converted public key does not match the converted private key")
+	}
+
+	if _, err := X25519PrivateKeyFromEd25519(priv[:10]); err != ErrInvalidX25519Key {
+		t.Errorf("X25519PrivateKeyFromEd25519(short) error = %v, want %v", err, ErrInvalidX25519Key)
+	}
+	if _, err := X25519PublicKeyFromEd25519(pub[:10]); err != ErrInvalidX25519Key {
+		t.Errorf("X25519PublicKeyFromEd25519(short) error = %v, want %v", err, ErrInvalidX25519Key)
+	}
+
+	// The identity point (y = 1) has no Montgomery form
+	identity := make(ed25519.PublicKey, ed25519.PublicKeySize)
+	identity[0] = 1
+	if _, err := X25519PublicKeyFromEd25519(identity); err != ErrInvalidX25519Key {
+		t.Errorf("X25519PublicKeyFromEd25519(identity) error = %v, want %v", err, ErrInvalidX25519Key)
+	}
+}
+
+func TestDeriveSharedKey(t *testing.T) {
+	alicePub, alicePriv, err := GenerateX25519Keypair()
+	if err != nil {
+		t.Fatalf("GenerateX25519Keypair() error = %v", err)
+	}
+	edPub, edPriv, _ := GenerateEd25519Keypair()
+	bobPub, _ := X25519PublicKeyFromEd25519(edPub)
+	bobPriv, _ := X25519PrivateKeyFromEd25519(edPriv)
+
+	fromAlice, err := DeriveSharedKey(alicePriv, bobPub, "context")
+	if err != nil {
+		t.Fatalf("DeriveSharedKey() error = %v", err)
+	}
+	fromBob, err := DeriveSharedKey(bobPriv, alicePub, "context")
+	if err != nil {
+		t.Fatalf("DeriveSharedKey() error = %v", err)
+	}
+	if len(fromAlice) != SharedKeySize {
+		t.Errorf("DeriveSharedKey() length = %d, want %d", len(fromAlice), SharedKeySize)
+	}
+	if !bytes.Equal(fromAlice, fromBob) {
+		t.Error("DeriveSharedKey() differs between the two sides")
+	}
+
+	if other, _ := DeriveSharedKey(alicePriv, bobPub, "other context"); bytes.Equal(other, fromAlice) {
+		t.Error("DeriveSharedKey() with different info gave the same key")
+	}
+}
@@ -1,21 +1,47 @@
 package did
 
 import (
+	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/elliptic"
 	"encoding/json"
+	"errors"
 	"fmt"
 
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/mr-tron/base58"
 )
 
+// KeyType identifies the key algorithm behind a did:key
+type KeyType string
+
+const (
+	KeyTypeEd25519   KeyType = "Ed25519"
+	KeyTypeSecp256k1 KeyType = "secp256k1"
+	KeyTypeP256      KeyType = "P-256"
+)
+
+var ErrInvalidPublicKey = errors.New("invalid public key")
+
 // Multicodec prefix for Ed25519 public key (0xed01)
 var ed25519Multicodec = []byte{0xed, 0x01}
 
+// Multicodec prefix for secp256k1 public key (0xe701)
+var secp256k1Multicodec = []byte{0xe7, 0x01}
+
+// Multicodec prefix for P-256 public key (0x1200, varint-encoded)
+var p256Multicodec = []byte{0x80, 0x24}
+
 // DIDKey represents a did:key identifier
 type DIDKey struct {
-	DID         string
-	PublicKey   ed25519.PublicKey
-	DIDDocument DIDDocument
+	DID     string
+	KeyType KeyType
+	// PublicKey is only set for Ed25519 keys
+	PublicKey ed25519.PublicKey
+	// RawPublicKey holds the encoded key for any key type
+	// (compressed SEC1 point for secp256k1 and P-256)
+	RawPublicKey []byte
+	DIDDocument  DIDDocument
 }
 
 // DIDDocument is a minimal DID Document for did:key
@@ -32,12 +58,55 @@ type VerificationMethod struct {
 	Type            string `json:"type"`
 	Controller      string `json:"controller"`
 	PublicKeyBase58 string `json:"publicKeyBase58"`
+	// PreviousKey is the ID of the verification method this one replaced
+	// when the controller rotated its key
+	PreviousKey string `json:"previousKey,omitempty"`
 }
 
 // CreateDIDKey generates a did:key from an Ed25519 public key
 func CreateDIDKey(pub ed25519.PublicKey) (*DIDKey, error) {
-	// 1. Prefix public key with multicodec
-	prefixedKey := append(ed25519Multicodec, pub...)
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, ErrInvalidPublicKey
+	}
+
+	didKey := newDIDKey(KeyTypeEd25519, ed25519Multicodec, pub, "Ed25519VerificationKey2018")
+	didKey.PublicKey = pub
+	return didKey, nil
+}
+
+// CreateDIDKeySecp256k1 generates a did:key from a secp256k1 public key.
+// The key may be given in compressed or uncompressed SEC1 form; the DID
+// always encodes the compressed point.
+func CreateDIDKeySecp256k1(pub []byte) (*DIDKey, error) {
+	key, err := secp256k1.ParsePubKey(pub)
+	if err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+
+	return newDIDKey(KeyTypeSecp256k1, secp256k1Multicodec, key.SerializeCompressed(), "EcdsaSecp256k1VerificationKey2019"), nil
+}
+
+// CreateDIDKeyP256 generates a did:key from an ECDSA P-256 public key, e.g.
+// one produced by a WebAuthn authenticator. The DID encodes the compressed point.
+func CreateDIDKeyP256(pub *ecdsa.PublicKey) (*DIDKey, error) {
+	if pub == nil || pub.Curve != elliptic.P256() {
+		return nil, ErrInvalidPublicKey
+	}
+	// ECDH conversion rejects points that are not on the curve
+	if _, err := pub.ECDH(); err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+
+	compressed := elliptic.MarshalCompressed(elliptic.P256(), pub.X, pub.Y)
+	return newDIDKey(KeyTypeP256, p256Multicodec, compressed, "EcdsaSecp256r1VerificationKey2019"), nil
+}
+
+// newDIDKey builds the DID and DID Document for a multicodec-prefixed key
+func newDIDKey(keyType KeyType, multicodec, pub []byte, vmType string) *DIDKey {
+	// 1. Prefix public key with multicodec (fresh buffer so the shared prefix is never aliased)
+	prefixedKey := make([]byte, 0, len(multicodec)+len(pub))
+	prefixedKey = append(prefixedKey, multicodec...)
+	prefixedKey = append(prefixedKey, pub...)
 
 	// 2. Multibase encode (base58btc)
 	encoded := "z" + base58.Encode(prefixedKey)
@@ -53,7 +122,7 @@ func CreateDIDKey(pub ed25519.PublicKey) (*DIDKey, error) {
 		VerificationMethod: []VerificationMethod{
 			{
 				ID:              vmID,
-				Type:            "Ed25519VerificationKey2018",
+				Type:            vmType,
 				Controller:      did,
 				PublicKeyBase58: base58.Encode(pub),
 			},
@@ -63,10 +132,11 @@ func CreateDIDKey(pub ed25519.PublicKey) (*DIDKey, error) {
 	}
 
 	return &DIDKey{
-		DID:         did,
-		PublicKey:   pub,
-		DIDDocument: doc,
-	}, nil
+		DID:          did,
+		KeyType:      keyType,
+		RawPublicKey: pub,
+		DIDDocument:  doc,
+	}
 }
 
 // PrettyPrint returns the DID Document as formatted JSON
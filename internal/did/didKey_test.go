@@ -1,11 +1,18 @@
 package did
 
 import (
+	"bytes"
+	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"encoding/json"
 	"strings"
+	"sync"
 	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/mr-tron/base58"
 )
 
 func TestCreateDIDKey(t *testing.T) {
@@ -51,3 +58,151 @@ func TestPrettyPrint(t *testing.T) {
 		t.Errorf("JSON ID mismatch. Expected %s, got %s", didKey.DID, doc.ID)
 	}
 }
+
+func TestCreateDIDKeyConcurrent(t *testing.T) {
+	const workers = 64
+
+	var wg sync.WaitGroup
+	errs := make(chan string, workers)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			pub, _, err := ed25519.GenerateKey(rand.Reader)
+			if err != nil {
+				errs <- err.Error()
+				return
+			}
+
+			didKey, err := CreateDIDKey(pub)
+			if err != nil {
+				errs <- err.Error()
+				return
+			}
+
+			decoded, err := base58.Decode(strings.TrimPrefix(didKey.DID, "did:key:z"))
+			if err != nil {
+				errs <- err.Error()
+				return
+			}
+
+			if !bytes.Equal(decoded[:2], []byte{0xed, 0x01}) {
+				errs <- "multicodec prefix corrupted"
+				return
+			}
+
+			if !bytes.Equal(decoded[2:], pub) {
+				errs <- "DID does not decode back to the original key"
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for e := range errs {
+		t.Error(e)
+	}
+
+	if !bytes.Equal(ed25519Multicodec, []byte{0xed, 0x01}) {
+		t.Errorf("Shared multicodec prefix was modified: %x", ed25519Multicodec)
+	}
+}
+
+func TestCreateDIDKeySecp256k1(t *testing.T) {
+	priv, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	pub := priv.PubKey()
+
+	// Compressed and uncompressed forms produce the same DID
+	fromCompressed, err := CreateDIDKeySecp256k1(pub.SerializeCompressed())
+	if err != nil {
+		t.Fatalf("CreateDIDKeySecp256k1 failed: %v", err)
+	}
+	fromUncompressed, err := CreateDIDKeySecp256k1(pub.SerializeUncompressed())
+	if err != nil {
+		t.Fatalf("CreateDIDKeySecp256k1 (uncompressed) failed: %v", err)
+	}
+	if fromCompressed.DID != fromUncompressed.DID {
+		t.Errorf("DID mismatch between key encodings: %s vs %s", fromCompressed.DID, fromUncompressed.DID)
+	}
+
+	if fromCompressed.KeyType != KeyTypeSecp256k1 {
+		t.Errorf("Expected key type %s, got %s", KeyTypeSecp256k1, fromCompressed.KeyType)
+	}
+
+	if fromCompressed.PublicKey != nil {
+		t.Error("Ed25519 PublicKey should not be set for secp256k1 keys")
+	}
+
+	decoded, err := base58.Decode(strings.TrimPrefix(fromCompressed.DID, "did:key:z"))
+	if err != nil {
+		t.Fatalf("Failed to decode DID: %v", err)
+	}
+	if !bytes.Equal(decoded[:2], []byte{0xe7, 0x01}) {
+		t.Errorf("Expected secp256k1 multicodec prefix, got %x", decoded[:2])
+	}
+
+	vm := fromCompressed.DIDDocument.VerificationMethod[0]
+	if vm.Type != "EcdsaSecp256k1VerificationKey2019" {
+		t.Errorf("Unexpected verification method type: %s", vm.Type)
+	}
+}
+
+func TestCreateDIDKeyP256(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	didKey, err := CreateDIDKeyP256(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("CreateDIDKeyP256 failed: %v", err)
+	}
+
+	// P-256 did:keys always start with zDn
+	if !strings.HasPrefix(didKey.DID, "did:key:zDn") {
+		t.Errorf("Expected a did:key:zDn DID, got %s", didKey.DID)
+	}
+	if didKey.KeyType != KeyTypeP256 {
+		t.Errorf("Expected key type %s, got %s", KeyTypeP256, didKey.KeyType)
+	}
+
+	decoded, err := base58.Decode(strings.TrimPrefix(didKey.DID, "did:key:z"))
+	if err != nil {
+		t.Fatalf("Failed to decode DID: %v", err)
+	}
+	if !bytes.Equal(decoded[:2], []byte{0x80, 0x24}) {
+		t.Errorf("Expected P-256 multicodec prefix, got %x", decoded[:2])
+	}
+	if len(decoded[2:]) != 33 {
+		t.Errorf("Expected a 33-byte compressed point, got %d bytes", len(decoded[2:]))
+	}
+
+	vm := didKey.DIDDocument.VerificationMethod[0]
+	if vm.Type != "EcdsaSecp256r1VerificationKey2019" {
+		t.Errorf("Unexpected verification method type: %s", vm.Type)
+	}
+}
+
+func TestCreateDIDKeyInvalidKeys(t *testing.T) {
+	if _, err := CreateDIDKey(make([]byte, 16)); err != ErrInvalidPublicKey {
+		t.Errorf("Expected ErrInvalidPublicKey for short Ed25519 key, got %v", err)
+	}
+
+	if _, err := CreateDIDKeySecp256k1(make([]byte, 33)); err != ErrInvalidPublicKey {
+		t.Errorf("Expected ErrInvalidPublicKey for invalid secp256k1 point, got %v", err)
+	}
+
+	otherCurve, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if _, err := CreateDIDKeyP256(&otherCurve.PublicKey); err != ErrInvalidPublicKey {
+		t.Errorf("Expected ErrInvalidPublicKey for a P-384 key, got %v", err)
+	}
+	if _, err := CreateDIDKeyP256(nil); err != ErrInvalidPublicKey {
+		t.Errorf("Expected ErrInvalidPublicKey for a nil key, got %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package did
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrInvalidDomain = errors.New("invalid did:web domain")
+	ErrInvalidPath   = errors.New("invalid did:web path")
+)
+
+// WellKnownDIDPath is where a did:web DID without path segments publishes
+// its DID document
+const WellKnownDIDPath = "/.well-known/did.json"
+
+// DIDWeb represents a did:web identifier together with the DID document the
+// domain must publish for it
+type DIDWeb struct {
+	DID string
+	// Domain is the host, with any port, that serves the document
+	Domain string
+	// DocumentPath is the URL path of the document on Domain:
+	// WellKnownDIDPath, or /<path>/did.json for a DID with path segments
+	DocumentPath string
+	DIDDocument  DIDDocument
+}
+
+// WebDID returns the canonical did:web identifier of a domain and optional
+// path, e.g. ("issuer.example:8443", "issuers/acme") gives
+// did:web:issuer.example%3A8443:issuers:acme
+func WebDID(domain, path string) (string, error) {
+	host, err := canonicalWebDomain(domain)
+	if err != nil {
+		return "", err
+	}
+	segments, err := webPathSegments(path)
+	if err != nil {
+		return "", err
+	}
+
+	parts := []string{"did:web", webEscape(host)}
+	for _, s := range segments {
+		parts = append(parts, webEscape(s))
+	}
+	return strings.Join(parts, ":"), nil
+}
+
+// CreateDIDWeb builds the did:web identity of a domain and optional path
+// whose key is the key of a did:key. The document has the same single
+// verification method as the did:key document, renamed under the did:web DID.
+func CreateDIDWeb(domain, path string, key *DIDKey) (*DIDWeb, error) {
+	if key == nil || len(key.DIDDocument.VerificationMethod) == 0 {
+		return nil, ErrInvalidPublicKey
+	}
+	didStr, err := WebDID(domain, path)
+	if err != nil {
+		return nil, err
+	}
+	host, _ := canonicalWebDomain(domain)
+	segments, _ := webPathSegments(path)
+
+	documentPath := WellKnownDIDPath
+	if len(segments) > 0 {
+		escaped := make([]string, len(segments))
+		for i, s := range segments {
+			escaped[i] = url.PathEscape(s)
+		}
+		documentPath = "/" + strings.Join(escaped, "/") + "/did.json"
+	}
+
+	source := key.DIDDocument.VerificationMethod[0]
+	vmID := didStr + "#key-1"
+	doc := DIDDocument{
+		Context: []string{
+			"https://www.w3.org/ns/did/v1",
+		},
+		ID: didStr,
+		VerificationMethod: []VerificationMethod{
+			{
+				ID:              vmID,
+				Type:            source.Type,
+				Controller:      didStr,
+				PublicKeyBase58: source.PublicKeyBase58,
+			},
+		},
+		Authentication:  []string{vmID},
+		AssertionMethod: []string{vmID},
+	}
+
+	return &DIDWeb{
+		DID:          didStr,
+		Domain:       host,
+		DocumentPath: documentPath,
+		DIDDocument:  doc,
+	}, nil
+}
+
+// CreateDIDWebEd25519 builds the did:web identity of a domain and optional
+// path for an Ed25519 public key
+func CreateDIDWebEd25519(domain, path string, pub ed25519.PublicKey) (*DIDWeb, error) {
+	key, err := CreateDIDKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return CreateDIDWeb(domain, path, key)
+}
+
+// RotateKey makes key the identity's signing key. The new verification
+// method replaces the current assertion method for authentication and
+// assertion and links back to it through PreviousKey. The old method stays
+// in the document so credentials it signed still verify; remove it to
+// retire the key outright, e.g. after a compromise. Handlers created before
+// the rotation keep serving the old document.
+func (d *DIDWeb) RotateKey(key *DIDKey) error {
+	if key == nil || len(key.DIDDocument.VerificationMethod) == 0 {
+		return ErrInvalidPublicKey
+	}
+
+	previous := ""
+	if len(d.DIDDocument.AssertionMethod) > 0 {
+		previous = d.DIDDocument.AssertionMethod[0]
+	}
+	vmID := d.nextKeyID()
+	source := key.DIDDocument.VerificationMethod[0]
+	d.DIDDocument.VerificationMethod = append(d.DIDDocument.VerificationMethod, VerificationMethod{
+		ID:              vmID,
+		Type:            source.Type,
+		Controller:      d.DID,
+		PublicKeyBase58: source.PublicKeyBase58,
+		PreviousKey:     previous,
+	})
+	d.DIDDocument.Authentication = []string{vmID}
+	d.DIDDocument.AssertionMethod = []string{vmID}
+	return nil
+}
+
+// nextKeyID returns the first #key-N verification method ID not in use
+func (d *DIDWeb) nextKeyID() string {
+	for n := len(d.DIDDocument.VerificationMethod) + 1; ; n++ {
+		id := fmt.Sprintf("%s#key-%d", d.DID, n)
+		taken := false
+		for _, vm := range d.DIDDocument.VerificationMethod {
+			taken = taken || vm.ID == id
+		}
+		if !taken {
+			return id
+		}
+	}
+}
+
+// DocumentURL returns the HTTPS URL a resolver fetches the document from
+func (d *DIDWeb) DocumentURL() string {
+	return "https://" + d.Domain + d.DocumentPath
+}
+
+// PrettyPrint returns the DID Document as formatted JSON, ready to publish
+// at DocumentPath
+func (d *DIDWeb) PrettyPrint() (string, error) {
+	b, err := json.MarshalIndent(d.DIDDocument, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Handler returns an http.Handler that serves the DID document at
+// DocumentPath and 404 for every other path. It can be mounted on the
+// domain's server mux as is.
+func (d *DIDWeb) Handler() http.Handler {
+	body, err := json.Marshal(d.DIDDocument)
+	documentPath := d.DocumentPath
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != documentPath {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err != nil {
+			http.Error(w, "DID document unavailable", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/did+json")
+		w.Write(body)
+	})
+}
+
+// canonicalWebDomain validates a host with an optional port and lowercases it
+func canonicalWebDomain(domain string) (string, error) {
+	if domain == "" || strings.ContainsAny(domain, "/?#@[]% ") {
+		return "", ErrInvalidDomain
+	}
+	u, err := url.Parse("https://" + domain)
+	if err != nil || u.Host != domain || u.Hostname() == "" {
+		return "", ErrInvalidDomain
+	}
+	return strings.ToLower(domain), nil
+}
+
+// webPathSegments splits a slash-separated path into its segments. Leading
+// and trailing slashes are ignored; empty, "." and ".." segments are not.
+func webPathSegments(path string) ([]string, error) {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil, nil
+	}
+	segments := strings.Split(path, "/")
+	for _, s := range segments {
+		if s == "" || s == "." || s == ".." {
+			return nil, ErrInvalidPath
+		}
+	}
+	return segments, nil
+}
+
+// webEscape percent-encodes a did:web component. Colons separate the
+// components, so they are encoded too.
+func webEscape(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), ":", "%3A")
+}
@@ -0,0 +1,155 @@
+package did
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebDID(t *testing.T) {
+	tests := []struct {
+		domain, path string
+		want         string
+	}{
+		{"issuer.example", "", "did:web:issuer.example"},
+		{"Issuer.Example", "/", "did:web:issuer.example"},
+		{"issuer.example:8443", "", "did:web:issuer.example%3A8443"},
+		{"issuer.example", "/issuers/acme/", "did:web:issuer.example:issuers:acme"},
+		{"issuer.example", "a b/c:d", "did:web:issuer.example:a%20b:c%3Ad"},
+	}
+	for _, tt := range tests {
+		got, err := WebDID(tt.domain, tt.path)
+		if err != nil {
+			t.Errorf("WebDID(%q, %q) failed: %v", tt.domain, tt.path, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Expected %s, got %s", tt.want, got)
+		}
+	}
+
+	for _, domain := range []string{"", "https://issuer.example", "issuer.example/path", "user@issuer.example", ":8443"} {
+		if _, err := WebDID(domain, ""); err != ErrInvalidDomain {
+			t.Errorf("Expected ErrInvalidDomain for %q, got %v", domain, err)
+		}
+	}
+	for _, path := range []string{"a//b", "a/../b", "./a"} {
+		if _, err := WebDID("issuer.example", path); err != ErrInvalidPath {
+			t.Errorf("Expected ErrInvalidPath for %q, got %v", path, err)
+		}
+	}
+}
+
+func TestCreateDIDWeb(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	key, _ := CreateDIDKey(pub)
+
+	web, err := CreateDIDWebEd25519("issuer.example:8443", "issuers/acme", pub)
+	if err != nil {
+		t.Fatalf("CreateDIDWebEd25519 failed: %v", err)
+	}
+	if web.DID != "did:web:issuer.example%3A8443:issuers:acme" {
+		t.Errorf("Unexpected DID %s", web.DID)
+	}
+	if web.DocumentURL() != "https://issuer.example:8443/issuers/acme/did.json" {
+		t.Errorf("Unexpected document URL %s", web.DocumentURL())
+	}
+
+	doc := web.DIDDocument
+	if doc.ID != web.DID || len(doc.VerificationMethod) != 1 {
+		t.Fatalf("Expected one verification method for %s, got %+v", web.DID, doc)
+	}
+	vm := doc.VerificationMethod[0]
+	if vm.ID != web.DID+"#key-1" || vm.Controller != web.DID {
+		t.Errorf("Expected method %s#key-1 controlled by the DID, got %+v", web.DID, vm)
+	}
+	if vm.PublicKeyBase58 != key.DIDDocument.VerificationMethod[0].PublicKeyBase58 || vm.Type != "Ed25519VerificationKey2018" {
+		t.Errorf("Expected the did:key verification method key, got %+v", vm)
+	}
+	if len(doc.AssertionMethod) != 1 || doc.AssertionMethod[0] != vm.ID {
+		t.Errorf("Expected assertion method %s, got %v", vm.ID, doc.AssertionMethod)
+	}
+
+	root, _ := CreateDIDWeb("issuer.example", "", key)
+	if root.DocumentPath != WellKnownDIDPath {
+		t.Errorf("Expected document path %s, got %s", WellKnownDIDPath, root.DocumentPath)
+	}
+
+	if _, err := CreateDIDWebEd25519("issuer.example", "", pub[:10]); err != ErrInvalidPublicKey {
+		t.Errorf("Expected ErrInvalidPublicKey, got %v", err)
+	}
+}
+
+func TestDIDWebHandler(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	web, _ := CreateDIDWebEd25519("issuer.example", "", pub)
+	handler := web.Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, WellKnownDIDPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/did+json" {
+		t.Errorf("Expected content type application/did+json, got %s", ct)
+	}
+	var doc DIDDocument
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to decode document: %v", err)
+	}
+	if doc.ID != web.DID {
+		t.Errorf("Expected document for %s, got %s", web.DID, doc.ID)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/did.json", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for another path, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, WellKnownDIDPath, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for POST, got %d", rec.Code)
+	}
+}
+
+func TestDIDWebRotateKey(t *testing.T) {
+	oldPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	newPub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	web, err := CreateDIDWebEd25519("issuer.example", "", oldPub)
+	if err != nil {
+		t.Fatalf("CreateDIDWebEd25519 failed: %v", err)
+	}
+	newKey, _ := CreateDIDKey(newPub)
+	if err := web.RotateKey(newKey); err != nil {
+		t.Fatalf("RotateKey failed: %v", err)
+	}
+
+	doc := web.DIDDocument
+	if len(doc.VerificationMethod) != 2 {
+		t.Fatalf("Expected both keys in the document, got %d", len(doc.VerificationMethod))
+	}
+	rotated := doc.VerificationMethod[1]
+	if rotated.ID != web.DID+"#key-2" || rotated.Controller != web.DID || rotated.PreviousKey != web.DID+"#key-1" {
+		t.Errorf("Unexpected rotated verification method %+v", rotated)
+	}
+	if len(doc.AssertionMethod) != 1 || doc.AssertionMethod[0] != rotated.ID || doc.Authentication[0] != rotated.ID {
+		t.Errorf("Expected only the new key to be used, got %v and %v", doc.AssertionMethod, doc.Authentication)
+	}
+
+	// The link survives publishing
+	published, _ := web.PrettyPrint()
+	var decoded DIDDocument
+	if err := json.Unmarshal([]byte(published), &decoded); err != nil || decoded.VerificationMethod[1].PreviousKey != rotated.PreviousKey {
+		t.Errorf("Expected previousKey in the published document, got %v", err)
+	}
+
+	if err := web.RotateKey(nil); err != ErrInvalidPublicKey {
+		t.Errorf("Expected ErrInvalidPublicKey, got %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+// Package httpclient wraps an HTTP client with retries, exponential backoff
+// and per-host rate limiting for the network-backed resolver and registry.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrBodyNotReplayable = errors.New("request body cannot be replayed for a retry")
+)
+
+// Defaults applied to zero-valued Options
+const (
+	DefaultMaxRetries = 3
+	DefaultBaseDelay  = 100 * time.Millisecond
+	DefaultMaxDelay   = 10 * time.Second
+)
+
+// Options configures a Client. The zero value retries DefaultMaxRetries
+// times with no rate limit.
+type Options struct {
+	// Client performs the requests; nil uses http.DefaultClient
+	Client *http.Client
+	// MaxRetries is how many times a failed request is retried; a negative
+	// value disables retries
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry, doubling for each
+	// retry after it up to MaxDelay. Each delay is jittered down by up to half.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// RequestsPerSecond limits the rate of requests, retries included, to
+	// each host; zero or less is unlimited
+	RequestsPerSecond float64
+}
+
+// Client sends requests, retrying network failures, 429 Too Many Requests
+// and 5xx responses. A Retry-After header on a 429 or 503 response replaces
+// the backoff delay, capped at MaxDelay. It is safe for concurrent use.
+type Client struct {
+	client     *http.Client
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	interval   time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time // earliest time each host may be sent a request
+
+	now func() time.Time
+}
+
+// NewClient creates a client from opts, filling in defaults
+func NewClient(opts Options) *Client {
+	c := &Client{
+		client:     opts.Client,
+		maxRetries: opts.MaxRetries,
+		baseDelay:  opts.BaseDelay,
+		maxDelay:   opts.MaxDelay,
+		next:       make(map[string]time.Time),
+		now:        time.Now,
+	}
+	if c.client == nil {
+		c.client = http.DefaultClient
+	}
+	switch {
+	case c.maxRetries == 0:
+		c.maxRetries = DefaultMaxRetries
+	case c.maxRetries < 0:
+		c.maxRetries = 0
+	}
+	if c.baseDelay <= 0 {
+		c.baseDelay = DefaultBaseDelay
+	}
+	if c.maxDelay <= 0 {
+		c.maxDelay = DefaultMaxDelay
+	}
+	if opts.RequestsPerSecond > 0 {
+		c.interval = time.Duration(float64(time.Second) / opts.RequestsPerSecond)
+	}
+	return c
+}
+
+// Do sends the request, retrying as configured. The request's context bounds
+// every attempt and every wait; once it is done Do returns its error. When
+// retries run out, the last response or error is returned as is. A request
+// with a body is only retried if it sets GetBody, as http.NewRequest does.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		if err := c.wait(ctx, req.URL.Host); err != nil {
+			return nil, err
+		}
+
+		attemptReq, err := c.attemptRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.client.Do(attemptReq)
+		if ctx.Err() != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, err
+		}
+		if attempt >= c.maxRetries || !retryable(resp, err) {
+			return resp, err
+		}
+
+		delay := c.backoff(attempt)
+		if resp != nil {
+			if d, ok := retryAfter(resp, c.now()); ok {
+				delay = min(d, c.maxDelay)
+			}
+			// Drain so the connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attemptRequest returns the request to send for an attempt, with a fresh body for retries
+func (c *Client) attemptRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, ErrBodyNotReplayable
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	retry := req.Clone(req.Context())
+	retry.Body = body
+	return retry, nil
+}
+
+// retryable reports whether an attempt failed in a way worth retrying
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusInternalServerError ||
+		resp.StatusCode == http.StatusBadGateway ||
+		resp.StatusCode == http.StatusServiceUnavailable ||
+		resp.StatusCode == http.StatusGatewayTimeout
+}
+
+// backoff returns the jittered exponential delay before retry attempt+1
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.baseDelay << attempt
+	if d <= 0 || d > c.maxDelay {
+		d = c.maxDelay
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// wait blocks until host may be sent another request under the rate limit
+func (c *Client) wait(ctx context.Context, host string) error {
+	if c.interval == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	now := c.now()
+	slot := c.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	c.next[host] = slot.Add(c.interval)
+	c.mu.Unlock()
+
+	return sleep(ctx, slot.Sub(now))
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,196 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFlakyServer answers the first failures requests with status, then 200
+func newFlakyServer(failures int32, status int, retryAfter string, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(hits, 1) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+		body, _ := io.ReadAll(req.Body)
+		w.Write(append([]byte("ok "), body...))
+	}))
+}
+
+func get(t *testing.T, c *Client, ctx context.Context, url string) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("NewRequest failed: %v", err)
+	}
+	return c.Do(req)
+}
+
+func TestClientRetriesTooManyRequests(t *testing.T) {
+	var hits int32
+	srv := newFlakyServer(1, http.StatusTooManyRequests, "0", &hits)
+	defer srv.Close()
+
+	c := NewClient(Options{BaseDelay: time.Millisecond})
+	resp, err := get(t, c, context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if hits != 2 {
+		t.Errorf("Expected 2 requests, got %d", hits)
+	}
+}
+
+func TestClientGivesUpAfterMaxRetries(t *testing.T) {
+	var hits int32
+	srv := newFlakyServer(100, http.StatusServiceUnavailable, "", &hits)
+	defer srv.Close()
+
+	c := NewClient(Options{MaxRetries: 2, BaseDelay: time.Millisecond})
+	resp, err := get(t, c, context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected the last status 503, got %d", resp.StatusCode)
+	}
+	if hits != 3 {
+		t.Errorf("Expected 3 requests, got %d", hits)
+	}
+}
+
+func TestClientDoesNotRetryClientErrors(t *testing.T) {
+	var hits int32
+	srv := newFlakyServer(100, http.StatusNotFound, "", &hits)
+	defer srv.Close()
+
+	c := NewClient(Options{BaseDelay: time.Millisecond})
+	resp, err := get(t, c, context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if hits != 1 {
+		t.Errorf("Expected 404 not to be retried, got %d requests", hits)
+	}
+
+	// Negative MaxRetries disables retries
+	hits = 0
+	srv5xx := newFlakyServer(100, http.StatusBadGateway, "", &hits)
+	defer srv5xx.Close()
+	resp, err = get(t, NewClient(Options{MaxRetries: -1}), context.Background(), srv5xx.URL)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	resp.Body.Close()
+	if hits != 1 {
+		t.Errorf("Expected no retries, got %d requests", hits)
+	}
+}
+
+func TestClientContextCancelsRetries(t *testing.T) {
+	var hits int32
+	srv := newFlakyServer(100, http.StatusTooManyRequests, "60", &hits)
+	defer srv.Close()
+
+	c := NewClient(Options{MaxDelay: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := get(t, c, ctx, srv.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected cancellation to stop the Retry-After wait, took %v", elapsed)
+	}
+	if hits != 1 {
+		t.Errorf("Expected 1 request before cancellation, got %d", hits)
+	}
+}
+
+func TestClientReplaysBody(t *testing.T) {
+	var hits int32
+	srv := newFlakyServer(1, http.StatusInternalServerError, "", &hits)
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("NewRequest failed: %v", err)
+	}
+	resp, err := NewClient(Options{BaseDelay: time.Millisecond}).Do(req)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "ok payload" {
+		t.Errorf("Expected the body to be resent, got %q", body)
+	}
+}
+
+func TestClientRateLimitsPerHost(t *testing.T) {
+	var hits int32
+	srv := newFlakyServer(0, 0, "", &hits)
+	defer srv.Close()
+
+	c := NewClient(Options{RequestsPerSecond: 50})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := get(t, c, context.Background(), srv.URL)
+		if err != nil {
+			t.Fatalf("Do failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// Three requests at 50/s are spaced by at least 20ms each
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected requests to be rate limited, took %v", elapsed)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		status int
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{http.StatusTooManyRequests, "3", 3 * time.Second, true},
+		{http.StatusServiceUnavailable, now.Add(time.Minute).Format(http.TimeFormat), time.Minute, true},
+		{http.StatusTooManyRequests, now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{http.StatusTooManyRequests, "soon", 0, false},
+		{http.StatusTooManyRequests, "-1", 0, false},
+		{http.StatusInternalServerError, "3", 0, false},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Header: http.Header{"Retry-After": []string{tt.header}}}
+		got, ok := retryAfter(resp, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%d, %q): expected %v %v, got %v %v", tt.status, tt.header, tt.want, tt.ok, got, ok)
+		}
+	}
+}
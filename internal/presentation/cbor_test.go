@@ -0,0 +1,87 @@
+package presentation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+	"github.com/veriglob/veriglob-core/internal/vc"
+)
+
+// typicalIdentityPresentation presents one identity credential, with both
+// the credential and the presentation in enc
+func typicalIdentityPresentation(t *testing.T, enc vc.Encoding) (token string, holderPub ed25519.PublicKey, issuerPub ed25519.PublicKey) {
+	t.Helper()
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	holderPub, holderPriv := generateTestKeypair(t)
+	issuer, _ := did.CreateDIDKey(issuerPub)
+	holder, _ := did.CreateDIDKey(holderPub)
+
+	cred, err := vc.IssueVCWithOptions(issuer.DID, holder.DID, issuerPriv, vc.IdentitySubject{
+		ID:           holder.DID,
+		GivenName:    "Alice",
+		FamilyName:   "Doe",
+		DateOfBirth:  "1990-01-01",
+		Nationality:  "NL",
+		DocumentType: "passport",
+		DocumentID:   "NX1234567",
+	}, vc.IssueOptions{CredentialID: "urn:uuid:3f8b2c1e-7d4a-4e6b-9c2f-1a5d8e7b6c40", Encoding: enc})
+	if err != nil {
+		t.Fatalf("IssueVCWithOptions failed: %v", err)
+	}
+
+	nonce, _ := GenerateNonce()
+	token, err = CreatePresentationWithOptions(holder.DID, holderPriv, []string{cred},
+		"did:web:verifier.example", nonce, CreateOptions{ValidateCredentials: true, Encoding: enc})
+	if err != nil {
+		t.Fatalf("CreatePresentationWithOptions failed: %v", err)
+	}
+	return token, holderPub, issuerPub
+}
+
+func TestCBORPresentation(t *testing.T) {
+	token, holderPub, issuerPub := typicalIdentityPresentation(t, vc.EncodingCBOR)
+
+	claims, err := VerifyPresentation(token, holderPub, "did:web:verifier.example", "")
+	if err != nil {
+		t.Fatalf("VerifyPresentation failed: %v", err)
+	}
+	if len(claims.VP.VerifiableCredential) != 1 {
+		t.Fatalf("Expected 1 credential, got %d", len(claims.VP.VerifiableCredential))
+	}
+	if claims.VP.Holder != claims.Issuer {
+		t.Errorf("Expected holder %s, got %s", claims.Issuer, claims.VP.Holder)
+	}
+
+	credClaims, err := vc.VerifyVC(claims.VP.VerifiableCredential[0], issuerPub)
+	if err != nil {
+		t.Fatalf("VerifyVC failed for the embedded credential: %v", err)
+	}
+	if credClaims.VC.ID != "urn:uuid:3f8b2c1e-7d4a-4e6b-9c2f-1a5d8e7b6c40" {
+		t.Errorf("Expected the credential ID, got %q", credClaims.VC.ID)
+	}
+
+	// A CBOR presentation is still not a credential
+	if _, err := vc.VerifyVC(token, holderPub); err != vc.ErrNotACredential {
+		t.Errorf("Expected ErrNotACredential, got %v", err)
+	}
+
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	_, err = CreatePresentationWithOptions("did:key:zHolder", priv, []string{"cred"}, "aud", "nonce",
+		CreateOptions{Encoding: "xml"})
+	if err == nil {
+		t.Error("Expected an error for an unsupported encoding")
+	}
+}
+
+func TestCBORPresentationSize(t *testing.T) {
+	jsonToken, _, _ := typicalIdentityPresentation(t, vc.EncodingJSON)
+	cborToken, _, _ := typicalIdentityPresentation(t, vc.EncodingCBOR)
+	t.Logf("identity presentation: JSON %d bytes, CBOR %d bytes (%.0f%% smaller)",
+		len(jsonToken), len(cborToken), 100*(1-float64(len(cborToken))/float64(len(jsonToken))))
+
+	if len(cborToken) >= len(jsonToken) {
+		t.Errorf("Expected the CBOR presentation to be smaller, got %d >= %d bytes", len(cborToken), len(jsonToken))
+	}
+}
@@ -0,0 +1,217 @@
+package presentation
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidCompactPayload = errors.New("invalid compact presentation payload")
+	ErrQRPayloadTooLarge     = errors.New("presentation too large for a QR code")
+	ErrInvalidQRFrames       = errors.New("invalid QR frame sequence")
+	ErrCompactTooLarge       = errors.New("compact presentation decompresses beyond the size limit")
+)
+
+// CompactPrefix marks a compact-encoded presentation
+const CompactPrefix = "VP1:"
+
+// MaxCompactTokenSize bounds the decompressed size of a compact payload.
+// zlib compresses repetitive input over a thousandfold, so a payload
+// scanned from a QR code could otherwise expand to exhaust memory.
+const MaxCompactTokenSize = 1 << 20
+
+// MaxQRAlphanumericLength is the capacity of a version 40 QR code in
+// alphanumeric mode at the lowest error correction level (L)
+const MaxQRAlphanumericLength = 4296
+
+// QRFramePrefix marks one frame of a compact payload split across several
+// QR codes, as QRFramePrefix + "<index>/<total>:" + chunk, index from 1
+const QRFramePrefix = "VPF:"
+
+// DefaultQRFrameLength is the chunk size SplitQRFrames uses when none is
+// given. It keeps each frame to a QR version that phones scan reliably.
+const DefaultQRFrameLength = 1000
+
+// base45Alphabet is the RFC 9285 alphabet, which is exactly the QR alphanumeric charset
+const base45Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// EncodeCompact zlib-compresses a presentation token and base45-encodes it
+// so it fits QR alphanumeric mode
+func EncodeCompact(token string) (string, error) {
+	var buf bytes.Buffer
+	zw, err := zlib.NewWriterLevel(&buf, zlib.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := zw.Write([]byte(token)); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+
+	return CompactPrefix + base45Encode(buf.Bytes()), nil
+}
+
+// DecodeCompact reverses EncodeCompact, returning the presentation token. A
+// payload that decompresses to more than MaxCompactTokenSize bytes returns
+// ErrCompactTooLarge.
+func DecodeCompact(payload string) (string, error) {
+	if !strings.HasPrefix(payload, CompactPrefix) {
+		return "", ErrInvalidCompactPayload
+	}
+
+	compressed, err := base45Decode(strings.TrimPrefix(payload, CompactPrefix))
+	if err != nil {
+		return "", err
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return "", ErrInvalidCompactPayload
+	}
+	defer zr.Close()
+
+	token, err := io.ReadAll(io.LimitReader(zr, MaxCompactTokenSize+1))
+	if err != nil {
+		return "", ErrInvalidCompactPayload
+	}
+	if len(token) > MaxCompactTokenSize {
+		return "", fmt.Errorf("%w: more than %d bytes", ErrCompactTooLarge, MaxCompactTokenSize)
+	}
+
+	return string(token), nil
+}
+
+// EncodeQRPayload compact-encodes a presentation token and checks it fits in a single QR code
+func EncodeQRPayload(token string) (string, error) {
+	payload, err := EncodeCompact(token)
+	if err != nil {
+		return "", err
+	}
+
+	if len(payload) > MaxQRAlphanumericLength {
+		return "", fmt.Errorf("%w: %d characters exceeds the %d character limit; present fewer credentials",
+			ErrQRPayloadTooLarge, len(payload), MaxQRAlphanumericLength)
+	}
+
+	return payload, nil
+}
+
+// SplitQRFrames splits a compact payload that is too large for one QR code
+// into frames of at most frameLength payload characters (zero uses
+// DefaultQRFrameLength). Every frame stays in the QR alphanumeric charset.
+// A payload that fits in one frame is still returned as a single frame.
+func SplitQRFrames(payload string, frameLength int) ([]string, error) {
+	if !strings.HasPrefix(payload, CompactPrefix) {
+		return nil, ErrInvalidCompactPayload
+	}
+	if frameLength <= 0 {
+		frameLength = DefaultQRFrameLength
+	}
+
+	total := (len(payload) + frameLength - 1) / frameLength
+	frames := make([]string, 0, total)
+	for i := 0; i < total; i++ {
+		end := min((i+1)*frameLength, len(payload))
+		frames = append(frames, fmt.Sprintf("%s%d/%d:%s", QRFramePrefix, i+1, total, payload[i*frameLength:end]))
+	}
+	return frames, nil
+}
+
+// JoinQRFrames reassembles the compact payload from frames produced by
+// SplitQRFrames, scanned in any order. A missing, duplicate, or foreign
+// frame returns ErrInvalidQRFrames.
+func JoinQRFrames(frames []string) (string, error) {
+	if len(frames) == 0 {
+		return "", ErrInvalidQRFrames
+	}
+
+	chunks := make([]string, len(frames))
+	for _, frame := range frames {
+		header, chunk, ok := strings.Cut(strings.TrimPrefix(frame, QRFramePrefix), ":")
+		if !ok || !strings.HasPrefix(frame, QRFramePrefix) {
+			return "", ErrInvalidQRFrames
+		}
+		indexStr, totalStr, ok := strings.Cut(header, "/")
+		if !ok {
+			return "", ErrInvalidQRFrames
+		}
+		index, err := strconv.Atoi(indexStr)
+		if err != nil {
+			return "", ErrInvalidQRFrames
+		}
+		total, err := strconv.Atoi(totalStr)
+		if err != nil || total != len(frames) || index < 1 || index > total || chunks[index-1] != "" || chunk == "" {
+			return "", ErrInvalidQRFrames
+		}
+		chunks[index-1] = chunk
+	}
+
+	payload := strings.Join(chunks, "")
+	if !strings.HasPrefix(payload, CompactPrefix) {
+		return "", ErrInvalidQRFrames
+	}
+	return payload, nil
+}
+
+// base45Encode encodes data per RFC 9285
+func base45Encode(data []byte) string {
+	var sb strings.Builder
+	sb.Grow((len(data)/2)*3 + 2)
+
+	for i := 0; i+1 < len(data); i += 2 {
+		n := int(data[i])*256 + int(data[i+1])
+		sb.WriteByte(base45Alphabet[n%45])
+		sb.WriteByte(base45Alphabet[(n/45)%45])
+		sb.WriteByte(base45Alphabet[n/2025])
+	}
+
+	if len(data)%2 == 1 {
+		n := int(data[len(data)-1])
+		sb.WriteByte(base45Alphabet[n%45])
+		sb.WriteByte(base45Alphabet[n/45])
+	}
+
+	return sb.String()
+}
+
+// base45Decode decodes data per RFC 9285
+func base45Decode(s string) ([]byte, error) {
+	if len(s)%3 == 1 {
+		return nil, ErrInvalidCompactPayload
+	}
+
+	values := make([]int, len(s))
+	for i := 0; i < len(s); i++ {
+		v := strings.IndexByte(base45Alphabet, s[i])
+		if v < 0 {
+			return nil, ErrInvalidCompactPayload
+		}
+		values[i] = v
+	}
+
+	out := make([]byte, 0, (len(s)/3)*2+1)
+	for i := 0; i < len(values); i += 3 {
+		if i+2 < len(values) {
+			n := values[i] + values[i+1]*45 + values[i+2]*2025
+			if n > 0xffff {
+				return nil, ErrInvalidCompactPayload
+			}
+			out = append(out, byte(n>>8), byte(n))
+		} else {
+			n := values[i] + values[i+1]*45
+			if n > 0xff {
+				return nil, ErrInvalidCompactPayload
+			}
+			out = append(out, byte(n))
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,207 @@
+package presentation
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBase45RFCVectors(t *testing.T) {
+	// Test vectors from RFC 9285
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"AB", "BB8"},
+		{"Hello!!", "%69 VD92EX0"},
+		{"base-45", "UJCLQE7W581"},
+		{"ietf!", "QED8WEX0"},
+	}
+
+	for _, tt := range tests {
+		if got := base45Encode([]byte(tt.in)); got != tt.out {
+			t.Errorf("base45Encode(%q) = %q, want %q", tt.in, got, tt.out)
+		}
+		got, err := base45Decode(tt.out)
+		if err != nil {
+			t.Fatalf("base45Decode(%q) error: %v", tt.out, err)
+		}
+		if !bytes.Equal(got, []byte(tt.in)) {
+			t.Errorf("base45Decode(%q) = %q, want %q", tt.out, got, tt.in)
+		}
+	}
+}
+
+func TestCompactRoundTrip(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+
+	token, err := CreatePresentation("did:key:holder", priv, []string{"v4.public.credential"}, "aud", "nonce")
+	if err != nil {
+		t.Fatalf("Failed to create presentation: %v", err)
+	}
+
+	payload, err := EncodeQRPayload(token)
+	if err != nil {
+		t.Fatalf("Failed to encode QR payload: %v", err)
+	}
+
+	if !strings.HasPrefix(payload, CompactPrefix) {
+		t.Errorf("Payload should start with %s", CompactPrefix)
+	}
+
+	// Payload must only use the QR alphanumeric charset
+	for _, c := range payload {
+		if !strings.ContainsRune(base45Alphabet, c) {
+			t.Fatalf("Payload contains non-alphanumeric character %q", c)
+		}
+	}
+
+	decoded, err := DecodeCompact(payload)
+	if err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+
+	if decoded != token {
+		t.Error("Decoded token does not match original")
+	}
+
+	if _, err := VerifyPresentation(decoded, pub, "aud", "nonce"); err != nil {
+		t.Errorf("Decoded presentation failed verification: %v", err)
+	}
+}
+
+func TestDecodeCompactInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"not-a-payload",
+		CompactPrefix + "abc", // lowercase is outside the charset
+		CompactPrefix + "A",   // dangling single character
+		CompactPrefix + "GGW", // exceeds 16 bits
+		CompactPrefix + "BB8", // valid base45 but not zlib
+	}
+
+	for _, payload := range tests {
+		if _, err := DecodeCompact(payload); err != ErrInvalidCompactPayload {
+			t.Errorf("DecodeCompact(%q) expected ErrInvalidCompactPayload, got %v", payload, err)
+		}
+	}
+}
+
+func TestDecodeCompactSizeLimit(t *testing.T) {
+	// Zeros compress to almost nothing, like a decompression bomb
+	atLimit, _ := EncodeCompact(strings.Repeat("0", MaxCompactTokenSize))
+	token, err := DecodeCompact(atLimit)
+	if err != nil || len(token) != MaxCompactTokenSize {
+		t.Errorf("Expected a token of exactly the limit to decode, got %d bytes, %v", len(token), err)
+	}
+
+	bomb, _ := EncodeCompact(strings.Repeat("0", MaxCompactTokenSize+1))
+	if len(bomb) > MaxQRAlphanumericLength {
+		t.Fatalf("Expected the bomb to fit in a QR code, got %d characters", len(bomb))
+	}
+	if _, err := DecodeCompact(bomb); !errors.Is(err, ErrCompactTooLarge) {
+		t.Errorf("Expected ErrCompactTooLarge, got %v", err)
+	}
+}
+
+func TestEncodeQRPayloadTooLarge(t *testing.T) {
+	_, priv := generateTestKeypair(t)
+
+	// Random-looking credentials do not compress, so enough of them overflow a QR code
+	creds := make([]string, 8)
+	for i := range creds {
+		var sb strings.Builder
+		sb.WriteString("v4.public.")
+		for j := 0; j < 16; j++ {
+			nonce, _ := GenerateNonce()
+			sb.WriteString(nonce)
+		}
+		creds[i] = sb.String()
+	}
+
+	token, err := CreatePresentation("did:key:holder", priv, creds, "aud", "nonce")
+	if err != nil {
+		t.Fatalf("Failed to create presentation: %v", err)
+	}
+
+	_, err = EncodeQRPayload(token)
+	if !errors.Is(err, ErrQRPayloadTooLarge) {
+		t.Errorf("Expected ErrQRPayloadTooLarge, got %v", err)
+	}
+}
+
+func TestQRFramesRoundTrip(t *testing.T) {
+	payload, err := EncodeCompact(strings.Repeat("v4.public.", 500))
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+
+	frames, err := SplitQRFrames(payload, 20)
+	if err != nil {
+		t.Fatalf("Failed to split: %v", err)
+	}
+	if len(frames) < 2 {
+		t.Fatalf("Expected several frames, got %d", len(frames))
+	}
+	for _, frame := range frames {
+		for _, c := range frame {
+			if !strings.ContainsRune(base45Alphabet, c) && !strings.ContainsRune("VPF", c) {
+				t.Fatalf("Frame %q has a character outside the QR alphanumeric charset", frame)
+			}
+		}
+	}
+
+	// Frames may be scanned in any order
+	reversed := make([]string, len(frames))
+	for i, frame := range frames {
+		reversed[len(frames)-1-i] = frame
+	}
+	joined, err := JoinQRFrames(reversed)
+	if err != nil {
+		t.Fatalf("Failed to join: %v", err)
+	}
+	if joined != payload {
+		t.Error("Joined payload does not match the original")
+	}
+}
+
+func TestSplitQRFramesSingleFrame(t *testing.T) {
+	payload, _ := EncodeCompact("v4.public.short")
+
+	frames, err := SplitQRFrames(payload, 0)
+	if err != nil {
+		t.Fatalf("Failed to split: %v", err)
+	}
+	if len(frames) != 1 || frames[0] != QRFramePrefix+"1/1:"+payload {
+		t.Errorf("Expected a single frame, got %v", frames)
+	}
+
+	if _, err := SplitQRFrames("not compact", 0); err != ErrInvalidCompactPayload {
+		t.Errorf("Expected ErrInvalidCompactPayload, got %v", err)
+	}
+}
+
+func TestJoinQRFramesInvalid(t *testing.T) {
+	payload, _ := EncodeCompact(strings.Repeat("v4.public.", 100))
+	frames, _ := SplitQRFrames(payload, 20)
+
+	tests := []struct {
+		name   string
+		frames []string
+	}{
+		{"empty", nil},
+		{"missing frame", frames[1:]},
+		{"duplicate frame", append([]string{frames[0]}, frames[:len(frames)-1]...)},
+		{"foreign frame", append([]string{"NOT A FRAME"}, frames[1:]...)},
+		{"bad index", append([]string{QRFramePrefix + "X/2:ABC"}, frames[1:]...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := JoinQRFrames(tt.frames); err != ErrInvalidQRFrames {
+				t.Errorf("Expected ErrInvalidQRFrames, got %v", err)
+			}
+		})
+	}
+}
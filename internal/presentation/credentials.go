@@ -0,0 +1,412 @@
+package presentation
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/resolver"
+	"github.com/veriglob/veriglob-core/internal/revocation"
+	"github.com/veriglob/veriglob-core/internal/vc"
+)
+
+var (
+	ErrCredentialNotActive   = errors.New("credential is revoked or suspended")
+	ErrHolderSubjectMismatch = errors.New("credential subject does not match presentation holder")
+	ErrHolderKeyMismatch     = errors.New("credential is not bound to the presentation holder key")
+	ErrStatusProofMissing    = errors.New("credential has no status proof")
+	ErrStatusProofMismatch   = errors.New("status proof is for a different credential or issuer")
+	ErrExpiredCredential     = errors.New("presentation contains an expired credential")
+)
+
+// StatusChecker looks up the revocation status of a credential, e.g. a
+// *revocation.Registry
+type StatusChecker = revocation.StatusChecker
+
+// CredentialCheckOptions configures verification of embedded credentials
+type CredentialCheckOptions struct {
+	// IssuerKeys maps issuer DIDs to known keys and is consulted before Resolver
+	IssuerKeys map[string]ed25519.PublicKey
+	// Resolver resolves issuer DIDs to keys; nil uses the default did:key resolver
+	Resolver *resolver.Resolver
+	// Status, when set, is consulted for each credential's revocation status
+	Status StatusChecker
+	// RequireHolderBinding rejects credentials whose subject is not the
+	// presentation holder, so a holder cannot present someone else's
+	// credential, and a presentation key the holder DID does not resolve to
+	RequireHolderBinding bool
+	// RequireKeyBinding rejects credentials without a cnf key binding.
+	// A credential that has one is always checked against the key that
+	// signed the presentation, e.g. a key the holder derived for this verifier.
+	RequireKeyBinding bool
+	// RequireProofPurpose checks each key against the signer's resolved DID
+	// document: the presentation key must be an authentication method of
+	// the holder and each issuer key an assertionMethod of the issuer.
+	// Otherwise ErrInvalidProofPurpose is returned.
+	RequireProofPurpose bool
+	// StatusProofMaxAge is how old an attached status proof may be; zero uses
+	// revocation.DefaultStatusProofMaxAge
+	StatusProofMaxAge time.Duration
+	// RequireStatusProof rejects credentials with an ID that the presentation
+	// carries no status proof for, for verifiers that check revocation offline
+	RequireStatusProof bool
+	// TrustedIssuers, when set, fails credentials whose issuer it does not
+	// trust for the credential's types with vc.ErrUntrustedIssuer
+	TrustedIssuers *vc.TrustList
+	// RejectExpiredCredentials fails the whole presentation with
+	// ErrExpiredCredential if any embedded credential has expired, rather
+	// than only reporting it in that credential's result
+	RejectExpiredCredentials bool
+	// Leeway is the clock skew tolerated on the expiry and nbf of the
+	// presentation and each credential; zero uses vc.DefaultLeeway and a
+	// negative value none
+	Leeway time.Duration
+	// ProofMaxAge is how long after its created time an embedded JSON
+	// presentation proof is accepted; zero uses DefaultPresentationTTL
+	ProofMaxAge time.Duration
+	// FetchCredential retrieves credentials presented by reference; without
+	// it they fail with ErrReferenceNotFetched
+	FetchCredential CredentialFetcher
+	// Metrics, when set, receives the duration of each verification phase
+	Metrics PhaseRecorder
+	// Now is the clock used for phase timings and proof ages; nil uses
+	// time.Now
+	Now func() time.Time
+}
+
+// Verification phases timed for each embedded credential
+const (
+	PhaseResolution = "resolution"
+	PhaseSignature  = "signature"
+	PhaseRevocation = "revocation"
+)
+
+// PhaseRecorder receives per-phase verification timings, e.g. to feed a
+// metrics histogram
+type PhaseRecorder interface {
+	RecordPhase(phase string, d time.Duration)
+}
+
+// PhaseTimings are the time spent in each verification phase. A phase that
+// did not run is zero.
+type PhaseTimings struct {
+	Resolution time.Duration
+	Signature  time.Duration
+	Revocation time.Duration
+}
+
+// CredentialResult is the outcome of verifying one embedded credential
+type CredentialResult struct {
+	Index        int
+	Valid        bool
+	Issuer       string
+	Subject      string
+	CredentialID string
+	// ExpiresAt is the credential's expiry, set once its signature verified.
+	// Expired reports that it has passed, beyond the leeway; Err then wraps
+	// vc.ErrCredentialExpired.
+	ExpiresAt time.Time
+	Expired   bool
+	Status    revocation.Status // empty if not checked or not in the registry
+	// StatusFromProof reports whether Status came from an attached status
+	// proof rather than a registry lookup
+	StatusFromProof bool
+	// Reference is set for a credential presented by reference
+	Reference *CredentialReference
+	Claims    *vc.VCClaims
+	Timings   PhaseTimings
+	Err       error
+}
+
+// CredentialResults are the per-credential outcomes for a presentation, in
+// presentation order: inline credentials, then references
+type CredentialResults []CredentialResult
+
+// ValidCount returns how many credentials verified
+func (rs CredentialResults) ValidCount() int {
+	n := 0
+	for _, r := range rs {
+		if r.Valid {
+			n++
+		}
+	}
+	return n
+}
+
+// AllValid reports whether every credential verified
+func (rs CredentialResults) AllValid() bool {
+	return rs.ValidCount() == len(rs)
+}
+
+// Partial reports whether some, but not all, credentials verified
+func (rs CredentialResults) Partial() bool {
+	n := rs.ValidCount()
+	return n > 0 && n < len(rs)
+}
+
+// ExpiredError returns ErrExpiredCredential naming the first expired
+// credential, or nil if none has expired
+func (rs CredentialResults) ExpiredError() error {
+	for _, r := range rs {
+		if r.Expired {
+			return fmt.Errorf("%w: credential %d", ErrExpiredCredential, r.Index)
+		}
+	}
+	return nil
+}
+
+// Revoked reports whether the registry marked the credential revoked
+func (r CredentialResult) Revoked() bool {
+	return r.Status == revocation.StatusRevoked
+}
+
+// VerifyPresentationWithCredentials verifies a presentation and then each
+// embedded credential, resolving every issuer key from the credential's issuer
+// DID. An error is returned only if the presentation itself fails. A
+// malformed, expired, or revoked credential is reported in its own result and
+// does not stop the others from being verified. With
+// RejectExpiredCredentials, an expired credential fails the presentation:
+// ErrExpiredCredential is returned together with the claims and results.
+func VerifyPresentationWithCredentials(
+	tokenString string,
+	holderPublicKey ed25519.PublicKey,
+	expectedAudience string,
+	expectedNonce string,
+	opts CredentialCheckOptions,
+) (*VPClaims, CredentialResults, error) {
+	suite := &vc.PasetoV4Suite{PublicKey: holderPublicKey, Leeway: opts.Leeway}
+	claims, err := VerifyPresentationWithSuite(tokenString, suite, expectedAudience, expectedNonce, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if opts.RequireHolderBinding {
+		if err := CheckHolderKey(claims.VP.Holder, holderPublicKey, opts.Resolver); err != nil {
+			return nil, nil, err
+		}
+	}
+	if opts.RequireProofPurpose {
+		if err := proofPurposeResolver(opts).VerifyProofPurpose(claims.Issuer, holderPublicKey, resolver.ProofPurposeAuthentication); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	results := VerifyEmbeddedCredentials(claims, holderPublicKey, opts)
+	if opts.RejectExpiredCredentials {
+		if err := results.ExpiredError(); err != nil {
+			return claims, results, err
+		}
+	}
+	return claims, results, nil
+}
+
+// VerifyEmbeddedCredentials verifies each credential of an already verified
+// presentation, as VerifyPresentationWithCredentials does, for callers that
+// check the presentation itself some other way
+func VerifyEmbeddedCredentials(claims *VPClaims, holderPublicKey ed25519.PublicKey, opts CredentialCheckOptions) CredentialResults {
+	inline := len(claims.VP.VerifiableCredential)
+	results := make(CredentialResults, inline+len(claims.VP.References))
+	for i, credToken := range claims.VP.VerifiableCredential {
+		results[i] = verifyEmbeddedCredential(i, credToken, claims.VP.Holder, holderPublicKey, claims.StatusProofs, opts)
+	}
+	for i, ref := range claims.VP.References {
+		results[inline+i] = verifyReferencedCredential(inline+i, ref, claims.VP.Holder, holderPublicKey, claims.StatusProofs, opts)
+	}
+	return results
+}
+
+func verifyEmbeddedCredential(index int, token, holderDID string, holderKey ed25519.PublicKey, proofs []*revocation.StatusProof, opts CredentialCheckOptions) CredentialResult {
+	result := CredentialResult{Index: index}
+
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	timed := func(phase string, d *time.Duration, f func()) {
+		start := now()
+		f()
+		*d = now().Sub(start)
+		if opts.Metrics != nil {
+			opts.Metrics.RecordPhase(phase, *d)
+		}
+	}
+
+	issuerDID, err := vc.UnverifiedIssuer(token)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Issuer = issuerDID
+
+	var issuerKey ed25519.PublicKey
+	timed(PhaseResolution, &result.Timings.Resolution, func() {
+		issuerKey, err = issuerPublicKey(issuerDID, opts)
+		if err == nil && opts.RequireProofPurpose {
+			err = proofPurposeResolver(opts).VerifyProofPurpose(issuerDID, issuerKey, resolver.ProofPurposeAssertionMethod)
+		}
+	})
+	if err != nil {
+		result.Err = fmt.Errorf("resolving issuer %s: %w", issuerDID, err)
+		return result
+	}
+
+	var claims *vc.VCClaims
+	timed(PhaseSignature, &result.Timings.Signature, func() {
+		suite := &vc.PasetoV4Suite{PublicKey: issuerKey, Leeway: opts.Leeway}
+		if vc.IsSDCredential(token) {
+			claims, err = vc.VerifySDVCWithSuite(token, suite)
+		} else {
+			claims, err = vc.VerifyVCWithSuite(token, suite)
+		}
+	})
+	if errors.Is(err, vc.ErrCredentialExpired) {
+		// The signature verified before the expiry was checked, so the
+		// claims are the issuer's
+		result.Expired = true
+		if expired, decodeErr := vc.UnverifiedClaims(token); decodeErr == nil {
+			result.Subject = expired.Subject
+			result.CredentialID = expired.GetCredentialID()
+			result.ExpiresAt = expired.ExpiresAt
+		}
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Claims = claims
+	result.Subject = claims.Subject
+	result.CredentialID = claims.GetCredentialID()
+	result.ExpiresAt = claims.ExpiresAt
+
+	if opts.TrustedIssuers != nil {
+		if err := opts.TrustedIssuers.Check(claims); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	if bound, ok := claims.BoundKey(); ok {
+		if !bound.Equal(holderKey) {
+			result.Err = ErrHolderKeyMismatch
+			return result
+		}
+	} else if claims.Confirmation != nil || opts.RequireKeyBinding {
+		result.Err = ErrHolderKeyMismatch
+		return result
+	}
+
+	if opts.RequireHolderBinding && claims.Subject != holderDID {
+		result.Err = ErrHolderSubjectMismatch
+		return result
+	}
+
+	if result.CredentialID != "" {
+		timed(PhaseRevocation, &result.Timings.Revocation, func() {
+			err = checkCredentialStatus(&result, issuerKey, proofs, opts, time.Now())
+		})
+		if err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	if result.Status == revocation.StatusRevoked || result.Status == revocation.StatusSuspended {
+		result.Err = ErrCredentialNotActive
+		return result
+	}
+
+	result.Valid = true
+	return result
+}
+
+// checkCredentialStatus sets the result's status from an attached status
+// proof signed by the issuer, then from opts.Status, which being live takes
+// precedence. An attached proof that does not verify fails the credential.
+func checkCredentialStatus(result *CredentialResult, issuerKey ed25519.PublicKey, proofs []*revocation.StatusProof, opts CredentialCheckOptions, now time.Time) error {
+	proof := findStatusProof(proofs, result.CredentialID)
+	switch {
+	case proof != nil:
+		statement, err := proof.Verify(issuerKey, opts.StatusProofMaxAge, now)
+		if err != nil {
+			return err
+		}
+		if statement.IssuerDID != result.Issuer {
+			return ErrStatusProofMismatch
+		}
+		result.Status = statement.Status
+		result.StatusFromProof = true
+	case opts.RequireStatusProof:
+		return ErrStatusProofMissing
+	}
+
+	if opts.Status == nil {
+		return nil
+	}
+	entry, err := opts.Status.CheckStatus(result.CredentialID)
+	switch {
+	case err == nil:
+		result.Status = entry.Status
+		result.StatusFromProof = false
+	case errors.Is(err, revocation.ErrCredentialNotFound):
+		// Not tracked; the signature alone decides validity
+	default:
+		return err
+	}
+	return nil
+}
+
+// findStatusProof returns the attached proof that claims to cover a
+// credential. Proofs that do not decode are skipped.
+func findStatusProof(proofs []*revocation.StatusProof, credentialID string) *revocation.StatusProof {
+	for _, p := range proofs {
+		if p == nil {
+			continue
+		}
+		var statement revocation.StatusStatement
+		if err := json.Unmarshal(p.Payload, &statement); err == nil && statement.CredentialID == credentialID {
+			return p
+		}
+	}
+	return nil
+}
+
+// CheckHolderKey returns ErrHolderNotSigner unless key is one the holder DID
+// resolves to with res, or the default resolver when res is nil. A
+// presentation verifies against whatever key the caller passes, so binding
+// its credentials to the holder also needs the key bound to the holder.
+func CheckHolderKey(holderDID string, key ed25519.PublicKey, res *resolver.Resolver) error {
+	if res == nil {
+		res = resolver.NewResolver()
+	}
+	keys, err := res.ResolveAllKeys(holderDID)
+	if err != nil {
+		return fmt.Errorf("%w: resolving holder %s: %w", ErrHolderNotSigner, holderDID, err)
+	}
+	for _, k := range keys {
+		if k.Equal(key) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: key is not one of %s", ErrHolderNotSigner, holderDID)
+}
+
+// issuerPublicKey looks up a known issuer key, falling back to DID resolution
+func issuerPublicKey(issuerDID string, opts CredentialCheckOptions) (ed25519.PublicKey, error) {
+	if key, ok := opts.IssuerKeys[issuerDID]; ok {
+		return key, nil
+	}
+	if opts.Resolver != nil {
+		return opts.Resolver.Resolve(issuerDID)
+	}
+	return resolver.ResolveDID(issuerDID)
+}
+
+// proofPurposeResolver returns the resolver used for proof purpose checks
+func proofPurposeResolver(opts CredentialCheckOptions) *resolver.Resolver {
+	if opts.Resolver != nil {
+		return opts.Resolver
+	}
+	return resolver.NewResolver()
+}
@@ -0,0 +1,626 @@
+package presentation
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/mr-tron/base58"
+	"github.com/veriglob/veriglob-core/internal/crypto"
+	"github.com/veriglob/veriglob-core/internal/did"
+	"github.com/veriglob/veriglob-core/internal/resolver"
+	"github.com/veriglob/veriglob-core/internal/revocation"
+	"github.com/veriglob/veriglob-core/internal/vc"
+	"github.com/veriglob/veriglob-core/internal/vc/vctest"
+)
+
+func TestVerifyPresentationWithCredentials(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+	holderPub, holderPriv := generateTestKeypair(t)
+	holderDID, _ := did.CreateDIDKey(holderPub)
+
+	_, otherPriv := generateTestKeypair(t)
+
+	registry := revocation.NewRegistry()
+	registry.Register("urn:uuid:good", issuerDID.DID, holderDID.DID)
+	registry.Register("urn:uuid:revoked", issuerDID.DID, holderDID.DID)
+	registry.Revoke("urn:uuid:revoked", "test")
+
+	subject := vctest.IdentitySubject(holderDID.DID)
+	good, _ := vc.IssueVCWithID(issuerDID.DID, holderDID.DID, issuerPriv, subject, "urn:uuid:good")
+	revoked, _ := vc.IssueVCWithID(issuerDID.DID, holderDID.DID, issuerPriv, subject, "urn:uuid:revoked")
+	// Claims to come from the issuer but is signed by someone else
+	forged, _ := vc.IssueVCWithID(issuerDID.DID, holderDID.DID, otherPriv, subject, "urn:uuid:forged")
+
+	token, err := CreatePresentation(holderDID.DID, holderPriv, []string{good, revoked, forged}, "did:key:zVerifier", "nonce")
+	if err != nil {
+		t.Fatalf("CreatePresentation failed: %v", err)
+	}
+
+	claims, results, err := VerifyPresentationWithCredentials(token, holderPub, "did:key:zVerifier", "nonce", CredentialCheckOptions{Status: registry})
+	if err != nil {
+		t.Fatalf("VerifyPresentationWithCredentials failed: %v", err)
+	}
+	if claims.VP.Holder != holderDID.DID {
+		t.Errorf("Expected holder %s, got %s", holderDID.DID, claims.VP.Holder)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	if !results[0].Valid || results[0].Err != nil {
+		t.Errorf("Expected first credential valid, got error %v", results[0].Err)
+	}
+	if results[0].Issuer != issuerDID.DID || results[0].Subject != holderDID.DID {
+		t.Errorf("Unexpected issuer/subject %s/%s", results[0].Issuer, results[0].Subject)
+	}
+	if results[0].Status != revocation.StatusActive {
+		t.Errorf("Expected status active, got %q", results[0].Status)
+	}
+
+	if results[1].Valid || !results[1].Revoked() || !errors.Is(results[1].Err, ErrCredentialNotActive) {
+		t.Errorf("Expected second credential revoked, got valid=%v status=%q err=%v", results[1].Valid, results[1].Status, results[1].Err)
+	}
+
+	if results[2].Valid || results[2].Err == nil {
+		t.Error("Expected forged credential to fail signature verification")
+	}
+	if results[2].Issuer != issuerDID.DID {
+		t.Errorf("Expected forged credential issuer to be reported, got %s", results[2].Issuer)
+	}
+}
+
+func TestVerifyPresentationWithCredentialsSkipsRevocation(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+	holderPub, holderPriv := generateTestKeypair(t)
+
+	cred, _ := vc.IssueVCWithID(issuerDID.DID, "did:key:zHolder", issuerPriv, vctest.IdentitySubject("did:key:zHolder"), "urn:uuid:untracked")
+	token, _ := CreatePresentation("did:key:zHolder", holderPriv, []string{cred}, "", "")
+
+	_, results, err := VerifyPresentationWithCredentials(token, holderPub, "", "", CredentialCheckOptions{})
+	if err != nil {
+		t.Fatalf("VerifyPresentationWithCredentials failed: %v", err)
+	}
+	if !results[0].Valid || results[0].Status != "" {
+		t.Errorf("Expected valid unchecked credential, got valid=%v status=%q err=%v", results[0].Valid, results[0].Status, results[0].Err)
+	}
+}
+
+func TestVerifyPresentationWithCredentialsPresentationFailure(t *testing.T) {
+	_, holderPriv := generateTestKeypair(t)
+	otherPub, _ := generateTestKeypair(t)
+
+	token, _ := CreatePresentation("did:key:zHolder", holderPriv, []string{"v4.public.x"}, "", "")
+	if _, _, err := VerifyPresentationWithCredentials(token, otherPub, "", "", CredentialCheckOptions{}); err == nil {
+		t.Error("Expected error for presentation signed by another key")
+	}
+}
+
+func TestVerifyPresentationHolderBinding(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	_, malloryPriv := generateTestKeypair(t)
+	malloryPub := malloryPriv.Public().(ed25519.PublicKey)
+	mallory, _ := did.CreateDIDKey(malloryPub)
+
+	// Alice's credential, wrapped by Mallory in Mallory's own presentation
+	alicesCred, _ := vc.IssueVC("did:web:issuer.example.com", "did:key:zAlice", issuerPriv, vctest.IdentitySubject("did:key:zAlice"))
+	malloryCred, _ := vc.IssueVC("did:web:issuer.example.com", mallory.DID, issuerPriv, vctest.IdentitySubject(mallory.DID))
+	token, _ := CreatePresentation(mallory.DID, malloryPriv, []string{malloryCred, alicesCred}, "", "")
+
+	// Issuer keys supplied directly, since did:web is not resolvable here
+	opts := CredentialCheckOptions{
+		IssuerKeys:           map[string]ed25519.PublicKey{"did:web:issuer.example.com": issuerPub},
+		RequireHolderBinding: true,
+	}
+
+	_, results, err := VerifyPresentationWithCredentials(token, malloryPub, "", "", opts)
+	if err != nil {
+		t.Fatalf("VerifyPresentationWithCredentials failed: %v", err)
+	}
+	if !results[0].Valid {
+		t.Errorf("Expected holder's own credential to be valid, got %v", results[0].Err)
+	}
+	if results[1].Valid || results[1].Err != ErrHolderSubjectMismatch {
+		t.Errorf("Expected ErrHolderSubjectMismatch, got valid=%v err=%v", results[1].Valid, results[1].Err)
+	}
+
+	// Without the option the wrapped credential is accepted on signature alone
+	opts.RequireHolderBinding = false
+	_, results, _ = VerifyPresentationWithCredentials(token, malloryPub, "", "", opts)
+	if !results[1].Valid {
+		t.Errorf("Expected unbound check to accept credential, got %v", results[1].Err)
+	}
+}
+
+func TestVerifyPresentationForgedHolder(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	victimPub, _ := generateTestKeypair(t)
+	attackerPub, attackerPriv := generateTestKeypair(t)
+	victim, _ := did.CreateDIDKey(victimPub)
+	attacker, _ := did.CreateDIDKey(attackerPub)
+
+	victimsCred, _ := vc.IssueVC("did:web:issuer.example.com", victim.DID, issuerPriv, vctest.IdentitySubject(victim.DID))
+	opts := CredentialCheckOptions{
+		IssuerKeys:           map[string]ed25519.PublicKey{"did:web:issuer.example.com": issuerPub},
+		RequireHolderBinding: true,
+	}
+
+	// Signed by the attacker as themselves, naming the victim as holder
+	secretKey, _ := paseto.NewV4AsymmetricSecretKeyFromBytes(attackerPriv)
+	token := paseto.NewToken()
+	token.SetIssuer(attacker.DID)
+	token.SetSubject(attacker.DID)
+	token.SetAudience("aud")
+	token.SetIssuedAt(time.Now())
+	token.SetExpiration(time.Now().Add(time.Minute))
+	token.SetString("nonce", "nonce")
+	token.Set("vp", VerifiablePresentation{Holder: victim.DID, VerifiableCredential: []string{victimsCred}})
+	forged := token.V4Sign(secretKey, nil)
+
+	if _, _, err := VerifyPresentationWithCredentials(forged, attackerPub, "aud", "nonce", opts); !errors.Is(err, ErrHolderNotSigner) {
+		t.Errorf("Expected ErrHolderNotSigner for a holder other than iss, got %v", err)
+	}
+
+	// Signed by the attacker with both iss and holder set to the victim
+	impersonation, _ := CreatePresentation(victim.DID, attackerPriv, []string{victimsCred}, "aud", "nonce")
+	if _, _, err := VerifyPresentationWithCredentials(impersonation, attackerPub, "aud", "nonce", opts); !errors.Is(err, ErrHolderNotSigner) {
+		t.Errorf("Expected ErrHolderNotSigner for a key the holder does not hold, got %v", err)
+	}
+}
+
+func TestVerifyPresentationWithCredentialsPartialResults(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+	holderPub, holderPriv := generateTestKeypair(t)
+
+	first, _ := vc.IssueVC(issuerDID.DID, "did:key:zHolder", issuerPriv, vctest.IdentitySubject("did:key:zHolder"))
+	third, _ := vc.IssueVC(issuerDID.DID, "did:key:zHolder", issuerPriv, vctest.IdentitySubject("did:key:zHolder"))
+
+	corrupt := []string{
+		"v4.public.%%%not-base64%%%",
+		"v2.public." + first[len("v4.public."):],
+		first[:len(first)-10] + "AAAAAAAAAA",
+	}
+
+	for _, bad := range corrupt {
+		token, _ := CreatePresentation("did:key:zHolder", holderPriv, []string{first, bad, third}, "", "")
+
+		_, results, err := VerifyPresentationWithCredentials(token, holderPub, "", "", CredentialCheckOptions{})
+		if err != nil {
+			t.Fatalf("Presentation should verify despite a corrupt credential: %v", err)
+		}
+		if len(results) != 3 {
+			t.Fatalf("Expected 3 results, got %d", len(results))
+		}
+		if !results[0].Valid || !results[2].Valid {
+			t.Errorf("Expected first and third credentials valid, got %v / %v", results[0].Err, results[2].Err)
+		}
+		if results[1].Valid || results[1].Err == nil {
+			t.Errorf("Expected corrupt credential %q to carry an error", bad[:20])
+		}
+		if !results.Partial() || results.AllValid() || results.ValidCount() != 2 {
+			t.Errorf("Expected partial success with 2 valid, got %d", results.ValidCount())
+		}
+	}
+}
+
+func TestVerifyPresentationWithCredentialsTrustedIssuers(t *testing.T) {
+	trustedPub, trustedPriv := generateTestKeypair(t)
+	trustedDID, _ := did.CreateDIDKey(trustedPub)
+	otherPub, otherPriv := generateTestKeypair(t)
+	otherDID, _ := did.CreateDIDKey(otherPub)
+	holderPub, holderPriv := generateTestKeypair(t)
+
+	fromTrusted, _ := vc.IssueVC(trustedDID.DID, "did:key:zHolder", trustedPriv, vctest.IdentitySubject("did:key:zHolder"))
+	fromOther, _ := vc.IssueVC(otherDID.DID, "did:key:zHolder", otherPriv, vctest.IdentitySubject("did:key:zHolder"))
+	token, _ := CreatePresentation("did:key:zHolder", holderPriv, []string{fromTrusted, fromOther}, "", "")
+
+	trust := vc.NewTrustList()
+	trust.Trust(trustedDID.DID)
+	_, results, err := VerifyPresentationWithCredentials(token, holderPub, "", "", CredentialCheckOptions{TrustedIssuers: trust})
+	if err != nil {
+		t.Fatalf("VerifyPresentationWithCredentials failed: %v", err)
+	}
+	if !results[0].Valid {
+		t.Errorf("Expected the trusted issuer's credential valid, got %v", results[0].Err)
+	}
+	if results[1].Valid || !errors.Is(results[1].Err, vc.ErrUntrustedIssuer) {
+		t.Errorf("Expected ErrUntrustedIssuer for the other issuer, got %v", results[1].Err)
+	}
+	// The signature was checked before the trust list, so the claims are known
+	if results[1].Claims == nil || results[1].Issuer != otherDID.DID {
+		t.Errorf("Expected verified claims for the untrusted credential, got %+v", results[1])
+	}
+}
+
+func TestVerifyPresentationWithCredentialsLeeway(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+	holderPub, holderPriv := generateTestKeypair(t)
+
+	// IssueVC refuses to sign an already expired credential, so sign the claims directly
+	expiredAgo := func(d time.Duration) string {
+		token, err := vc.NewPasetoV4Suite(issuerPriv, nil).Sign(&vc.TokenClaims{
+			Issuer:    issuerDID.DID,
+			Subject:   "did:key:zHolder",
+			IssuedAt:  time.Now().Add(-time.Hour),
+			ExpiresAt: time.Now().Add(-d),
+			Custom:    map[string]json.RawMessage{"vc": json.RawMessage(`{"type":["VerifiableCredential"]}`)},
+		})
+		if err != nil {
+			t.Fatalf("Failed to sign: %v", err)
+		}
+		return token
+	}
+
+	token, _ := CreatePresentation("did:key:zHolder", holderPriv, []string{expiredAgo(30 * time.Second), expiredAgo(90 * time.Second)}, "", "")
+	_, results, err := VerifyPresentationWithCredentials(token, holderPub, "", "", CredentialCheckOptions{Leeway: 60 * time.Second})
+	if err != nil {
+		t.Fatalf("VerifyPresentationWithCredentials failed: %v", err)
+	}
+	if !results[0].Valid {
+		t.Errorf("Expected a credential 30s past expiry valid with 60s leeway, got %v", results[0].Err)
+	}
+	if results[1].Valid || !errors.Is(results[1].Err, vc.ErrCredentialExpired) {
+		t.Errorf("Expected ErrCredentialExpired 90s past expiry, got %v", results[1].Err)
+	}
+}
+
+func TestVerifyPresentationWithCredentialsExpired(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+	holderPub, holderPriv := generateTestKeypair(t)
+
+	expired, _ := vc.IssueVCWithOptions(issuerDID.DID, "did:key:zHolder", issuerPriv, vctest.IdentitySubject("did:key:zHolder"), vc.IssueOptions{Lifetime: time.Second})
+	time.Sleep(1100 * time.Millisecond)
+	fresh, _ := vc.IssueVC(issuerDID.DID, "did:key:zHolder", issuerPriv, vctest.IdentitySubject("did:key:zHolder"))
+
+	token, _ := CreatePresentation("did:key:zHolder", holderPriv, []string{expired, fresh}, "", "")
+	_, results, err := VerifyPresentationWithCredentials(token, holderPub, "", "", CredentialCheckOptions{Leeway: -1})
+	if err != nil {
+		t.Fatalf("VerifyPresentationWithCredentials failed: %v", err)
+	}
+	if results[0].Valid || results[0].Err == nil {
+		t.Error("Expected expired credential to be reported invalid")
+	}
+	if results[0].Issuer != issuerDID.DID {
+		t.Errorf("Expected issuer reported for expired credential, got %q", results[0].Issuer)
+	}
+	if !results[1].Valid {
+		t.Errorf("Expected fresh credential valid, got %v", results[1].Err)
+	}
+}
+
+func TestVerifyPresentationWithCredentialsRejectExpired(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+	holderPub, holderPriv := generateTestKeypair(t)
+
+	expiresAt := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	expired, err := vc.NewPasetoV4Suite(issuerPriv, nil).Sign(&vc.TokenClaims{
+		Issuer:    issuerDID.DID,
+		Subject:   "did:key:zHolder",
+		JTI:       "urn:uuid:lapsed",
+		IssuedAt:  expiresAt.Add(-30 * 24 * time.Hour),
+		ExpiresAt: expiresAt,
+		Custom:    map[string]json.RawMessage{"vc": json.RawMessage(`{"type":["VerifiableCredential"]}`)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	fresh, _ := vc.IssueVCWithID(issuerDID.DID, "did:key:zHolder", issuerPriv, vctest.IdentitySubject("did:key:zHolder"), "urn:uuid:fresh")
+	token, _ := CreatePresentation("did:key:zHolder", holderPriv, []string{fresh, expired, fresh}, "", "")
+
+	// By default the expired credential is only flagged in its result
+	claims, results, err := VerifyPresentationWithCredentials(token, holderPub, "", "", CredentialCheckOptions{})
+	if err != nil {
+		t.Fatalf("VerifyPresentationWithCredentials failed: %v", err)
+	}
+	if !results[0].Valid || results[0].Expired || results[0].ExpiresAt.IsZero() {
+		t.Errorf("Expected the fresh credential valid with its expiry, got %+v", results[0])
+	}
+	if results[1].Valid || !results[1].Expired || !errors.Is(results[1].Err, vc.ErrCredentialExpired) {
+		t.Errorf("Expected the lapsed credential flagged expired, got %+v", results[1])
+	}
+	if !results[1].ExpiresAt.Equal(expiresAt) || results[1].CredentialID != "urn:uuid:lapsed" || results[1].Subject != "did:key:zHolder" {
+		t.Errorf("Expected the lapsed credential's expiry, ID and subject, got %+v", results[1])
+	}
+	if results[1].Claims != nil {
+		t.Error("Expected no verified claims for an expired credential")
+	}
+	if err := results[:1].ExpiredError(); err != nil {
+		t.Errorf("Expected no expiry error without expired credentials, got %v", err)
+	}
+
+	// With the option the presentation fails, naming the first expired credential
+	opts := CredentialCheckOptions{RejectExpiredCredentials: true}
+	claims, results, err = VerifyPresentationWithCredentials(token, holderPub, "", "", opts)
+	if !errors.Is(err, ErrExpiredCredential) || !strings.Contains(err.Error(), "credential 1") {
+		t.Fatalf("Expected ErrExpiredCredential for credential 1, got %v", err)
+	}
+	if claims == nil || len(results) != 3 || !results[2].Valid {
+		t.Errorf("Expected the claims and every result alongside the error, got %v and %+v", claims, results)
+	}
+
+	// A presentation of unexpired credentials passes with the option
+	token, _ = CreatePresentation("did:key:zHolder", holderPriv, []string{fresh}, "", "")
+	if _, _, err := VerifyPresentationWithCredentials(token, holderPub, "", "", opts); err != nil {
+		t.Errorf("Expected no error without expired credentials, got %v", err)
+	}
+}
+
+type fakePhaseRecorder struct {
+	phases []string
+}
+
+func (r *fakePhaseRecorder) RecordPhase(phase string, d time.Duration) {
+	r.phases = append(r.phases, phase)
+}
+
+func TestVerifyPresentationWithCredentialsPhaseTimings(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+	holderPub, holderPriv := generateTestKeypair(t)
+
+	registry := revocation.NewRegistry()
+	registry.Register("urn:uuid:timed", issuerDID.DID, "did:key:zHolder")
+
+	cred, _ := vc.IssueVCWithID(issuerDID.DID, "did:key:zHolder", issuerPriv, vctest.IdentitySubject("did:key:zHolder"), "urn:uuid:timed")
+	token, _ := CreatePresentation("did:key:zHolder", holderPriv, []string{cred}, "", "")
+
+	// Every reading of the fake clock advances it by 10ms, so each phase measures exactly 10ms
+	clock := time.Unix(0, 0)
+	recorder := &fakePhaseRecorder{}
+	opts := CredentialCheckOptions{
+		Status:  registry,
+		Metrics: recorder,
+		Now: func() time.Time {
+			clock = clock.Add(10 * time.Millisecond)
+			return clock
+		},
+	}
+
+	_, results, err := VerifyPresentationWithCredentials(token, holderPub, "", "", opts)
+	if err != nil {
+		t.Fatalf("VerifyPresentationWithCredentials failed: %v", err)
+	}
+
+	timings := results[0].Timings
+	if timings.Resolution != 10*time.Millisecond || timings.Signature != 10*time.Millisecond || timings.Revocation != 10*time.Millisecond {
+		t.Errorf("Expected 10ms for every phase, got %+v", timings)
+	}
+
+	want := []string{PhaseResolution, PhaseSignature, PhaseRevocation}
+	if len(recorder.phases) != len(want) {
+		t.Fatalf("Expected phases %v, got %v", want, recorder.phases)
+	}
+	for i := range want {
+		if recorder.phases[i] != want[i] {
+			t.Errorf("Phase %d: expected %s, got %s", i, want[i], recorder.phases[i])
+		}
+	}
+}
+
+func TestVerifyPresentationWithCredentialsSkippedPhasesAreZero(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+	holderPub, holderPriv := generateTestKeypair(t)
+
+	cred, _ := vc.IssueVC(issuerDID.DID, "did:key:zHolder", issuerPriv, vctest.IdentitySubject("did:key:zHolder"))
+	token, _ := CreatePresentation("did:key:zHolder", holderPriv, []string{cred}, "", "")
+
+	_, results, _ := VerifyPresentationWithCredentials(token, holderPub, "", "", CredentialCheckOptions{})
+	if results[0].Timings.Revocation != 0 {
+		t.Errorf("Expected no revocation timing without a status checker, got %v", results[0].Timings.Revocation)
+	}
+}
+
+func TestVerifyPresentationWithDerivedHolderKey(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+	_, masterPriv := generateTestKeypair(t)
+
+	const verifierA = "did:key:zVerifierA"
+	const verifierB = "did:key:zVerifierB"
+
+	// The holder derives a key for verifier A and gets a credential bound to it
+	pubA, privA, _ := crypto.DeriveKeypair(masterPriv, verifierA)
+	didA, _ := did.CreateDIDKey(pubA)
+	pubB, privB, _ := crypto.DeriveKeypair(masterPriv, verifierB)
+	didB, _ := did.CreateDIDKey(pubB)
+
+	subject := vctest.IdentitySubject(didA.DID)
+	bound, err := vc.IssueVCWithOptions(issuerDID.DID, didA.DID, issuerPriv, subject, vc.IssueOptions{HolderKey: pubA})
+	if err != nil {
+		t.Fatalf("IssueVCWithOptions failed: %v", err)
+	}
+	opts := CredentialCheckOptions{RequireKeyBinding: true}
+
+	token, _ := CreatePresentation(didA.DID, privA, []string{bound}, verifierA, "nonce")
+	_, results, err := VerifyPresentationWithCredentials(token, pubA, verifierA, "nonce", opts)
+	if err != nil {
+		t.Fatalf("VerifyPresentationWithCredentials failed: %v", err)
+	}
+	if !results[0].Valid {
+		t.Errorf("Expected credential presented with its bound key to be valid, got %v", results[0].Err)
+	}
+
+	// Presented with the key derived for another verifier
+	token, _ = CreatePresentation(didB.DID, privB, []string{bound}, verifierB, "nonce")
+	_, results, err = VerifyPresentationWithCredentials(token, pubB, verifierB, "nonce", CredentialCheckOptions{})
+	if err != nil {
+		t.Fatalf("VerifyPresentationWithCredentials failed: %v", err)
+	}
+	if results[0].Valid || !errors.Is(results[0].Err, ErrHolderKeyMismatch) {
+		t.Errorf("Expected ErrHolderKeyMismatch for a mismatched derived key, got valid=%v err=%v", results[0].Valid, results[0].Err)
+	}
+
+	// Unbound credentials are only rejected when binding is required
+	unbound, _ := vc.IssueVC(issuerDID.DID, didA.DID, issuerPriv, subject)
+	token, _ = CreatePresentation(didA.DID, privA, []string{unbound}, verifierA, "nonce")
+	_, results, _ = VerifyPresentationWithCredentials(token, pubA, verifierA, "nonce", CredentialCheckOptions{})
+	if !results[0].Valid {
+		t.Errorf("Expected unbound credential to be valid without RequireKeyBinding, got %v", results[0].Err)
+	}
+	_, results, _ = VerifyPresentationWithCredentials(token, pubA, verifierA, "nonce", opts)
+	if !errors.Is(results[0].Err, ErrHolderKeyMismatch) {
+		t.Errorf("Expected ErrHolderKeyMismatch for an unbound credential with RequireKeyBinding, got %v", results[0].Err)
+	}
+}
+
+// splitKeyResolver resolves didStr to a document whose authentication and
+// assertionMethod relationships use different keys
+func splitKeyResolver(t *testing.T, didStr string, authPub, assertPub ed25519.PublicKey) *resolver.Resolver {
+	t.Helper()
+	store := resolver.NewStaticStore()
+	err := store.AddDocument(did.DIDDocument{
+		ID: didStr,
+		VerificationMethod: []did.VerificationMethod{
+			{ID: didStr + "#auth", Type: "Ed25519VerificationKey2018", Controller: didStr, PublicKeyBase58: base58.Encode(authPub)},
+			{ID: didStr + "#assert", Type: "Ed25519VerificationKey2018", Controller: didStr, PublicKeyBase58: base58.Encode(assertPub)},
+		},
+		Authentication:  []string{didStr + "#auth"},
+		AssertionMethod: []string{didStr + "#assert"},
+	})
+	if err != nil {
+		t.Fatalf("AddDocument failed: %v", err)
+	}
+	return resolver.NewResolverWithStaticStore(store)
+}
+
+func TestVerifyPresentationProofPurposeDIDKey(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+	holderPub, holderPriv := generateTestKeypair(t)
+	holderDID, _ := did.CreateDIDKey(holderPub)
+
+	cred, _ := vc.IssueVC(issuerDID.DID, holderDID.DID, issuerPriv, vctest.IdentitySubject(holderDID.DID))
+	token, _ := CreatePresentation(holderDID.DID, holderPriv, []string{cred}, "", "")
+
+	// did:key lists its key under both relationships
+	_, results, err := VerifyPresentationWithCredentials(token, holderPub, "", "", CredentialCheckOptions{RequireProofPurpose: true})
+	if err != nil {
+		t.Fatalf("VerifyPresentationWithCredentials failed: %v", err)
+	}
+	if !results[0].Valid {
+		t.Errorf("Expected credential to be valid, got %v", results[0].Err)
+	}
+}
+
+func TestVerifyPresentationHolderKeyNotAuthentication(t *testing.T) {
+	authPub, _ := generateTestKeypair(t)
+	assertPub, assertPriv := generateTestKeypair(t)
+	holderDID := "did:web:holder.example.com"
+
+	// The holder signs with its assertion key, which may not authenticate it
+	token, _ := CreatePresentation(holderDID, assertPriv, []string{"v4.public.x"}, "", "")
+	opts := CredentialCheckOptions{
+		Resolver:            splitKeyResolver(t, holderDID, authPub, assertPub),
+		RequireProofPurpose: true,
+	}
+
+	if _, _, err := VerifyPresentationWithCredentials(token, assertPub, "", "", opts); !errors.Is(err, resolver.ErrInvalidProofPurpose) {
+		t.Errorf("Expected ErrInvalidProofPurpose, got %v", err)
+	}
+}
+
+func TestVerifyPresentationIssuerKeyNotAssertionMethod(t *testing.T) {
+	authPub, authPriv := generateTestKeypair(t)
+	assertPub, _ := generateTestKeypair(t)
+	issuerDID := "did:web:issuer.example.com"
+	holderPub, holderPriv := generateTestKeypair(t)
+	holderDID, _ := did.CreateDIDKey(holderPub)
+
+	// The issuer signs with its authentication key instead of its assertion key
+	cred, _ := vc.IssueVC(issuerDID, holderDID.DID, authPriv, vctest.IdentitySubject(holderDID.DID))
+	token, _ := CreatePresentation(holderDID.DID, holderPriv, []string{cred}, "", "")
+
+	opts := CredentialCheckOptions{
+		IssuerKeys:          map[string]ed25519.PublicKey{issuerDID: authPub},
+		Resolver:            splitKeyResolver(t, issuerDID, authPub, assertPub),
+		RequireProofPurpose: true,
+	}
+
+	_, results, err := VerifyPresentationWithCredentials(token, holderPub, "", "", opts)
+	if err != nil {
+		t.Fatalf("VerifyPresentationWithCredentials failed: %v", err)
+	}
+	if results[0].Valid || !errors.Is(results[0].Err, resolver.ErrInvalidProofPurpose) {
+		t.Errorf("Expected ErrInvalidProofPurpose, got valid=%v err=%v", results[0].Valid, results[0].Err)
+	}
+
+	// Without the option the signature alone decides
+	opts.RequireProofPurpose = false
+	_, results, _ = VerifyPresentationWithCredentials(token, holderPub, "", "", opts)
+	if !results[0].Valid {
+		t.Errorf("Expected credential to be valid without the check, got %v", results[0].Err)
+	}
+}
+
+func TestVerifyPresentationWithStatusProofs(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+	holderPub, holderPriv := generateTestKeypair(t)
+	_, otherPriv := generateTestKeypair(t)
+
+	registry := revocation.NewRegistry()
+	registry.Register("urn:uuid:active", issuerDID.DID, "did:key:zHolder")
+	registry.Register("urn:uuid:revoked", issuerDID.DID, "did:key:zHolder")
+	registry.Revoke("urn:uuid:revoked", "test")
+
+	active, _ := vc.IssueVCWithID(issuerDID.DID, "did:key:zHolder", issuerPriv, vctest.IdentitySubject("did:key:zHolder"), "urn:uuid:active")
+	revoked, _ := vc.IssueVCWithID(issuerDID.DID, "did:key:zHolder", issuerPriv, vctest.IdentitySubject("did:key:zHolder"), "urn:uuid:revoked")
+	activeProof, _ := registry.StatusProof("urn:uuid:active", issuerPriv)
+	revokedProof, _ := registry.StatusProof("urn:uuid:revoked", issuerPriv)
+
+	token, err := CreatePresentationWithOptions("did:key:zHolder", holderPriv, []string{active, revoked}, "", "", CreateOptions{
+		StatusProofs: []*revocation.StatusProof{activeProof, revokedProof},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create presentation: %v", err)
+	}
+
+	// No registry: the proofs alone decide
+	claims, results, err := VerifyPresentationWithCredentials(token, holderPub, "", "", CredentialCheckOptions{RequireStatusProof: true})
+	if err != nil {
+		t.Fatalf("VerifyPresentationWithCredentials failed: %v", err)
+	}
+	if len(claims.StatusProofs) != 2 {
+		t.Errorf("Expected 2 status proofs in the claims, got %d", len(claims.StatusProofs))
+	}
+	if !results[0].Valid || !results[0].StatusFromProof || results[0].Status != revocation.StatusActive {
+		t.Errorf("Expected first credential active from its proof, got %+v", results[0])
+	}
+	if results[1].Valid || !results[1].Revoked() || !errors.Is(results[1].Err, ErrCredentialNotActive) {
+		t.Errorf("Expected second credential revoked from its proof, got %+v", results[1])
+	}
+
+	// A stale proof fails the credential
+	_, results, _ = VerifyPresentationWithCredentials(token, holderPub, "", "", CredentialCheckOptions{StatusProofMaxAge: time.Nanosecond})
+	if !errors.Is(results[0].Err, revocation.ErrStatusProofStale) {
+		t.Errorf("Expected ErrStatusProofStale, got %v", results[0].Err)
+	}
+
+	// A proof not signed by the issuer fails the credential
+	forged, _ := registry.StatusProof("urn:uuid:revoked", otherPriv)
+	token, _ = CreatePresentationWithOptions("did:key:zHolder", holderPriv, []string{revoked}, "", "", CreateOptions{
+		StatusProofs: []*revocation.StatusProof{forged},
+	})
+	_, results, _ = VerifyPresentationWithCredentials(token, holderPub, "", "", CredentialCheckOptions{})
+	if !errors.Is(results[0].Err, revocation.ErrStatusProofSignatureInvalid) {
+		t.Errorf("Expected ErrStatusProofSignatureInvalid, got %v", results[0].Err)
+	}
+
+	// Without a proof, RequireStatusProof rejects the credential
+	token, _ = CreatePresentation("did:key:zHolder", holderPriv, []string{active}, "", "")
+	_, results, _ = VerifyPresentationWithCredentials(token, holderPub, "", "", CredentialCheckOptions{RequireStatusProof: true})
+	if !errors.Is(results[0].Err, ErrStatusProofMissing) {
+		t.Errorf("Expected ErrStatusProofMissing, got %v", results[0].Err)
+	}
+}
@@ -0,0 +1,156 @@
+package presentation
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"aidanwoods.dev/go-paseto"
+
+	"github.com/veriglob/veriglob-core/internal/crypto"
+)
+
+var (
+	ErrNotEncrypted     = errors.New("token is not an encrypted presentation")
+	ErrDecryptionFailed = errors.New("presentation could not be decrypted")
+)
+
+// EncryptedPrefix is the header of an encrypted presentation, a PASETO v4
+// local token
+const EncryptedPrefix = "v4.local."
+
+// encryptionInfo is the HKDF context of an encrypted presentation key
+const encryptionInfo = "veriglob presentation encryption v1"
+
+// encryptedFooter carries the holder's ephemeral X25519 public key so the
+// verifier can agree the same key. It names neither party.
+type encryptedFooter struct {
+	EphemeralKey string `json:"epk"`
+}
+
+// CreateEncryptedPresentation creates a presentation like
+// CreatePresentationWithOptions and encrypts it to the verifier as a PASETO
+// v4 local token, so only the holder of the verifier's private key can read
+// the credentials. The key is agreed by X25519 between a fresh ephemeral key
+// and the verifier's Ed25519 key, e.g. from its did:key; the signed
+// presentation inside still proves the holder. Presentations are public
+// unless this is used.
+func CreateEncryptedPresentation(
+	holderDID string,
+	holderPrivateKey ed25519.PrivateKey,
+	verifierPublicKey ed25519.PublicKey,
+	credentials []string,
+	audience string,
+	nonce string,
+	opts CreateOptions,
+) (string, error) {
+	verifierKey, err := crypto.X25519PublicKeyFromEd25519(verifierPublicKey)
+	if err != nil {
+		return "", err
+	}
+
+	signed, err := CreatePresentationWithOptions(holderDID, holderPrivateKey, credentials, audience, nonce, opts)
+	if err != nil {
+		return "", err
+	}
+
+	ephemeralPub, ephemeralPriv, err := crypto.GenerateX25519Keypair()
+	if err != nil {
+		return "", err
+	}
+	key, err := encryptionKey(ephemeralPriv, verifierKey, ephemeralPub, verifierKey)
+	if err != nil {
+		return "", err
+	}
+	footer, err := json.Marshal(encryptedFooter{
+		EphemeralKey: base64.RawURLEncoding.EncodeToString(ephemeralPub.Bytes()),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	token := paseto.NewToken()
+	token.SetString("presentation", signed)
+	token.SetFooter(footer)
+	return token.V4Encrypt(key, nil), nil
+}
+
+// DecryptPresentation decrypts an encrypted presentation with the verifier's
+// private key and returns the signed presentation inside, for
+// VerifyPresentation or VerifyPresentationWithCredentials. A token that is not
+// v4 local returns ErrNotEncrypted; one that was not encrypted to this key or
+// was modified returns ErrDecryptionFailed.
+func DecryptPresentation(tokenString string, verifierPrivateKey ed25519.PrivateKey) (string, error) {
+	if !strings.HasPrefix(tokenString, EncryptedPrefix) {
+		return "", ErrNotEncrypted
+	}
+
+	parser := paseto.NewParserWithoutExpiryCheck()
+	rawFooter, err := parser.UnsafeParseFooter(paseto.V4Local, tokenString)
+	if err != nil {
+		return "", ErrDecryptionFailed
+	}
+	var footer encryptedFooter
+	if err := json.Unmarshal(rawFooter, &footer); err != nil {
+		return "", ErrDecryptionFailed
+	}
+	epk, err := base64.RawURLEncoding.DecodeString(footer.EphemeralKey)
+	if err != nil {
+		return "", ErrDecryptionFailed
+	}
+	ephemeralPub, err := ecdh.X25519().NewPublicKey(epk)
+	if err != nil {
+		return "", ErrDecryptionFailed
+	}
+
+	verifierKey, err := crypto.X25519PrivateKeyFromEd25519(verifierPrivateKey)
+	if err != nil {
+		return "", err
+	}
+	key, err := encryptionKey(verifierKey, ephemeralPub, ephemeralPub, verifierKey.PublicKey())
+	if err != nil {
+		return "", ErrDecryptionFailed
+	}
+
+	// The footer is authenticated by the decryption
+	token, err := parser.ParseV4Local(key, tokenString, nil)
+	if err != nil {
+		return "", ErrDecryptionFailed
+	}
+	signed, err := token.GetString("presentation")
+	if err != nil {
+		return "", ErrDecryptionFailed
+	}
+	return signed, nil
+}
+
+// VerifyEncryptedPresentation decrypts an encrypted presentation with the
+// verifier's private key and verifies the signed presentation inside like
+// VerifyPresentation
+func VerifyEncryptedPresentation(
+	tokenString string,
+	verifierPrivateKey ed25519.PrivateKey,
+	holderPublicKey ed25519.PublicKey,
+	expectedAudience string,
+	expectedNonce string,
+) (*VPClaims, error) {
+	signed, err := DecryptPresentation(tokenString, verifierPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return VerifyPresentation(signed, holderPublicKey, expectedAudience, expectedNonce)
+}
+
+// encryptionKey agrees the PASETO key between priv and peer, bound to the
+// ephemeral and verifier public keys so both sides use the same context
+func encryptionKey(priv *ecdh.PrivateKey, peer, ephemeralPub, verifierPub *ecdh.PublicKey) (paseto.V4SymmetricKey, error) {
+	info := encryptionInfo + string(ephemeralPub.Bytes()) + string(verifierPub.Bytes())
+	shared, err := crypto.DeriveSharedKey(priv, peer, info)
+	if err != nil {
+		return paseto.V4SymmetricKey{}, err
+	}
+	return paseto.V4SymmetricKeyFromBytes(shared)
+}
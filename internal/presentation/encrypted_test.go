@@ -0,0 +1,103 @@
+package presentation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncryptedPresentation(t *testing.T) {
+	holderPub, holderPriv := generateTestKeypair(t)
+	verifierPub, verifierPriv := generateTestKeypair(t)
+	credentials := []string{"v4.public.test-credential-token"}
+
+	token, err := CreateEncryptedPresentation("did:key:z6MkHolder", holderPriv, verifierPub, credentials, "did:key:z6MkVerifier", "nonce-1", CreateOptions{})
+	if err != nil {
+		t.Fatalf("CreateEncryptedPresentation failed: %v", err)
+	}
+	if !strings.HasPrefix(token, EncryptedPrefix) {
+		t.Errorf("Expected a v4.local token, got %s", token)
+	}
+	// Neither the credential nor the holder is readable in transit
+	for _, secret := range []string{"test-credential-token", "did:key:z6MkHolder"} {
+		if strings.Contains(token, secret) {
+			t.Errorf("Expected %q to be encrypted", secret)
+		}
+	}
+
+	claims, err := VerifyEncryptedPresentation(token, verifierPriv, holderPub, "did:key:z6MkVerifier", "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyEncryptedPresentation failed: %v", err)
+	}
+	if claims.Issuer != "did:key:z6MkHolder" {
+		t.Errorf("Expected issuer did:key:z6MkHolder, got %s", claims.Issuer)
+	}
+	if len(claims.VP.VerifiableCredential) != 1 || claims.VP.VerifiableCredential[0] != credentials[0] {
+		t.Errorf("Expected the credential to round trip, got %v", claims.VP.VerifiableCredential)
+	}
+
+	// The decrypted presentation is an ordinary signed one
+	signed, err := DecryptPresentation(token, verifierPriv)
+	if err != nil {
+		t.Fatalf("DecryptPresentation failed: %v", err)
+	}
+	if _, err := VerifyPresentation(signed, holderPub, "did:key:z6MkVerifier", "nonce-1"); err != nil {
+		t.Errorf("Expected the decrypted presentation to verify, got %v", err)
+	}
+
+	// Each encryption uses a fresh key
+	again, _ := CreateEncryptedPresentation("did:key:z6MkHolder", holderPriv, verifierPub, credentials, "did:key:z6MkVerifier", "nonce-1", CreateOptions{})
+	if again == token {
+		t.Error("Expected two encryptions to differ")
+	}
+}
+
+func TestEncryptedPresentationErrors(t *testing.T) {
+	holderPub, holderPriv := generateTestKeypair(t)
+	verifierPub, verifierPriv := generateTestKeypair(t)
+	_, otherPriv := generateTestKeypair(t)
+
+	token, err := CreateEncryptedPresentation("did:key:z6MkHolder", holderPriv, verifierPub, []string{"v4.public.cred"}, "aud", "nonce", CreateOptions{})
+	if err != nil {
+		t.Fatalf("CreateEncryptedPresentation failed: %v", err)
+	}
+
+	// Only the intended verifier can decrypt
+	if _, err := DecryptPresentation(token, otherPriv); err != ErrDecryptionFailed {
+		t.Errorf("Expected ErrDecryptionFailed for another verifier's key, got %v", err)
+	}
+
+	// Any change to the ciphertext or footer is detected
+	parts := strings.Split(token, ".")
+	payload := []byte(parts[2])
+	if payload[10] == 'A' {
+		payload[10] = 'B'
+	} else {
+		payload[10] = 'A'
+	}
+	tampered := strings.Join([]string{parts[0], parts[1], string(payload), parts[3]}, ".")
+	if _, err := DecryptPresentation(tampered, verifierPriv); err != ErrDecryptionFailed {
+		t.Errorf("Expected ErrDecryptionFailed for a modified token, got %v", err)
+	}
+	if _, err := DecryptPresentation(strings.Join(parts[:3], "."), verifierPriv); err != ErrDecryptionFailed {
+		t.Errorf("Expected ErrDecryptionFailed without the footer, got %v", err)
+	}
+
+	// A public presentation is not an encrypted one
+	public, _ := CreatePresentation("did:key:z6MkHolder", holderPriv, []string{"v4.public.cred"}, "aud", "nonce")
+	if _, err := DecryptPresentation(public, verifierPriv); err != ErrNotEncrypted {
+		t.Errorf("Expected ErrNotEncrypted for a public presentation, got %v", err)
+	}
+
+	// The inner presentation is still checked
+	if _, err := VerifyEncryptedPresentation(token, verifierPriv, holderPub, "other", "nonce"); !errors.Is(err, ErrAudienceMismatch) {
+		t.Errorf("Expected ErrAudienceMismatch, got %v", err)
+	}
+	if _, err := VerifyEncryptedPresentation(token, verifierPriv, holderPub, "aud", "other"); !errors.Is(err, ErrNonceMismatch) {
+		t.Errorf("Expected ErrNonceMismatch, got %v", err)
+	}
+	otherHolder, _ := generateTestKeypair(t)
+	if _, err := VerifyEncryptedPresentation(token, verifierPriv, otherHolder, "aud", "nonce"); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid for another holder key, got %v", err)
+	}
+}
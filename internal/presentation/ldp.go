@@ -0,0 +1,210 @@
+package presentation
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mr-tron/base58"
+
+	"github.com/veriglob/veriglob-core/internal/vc"
+)
+
+var (
+	ErrMissingProof      = errors.New("presentation has no proof")
+	ErrInvalidProof      = errors.New("invalid presentation proof")
+	ErrChallengeMismatch = errors.New("proof challenge mismatch")
+	ErrDomainMismatch    = errors.New("proof domain mismatch")
+)
+
+// LDProofType identifies the proof suite used for JSON presentations. The
+// signing input is the deterministic JSON encoding of the presentation with
+// an empty proofValue, not RDF dataset canonicalization, so these proofs are
+// only verifiable by Veriglob.
+const LDProofType = "VeriglobEd25519Signature2024"
+
+// Proof is an embedded linked-data style proof. Challenge and domain carry
+// the same replay protection as the PASETO nonce and audience, and created
+// stands in for the token's expiry.
+type Proof struct {
+	Type               string    `json:"type"`
+	Created            time.Time `json:"created"`
+	VerificationMethod string    `json:"verificationMethod"`
+	ProofPurpose       string    `json:"proofPurpose"`
+	Challenge          string    `json:"challenge"`
+	Domain             string    `json:"domain"`
+	ProofValue         string    `json:"proofValue"`
+}
+
+// LDPresentation is a JSON Verifiable Presentation carrying an embedded proof
+// instead of being wrapped in a PASETO token
+type LDPresentation struct {
+	VerifiablePresentation
+	Proof *Proof `json:"proof,omitempty"`
+}
+
+// CreateLDPresentation creates a JSON presentation with an embedded proof
+// bound to the verifier's domain and challenge
+func CreateLDPresentation(
+	holderDID string,
+	holderPrivateKey ed25519.PrivateKey,
+	credentials []string,
+	domain string,
+	challenge string,
+) (*LDPresentation, error) {
+	if len(credentials) == 0 {
+		return nil, errors.New("at least one credential is required")
+	}
+	if len(holderPrivateKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("private key must be ed25519.PrivateKey")
+	}
+
+	presentationID, err := generatePresentationID()
+	if err != nil {
+		return nil, err
+	}
+
+	vp := &LDPresentation{
+		VerifiablePresentation: VerifiablePresentation{
+			Context: []string{
+				"https://www.w3.org/2018/credentials/v1",
+			},
+			Type: []string{
+				"VerifiablePresentation",
+			},
+			ID:                   presentationID,
+			Holder:               holderDID,
+			VerifiableCredential: credentials,
+		},
+		Proof: &Proof{
+			Type:               LDProofType,
+			Created:            time.Now().UTC().Truncate(time.Second),
+			VerificationMethod: holderDID + "#key-1",
+			ProofPurpose:       "authentication",
+			Challenge:          challenge,
+			Domain:             domain,
+		},
+	}
+
+	signingInput, err := vp.signingInput()
+	if err != nil {
+		return nil, err
+	}
+	vp.Proof.ProofValue = "z" + base58.Encode(ed25519.Sign(holderPrivateKey, signingInput))
+
+	return vp, nil
+}
+
+// LDVerifyOptions configures VerifyLDPresentationWithOptions
+type LDVerifyOptions struct {
+	// MaxAge is how long after proof.created the presentation is accepted;
+	// zero uses DefaultPresentationTTL
+	MaxAge time.Duration
+	// Now is the time the proof's age is measured at; zero uses time.Now
+	Now time.Time
+}
+
+// VerifyLDPresentation checks the embedded proof and, when provided, that it
+// is bound to the expected domain and challenge. A proof created more than
+// DefaultPresentationTTL ago returns ErrPresentationExpired.
+//
+// The challenge is what stops a captured presentation being replayed within
+// that window: verifiers must issue a fresh challenge for each request and
+// pass it here. An empty expectedChallenge skips the check and accepts any
+// presentation the holder ever signed for the domain.
+func VerifyLDPresentation(
+	vp *LDPresentation,
+	holderPublicKey ed25519.PublicKey,
+	expectedDomain string,
+	expectedChallenge string,
+) error {
+	return VerifyLDPresentationWithOptions(vp, holderPublicKey, expectedDomain, expectedChallenge, LDVerifyOptions{})
+}
+
+// VerifyLDPresentationWithOptions verifies a JSON presentation like
+// VerifyLDPresentation, with the proof's maximum age and the clock taken from
+// opts
+func VerifyLDPresentationWithOptions(
+	vp *LDPresentation,
+	holderPublicKey ed25519.PublicKey,
+	expectedDomain string,
+	expectedChallenge string,
+	opts LDVerifyOptions,
+) error {
+	if vp.Proof == nil {
+		return ErrMissingProof
+	}
+	if vp.Proof.Type != LDProofType || len(holderPublicKey) != ed25519.PublicKeySize {
+		return ErrInvalidProof
+	}
+
+	// The proof must be made by a key the holder controls
+	controller, _, _ := strings.Cut(vp.Proof.VerificationMethod, "#")
+	if controller != vp.Holder {
+		return ErrInvalidProof
+	}
+
+	if !strings.HasPrefix(vp.Proof.ProofValue, "z") {
+		return ErrInvalidProof
+	}
+	signature, err := base58.Decode(vp.Proof.ProofValue[1:])
+	if err != nil {
+		return ErrInvalidProof
+	}
+
+	signingInput, err := vp.signingInput()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(holderPublicKey, signingInput, signature) {
+		return ErrInvalidProof
+	}
+
+	if err := checkProofCreated(vp.Proof, opts.MaxAge, vc.DefaultLeeway, opts.Now); err != nil {
+		return err
+	}
+
+	// Verify domain if provided
+	if expectedDomain != "" && vp.Proof.Domain != expectedDomain {
+		return ErrDomainMismatch
+	}
+
+	// Verify challenge if provided
+	if expectedChallenge != "" && vp.Proof.Challenge != expectedChallenge {
+		return ErrChallengeMismatch
+	}
+
+	return nil
+}
+
+// checkProofCreated rejects a proof created more than maxAge before now with
+// ErrPresentationExpired, and one created after now with ErrInvalidProof.
+// Both tolerate leeway of clock skew; a zero maxAge uses
+// DefaultPresentationTTL and a zero now the current time.
+func checkProofCreated(proof *Proof, maxAge, leeway time.Duration, now time.Time) error {
+	if maxAge <= 0 {
+		maxAge = DefaultPresentationTTL
+	}
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if proof.Created.IsZero() || proof.Created.After(now.Add(leeway)) {
+		return ErrInvalidProof
+	}
+	if now.Sub(proof.Created) > maxAge+leeway {
+		return fmt.Errorf("%w: proof created %s", ErrPresentationExpired, proof.Created.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// signingInput is the presentation encoded with an empty proofValue
+func (vp *LDPresentation) signingInput() ([]byte, error) {
+	unsigned := *vp
+	proof := *vp.Proof
+	proof.ProofValue = ""
+	unsigned.Proof = &proof
+	return json.Marshal(unsigned)
+}
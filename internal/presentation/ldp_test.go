@@ -0,0 +1,108 @@
+package presentation
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLDPresentationChallengeAndDomain(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+	holderDID := "did:key:z6MkHolder"
+	domain := "did:key:z6MkVerifier"
+	challenge := "challenge-12345"
+
+	vp, err := CreateLDPresentation(holderDID, priv, []string{"v4.public.test-credential-token"}, domain, challenge)
+	if err != nil {
+		t.Fatalf("Failed to create LD presentation: %v", err)
+	}
+
+	if vp.Proof.Challenge != challenge {
+		t.Errorf("Expected proof.challenge %s, got %s", challenge, vp.Proof.Challenge)
+	}
+	if vp.Proof.Domain != domain {
+		t.Errorf("Expected proof.domain %s, got %s", domain, vp.Proof.Domain)
+	}
+
+	// Round-trip through JSON as a verifier would receive it
+	data, _ := json.Marshal(vp)
+	var received LDPresentation
+	if err := json.Unmarshal(data, &received); err != nil {
+		t.Fatalf("Failed to parse LD presentation: %v", err)
+	}
+
+	if err := VerifyLDPresentation(&received, pub, domain, challenge); err != nil {
+		t.Errorf("Expected matching challenge and domain to verify, got %v", err)
+	}
+	if err := VerifyLDPresentation(&received, pub, domain, "other-challenge"); err != ErrChallengeMismatch {
+		t.Errorf("Expected ErrChallengeMismatch, got %v", err)
+	}
+	if err := VerifyLDPresentation(&received, pub, "did:key:z6MkOther", challenge); err != ErrDomainMismatch {
+		t.Errorf("Expected ErrDomainMismatch, got %v", err)
+	}
+	if err := VerifyLDPresentation(&received, pub, "", ""); err != nil {
+		t.Errorf("Expected empty expectations to skip checks, got %v", err)
+	}
+}
+
+func TestLDPresentationTamperedProof(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+	otherPub, _ := generateTestKeypair(t)
+
+	vp, _ := CreateLDPresentation("did:key:z6MkHolder", priv, []string{"v4.public.test"}, "did:key:z6MkVerifier", "challenge")
+
+	// Rewriting the challenge invalidates the signature rather than passing the check
+	vp.Proof.Challenge = "replayed-challenge"
+	if err := VerifyLDPresentation(vp, pub, "", "replayed-challenge"); err != ErrInvalidProof {
+		t.Errorf("Expected ErrInvalidProof for rewritten challenge, got %v", err)
+	}
+
+	vp, _ = CreateLDPresentation("did:key:z6MkHolder", priv, []string{"v4.public.test"}, "did:key:z6MkVerifier", "challenge")
+	if err := VerifyLDPresentation(vp, otherPub, "", ""); err != ErrInvalidProof {
+		t.Errorf("Expected ErrInvalidProof for wrong key, got %v", err)
+	}
+
+	vp.Proof = nil
+	if err := VerifyLDPresentation(vp, pub, "", ""); err != ErrMissingProof {
+		t.Errorf("Expected ErrMissingProof, got %v", err)
+	}
+}
+
+func TestLDPresentationFreshness(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+
+	vp, err := CreateLDPresentation("did:key:z6MkHolder", priv, []string{"v4.public.test"}, "did:key:z6MkVerifier", "challenge")
+	if err != nil {
+		t.Fatalf("Failed to create LD presentation: %v", err)
+	}
+	created := vp.Proof.Created
+
+	tests := []struct {
+		name string
+		opts LDVerifyOptions
+		want error
+	}{
+		{"fresh", LDVerifyOptions{}, nil},
+		{"within default max age", LDVerifyOptions{Now: created.Add(DefaultPresentationTTL)}, nil},
+		{"older than default max age", LDVerifyOptions{Now: created.Add(DefaultPresentationTTL + 2*time.Minute)}, ErrPresentationExpired},
+		{"older than max age", LDVerifyOptions{MaxAge: time.Minute, Now: created.Add(5 * time.Minute)}, ErrPresentationExpired},
+		{"longer max age", LDVerifyOptions{MaxAge: time.Hour, Now: created.Add(30 * time.Minute)}, nil},
+		{"created in the future", LDVerifyOptions{Now: created.Add(-time.Hour)}, ErrInvalidProof},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyLDPresentationWithOptions(vp, pub, "did:key:z6MkVerifier", "challenge", tt.opts)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// Rewriting the created time invalidates the signature rather than passing the check
+	vp.Proof.Created = time.Now().UTC().Truncate(time.Second).Add(time.Minute)
+	if err := VerifyLDPresentation(vp, pub, "", ""); err != ErrInvalidProof {
+		t.Errorf("Expected ErrInvalidProof for a rewritten created time, got %v", err)
+	}
+}
@@ -0,0 +1,285 @@
+package presentation
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mr-tron/base58"
+
+	"github.com/veriglob/veriglob-core/internal/resolver"
+	"github.com/veriglob/veriglob-core/internal/vc"
+)
+
+var (
+	ErrNoHolders         = errors.New("presentation has no holders")
+	ErrDuplicateHolder   = errors.New("holder appears more than once in presentation")
+	ErrHolderNotIncluded = errors.New("holder is not part of the presentation")
+	ErrMissingBinding    = errors.New("presentation proof requires a domain and challenge")
+)
+
+// MultiHolderPresentationType is the additional type of a presentation
+// combining credentials of several holders
+const MultiHolderPresentationType = "MultiHolderPresentation"
+
+// HolderPortion is the credentials one holder contributes to a
+// MultiHolderPresentation
+type HolderPortion struct {
+	Holder               string   `json:"holder"`
+	VerifiableCredential []string `json:"verifiableCredential"`
+}
+
+// MultiHolderPresentation is a JSON presentation of credentials held by
+// several subjects, e.g. both parties to a contract. Each holder adds a
+// proof, in the form of an LDPresentation proof, over the whole presentation,
+// so no portion can be moved into another presentation:
+//
+//	{
+//	  "@context": ["https://www.w3.org/2018/credentials/v1"],
+//	  "type": ["VerifiablePresentation", "MultiHolderPresentation"],
+//	  "id": "urn:uuid:...",
+//	  "holders": [
+//	    {"holder": "did:key:zAlice", "verifiableCredential": ["v4.public..."]},
+//	    {"holder": "did:key:zBob", "verifiableCredential": ["v4.public..."]}
+//	  ],
+//	  "proof": [
+//	    {"verificationMethod": "did:key:zAlice#key-1", "proofValue": "z...", ...},
+//	    {"verificationMethod": "did:key:zBob#key-1", "proofValue": "z...", ...}
+//	  ]
+//	}
+type MultiHolderPresentation struct {
+	Context []string        `json:"@context"`
+	Type    []string        `json:"type"`
+	ID      string          `json:"id"`
+	Holders []HolderPortion `json:"holders"`
+	Proof   []*Proof        `json:"proof,omitempty"`
+}
+
+// NewMultiHolderPresentation creates an unsigned presentation of each
+// holder's credentials, to be passed to every holder for Sign. A holder
+// listed twice returns ErrDuplicateHolder.
+func NewMultiHolderPresentation(portions []HolderPortion) (*MultiHolderPresentation, error) {
+	if len(portions) == 0 {
+		return nil, ErrNoHolders
+	}
+	seen := make(map[string]bool, len(portions))
+	for _, portion := range portions {
+		if portion.Holder == "" {
+			return nil, ErrNoHolders
+		}
+		if seen[portion.Holder] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateHolder, portion.Holder)
+		}
+		seen[portion.Holder] = true
+		if len(portion.VerifiableCredential) == 0 {
+			return nil, fmt.Errorf("holder %s: at least one credential is required", portion.Holder)
+		}
+	}
+
+	presentationID, err := generatePresentationID()
+	if err != nil {
+		return nil, err
+	}
+	return &MultiHolderPresentation{
+		Context: []string{"https://www.w3.org/2018/credentials/v1"},
+		Type:    []string{"VerifiablePresentation", MultiHolderPresentationType},
+		ID:      presentationID,
+		Holders: append([]HolderPortion(nil), portions...),
+	}, nil
+}
+
+// Sign adds holderDID's proof, bound to the verifier's domain and challenge.
+// The proof covers every portion but not the other holders' proofs, so
+// holders can sign in any order; signing again replaces the holder's proof.
+// A holder without a portion returns ErrHolderNotIncluded, and an empty
+// domain or challenge ErrMissingBinding.
+func (p *MultiHolderPresentation) Sign(holderDID string, holderPrivateKey ed25519.PrivateKey, domain, challenge string) error {
+	if p.portion(holderDID) == nil {
+		return ErrHolderNotIncluded
+	}
+	if domain == "" || challenge == "" {
+		return ErrMissingBinding
+	}
+	if len(holderPrivateKey) != ed25519.PrivateKeySize {
+		return errors.New("private key must be ed25519.PrivateKey")
+	}
+
+	proof := &Proof{
+		Type:               LDProofType,
+		Created:            time.Now().UTC().Truncate(time.Second),
+		VerificationMethod: holderDID + "#key-1",
+		ProofPurpose:       "authentication",
+		Challenge:          challenge,
+		Domain:             domain,
+	}
+	signingInput, err := p.signingInput(proof)
+	if err != nil {
+		return err
+	}
+	proof.ProofValue = "z" + base58.Encode(ed25519.Sign(holderPrivateKey, signingInput))
+
+	for i, existing := range p.Proof {
+		if proofController(existing) == holderDID {
+			p.Proof[i] = proof
+			return nil
+		}
+	}
+	p.Proof = append(p.Proof, proof)
+	return nil
+}
+
+// VerifyMultiHolderPresentation checks that every holder signed the
+// presentation with the key their DID resolves to, bound to the expected
+// domain and challenge, and then verifies each credential like
+// VerifyPresentationWithCredentials. Holder binding is always required: a
+// credential whose subject is not the holder it is listed under fails with
+// ErrHolderSubjectMismatch. Results are in presentation order across all
+// portions. An error is returned only if the presentation itself fails: a
+// holder without a proof returns ErrMissingProof, and a proof that does not
+// verify or is not from a holder returns ErrInvalidProof. A proof created
+// more than opts.ProofMaxAge ago returns ErrPresentationExpired. The domain
+// and challenge cannot be skipped: either one empty returns
+// ErrMissingBinding. With RejectExpiredCredentials, an expired credential
+// returns ErrExpiredCredential together with the results.
+func VerifyMultiHolderPresentation(
+	p *MultiHolderPresentation,
+	expectedDomain string,
+	expectedChallenge string,
+	opts CredentialCheckOptions,
+) (CredentialResults, error) {
+	if expectedDomain == "" || expectedChallenge == "" {
+		return nil, ErrMissingBinding
+	}
+	if len(p.Holders) == 0 {
+		return nil, ErrNoHolders
+	}
+
+	now := time.Now()
+	if opts.Now != nil {
+		now = opts.Now()
+	}
+	leeway := opts.Leeway
+	if leeway == 0 {
+		leeway = vc.DefaultLeeway
+	} else if leeway < 0 {
+		leeway = 0
+	}
+
+	holderKeys := make(map[string]ed25519.PublicKey, len(p.Holders))
+	for _, portion := range p.Holders {
+		if _, dup := holderKeys[portion.Holder]; dup {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateHolder, portion.Holder)
+		}
+		holderKeys[portion.Holder] = nil
+	}
+
+	for _, proof := range p.Proof {
+		if proof == nil {
+			return nil, ErrInvalidProof
+		}
+		holder := proofController(proof)
+		key, included := holderKeys[holder]
+		if !included || key != nil {
+			// Not a holder, or a second proof from one
+			return nil, ErrInvalidProof
+		}
+		key, err := holderPublicKey(holder, opts)
+		if err != nil {
+			return nil, fmt.Errorf("resolving holder %s: %w", holder, err)
+		}
+		if err := p.verifyProof(proof, key, expectedDomain, expectedChallenge); err != nil {
+			return nil, fmt.Errorf("holder %s: %w", holder, err)
+		}
+		if err := checkProofCreated(proof, opts.ProofMaxAge, leeway, now); err != nil {
+			return nil, fmt.Errorf("holder %s: %w", holder, err)
+		}
+		if opts.RequireProofPurpose {
+			if err := proofPurposeResolver(opts).VerifyProofPurpose(holder, key, resolver.ProofPurposeAuthentication); err != nil {
+				return nil, err
+			}
+		}
+		holderKeys[holder] = key
+	}
+
+	opts.RequireHolderBinding = true
+	var results CredentialResults
+	for _, portion := range p.Holders {
+		key := holderKeys[portion.Holder]
+		if key == nil {
+			return nil, fmt.Errorf("%w: holder %s", ErrMissingProof, portion.Holder)
+		}
+		for _, credToken := range portion.VerifiableCredential {
+			results = append(results, verifyEmbeddedCredential(len(results), credToken, portion.Holder, key, nil, opts))
+		}
+	}
+	if opts.RejectExpiredCredentials {
+		if err := results.ExpiredError(); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// verifyProof checks one holder's proof signature and binding
+func (p *MultiHolderPresentation) verifyProof(proof *Proof, key ed25519.PublicKey, expectedDomain, expectedChallenge string) error {
+	if proof.Type != LDProofType || !strings.HasPrefix(proof.ProofValue, "z") {
+		return ErrInvalidProof
+	}
+	signature, err := base58.Decode(proof.ProofValue[1:])
+	if err != nil {
+		return ErrInvalidProof
+	}
+	signingInput, err := p.signingInput(proof)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, signingInput, signature) {
+		return ErrInvalidProof
+	}
+
+	if proof.Domain != expectedDomain {
+		return ErrDomainMismatch
+	}
+	if proof.Challenge != expectedChallenge {
+		return ErrChallengeMismatch
+	}
+	return nil
+}
+
+// signingInput is the presentation encoded with only the given proof, its
+// proofValue empty
+func (p *MultiHolderPresentation) signingInput(proof *Proof) ([]byte, error) {
+	unsigned := *p
+	own := *proof
+	own.ProofValue = ""
+	unsigned.Proof = []*Proof{&own}
+	return json.Marshal(unsigned)
+}
+
+// portion returns the holder's portion, or nil
+func (p *MultiHolderPresentation) portion(holderDID string) *HolderPortion {
+	for i := range p.Holders {
+		if p.Holders[i].Holder == holderDID {
+			return &p.Holders[i]
+		}
+	}
+	return nil
+}
+
+// proofController returns the DID whose key made a proof
+func proofController(proof *Proof) string {
+	controller, _, _ := strings.Cut(proof.VerificationMethod, "#")
+	return controller
+}
+
+// holderPublicKey resolves a holder DID with opts.Resolver, or the default
+// did:key resolver
+func holderPublicKey(holderDID string, opts CredentialCheckOptions) (ed25519.PublicKey, error) {
+	if opts.Resolver != nil {
+		return opts.Resolver.Resolve(holderDID)
+	}
+	return resolver.ResolveDID(holderDID)
+}
@@ -0,0 +1,190 @@
+package presentation
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+	"github.com/veriglob/veriglob-core/internal/vc"
+	"github.com/veriglob/veriglob-core/internal/vc/vctest"
+)
+
+func TestMultiHolderPresentationRoundTrip(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+	alicePub, alicePriv := generateTestKeypair(t)
+	aliceDID, _ := did.CreateDIDKey(alicePub)
+	bobPub, bobPriv := generateTestKeypair(t)
+	bobDID, _ := did.CreateDIDKey(bobPub)
+
+	aliceCred, _ := vc.IssueVCWithID(issuerDID.DID, aliceDID.DID, issuerPriv, vctest.IdentitySubject(aliceDID.DID), "urn:uuid:alice")
+	bobCred, _ := vc.IssueVCWithID(issuerDID.DID, bobDID.DID, issuerPriv, vctest.IdentitySubject(bobDID.DID), "urn:uuid:bob")
+
+	vp, err := NewMultiHolderPresentation([]HolderPortion{
+		{Holder: aliceDID.DID, VerifiableCredential: []string{aliceCred}},
+		{Holder: bobDID.DID, VerifiableCredential: []string{bobCred}},
+	})
+	if err != nil {
+		t.Fatalf("NewMultiHolderPresentation failed: %v", err)
+	}
+	// Holders sign in any order
+	if err := vp.Sign(bobDID.DID, bobPriv, "https://verifier.example", "challenge"); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if err := vp.Sign(aliceDID.DID, alicePriv, "https://verifier.example", "challenge"); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	// The combined presentation travels as JSON
+	data, err := json.Marshal(vp)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var received MultiHolderPresentation
+	if err := json.Unmarshal(data, &received); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(received.Proof) != 2 {
+		t.Fatalf("Expected 2 proofs, got %d", len(received.Proof))
+	}
+
+	results, err := VerifyMultiHolderPresentation(&received, "https://verifier.example", "challenge", CredentialCheckOptions{})
+	if err != nil {
+		t.Fatalf("VerifyMultiHolderPresentation failed: %v", err)
+	}
+	if len(results) != 2 || !results.AllValid() {
+		t.Fatalf("Expected 2 valid credentials, got %+v", results)
+	}
+	if results[0].Subject != aliceDID.DID || results[1].Subject != bobDID.DID {
+		t.Errorf("Expected results in presentation order, got %s and %s", results[0].Subject, results[1].Subject)
+	}
+
+	if _, err := VerifyMultiHolderPresentation(&received, "https://other.example", "challenge", CredentialCheckOptions{}); !errors.Is(err, ErrDomainMismatch) {
+		t.Errorf("Expected ErrDomainMismatch, got %v", err)
+	}
+	if _, err := VerifyMultiHolderPresentation(&received, "https://verifier.example", "other", CredentialCheckOptions{}); !errors.Is(err, ErrChallengeMismatch) {
+		t.Errorf("Expected ErrChallengeMismatch, got %v", err)
+	}
+
+	// The binding cannot be skipped
+	if _, err := VerifyMultiHolderPresentation(&received, "", "challenge", CredentialCheckOptions{}); err != ErrMissingBinding {
+		t.Errorf("Expected ErrMissingBinding without a domain, got %v", err)
+	}
+	if _, err := VerifyMultiHolderPresentation(&received, "https://verifier.example", "", CredentialCheckOptions{}); err != ErrMissingBinding {
+		t.Errorf("Expected ErrMissingBinding without a challenge, got %v", err)
+	}
+	if err := vp.Sign(aliceDID.DID, alicePriv, "https://verifier.example", ""); err != ErrMissingBinding {
+		t.Errorf("Expected ErrMissingBinding signing without a challenge, got %v", err)
+	}
+
+	// Proofs are only accepted for a limited time after they were made
+	created := received.Proof[0].Created
+	tests := []struct {
+		name string
+		opts CredentialCheckOptions
+		want error
+	}{
+		{"within default max age", CredentialCheckOptions{Now: func() time.Time { return created.Add(DefaultPresentationTTL) }}, nil},
+		{"older than default max age", CredentialCheckOptions{Now: func() time.Time { return created.Add(time.Hour) }}, ErrPresentationExpired},
+		{"older than max age", CredentialCheckOptions{ProofMaxAge: time.Minute, Now: func() time.Time { return created.Add(5 * time.Minute) }}, ErrPresentationExpired},
+		{"created in the future", CredentialCheckOptions{Now: func() time.Time { return created.Add(-time.Hour) }}, ErrInvalidProof},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifyMultiHolderPresentation(&received, "https://verifier.example", "challenge", tt.opts)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestMultiHolderPresentationErrors(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+	alicePub, alicePriv := generateTestKeypair(t)
+	aliceDID, _ := did.CreateDIDKey(alicePub)
+	bobPub, bobPriv := generateTestKeypair(t)
+	bobDID, _ := did.CreateDIDKey(bobPub)
+	malloryPub, malloryPriv := generateTestKeypair(t)
+	malloryDID, _ := did.CreateDIDKey(malloryPub)
+
+	aliceCred, _ := vc.IssueVCWithID(issuerDID.DID, aliceDID.DID, issuerPriv, vctest.IdentitySubject(aliceDID.DID), "urn:uuid:alice")
+	bobCred, _ := vc.IssueVCWithID(issuerDID.DID, bobDID.DID, issuerPriv, vctest.IdentitySubject(bobDID.DID), "urn:uuid:bob")
+
+	if _, err := NewMultiHolderPresentation(nil); err != ErrNoHolders {
+		t.Errorf("Expected ErrNoHolders, got %v", err)
+	}
+	if _, err := NewMultiHolderPresentation([]HolderPortion{
+		{Holder: aliceDID.DID, VerifiableCredential: []string{aliceCred}},
+		{Holder: aliceDID.DID, VerifiableCredential: []string{bobCred}},
+	}); !errors.Is(err, ErrDuplicateHolder) {
+		t.Errorf("Expected ErrDuplicateHolder, got %v", err)
+	}
+
+	newSigned := func(portions []HolderPortion) *MultiHolderPresentation {
+		t.Helper()
+		vp, err := NewMultiHolderPresentation(portions)
+		if err != nil {
+			t.Fatalf("NewMultiHolderPresentation failed: %v", err)
+		}
+		vp.Sign(aliceDID.DID, alicePriv, "https://verifier.example", "challenge")
+		vp.Sign(bobDID.DID, bobPriv, "https://verifier.example", "challenge")
+		return vp
+	}
+	portions := []HolderPortion{
+		{Holder: aliceDID.DID, VerifiableCredential: []string{aliceCred}},
+		{Holder: bobDID.DID, VerifiableCredential: []string{bobCred}},
+	}
+
+	// Only holders can sign
+	vp := newSigned(portions)
+	if err := vp.Sign(malloryDID.DID, malloryPriv, "https://verifier.example", "challenge"); err != ErrHolderNotIncluded {
+		t.Errorf("Expected ErrHolderNotIncluded, got %v", err)
+	}
+
+	// Every holder must sign
+	unsigned, _ := NewMultiHolderPresentation(portions)
+	unsigned.Sign(aliceDID.DID, alicePriv, "https://verifier.example", "challenge")
+	if _, err := VerifyMultiHolderPresentation(unsigned, "https://verifier.example", "challenge", CredentialCheckOptions{}); !errors.Is(err, ErrMissingProof) {
+		t.Errorf("Expected ErrMissingProof without Bob's proof, got %v", err)
+	}
+
+	// A proof by the wrong key fails
+	forged, _ := NewMultiHolderPresentation(portions)
+	forged.Sign(aliceDID.DID, alicePriv, "https://verifier.example", "challenge")
+	forged.Sign(bobDID.DID, malloryPriv, "https://verifier.example", "challenge")
+	if _, err := VerifyMultiHolderPresentation(forged, "https://verifier.example", "challenge", CredentialCheckOptions{}); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("Expected ErrInvalidProof for a forged proof, got %v", err)
+	}
+
+	// Swapping credentials between portions breaks every proof
+	swapped := newSigned(portions)
+	swapped.Holders[0].VerifiableCredential, swapped.Holders[1].VerifiableCredential =
+		swapped.Holders[1].VerifiableCredential, swapped.Holders[0].VerifiableCredential
+	if _, err := VerifyMultiHolderPresentation(swapped, "https://verifier.example", "challenge", CredentialCheckOptions{}); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("Expected ErrInvalidProof after moving credentials, got %v", err)
+	}
+
+	// A holder may only prove once
+	extra := newSigned(portions)
+	extra.Proof = append(extra.Proof, extra.Proof[0])
+	if _, err := VerifyMultiHolderPresentation(extra, "https://verifier.example", "challenge", CredentialCheckOptions{}); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("Expected ErrInvalidProof for a repeated proof, got %v", err)
+	}
+
+	// A credential listed under the wrong holder fails on its own
+	misfiled := newSigned([]HolderPortion{
+		{Holder: aliceDID.DID, VerifiableCredential: []string{aliceCred, bobCred}},
+		{Holder: bobDID.DID, VerifiableCredential: []string{bobCred}},
+	})
+	results, err := VerifyMultiHolderPresentation(misfiled, "https://verifier.example", "challenge", CredentialCheckOptions{})
+	if err != nil {
+		t.Fatalf("VerifyMultiHolderPresentation failed: %v", err)
+	}
+	if !results[0].Valid || results[1].Err != ErrHolderSubjectMismatch || !results[2].Valid {
+		t.Errorf("Expected only the misfiled credential to fail with ErrHolderSubjectMismatch, got %+v", results)
+	}
+}
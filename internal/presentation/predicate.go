@@ -0,0 +1,207 @@
+package presentation
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/vc"
+)
+
+var (
+	ErrPredicateNotSatisfied = errors.New("credential does not satisfy the age predicate")
+	ErrNoAgePredicate        = errors.New("presentation carries no age predicate")
+	ErrNotIdentityCredential = errors.New("age predicates need an identity credential with a date of birth")
+	ErrInvalidDateOfBirth    = errors.New("invalid date of birth")
+)
+
+const (
+	// AgePredicateType is the type of the agePredicate presentation claim
+	AgePredicateType = "AgeOver"
+
+	// DateOfBirthLayout is the format of identity credential birth dates
+	DateOfBirthLayout = "2006-01-02"
+)
+
+// AgePredicate is the agePredicate claim of a presentation: the holder's
+// signed statement that the subject of one embedded identity credential is
+// at least MinAge years old. It is not a zero-knowledge proof. The credential
+// is attached so the verifier can check the issuer's signature and recompute
+// the age, which reveals the date of birth to the verifier; use a selective
+// disclosure credential to reveal nothing else.
+type AgePredicate struct {
+	Type    string `json:"type"`
+	MinAge  int    `json:"minAge"`
+	AgeOver bool   `json:"ageOver"`
+	// AsOf is the date, in DateOfBirthLayout, the holder evaluated the predicate
+	AsOf string `json:"asOf"`
+	// Credential is the index of the identity credential in the presentation
+	Credential int `json:"credential"`
+}
+
+// AgeOver reports whether someone born on dateOfBirth (YYYY-MM-DD) is at
+// least minAge years old on the given day. The age increases on the
+// birthday itself; someone born on 29 February turns a year older on
+// 1 March in non-leap years.
+func AgeOver(dateOfBirth string, minAge int, on time.Time) (bool, error) {
+	birth, err := time.Parse(DateOfBirthLayout, dateOfBirth)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInvalidDateOfBirth, err)
+	}
+	y, m, d := on.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	if birth.After(today) {
+		return false, fmt.Errorf("%w: %s is in the future", ErrInvalidDateOfBirth, dateOfBirth)
+	}
+	return !birth.AddDate(minAge, 0, 0).After(today), nil
+}
+
+// CreateAgePresentation creates a presentation asserting only that the
+// holder is at least minAge years old, backed by an identity credential. A
+// selective disclosure credential is reduced to its dateOfBirth disclosure;
+// any other credential is attached whole. A holder who is too young gets
+// ErrPredicateNotSatisfied rather than a presentation.
+func CreateAgePresentation(
+	holderDID string,
+	holderPrivateKey ed25519.PrivateKey,
+	credential string,
+	minAge int,
+	audience string,
+	nonce string,
+) (string, error) {
+	if vc.IsSDCredential(credential) {
+		var err error
+		if credential, err = vc.SelectDisclosures(credential, "dateOfBirth"); err != nil {
+			return "", fmt.Errorf("%w: %w", ErrNotIdentityCredential, err)
+		}
+	}
+
+	dateOfBirth, err := unverifiedDateOfBirth(credential)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	over, err := AgeOver(dateOfBirth, minAge, now)
+	if err != nil {
+		return "", err
+	}
+	if !over {
+		return "", ErrPredicateNotSatisfied
+	}
+
+	return CreatePresentationWithOptions(holderDID, holderPrivateKey, []string{credential}, audience, nonce, CreateOptions{
+		ValidateCredentials: true,
+		agePredicate: &AgePredicate{
+			Type:    AgePredicateType,
+			MinAge:  minAge,
+			AgeOver: true,
+			AsOf:    now.Format(DateOfBirthLayout),
+		},
+	})
+}
+
+// VerifyAgePresentation verifies a presentation made by CreateAgePresentation
+// and checks that it proves an age of at least minAge. The identity
+// credential must verify, be issued to the presentation holder, and carry a
+// date of birth that makes the subject at least minAge today; the holder's
+// own claim is not trusted on its own.
+func VerifyAgePresentation(
+	tokenString string,
+	holderPublicKey ed25519.PublicKey,
+	expectedAudience string,
+	expectedNonce string,
+	minAge int,
+	opts CredentialCheckOptions,
+) (*VPClaims, error) {
+	opts.RequireHolderBinding = true
+	claims, results, err := VerifyPresentationWithCredentials(tokenString, holderPublicKey, expectedAudience, expectedNonce, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	predicate := claims.AgePredicate
+	if predicate == nil || predicate.Type != AgePredicateType {
+		return nil, ErrNoAgePredicate
+	}
+	if !predicate.AgeOver || predicate.MinAge < minAge {
+		return nil, ErrPredicateNotSatisfied
+	}
+	if predicate.Credential < 0 || predicate.Credential >= len(results) {
+		return nil, ErrCredentialIndex
+	}
+
+	result := results[predicate.Credential]
+	if !result.Valid {
+		return nil, result.Err
+	}
+	dateOfBirth, err := identityDateOfBirth(result.Claims)
+	if err != nil {
+		return nil, err
+	}
+
+	over, err := AgeOver(dateOfBirth, minAge, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !over {
+		return nil, ErrPredicateNotSatisfied
+	}
+	return claims, nil
+}
+
+// unverifiedDateOfBirth reads the date of birth of an identity credential
+// the holder is about to present, disclosing it first if the credential is
+// a selective disclosure credential
+func unverifiedDateOfBirth(credential string) (string, error) {
+	token := credential
+	var disclosures []string
+	if vc.IsSDCredential(credential) {
+		var err error
+		if token, disclosures, err = vc.SplitSDCredential(credential); err != nil {
+			return "", err
+		}
+	}
+
+	claims, err := vc.UnverifiedClaims(token)
+	if err != nil {
+		return "", err
+	}
+	for _, encoded := range disclosures {
+		d, err := vc.ParseDisclosure(encoded)
+		if err != nil {
+			return "", err
+		}
+		if d.Name == "dateOfBirth" {
+			claims.VC.CredentialSubject = map[string]json.RawMessage{"dateOfBirth": d.Value}
+		}
+	}
+	return identityDateOfBirth(claims)
+}
+
+// identityDateOfBirth returns the dateOfBirth of an identity credential's subject
+func identityDateOfBirth(claims *vc.VCClaims) (string, error) {
+	isIdentity := false
+	for _, t := range claims.VC.Type {
+		if t == vc.CredentialTypeIdentity {
+			isIdentity = true
+		}
+	}
+	if !isIdentity {
+		return "", ErrNotIdentityCredential
+	}
+
+	subjectJSON, err := json.Marshal(claims.VC.CredentialSubject)
+	if err != nil {
+		return "", err
+	}
+	var subject struct {
+		DateOfBirth string `json:"dateOfBirth"`
+	}
+	if err := json.Unmarshal(subjectJSON, &subject); err != nil || subject.DateOfBirth == "" {
+		return "", ErrNotIdentityCredential
+	}
+	return subject.DateOfBirth, nil
+}
@@ -0,0 +1,140 @@
+package presentation
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+	"github.com/veriglob/veriglob-core/internal/vc"
+	"github.com/veriglob/veriglob-core/internal/vc/vctest"
+)
+
+func TestAgeOverBoundary(t *testing.T) {
+	on := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		dateOfBirth string
+		want        bool
+	}{
+		{"18th birthday today", "2008-10-17", true},
+		{"18th birthday tomorrow", "2008-10-18", false},
+		{"turned 18 yesterday", "2008-10-16", true},
+		{"well over", "1990-01-01", true},
+		{"a year short", "2009-10-17", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AgeOver(tt.dateOfBirth, 18, on)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAgeOverLeapDay(t *testing.T) {
+	// Born on 29 February: 18 on 1 March of the non-leap year 2026
+	if over, _ := AgeOver("2008-02-29", 18, time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)); over {
+		t.Error("Expected not yet 18 on 28 February")
+	}
+	if over, _ := AgeOver("2008-02-29", 18, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)); !over {
+		t.Error("Expected 18 on 1 March")
+	}
+}
+
+func TestAgeOverInvalidDate(t *testing.T) {
+	for _, dob := range []string{"01/01/1990", "", "2999-01-01"} {
+		if _, err := AgeOver(dob, 18, time.Now()); !errors.Is(err, ErrInvalidDateOfBirth) {
+			t.Errorf("Expected ErrInvalidDateOfBirth for %q, got %v", dob, err)
+		}
+	}
+}
+
+func issueIdentity(t *testing.T, priv ed25519.PrivateKey, holderDID, dateOfBirth string, sd bool) string {
+	t.Helper()
+	subject := vctest.IdentitySubject(holderDID)
+	subject.DateOfBirth = dateOfBirth
+	issue := vc.IssueVCWithOptions
+	if sd {
+		issue = vc.IssueSDVC
+	}
+	cred, err := issue("did:key:zIssuer", holderDID, priv, subject, vc.IssueOptions{})
+	if err != nil {
+		t.Fatalf("Failed to issue: %v", err)
+	}
+	return cred
+}
+
+func TestAgePresentation(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	holderPub, holderPriv := generateTestKeypair(t)
+	holder, _ := did.CreateDIDKey(holderPub)
+	opts := CredentialCheckOptions{IssuerKeys: map[string]ed25519.PublicKey{"did:key:zIssuer": issuerPub}}
+	adult := time.Now().AddDate(-30, 0, 0).Format(DateOfBirthLayout)
+
+	for _, sd := range []bool{false, true} {
+		cred := issueIdentity(t, issuerPriv, holder.DID, adult, sd)
+		token, err := CreateAgePresentation(holder.DID, holderPriv, cred, 18, "aud", "nonce")
+		if err != nil {
+			t.Fatalf("Failed to create age presentation (sd=%v): %v", sd, err)
+		}
+
+		claims, err := VerifyAgePresentation(token, holderPub, "aud", "nonce", 18, opts)
+		if err != nil {
+			t.Fatalf("Failed to verify age presentation (sd=%v): %v", sd, err)
+		}
+		if claims.AgePredicate.MinAge != 18 || !claims.AgePredicate.AgeOver {
+			t.Errorf("Expected an ageOver 18 predicate, got %+v", claims.AgePredicate)
+		}
+
+		// A verifier asking for more than was claimed is refused
+		if _, err := VerifyAgePresentation(token, holderPub, "aud", "nonce", 21, opts); err != ErrPredicateNotSatisfied {
+			t.Errorf("Expected ErrPredicateNotSatisfied, got %v", err)
+		}
+
+		if sd {
+			_, results, _ := VerifyPresentationWithCredentials(token, holderPub, "aud", "nonce", opts)
+			subject := results[0].Claims.VC.CredentialSubject.(map[string]interface{})
+			if _, ok := subject["givenName"]; ok {
+				t.Error("Expected only dateOfBirth to be disclosed")
+			}
+		}
+	}
+}
+
+func TestAgePresentationRejects(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	holderPub, holderPriv := generateTestKeypair(t)
+	holder, _ := did.CreateDIDKey(holderPub)
+	opts := CredentialCheckOptions{IssuerKeys: map[string]ed25519.PublicKey{"did:key:zIssuer": issuerPub}}
+
+	// One day short of 18
+	minor := time.Now().AddDate(-18, 0, 1).Format(DateOfBirthLayout)
+	cred := issueIdentity(t, issuerPriv, holder.DID, minor, false)
+	if _, err := CreateAgePresentation(holder.DID, holderPriv, cred, 18, "aud", "nonce"); err != ErrPredicateNotSatisfied {
+		t.Errorf("Expected ErrPredicateNotSatisfied, got %v", err)
+	}
+
+	// Someone else's credential
+	other := issueIdentity(t, issuerPriv, "did:key:zSomeoneElse", "1990-01-01", false)
+	token, err := CreateAgePresentation(holder.DID, holderPriv, other, 18, "aud", "nonce")
+	if err != nil {
+		t.Fatalf("Failed to create age presentation: %v", err)
+	}
+	if _, err := VerifyAgePresentation(token, holderPub, "aud", "nonce", 18, opts); err != ErrHolderSubjectMismatch {
+		t.Errorf("Expected ErrHolderSubjectMismatch, got %v", err)
+	}
+
+	// A plain presentation carries no predicate
+	adult := issueIdentity(t, issuerPriv, holder.DID, "1990-01-01", false)
+	plain, _ := CreatePresentation(holder.DID, holderPriv, []string{adult}, "aud", "nonce")
+	if _, err := VerifyAgePresentation(plain, holderPub, "aud", "nonce", 18, opts); err != ErrNoAgePredicate {
+		t.Errorf("Expected ErrNoAgePredicate, got %v", err)
+	}
+}
@@ -6,32 +6,137 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
-	"aidanwoods.dev/go-paseto"
+	"github.com/veriglob/veriglob-core/internal/revocation"
+	"github.com/veriglob/veriglob-core/internal/vc"
 )
 
-// VerifiablePresentation represents a VP containing one or more VCs
+var (
+	ErrNotAPresentation    = errors.New("token is a verifiable credential, not a presentation")
+	ErrCredentialIndex     = errors.New("credential index out of range")
+	ErrMalformedCredential = errors.New("malformed credential token")
+	ErrAudienceMismatch    = errors.New("audience mismatch")
+	ErrNonceMismatch       = errors.New("nonce mismatch")
+	ErrPresentationExpired = errors.New("presentation expired")
+	ErrSignatureInvalid    = errors.New("presentation signature is invalid")
+	ErrInvalidTTL          = errors.New("invalid presentation lifetime")
+	ErrHolderNotSigner     = errors.New("presentation holder is not its signer")
+)
+
+const (
+	// DefaultPresentationTTL is how long a presentation is valid when no
+	// lifetime is requested
+	DefaultPresentationTTL = 15 * time.Minute
+
+	// DefaultMaxPresentationTTL is the longest lifetime accepted when
+	// CreateOptions.MaxTTL is unset
+	DefaultMaxPresentationTTL = 24 * time.Hour
+)
+
+// CreateOptions configures presentation creation
+type CreateOptions struct {
+	// ValidateCredentials checks that every credential is a well-formed
+	// v4.public token carrying a vc claim before signing. Signatures are not
+	// checked. Production callers should enable it; it is off by default so
+	// tests can embed placeholder strings.
+	ValidateCredentials bool
+	// TTL is the presentation lifetime; zero uses DefaultPresentationTTL
+	TTL time.Duration
+	// ExpiresAt, when set, is an absolute expiry and takes precedence over TTL
+	ExpiresAt time.Time
+	// MaxTTL caps the lifetime; zero uses DefaultMaxPresentationTTL
+	MaxTTL time.Duration
+	// Domain binds the presentation to the origin requesting it, e.g.
+	// https://verifier.example; empty omits the claim
+	Domain string
+	// Suite, when set, signs the presentation instead of PASETO v4 with the
+	// holder private key, which may then be nil
+	Suite vc.SignatureSuite
+	// Disclose maps the index of a selective disclosure credential to the
+	// claims to reveal from it; the rest of its disclosures are dropped.
+	// Credentials without an entry are presented as given.
+	Disclose map[int][]string
+
+	// StatusProofs are issuer-signed revocation statuses of the credentials,
+	// e.g. from revocation.Registry.StatusProof, for verifiers without
+	// network access
+	StatusProofs []*revocation.StatusProof
+
+	// Encoding serializes the vp claim; empty uses vc.EncodingJSON. The
+	// embedded credentials keep the encoding they were issued with.
+	Encoding vc.Encoding
+
+	// agePredicate is set by CreateAgePresentation
+	agePredicate *AgePredicate
+}
+
+// expiry returns the expiration for a presentation issued at now, or
+// ErrInvalidTTL if the requested lifetime is not positive or exceeds the cap
+func (o CreateOptions) expiry(now time.Time) (time.Time, error) {
+	maxTTL := o.MaxTTL
+	if maxTTL == 0 {
+		maxTTL = DefaultMaxPresentationTTL
+	}
+
+	ttl := o.TTL
+	if !o.ExpiresAt.IsZero() {
+		ttl = o.ExpiresAt.Sub(now)
+	} else if ttl == 0 {
+		ttl = DefaultPresentationTTL
+	}
+
+	if ttl <= 0 {
+		return time.Time{}, fmt.Errorf("%w: lifetime must be positive", ErrInvalidTTL)
+	}
+	if ttl > maxTTL {
+		return time.Time{}, fmt.Errorf("%w: %v exceeds the maximum of %v", ErrInvalidTTL, ttl, maxTTL)
+	}
+	return now.Add(ttl), nil
+}
+
+// VerifiablePresentation represents a VP containing one or more VCs.
+// VerifiableCredential keeps the exact order passed to CreatePresentation,
+// so verifiers may map submissions to credentials by position.
 type VerifiablePresentation struct {
 	Context              []string `json:"@context"`
 	Type                 []string `json:"type"`
 	ID                   string   `json:"id,omitempty"`
 	Holder               string   `json:"holder"`
 	VerifiableCredential []string `json:"verifiableCredential"`
+	// References are the credentials presented by reference, which follow
+	// the inline ones in the signed verifiableCredential array. JSON
+	// presentations with embedded proofs do not carry them.
+	References []CredentialReference `json:"-"`
 }
 
-// VPClaims represents the PASETO claims for a Verifiable Presentation
+// VPClaims represents the token claims for a Verifiable Presentation
 type VPClaims struct {
 	Issuer    string                 `json:"iss"`
 	Subject   string                 `json:"sub"`
 	Audience  string                 `json:"aud"`
 	Nonce     string                 `json:"nonce"`
+	Domain    string                 `json:"domain,omitempty"`
 	IssuedAt  time.Time              `json:"iat"`
 	ExpiresAt time.Time              `json:"exp"`
 	VP        VerifiablePresentation `json:"vp"`
+	// AgePredicate is set on presentations made by CreateAgePresentation
+	AgePredicate *AgePredicate `json:"agePredicate,omitempty"`
+	// StatusProofs are the status proofs attached with CreateOptions.StatusProofs
+	StatusProofs []*revocation.StatusProof `json:"statusProofs,omitempty"`
+}
+
+// Credential returns the inline credential token at position i
+func (c *VPClaims) Credential(i int) (string, error) {
+	if i < 0 || i >= len(c.VP.VerifiableCredential) {
+		return "", ErrCredentialIndex
+	}
+	return c.VP.VerifiableCredential[i], nil
 }
 
-// CreatePresentation creates a signed Verifiable Presentation
+// CreatePresentation creates a signed Verifiable Presentation. Credentials
+// are embedded as given, without validation; see CreatePresentationWithOptions.
 func CreatePresentation(
 	holderDID string,
 	holderPrivateKey ed25519.PrivateKey,
@@ -39,27 +144,78 @@ func CreatePresentation(
 	audience string,
 	nonce string,
 ) (string, error) {
-	if len(credentials) == 0 {
+	return CreatePresentationWithOptions(holderDID, holderPrivateKey, credentials, audience, nonce, CreateOptions{})
+}
+
+// CreatePresentationWithOptions creates a signed Verifiable Presentation.
+// With ValidateCredentials set, a credential that is not a well-formed
+// credential token returns ErrMalformedCredential. A lifetime that is not
+// positive or exceeds the cap returns ErrInvalidTTL.
+func CreatePresentationWithOptions(
+	holderDID string,
+	holderPrivateKey ed25519.PrivateKey,
+	credentials []string,
+	audience string,
+	nonce string,
+	opts CreateOptions,
+) (string, error) {
+	return createPresentation(holderDID, holderPrivateKey, credentials, nil, audience, nonce, opts)
+}
+
+// createPresentation signs a presentation of inline credentials and
+// credential references
+func createPresentation(
+	holderDID string,
+	holderPrivateKey ed25519.PrivateKey,
+	credentials []string,
+	references []CredentialReference,
+	audience string,
+	nonce string,
+	opts CreateOptions,
+) (string, error) {
+	if len(credentials) == 0 && len(references) == 0 {
 		return "", errors.New("at least one credential is required")
 	}
 
-	secretKey, err := paseto.NewV4AsymmetricSecretKeyFromBytes(holderPrivateKey)
-	if err != nil {
-		return "", err
+	if opts.ValidateCredentials {
+		for i, cred := range credentials {
+			if err := vc.CheckWellFormed(cred); err != nil {
+				return "", fmt.Errorf("%w: credential %d", ErrMalformedCredential, i)
+			}
+		}
 	}
 
-	// Generate presentation ID
-	idBytes := make([]byte, 16)
-	if _, err := rand.Read(idBytes); err != nil {
+	if len(opts.Disclose) > 0 {
+		selected := make([]string, len(credentials))
+		copy(selected, credentials)
+		for i, names := range opts.Disclose {
+			if i < 0 || i >= len(credentials) {
+				return "", ErrCredentialIndex
+			}
+			cred, err := vc.SelectDisclosures(credentials[i], names...)
+			if err != nil {
+				return "", fmt.Errorf("credential %d: %w", i, err)
+			}
+			selected[i] = cred
+		}
+		credentials = selected
+	}
+
+	suite := opts.Suite
+	if suite == nil {
+		suite = vc.NewPasetoV4Suite(holderPrivateKey, nil)
+	}
+
+	presentationID, err := generatePresentationID()
+	if err != nil {
 		return "", err
 	}
-	presentationID := "urn:uuid:" + hex.EncodeToString(idBytes[:4]) + "-" +
-		hex.EncodeToString(idBytes[4:6]) + "-" +
-		hex.EncodeToString(idBytes[6:8]) + "-" +
-		hex.EncodeToString(idBytes[8:10]) + "-" +
-		hex.EncodeToString(idBytes[10:])
 
 	now := time.Now()
+	expiresAt, err := opts.expiry(now)
+	if err != nil {
+		return "", err
+	}
 
 	vp := VerifiablePresentation{
 		Context: []string{
@@ -71,6 +227,7 @@ func CreatePresentation(
 		ID:                   presentationID,
 		Holder:               holderDID,
 		VerifiableCredential: credentials,
+		References:           references,
 	}
 
 	vpClaims := VPClaims{
@@ -78,28 +235,57 @@ func CreatePresentation(
 		Subject:   holderDID,
 		Audience:  audience,
 		Nonce:     nonce,
+		Domain:    opts.Domain,
 		IssuedAt:  now,
-		ExpiresAt: now.Add(15 * time.Minute), // Presentations are short-lived
+		ExpiresAt: expiresAt, // Presentations are short-lived
 		VP:        vp,
 	}
 
-	token := paseto.NewToken()
-	token.SetIssuer(vpClaims.Issuer)
-	token.SetSubject(vpClaims.Subject)
-	token.SetAudience(vpClaims.Audience)
-	token.SetIssuedAt(vpClaims.IssuedAt)
-	token.SetExpiration(vpClaims.ExpiresAt)
-	token.SetString("nonce", vpClaims.Nonce)
-
-	vpJSON, err := json.Marshal(vpClaims.VP)
+	vpJSON, err := encodeVP(vpClaims.VP)
 	if err != nil {
 		return "", err
 	}
-	if err := token.Set("vp", json.RawMessage(vpJSON)); err != nil {
+	if vpJSON, err = vc.EncodeClaim(vpJSON, opts.Encoding); err != nil {
 		return "", err
 	}
+	nonceJSON, err := json.Marshal(vpClaims.Nonce)
+	if err != nil {
+		return "", err
+	}
+	claims := &vc.TokenClaims{
+		Issuer:    vpClaims.Issuer,
+		Subject:   vpClaims.Subject,
+		Audience:  vpClaims.Audience,
+		IssuedAt:  vpClaims.IssuedAt,
+		ExpiresAt: vpClaims.ExpiresAt,
+		Custom: map[string]json.RawMessage{
+			"nonce": nonceJSON,
+			"vp":    vpJSON,
+		},
+	}
+	if vpClaims.Domain != "" {
+		domainJSON, err := json.Marshal(vpClaims.Domain)
+		if err != nil {
+			return "", err
+		}
+		claims.Custom["domain"] = domainJSON
+	}
+	if opts.agePredicate != nil {
+		predicateJSON, err := json.Marshal(opts.agePredicate)
+		if err != nil {
+			return "", err
+		}
+		claims.Custom["agePredicate"] = predicateJSON
+	}
+	if len(opts.StatusProofs) > 0 {
+		proofsJSON, err := json.Marshal(opts.StatusProofs)
+		if err != nil {
+			return "", err
+		}
+		claims.Custom["statusProofs"] = proofsJSON
+	}
 
-	return token.V4Sign(secretKey, nil), nil
+	return suite.Sign(claims)
 }
 
 // VerifyPresentation verifies a PASETO VP token and returns the claims
@@ -109,73 +295,128 @@ func VerifyPresentation(
 	expectedAudience string,
 	expectedNonce string,
 ) (*VPClaims, error) {
-	pasetoPublicKey, err := paseto.NewV4AsymmetricPublicKeyFromBytes(holderPublicKey)
-	if err != nil {
-		return nil, err
-	}
-
-	parser := paseto.NewParser()
-	token, err := parser.ParseV4Public(pasetoPublicKey, tokenString, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	claims := &VPClaims{}
+	return VerifyPresentationForDomain(tokenString, holderPublicKey, expectedAudience, expectedNonce, "")
+}
 
-	claims.Issuer, err = token.GetIssuer()
-	if err != nil {
-		return nil, err
-	}
+// VerifyPresentationForDomain verifies a PASETO VP token like
+// VerifyPresentation and also checks its domain. An empty expectedDomain
+// skips the check, as with the audience and nonce; otherwise a presentation
+// without that domain returns ErrDomainMismatch.
+func VerifyPresentationForDomain(
+	tokenString string,
+	holderPublicKey ed25519.PublicKey,
+	expectedAudience string,
+	expectedNonce string,
+	expectedDomain string,
+) (*VPClaims, error) {
+	return VerifyPresentationWithSuite(tokenString, vc.NewPasetoV4Suite(nil, holderPublicKey), expectedAudience, expectedNonce, expectedDomain)
+}
 
-	claims.Subject, err = token.GetSubject()
+// VerifyPresentationWithSuite verifies a presentation signed with the given
+// signature suite, e.g. vc.NewES256Suite for a JWT VP, and checks its
+// audience, nonce and domain like VerifyPresentationForDomain. The suite
+// checks expiry, tolerating its Leeway of clock skew.
+func VerifyPresentationWithSuite(
+	tokenString string,
+	suite vc.SignatureSuite,
+	expectedAudience string,
+	expectedNonce string,
+	expectedDomain string,
+) (*VPClaims, error) {
+	tc, err := suite.Verify(tokenString)
 	if err != nil {
-		return nil, err
+		return nil, presentationError(err)
 	}
 
-	claims.Audience, err = token.GetAudience()
-	if err != nil {
-		return nil, err
+	// A VC token verifies against the same key type; catch it before the
+	// missing aud/nonce claims produce a confusing error
+	if tc.Custom["vp"] == nil && tc.Custom["vc"] != nil {
+		return nil, ErrNotAPresentation
 	}
 
-	claims.IssuedAt, err = token.GetIssuedAt()
-	if err != nil {
-		return nil, err
+	claims := &VPClaims{
+		Issuer:    tc.Issuer,
+		Subject:   tc.Subject,
+		Audience:  tc.Audience,
+		IssuedAt:  tc.IssuedAt,
+		ExpiresAt: tc.ExpiresAt,
 	}
 
-	claims.ExpiresAt, err = token.GetExpiration()
-	if err != nil {
-		return nil, err
+	if err := json.Unmarshal(tc.Custom["nonce"], &claims.Nonce); err != nil {
+		return nil, fmt.Errorf("presentation has no nonce claim: %w", err)
 	}
 
-	claims.Nonce, err = token.GetString("nonce")
-	if err != nil {
-		return nil, err
+	// The domain is optional; presentations created without one omit it
+	if domain, ok := tc.Custom["domain"]; ok {
+		if err := json.Unmarshal(domain, &claims.Domain); err != nil {
+			return nil, err
+		}
 	}
 
 	// Verify audience if provided
 	if expectedAudience != "" && claims.Audience != expectedAudience {
-		return nil, errors.New("audience mismatch")
+		return nil, ErrAudienceMismatch
 	}
 
 	// Verify nonce if provided
 	if expectedNonce != "" && claims.Nonce != expectedNonce {
-		return nil, errors.New("nonce mismatch")
+		return nil, ErrNonceMismatch
 	}
 
-	// Check expiration
-	if time.Now().After(claims.ExpiresAt) {
-		return nil, errors.New("presentation expired")
+	// Verify domain if provided
+	if expectedDomain != "" && claims.Domain != expectedDomain {
+		return nil, ErrDomainMismatch
 	}
 
-	var vp VerifiablePresentation
-	if err := token.Get("vp", &vp); err != nil {
+	if err := decodeVP(tc.Custom["vp"], &claims.VP); err != nil {
 		return nil, err
 	}
-	claims.VP = vp
+	// The holder is who signed; otherwise a presentation signed by anyone
+	// could name someone else as holder and present their credentials
+	if claims.VP.Holder != claims.Issuer {
+		return nil, fmt.Errorf("%w: holder %s, iss %s", ErrHolderNotSigner, claims.VP.Holder, claims.Issuer)
+	}
+	if predicate, ok := tc.Custom["agePredicate"]; ok {
+		if err := json.Unmarshal(predicate, &claims.AgePredicate); err != nil {
+			return nil, err
+		}
+	}
+	if proofs, ok := tc.Custom["statusProofs"]; ok {
+		if err := json.Unmarshal(proofs, &claims.StatusProofs); err != nil {
+			return nil, err
+		}
+	}
 
 	return claims, nil
 }
 
+// presentationError maps a suite verification failure to
+// ErrPresentationExpired or ErrSignatureInvalid, keeping the original error
+// in the chain
+func presentationError(err error) error {
+	switch {
+	case errors.Is(err, vc.ErrTokenExpired):
+		return fmt.Errorf("%w: %w", ErrPresentationExpired, err)
+	case errors.Is(err, vc.ErrTokenSignatureInvalid):
+		return fmt.Errorf("%w: %w", ErrSignatureInvalid, err)
+	default:
+		return err
+	}
+}
+
+// generatePresentationID creates a random URN UUID for a presentation
+func generatePresentationID() (string, error) {
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", err
+	}
+	return "urn:uuid:" + hex.EncodeToString(idBytes[:4]) + "-" +
+		hex.EncodeToString(idBytes[4:6]) + "-" +
+		hex.EncodeToString(idBytes[6:8]) + "-" +
+		hex.EncodeToString(idBytes[8:10]) + "-" +
+		hex.EncodeToString(idBytes[10:]), nil
+}
+
 // GenerateNonce creates a random nonce for challenge-response
 func GenerateNonce() (string, error) {
 	bytes := make([]byte, 32)
@@ -1,10 +1,18 @@
 package presentation
 
 import (
+	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
+	"errors"
+	"strings"
 	"testing"
 	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/veriglob/veriglob-core/internal/vc"
+	"github.com/veriglob/veriglob-core/internal/vc/vctest"
 )
 
 func generateTestKeypair(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
@@ -85,6 +93,45 @@ func TestCreatePresentationNoCredentials(t *testing.T) {
 	}
 }
 
+func TestCreatePresentationValidateCredentials(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+	_, issuerPriv := generateTestKeypair(t)
+	credToken, err := vc.IssueVC("did:key:issuer", "did:key:holder", issuerPriv, vctest.IdentitySubject("did:key:holder"))
+	if err != nil {
+		t.Fatalf("Failed to issue credential: %v", err)
+	}
+	opts := CreateOptions{ValidateCredentials: true}
+
+	token, err := CreatePresentationWithOptions("did:key:holder", priv, []string{credToken}, "did:key:verifier", "nonce", opts)
+	if err != nil {
+		t.Fatalf("Expected well-formed credential to be accepted, got %v", err)
+	}
+	if _, err := VerifyPresentation(token, pub, "did:key:verifier", "nonce"); err != nil {
+		t.Errorf("Failed to verify presentation: %v", err)
+	}
+
+	// A presentation token is well-formed PASETO but carries no vc claim
+	vpToken, _ := CreatePresentation("did:key:holder", priv, []string{credToken}, "did:key:verifier", "nonce")
+
+	malformed := map[string]string{
+		"placeholder":   "cred",
+		"bad payload":   "v4.public.not-base64!",
+		"short payload": "v4.public.AAAA",
+		"presentation":  vpToken,
+	}
+	for name, cred := range malformed {
+		_, err := CreatePresentationWithOptions("did:key:holder", priv, []string{credToken, cred}, "did:key:verifier", "nonce", opts)
+		if !errors.Is(err, ErrMalformedCredential) {
+			t.Errorf("%s: expected ErrMalformedCredential, got %v", name, err)
+		}
+	}
+
+	// Without the option, placeholders are still accepted
+	if _, err := CreatePresentation("did:key:holder", priv, []string{"cred"}, "did:key:verifier", "nonce"); err != nil {
+		t.Errorf("Expected unvalidated presentation to accept placeholders, got %v", err)
+	}
+}
+
 func TestCreatePresentationMultipleCredentials(t *testing.T) {
 	pub, priv := generateTestKeypair(t)
 	credentials := []string{
@@ -115,8 +162,8 @@ func TestVerifyPresentationWrongKey(t *testing.T) {
 	token, _ := CreatePresentation("did:key:holder", priv, []string{"cred"}, "aud", "nonce")
 
 	_, err := VerifyPresentation(token, wrongPub, "aud", "nonce")
-	if err == nil {
-		t.Error("Expected error when verifying with wrong key")
+	if !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid when verifying with wrong key, got %v", err)
 	}
 }
 
@@ -126,8 +173,8 @@ func TestVerifyPresentationWrongAudience(t *testing.T) {
 	token, _ := CreatePresentation("did:key:holder", priv, []string{"cred"}, "did:key:verifier1", "nonce")
 
 	_, err := VerifyPresentation(token, pub, "did:key:verifier2", "nonce")
-	if err == nil {
-		t.Error("Expected error when verifying with wrong audience")
+	if !errors.Is(err, ErrAudienceMismatch) {
+		t.Errorf("Expected ErrAudienceMismatch when verifying with wrong audience, got %v", err)
 	}
 }
 
@@ -137,8 +184,55 @@ func TestVerifyPresentationWrongNonce(t *testing.T) {
 	token, _ := CreatePresentation("did:key:holder", priv, []string{"cred"}, "aud", "nonce1")
 
 	_, err := VerifyPresentation(token, pub, "aud", "nonce2")
-	if err == nil {
-		t.Error("Expected error when verifying with wrong nonce")
+	if !errors.Is(err, ErrNonceMismatch) {
+		t.Errorf("Expected ErrNonceMismatch when verifying with wrong nonce, got %v", err)
+	}
+}
+
+func TestVerifyPresentationDomain(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+
+	token, err := CreatePresentationWithOptions("did:key:holder", priv, []string{"cred"}, "aud", "nonce",
+		CreateOptions{Domain: "https://verifier.example"})
+	if err != nil {
+		t.Fatalf("Failed to create presentation: %v", err)
+	}
+
+	claims, err := VerifyPresentationForDomain(token, pub, "aud", "nonce", "https://verifier.example")
+	if err != nil {
+		t.Fatalf("Failed to verify with matching domain: %v", err)
+	}
+	if claims.Domain != "https://verifier.example" {
+		t.Errorf("Expected domain https://verifier.example, got %s", claims.Domain)
+	}
+
+	_, err = VerifyPresentationForDomain(token, pub, "aud", "nonce", "https://attacker.example")
+	if !errors.Is(err, ErrDomainMismatch) {
+		t.Errorf("Expected ErrDomainMismatch when verifying with wrong domain, got %v", err)
+	}
+
+	// An empty expected domain skips the check
+	if _, err := VerifyPresentationForDomain(token, pub, "aud", "nonce", ""); err != nil {
+		t.Errorf("Expected empty expected domain to skip the check, got %v", err)
+	}
+}
+
+func TestVerifyPresentationMissingDomain(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+
+	token, _ := CreatePresentation("did:key:holder", priv, []string{"cred"}, "aud", "nonce")
+
+	claims, err := VerifyPresentation(token, pub, "aud", "nonce")
+	if err != nil {
+		t.Fatalf("Failed to verify: %v", err)
+	}
+	if claims.Domain != "" {
+		t.Errorf("Expected no domain, got %s", claims.Domain)
+	}
+
+	_, err = VerifyPresentationForDomain(token, pub, "aud", "nonce", "https://verifier.example")
+	if !errors.Is(err, ErrDomainMismatch) {
+		t.Errorf("Expected ErrDomainMismatch for a presentation without a domain, got %v", err)
 	}
 }
 
@@ -175,6 +269,148 @@ func TestPresentationExpiration(t *testing.T) {
 	}
 }
 
+func TestPresentationCustomTTL(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+
+	token, err := CreatePresentationWithOptions("did:key:holder", priv, []string{"cred"}, "aud", "nonce",
+		CreateOptions{TTL: 30 * time.Second})
+	if err != nil {
+		t.Fatalf("Failed to create presentation: %v", err)
+	}
+
+	claims, err := VerifyPresentation(token, pub, "", "")
+	if err != nil {
+		t.Fatalf("Failed to verify: %v", err)
+	}
+
+	lifetime := claims.ExpiresAt.Sub(claims.IssuedAt)
+	if lifetime != 30*time.Second {
+		t.Errorf("Expected a 30s lifetime, got %v", lifetime)
+	}
+}
+
+func TestPresentationAbsoluteExpiry(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+	expiresAt := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+
+	token, err := CreatePresentationWithOptions("did:key:holder", priv, []string{"cred"}, "aud", "nonce",
+		CreateOptions{TTL: time.Minute, ExpiresAt: expiresAt})
+	if err != nil {
+		t.Fatalf("Failed to create presentation: %v", err)
+	}
+
+	claims, err := VerifyPresentation(token, pub, "", "")
+	if err != nil {
+		t.Fatalf("Failed to verify: %v", err)
+	}
+	if !claims.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("Expected expiry %v, got %v", expiresAt, claims.ExpiresAt)
+	}
+}
+
+func TestPresentationInvalidTTL(t *testing.T) {
+	_, priv := generateTestKeypair(t)
+
+	tests := []struct {
+		name string
+		opts CreateOptions
+	}{
+		{"negative TTL", CreateOptions{TTL: -time.Minute}},
+		{"TTL over default cap", CreateOptions{TTL: DefaultMaxPresentationTTL + time.Minute}},
+		{"TTL over custom cap", CreateOptions{TTL: time.Hour, MaxTTL: 5 * time.Minute}},
+		{"expiry in the past", CreateOptions{ExpiresAt: time.Now().Add(-time.Minute)}},
+		{"expiry over cap", CreateOptions{ExpiresAt: time.Now().Add(48 * time.Hour)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreatePresentationWithOptions("did:key:holder", priv, []string{"cred"}, "aud", "nonce", tt.opts)
+			if !errors.Is(err, ErrInvalidTTL) {
+				t.Errorf("Expected ErrInvalidTTL, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyPresentationExpired(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+	secretKey, _ := paseto.NewV4AsymmetricSecretKeyFromBytes(priv)
+
+	token := paseto.NewToken()
+	token.SetIssuer("did:key:holder")
+	token.SetSubject("did:key:holder")
+	token.SetAudience("aud")
+	token.SetIssuedAt(time.Now().Add(-time.Hour))
+	token.SetExpiration(time.Now().Add(-time.Minute))
+	token.SetString("nonce", "nonce")
+	token.Set("vp", VerifiablePresentation{Holder: "did:key:holder", VerifiableCredential: []string{"cred"}})
+
+	_, err := VerifyPresentation(token.V4Sign(secretKey, nil), pub, "aud", "nonce")
+	if !errors.Is(err, ErrPresentationExpired) {
+		t.Errorf("Expected ErrPresentationExpired, got %v", err)
+	}
+	if errors.Is(err, ErrSignatureInvalid) {
+		t.Error("An expired presentation must not report an invalid signature")
+	}
+}
+
+func TestVerifyPresentationLeeway(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+	secretKey, _ := paseto.NewV4AsymmetricSecretKeyFromBytes(priv)
+
+	expiredAgo := func(d time.Duration) string {
+		token := paseto.NewToken()
+		token.SetIssuer("did:key:holder")
+		token.SetSubject("did:key:holder")
+		token.SetAudience("aud")
+		token.SetIssuedAt(time.Now().Add(-time.Hour))
+		token.SetExpiration(time.Now().Add(-d))
+		token.SetString("nonce", "nonce")
+		token.Set("vp", VerifiablePresentation{Holder: "did:key:holder", VerifiableCredential: []string{"cred"}})
+		return token.V4Sign(secretKey, nil)
+	}
+
+	tests := []struct {
+		name    string
+		expired time.Duration
+		leeway  time.Duration
+		wantErr bool
+	}{
+		{"within leeway", 30 * time.Second, 60 * time.Second, false},
+		{"beyond leeway", 90 * time.Second, 60 * time.Second, true},
+		{"within default leeway", 30 * time.Second, 0, false},
+		{"no leeway", 30 * time.Second, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suite := &vc.PasetoV4Suite{PublicKey: pub, Leeway: tt.leeway}
+			_, err := VerifyPresentationWithSuite(expiredAgo(tt.expired), suite, "aud", "nonce", "")
+			if tt.wantErr && !errors.Is(err, ErrPresentationExpired) {
+				t.Errorf("Expected ErrPresentationExpired, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected success, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyPresentationTamperedToken(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+	token, _ := CreatePresentation("did:key:holder", priv, []string{"cred"}, "aud", "nonce")
+
+	tampered := token[:len(token)-4] + "AAAA"
+	_, err := VerifyPresentation(tampered, pub, "aud", "nonce")
+	if !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid for a tampered token, got %v", err)
+	}
+	// The underlying PASETO error stays in the chain
+	if !errors.Is(err, paseto.TokenError{}) {
+		t.Errorf("Expected the PASETO error to be wrapped, got %v", err)
+	}
+}
+
 func TestVerifiablePresentationStructure(t *testing.T) {
 	pub, priv := generateTestKeypair(t)
 	holderDID := "did:key:z6MkTestHolder"
@@ -214,3 +450,137 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+func TestVerifyPresentationRejectsCredentialToken(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+
+	credToken, err := vc.IssueVC("did:key:z6MkIssuer", "did:key:z6MkHolder", priv, vctest.IdentitySubject("did:key:z6MkHolder"))
+	if err != nil {
+		t.Fatalf("IssueVC failed: %v", err)
+	}
+
+	_, err = VerifyPresentation(credToken, pub, "", "")
+	if err != ErrNotAPresentation {
+		t.Errorf("Expected ErrNotAPresentation, got %v", err)
+	}
+}
+
+func TestPresentationCredentialOrderIsStable(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+
+	credentials := []string{
+		"v4.public.credential-e",
+		"v4.public.credential-a",
+		"v4.public.credential-d",
+		"v4.public.credential-b",
+		"v4.public.credential-c",
+	}
+
+	token, err := CreatePresentation("did:key:z6MkHolder", priv, credentials, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create presentation: %v", err)
+	}
+
+	claims, err := VerifyPresentation(token, pub, "", "")
+	if err != nil {
+		t.Fatalf("Failed to verify presentation: %v", err)
+	}
+
+	if len(claims.VP.VerifiableCredential) != len(credentials) {
+		t.Fatalf("Expected %d credentials, got %d", len(credentials), len(claims.VP.VerifiableCredential))
+	}
+	for i, want := range credentials {
+		got, err := claims.Credential(i)
+		if err != nil {
+			t.Fatalf("Credential(%d) failed: %v", i, err)
+		}
+		if got != want {
+			t.Errorf("Position %d: expected %s, got %s", i, want, got)
+		}
+	}
+
+	if _, err := claims.Credential(len(credentials)); err != ErrCredentialIndex {
+		t.Errorf("Expected ErrCredentialIndex past the end, got %v", err)
+	}
+	if _, err := claims.Credential(-1); err != ErrCredentialIndex {
+		t.Errorf("Expected ErrCredentialIndex for negative index, got %v", err)
+	}
+}
+
+func TestPresentationWithSuite(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	token, err := CreatePresentationWithOptions("did:key:zDnHolder", nil, []string{"cred"}, "did:key:zVerifier", "nonce-1", CreateOptions{
+		Domain: "https://verifier.example",
+		Suite:  vc.NewES256Suite(priv, nil),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create presentation: %v", err)
+	}
+	if strings.HasPrefix(token, "v4.") {
+		t.Fatalf("Expected a compact JWS, got %s", token)
+	}
+
+	suite := vc.NewES256Suite(nil, &priv.PublicKey)
+	claims, err := VerifyPresentationWithSuite(token, suite, "did:key:zVerifier", "nonce-1", "https://verifier.example")
+	if err != nil {
+		t.Fatalf("Failed to verify presentation: %v", err)
+	}
+	if claims.Issuer != "did:key:zDnHolder" || len(claims.VP.VerifiableCredential) != 1 {
+		t.Errorf("Expected the holder's presentation, got %+v", claims)
+	}
+
+	if _, err := VerifyPresentationWithSuite(token, suite, "did:key:zVerifier", "other", ""); err != ErrNonceMismatch {
+		t.Errorf("Expected ErrNonceMismatch, got %v", err)
+	}
+
+	pub, _ := generateTestKeypair(t)
+	if _, err := VerifyPresentation(token, pub, "", ""); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid, got %v", err)
+	}
+}
+
+func TestPresentationSelectiveDisclosure(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	holderPub, holderPriv := generateTestKeypair(t)
+
+	credential, err := vc.IssueSDVC("did:key:zIssuer", "did:key:zHolder", issuerPriv, vctest.IdentitySubject("did:key:zHolder"), vc.IssueOptions{})
+	if err != nil {
+		t.Fatalf("Failed to issue: %v", err)
+	}
+
+	token, err := CreatePresentationWithOptions("did:key:zHolder", holderPriv, []string{credential}, "aud", "nonce", CreateOptions{
+		ValidateCredentials: true,
+		Disclose:            map[int][]string{0: {"dateOfBirth"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create presentation: %v", err)
+	}
+
+	_, results, err := VerifyPresentationWithCredentials(token, holderPub, "aud", "nonce", CredentialCheckOptions{
+		IssuerKeys: map[string]ed25519.PublicKey{"did:key:zIssuer": issuerPub},
+	})
+	if err != nil {
+		t.Fatalf("Failed to verify presentation: %v", err)
+	}
+	if !results.AllValid() {
+		t.Fatalf("Expected the credential to verify, got %v", results[0].Err)
+	}
+	subject := results[0].Claims.VC.CredentialSubject.(map[string]interface{})
+	if subject["dateOfBirth"] != "1990-01-01" {
+		t.Errorf("Expected dateOfBirth 1990-01-01, got %v", subject["dateOfBirth"])
+	}
+	if _, ok := subject["familyName"]; ok {
+		t.Error("Expected familyName to stay undisclosed")
+	}
+
+	_, err = CreatePresentationWithOptions("did:key:zHolder", holderPriv, []string{credential}, "aud", "nonce", CreateOptions{
+		Disclose: map[int][]string{1: {"dateOfBirth"}},
+	})
+	if err != ErrCredentialIndex {
+		t.Errorf("Expected ErrCredentialIndex, got %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+package presentation
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/veriglob/veriglob-core/internal/httpclient"
+	"github.com/veriglob/veriglob-core/internal/revocation"
+	"github.com/veriglob/veriglob-core/internal/vc"
+)
+
+var (
+	ErrInvalidReference         = errors.New("invalid credential reference")
+	ErrReferenceNotFetched      = errors.New("credential is presented by reference and no fetcher is configured")
+	ErrCredentialDigestMismatch = errors.New("referenced credential does not match its digest")
+)
+
+const (
+	// DigestAlgSHA256 prefixes the hex SHA-256 digest of a referenced credential
+	DigestAlgSHA256 = "sha256"
+
+	// maxReferencedCredentialSize bounds the credential read from a reference URL
+	maxReferencedCredentialSize = 1 << 20
+)
+
+// CredentialReference stands in for a credential in a presentation: the
+// verifier fetches the token from ID and checks it against Digest, which the
+// holder's signature covers
+type CredentialReference struct {
+	ID     string `json:"id"`
+	Digest string `json:"digest"`
+}
+
+// CredentialDigest returns the digest of a credential token as used in a
+// CredentialReference: "sha256:" and the hex SHA-256 of the token's bytes
+func CredentialDigest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return DigestAlgSHA256 + ":" + hex.EncodeToString(sum[:])
+}
+
+// NewCredentialReference references a credential token published at id
+func NewCredentialReference(id, token string) CredentialReference {
+	return CredentialReference{ID: id, Digest: CredentialDigest(token)}
+}
+
+// Matches reports whether token is the credential the reference names
+func (r CredentialReference) Matches(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(r.Digest), []byte(CredentialDigest(token))) == 1
+}
+
+// validate checks the reference has an ID and a SHA-256 digest
+func (r CredentialReference) validate() error {
+	alg, digest, ok := strings.Cut(r.Digest, ":")
+	if r.ID == "" || !ok || alg != DigestAlgSHA256 {
+		return ErrInvalidReference
+	}
+	if raw, err := hex.DecodeString(digest); err != nil || len(raw) != sha256.Size {
+		return ErrInvalidReference
+	}
+	return nil
+}
+
+// CredentialFetcher retrieves the credential token a reference points at.
+// The verifier checks the digest; the fetcher need not.
+type CredentialFetcher func(ref CredentialReference) (string, error)
+
+// NewHTTPCredentialFetcher returns a CredentialFetcher that downloads
+// referenced credentials over HTTPS, retrying transient failures as opts
+// configures. Reference IDs come from the holder, so verifiers that run
+// inside a private network should pass a client that cannot reach it.
+func NewHTTPCredentialFetcher(ctx context.Context, opts httpclient.Options) CredentialFetcher {
+	client := httpclient.NewClient(opts)
+	return func(ref CredentialReference) (string, error) {
+		u, err := url.Parse(ref.ID)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return "", fmt.Errorf("%w: %s is not an https URL", ErrInvalidReference, ref.ID)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return "", err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("fetching %s: unexpected status %d", ref.ID, resp.StatusCode)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxReferencedCredentialSize))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(body)), nil
+	}
+}
+
+// CreatePresentationWithReferences creates a signed presentation carrying
+// some credentials inline and others as references, so a holder on a slow
+// link can leave out credentials the verifier can download. References
+// follow the inline credentials in verifiableCredential. A reference without
+// an ID or a sha256 digest returns ErrInvalidReference; opts.Disclose
+// indexes the inline credentials only.
+func CreatePresentationWithReferences(
+	holderDID string,
+	holderPrivateKey ed25519.PrivateKey,
+	credentials []string,
+	references []CredentialReference,
+	audience string,
+	nonce string,
+	opts CreateOptions,
+) (string, error) {
+	for i, ref := range references {
+		if err := ref.validate(); err != nil {
+			return "", fmt.Errorf("%w: reference %d", err, i)
+		}
+	}
+	return createPresentation(holderDID, holderPrivateKey, credentials, references, audience, nonce, opts)
+}
+
+// verifyReferencedCredential fetches a referenced credential, checks it
+// against the reference digest, and verifies it like an embedded one
+func verifyReferencedCredential(index int, ref CredentialReference, holderDID string, holderKey ed25519.PublicKey, proofs []*revocation.StatusProof, opts CredentialCheckOptions) CredentialResult {
+	reference := ref
+	result := CredentialResult{Index: index, Reference: &reference}
+	if err := ref.validate(); err != nil {
+		result.Err = err
+		return result
+	}
+	if opts.FetchCredential == nil {
+		result.Err = ErrReferenceNotFetched
+		return result
+	}
+
+	token, err := opts.FetchCredential(ref)
+	if err != nil {
+		result.Err = fmt.Errorf("fetching %s: %w", ref.ID, err)
+		return result
+	}
+	if !ref.Matches(token) {
+		result.Err = ErrCredentialDigestMismatch
+		return result
+	}
+
+	result = verifyEmbeddedCredential(index, token, holderDID, holderKey, proofs, opts)
+	result.Reference = &reference
+	return result
+}
+
+// vpWire is the vp claim as signed, where verifiableCredential holds both
+// inline tokens and reference objects
+type vpWire struct {
+	Context              []string          `json:"@context"`
+	Type                 []string          `json:"type"`
+	ID                   string            `json:"id,omitempty"`
+	Holder               string            `json:"holder"`
+	VerifiableCredential []json.RawMessage `json:"verifiableCredential"`
+}
+
+// encodeVP encodes the vp claim. Without references the encoding is the
+// plain VerifiablePresentation, as before references existed.
+func encodeVP(vp VerifiablePresentation) ([]byte, error) {
+	if len(vp.References) == 0 {
+		return json.Marshal(vp)
+	}
+
+	wire := vpWire{
+		Context: vp.Context,
+		Type:    vp.Type,
+		ID:      vp.ID,
+		Holder:  vp.Holder,
+	}
+	for _, token := range vp.VerifiableCredential {
+		raw, err := json.Marshal(token)
+		if err != nil {
+			return nil, err
+		}
+		wire.VerifiableCredential = append(wire.VerifiableCredential, raw)
+	}
+	for _, ref := range vp.References {
+		raw, err := json.Marshal(ref)
+		if err != nil {
+			return nil, err
+		}
+		wire.VerifiableCredential = append(wire.VerifiableCredential, raw)
+	}
+	return json.Marshal(wire)
+}
+
+// decodeVP decodes the vp claim in either encoding, splitting
+// verifiableCredential into inline tokens and references
+func decodeVP(data []byte, vp *VerifiablePresentation) error {
+	data, err := vc.DecodeClaim(data)
+	if err != nil {
+		return err
+	}
+	var wire vpWire
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	*vp = VerifiablePresentation{
+		Context: wire.Context,
+		Type:    wire.Type,
+		ID:      wire.ID,
+		Holder:  wire.Holder,
+	}
+	for i, raw := range wire.VerifiableCredential {
+		if len(raw) > 0 && raw[0] == '{' {
+			var ref CredentialReference
+			if err := json.Unmarshal(raw, &ref); err != nil {
+				return fmt.Errorf("%w: credential %d", ErrMalformedCredential, i)
+			}
+			vp.References = append(vp.References, ref)
+			continue
+		}
+		var token string
+		if err := json.Unmarshal(raw, &token); err != nil {
+			return fmt.Errorf("%w: credential %d", ErrMalformedCredential, i)
+		}
+		vp.VerifiableCredential = append(vp.VerifiableCredential, token)
+	}
+	return nil
+}
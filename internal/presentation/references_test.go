@@ -0,0 +1,114 @@
+package presentation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+	"github.com/veriglob/veriglob-core/internal/httpclient"
+	"github.com/veriglob/veriglob-core/internal/vc"
+	"github.com/veriglob/veriglob-core/internal/vc/vctest"
+)
+
+func TestCreatePresentationWithReferences(t *testing.T) {
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+	holderPub, holderPriv := generateTestKeypair(t)
+	holderDID, _ := did.CreateDIDKey(holderPub)
+
+	subject := vctest.IdentitySubject(holderDID.DID)
+	inline, _ := vc.IssueVCWithID(issuerDID.DID, holderDID.DID, issuerPriv, subject, "urn:uuid:inline")
+	published, _ := vc.IssueVCWithID(issuerDID.DID, holderDID.DID, issuerPriv, subject, "urn:uuid:published")
+	swapped, _ := vc.IssueVCWithID(issuerDID.DID, holderDID.DID, issuerPriv, subject, "urn:uuid:swapped")
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/published":
+			w.Write([]byte(published + "\n"))
+		case "/swapped":
+			// Validly signed, but not the credential the holder referenced
+			w.Write([]byte(swapped))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	refs := []CredentialReference{
+		NewCredentialReference(srv.URL+"/published", published),
+		NewCredentialReference(srv.URL+"/swapped", published),
+	}
+	token, err := CreatePresentationWithReferences(holderDID.DID, holderPriv, []string{inline}, refs, "did:key:zVerifier", "nonce", CreateOptions{})
+	if err != nil {
+		t.Fatalf("CreatePresentationWithReferences failed: %v", err)
+	}
+	if strings.Contains(token, published) {
+		t.Error("Expected the referenced credential to be left out of the token")
+	}
+
+	// Without a fetcher, references cannot be verified
+	claims, results, err := VerifyPresentationWithCredentials(token, holderPub, "did:key:zVerifier", "nonce", CredentialCheckOptions{})
+	if err != nil {
+		t.Fatalf("VerifyPresentationWithCredentials failed: %v", err)
+	}
+	if len(claims.VP.VerifiableCredential) != 1 || len(claims.VP.References) != 2 {
+		t.Fatalf("Expected 1 inline credential and 2 references, got %d and %d", len(claims.VP.VerifiableCredential), len(claims.VP.References))
+	}
+	if claims.VP.References[0] != refs[0] {
+		t.Errorf("Expected reference %+v, got %+v", refs[0], claims.VP.References[0])
+	}
+	if !results[0].Valid || !errors.Is(results[1].Err, ErrReferenceNotFetched) {
+		t.Errorf("Expected inline valid and reference unfetched, got %v and %v", results[0].Err, results[1].Err)
+	}
+
+	fetch := NewHTTPCredentialFetcher(context.Background(), httpclient.Options{Client: srv.Client(), MaxRetries: -1})
+	_, results, err = VerifyPresentationWithCredentials(token, holderPub, "did:key:zVerifier", "nonce", CredentialCheckOptions{FetchCredential: fetch})
+	if err != nil {
+		t.Fatalf("VerifyPresentationWithCredentials failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if !results[1].Valid || results[1].CredentialID != "urn:uuid:published" {
+		t.Errorf("Expected the referenced credential to verify, got %v", results[1].Err)
+	}
+	if results[1].Index != 1 || results[1].Reference == nil || results[1].Reference.ID != refs[0].ID {
+		t.Errorf("Expected result 1 to carry its reference, got %+v", results[1])
+	}
+	if results[2].Valid || !errors.Is(results[2].Err, ErrCredentialDigestMismatch) {
+		t.Errorf("Expected ErrCredentialDigestMismatch for a swapped credential, got %v", results[2].Err)
+	}
+}
+
+func TestCredentialReferenceValidation(t *testing.T) {
+	_, holderPriv := generateTestKeypair(t)
+
+	bad := []CredentialReference{
+		{ID: "", Digest: CredentialDigest("token")},
+		{ID: "https://example.com/cred", Digest: "md5:abcd"},
+		{ID: "https://example.com/cred", Digest: "sha256:not-hex"},
+	}
+	for _, ref := range bad {
+		if _, err := CreatePresentationWithReferences("did:key:zHolder", holderPriv, nil, []CredentialReference{ref}, "", "", CreateOptions{}); !errors.Is(err, ErrInvalidReference) {
+			t.Errorf("Expected ErrInvalidReference for %+v, got %v", ref, err)
+		}
+	}
+
+	// References alone make a presentation; inline credentials are optional
+	ref := NewCredentialReference("https://example.com/cred", "token")
+	if _, err := CreatePresentationWithReferences("did:key:zHolder", holderPriv, nil, []CredentialReference{ref}, "", "", CreateOptions{}); err != nil {
+		t.Errorf("Expected a references-only presentation, got %v", err)
+	}
+	if !ref.Matches("token") || ref.Matches("other") {
+		t.Error("Expected the reference to match only its own token")
+	}
+
+	fetch := NewHTTPCredentialFetcher(context.Background(), httpclient.Options{})
+	if _, err := fetch(CredentialReference{ID: "http://example.com/cred"}); !errors.Is(err, ErrInvalidReference) {
+		t.Errorf("Expected ErrInvalidReference for a plain http URL, got %v", err)
+	}
+}
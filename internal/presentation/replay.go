@@ -0,0 +1,115 @@
+package presentation
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/vc"
+)
+
+var (
+	ErrNonceReplayed = errors.New("presentation nonce already used")
+)
+
+// nonceSweepInterval is how often a MemoryNonceStore drops expired nonces
+const nonceSweepInterval = time.Minute
+
+// NonceStore records the nonces of accepted presentations so a presentation
+// cannot be accepted twice. Implementations backed by a shared database let
+// several verifier instances reject each other's replays.
+type NonceStore interface {
+	// Consume records nonce as used until expiresAt. It returns
+	// ErrNonceReplayed if the nonce is already recorded and not yet expired.
+	Consume(nonce string, expiresAt time.Time) error
+}
+
+// MemoryNonceStore is an in-memory NonceStore. Nonces are dropped once they
+// expire, so it holds at most the nonces of presentations still valid. It is
+// safe for concurrent use.
+type MemoryNonceStore struct {
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryNonceStore creates an empty in-memory nonce store
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		seen: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+// Consume records nonce as used until expiresAt, returning ErrNonceReplayed
+// if it is already recorded
+func (s *MemoryNonceStore) Consume(nonce string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if !now.Before(s.nextSweep) {
+		for n, exp := range s.seen {
+			if !now.Before(exp) {
+				delete(s.seen, n)
+			}
+		}
+		s.nextSweep = now.Add(nonceSweepInterval)
+	}
+
+	if exp, ok := s.seen[nonce]; ok && now.Before(exp) {
+		return ErrNonceReplayed
+	}
+	s.seen[nonce] = expiresAt
+	return nil
+}
+
+// Len returns the number of recorded nonces, including expired ones not yet swept
+func (s *MemoryNonceStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.seen)
+}
+
+// NonceExpiry returns how long a NonceStore must remember the nonce of a
+// presentation verified with VerifyPresentation: its expiry plus
+// vc.DefaultLeeway, since until then the presentation still verifies
+func (c *VPClaims) NonceExpiry() time.Time {
+	return c.ExpiresAt.Add(vc.DefaultLeeway)
+}
+
+// VerifyPresentationOnce verifies a presentation like VerifyPresentation and
+// then consumes its nonce in store, so the same presentation, or any other
+// with that nonce, is rejected with ErrNonceReplayed until it expires. The
+// nonce is kept until NonceExpiry, as long as the presentation verifies; a
+// presentation without a
+// nonce returns ErrNonceMismatch, and one valid for longer than
+// DefaultMaxPresentationTTL returns ErrInvalidTTL, which bounds how long
+// the store must remember it.
+func VerifyPresentationOnce(
+	tokenString string,
+	holderPublicKey ed25519.PublicKey,
+	store NonceStore,
+	expectedAudience string,
+	expectedNonce string,
+) (*VPClaims, error) {
+	claims, err := VerifyPresentation(tokenString, holderPublicKey, expectedAudience, expectedNonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Nonce == "" {
+		return nil, fmt.Errorf("%w: presentation has no nonce to consume", ErrNonceMismatch)
+	}
+	if claims.ExpiresAt.After(time.Now().Add(DefaultMaxPresentationTTL)) {
+		return nil, fmt.Errorf("%w: presentation is valid for longer than %v", ErrInvalidTTL, DefaultMaxPresentationTTL)
+	}
+
+	if err := store.Consume(claims.Nonce, claims.NonceExpiry()); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,120 @@
+package presentation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/veriglob/veriglob-core/internal/vc"
+)
+
+func TestVerifyPresentationOnceRejectsReplay(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+	audience := "did:key:z6MkVerifier"
+	nonce, _ := GenerateNonce()
+
+	token, err := CreatePresentation("did:key:z6MkHolder", priv, []string{"v4.public.test-credential-token"}, audience, nonce)
+	if err != nil {
+		t.Fatalf("Failed to create presentation: %v", err)
+	}
+
+	store := NewMemoryNonceStore()
+	if _, err := VerifyPresentationOnce(token, pub, store, audience, nonce); err != nil {
+		t.Fatalf("First verification failed: %v", err)
+	}
+
+	if _, err := VerifyPresentationOnce(token, pub, store, audience, nonce); !errors.Is(err, ErrNonceReplayed) {
+		t.Errorf("Expected ErrNonceReplayed on replay, got %v", err)
+	}
+
+	// A failed verification does not consume the nonce
+	other, _ := GenerateNonce()
+	otherToken, _ := CreatePresentation("did:key:z6MkHolder", priv, []string{"v4.public.test-credential-token"}, audience, other)
+	if _, err := VerifyPresentationOnce(otherToken, pub, store, "did:key:z6MkSomeoneElse", other); !errors.Is(err, ErrAudienceMismatch) {
+		t.Errorf("Expected ErrAudienceMismatch, got %v", err)
+	}
+	if _, err := VerifyPresentationOnce(otherToken, pub, store, audience, other); err != nil {
+		t.Errorf("Expected nonce of a rejected presentation to stay unused, got %v", err)
+	}
+}
+
+func TestVerifyPresentationOnceRequiresNonceAndBoundedLifetime(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+	audience := "did:key:z6MkVerifier"
+	store := NewMemoryNonceStore()
+
+	token, _ := CreatePresentation("did:key:z6MkHolder", priv, []string{"v4.public.test-credential-token"}, audience, "")
+	if _, err := VerifyPresentationOnce(token, pub, store, audience, ""); !errors.Is(err, ErrNonceMismatch) {
+		t.Errorf("Expected ErrNonceMismatch without a nonce, got %v", err)
+	}
+
+	longLived, err := CreatePresentationWithOptions("did:key:z6MkHolder", priv, []string{"v4.public.test-credential-token"}, audience, "nonce-long", CreateOptions{
+		TTL:    48 * time.Hour,
+		MaxTTL: 72 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create presentation: %v", err)
+	}
+	if _, err := VerifyPresentationOnce(longLived, pub, store, audience, "nonce-long"); !errors.Is(err, ErrInvalidTTL) {
+		t.Errorf("Expected ErrInvalidTTL for a presentation outliving the store, got %v", err)
+	}
+	if store.Len() != 0 {
+		t.Errorf("Expected no nonces recorded, got %d", store.Len())
+	}
+}
+
+func TestVerifyPresentationOnceRejectsReplayWithinLeeway(t *testing.T) {
+	pub, priv := generateTestKeypair(t)
+	secretKey, _ := paseto.NewV4AsymmetricSecretKeyFromBytes(priv)
+
+	// Expired, but still within the leeway VerifyPresentation allows
+	token := paseto.NewToken()
+	token.SetIssuer("did:key:holder")
+	token.SetSubject("did:key:holder")
+	token.SetAudience("aud")
+	token.SetIssuedAt(time.Now().Add(-time.Minute))
+	token.SetExpiration(time.Now().Add(-vc.DefaultLeeway / 2))
+	token.SetString("nonce", "nonce")
+	token.Set("vp", VerifiablePresentation{Holder: "did:key:holder", VerifiableCredential: []string{"cred"}})
+	signed := token.V4Sign(secretKey, nil)
+
+	store := NewMemoryNonceStore()
+	if _, err := VerifyPresentationOnce(signed, pub, store, "aud", "nonce"); err != nil {
+		t.Fatalf("Expected a presentation within leeway to verify, got %v", err)
+	}
+	if _, err := VerifyPresentationOnce(signed, pub, store, "aud", "nonce"); !errors.Is(err, ErrNonceReplayed) {
+		t.Errorf("Expected ErrNonceReplayed past exp but within leeway, got %v", err)
+	}
+}
+
+func TestMemoryNonceStoreExpires(t *testing.T) {
+	store := NewMemoryNonceStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	if err := store.Consume("a", now.Add(time.Minute)); err != nil {
+		t.Fatalf("Consume failed: %v", err)
+	}
+	if err := store.Consume("b", now.Add(time.Hour)); err != nil {
+		t.Fatalf("Consume failed: %v", err)
+	}
+	if err := store.Consume("a", now.Add(time.Minute)); !errors.Is(err, ErrNonceReplayed) {
+		t.Errorf("Expected ErrNonceReplayed, got %v", err)
+	}
+
+	// Once expired, a nonce is swept and may be used again
+	now = now.Add(2 * time.Minute)
+	if err := store.Consume("c", now.Add(time.Minute)); err != nil {
+		t.Fatalf("Consume failed: %v", err)
+	}
+	if store.Len() != 2 {
+		t.Errorf("Expected the expired nonce to be swept (2 left), got %d", store.Len())
+	}
+	if err := store.Consume("a", now.Add(time.Minute)); err != nil {
+		t.Errorf("Expected expired nonce to be accepted again, got %v", err)
+	}
+	if err := store.Consume("b", now.Add(time.Hour)); !errors.Is(err, ErrNonceReplayed) {
+		t.Errorf("Expected unexpired nonce to stay recorded, got %v", err)
+	}
+}
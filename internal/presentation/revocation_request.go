@@ -0,0 +1,119 @@
+package presentation
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/resolver"
+	"github.com/veriglob/veriglob-core/internal/vc"
+)
+
+var (
+	ErrNotARevocationRequest = errors.New("token is not a revocation request")
+	ErrNotCredentialSubject  = errors.New("revocation requester is not the credential subject")
+)
+
+// RevocationRequestLifetime is how long a signed revocation request is valid
+const RevocationRequestLifetime = 15 * time.Minute
+
+// RevocationReasonHolderRequest is the registry reason recorded for a
+// credential revoked at its holder's request
+const RevocationReasonHolderRequest = "revoked at holder's request"
+
+// RevocationRequest is a holder's signed request that an issuer revoke one of
+// the holder's credentials
+type RevocationRequest struct {
+	ID           string
+	Requester    string // the holder DID that signed the request
+	CredentialID string
+	IssuedAt     time.Time
+	ExpiresAt    time.Time
+}
+
+// revocationRequestClaim is the body of the "revocationRequest" token claim
+type revocationRequestClaim struct {
+	CredentialID string `json:"credentialId"`
+}
+
+// CreateRevocationRequest signs a request, as the holder, for the issuer to
+// revoke credentialID, e.g. after the holder's device is compromised
+func CreateRevocationRequest(holderDID string, holderPrivateKey ed25519.PrivateKey, credentialID string) (string, error) {
+	if credentialID == "" {
+		return "", errors.New("credential ID is required")
+	}
+
+	requestID, err := generatePresentationID()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	body, err := json.Marshal(revocationRequestClaim{CredentialID: credentialID})
+	if err != nil {
+		return "", err
+	}
+
+	return vc.NewPasetoV4Suite(holderPrivateKey, nil).Sign(&vc.TokenClaims{
+		Issuer:    holderDID,
+		Subject:   holderDID,
+		JTI:       requestID,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(RevocationRequestLifetime),
+		Custom:    map[string]json.RawMessage{"revocationRequest": body},
+	})
+}
+
+// VerifyRevocationRequest checks a revocation request before the issuer acts
+// on it. The requester's key is resolved from the request's issuer DID
+// (res may be nil for did:key), and the requester must be the subject the
+// credential was registered to, or ErrNotCredentialSubject is returned.
+// Revoking is left to the caller, e.g.
+// registry.Revoke(req.CredentialID, RevocationReasonHolderRequest).
+func VerifyRevocationRequest(tokenString string, status StatusChecker, res *resolver.Resolver) (*RevocationRequest, error) {
+	requester, err := vc.UnverifiedIssuer(tokenString)
+	if err != nil {
+		return nil, ErrNotARevocationRequest
+	}
+
+	var holderKey ed25519.PublicKey
+	if res != nil {
+		holderKey, err = res.Resolve(requester)
+	} else {
+		holderKey, err = resolver.ResolveDID(requester)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The suite rejects expired tokens
+	token, err := vc.NewPasetoV4Suite(nil, holderKey).Verify(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	var body revocationRequestClaim
+	if err := json.Unmarshal(token.Custom["revocationRequest"], &body); err != nil || body.CredentialID == "" {
+		return nil, ErrNotARevocationRequest
+	}
+
+	req := &RevocationRequest{
+		ID:           token.JTI,
+		Requester:    requester,
+		CredentialID: body.CredentialID,
+		IssuedAt:     token.IssuedAt,
+		ExpiresAt:    token.ExpiresAt,
+	}
+
+	entry, err := status.CheckStatus(req.CredentialID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.SubjectDID != requester {
+		return nil, ErrNotCredentialSubject
+	}
+
+	return req, nil
+}
@@ -0,0 +1,94 @@
+package presentation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+	"github.com/veriglob/veriglob-core/internal/revocation"
+	"github.com/veriglob/veriglob-core/internal/vc"
+	"github.com/veriglob/veriglob-core/internal/vc/vctest"
+)
+
+func TestRevocationRequestFromSubject(t *testing.T) {
+	holderPub, holderPriv := generateTestKeypair(t)
+	holderDID, _ := did.CreateDIDKey(holderPub)
+
+	registry := revocation.NewRegistry()
+	registry.Register("urn:uuid:cred-1", "did:key:zIssuer", holderDID.DID)
+
+	token, err := CreateRevocationRequest(holderDID.DID, holderPriv, "urn:uuid:cred-1")
+	if err != nil {
+		t.Fatalf("CreateRevocationRequest failed: %v", err)
+	}
+
+	req, err := VerifyRevocationRequest(token, registry, nil)
+	if err != nil {
+		t.Fatalf("VerifyRevocationRequest failed: %v", err)
+	}
+	if req.Requester != holderDID.DID || req.CredentialID != "urn:uuid:cred-1" {
+		t.Errorf("Unexpected request %s/%s", req.Requester, req.CredentialID)
+	}
+	if req.ID == "" || !req.ExpiresAt.After(req.IssuedAt) {
+		t.Errorf("Expected request ID and lifetime, got %q %v-%v", req.ID, req.IssuedAt, req.ExpiresAt)
+	}
+
+	if err := registry.Revoke(req.CredentialID, RevocationReasonHolderRequest); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if revoked, _ := registry.IsRevoked("urn:uuid:cred-1"); !revoked {
+		t.Error("Expected credential to be revoked")
+	}
+}
+
+func TestRevocationRequestFromNonSubject(t *testing.T) {
+	holderPub, _ := generateTestKeypair(t)
+	holderDID, _ := did.CreateDIDKey(holderPub)
+	attackerPub, attackerPriv := generateTestKeypair(t)
+	attackerDID, _ := did.CreateDIDKey(attackerPub)
+
+	registry := revocation.NewRegistry()
+	registry.Register("urn:uuid:cred-1", "did:key:zIssuer", holderDID.DID)
+
+	// Validly signed, but by someone the credential was not issued to
+	token, _ := CreateRevocationRequest(attackerDID.DID, attackerPriv, "urn:uuid:cred-1")
+	if _, err := VerifyRevocationRequest(token, registry, nil); err != ErrNotCredentialSubject {
+		t.Errorf("Expected ErrNotCredentialSubject, got %v", err)
+	}
+
+	// Claims to be the holder but is signed with another key
+	forged, _ := CreateRevocationRequest(holderDID.DID, attackerPriv, "urn:uuid:cred-1")
+	if _, err := VerifyRevocationRequest(forged, registry, nil); err == nil {
+		t.Error("Expected a request signed by another key to fail")
+	}
+
+	// Unknown credential
+	token, _ = CreateRevocationRequest(attackerDID.DID, attackerPriv, "urn:uuid:unknown")
+	if _, err := VerifyRevocationRequest(token, registry, nil); !errors.Is(err, revocation.ErrCredentialNotFound) {
+		t.Errorf("Expected ErrCredentialNotFound, got %v", err)
+	}
+
+	if revoked, _ := registry.IsRevoked("urn:uuid:cred-1"); revoked {
+		t.Error("Credential must not be revoked by a rejected request")
+	}
+}
+
+func TestVerifyRevocationRequestRejectsOtherTokens(t *testing.T) {
+	holderPub, holderPriv := generateTestKeypair(t)
+	holderDID, _ := did.CreateDIDKey(holderPub)
+	registry := revocation.NewRegistry()
+
+	vpToken, _ := CreatePresentation(holderDID.DID, holderPriv, []string{"cred"}, "did:key:zVerifier", "nonce")
+	if _, err := VerifyRevocationRequest(vpToken, registry, nil); err != ErrNotARevocationRequest {
+		t.Errorf("Expected ErrNotARevocationRequest for a presentation, got %v", err)
+	}
+
+	credToken, _ := vc.IssueVC(holderDID.DID, holderDID.DID, holderPriv, vctest.IdentitySubject(holderDID.DID))
+	if _, err := VerifyRevocationRequest(credToken, registry, nil); err != ErrNotARevocationRequest {
+		t.Errorf("Expected ErrNotARevocationRequest for a credential, got %v", err)
+	}
+
+	if _, err := VerifyRevocationRequest("garbage", registry, nil); err != ErrNotARevocationRequest {
+		t.Errorf("Expected ErrNotARevocationRequest for garbage, got %v", err)
+	}
+}
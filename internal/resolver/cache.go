@@ -0,0 +1,114 @@
+package resolver
+
+import (
+	"container/list"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+)
+
+// DefaultCacheSize is the number of DID documents a resolver cache holds
+// when NewResolverWithCache is given a size that is not positive
+const DefaultCacheSize = 256
+
+// documentCache is a least-recently-used cache of resolved DID documents
+// with a time-to-live. It is safe for concurrent use.
+type documentCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	now     func() time.Time
+	order   *list.List // front is most recently used
+	entries map[string]*list.Element
+}
+
+// cacheEntry is a cached document and when it stops being fresh
+type cacheEntry struct {
+	did       string
+	doc       *did.DIDDocument
+	expiresAt time.Time
+}
+
+func newDocumentCache(size int, ttl time.Duration) *documentCache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &documentCache{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// NewResolverWithCache creates a resolver that caches resolved DID documents,
+// so verifying many credentials from one issuer resolves its DID once. The
+// cache holds at most size documents, evicting the least recently used, and
+// each expires ttl after it was resolved; a ttl that is not positive keeps
+// documents until they are evicted. Documents from a static store are not
+// cached, since the store can change.
+func NewResolverWithCache(size int, ttl time.Duration) *Resolver {
+	r := NewResolver()
+	r.cache = newDocumentCache(size, ttl)
+	return r
+}
+
+// get returns a copy of a fresh cached document, dropping it if it has expired
+func (c *documentCache) get(didStr string) (*did.DIDDocument, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[didStr]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if c.ttl > 0 && !c.now().Before(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.entries, didStr)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return copyDocument(entry.doc), true
+}
+
+// put stores a copy of a document, evicting the least recently used one when full
+func (c *documentCache) put(didStr string, doc *did.DIDDocument) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cacheEntry{did: didStr, doc: copyDocument(doc), expiresAt: c.now().Add(c.ttl)}
+	if elem, ok := c.entries[didStr]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[didStr] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).did)
+	}
+}
+
+// copyDocument returns a deep copy of doc, so a caller changing a document
+// it was given cannot change the one a cache or store holds
+func copyDocument(doc *did.DIDDocument) *did.DIDDocument {
+	copied := *doc
+	copied.Context = slices.Clone(doc.Context)
+	copied.VerificationMethod = slices.Clone(doc.VerificationMethod)
+	copied.Authentication = slices.Clone(doc.Authentication)
+	copied.AssertionMethod = slices.Clone(doc.AssertionMethod)
+	return &copied
+}
+
+// len returns the number of cached documents, including expired ones not yet dropped
+func (c *documentCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,168 @@
+package resolver
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+)
+
+func newTestDIDKey(t *testing.T) (string, ed25519.PublicKey) {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate keypair: %v", err)
+	}
+	return makeDIDKey(pub), pub
+}
+
+func TestResolverCacheHit(t *testing.T) {
+	r := NewResolverWithCache(10, time.Minute)
+	did, pub := newTestDIDKey(t)
+
+	for i := 0; i < 3; i++ {
+		key, err := r.Resolve(did)
+		if err != nil {
+			t.Fatalf("Failed to resolve: %v", err)
+		}
+		if !bytes.Equal(key, pub) {
+			t.Error("Resolved key does not match")
+		}
+	}
+	if r.cache.len() != 1 {
+		t.Errorf("Expected 1 cached document, got %d", r.cache.len())
+	}
+
+	// Callers get copies, so changing one does not corrupt the cache
+	doc, _ := r.ResolveDocument(did)
+	doc.ID = "did:key:zChanged"
+	again, _ := r.ResolveDocument(did)
+	if again.ID != did {
+		t.Errorf("Expected cached document ID %s, got %s", did, again.ID)
+	}
+}
+
+func TestResolverCacheDeepCopies(t *testing.T) {
+	r := NewResolverWithCache(10, time.Minute)
+	didStr, pub := newTestDIDKey(t)
+	_, otherPub := newTestDIDKey(t)
+	forged := makeDIDKey(otherPub)
+
+	tamper := func(doc *did.DIDDocument) {
+		doc.VerificationMethod[0].PublicKeyBase58 = "forged"
+		doc.AssertionMethod[0] = forged + "#key-1"
+		doc.Authentication[0] = forged + "#key-1"
+		doc.Context[0] = "https://attacker.example"
+	}
+
+	// The document returned by the resolution that fills the cache
+	first, err := r.ResolveDocument(didStr)
+	if err != nil {
+		t.Fatalf("Failed to resolve: %v", err)
+	}
+	want := copyDocument(first)
+	tamper(first)
+
+	// And one served from the cache
+	cached, _ := r.ResolveDocument(didStr)
+	if !reflect.DeepEqual(cached, want) {
+		t.Fatalf("Expected the cached document to be unchanged, got %+v", cached)
+	}
+	tamper(cached)
+
+	again, _ := r.ResolveDocument(didStr)
+	if !reflect.DeepEqual(again, want) {
+		t.Errorf("Expected the cached document to be unchanged, got %+v", again)
+	}
+	key, err := r.Resolve(didStr)
+	if err != nil || !bytes.Equal(key, pub) {
+		t.Errorf("Expected the original key after tampering with copies, got %x, %v", key, err)
+	}
+}
+
+func TestResolverCacheTTL(t *testing.T) {
+	r := NewResolverWithCache(10, time.Minute)
+	now := time.Now()
+	r.cache.now = func() time.Time { return now }
+
+	did, _ := newTestDIDKey(t)
+	if _, err := r.ResolveDocument(did); err != nil {
+		t.Fatalf("Failed to resolve: %v", err)
+	}
+	if _, ok := r.cache.get(did); !ok {
+		t.Fatal("Expected the document to be cached")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := r.cache.get(did); ok {
+		t.Error("Expected the document to expire after the TTL")
+	}
+	if r.cache.len() != 0 {
+		t.Errorf("Expected the expired document to be dropped, got %d cached", r.cache.len())
+	}
+}
+
+func TestResolverCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	r := NewResolverWithCache(2, 0)
+	first, _ := newTestDIDKey(t)
+	second, _ := newTestDIDKey(t)
+	third, _ := newTestDIDKey(t)
+
+	r.ResolveDocument(first)
+	r.ResolveDocument(second)
+	r.ResolveDocument(first) // first is now more recently used than second
+	r.ResolveDocument(third)
+
+	if r.cache.len() != 2 {
+		t.Errorf("Expected 2 cached documents, got %d", r.cache.len())
+	}
+	if _, ok := r.cache.get(second); ok {
+		t.Error("Expected the least recently used document to be evicted")
+	}
+	if _, ok := r.cache.get(first); !ok {
+		t.Error("Expected the recently used document to stay cached")
+	}
+}
+
+func TestResolverCacheSkipsFailures(t *testing.T) {
+	r := NewResolverWithCache(0, time.Minute)
+	if r.cache.size != DefaultCacheSize {
+		t.Errorf("Expected default size %d, got %d", DefaultCacheSize, r.cache.size)
+	}
+
+	if _, err := r.ResolveDocument("did:key:invalid"); err == nil {
+		t.Fatal("Expected an invalid DID to fail")
+	}
+	if r.cache.len() != 0 {
+		t.Errorf("Expected failures not to be cached, got %d cached", r.cache.len())
+	}
+}
+
+func TestResolverCacheConcurrent(t *testing.T) {
+	r := NewResolverWithCache(4, time.Minute)
+	dids := make([]string, 8)
+	for i := range dids {
+		dids[i], _ = newTestDIDKey(t)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := r.Resolve(dids[i%len(dids)]); err != nil {
+				t.Errorf("Failed to resolve: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if r.cache.len() > 4 {
+		t.Errorf("Expected at most 4 cached documents, got %d", r.cache.len())
+	}
+}
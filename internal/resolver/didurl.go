@@ -0,0 +1,45 @@
+package resolver
+
+import "strings"
+
+// DIDURL is a DID with an optional query and fragment, such as
+// did:key:z6Mk...#key-1 or did:web:example.com?versionId=2#key-2
+type DIDURL struct {
+	// DID is the base DID the URL points into
+	DID string
+	// Query is the query without its "?", e.g. "versionId=2"
+	Query string
+	// Fragment is the fragment without its "#", usually naming a
+	// verification method, e.g. "key-1"
+	Fragment string
+}
+
+// ParseDIDURL splits a DID URL into its base DID, query and fragment. The
+// base DID must pass ValidateDID; DID URLs with a path are not supported and,
+// like any other malformed input, return ErrInvalidDID.
+func ParseDIDURL(s string) (*DIDURL, error) {
+	rest, fragment, hasFragment := strings.Cut(s, "#")
+	base, query, hasQuery := strings.Cut(rest, "?")
+	if (hasFragment && fragment == "") || (hasQuery && query == "") {
+		return nil, ErrInvalidDID
+	}
+	if strings.ContainsAny(fragment, " \t\r\n#") || strings.ContainsAny(query, " \t\r\n") {
+		return nil, ErrInvalidDID
+	}
+	if err := ValidateDID(base); err != nil {
+		return nil, err
+	}
+	return &DIDURL{DID: base, Query: query, Fragment: fragment}, nil
+}
+
+// String reassembles the DID URL
+func (u DIDURL) String() string {
+	s := u.DID
+	if u.Query != "" {
+		s += "?" + u.Query
+	}
+	if u.Fragment != "" {
+		s += "#" + u.Fragment
+	}
+	return s
+}
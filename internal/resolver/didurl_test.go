@@ -0,0 +1,111 @@
+package resolver
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	"github.com/veriglob/veriglob-core/internal/did"
+)
+
+func TestParseDIDURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want DIDURL
+	}{
+		{"did:key:z6MkTest", DIDURL{DID: "did:key:z6MkTest"}},
+		{"did:key:z6MkTest#key-1", DIDURL{DID: "did:key:z6MkTest", Fragment: "key-1"}},
+		{"did:web:example.com?versionId=2", DIDURL{DID: "did:web:example.com", Query: "versionId=2"}},
+		{"did:web:example.com?versionId=2#key-2", DIDURL{DID: "did:web:example.com", Query: "versionId=2", Fragment: "key-2"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseDIDURL(tt.in)
+		if err != nil {
+			t.Errorf("ParseDIDURL(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if *got != tt.want {
+			t.Errorf("Expected %+v, got %+v", tt.want, *got)
+		}
+		if got.String() != tt.in {
+			t.Errorf("Expected String() %q, got %q", tt.in, got.String())
+		}
+	}
+
+	for _, bad := range []string{"did:key:", "did:key:z6MkTest#", "did:key:z6MkTest?", "did:key:z6MkTest#a#b", "did:key:z6MkTest#key 1", "did:web:example.com/path#key-1"} {
+		if _, err := ParseDIDURL(bad); err != ErrInvalidDID {
+			t.Errorf("Expected ErrInvalidDID for %q, got %v", bad, err)
+		}
+	}
+}
+
+func TestResolveDIDURL(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	didKey := makeDIDKey(pub)
+	r := NewResolver()
+
+	for _, u := range []string{didKey + "#key-1", didKey + "?versionId=1", didKey + "?versionId=1#key-1"} {
+		got, err := r.Resolve(u)
+		if err != nil {
+			t.Fatalf("Resolve(%q) failed: %v", u, err)
+		}
+		if !got.Equal(pub) {
+			t.Errorf("Resolve(%q) returned the wrong key", u)
+		}
+		doc, err := r.ResolveDocument(u)
+		if err != nil || doc.ID != didKey {
+			t.Errorf("Expected the document of %s for %q, got %v", didKey, u, err)
+		}
+	}
+
+	if _, err := r.Resolve(didKey + "#key-2"); err != ErrVerificationMethodNotFound {
+		t.Errorf("Expected ErrVerificationMethodNotFound, got %v", err)
+	}
+	if _, err := r.ResolvePublicKey(didKey + "#key-2"); err != ErrVerificationMethodNotFound {
+		t.Errorf("Expected ErrVerificationMethodNotFound from ResolvePublicKey, got %v", err)
+	}
+}
+
+func TestResolveDIDURLSelectsVerificationMethod(t *testing.T) {
+	signingPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	rotatedPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	issuerDID := "did:web:issuer.example.com"
+
+	// A did:web document with two keys, the second given by a relative ID
+	doc := webDocument(issuerDID, signingPub)
+	doc.VerificationMethod = append(doc.VerificationMethod, did.VerificationMethod{
+		ID:              "#key-2",
+		Type:            "Ed25519VerificationKey2018",
+		Controller:      issuerDID,
+		PublicKeyBase58: base58.Encode(rotatedPub),
+	})
+	store := NewStaticStore()
+	if err := store.AddDocument(doc); err != nil {
+		t.Fatalf("AddDocument failed: %v", err)
+	}
+	r := NewResolverWithStaticStore(store)
+
+	tests := []struct {
+		url  string
+		want ed25519.PublicKey
+	}{
+		{issuerDID, signingPub},
+		{issuerDID + "#issuer-key", signingPub},
+		{issuerDID + "#key-2", rotatedPub},
+		{issuerDID + "?versionId=3#key-2", rotatedPub},
+	}
+	for _, tt := range tests {
+		got, err := r.Resolve(tt.url)
+		if err != nil {
+			t.Fatalf("Resolve(%q) failed: %v", tt.url, err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("Resolve(%q) returned the wrong verification method key", tt.url)
+		}
+		key, err := r.ResolvePublicKey(tt.url)
+		if err != nil || !ed25519.PublicKey(key.Bytes).Equal(tt.want) {
+			t.Errorf("ResolvePublicKey(%q) returned the wrong key, err %v", tt.url, err)
+		}
+	}
+}
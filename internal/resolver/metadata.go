@@ -0,0 +1,162 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrNoMetadataSource       = errors.New("resolver has no issuer metadata source")
+	ErrMetadataIssuerMismatch = errors.New("issuer metadata published for a different DID")
+	ErrInvalidIssuerMetadata  = errors.New("invalid issuer metadata")
+)
+
+// WellKnownIssuerMetadataPath is where an issuer hosts its metadata document,
+// relative to the origin of its did:web domain
+const WellKnownIssuerMetadataPath = "/.well-known/veriglob-issuer.json"
+
+// DefaultMetadataTTL is how long a resolver keeps issuer metadata before
+// fetching it again, so an issuer's changes are picked up
+const DefaultMetadataTTL = time.Hour
+
+// IssuerMetadata is the document an issuer publishes describing the
+// credentials it issues
+type IssuerMetadata struct {
+	Issuer           string   `json:"issuer"`
+	CredentialTypes  []string `json:"credentialTypes"`
+	StatusEndpoint   string   `json:"statusEndpoint,omitempty"`
+	IssuanceEndpoint string   `json:"issuanceEndpoint,omitempty"`
+}
+
+// MetadataFetcher retrieves the raw metadata document published by an
+// issuer. ctx bounds the fetch.
+type MetadataFetcher func(ctx context.Context, issuerDID string) ([]byte, error)
+
+// metadataEntry is cached issuer metadata and when it stops being fresh
+type metadataEntry struct {
+	md        *IssuerMetadata
+	expiresAt time.Time
+}
+
+// metadataCall is an in-flight metadata fetch shared by concurrent callers
+type metadataCall struct {
+	done chan struct{}
+	md   *IssuerMetadata
+	err  error
+}
+
+// NewResolverWithMetadataFetcher creates a resolver that can also resolve issuer metadata
+func NewResolverWithMetadataFetcher(fetch MetadataFetcher) *Resolver {
+	r := NewResolver()
+	r.fetchMetadata = fetch
+	r.metadataTTL = DefaultMetadataTTL
+	return r
+}
+
+// ResolveIssuerMetadata fetches and parses the metadata published by an issuer.
+// Successful results are cached for DefaultMetadataTTL, and concurrent
+// requests for the same issuer share a single fetch. ctx bounds the fetch,
+// and a caller waiting on another's fetch stops waiting when its own ctx is
+// done.
+func (r *Resolver) ResolveIssuerMetadata(ctx context.Context, issuerDID string) (*IssuerMetadata, error) {
+	if r.fetchMetadata == nil {
+		return nil, ErrNoMetadataSource
+	}
+
+	r.mu.RLock()
+	md, ok := r.cachedMetadata(issuerDID)
+	r.mu.RUnlock()
+	if ok {
+		return md, nil
+	}
+
+	r.mu.Lock()
+	if md, ok := r.cachedMetadata(issuerDID); ok {
+		r.mu.Unlock()
+		return md, nil
+	}
+	if c, ok := r.inflight[issuerDID]; ok {
+		r.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.md, c.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	c := &metadataCall{done: make(chan struct{})}
+	r.inflight[issuerDID] = c
+	r.mu.Unlock()
+
+	c.md, c.err = r.fetchIssuerMetadata(ctx, issuerDID)
+
+	r.mu.Lock()
+	if c.err == nil {
+		r.metadata[issuerDID] = &metadataEntry{md: c.md, expiresAt: r.now().Add(r.metadataTTL)}
+	}
+	delete(r.inflight, issuerDID)
+	r.mu.Unlock()
+	close(c.done)
+
+	return c.md, c.err
+}
+
+// cachedMetadata returns fresh cached metadata for an issuer. Stale entries
+// are left for the next fetch to replace. The caller must hold r.mu.
+func (r *Resolver) cachedMetadata(issuerDID string) (*IssuerMetadata, bool) {
+	entry, ok := r.metadata[issuerDID]
+	if !ok || !r.now().Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.md, true
+}
+
+// fetchIssuerMetadata performs an uncached metadata fetch
+func (r *Resolver) fetchIssuerMetadata(ctx context.Context, issuerDID string) (*IssuerMetadata, error) {
+	return FetchIssuerMetadata(ctx, issuerDID, r.fetchMetadata)
+}
+
+// FetchIssuerMetadata retrieves and parses an issuer's metadata without
+// caching. The document must name issuerDID as its issuer.
+func FetchIssuerMetadata(ctx context.Context, issuerDID string, fetch MetadataFetcher) (*IssuerMetadata, error) {
+	if fetch == nil {
+		return nil, ErrNoMetadataSource
+	}
+
+	data, err := fetch(ctx, issuerDID)
+	if err != nil {
+		return nil, err
+	}
+
+	var md IssuerMetadata
+	if err := json.Unmarshal(data, &md); err != nil {
+		return nil, err
+	}
+
+	// A document that names another issuer must not vouch for this one
+	if md.Issuer != issuerDID {
+		return nil, ErrMetadataIssuerMismatch
+	}
+
+	return &md, nil
+}
+
+// IssuesType reports whether the issuer publishes the given credential type
+func (m *IssuerMetadata) IssuesType(credentialType string) bool {
+	for _, t := range m.CredentialTypes {
+		if t == credentialType {
+			return true
+		}
+	}
+	return false
+}
+
+// JSON renders the metadata document for hosting at WellKnownIssuerMetadataPath
+func (m *IssuerMetadata) JSON() ([]byte, error) {
+	if m.Issuer == "" || len(m.CredentialTypes) == 0 {
+		return nil, ErrInvalidIssuerMetadata
+	}
+	return json.MarshalIndent(m, "", "  ")
+}
@@ -0,0 +1,266 @@
+package resolver
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResolveIssuerMetadata(t *testing.T) {
+	r := NewResolverWithMetadataFetcher(func(_ context.Context, issuerDID string) ([]byte, error) {
+		return []byte(`{"issuer":"` + issuerDID + `","credentialTypes":["IdentityCredential"]}`), nil
+	})
+
+	md, err := r.ResolveIssuerMetadata(context.Background(), "did:key:issuer")
+	if err != nil {
+		t.Fatalf("Failed to resolve issuer metadata: %v", err)
+	}
+
+	if !md.IssuesType("IdentityCredential") {
+		t.Error("Expected IdentityCredential to be published")
+	}
+
+	if md.IssuesType("EducationCredential") {
+		t.Error("EducationCredential should not be published")
+	}
+}
+
+func TestResolveIssuerMetadataErrors(t *testing.T) {
+	if _, err := NewResolver().ResolveIssuerMetadata(context.Background(), "did:key:issuer"); err != ErrNoMetadataSource {
+		t.Errorf("Expected ErrNoMetadataSource, got %v", err)
+	}
+
+	mismatch := NewResolverWithMetadataFetcher(func(context.Context, string) ([]byte, error) {
+		return []byte(`{"issuer":"did:key:other","credentialTypes":["IdentityCredential"]}`), nil
+	})
+	if _, err := mismatch.ResolveIssuerMetadata(context.Background(), "did:key:issuer"); err != ErrMetadataIssuerMismatch {
+		t.Errorf("Expected ErrMetadataIssuerMismatch, got %v", err)
+	}
+
+	fetchErr := errors.New("unreachable")
+	failing := NewResolverWithMetadataFetcher(func(context.Context, string) ([]byte, error) {
+		return nil, fetchErr
+	})
+	if _, err := failing.ResolveIssuerMetadata(context.Background(), "did:key:issuer"); err != fetchErr {
+		t.Errorf("Expected fetch error, got %v", err)
+	}
+}
+
+func TestResolveIssuerMetadataWaiterContext(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r := NewResolverWithMetadataFetcher(func(_ context.Context, issuerDID string) ([]byte, error) {
+		close(started)
+		<-release
+		return []byte(`{"issuer":"` + issuerDID + `","credentialTypes":["IdentityCredential"]}`), nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.ResolveIssuerMetadata(context.Background(), "did:key:issuer")
+		done <- err
+	}()
+	<-started
+
+	// A caller waiting on another's fetch gives up when its own context ends
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.ResolveIssuerMetadata(ctx, "did:key:issuer"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("Expected the shared fetch to succeed, got %v", err)
+	}
+}
+
+func TestResolveIssuerMetadataCachesResults(t *testing.T) {
+	var fetches int32
+	r := NewResolverWithMetadataFetcher(func(_ context.Context, issuerDID string) ([]byte, error) {
+		atomic.AddInt32(&fetches, 1)
+		return []byte(`{"issuer":"` + issuerDID + `","credentialTypes":[]}`), nil
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := r.ResolveIssuerMetadata(context.Background(), "did:key:issuer"); err != nil {
+			t.Fatalf("Failed to resolve: %v", err)
+		}
+	}
+
+	if fetches != 1 {
+		t.Errorf("Expected 1 fetch, got %d", fetches)
+	}
+}
+
+func TestResolveIssuerMetadataExpires(t *testing.T) {
+	var fetches int32
+	r := NewResolverWithMetadataFetcher(func(_ context.Context, issuerDID string) ([]byte, error) {
+		atomic.AddInt32(&fetches, 1)
+		return []byte(`{"issuer":"` + issuerDID + `","credentialTypes":["IdentityCredential"]}`), nil
+	})
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	r.ResolveIssuerMetadata(context.Background(), "did:key:issuer")
+	now = now.Add(DefaultMetadataTTL - time.Second)
+	r.ResolveIssuerMetadata(context.Background(), "did:key:issuer")
+	if fetches != 1 {
+		t.Errorf("Expected fresh metadata to be served from the cache, got %d fetches", fetches)
+	}
+
+	now = now.Add(time.Second)
+	r.ResolveIssuerMetadata(context.Background(), "did:key:issuer")
+	if fetches != 2 {
+		t.Errorf("Expected expired metadata to be fetched again, got %d fetches", fetches)
+	}
+	r.ResolveIssuerMetadata(context.Background(), "did:key:issuer")
+	if fetches != 2 {
+		t.Errorf("Expected the refetched metadata to be cached, got %d fetches", fetches)
+	}
+}
+
+func TestResolveIssuerMetadataDoesNotCacheErrors(t *testing.T) {
+	var fetches int32
+	r := NewResolverWithMetadataFetcher(func(context.Context, string) ([]byte, error) {
+		atomic.AddInt32(&fetches, 1)
+		return nil, errors.New("unreachable")
+	})
+
+	r.ResolveIssuerMetadata(context.Background(), "did:key:issuer")
+	r.ResolveIssuerMetadata(context.Background(), "did:key:issuer")
+
+	if fetches != 2 {
+		t.Errorf("Expected failed fetches to be retried, got %d fetches", fetches)
+	}
+}
+
+func TestResolverConcurrentUse(t *testing.T) {
+	const (
+		issuers    = 4
+		goroutines = 64
+	)
+
+	var mu sync.Mutex
+	fetches := make(map[string]int)
+	started := make(chan struct{}, goroutines)
+	release := make(chan struct{})
+
+	r := NewResolverWithMetadataFetcher(func(_ context.Context, issuerDID string) ([]byte, error) {
+		mu.Lock()
+		fetches[issuerDID]++
+		mu.Unlock()
+		started <- struct{}{}
+		// Hold the fetch open so concurrent callers pile up behind it
+		<-release
+		return []byte(`{"issuer":"` + issuerDID + `","credentialTypes":["IdentityCredential"]}`), nil
+	})
+
+	keys := make([]ed25519.PublicKey, issuers)
+	for i := range keys {
+		keys[i], _, _ = ed25519.GenerateKey(rand.Reader)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines*2)
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			issuerDID := fmt.Sprintf("did:key:issuer-%d", i%issuers)
+			md, err := r.ResolveIssuerMetadata(context.Background(), issuerDID)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if md.Issuer != issuerDID {
+				errs <- fmt.Errorf("got metadata for %s, want %s", md.Issuer, issuerDID)
+			}
+
+			pub := keys[i%issuers]
+			resolved, err := r.Resolve(makeDIDKey(pub))
+			if err != nil {
+				errs <- err
+				return
+			}
+			if !pub.Equal(resolved) {
+				errs <- errors.New("resolved key does not match")
+			}
+		}(i)
+	}
+
+	// Release the fetches once every issuer's is in flight; callers that
+	// arrive later either join one or are served from the cache
+	for i := 0; i < issuers; i++ {
+		<-started
+	}
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	for issuerDID, n := range fetches {
+		if n != 1 {
+			t.Errorf("Expected a single fetch for %s, got %d", issuerDID, n)
+		}
+	}
+	if len(fetches) != issuers {
+		t.Errorf("Expected %d distinct fetches, got %d", issuers, len(fetches))
+	}
+}
+
+func TestIssuerMetadataRenderAndFetch(t *testing.T) {
+	md := &IssuerMetadata{
+		Issuer:           "did:web:issuer.example.com",
+		CredentialTypes:  []string{"IdentityCredential", "EmploymentCredential"},
+		StatusEndpoint:   "https://issuer.example.com/status",
+		IssuanceEndpoint: "https://issuer.example.com/issue",
+	}
+
+	data, err := md.JSON()
+	if err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+
+	fetched, err := FetchIssuerMetadata(context.Background(), md.Issuer, func(context.Context, string) ([]byte, error) { return data, nil })
+	if err != nil {
+		t.Fatalf("FetchIssuerMetadata failed: %v", err)
+	}
+
+	if fetched.StatusEndpoint != md.StatusEndpoint {
+		t.Errorf("Expected status endpoint %s, got %s", md.StatusEndpoint, fetched.StatusEndpoint)
+	}
+	if fetched.IssuanceEndpoint != md.IssuanceEndpoint {
+		t.Errorf("Expected issuance endpoint %s, got %s", md.IssuanceEndpoint, fetched.IssuanceEndpoint)
+	}
+	if !fetched.IssuesType("EmploymentCredential") {
+		t.Error("Expected EmploymentCredential to be published")
+	}
+
+	if _, err := FetchIssuerMetadata(context.Background(), "did:web:other.example.com", func(context.Context, string) ([]byte, error) { return data, nil }); err != ErrMetadataIssuerMismatch {
+		t.Errorf("Expected ErrMetadataIssuerMismatch, got %v", err)
+	}
+	if _, err := FetchIssuerMetadata(context.Background(), md.Issuer, nil); err != ErrNoMetadataSource {
+		t.Errorf("Expected ErrNoMetadataSource, got %v", err)
+	}
+}
+
+func TestIssuerMetadataJSONRequiresIssuerAndTypes(t *testing.T) {
+	if _, err := (&IssuerMetadata{CredentialTypes: []string{"IdentityCredential"}}).JSON(); err != ErrInvalidIssuerMetadata {
+		t.Errorf("Expected ErrInvalidIssuerMetadata without issuer, got %v", err)
+	}
+	if _, err := (&IssuerMetadata{Issuer: "did:web:issuer.example.com"}).JSON(); err != ErrInvalidIssuerMetadata {
+		t.Errorf("Expected ErrInvalidIssuerMetadata without types, got %v", err)
+	}
+}
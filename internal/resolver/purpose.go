@@ -0,0 +1,75 @@
+package resolver
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+)
+
+var (
+	ErrInvalidProofPurpose = errors.New("key is not authorized for the proof purpose")
+)
+
+// Proof purposes, named after the DID document verification relationships
+// that authorize them
+const (
+	// ProofPurposeAuthentication covers proofs of control, such as a holder
+	// signing a presentation
+	ProofPurposeAuthentication = "authentication"
+	// ProofPurposeAssertionMethod covers statements made by the DID subject,
+	// such as an issuer signing a credential
+	ProofPurposeAssertionMethod = "assertionMethod"
+)
+
+// VerifyProofPurpose checks that key is the key of a verification method
+// listed under the given proof purpose in didStr's DID document. A key that
+// belongs to the DID but only to another relationship, or an unknown purpose,
+// returns ErrInvalidProofPurpose.
+func (r *Resolver) VerifyProofPurpose(didStr string, key ed25519.PublicKey, purpose string) error {
+	doc, err := r.ResolveDocument(didStr)
+	if err != nil {
+		return err
+	}
+
+	methods, err := relationship(doc, purpose)
+	if err != nil {
+		return err
+	}
+
+	for _, vmID := range methods {
+		vm := findMethod(doc, vmID)
+		if vm == nil {
+			continue
+		}
+		vmKey, err := methodKey(vm)
+		if err != nil || vmKey.Type != did.KeyTypeEd25519 {
+			continue
+		}
+		if bytes.Equal(vmKey.Bytes, key) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: key is not listed under %s of %s", ErrInvalidProofPurpose, purpose, didStr)
+}
+
+// relationship returns the verification method IDs a document lists for a
+// proof purpose
+func relationship(doc *did.DIDDocument, purpose string) ([]string, error) {
+	switch purpose {
+	case ProofPurposeAuthentication:
+		return doc.Authentication, nil
+	case ProofPurposeAssertionMethod:
+		return doc.AssertionMethod, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown proof purpose %q", ErrInvalidProofPurpose, purpose)
+	}
+}
+
+// VerifyDIDProofPurpose is a convenience function that checks a key's proof
+// purpose with the default resolver
+func VerifyDIDProofPurpose(didStr string, key ed25519.PublicKey, purpose string) error {
+	return NewResolver().VerifyProofPurpose(didStr, key, purpose)
+}
@@ -0,0 +1,80 @@
+package resolver
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	"github.com/veriglob/veriglob-core/internal/did"
+)
+
+func TestVerifyProofPurposeDIDKey(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	didKey, _ := did.CreateDIDKey(pub)
+
+	// did:key lists its only key under both relationships
+	for _, purpose := range []string{ProofPurposeAuthentication, ProofPurposeAssertionMethod} {
+		if err := VerifyDIDProofPurpose(didKey.DID, pub, purpose); err != nil {
+			t.Errorf("Expected %s to accept the DID's key, got %v", purpose, err)
+		}
+		if err := VerifyDIDProofPurpose(didKey.DID, otherPub, purpose); !errors.Is(err, ErrInvalidProofPurpose) {
+			t.Errorf("Expected ErrInvalidProofPurpose for another key, got %v", err)
+		}
+	}
+}
+
+func TestVerifyProofPurposeSeparateKeys(t *testing.T) {
+	authPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	assertPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	issuerDID := "did:web:issuer.example.com"
+
+	store := NewStaticStore()
+	if err := store.AddDocument(did.DIDDocument{
+		ID: issuerDID,
+		VerificationMethod: []did.VerificationMethod{
+			{ID: issuerDID + "#auth", Type: "Ed25519VerificationKey2018", Controller: issuerDID, PublicKeyBase58: base58.Encode(authPub)},
+			{ID: issuerDID + "#assert", Type: "Ed25519VerificationKey2018", Controller: issuerDID, PublicKeyBase58: base58.Encode(assertPub)},
+		},
+		Authentication:  []string{issuerDID + "#auth"},
+		AssertionMethod: []string{issuerDID + "#assert"},
+	}); err != nil {
+		t.Fatalf("AddDocument failed: %v", err)
+	}
+	r := NewResolverWithStaticStore(store)
+
+	tests := []struct {
+		name    string
+		key     ed25519.PublicKey
+		purpose string
+		wantErr bool
+	}{
+		{"authentication key for authentication", authPub, ProofPurposeAuthentication, false},
+		{"assertion key for assertionMethod", assertPub, ProofPurposeAssertionMethod, false},
+		{"authentication key for assertionMethod", authPub, ProofPurposeAssertionMethod, true},
+		{"assertion key for authentication", assertPub, ProofPurposeAuthentication, true},
+		{"unknown purpose", authPub, "capabilityInvocation", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.VerifyProofPurpose(issuerDID, tt.key, tt.purpose)
+			if tt.wantErr && !errors.Is(err, ErrInvalidProofPurpose) {
+				t.Errorf("Expected ErrInvalidProofPurpose, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyProofPurposeUnresolvable(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	if err := VerifyDIDProofPurpose("did:web:unknown.example.com", pub, ProofPurposeAuthentication); err != ErrUnsupportedMethod {
+		t.Errorf("Expected ErrUnsupportedMethod, got %v", err)
+	}
+}
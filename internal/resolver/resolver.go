@@ -1,11 +1,17 @@
 package resolver
 
 import (
+	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/elliptic"
 	"errors"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/mr-tron/base58"
+	"github.com/veriglob/veriglob-core/internal/did"
 )
 
 var (
@@ -13,42 +19,208 @@ var (
 	ErrUnsupportedMethod = errors.New("unsupported DID method")
 	ErrInvalidMulticodec = errors.New("invalid multicodec prefix")
 	ErrInvalidKeyLength  = errors.New("invalid public key length")
+	ErrUnexpectedKeyType = errors.New("unexpected key type")
+
+	ErrVerificationMethodNotFound = errors.New("verification method not found")
 )
 
 // ed25519Multicodec is the multicodec prefix for Ed25519 public keys (0xed01)
 var ed25519Multicodec = []byte{0xed, 0x01}
 
-// Resolver resolves DIDs to their public keys
-type Resolver struct{}
+// secp256k1Multicodec is the multicodec prefix for secp256k1 public keys (0xe701)
+var secp256k1Multicodec = []byte{0xe7, 0x01}
+
+// p256Multicodec is the varint-encoded multicodec prefix for P-256 public keys (0x1200)
+var p256Multicodec = []byte{0x80, 0x24}
+
+// PublicKey is a resolved public key tagged with its algorithm
+type PublicKey struct {
+	Type did.KeyType
+	// Bytes is the raw Ed25519 key or the compressed secp256k1 or P-256 point
+	Bytes []byte
+}
+
+// Ed25519 returns the key as an Ed25519 public key
+func (k *PublicKey) Ed25519() (ed25519.PublicKey, error) {
+	if k.Type != did.KeyTypeEd25519 {
+		return nil, ErrUnexpectedKeyType
+	}
+	return ed25519.PublicKey(k.Bytes), nil
+}
+
+// Secp256k1 returns the key as a parsed secp256k1 public key
+func (k *PublicKey) Secp256k1() (*secp256k1.PublicKey, error) {
+	if k.Type != did.KeyTypeSecp256k1 {
+		return nil, ErrUnexpectedKeyType
+	}
+	return secp256k1.ParsePubKey(k.Bytes)
+}
+
+// P256 returns the key as an ECDSA P-256 public key
+func (k *PublicKey) P256() (*ecdsa.PublicKey, error) {
+	if k.Type != did.KeyTypeP256 {
+		return nil, ErrUnexpectedKeyType
+	}
+	return parseP256Compressed(k.Bytes)
+}
+
+// parseP256Compressed decodes a compressed P-256 point, rejecting points
+// that are not on the curve
+func parseP256Compressed(b []byte) (*ecdsa.PublicKey, error) {
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), b)
+	if x == nil {
+		return nil, ErrInvalidKeyLength
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+}
+
+// Resolver resolves DIDs to their public keys. It is safe for concurrent use.
+type Resolver struct {
+	fetchMetadata MetadataFetcher
+	static        *StaticStore
+	cache         *documentCache
+
+	mu          sync.RWMutex
+	now         func() time.Time
+	metadataTTL time.Duration
+	metadata    map[string]*metadataEntry
+	inflight    map[string]*metadataCall
+}
 
 // New creates a new DID resolver
 func NewResolver() *Resolver {
-	return &Resolver{}
+	return &Resolver{
+		now:      time.Now,
+		metadata: make(map[string]*metadataEntry),
+		inflight: make(map[string]*metadataCall),
+	}
 }
 
-// Resolve extracts the public key from a DID
-// Currently supports: did:key
+// Resolve extracts the Ed25519 public key from a DID: the key of its
+// document's first assertion method. A DID URL with a fragment, such as
+// did:key:z...#key-1, returns the key of the verification method the
+// fragment names instead, or ErrVerificationMethodNotFound.
+// Currently supports: did:key. DIDs for other key types return ErrUnexpectedKeyType;
+// use ResolvePublicKey to handle them.
 func (r *Resolver) Resolve(did string) (ed25519.PublicKey, error) {
-	parts := strings.Split(did, ":")
-	if len(parts) < 3 {
-		return nil, ErrInvalidDID
+	u, err := ParseDIDURL(did)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := r.ResolveDocument(u.DID)
+	if err != nil {
+		return nil, err
 	}
 
-	if parts[0] != "did" {
-		return nil, ErrInvalidDID
+	var key *PublicKey
+	if u.Fragment != "" {
+		key, err = fragmentKey(doc, u)
+	} else {
+		key, err = documentKey(doc)
+	}
+	if err != nil {
+		return nil, err
 	}
+	return key.Ed25519()
+}
 
-	method := parts[1]
+// ResolveDocument returns the full DID document of a DID, including its
+// verification method IDs, controllers and proof purposes. A did:key
+// document is reconstructed the way did.CreateDIDKey builds it; a DID in the
+// resolver's static store returns a copy of the stored document. A resolver
+// with a cache reuses documents it resolved before. A DID URL resolves to
+// the document of its base DID; its query, such as ?versionId=, is parsed
+// but not interpreted, as the supported methods keep a single version. Input
+// that fails ParseDIDURL returns ErrInvalidDID before any lookup.
+func (r *Resolver) ResolveDocument(didURL string) (*did.DIDDocument, error) {
+	u, err := ParseDIDURL(didURL)
+	if err != nil {
+		return nil, err
+	}
+	didStr := u.DID
+
+	if r.static != nil {
+		if doc, ok := r.static.Document(didStr); ok {
+			return copyDocument(doc), nil
+		}
+	}
+
+	if r.cache == nil {
+		return r.resolveDocument(didStr)
+	}
+	if doc, ok := r.cache.get(didStr); ok {
+		return doc, nil
+	}
+	doc, err := r.resolveDocument(didStr)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.put(didStr, doc)
+	return doc, nil
+}
+
+// resolveDocument builds the DID document of a DID from its method
+func (r *Resolver) resolveDocument(didStr string) (*did.DIDDocument, error) {
+	key, err := r.ResolvePublicKey(didStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var didKey *did.DIDKey
+	switch key.Type {
+	case did.KeyTypeEd25519:
+		didKey, err = did.CreateDIDKey(key.Bytes)
+	case did.KeyTypeSecp256k1:
+		didKey, err = did.CreateDIDKeySecp256k1(key.Bytes)
+	case did.KeyTypeP256:
+		var pub *ecdsa.PublicKey
+		if pub, err = key.P256(); err == nil {
+			didKey, err = did.CreateDIDKeyP256(pub)
+		}
+	default:
+		return nil, ErrUnexpectedKeyType
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &didKey.DIDDocument, nil
+}
+
+// ResolvePublicKey extracts the public key and its algorithm from a DID
+// Currently supports: did:key (Ed25519, secp256k1, P-256), and any DID in the
+// resolver's static store. A DID URL with a fragment returns the key of the
+// verification method the fragment names.
+func (r *Resolver) ResolvePublicKey(didURL string) (*PublicKey, error) {
+	u, err := ParseDIDURL(didURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Fragment != "" {
+		doc, err := r.ResolveDocument(u.DID)
+		if err != nil {
+			return nil, err
+		}
+		return fragmentKey(doc, u)
+	}
+	did := u.DID
+
+	if r.static != nil {
+		if key, ok := r.static.publicKey(did); ok {
+			return key, nil
+		}
+	}
+
+	method, id, _ := strings.Cut(strings.TrimPrefix(did, "did:"), ":")
 	switch method {
 	case "key":
-		return r.resolveKey(parts[2])
+		return r.resolveKey(id)
 	default:
 		return nil, ErrUnsupportedMethod
 	}
 }
 
 // resolveKey extracts the public key from a did:key identifier
-func (r *Resolver) resolveKey(identifier string) (ed25519.PublicKey, error) {
+func (r *Resolver) resolveKey(identifier string) (*PublicKey, error) {
 	// did:key uses multibase encoding with 'z' prefix (base58btc)
 	if len(identifier) == 0 || identifier[0] != 'z' {
 		return nil, ErrInvalidDID
@@ -60,26 +232,91 @@ func (r *Resolver) resolveKey(identifier string) (ed25519.PublicKey, error) {
 		return nil, err
 	}
 
-	// Check multicodec prefix (0xed01 for Ed25519)
+	// Check multicodec prefix (0xed01 for Ed25519, 0xe701 for secp256k1,
+	// 0x8024 for P-256)
 	if len(decoded) < 2 {
 		return nil, ErrInvalidMulticodec
 	}
 
-	if decoded[0] != ed25519Multicodec[0] || decoded[1] != ed25519Multicodec[1] {
+	// Extract public key (skip the 2-byte multicodec prefix)
+	pubKeyBytes := decoded[2:]
+
+	switch {
+	case decoded[0] == ed25519Multicodec[0] && decoded[1] == ed25519Multicodec[1]:
+		if len(pubKeyBytes) != ed25519.PublicKeySize {
+			return nil, ErrInvalidKeyLength
+		}
+		return &PublicKey{Type: did.KeyTypeEd25519, Bytes: pubKeyBytes}, nil
+
+	case decoded[0] == secp256k1Multicodec[0] && decoded[1] == secp256k1Multicodec[1]:
+		if len(pubKeyBytes) != secp256k1.PubKeyBytesLenCompressed {
+			return nil, ErrInvalidKeyLength
+		}
+		if _, err := secp256k1.ParsePubKey(pubKeyBytes); err != nil {
+			return nil, err
+		}
+		return &PublicKey{Type: did.KeyTypeSecp256k1, Bytes: pubKeyBytes}, nil
+
+	case decoded[0] == p256Multicodec[0] && decoded[1] == p256Multicodec[1]:
+		if len(pubKeyBytes) != 33 {
+			return nil, ErrInvalidKeyLength
+		}
+		if _, err := parseP256Compressed(pubKeyBytes); err != nil {
+			return nil, err
+		}
+		return &PublicKey{Type: did.KeyTypeP256, Bytes: pubKeyBytes}, nil
+
+	default:
 		return nil, ErrInvalidMulticodec
 	}
+}
 
-	// Extract public key (skip the 2-byte multicodec prefix)
-	pubKeyBytes := decoded[2:]
+// ResolveVerificationMethod resolves a verification method ID
+// (e.g. did:key:z...#key-1) to the public key of exactly that method
+func (r *Resolver) ResolveVerificationMethod(vmID string) (ed25519.PublicKey, error) {
+	u, err := ParseDIDURL(vmID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Fragment == "" {
+		return nil, ErrInvalidDID
+	}
 
-	if len(pubKeyBytes) != ed25519.PublicKeySize {
-		return nil, ErrInvalidKeyLength
+	doc, err := r.ResolveDocument(u.DID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := fragmentKey(doc, u)
+	if err != nil {
+		return nil, err
 	}
+	return key.Ed25519()
+}
 
-	return ed25519.PublicKey(pubKeyBytes), nil
+// fragmentKey returns the key of the verification method a DID URL fragment
+// names, whether the document gives its ID in full or relative ("#key-1")
+func fragmentKey(doc *did.DIDDocument, u *DIDURL) (*PublicKey, error) {
+	vm := findMethod(doc, u.DID+"#"+u.Fragment)
+	if vm == nil {
+		vm = findMethod(doc, "#"+u.Fragment)
+	}
+	if vm == nil {
+		return nil, ErrVerificationMethodNotFound
+	}
+	return methodKey(vm)
 }
 
 // ResolveDID is a convenience function that creates a resolver and resolves a DID
 func ResolveDID(did string) (ed25519.PublicKey, error) {
 	return NewResolver().Resolve(did)
 }
+
+// ResolveDIDDocument is a convenience function that resolves a DID to its DID document
+func ResolveDIDDocument(didStr string) (*did.DIDDocument, error) {
+	return NewResolver().ResolveDocument(didStr)
+}
+
+// ResolveDIDPublicKey is a convenience function that resolves a DID to its typed public key
+func ResolveDIDPublicKey(did string) (*PublicKey, error) {
+	return NewResolver().ResolvePublicKey(did)
+}
@@ -1,13 +1,24 @@
 package resolver
 
 import (
+	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"testing"
 
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/mr-tron/base58"
 )
 
+// makeDIDKey builds a did:key for an Ed25519 key the same way the did package does
+func makeDIDKey(pub ed25519.PublicKey) string {
+	prefixedKey := make([]byte, 0, 2+len(pub))
+	prefixedKey = append(prefixedKey, 0xed, 0x01)
+	prefixedKey = append(prefixedKey, pub...)
+	return "did:key:z" + base58.Encode(prefixedKey)
+}
+
 func TestNewResolver(t *testing.T) {
 	r := NewResolver()
 	if r == nil {
@@ -23,10 +34,7 @@ func TestResolveValidDIDKey(t *testing.T) {
 	}
 
 	// Create did:key manually (same as did package)
-	multicodec := []byte{0xed, 0x01}
-	prefixedKey := append(multicodec, pub...)
-	encoded := "z" + base58.Encode(prefixedKey)
-	did := "did:key:" + encoded
+	did := makeDIDKey(pub)
 
 	// Resolve
 	r := NewResolver()
@@ -44,10 +52,7 @@ func TestResolveValidDIDKey(t *testing.T) {
 func TestResolveDIDConvenienceFunction(t *testing.T) {
 	pub, _, _ := ed25519.GenerateKey(rand.Reader)
 
-	multicodec := []byte{0xed, 0x01}
-	prefixedKey := append(multicodec, pub...)
-	encoded := "z" + base58.Encode(prefixedKey)
-	did := "did:key:" + encoded
+	did := makeDIDKey(pub)
 
 	resolvedPub, err := ResolveDID(did)
 	if err != nil {
@@ -144,10 +149,7 @@ func TestResolveRealWorldDID(t *testing.T) {
 	// This DID was generated with a known public key
 	pub, _, _ := ed25519.GenerateKey(rand.Reader)
 
-	multicodec := []byte{0xed, 0x01}
-	prefixedKey := append(multicodec, pub...)
-	encoded := "z" + base58.Encode(prefixedKey)
-	did := "did:key:" + encoded
+	did := makeDIDKey(pub)
 
 	resolvedPub, err := ResolveDID(did)
 	if err != nil {
@@ -167,10 +169,7 @@ func TestResolverRoundTrip(t *testing.T) {
 			t.Fatalf("Failed to generate key: %v", err)
 		}
 
-		multicodec := []byte{0xed, 0x01}
-		prefixedKey := append(multicodec, pub...)
-		encoded := "z" + base58.Encode(prefixedKey)
-		did := "did:key:" + encoded
+		did := makeDIDKey(pub)
 
 		resolved, err := ResolveDID(did)
 		if err != nil {
@@ -182,3 +181,222 @@ func TestResolverRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestResolveVerificationMethod(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	did := makeDIDKey(pub)
+
+	r := NewResolver()
+	resolved, err := r.ResolveVerificationMethod(did + "#key-1")
+	if err != nil {
+		t.Fatalf("Failed to resolve verification method: %v", err)
+	}
+	if !pub.Equal(resolved) {
+		t.Error("Resolved method key does not match original")
+	}
+
+	if _, err := r.ResolveVerificationMethod(did + "#key-2"); err != ErrVerificationMethodNotFound {
+		t.Errorf("Expected ErrVerificationMethodNotFound, got %v", err)
+	}
+
+	if _, err := r.ResolveVerificationMethod(did); err != ErrInvalidDID {
+		t.Errorf("Expected ErrInvalidDID for missing fragment, got %v", err)
+	}
+}
+
+func TestResolveSecp256k1DIDKey(t *testing.T) {
+	priv, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	compressed := priv.PubKey().SerializeCompressed()
+
+	prefixedKey := make([]byte, 0, 2+len(compressed))
+	prefixedKey = append(prefixedKey, 0xe7, 0x01)
+	prefixedKey = append(prefixedKey, compressed...)
+	did := "did:key:z" + base58.Encode(prefixedKey)
+
+	r := NewResolver()
+	key, err := r.ResolvePublicKey(did)
+	if err != nil {
+		t.Fatalf("Failed to resolve secp256k1 DID: %v", err)
+	}
+
+	if key.Type != "secp256k1" {
+		t.Errorf("Expected key type secp256k1, got %s", key.Type)
+	}
+
+	parsed, err := key.Secp256k1()
+	if err != nil {
+		t.Fatalf("Secp256k1() failed: %v", err)
+	}
+	if !parsed.IsEqual(priv.PubKey()) {
+		t.Error("Resolved secp256k1 key does not match original")
+	}
+
+	if _, err := key.Ed25519(); err != ErrUnexpectedKeyType {
+		t.Errorf("Expected ErrUnexpectedKeyType from Ed25519(), got %v", err)
+	}
+
+	// The Ed25519-only Resolve rejects secp256k1 DIDs
+	if _, err := r.Resolve(did); err != ErrUnexpectedKeyType {
+		t.Errorf("Expected ErrUnexpectedKeyType from Resolve, got %v", err)
+	}
+}
+
+func TestResolveP256DIDKey(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	compressed := elliptic.MarshalCompressed(elliptic.P256(), priv.X, priv.Y)
+	did := "did:key:z" + base58.Encode(append([]byte{0x80, 0x24}, compressed...))
+
+	r := NewResolver()
+	key, err := r.ResolvePublicKey(did)
+	if err != nil {
+		t.Fatalf("Failed to resolve P-256 DID: %v", err)
+	}
+	if key.Type != "P-256" {
+		t.Errorf("Expected key type P-256, got %s", key.Type)
+	}
+
+	parsed, err := key.P256()
+	if err != nil {
+		t.Fatalf("P256() failed: %v", err)
+	}
+	if !parsed.Equal(&priv.PublicKey) {
+		t.Error("Resolved P-256 key does not match original")
+	}
+
+	if _, err := key.Secp256k1(); err != ErrUnexpectedKeyType {
+		t.Errorf("Expected ErrUnexpectedKeyType from Secp256k1(), got %v", err)
+	}
+	if _, err := r.Resolve(did); err != ErrUnexpectedKeyType {
+		t.Errorf("Expected ErrUnexpectedKeyType from Resolve, got %v", err)
+	}
+
+	doc, err := r.ResolveDocument(did)
+	if err != nil {
+		t.Fatalf("Failed to resolve document: %v", err)
+	}
+	if doc.ID != did || doc.VerificationMethod[0].Type != "EcdsaSecp256r1VerificationKey2019" {
+		t.Errorf("Unexpected P-256 document: %+v", doc)
+	}
+}
+
+func TestResolveP256InvalidPoint(t *testing.T) {
+	// Right length, but not a point on the curve
+	invalid := append([]byte{0x80, 0x24, 0x02}, make([]byte, 32)...)
+	for i := range invalid[3:] {
+		invalid[3+i] = 0xff
+	}
+	did := "did:key:z" + base58.Encode(invalid)
+
+	if _, err := ResolveDIDPublicKey(did); err == nil {
+		t.Error("Expected an error for an invalid P-256 point")
+	}
+
+	short := "did:key:z" + base58.Encode(append([]byte{0x80, 0x24}, make([]byte, 16)...))
+	if _, err := ResolveDIDPublicKey(short); err != ErrInvalidKeyLength {
+		t.Errorf("Expected ErrInvalidKeyLength, got %v", err)
+	}
+}
+
+func TestResolvePublicKeyEd25519(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	key, err := ResolveDIDPublicKey(makeDIDKey(pub))
+	if err != nil {
+		t.Fatalf("Failed to resolve: %v", err)
+	}
+
+	if key.Type != "Ed25519" {
+		t.Errorf("Expected key type Ed25519, got %s", key.Type)
+	}
+
+	edKey, err := key.Ed25519()
+	if err != nil {
+		t.Fatalf("Ed25519() failed: %v", err)
+	}
+	if !pub.Equal(edKey) {
+		t.Error("Resolved key does not match original")
+	}
+}
+
+func TestResolveSecp256k1InvalidKeyLength(t *testing.T) {
+	prefixedKey := append([]byte{0xe7, 0x01}, make([]byte, 16)...)
+	did := "did:key:z" + base58.Encode(prefixedKey)
+
+	if _, err := ResolveDIDPublicKey(did); err != ErrInvalidKeyLength {
+		t.Errorf("Expected ErrInvalidKeyLength, got %v", err)
+	}
+}
+
+func TestResolveDocumentDIDKey(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	did := makeDIDKey(pub)
+
+	doc, err := NewResolver().ResolveDocument(did)
+	if err != nil {
+		t.Fatalf("Failed to resolve document: %v", err)
+	}
+
+	if doc.ID != did {
+		t.Errorf("Expected document ID %s, got %s", did, doc.ID)
+	}
+	if len(doc.VerificationMethod) != 1 {
+		t.Fatalf("Expected 1 verification method, got %d", len(doc.VerificationMethod))
+	}
+
+	vm := doc.VerificationMethod[0]
+	if vm.ID != did+"#key-1" {
+		t.Errorf("Expected method ID %s#key-1, got %s", did, vm.ID)
+	}
+	if vm.Controller != did {
+		t.Errorf("Expected controller %s, got %s", did, vm.Controller)
+	}
+	if vm.Type != "Ed25519VerificationKey2018" {
+		t.Errorf("Expected type Ed25519VerificationKey2018, got %s", vm.Type)
+	}
+	if vm.PublicKeyBase58 != base58.Encode(pub) {
+		t.Error("Verification method key does not match original")
+	}
+	if len(doc.AssertionMethod) != 1 || doc.AssertionMethod[0] != vm.ID {
+		t.Errorf("Expected assertion method %s, got %v", vm.ID, doc.AssertionMethod)
+	}
+	if len(doc.Authentication) != 1 || doc.Authentication[0] != vm.ID {
+		t.Errorf("Expected authentication %s, got %v", vm.ID, doc.Authentication)
+	}
+}
+
+func TestResolveDocumentSecp256k1(t *testing.T) {
+	priv, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	compressed := priv.PubKey().SerializeCompressed()
+
+	prefixedKey := append([]byte{0xe7, 0x01}, compressed...)
+	did := "did:key:z" + base58.Encode(prefixedKey)
+
+	doc, err := ResolveDIDDocument(did)
+	if err != nil {
+		t.Fatalf("Failed to resolve document: %v", err)
+	}
+	if doc.VerificationMethod[0].Type != "EcdsaSecp256k1VerificationKey2019" {
+		t.Errorf("Expected type EcdsaSecp256k1VerificationKey2019, got %s", doc.VerificationMethod[0].Type)
+	}
+}
+
+func TestResolveDocumentErrors(t *testing.T) {
+	r := NewResolver()
+
+	if _, err := r.ResolveDocument("not-a-did"); err != ErrInvalidDID {
+		t.Errorf("Expected ErrInvalidDID, got %v", err)
+	}
+	if _, err := r.ResolveDocument("did:web:example.com"); err != ErrUnsupportedMethod {
+		t.Errorf("Expected ErrUnsupportedMethod, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package resolver
+
+import (
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+)
+
+// ResolveAllKeys returns every Ed25519 key a DID has signed credentials
+// with, newest first: the key of its first assertion method, then each key
+// it replaced, following the previousKey links of the document's
+// verification methods. A link to a method no longer in the document ends
+// the history, which is how a controller retires a compromised key. Keys of
+// other types are skipped; if none is Ed25519, ErrUnexpectedKeyType is
+// returned. A did:key DID has a single key.
+func (r *Resolver) ResolveAllKeys(didStr string) ([]ed25519.PublicKey, error) {
+	doc, err := r.ResolveDocument(didStr)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.VerificationMethod) == 0 {
+		return nil, ErrInvalidDIDDocument
+	}
+
+	current := &doc.VerificationMethod[0]
+	if len(doc.AssertionMethod) > 0 {
+		current = findMethod(doc, doc.AssertionMethod[0])
+		if current == nil {
+			return nil, fmt.Errorf("%w: assertion method %s not found", ErrInvalidDIDDocument, doc.AssertionMethod[0])
+		}
+	}
+
+	var keys []ed25519.PublicKey
+	seen := make(map[string]bool)
+	for vm := current; vm != nil && !seen[vm.ID]; vm = previousMethod(doc, vm) {
+		seen[vm.ID] = true
+		key, err := methodKey(vm)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidDIDDocument, vm.ID, err)
+		}
+		if key.Type == did.KeyTypeEd25519 {
+			keys = append(keys, ed25519.PublicKey(key.Bytes))
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrUnexpectedKeyType
+	}
+	return keys, nil
+}
+
+// previousMethod returns the verification method vm replaced, or nil when
+// vm has no previousKey or that method is no longer in the document
+func previousMethod(doc *did.DIDDocument, vm *did.VerificationMethod) *did.VerificationMethod {
+	if vm.PreviousKey == "" {
+		return nil
+	}
+	if prev := findMethod(doc, vm.PreviousKey); prev != nil {
+		return prev
+	}
+	// A relative link such as "#key-1" names a method in the same document
+	return findMethod(doc, doc.ID+vm.PreviousKey)
+}
+
+// ResolveAllKeys is a convenience function that resolves every Ed25519 key
+// a DID has signed with, newest first
+func ResolveAllKeys(didStr string) ([]ed25519.PublicKey, error) {
+	return NewResolver().ResolveAllKeys(didStr)
+}
@@ -0,0 +1,86 @@
+package resolver
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+)
+
+func TestResolveAllKeys(t *testing.T) {
+	firstPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	secondPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	thirdPub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	web, _ := did.CreateDIDWebEd25519("issuer.example.com", "", firstPub)
+	for _, pub := range []ed25519.PublicKey{secondPub, thirdPub} {
+		key, _ := did.CreateDIDKey(pub)
+		if err := web.RotateKey(key); err != nil {
+			t.Fatalf("RotateKey failed: %v", err)
+		}
+	}
+
+	store := NewStaticStore()
+	if err := store.AddDocument(web.DIDDocument); err != nil {
+		t.Fatalf("AddDocument failed: %v", err)
+	}
+	r := NewResolverWithStaticStore(store)
+
+	keys, err := r.ResolveAllKeys(web.DID)
+	if err != nil {
+		t.Fatalf("ResolveAllKeys failed: %v", err)
+	}
+	want := []ed25519.PublicKey{thirdPub, secondPub, firstPub}
+	if len(keys) != len(want) {
+		t.Fatalf("Expected %d keys, got %d", len(want), len(keys))
+	}
+	for i := range want {
+		if !keys[i].Equal(want[i]) {
+			t.Errorf("Expected key %d to be the one rotated in at step %d", i, len(want)-i)
+		}
+	}
+
+	// Resolution itself only returns the current key
+	current, err := r.Resolve(web.DID)
+	if err != nil || !current.Equal(thirdPub) {
+		t.Errorf("Expected Resolve to return the newest key, got %v", err)
+	}
+
+	// Removing a method retires its key and everything before it
+	retired := web.DIDDocument
+	retired.VerificationMethod = []did.VerificationMethod{web.DIDDocument.VerificationMethod[0], web.DIDDocument.VerificationMethod[2]}
+	store = NewStaticStore()
+	store.AddDocument(retired)
+	keys, err = NewResolverWithStaticStore(store).ResolveAllKeys(web.DID)
+	if err != nil || len(keys) != 1 || !keys[0].Equal(thirdPub) {
+		t.Errorf("Expected only the current key once its predecessor is removed, got %d keys, %v", len(keys), err)
+	}
+}
+
+func TestResolveAllKeysLoopAndDIDKey(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	// A document whose keys name each other as predecessors still terminates
+	web, _ := did.CreateDIDWebEd25519("issuer.example.com", "", pub)
+	other, _ := did.CreateDIDKey(otherPub)
+	web.RotateKey(other)
+	web.DIDDocument.VerificationMethod[0].PreviousKey = "#key-2"
+
+	store := NewStaticStore()
+	store.AddDocument(web.DIDDocument)
+	keys, err := NewResolverWithStaticStore(store).ResolveAllKeys(web.DID)
+	if err != nil || len(keys) != 2 {
+		t.Errorf("Expected 2 keys from a looping history, got %d, %v", len(keys), err)
+	}
+
+	didKey := makeDIDKey(pub)
+	keys, err = ResolveAllKeys(didKey)
+	if err != nil || len(keys) != 1 || !keys[0].Equal(pub) {
+		t.Errorf("Expected a did:key to have its single key, got %v", err)
+	}
+	if _, err := ResolveAllKeys("did:key:"); err != ErrInvalidDID {
+		t.Errorf("Expected ErrInvalidDID, got %v", err)
+	}
+}
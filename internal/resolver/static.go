@@ -0,0 +1,181 @@
+package resolver
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/mr-tron/base58"
+	"github.com/veriglob/veriglob-core/internal/did"
+)
+
+var (
+	ErrInvalidDIDDocument = errors.New("invalid DID document")
+)
+
+// StaticStore holds preloaded DID documents of trusted DIDs, so they can be
+// resolved offline. It is safe for concurrent use.
+type StaticStore struct {
+	mu   sync.RWMutex
+	docs map[string]*did.DIDDocument
+	keys map[string]*PublicKey
+}
+
+// NewStaticStore creates an empty static store
+func NewStaticStore() *StaticStore {
+	return &StaticStore{
+		docs: make(map[string]*did.DIDDocument),
+		keys: make(map[string]*PublicKey),
+	}
+}
+
+// NewResolverWithStaticStore creates a resolver that consults store before
+// any DID method
+func NewResolverWithStaticStore(store *StaticStore) *Resolver {
+	r := NewResolver()
+	r.static = store
+	return r
+}
+
+// AddDocument adds a DID document, replacing any earlier one for the same
+// DID. The DID's key is its first assertion method, or its first
+// verification method if it lists none.
+func (s *StaticStore) AddDocument(doc did.DIDDocument) error {
+	if doc.ID == "" || len(doc.VerificationMethod) == 0 {
+		return ErrInvalidDIDDocument
+	}
+
+	key, err := documentKey(&doc)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.docs[doc.ID] = copyDocument(&doc)
+	s.keys[doc.ID] = key
+	return nil
+}
+
+// LoadFile adds the DID document stored as JSON at path
+func (s *StaticStore) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var doc did.DIDDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidDIDDocument, path, err)
+	}
+	return s.AddDocument(doc)
+}
+
+// LoadDir adds every *.json DID document in dir
+func (s *StaticStore) LoadDir(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := s.LoadFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Document returns the stored DID document for a DID
+func (s *StaticStore) Document(didStr string) (*did.DIDDocument, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	doc, ok := s.docs[didStr]
+	return doc, ok
+}
+
+// publicKey returns the stored key for a DID
+func (s *StaticStore) publicKey(didStr string) (*PublicKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[didStr]
+	return key, ok
+}
+
+// documentKey decodes the key of a DID document's first assertion method, or
+// of its first verification method if it lists none
+func documentKey(doc *did.DIDDocument) (*PublicKey, error) {
+	if len(doc.VerificationMethod) == 0 {
+		return nil, ErrInvalidDIDDocument
+	}
+
+	primary := &doc.VerificationMethod[0]
+	if len(doc.AssertionMethod) > 0 {
+		primary = findMethod(doc, doc.AssertionMethod[0])
+		if primary == nil {
+			return nil, fmt.Errorf("%w: assertion method %s not found", ErrInvalidDIDDocument, doc.AssertionMethod[0])
+		}
+	}
+
+	key, err := methodKey(primary)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDIDDocument, err)
+	}
+	return key, nil
+}
+
+// findMethod returns the verification method with the given ID
+func findMethod(doc *did.DIDDocument, vmID string) *did.VerificationMethod {
+	for i := range doc.VerificationMethod {
+		if doc.VerificationMethod[i].ID == vmID {
+			return &doc.VerificationMethod[i]
+		}
+	}
+	return nil
+}
+
+// methodKey decodes the public key of a verification method
+func methodKey(vm *did.VerificationMethod) (*PublicKey, error) {
+	keyBytes, err := base58.Decode(vm.PublicKeyBase58)
+	if err != nil {
+		return nil, err
+	}
+
+	switch vm.Type {
+	case "Ed25519VerificationKey2018", "Ed25519VerificationKey2020":
+		if len(keyBytes) != ed25519.PublicKeySize {
+			return nil, ErrInvalidKeyLength
+		}
+		return &PublicKey{Type: did.KeyTypeEd25519, Bytes: keyBytes}, nil
+
+	case "EcdsaSecp256k1VerificationKey2019":
+		key, err := secp256k1.ParsePubKey(keyBytes)
+		if err != nil {
+			return nil, err
+		}
+		return &PublicKey{Type: did.KeyTypeSecp256k1, Bytes: key.SerializeCompressed()}, nil
+
+	case "EcdsaSecp256r1VerificationKey2019":
+		var key *ecdsa.PublicKey
+		if len(keyBytes) == 33 {
+			key, err = parseP256Compressed(keyBytes)
+		} else {
+			key, err = ecdsa.ParseUncompressedPublicKey(elliptic.P256(), keyBytes)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &PublicKey{Type: did.KeyTypeP256, Bytes: elliptic.MarshalCompressed(elliptic.P256(), key.X, key.Y)}, nil
+
+	default:
+		return nil, ErrUnexpectedKeyType
+	}
+}
@@ -0,0 +1,125 @@
+package resolver
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	"github.com/veriglob/veriglob-core/internal/did"
+)
+
+func webDocument(id string, pub ed25519.PublicKey) did.DIDDocument {
+	vmID := id + "#issuer-key"
+	return did.DIDDocument{
+		Context: []string{"https://www.w3.org/ns/did/v1"},
+		ID:      id,
+		VerificationMethod: []did.VerificationMethod{{
+			ID:              vmID,
+			Type:            "Ed25519VerificationKey2018",
+			Controller:      id,
+			PublicKeyBase58: base58.Encode(pub),
+		}},
+		AssertionMethod: []string{vmID},
+	}
+}
+
+func TestStaticStoreResolvesOffline(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	issuerDID := "did:web:issuer.example.com"
+
+	dir := t.TempDir()
+	data, _ := json.Marshal(webDocument(issuerDID, pub))
+	if err := os.WriteFile(filepath.Join(dir, "issuer.json"), data, 0600); err != nil {
+		t.Fatalf("Failed to write document: %v", err)
+	}
+
+	// did:web has no resolution method of its own
+	if _, err := NewResolver().Resolve(issuerDID); err != ErrUnsupportedMethod {
+		t.Fatalf("Expected ErrUnsupportedMethod without a static store, got %v", err)
+	}
+
+	store := NewStaticStore()
+	if err := store.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	r := NewResolverWithStaticStore(store)
+
+	got, err := r.Resolve(issuerDID)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if !got.Equal(pub) {
+		t.Error("Resolved key does not match the stored document")
+	}
+
+	vmKey, err := r.ResolveVerificationMethod(issuerDID + "#issuer-key")
+	if err != nil {
+		t.Fatalf("ResolveVerificationMethod failed: %v", err)
+	}
+	if !vmKey.Equal(pub) {
+		t.Error("Verification method key does not match the stored document")
+	}
+	if _, err := r.ResolveVerificationMethod(issuerDID + "#other"); err != ErrVerificationMethodNotFound {
+		t.Errorf("Expected ErrVerificationMethodNotFound, got %v", err)
+	}
+
+	doc, err := r.ResolveDocument(issuerDID)
+	if err != nil {
+		t.Fatalf("ResolveDocument failed: %v", err)
+	}
+	if doc.ID != issuerDID || len(doc.VerificationMethod) == 0 || doc.VerificationMethod[0].ID != issuerDID+"#issuer-key" {
+		t.Errorf("Expected the stored document, got %+v", doc)
+	}
+
+	// DIDs outside the store still resolve through their method
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	if key, err := r.Resolve(makeDIDKey(otherPub)); err != nil || !key.Equal(otherPub) {
+		t.Errorf("Expected did:key fallback to resolve, got %v", err)
+	}
+}
+
+func TestStaticStoreOverridesDIDKey(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	didKey, _ := did.CreateDIDKey(pub)
+
+	store := NewStaticStore()
+	if err := store.AddDocument(didKey.DIDDocument); err != nil {
+		t.Fatalf("AddDocument failed: %v", err)
+	}
+
+	got, err := NewResolverWithStaticStore(store).Resolve(didKey.DID)
+	if err != nil || !got.Equal(pub) {
+		t.Errorf("Expected stored did:key document to resolve, got %v", err)
+	}
+}
+
+func TestStaticStoreInvalidDocuments(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	store := NewStaticStore()
+
+	if err := store.AddDocument(did.DIDDocument{ID: "did:web:empty.example.com"}); err == nil {
+		t.Error("Expected error for a document without verification methods")
+	}
+
+	doc := webDocument("did:web:bad.example.com", pub)
+	doc.VerificationMethod[0].PublicKeyBase58 = base58.Encode(pub[:16])
+	if err := store.AddDocument(doc); err == nil {
+		t.Error("Expected error for a truncated key")
+	}
+
+	doc = webDocument("did:web:dangling.example.com", pub)
+	doc.AssertionMethod = []string{"did:web:dangling.example.com#missing"}
+	if err := store.AddDocument(doc); err == nil {
+		t.Error("Expected error for a dangling assertion method")
+	}
+
+	path := filepath.Join(t.TempDir(), "broken.json")
+	os.WriteFile(path, []byte("{not json"), 0600)
+	if err := store.LoadFile(path); err == nil {
+		t.Error("Expected error for malformed JSON")
+	}
+}
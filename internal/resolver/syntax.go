@@ -0,0 +1,53 @@
+package resolver
+
+import "strings"
+
+// ValidateDID checks a DID against the DID Core syntax:
+//
+//	did                = "did:" method-name ":" method-specific-id
+//	method-name        = 1*( %x61-7A / DIGIT )
+//	method-specific-id = *( *idchar ":" ) 1*idchar
+//	idchar             = ALPHA / DIGIT / "." / "-" / "_" / pct-encoded
+//
+// The method name is lowercase, and the method-specific ID may contain empty
+// colon-separated segments but must not end with one. A DID URL with a path,
+// query or fragment is not a DID; callers resolving a verification method
+// should split off the fragment first. Anything else returns ErrInvalidDID.
+func ValidateDID(did string) error {
+	rest, ok := strings.CutPrefix(did, "did:")
+	if !ok {
+		return ErrInvalidDID
+	}
+	method, id, ok := strings.Cut(rest, ":")
+	if !ok || method == "" || id == "" || strings.HasSuffix(id, ":") {
+		return ErrInvalidDID
+	}
+
+	for i := 0; i < len(method); i++ {
+		if c := method[i]; !('a' <= c && c <= 'z' || '0' <= c && c <= '9') {
+			return ErrInvalidDID
+		}
+	}
+
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case isIDChar(c), c == ':':
+		case c == '%' && i+2 < len(id) && isHexDigit(id[i+1]) && isHexDigit(id[i+2]):
+			i += 2
+		default:
+			return ErrInvalidDID
+		}
+	}
+	return nil
+}
+
+// isIDChar reports whether c is an unencoded idchar
+func isIDChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '.' || c == '-' || c == '_'
+}
+
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
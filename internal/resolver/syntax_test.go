@@ -0,0 +1,61 @@
+package resolver
+
+import "testing"
+
+func TestValidateDID(t *testing.T) {
+	tests := []struct {
+		name  string
+		did   string
+		valid bool
+	}{
+		{"did:key", "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", true},
+		{"did:web", "did:web:example.com", true},
+		{"did:web with port", "did:web:example.com%3A8443", true},
+		{"did:web with path", "did:web:example.com:users:alice", true},
+		{"empty inner segment", "did:example::123", true},
+		{"digits in method", "did:v1:test", true},
+		{"idchar punctuation", "did:example:a.b-c_d", true},
+
+		{"empty", "", false},
+		{"no prefix", "key:z6MkTest", false},
+		{"uppercase prefix", "DID:key:z6MkTest", false},
+		{"two parts", "did:key", false},
+		{"empty method", "did::z6MkTest", false},
+		{"uppercase method", "did:Key:z6MkTest", false},
+		{"empty identifier", "did:key:", false},
+		{"trailing colon", "did:web:example.com:", false},
+		{"whitespace", "did:key:z6Mk Test", false},
+		{"trailing newline", "did:key:z6MkTest\n", false},
+		{"fragment", "did:key:z6MkTest#key-1", false},
+		{"query", "did:web:example.com?service=files", false},
+		{"path", "did:web:example.com/path", false},
+		{"bad percent encoding", "did:web:example.com%3", false},
+		{"non-hex percent encoding", "did:web:example.com%zz", false},
+		{"non-ASCII", "did:example:café", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDID(tt.did)
+			if tt.valid && err != nil {
+				t.Errorf("Expected %q to be valid, got %v", tt.did, err)
+			}
+			if !tt.valid && err != ErrInvalidDID {
+				t.Errorf("Expected ErrInvalidDID for %q, got %v", tt.did, err)
+			}
+		})
+	}
+}
+
+func TestResolveRejectsInvalidDIDSyntax(t *testing.T) {
+	r := NewResolver()
+
+	for _, d := range []string{"did:key:", "did:KEY:z6MkTest", " did:key:z6MkTest", "did:key:z6MkTest#"} {
+		if _, err := r.Resolve(d); err != ErrInvalidDID {
+			t.Errorf("Expected ErrInvalidDID for %q, got %v", d, err)
+		}
+		if _, err := r.ResolvePublicKey(d); err != ErrInvalidDID {
+			t.Errorf("Expected ErrInvalidDID from ResolvePublicKey for %q, got %v", d, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/veriglob/veriglob-core/internal/httpclient"
+)
+
+var (
+	ErrMetadataUnavailable = errors.New("issuer metadata unavailable")
+)
+
+// maxMetadataSize bounds the metadata document read from an issuer
+const maxMetadataSize = 1 << 20
+
+// WebMetadataURL returns the HTTPS URL of the metadata a did:web issuer
+// publishes at WellKnownIssuerMetadataPath on its domain. A port is
+// percent-encoded in the DID as in did:web (did:web:example.com%3A8443), and
+// any path segments after the domain are ignored.
+func WebMetadataURL(issuerDID string) (string, error) {
+	rest, ok := strings.CutPrefix(issuerDID, "did:web:")
+	if !ok {
+		return "", ErrUnsupportedMethod
+	}
+	domain, _, _ := strings.Cut(rest, ":")
+	host, err := url.PathUnescape(domain)
+	if err != nil || host == "" || strings.ContainsAny(host, "/?#@") {
+		return "", ErrInvalidDID
+	}
+	return "https://" + host + WellKnownIssuerMetadataPath, nil
+}
+
+// NewWebMetadataFetcher returns a MetadataFetcher that downloads did:web
+// issuer metadata over HTTPS, retrying transient failures and rate limiting
+// requests per host as opts configures. The context passed to each fetch
+// bounds it; cancelling it stops the request and any pending retries.
+func NewWebMetadataFetcher(opts httpclient.Options) MetadataFetcher {
+	client := httpclient.NewClient(opts)
+	return func(ctx context.Context, issuerDID string) ([]byte, error) {
+		endpoint, err := WebMetadataURL(issuerDID)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMetadataUnavailable, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%w: unexpected status %d", ErrMetadataUnavailable, resp.StatusCode)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, maxMetadataSize))
+	}
+}
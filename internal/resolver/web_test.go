@@ -0,0 +1,150 @@
+package resolver
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+	"github.com/veriglob/veriglob-core/internal/httpclient"
+)
+
+func TestWebMetadataURL(t *testing.T) {
+	tests := []struct {
+		did  string
+		want string
+		err  error
+	}{
+		{"did:web:issuer.example", "https://issuer.example" + WellKnownIssuerMetadataPath, nil},
+		{"did:web:issuer.example%3A8443", "https://issuer.example:8443" + WellKnownIssuerMetadataPath, nil},
+		{"did:web:issuer.example:users:alice", "https://issuer.example" + WellKnownIssuerMetadataPath, nil},
+		{"did:web:", "", ErrInvalidDID},
+		{"did:web:evil.example%2Fpath", "", ErrInvalidDID},
+		{"did:key:z6Mk", "", ErrUnsupportedMethod},
+	}
+
+	for _, tt := range tests {
+		got, err := WebMetadataURL(tt.did)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("WebMetadataURL(%q): expected %q %v, got %q %v", tt.did, tt.want, tt.err, got, err)
+		}
+	}
+}
+
+// webDID returns the did:web of a test server, percent-encoding its port
+func webDID(srv *httptest.Server) string {
+	return "did:web:" + strings.Replace(strings.TrimPrefix(srv.URL, "https://"), ":", "%3A", 1)
+}
+
+func TestWebMetadataFetcherRetries(t *testing.T) {
+	var hits int32
+	var issuerDID string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != WellKnownIssuerMetadataPath {
+			http.NotFound(w, req)
+			return
+		}
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"issuer":"` + issuerDID + `","credentialTypes":["EducationCredential"]}`))
+	}))
+	defer srv.Close()
+
+	issuerDID = webDID(srv)
+
+	fetch := NewWebMetadataFetcher(httpclient.Options{
+		Client:    srv.Client(),
+		BaseDelay: time.Millisecond,
+	})
+	md, err := NewResolverWithMetadataFetcher(fetch).ResolveIssuerMetadata(context.Background(), issuerDID)
+	if err != nil {
+		t.Fatalf("ResolveIssuerMetadata failed: %v", err)
+	}
+	if !md.IssuesType("EducationCredential") {
+		t.Errorf("Expected EducationCredential, got %v", md.CredentialTypes)
+	}
+	if hits != 2 {
+		t.Errorf("Expected the 429 to be retried (2 requests), got %d", hits)
+	}
+}
+
+func TestWebMetadataFetcherUnavailable(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	issuerDID := webDID(srv)
+	fetch := NewWebMetadataFetcher(httpclient.Options{
+		Client:     srv.Client(),
+		MaxRetries: 1,
+		BaseDelay:  time.Millisecond,
+	})
+	if _, err := fetch(context.Background(), issuerDID); !errors.Is(err, ErrMetadataUnavailable) {
+		t.Errorf("Expected ErrMetadataUnavailable, got %v", err)
+	}
+}
+
+func TestWebMetadataFetcherContextPerCall(t *testing.T) {
+	var issuerDID string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"issuer":"` + issuerDID + `","credentialTypes":["EducationCredential"]}`))
+	}))
+	defer srv.Close()
+
+	issuerDID = webDID(srv)
+	fetch := NewWebMetadataFetcher(httpclient.Options{Client: srv.Client()})
+
+	// A cancelled context fails only the call it was passed to
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := fetch(cancelled, issuerDID); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := fetch(context.Background(), issuerDID); err != nil {
+		t.Errorf("Expected a later fetch to succeed, got %v", err)
+	}
+}
+
+func TestPublishedDIDWebDocumentResolves(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	web, err := did.CreateDIDWebEd25519("issuer.example", "", pub)
+	if err != nil {
+		t.Fatalf("CreateDIDWebEd25519 failed: %v", err)
+	}
+	srv := httptest.NewServer(web.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + web.DocumentPath)
+	if err != nil {
+		t.Fatalf("Failed to fetch document: %v", err)
+	}
+	defer resp.Body.Close()
+	var doc did.DIDDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatalf("Failed to decode document: %v", err)
+	}
+
+	store := NewStaticStore()
+	if err := store.AddDocument(doc); err != nil {
+		t.Fatalf("AddDocument failed: %v", err)
+	}
+	got, err := NewResolverWithStaticStore(store).Resolve(web.DID)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if !pub.Equal(got) {
+		t.Error("Expected the published document to resolve to the issuer key")
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package revocation
+
+import "sync"
+
+var (
+	fileLocksMu sync.Mutex
+	fileLocks   = map[string]*sync.Mutex{}
+)
+
+// lockFile serializes access to path within this process. Without flock,
+// registries in different processes are not protected from each other.
+func lockFile(path string) (func(), error) {
+	fileLocksMu.Lock()
+	mu, ok := fileLocks[path]
+	if !ok {
+		mu = &sync.Mutex{}
+		fileLocks[path] = mu
+	}
+	fileLocksMu.Unlock()
+
+	mu.Lock()
+	return mu.Unlock, nil
+}
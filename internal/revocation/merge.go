@@ -0,0 +1,126 @@
+package revocation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Merge folds another registry's entries into r, e.g. to reconcile issuer
+// nodes that each keep their own registry file. Entries only other has are
+// added. For a credential both have, the entries are reconciled so that
+// merging in either direction, and in any order, ends in the same state:
+//
+//   - revoked wins over suspended and active, so a revocation is never undone
+//   - of two revocations, the one with the newer RevokedAt wins, with its reason
+//   - suspended wins over active, since a reactivation carries no time to
+//     compare against; reactivate on every instance
+//   - of two suspensions, the one with the newer SuspendedAt wins
+//
+// The IDs of credentials whose entries differed are returned, sorted. An
+// entry naming a different issuer or subject is not the same credential: the
+// local entry is kept and its ID is returned as a conflict too.
+func (r *Registry) Merge(other *Registry) (conflicts []string, err error) {
+	if other == r {
+		return nil, nil
+	}
+
+	// Snapshot other first so the two registries are never locked together
+	other.mu.RLock()
+	incoming := make([]Entry, 0, len(other.entries))
+	for _, entry := range other.entries {
+		incoming = append(incoming, *entry)
+	}
+	other.mu.RUnlock()
+
+	err = r.update(func() error {
+		conflicts = nil
+		for i := range incoming {
+			theirs := incoming[i]
+			ours, exists := r.entries[theirs.CredentialID]
+			if !exists {
+				r.entries[theirs.CredentialID] = &theirs
+				continue
+			}
+			if ours.IssuerDID != theirs.IssuerDID || ours.SubjectDID != theirs.SubjectDID {
+				conflicts = append(conflicts, theirs.CredentialID)
+				continue
+			}
+			if sameStatus(ours, &theirs) {
+				continue
+			}
+			conflicts = append(conflicts, theirs.CredentialID)
+			if supersedes(&theirs, ours) {
+				ours.Status = theirs.Status
+				ours.RevokedAt = theirs.RevokedAt
+				ours.SuspendedAt = theirs.SuspendedAt
+				ours.Reason = theirs.Reason
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(conflicts)
+	return conflicts, nil
+}
+
+// LoadAndMerge folds the registry file at path, as written by a peer's
+// NewRegistryWithFile or Export, into r. A peer's signed export must be
+// checked with LoadSignedRegistry and then merged with Merge instead.
+func (r *Registry) LoadAndMerge(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	peer := NewRegistry()
+	if err := json.Unmarshal(data, &peer.entries); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for id, entry := range peer.entries {
+		if entry == nil || entry.CredentialID != id {
+			return nil, fmt.Errorf("%s: malformed entry %q", path, id)
+		}
+	}
+	return r.Merge(peer)
+}
+
+// sameStatus reports whether two entries for a credential agree on its status
+func sameStatus(a, b *Entry) bool {
+	return a.Status == b.Status && a.RevokedAt.Equal(b.RevokedAt) &&
+		a.SuspendedAt.Equal(b.SuspendedAt) && a.Reason == b.Reason
+}
+
+// supersedes reports whether entry a wins over entry b when merging
+func supersedes(a, b *Entry) bool {
+	if rank(a.Status) != rank(b.Status) {
+		return rank(a.Status) > rank(b.Status)
+	}
+	switch a.Status {
+	case StatusRevoked:
+		if !a.RevokedAt.Equal(b.RevokedAt) {
+			return a.RevokedAt.After(b.RevokedAt)
+		}
+	case StatusSuspended:
+		if !a.SuspendedAt.Equal(b.SuspendedAt) {
+			return a.SuspendedAt.After(b.SuspendedAt)
+		}
+	}
+	// Equal times: break the tie on the reason so both sides pick the same
+	return a.Reason > b.Reason
+}
+
+// rank orders statuses by how far a credential is from valid
+func rank(s Status) int {
+	switch s {
+	case StatusRevoked:
+		return 2
+	case StatusSuspended:
+		return 1
+	default:
+		return 0
+	}
+}
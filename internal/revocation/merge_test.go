@@ -0,0 +1,204 @@
+package revocation
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRegistryMergeDisjoint(t *testing.T) {
+	local := NewRegistry()
+	local.Register("urn:uuid:a", "did:key:issuer", "did:key:alice")
+	peer := NewRegistry()
+	peer.Register("urn:uuid:b", "did:key:issuer", "did:key:bob")
+	peer.Revoke("urn:uuid:b", "key compromise")
+
+	conflicts, err := local.Merge(peer)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts for disjoint registries, got %v", conflicts)
+	}
+	if local.Count(ListOptions{}) != 2 {
+		t.Errorf("Expected 2 entries after merging, got %d", local.Count(ListOptions{}))
+	}
+	if revoked, _ := local.IsRevoked("urn:uuid:b"); !revoked {
+		t.Error("Expected the peer's revocation to be merged")
+	}
+
+	// The merged entry is a copy
+	entry, _ := peer.CheckStatus("urn:uuid:b")
+	entry.Reason = "changed"
+	if merged, _ := local.CheckStatus("urn:uuid:b"); merged.Reason != "key compromise" {
+		t.Errorf("Expected the merged entry not to share memory, got reason %q", merged.Reason)
+	}
+}
+
+func TestRegistryMergeActiveAndRevoked(t *testing.T) {
+	newPair := func() (*Registry, *Registry) {
+		active := NewRegistry()
+		active.Register("urn:uuid:cred", "did:key:issuer", "did:key:subject")
+		revoked := NewRegistry()
+		revoked.Register("urn:uuid:cred", "did:key:issuer", "did:key:subject")
+		revoked.Revoke("urn:uuid:cred", "fraud")
+		return active, revoked
+	}
+
+	// Revoked wins whichever side it is on
+	active, revoked := newPair()
+	conflicts, err := active.Merge(revoked)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if !reflect.DeepEqual(conflicts, []string{"urn:uuid:cred"}) {
+		t.Errorf("Expected the credential as a conflict, got %v", conflicts)
+	}
+	if entry, _ := active.CheckStatus("urn:uuid:cred"); entry.Status != StatusRevoked || entry.Reason != "fraud" {
+		t.Errorf("Expected the revocation to win, got %s (%q)", entry.Status, entry.Reason)
+	}
+
+	active, revoked = newPair()
+	revoked.Merge(active)
+	if entry, _ := revoked.CheckStatus("urn:uuid:cred"); entry.Status != StatusRevoked {
+		t.Errorf("Expected an active entry not to undo a revocation, got %s", entry.Status)
+	}
+
+	// A suspension also yields to a revocation, and wins over active
+	suspended := NewRegistry()
+	suspended.Register("urn:uuid:cred", "did:key:issuer", "did:key:subject")
+	suspended.Suspend("urn:uuid:cred", "review")
+	active, revoked = newPair()
+	active.Merge(suspended)
+	if entry, _ := active.CheckStatus("urn:uuid:cred"); entry.Status != StatusSuspended {
+		t.Errorf("Expected the suspension to win over active, got %s", entry.Status)
+	}
+	suspended.Merge(revoked)
+	if entry, _ := suspended.CheckStatus("urn:uuid:cred"); entry.Status != StatusRevoked {
+		t.Errorf("Expected the revocation to win over a suspension, got %s", entry.Status)
+	}
+}
+
+func TestRegistryMergeRevokedTwice(t *testing.T) {
+	earlier := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+	newRevoked := func(at time.Time, reason string) *Registry {
+		r := NewRegistry()
+		r.entries["urn:uuid:cred"] = &Entry{
+			CredentialID: "urn:uuid:cred",
+			IssuerDID:    "did:key:issuer",
+			SubjectDID:   "did:key:subject",
+			Status:       StatusRevoked,
+			RevokedAt:    at,
+			Reason:       reason,
+		}
+		return r
+	}
+
+	a := newRevoked(earlier, "superseded")
+	b := newRevoked(later, "key compromise")
+	conflicts, err := a.Merge(b)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(conflicts) != 1 {
+		t.Errorf("Expected 1 conflict, got %v", conflicts)
+	}
+	if entry, _ := a.CheckStatus("urn:uuid:cred"); !entry.RevokedAt.Equal(later) || entry.Reason != "key compromise" {
+		t.Errorf("Expected the newer revocation to win, got %s (%q)", entry.RevokedAt, entry.Reason)
+	}
+
+	// Merging the other way converges on the same entry
+	a, b = newRevoked(earlier, "superseded"), newRevoked(later, "key compromise")
+	b.Merge(a)
+	if entry, _ := b.CheckStatus("urn:uuid:cred"); !entry.RevokedAt.Equal(later) || entry.Reason != "key compromise" {
+		t.Errorf("Expected the newer revocation to be kept, got %s (%q)", entry.RevokedAt, entry.Reason)
+	}
+
+	// Identical entries are not conflicts
+	same := newRevoked(later, "key compromise")
+	if conflicts, _ := b.Merge(same); len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts for identical entries, got %v", conflicts)
+	}
+}
+
+func TestRegistryMergeMismatchedEntry(t *testing.T) {
+	local := NewRegistry()
+	local.Register("urn:uuid:cred", "did:key:issuer", "did:key:alice")
+	peer := NewRegistry()
+	peer.Register("urn:uuid:cred", "did:key:other", "did:key:mallory")
+	peer.Revoke("urn:uuid:cred", "")
+
+	conflicts, err := local.Merge(peer)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if !reflect.DeepEqual(conflicts, []string{"urn:uuid:cred"}) {
+		t.Errorf("Expected the credential as a conflict, got %v", conflicts)
+	}
+	entry, _ := local.CheckStatus("urn:uuid:cred")
+	if entry.IssuerDID != "did:key:issuer" || entry.Status != StatusActive {
+		t.Errorf("Expected another issuer's entry to be ignored, got %+v", entry)
+	}
+
+	if conflicts, err := local.Merge(local); err != nil || conflicts != nil {
+		t.Errorf("Expected merging a registry into itself to do nothing, got %v, %v", conflicts, err)
+	}
+}
+
+func TestRegistryLoadAndMerge(t *testing.T) {
+	dir := t.TempDir()
+
+	peer := NewRegistry()
+	peer.Register("urn:uuid:a", "did:key:issuer", "did:key:alice")
+	peer.Revoke("urn:uuid:a", "test")
+	peer.Register("urn:uuid:b", "did:key:issuer", "did:key:bob")
+	exported, _ := peer.Export()
+	peerPath := filepath.Join(dir, "peer.json")
+	os.WriteFile(peerPath, exported, 0644)
+
+	localPath := filepath.Join(dir, "local.json")
+	local, err := NewRegistryWithFile(localPath)
+	if err != nil {
+		t.Fatalf("NewRegistryWithFile failed: %v", err)
+	}
+	local.Register("urn:uuid:a", "did:key:issuer", "did:key:alice")
+
+	conflicts, err := local.LoadAndMerge(peerPath)
+	if err != nil {
+		t.Fatalf("LoadAndMerge failed: %v", err)
+	}
+	if !reflect.DeepEqual(conflicts, []string{"urn:uuid:a"}) {
+		t.Errorf("Expected urn:uuid:a as a conflict, got %v", conflicts)
+	}
+
+	// The merge is persisted
+	reopened, _ := NewRegistryWithFile(localPath)
+	if revoked, _ := reopened.IsRevoked("urn:uuid:a"); !revoked {
+		t.Error("Expected the merged revocation to be saved")
+	}
+	if _, err := reopened.CheckStatus("urn:uuid:b"); err != nil {
+		t.Errorf("Expected the peer's entry to be saved, got %v", err)
+	}
+
+	// Merging the peer file again changes nothing
+	before, _ := reopened.Export()
+	if conflicts, _ := reopened.LoadAndMerge(peerPath); len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts on a second merge, got %v", conflicts)
+	}
+	if after, _ := reopened.Export(); !bytes.Equal(before, after) {
+		t.Error("Expected merging twice to be idempotent")
+	}
+
+	if _, err := local.LoadAndMerge(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected a not-exist error, got %v", err)
+	}
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(bad, []byte(`{"urn:uuid:x": {"credentialId": "urn:uuid:y"}}`), 0644)
+	if _, err := local.LoadAndMerge(bad); err == nil {
+		t.Error("Expected an error for an entry filed under another ID")
+	}
+}
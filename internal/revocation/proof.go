@@ -0,0 +1,122 @@
+package revocation
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidStatusProof          = errors.New("invalid status proof")
+	ErrStatusProofSignatureInvalid = errors.New("status proof signature is invalid")
+	ErrStatusProofStale            = errors.New("status proof is older than the accepted maximum age")
+)
+
+const (
+	// StatusProofFormat identifies a status proof envelope
+	StatusProofFormat = "veriglob-status-proof"
+
+	// DefaultStatusProofMaxAge is how old a status proof may be when the
+	// verifier sets no policy
+	DefaultStatusProofMaxAge = 24 * time.Hour
+
+	// statusProofVersion is the current envelope format
+	statusProofVersion = 1
+
+	// statusProofClockSkew is how far in the future a proof's signing time may be
+	statusProofClockSkew = time.Minute
+)
+
+// StatusProof is an issuer-signed statement of one credential's status at a
+// point in time, which a holder can carry to a verifier that has no network
+// access. Like a signed registry, the signature covers the payload bytes as
+// stored: Ed25519 over StatusProofFormat, a newline, and the payload.
+type StatusProof struct {
+	Format    string `json:"format"`
+	Version   int    `json:"version"`
+	Payload   []byte `json:"payload"`
+	Signature []byte `json:"signature"`
+}
+
+// StatusStatement is the signed content of a status proof
+type StatusStatement struct {
+	CredentialID string    `json:"credentialId"`
+	IssuerDID    string    `json:"issuerDid"`
+	Status       Status    `json:"status"`
+	SignedAt     time.Time `json:"signedAt"`
+}
+
+// signingInput prefixes the payload with the format, so a status proof
+// signature cannot be replayed as a signature over some other kind of message
+func (p *StatusProof) signingInput() []byte {
+	return append([]byte(StatusProofFormat+"\n"), p.Payload...)
+}
+
+// StatusProof returns the current status of a credential signed with the
+// issuer's key. Holders attach it to presentations so verifiers can check
+// revocation offline; a proof is only as recent as its signing time.
+func (r *Registry) StatusProof(credentialID string, issuerPrivateKey ed25519.PrivateKey) (*StatusProof, error) {
+	if len(issuerPrivateKey) != ed25519.PrivateKeySize {
+		return nil, ErrInvalidStatusProof
+	}
+
+	r.mu.RLock()
+	entry, exists := r.entries[credentialID]
+	var statement StatusStatement
+	if exists {
+		statement = StatusStatement{
+			CredentialID: credentialID,
+			IssuerDID:    entry.IssuerDID,
+			Status:       entry.Status,
+			SignedAt:     time.Now().UTC(),
+		}
+	}
+	r.mu.RUnlock()
+	if !exists {
+		return nil, ErrCredentialNotFound
+	}
+
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &StatusProof{
+		Format:  StatusProofFormat,
+		Version: statusProofVersion,
+		Payload: payload,
+	}
+	proof.Signature = ed25519.Sign(issuerPrivateKey, proof.signingInput())
+	return proof, nil
+}
+
+// Verify checks the proof's signature against the issuer's public key and
+// that it was signed no more than maxAge before now, returning the signed
+// statement. A maxAge that is not positive uses DefaultStatusProofMaxAge.
+// The caller must still check that the statement names the credential and
+// issuer it expects.
+func (p *StatusProof) Verify(issuerPublicKey ed25519.PublicKey, maxAge time.Duration, now time.Time) (*StatusStatement, error) {
+	if p.Format != StatusProofFormat || p.Version != statusProofVersion {
+		return nil, ErrInvalidStatusProof
+	}
+	if len(issuerPublicKey) != ed25519.PublicKeySize || !ed25519.Verify(issuerPublicKey, p.signingInput(), p.Signature) {
+		return nil, ErrStatusProofSignatureInvalid
+	}
+
+	var statement StatusStatement
+	if err := json.Unmarshal(p.Payload, &statement); err != nil || statement.CredentialID == "" {
+		return nil, ErrInvalidStatusProof
+	}
+
+	if maxAge <= 0 {
+		maxAge = DefaultStatusProofMaxAge
+	}
+	if statement.SignedAt.After(now.Add(statusProofClockSkew)) {
+		return nil, ErrInvalidStatusProof
+	}
+	if now.Sub(statement.SignedAt) > maxAge {
+		return nil, ErrStatusProofStale
+	}
+	return &statement, nil
+}
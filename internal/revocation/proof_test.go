@@ -0,0 +1,90 @@
+package revocation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStatusProofRoundTrip(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	r := NewRegistry()
+	r.Register("urn:uuid:revoked", "did:key:issuer", "did:key:subject")
+	r.Revoke("urn:uuid:revoked", "compromised")
+
+	proof, err := r.StatusProof("urn:uuid:revoked", issuerPriv)
+	if err != nil {
+		t.Fatalf("StatusProof failed: %v", err)
+	}
+
+	// The proof survives its JSON wire format
+	data, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	var decoded StatusProof
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	statement, err := decoded.Verify(issuerPub, time.Hour, time.Now())
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if statement.CredentialID != "urn:uuid:revoked" || statement.IssuerDID != "did:key:issuer" {
+		t.Errorf("Expected statement for urn:uuid:revoked by did:key:issuer, got %+v", statement)
+	}
+	if statement.Status != StatusRevoked {
+		t.Errorf("Expected status %s, got %s", StatusRevoked, statement.Status)
+	}
+
+	if _, err := r.StatusProof("urn:uuid:missing", issuerPriv); err != ErrCredentialNotFound {
+		t.Errorf("Expected ErrCredentialNotFound, got %v", err)
+	}
+	if _, err := r.StatusProof("urn:uuid:revoked", issuerPriv[:10]); err != ErrInvalidStatusProof {
+		t.Errorf("Expected ErrInvalidStatusProof for a short key, got %v", err)
+	}
+}
+
+func TestStatusProofRejects(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	r := NewRegistry()
+	r.Register("urn:uuid:active", "did:key:issuer", "did:key:subject")
+	proof, err := r.StatusProof("urn:uuid:active", issuerPriv)
+	if err != nil {
+		t.Fatalf("StatusProof failed: %v", err)
+	}
+	now := time.Now()
+
+	if _, err := proof.Verify(otherPub, time.Hour, now); !errors.Is(err, ErrStatusProofSignatureInvalid) {
+		t.Errorf("Expected ErrStatusProofSignatureInvalid for another key, got %v", err)
+	}
+
+	if _, err := proof.Verify(issuerPub, time.Hour, now.Add(2*time.Hour)); !errors.Is(err, ErrStatusProofStale) {
+		t.Errorf("Expected ErrStatusProofStale after max age, got %v", err)
+	}
+	if _, err := proof.Verify(issuerPub, 0, now.Add(2*time.Hour)); err != nil {
+		t.Errorf("Expected default max age to accept a 2h old proof, got %v", err)
+	}
+	if _, err := proof.Verify(issuerPub, time.Hour, now.Add(-time.Hour)); !errors.Is(err, ErrInvalidStatusProof) {
+		t.Errorf("Expected ErrInvalidStatusProof for a proof signed in the future, got %v", err)
+	}
+
+	tampered := *proof
+	tampered.Payload = []byte(`{"credentialId":"urn:uuid:active","issuerDid":"did:key:issuer","status":"active","signedAt":"2099-01-01T00:00:00Z"}`)
+	if _, err := tampered.Verify(issuerPub, time.Hour, now); !errors.Is(err, ErrStatusProofSignatureInvalid) {
+		t.Errorf("Expected ErrStatusProofSignatureInvalid for a tampered payload, got %v", err)
+	}
+
+	wrongFormat := *proof
+	wrongFormat.Format = SignedRegistryFormat
+	if _, err := wrongFormat.Verify(issuerPub, time.Hour, now); !errors.Is(err, ErrInvalidStatusProof) {
+		t.Errorf("Expected ErrInvalidStatusProof for another format, got %v", err)
+	}
+}
@@ -2,10 +2,14 @@ package revocation
 
 import (
 	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"os"
+	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 )
@@ -13,14 +17,20 @@ import (
 var (
 	ErrCredentialNotFound = errors.New("credential not found in registry")
 	ErrAlreadyRevoked     = errors.New("credential already revoked")
+	ErrCredentialRevoked  = errors.New("credential has been revoked")
+	ErrAlreadySuspended   = errors.New("credential already suspended")
+	ErrNotSuspended       = errors.New("credential is not suspended")
+	ErrRevokedIsPermanent = errors.New("revoked credentials cannot be reactivated")
+	ErrInvalidListOptions = errors.New("invalid registry list options")
 )
 
 // Status represents the revocation status of a credential
 type Status string
 
 const (
-	StatusActive  Status = "active"
-	StatusRevoked Status = "revoked"
+	StatusActive    Status = "active"
+	StatusRevoked   Status = "revoked"
+	StatusSuspended Status = "suspended"
 )
 
 // Entry represents a single credential entry in the registry
@@ -31,6 +41,7 @@ type Entry struct {
 	Status       Status    `json:"status"`
 	IssuedAt     time.Time `json:"issuedAt"`
 	RevokedAt    time.Time `json:"revokedAt,omitempty"`
+	SuspendedAt  time.Time `json:"suspendedAt,omitempty"`
 	Reason       string    `json:"reason,omitempty"`
 }
 
@@ -77,48 +88,121 @@ func GenerateCredentialID() (string, error) {
 	if _, err := rand.Read(bytes); err != nil {
 		return "", err
 	}
+	return formatUUID(bytes), nil
+}
+
+// credentialIDNamespace is the UUIDv5 namespace of derived credential IDs,
+// itself the UUIDv5 of https://github.com/veriglob/veriglob-core/credential-id
+// in the URL namespace
+var credentialIDNamespace = []byte{
+	0x8b, 0x79, 0xb2, 0xa7, 0xfc, 0xa4, 0x55, 0xee,
+	0xab, 0x9e, 0x46, 0xd8, 0x96, 0x9d, 0xfb, 0x85,
+}
+
+// DeriveCredentialID returns a urn:uuid credential ID that is the same
+// every time it is given the same inputs: a UUIDv5 over the issuer DID,
+// subject DID, credential type and salt. An issuer re-running issuance gets
+// the ID it issued before and can find it in its registry. The salt is the
+// issuer's to choose, e.g. a record number, so a subject can hold several
+// credentials of one type; keep it secret if IDs must not be guessable.
+func DeriveCredentialID(issuerDID, subjectDID, credType string, salt []byte) string {
+	h := sha1.New()
+	h.Write(credentialIDNamespace)
+	// Length prefixes keep the fields from running into each other
+	for _, field := range [][]byte{[]byte(issuerDID), []byte(subjectDID), []byte(credType), salt} {
+		h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(field))))
+		h.Write(field)
+	}
+	bytes := h.Sum(nil)[:16]
+	bytes[6] = bytes[6]&0x0f | 0x50 // version 5
+	bytes[8] = bytes[8]&0x3f | 0x80 // RFC 4122 variant
+	return formatUUID(bytes)
+}
+
+// formatUUID formats 16 bytes as a urn:uuid
+func formatUUID(bytes []byte) string {
 	return "urn:uuid:" + hex.EncodeToString(bytes[:4]) + "-" +
 		hex.EncodeToString(bytes[4:6]) + "-" +
 		hex.EncodeToString(bytes[6:8]) + "-" +
 		hex.EncodeToString(bytes[8:10]) + "-" +
-		hex.EncodeToString(bytes[10:]), nil
+		hex.EncodeToString(bytes[10:])
 }
 
 // Register adds a new credential to the registry
 func (r *Registry) Register(credentialID, issuerDID, subjectDID string) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	r.entries[credentialID] = &Entry{
-		CredentialID: credentialID,
-		IssuerDID:    issuerDID,
-		SubjectDID:   subjectDID,
-		Status:       StatusActive,
-		IssuedAt:     time.Now(),
-	}
-
-	return r.save()
+	return r.update(func() error {
+		r.entries[credentialID] = &Entry{
+			CredentialID: credentialID,
+			IssuerDID:    issuerDID,
+			SubjectDID:   subjectDID,
+			Status:       StatusActive,
+			IssuedAt:     time.Now(),
+		}
+		return nil
+	})
 }
 
 // Revoke marks a credential as revoked
 func (r *Registry) Revoke(credentialID, reason string) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	return r.update(func() error {
+		entry, exists := r.entries[credentialID]
+		if !exists {
+			return ErrCredentialNotFound
+		}
 
-	entry, exists := r.entries[credentialID]
-	if !exists {
-		return ErrCredentialNotFound
-	}
+		if entry.Status == StatusRevoked {
+			return ErrAlreadyRevoked
+		}
 
-	if entry.Status == StatusRevoked {
-		return ErrAlreadyRevoked
-	}
+		entry.Status = StatusRevoked
+		entry.RevokedAt = time.Now()
+		entry.Reason = reason
+		return nil
+	})
+}
 
-	entry.Status = StatusRevoked
-	entry.RevokedAt = time.Now()
-	entry.Reason = reason
+// Suspend temporarily places a credential on hold; it can later be reactivated
+func (r *Registry) Suspend(credentialID, reason string) error {
+	return r.update(func() error {
+		entry, exists := r.entries[credentialID]
+		if !exists {
+			return ErrCredentialNotFound
+		}
 
-	return r.save()
+		switch entry.Status {
+		case StatusRevoked:
+			return ErrAlreadyRevoked
+		case StatusSuspended:
+			return ErrAlreadySuspended
+		}
+
+		entry.Status = StatusSuspended
+		entry.SuspendedAt = time.Now()
+		entry.Reason = reason
+		return nil
+	})
+}
+
+// Reactivate lifts a suspension. Revocation is permanent and cannot be undone.
+func (r *Registry) Reactivate(credentialID string) error {
+	return r.update(func() error {
+		entry, exists := r.entries[credentialID]
+		if !exists {
+			return ErrCredentialNotFound
+		}
+
+		switch entry.Status {
+		case StatusRevoked:
+			return ErrRevokedIsPermanent
+		case StatusActive:
+			return ErrNotSuspended
+		}
+
+		entry.Status = StatusActive
+		entry.SuspendedAt = time.Time{}
+		entry.Reason = ""
+		return nil
+	})
 }
 
 // CheckStatus returns the status of a credential
@@ -143,6 +227,15 @@ func (r *Registry) IsRevoked(credentialID string) (bool, error) {
 	return entry.Status == StatusRevoked, nil
 }
 
+// IsSuspended checks if a credential is currently suspended
+func (r *Registry) IsSuspended(credentialID string) (bool, error) {
+	entry, err := r.CheckStatus(credentialID)
+	if err != nil {
+		return false, err
+	}
+	return entry.Status == StatusSuspended, nil
+}
+
 // ListByIssuer returns all credentials issued by a specific DID
 func (r *Registry) ListByIssuer(issuerDID string) []*Entry {
 	r.mu.RLock()
@@ -171,7 +264,139 @@ func (r *Registry) ListBySubject(subjectDID string) []*Entry {
 	return results
 }
 
-// save persists the registry to disk if a path is configured
+// ListOptions filters and pages a registry listing. Zero-valued fields
+// match everything.
+type ListOptions struct {
+	Status     Status
+	IssuerDID  string
+	SubjectDID string
+	// IssuedAfter and IssuedBefore bound IssuedAt, exclusive on both ends
+	IssuedAfter  time.Time
+	IssuedBefore time.Time
+	// Offset skips that many matching entries; Limit caps the page size,
+	// with zero meaning no limit
+	Offset int
+	Limit  int
+}
+
+// matches reports whether an entry passes the filter fields of opts
+func (opts ListOptions) matches(e *Entry) bool {
+	if opts.Status != "" && e.Status != opts.Status {
+		return false
+	}
+	if opts.IssuerDID != "" && e.IssuerDID != opts.IssuerDID {
+		return false
+	}
+	if opts.SubjectDID != "" && e.SubjectDID != opts.SubjectDID {
+		return false
+	}
+	if !opts.IssuedAfter.IsZero() && !e.IssuedAt.After(opts.IssuedAfter) {
+		return false
+	}
+	if !opts.IssuedBefore.IsZero() && !e.IssuedAt.Before(opts.IssuedBefore) {
+		return false
+	}
+	return true
+}
+
+// List returns one page of the entries matching opts, oldest first by
+// IssuedAt with ties broken by credential ID, so pages are stable. The
+// entries are copies. Use Count for the total number of matches.
+func (r *Registry) List(opts ListOptions) ([]*Entry, error) {
+	if opts.Offset < 0 || opts.Limit < 0 {
+		return nil, ErrInvalidListOptions
+	}
+
+	r.mu.RLock()
+	var matched []*Entry
+	for _, entry := range r.entries {
+		if opts.matches(entry) {
+			copied := *entry
+			matched = append(matched, &copied)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].IssuedAt.Equal(matched[j].IssuedAt) {
+			return matched[i].IssuedAt.Before(matched[j].IssuedAt)
+		}
+		return matched[i].CredentialID < matched[j].CredentialID
+	})
+
+	if opts.Offset >= len(matched) {
+		return []*Entry{}, nil
+	}
+	matched = matched[opts.Offset:]
+	if opts.Limit > 0 && opts.Limit < len(matched) {
+		matched = matched[:opts.Limit]
+	}
+	return matched, nil
+}
+
+// Count returns how many entries match the filter fields of opts,
+// ignoring Offset and Limit
+func (r *Registry) Count(opts ListOptions) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n := 0
+	for _, entry := range r.entries {
+		if opts.matches(entry) {
+			n++
+		}
+	}
+	return n
+}
+
+// update applies a change to the registry and persists it. For a file-backed
+// registry, the file is locked and re-read first, so changes made by other
+// processes sharing the file are merged rather than overwritten.
+func (r *Registry) update(change func() error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.path != "" {
+		unlock, err := lockFile(r.path + ".lock")
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		if err := r.reload(); err != nil {
+			return err
+		}
+	}
+
+	if err := change(); err != nil {
+		return err
+	}
+	return r.save()
+}
+
+// reload replaces the in-memory entries with the file's, if it exists
+func (r *Registry) reload() error {
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	entries := make(map[string]*Entry)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	r.entries = entries
+	return nil
+}
+
+// save persists the registry to disk if a path is configured. The file is
+// replaced atomically so readers never see a partial write.
 func (r *Registry) save() error {
 	if r.path == "" {
 		return nil
@@ -182,7 +407,24 @@ func (r *Registry) save() error {
 		return err
 	}
 
-	return os.WriteFile(r.path, data, 0644)
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
 }
 
 // Export returns all entries as JSON
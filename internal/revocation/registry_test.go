@@ -1,9 +1,12 @@
 package revocation
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestGenerateCredentialID(t *testing.T) {
@@ -32,6 +35,38 @@ func TestGenerateCredentialID(t *testing.T) {
 	}
 }
 
+func TestDeriveCredentialID(t *testing.T) {
+	const issuer, alice, identity = "did:key:zIssuer", "did:key:zAlice", "IdentityCredential"
+
+	id := DeriveCredentialID(issuer, alice, identity, nil)
+	if id != DeriveCredentialID(issuer, alice, identity, nil) {
+		t.Error("Expected identical inputs to derive the same ID")
+	}
+	// The derivation is part of the format: IDs issued earlier must still match
+	if want := "urn:uuid:691d5787-313d-54bb-b328-0e88fefe7e89"; id != want {
+		t.Errorf("Expected %s, got %s", want, id)
+	}
+	if id[9+14] != '5' {
+		t.Errorf("Expected a version 5 UUID, got %s", id)
+	}
+
+	tests := []struct {
+		name  string
+		other string
+	}{
+		{"different subject", DeriveCredentialID(issuer, "did:key:zBob", identity, nil)},
+		{"different issuer", DeriveCredentialID("did:key:zOther", alice, identity, nil)},
+		{"different type", DeriveCredentialID(issuer, alice, "EducationCredential", nil)},
+		{"salt", DeriveCredentialID(issuer, alice, identity, []byte("record-2"))},
+		{"shifted field boundary", DeriveCredentialID(issuer, alice+"I", "dentityCredential", nil)},
+	}
+	for _, tt := range tests {
+		if tt.other == id {
+			t.Errorf("Expected a %s to derive a different ID, got %s for both", tt.name, id)
+		}
+	}
+}
+
 func TestNewRegistry(t *testing.T) {
 	r := NewRegistry()
 	if r == nil {
@@ -223,6 +258,72 @@ func TestRegistryWithFile(t *testing.T) {
 	}
 }
 
+func TestRegistryConcurrentWritersShareFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.json")
+
+	const writers = 8
+	const perWriter = 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter)
+	for w := 0; w < writers; w++ {
+		// Each writer has its own Registry, as separate issuer processes would
+		r, err := NewRegistryWithFile(path)
+		if err != nil {
+			t.Fatalf("Failed to open registry: %v", err)
+		}
+
+		wg.Add(1)
+		go func(w int, r *Registry) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				if err := r.Register(fmt.Sprintf("urn:uuid:w%d-%d", w, i), "did:key:issuer", "did:key:subject"); err != nil {
+					errs <- err
+				}
+			}
+		}(w, r)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	loaded, err := NewRegistryWithFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load registry: %v", err)
+	}
+	for w := 0; w < writers; w++ {
+		for i := 0; i < perWriter; i++ {
+			if _, err := loaded.CheckStatus(fmt.Sprintf("urn:uuid:w%d-%d", w, i)); err != nil {
+				t.Errorf("Entry urn:uuid:w%d-%d was lost: %v", w, i, err)
+			}
+		}
+	}
+}
+
+func TestRegistryMergesChangesFromOtherWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.json")
+
+	r1, _ := NewRegistryWithFile(path)
+	r2, _ := NewRegistryWithFile(path)
+
+	r1.Register("urn:uuid:one", "did:key:issuer", "did:key:subject")
+	// r2 never saw urn:uuid:one in memory
+	r2.Register("urn:uuid:two", "did:key:issuer", "did:key:subject")
+	if err := r2.Revoke("urn:uuid:one", "revoked by another writer"); err != nil {
+		t.Fatalf("Expected r2 to see r1's entry, got %v", err)
+	}
+
+	loaded, _ := NewRegistryWithFile(path)
+	if revoked, err := loaded.IsRevoked("urn:uuid:one"); err != nil || !revoked {
+		t.Errorf("Expected urn:uuid:one revoked, got %v (%v)", revoked, err)
+	}
+	if _, err := loaded.CheckStatus("urn:uuid:two"); err != nil {
+		t.Errorf("Expected urn:uuid:two to survive, got %v", err)
+	}
+}
+
 func TestRegistryExport(t *testing.T) {
 	r := NewRegistry()
 	r.Register("urn:uuid:export-test", "did:key:issuer", "did:key:subject")
@@ -236,3 +337,238 @@ func TestRegistryExport(t *testing.T) {
 		t.Error("Export should return non-empty data")
 	}
 }
+
+func TestRegistrySuspendReactivateCycle(t *testing.T) {
+	r := NewRegistry()
+
+	credID := "urn:uuid:suspend-test"
+	r.Register(credID, "did:key:issuer", "did:key:subject")
+
+	if err := r.Suspend(credID, "membership on hold"); err != nil {
+		t.Fatalf("Failed to suspend: %v", err)
+	}
+
+	entry, _ := r.CheckStatus(credID)
+	if entry.Status != StatusSuspended {
+		t.Errorf("Expected status %s, got %s", StatusSuspended, entry.Status)
+	}
+	if entry.SuspendedAt.IsZero() {
+		t.Error("SuspendedAt should be set")
+	}
+
+	// Suspension is not revocation
+	revoked, _ := r.IsRevoked(credID)
+	if revoked {
+		t.Error("Suspended credential should not report as revoked")
+	}
+	suspended, _ := r.IsSuspended(credID)
+	if !suspended {
+		t.Error("Credential should report as suspended")
+	}
+
+	if err := r.Suspend(credID, "again"); err != ErrAlreadySuspended {
+		t.Errorf("Expected ErrAlreadySuspended, got %v", err)
+	}
+
+	if err := r.Reactivate(credID); err != nil {
+		t.Fatalf("Failed to reactivate: %v", err)
+	}
+
+	entry, _ = r.CheckStatus(credID)
+	if entry.Status != StatusActive {
+		t.Errorf("Expected status %s after reactivation, got %s", StatusActive, entry.Status)
+	}
+	if !entry.SuspendedAt.IsZero() || entry.Reason != "" {
+		t.Error("Suspension details should be cleared on reactivation")
+	}
+
+	if err := r.Reactivate(credID); err != ErrNotSuspended {
+		t.Errorf("Expected ErrNotSuspended, got %v", err)
+	}
+
+	if err := r.Suspend(credID, "on hold again"); err != nil {
+		t.Fatalf("Failed to suspend a second time: %v", err)
+	}
+}
+
+func TestRegistryReactivateRevokedRejected(t *testing.T) {
+	r := NewRegistry()
+
+	credID := "urn:uuid:revoked-permanent"
+	r.Register(credID, "did:key:issuer", "did:key:subject")
+	r.Suspend(credID, "on hold")
+
+	// A suspended credential can still be revoked permanently
+	if err := r.Revoke(credID, "fraud"); err != nil {
+		t.Fatalf("Failed to revoke suspended credential: %v", err)
+	}
+
+	if err := r.Reactivate(credID); err != ErrRevokedIsPermanent {
+		t.Errorf("Expected ErrRevokedIsPermanent, got %v", err)
+	}
+
+	if err := r.Suspend(credID, "too late"); err != ErrAlreadyRevoked {
+		t.Errorf("Expected ErrAlreadyRevoked, got %v", err)
+	}
+
+	revoked, _ := r.IsRevoked(credID)
+	if !revoked {
+		t.Error("Credential should remain revoked")
+	}
+}
+
+func TestRegistrySuspensionPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.json")
+
+	r1, _ := NewRegistryWithFile(path)
+	r1.Register("urn:uuid:persist-suspend", "did:key:issuer", "did:key:subject")
+	r1.Suspend("urn:uuid:persist-suspend", "on hold")
+
+	r2, err := NewRegistryWithFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load registry: %v", err)
+	}
+
+	entry, err := r2.CheckStatus("urn:uuid:persist-suspend")
+	if err != nil {
+		t.Fatalf("Failed to find credential: %v", err)
+	}
+	if entry.Status != StatusSuspended {
+		t.Errorf("Expected status %s, got %s", StatusSuspended, entry.Status)
+	}
+	if entry.Reason != "on hold" {
+		t.Errorf("Expected reason 'on hold', got %s", entry.Reason)
+	}
+}
+
+// listTestRegistry returns a registry with five entries issued an hour apart
+func listTestRegistry(t *testing.T) (*Registry, time.Time) {
+	t.Helper()
+	r := NewRegistry()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	entries := []struct {
+		id, issuer, subject string
+	}{
+		{"urn:uuid:e", "did:key:issuer1", "did:key:subject1"},
+		{"urn:uuid:d", "did:key:issuer1", "did:key:subject2"},
+		{"urn:uuid:c", "did:key:issuer2", "did:key:subject1"},
+		{"urn:uuid:b", "did:key:issuer1", "did:key:subject3"},
+		{"urn:uuid:a", "did:key:issuer2", "did:key:subject2"},
+	}
+	for i, e := range entries {
+		if err := r.Register(e.id, e.issuer, e.subject); err != nil {
+			t.Fatalf("Failed to register: %v", err)
+		}
+		r.entries[e.id].IssuedAt = base.Add(time.Duration(i) * time.Hour)
+	}
+	r.Revoke("urn:uuid:d", "compromised")
+	r.Revoke("urn:uuid:a", "compromised")
+	r.Suspend("urn:uuid:b", "on hold")
+
+	return r, base
+}
+
+func TestRegistryListFilters(t *testing.T) {
+	r, base := listTestRegistry(t)
+
+	tests := []struct {
+		name string
+		opts ListOptions
+		want []string
+	}{
+		{"all", ListOptions{}, []string{"urn:uuid:e", "urn:uuid:d", "urn:uuid:c", "urn:uuid:b", "urn:uuid:a"}},
+		{"status", ListOptions{Status: StatusRevoked}, []string{"urn:uuid:d", "urn:uuid:a"}},
+		{"issuer", ListOptions{IssuerDID: "did:key:issuer2"}, []string{"urn:uuid:c", "urn:uuid:a"}},
+		{"subject", ListOptions{SubjectDID: "did:key:subject1"}, []string{"urn:uuid:e", "urn:uuid:c"}},
+		{"issued after", ListOptions{IssuedAfter: base.Add(2 * time.Hour)}, []string{"urn:uuid:b", "urn:uuid:a"}},
+		{"issued before", ListOptions{IssuedBefore: base.Add(2 * time.Hour)}, []string{"urn:uuid:e", "urn:uuid:d"}},
+		{"combined", ListOptions{IssuerDID: "did:key:issuer1", Status: StatusActive}, []string{"urn:uuid:e"}},
+		{"no match", ListOptions{IssuerDID: "did:key:unknown"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := r.List(tt.opts)
+			if err != nil {
+				t.Fatalf("Failed to list: %v", err)
+			}
+			if len(entries) != len(tt.want) {
+				t.Fatalf("Expected %d entries, got %d", len(tt.want), len(entries))
+			}
+			for i, id := range tt.want {
+				if entries[i].CredentialID != id {
+					t.Errorf("Expected entry %d to be %s, got %s", i, id, entries[i].CredentialID)
+				}
+			}
+			if count := r.Count(tt.opts); count != len(tt.want) {
+				t.Errorf("Expected count %d, got %d", len(tt.want), count)
+			}
+		})
+	}
+}
+
+func TestRegistryListPagination(t *testing.T) {
+	r, _ := listTestRegistry(t)
+
+	page, err := r.List(ListOptions{Offset: 1, Limit: 2})
+	if err != nil {
+		t.Fatalf("Failed to list: %v", err)
+	}
+	if len(page) != 2 || page[0].CredentialID != "urn:uuid:d" || page[1].CredentialID != "urn:uuid:c" {
+		t.Errorf("Expected entries d and c, got %v", page)
+	}
+
+	page, _ = r.List(ListOptions{Offset: 4, Limit: 2})
+	if len(page) != 1 || page[0].CredentialID != "urn:uuid:a" {
+		t.Errorf("Expected a short last page with entry a, got %v", page)
+	}
+
+	page, err = r.List(ListOptions{Offset: 10})
+	if err != nil || page == nil || len(page) != 0 {
+		t.Errorf("Expected an empty page past the end, got %v, %v", page, err)
+	}
+
+	// Count ignores pagination
+	if count := r.Count(ListOptions{Offset: 1, Limit: 2}); count != 5 {
+		t.Errorf("Expected count 5, got %d", count)
+	}
+}
+
+func TestRegistryListSortsTiesByID(t *testing.T) {
+	r := NewRegistry()
+	issuedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, id := range []string{"urn:uuid:3", "urn:uuid:1", "urn:uuid:2"} {
+		r.Register(id, "did:key:issuer1", "did:key:subject1")
+		r.entries[id].IssuedAt = issuedAt
+	}
+
+	entries, _ := r.List(ListOptions{})
+	for i, want := range []string{"urn:uuid:1", "urn:uuid:2", "urn:uuid:3"} {
+		if entries[i].CredentialID != want {
+			t.Errorf("Expected entry %d to be %s, got %s", i, want, entries[i].CredentialID)
+		}
+	}
+}
+
+func TestRegistryListReturnsCopies(t *testing.T) {
+	r, _ := listTestRegistry(t)
+
+	entries, _ := r.List(ListOptions{Limit: 1})
+	entries[0].Status = StatusRevoked
+
+	entry, _ := r.CheckStatus(entries[0].CredentialID)
+	if entry.Status != StatusActive {
+		t.Errorf("Expected stored entry to stay active, got %s", entry.Status)
+	}
+}
+
+func TestRegistryListInvalidOptions(t *testing.T) {
+	r := NewRegistry()
+
+	for _, opts := range []ListOptions{{Offset: -1}, {Limit: -1}} {
+		if _, err := r.List(opts); err != ErrInvalidListOptions {
+			t.Errorf("Expected ErrInvalidListOptions, got %v", err)
+		}
+	}
+}
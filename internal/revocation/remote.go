@@ -0,0 +1,192 @@
+package revocation
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/httpclient"
+)
+
+var (
+	ErrRegistryUnavailable = errors.New("revocation registry unavailable")
+)
+
+const (
+	// DefaultRemoteCacheTTL is how long remote status results are cached
+	DefaultRemoteCacheTTL = 5 * time.Minute
+
+	// RemoteCacheSize is the number of status results a RemoteRegistry
+	// caches before evicting the least recently used
+	RemoteCacheSize = 1024
+)
+
+// maxStatusResponseSize bounds the status response read from the service
+const maxStatusResponseSize = 1 << 16
+
+// RemoteRegistry checks credential status against a central revocation
+// service over HTTP (GET {base}/status/{credentialID} returning an Entry).
+// Found and not-found results are cached for the configured TTL, up to
+// RemoteCacheSize of them. Transient failures (network errors, 429 and 5xx
+// responses) are retried with backoff.
+type RemoteRegistry struct {
+	baseURL string
+	client  *httpclient.Client
+	ttl     time.Duration
+
+	mu    sync.Mutex
+	order *list.List // front is most recently used
+	cache map[string]*list.Element
+	now   func() time.Time
+}
+
+// remoteResult is a cached lookup; a nil entry means the credential was not found
+type remoteResult struct {
+	credentialID string
+	entry        *Entry
+	expires      time.Time
+}
+
+// NewRemoteRegistry creates a remote registry client using http.DefaultClient
+func NewRemoteRegistry(baseURL string, ttl time.Duration) *RemoteRegistry {
+	return NewRemoteRegistryWithClient(baseURL, http.DefaultClient, ttl)
+}
+
+// NewRemoteRegistryWithClient creates a remote registry client with a custom
+// HTTP client and the default retry policy
+func NewRemoteRegistryWithClient(baseURL string, client *http.Client, ttl time.Duration) *RemoteRegistry {
+	return NewRemoteRegistryWithOptions(baseURL, ttl, httpclient.Options{Client: client})
+}
+
+// NewRemoteRegistryWithOptions creates a remote registry client with the
+// given retry, backoff and rate limit settings
+func NewRemoteRegistryWithOptions(baseURL string, ttl time.Duration, opts httpclient.Options) *RemoteRegistry {
+	return &RemoteRegistry{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  httpclient.NewClient(opts),
+		ttl:     ttl,
+		order:   list.New(),
+		cache:   make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+// CheckStatus returns the status of a credential from the remote service.
+// Network failures and unexpected responses that persist after retrying
+// return an error wrapping ErrRegistryUnavailable so callers can choose to
+// fail open or closed. Cancelling ctx also stops any pending retry. The
+// returned entry is the caller's own copy.
+func (r *RemoteRegistry) CheckStatus(ctx context.Context, credentialID string) (*Entry, error) {
+	if cached, ok := r.cached(credentialID); ok {
+		if cached == nil {
+			return nil, ErrCredentialNotFound
+		}
+		entry := *cached
+		return &entry, nil
+	}
+
+	entry, err := r.fetch(ctx, credentialID)
+	if err != nil && err != ErrCredentialNotFound {
+		return nil, err
+	}
+	r.put(credentialID, entry)
+
+	if entry == nil {
+		return nil, ErrCredentialNotFound
+	}
+	copied := *entry
+	return &copied, nil
+}
+
+// IsRevoked checks if a credential is revoked according to the remote service
+func (r *RemoteRegistry) IsRevoked(ctx context.Context, credentialID string) (bool, error) {
+	entry, err := r.CheckStatus(ctx, credentialID)
+	if err != nil {
+		return false, err
+	}
+	return entry.Status == StatusRevoked, nil
+}
+
+// cached returns an unexpired cached entry, nil if the credential was not
+// found, dropping the result once it has expired
+func (r *RemoteRegistry) cached(credentialID string) (*Entry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elem, ok := r.cache[credentialID]
+	if !ok {
+		return nil, false
+	}
+	result := elem.Value.(*remoteResult)
+	if !r.now().Before(result.expires) {
+		r.order.Remove(elem)
+		delete(r.cache, credentialID)
+		return nil, false
+	}
+	r.order.MoveToFront(elem)
+	return result.entry, true
+}
+
+// put caches a lookup, evicting the least recently used when full
+func (r *RemoteRegistry) put(credentialID string, entry *Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := &remoteResult{credentialID: credentialID, entry: entry, expires: r.now().Add(r.ttl)}
+	if elem, ok := r.cache[credentialID]; ok {
+		elem.Value = result
+		r.order.MoveToFront(elem)
+		return
+	}
+
+	r.cache[credentialID] = r.order.PushFront(result)
+	if r.order.Len() > RemoteCacheSize {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.cache, oldest.Value.(*remoteResult).credentialID)
+	}
+}
+
+// fetch performs the HTTP lookup
+func (r *RemoteRegistry) fetch(ctx context.Context, credentialID string) (*Entry, error) {
+	endpoint := r.baseURL + "/status/" + url.PathEscape(credentialID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRegistryUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrCredentialNotFound
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrRegistryUnavailable, resp.StatusCode)
+	}
+
+	var entry Entry
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxStatusResponseSize)).Decode(&entry); err != nil {
+		return nil, fmt.Errorf("%w: invalid response: %v", ErrRegistryUnavailable, err)
+	}
+	// A status for another credential says nothing about this one
+	if entry.CredentialID != credentialID {
+		return nil, fmt.Errorf("%w: response is for credential %q", ErrRegistryUnavailable, entry.CredentialID)
+	}
+
+	return &entry, nil
+}
@@ -0,0 +1,262 @@
+package revocation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/httpclient"
+)
+
+// newStatusServer serves entries from a local registry the way a revocation service would
+func newStatusServer(t *testing.T, registry *Registry, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(hits, 1)
+		id := strings.TrimPrefix(req.URL.Path, "/status/")
+		entry, err := registry.CheckStatus(id)
+		if err != nil {
+			http.NotFound(w, req)
+			return
+		}
+		json.NewEncoder(w).Encode(entry)
+	}))
+}
+
+func TestRemoteRegistryCheckStatus(t *testing.T) {
+	local := NewRegistry()
+	local.Register("urn:uuid:active", "did:key:issuer", "did:key:subject")
+	local.Register("urn:uuid:revoked", "did:key:issuer", "did:key:subject")
+	local.Revoke("urn:uuid:revoked", "test")
+
+	var hits int32
+	srv := newStatusServer(t, local, &hits)
+	defer srv.Close()
+
+	remote := NewRemoteRegistry(srv.URL, time.Minute)
+	ctx := context.Background()
+
+	entry, err := remote.CheckStatus(ctx, "urn:uuid:active")
+	if err != nil {
+		t.Fatalf("CheckStatus failed: %v", err)
+	}
+	if entry.Status != StatusActive {
+		t.Errorf("Expected status %s, got %s", StatusActive, entry.Status)
+	}
+
+	revoked, err := remote.IsRevoked(ctx, "urn:uuid:revoked")
+	if err != nil {
+		t.Fatalf("IsRevoked failed: %v", err)
+	}
+	if !revoked {
+		t.Error("Expected credential to be revoked")
+	}
+
+	if _, err := remote.CheckStatus(ctx, "urn:uuid:missing"); err != ErrCredentialNotFound {
+		t.Errorf("Expected ErrCredentialNotFound, got %v", err)
+	}
+}
+
+func TestRemoteRegistryCachesWithTTL(t *testing.T) {
+	local := NewRegistry()
+	local.Register("urn:uuid:cached", "did:key:issuer", "did:key:subject")
+
+	var hits int32
+	srv := newStatusServer(t, local, &hits)
+	defer srv.Close()
+
+	remote := NewRemoteRegistry(srv.URL, time.Minute)
+	now := time.Now()
+	remote.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	remote.CheckStatus(ctx, "urn:uuid:cached")
+	remote.CheckStatus(ctx, "urn:uuid:cached")
+	remote.CheckStatus(ctx, "urn:uuid:missing")
+	remote.CheckStatus(ctx, "urn:uuid:missing")
+
+	if hits != 2 {
+		t.Errorf("Expected positive and negative results to be cached (2 hits), got %d", hits)
+	}
+
+	// After the TTL the service is asked again and sees the revocation
+	local.Revoke("urn:uuid:cached", "test")
+	now = now.Add(2 * time.Minute)
+
+	revoked, _ := remote.IsRevoked(ctx, "urn:uuid:cached")
+	if !revoked {
+		t.Error("Expected fresh lookup after TTL to report revocation")
+	}
+	if hits != 3 {
+		t.Errorf("Expected 3 hits after TTL expiry, got %d", hits)
+	}
+}
+
+func TestRemoteRegistryUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+
+	remote := NewRemoteRegistry(srv.URL, time.Minute)
+	if _, err := remote.CheckStatus(context.Background(), "urn:uuid:any"); !errors.Is(err, ErrRegistryUnavailable) {
+		t.Errorf("Expected ErrRegistryUnavailable for server error, got %v", err)
+	}
+
+	// Errors are not cached, and a closed server is a network failure
+	srv.Close()
+	if _, err := remote.IsRevoked(context.Background(), "urn:uuid:any"); !errors.Is(err, ErrRegistryUnavailable) {
+		t.Errorf("Expected ErrRegistryUnavailable for network failure, got %v", err)
+	}
+}
+
+func TestRemoteRegistryRetriesTooManyRequests(t *testing.T) {
+	local := NewRegistry()
+	local.Register("urn:uuid:busy", "did:key:issuer", "did:key:subject")
+
+	var hits int32
+	status := newStatusServer(t, local, new(int32))
+	defer status.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		status.Config.Handler.ServeHTTP(w, req)
+	}))
+	defer srv.Close()
+
+	remote := NewRemoteRegistryWithOptions(srv.URL, time.Minute, httpclient.Options{BaseDelay: time.Millisecond})
+	entry, err := remote.CheckStatus(context.Background(), "urn:uuid:busy")
+	if err != nil {
+		t.Fatalf("CheckStatus failed: %v", err)
+	}
+	if entry.Status != StatusActive {
+		t.Errorf("Expected status %s, got %s", StatusActive, entry.Status)
+	}
+	if hits != 2 {
+		t.Errorf("Expected the 429 to be retried (2 requests), got %d", hits)
+	}
+}
+
+func TestRemoteRegistryContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	remote := NewRemoteRegistry(srv.URL, time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := remote.CheckStatus(ctx, "urn:uuid:slow")
+	if !errors.Is(err, ErrRegistryUnavailable) {
+		t.Errorf("Expected ErrRegistryUnavailable, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected error to wrap context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestRemoteRegistryBoundsCache(t *testing.T) {
+	local := NewRegistry()
+	local.Register("urn:uuid:kept", "did:key:issuer", "did:key:subject")
+
+	var hits int32
+	srv := newStatusServer(t, local, &hits)
+	defer srv.Close()
+
+	remote := NewRemoteRegistry(srv.URL, time.Minute)
+	now := time.Now()
+	remote.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	remote.CheckStatus(ctx, "urn:uuid:kept")
+	for i := 0; i < RemoteCacheSize; i++ {
+		remote.CheckStatus(ctx, fmt.Sprintf("urn:uuid:missing-%d", i))
+		if i == RemoteCacheSize/2 {
+			// Recently used results survive eviction
+			remote.CheckStatus(ctx, "urn:uuid:kept")
+		}
+	}
+	if len(remote.cache) != RemoteCacheSize || remote.order.Len() != RemoteCacheSize {
+		t.Errorf("Expected %d cached results, got %d", RemoteCacheSize, len(remote.cache))
+	}
+	if _, ok := remote.cache["urn:uuid:missing-0"]; ok {
+		t.Error("Expected the least recently used result to be evicted")
+	}
+	if _, ok := remote.cache["urn:uuid:kept"]; !ok {
+		t.Error("Expected the recently used result to stay cached")
+	}
+
+	// Expired results are dropped when looked up
+	now = now.Add(2 * time.Minute)
+	if _, ok := remote.cached("urn:uuid:kept"); ok {
+		t.Error("Expected the expired result to miss")
+	}
+	if _, ok := remote.cache["urn:uuid:kept"]; ok {
+		t.Error("Expected the expired result to be dropped")
+	}
+}
+
+func TestRemoteRegistryReturnsCopies(t *testing.T) {
+	local := NewRegistry()
+	local.Register("urn:uuid:shared", "did:key:issuer", "did:key:subject")
+
+	var hits int32
+	srv := newStatusServer(t, local, &hits)
+	defer srv.Close()
+
+	remote := NewRemoteRegistry(srv.URL, time.Minute)
+	first, err := remote.CheckStatus(context.Background(), "urn:uuid:shared")
+	if err != nil {
+		t.Fatalf("CheckStatus failed: %v", err)
+	}
+	first.Status = StatusRevoked
+
+	second, _ := remote.CheckStatus(context.Background(), "urn:uuid:shared")
+	if second.Status != StatusActive {
+		t.Errorf("Expected one caller's change not to reach the cache, got %s", second.Status)
+	}
+	if hits != 1 {
+		t.Errorf("Expected the second lookup to be cached, got %d requests", hits)
+	}
+}
+
+func TestRemoteRegistryRejectsBadResponses(t *testing.T) {
+	local := NewRegistry()
+	local.Register("urn:uuid:other", "did:key:issuer", "did:key:subject")
+	other, _ := local.CheckStatus("urn:uuid:other")
+
+	tests := []struct {
+		name string
+		body []byte
+	}{
+		{"entry for another credential", func() []byte { b, _ := json.Marshal(other); return b }()},
+		{"oversized response", []byte(`{"credentialId":"urn:uuid:asked","reason":"` + strings.Repeat("x", maxStatusResponseSize) + `"}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Write(tt.body)
+			}))
+			defer srv.Close()
+
+			remote := NewRemoteRegistry(srv.URL, time.Minute)
+			if _, err := remote.CheckStatus(context.Background(), "urn:uuid:asked"); !errors.Is(err, ErrRegistryUnavailable) {
+				t.Errorf("Expected ErrRegistryUnavailable, got %v", err)
+			}
+			if len(remote.cache) != 0 {
+				t.Errorf("Expected a bad response not to be cached, got %d results", len(remote.cache))
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package revocation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+)
+
+func newIssuer(t *testing.T) (string, ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	didKey, err := did.CreateDIDKey(pub)
+	if err != nil {
+		t.Fatalf("CreateDIDKey failed: %v", err)
+	}
+	return didKey.DID, pub, priv
+}
+
+func TestRevokeSigned(t *testing.T) {
+	issuerDID, issuerPub, issuerPriv := newIssuer(t)
+	_, otherPub, otherPriv := newIssuer(t)
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		reason   string
+		signedAt time.Time
+		sig      func(signedAt time.Time) []byte
+		pub      ed25519.PublicKey
+		want     error
+	}{
+		{
+			name:     "other issuer's key",
+			signedAt: now,
+			sig: func(at time.Time) []byte {
+				return SignRevocation("urn:uuid:1", "compromised", at, otherPriv)
+			},
+			pub:  otherPub,
+			want: ErrUnauthorizedRevoker,
+		},
+		{
+			name:     "signature from a different key",
+			signedAt: now,
+			sig: func(at time.Time) []byte {
+				return SignRevocation("urn:uuid:1", "compromised", at, otherPriv)
+			},
+			pub:  issuerPub,
+			want: ErrUnauthorizedRevoker,
+		},
+		{
+			name:     "altered reason",
+			reason:   "issued in error",
+			signedAt: now,
+			sig: func(at time.Time) []byte {
+				return SignRevocation("urn:uuid:1", "compromised", at, issuerPriv)
+			},
+			pub:  issuerPub,
+			want: ErrUnauthorizedRevoker,
+		},
+		{
+			name:     "stale",
+			signedAt: now.Add(-SignedRevocationMaxAge - time.Minute),
+			sig: func(at time.Time) []byte {
+				return SignRevocation("urn:uuid:1", "compromised", at, issuerPriv)
+			},
+			pub:  issuerPub,
+			want: ErrStaleRevocation,
+		},
+		{
+			name:     "future",
+			signedAt: now.Add(time.Hour),
+			sig: func(at time.Time) []byte {
+				return SignRevocation("urn:uuid:1", "compromised", at, issuerPriv)
+			},
+			pub:  issuerPub,
+			want: ErrStaleRevocation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			r.Register("urn:uuid:1", issuerDID, "did:key:subject")
+
+			reason := tt.reason
+			if reason == "" {
+				reason = "compromised"
+			}
+			err := r.RevokeSigned("urn:uuid:1", reason, tt.signedAt, tt.sig(tt.signedAt), tt.pub)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+			if revoked, _ := r.IsRevoked("urn:uuid:1"); revoked {
+				t.Error("Expected the credential to stay active")
+			}
+		})
+	}
+
+	t.Run("issuer", func(t *testing.T) {
+		r := NewRegistry()
+		r.Register("urn:uuid:1", issuerDID, "did:key:subject")
+
+		// The timestamp travels as Unix seconds, so a truncated copy verifies
+		signedAt := now.Truncate(time.Second)
+		sig := SignRevocation("urn:uuid:1", "compromised", now, issuerPriv)
+		if err := r.RevokeSigned("urn:uuid:1", "compromised", signedAt, sig, issuerPub); err != nil {
+			t.Fatalf("RevokeSigned failed: %v", err)
+		}
+		entry, _ := r.CheckStatus("urn:uuid:1")
+		if entry.Status != StatusRevoked || entry.Reason != "compromised" {
+			t.Errorf("Expected revoked with reason compromised, got %s %q", entry.Status, entry.Reason)
+		}
+
+		// Replaying the request changes nothing
+		if err := r.RevokeSigned("urn:uuid:1", "compromised", signedAt, sig, issuerPub); err != ErrAlreadyRevoked {
+			t.Errorf("Expected ErrAlreadyRevoked on replay, got %v", err)
+		}
+	})
+
+	t.Run("unknown credential", func(t *testing.T) {
+		sig := SignRevocation("urn:uuid:missing", "compromised", now, issuerPriv)
+		if err := NewRegistry().RevokeSigned("urn:uuid:missing", "compromised", now, sig, issuerPub); err != ErrCredentialNotFound {
+			t.Errorf("Expected ErrCredentialNotFound, got %v", err)
+		}
+	})
+}
+
+func TestShardedRegistryRevokeSigned(t *testing.T) {
+	issuerDID, issuerPub, issuerPriv := newIssuer(t)
+	otherDID, _, _ := newIssuer(t)
+
+	s, err := NewShardedRegistry(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewShardedRegistry failed: %v", err)
+	}
+	s.Register("urn:uuid:a", issuerDID, "did:key:subject")
+	s.Register("urn:uuid:b", otherDID, "did:key:subject")
+
+	// One issuer cannot revoke another's credential in a shared store
+	now := time.Now()
+	sig := SignRevocation("urn:uuid:b", "", now, issuerPriv)
+	if err := s.RevokeSigned("urn:uuid:b", "", now, sig, issuerPub); err != ErrUnauthorizedRevoker {
+		t.Errorf("Expected ErrUnauthorizedRevoker, got %v", err)
+	}
+
+	sig = SignRevocation("urn:uuid:a", "", now, issuerPriv)
+	if err := s.RevokeSigned("urn:uuid:a", "", now, sig, issuerPub); err != nil {
+		t.Fatalf("RevokeSigned failed: %v", err)
+	}
+	if revoked, _ := s.IsRevoked("urn:uuid:a"); !revoked {
+		t.Error("Expected urn:uuid:a to be revoked")
+	}
+}
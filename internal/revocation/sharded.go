@@ -0,0 +1,179 @@
+package revocation
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ShardedRegistry keeps each issuer's entries in a separate file,
+// <dir>/<sha256(issuerDID)>.json, so issuers' data never share a file.
+// Lookups by credential ID search every shard.
+type ShardedRegistry struct {
+	dir    string
+	mu     sync.RWMutex
+	shards map[string]*Registry // keyed by shard name
+}
+
+// NewShardedRegistry opens (or creates) a sharded registry in dir and loads
+// any existing shards
+func NewShardedRegistry(dir string) (*ShardedRegistry, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	s := &ShardedRegistry{
+		dir:    dir,
+		shards: make(map[string]*Registry),
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		shard, err := NewRegistryWithFile(path)
+		if err != nil {
+			return nil, err
+		}
+		s.shards[strings.TrimSuffix(filepath.Base(path), ".json")] = shard
+	}
+
+	return s, nil
+}
+
+// ShardName returns the shard file name (without extension) for an issuer
+func ShardName(issuerDID string) string {
+	sum := sha256.Sum256([]byte(issuerDID))
+	return hex.EncodeToString(sum[:])
+}
+
+// ForIssuer returns the shard holding an issuer's entries, creating it if needed
+func (s *ShardedRegistry) ForIssuer(issuerDID string) (*Registry, error) {
+	name := ShardName(issuerDID)
+
+	s.mu.RLock()
+	shard, ok := s.shards[name]
+	s.mu.RUnlock()
+	if ok {
+		return shard, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if shard, ok := s.shards[name]; ok {
+		return shard, nil
+	}
+	shard, err := NewRegistryWithFile(filepath.Join(s.dir, name+".json"))
+	if err != nil {
+		return nil, err
+	}
+	s.shards[name] = shard
+	return shard, nil
+}
+
+// Register adds a credential to its issuer's shard
+func (s *ShardedRegistry) Register(credentialID, issuerDID, subjectDID string) error {
+	shard, err := s.ForIssuer(issuerDID)
+	if err != nil {
+		return err
+	}
+	return shard.Register(credentialID, issuerDID, subjectDID)
+}
+
+// Revoke marks a credential as revoked in whichever shard holds it
+func (s *ShardedRegistry) Revoke(credentialID, reason string) error {
+	shard, err := s.find(credentialID)
+	if err != nil {
+		return err
+	}
+	return shard.Revoke(credentialID, reason)
+}
+
+// RevokeSigned revokes a credential on its issuer's signature in whichever
+// shard holds it; see Registry.RevokeSigned
+func (s *ShardedRegistry) RevokeSigned(credentialID, reason string, signedAt time.Time, issuerSig []byte, issuerPub ed25519.PublicKey) error {
+	shard, err := s.find(credentialID)
+	if err != nil {
+		return err
+	}
+	return shard.RevokeSigned(credentialID, reason, signedAt, issuerSig, issuerPub)
+}
+
+// Suspend places a credential on hold in whichever shard holds it
+func (s *ShardedRegistry) Suspend(credentialID, reason string) error {
+	shard, err := s.find(credentialID)
+	if err != nil {
+		return err
+	}
+	return shard.Suspend(credentialID, reason)
+}
+
+// Reactivate lifts a suspension in whichever shard holds the credential
+func (s *ShardedRegistry) Reactivate(credentialID string) error {
+	shard, err := s.find(credentialID)
+	if err != nil {
+		return err
+	}
+	return shard.Reactivate(credentialID)
+}
+
+// CheckStatus returns the entry for a credential from any shard
+func (s *ShardedRegistry) CheckStatus(credentialID string) (*Entry, error) {
+	shard, err := s.find(credentialID)
+	if err != nil {
+		return nil, err
+	}
+	return shard.CheckStatus(credentialID)
+}
+
+// IsRevoked checks if a credential is revoked in any shard
+func (s *ShardedRegistry) IsRevoked(credentialID string) (bool, error) {
+	shard, err := s.find(credentialID)
+	if err != nil {
+		return false, err
+	}
+	return shard.IsRevoked(credentialID)
+}
+
+// ListByIssuer returns all entries for an issuer from its shard
+func (s *ShardedRegistry) ListByIssuer(issuerDID string) []*Entry {
+	s.mu.RLock()
+	shard, ok := s.shards[ShardName(issuerDID)]
+	s.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return shard.ListByIssuer(issuerDID)
+}
+
+// ListBySubject returns a subject's entries across all shards
+func (s *ShardedRegistry) ListBySubject(subjectDID string) []*Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*Entry
+	for _, shard := range s.shards {
+		result = append(result, shard.ListBySubject(subjectDID)...)
+	}
+	return result
+}
+
+// find returns the shard holding a credential
+func (s *ShardedRegistry) find(credentialID string) (*Registry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, shard := range s.shards {
+		if _, err := shard.CheckStatus(credentialID); err == nil {
+			return shard, nil
+		}
+	}
+	return nil, ErrCredentialNotFound
+}
@@ -0,0 +1,75 @@
+package revocation
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestShardedRegistryRoutesByIssuer(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := NewShardedRegistry(dir)
+	if err != nil {
+		t.Fatalf("NewShardedRegistry failed: %v", err)
+	}
+
+	s.Register("urn:uuid:a1", "did:key:issuerA", "did:key:alice")
+	s.Register("urn:uuid:a2", "did:key:issuerA", "did:key:bob")
+	s.Register("urn:uuid:b1", "did:key:issuerB", "did:key:alice")
+
+	shardA, err := NewRegistryWithFile(filepath.Join(dir, ShardName("did:key:issuerA")+".json"))
+	if err != nil {
+		t.Fatalf("Failed to read issuer A shard: %v", err)
+	}
+	if got := len(shardA.ListByIssuer("did:key:issuerA")); got != 2 {
+		t.Errorf("Expected 2 entries in issuer A shard, got %d", got)
+	}
+	if _, err := shardA.CheckStatus("urn:uuid:b1"); err != ErrCredentialNotFound {
+		t.Error("Issuer B's credential must not be stored in issuer A's shard")
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 2 {
+		t.Errorf("Expected 2 shard files, got %d", len(files))
+	}
+}
+
+func TestShardedRegistryCrossShardQueries(t *testing.T) {
+	dir := t.TempDir()
+
+	s, _ := NewShardedRegistry(dir)
+	s.Register("urn:uuid:a1", "did:key:issuerA", "did:key:alice")
+	s.Register("urn:uuid:b1", "did:key:issuerB", "did:key:alice")
+
+	if err := s.Revoke("urn:uuid:b1", "test"); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if err := s.Revoke("urn:uuid:missing", "test"); err != ErrCredentialNotFound {
+		t.Errorf("Expected ErrCredentialNotFound, got %v", err)
+	}
+
+	// Reopen to confirm shards are loaded from disk
+	reopened, err := NewShardedRegistry(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen sharded registry: %v", err)
+	}
+
+	revoked, err := reopened.IsRevoked("urn:uuid:b1")
+	if err != nil || !revoked {
+		t.Errorf("Expected urn:uuid:b1 revoked, got %v (err %v)", revoked, err)
+	}
+	entry, err := reopened.CheckStatus("urn:uuid:a1")
+	if err != nil || entry.Status != StatusActive {
+		t.Errorf("Expected urn:uuid:a1 active, got %v (err %v)", entry, err)
+	}
+
+	if got := len(reopened.ListBySubject("did:key:alice")); got != 2 {
+		t.Errorf("Expected 2 entries for alice across shards, got %d", got)
+	}
+	if got := len(reopened.ListByIssuer("did:key:issuerB")); got != 1 {
+		t.Errorf("Expected 1 entry for issuer B, got %d", got)
+	}
+	if got := reopened.ListByIssuer("did:key:unknown"); len(got) != 0 {
+		t.Errorf("Expected no entries for unknown issuer, got %d", len(got))
+	}
+}
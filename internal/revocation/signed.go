@@ -0,0 +1,165 @@
+package revocation
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+)
+
+var (
+	ErrInvalidSignedRegistry     = errors.New("invalid signed registry")
+	ErrRegistrySignatureInvalid  = errors.New("registry signature is invalid")
+	ErrUnsupportedRegistryFormat = errors.New("unsupported signed registry version")
+	ErrSignedRegistryStale       = errors.New("signed registry is older than the accepted maximum age")
+	ErrRegistryIssuerMismatch    = errors.New("signed registry holds an entry of another issuer")
+)
+
+const (
+	// SignedRegistryFormat identifies a signed registry envelope
+	SignedRegistryFormat = "veriglob-signed-registry"
+
+	// DefaultSignedRegistryMaxAge is how old a signed registry may be when
+	// the verifier sets no policy
+	DefaultSignedRegistryMaxAge = 24 * time.Hour
+
+	// signedRegistryVersion is the current envelope format
+	signedRegistryVersion = 1
+)
+
+// SignedRegistryOptions configures LoadSignedRegistry
+type SignedRegistryOptions struct {
+	// IssuerDID is the DID every entry must be issued by; empty uses the
+	// did:key of the public key the registry is checked against
+	IssuerDID string
+	// MaxAge rejects a registry signed longer ago than this; zero uses
+	// DefaultSignedRegistryMaxAge
+	MaxAge time.Duration
+	// NotBefore rejects a registry signed before this time. Pass the
+	// SignedAt of the last registry loaded, so a mirror cannot roll back to
+	// an older snapshot taken before a revocation.
+	NotBefore time.Time
+	// Now is the time the registry's age is measured at; zero uses time.Now
+	Now time.Time
+}
+
+// signedRegistry is the envelope produced by ExportSigned. The signature
+// covers the payload bytes exactly as stored, so verification does not
+// depend on how the entries would be re-encoded.
+type signedRegistry struct {
+	Format    string `json:"format"`
+	Version   int    `json:"version"`
+	Payload   []byte `json:"payload"`
+	Signature []byte `json:"signature"`
+}
+
+// signedRegistryPayload is the signed content of the envelope
+type signedRegistryPayload struct {
+	SignedAt time.Time         `json:"signedAt"`
+	Entries  map[string]*Entry `json:"entries"`
+}
+
+// signingInput prefixes the payload with the format, so a registry signature
+// cannot be replayed as a signature over some other kind of message
+func (s *signedRegistry) signingInput() []byte {
+	return append([]byte(SignedRegistryFormat+"\n"), s.Payload...)
+}
+
+// ExportSigned returns the registry entries in an envelope signed with the
+// issuer's key. Verifiers load it with LoadSignedRegistry, so the registry can
+// be served from an untrusted mirror.
+func (r *Registry) ExportSigned(privateKey ed25519.PrivateKey) ([]byte, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, ErrInvalidSignedRegistry
+	}
+
+	r.mu.RLock()
+	payload, err := json.Marshal(signedRegistryPayload{
+		SignedAt: time.Now().UTC(),
+		Entries:  r.entries,
+	})
+	r.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := signedRegistry{
+		Format:  SignedRegistryFormat,
+		Version: signedRegistryVersion,
+		Payload: payload,
+	}
+	envelope.Signature = ed25519.Sign(privateKey, envelope.signingInput())
+
+	return json.MarshalIndent(envelope, "", "  ")
+}
+
+// LoadSignedRegistry verifies an envelope produced by ExportSigned against
+// the issuer's public key and returns its entries as an in-memory registry,
+// along with when it was signed. Nothing in data is trusted until the
+// signature checks out; a modified envelope returns
+// ErrRegistrySignatureInvalid. A registry signed more than opts.MaxAge ago,
+// or before opts.NotBefore, returns ErrSignedRegistryStale, since an old
+// snapshot may predate a revocation. An entry issued by anyone but
+// opts.IssuerDID returns ErrRegistryIssuerMismatch: the key only vouches for
+// its own issuer's credentials.
+func LoadSignedRegistry(data []byte, issuerPublicKey ed25519.PublicKey, opts SignedRegistryOptions) (*Registry, time.Time, error) {
+	var envelope signedRegistry
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.Format != SignedRegistryFormat {
+		return nil, time.Time{}, ErrInvalidSignedRegistry
+	}
+	if envelope.Version != signedRegistryVersion {
+		return nil, time.Time{}, ErrUnsupportedRegistryFormat
+	}
+	if len(issuerPublicKey) != ed25519.PublicKeySize {
+		return nil, time.Time{}, ErrRegistrySignatureInvalid
+	}
+
+	if !ed25519.Verify(issuerPublicKey, envelope.signingInput(), envelope.Signature) {
+		return nil, time.Time{}, ErrRegistrySignatureInvalid
+	}
+
+	var payload signedRegistryPayload
+	if err := json.Unmarshal(envelope.Payload, &payload); err != nil || payload.SignedAt.IsZero() {
+		return nil, time.Time{}, ErrInvalidSignedRegistry
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	maxAge := opts.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultSignedRegistryMaxAge
+	}
+	// Signing times come from the issuer's clock, like status proofs
+	if payload.SignedAt.After(now.Add(statusProofClockSkew)) {
+		return nil, time.Time{}, ErrInvalidSignedRegistry
+	}
+	if now.Sub(payload.SignedAt) > maxAge || payload.SignedAt.Before(opts.NotBefore) {
+		return nil, time.Time{}, ErrSignedRegistryStale
+	}
+
+	issuerDID := opts.IssuerDID
+	if issuerDID == "" {
+		key, err := did.CreateDIDKey(issuerPublicKey)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		issuerDID = key.DID
+	}
+
+	r := NewRegistry()
+	for id, entry := range payload.Entries {
+		if entry == nil {
+			continue
+		}
+		if entry.IssuerDID != issuerDID {
+			return nil, time.Time{}, fmt.Errorf("%w: %s is issued by %s", ErrRegistryIssuerMismatch, id, entry.IssuerDID)
+		}
+		r.entries[id] = entry
+	}
+	return r, payload.SignedAt, nil
+}
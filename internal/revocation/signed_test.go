@@ -0,0 +1,180 @@
+package revocation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+)
+
+// signedTestIssuer returns an issuer key pair and its did:key
+func signedTestIssuer(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey, string) {
+	t.Helper()
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	key, err := did.CreateDIDKey(pub)
+	if err != nil {
+		t.Fatalf("Failed to create did:key: %v", err)
+	}
+	return pub, priv, key.DID
+}
+
+func TestSignedRegistryRoundTrip(t *testing.T) {
+	issuerPub, issuerPriv, issuerDID := signedTestIssuer(t)
+
+	r := NewRegistry()
+	r.Register("urn:uuid:active", issuerDID, "did:key:subject")
+	r.Register("urn:uuid:revoked", issuerDID, "did:key:subject")
+	r.Revoke("urn:uuid:revoked", "compromised")
+
+	before := time.Now()
+	data, err := r.ExportSigned(issuerPriv)
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	loaded, signedAt, err := LoadSignedRegistry(data, issuerPub, SignedRegistryOptions{})
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if signedAt.Before(before.Add(-time.Second)) || signedAt.After(time.Now()) {
+		t.Errorf("Expected signedAt around the export, got %v", signedAt)
+	}
+
+	revoked, err := loaded.IsRevoked("urn:uuid:revoked")
+	if err != nil || !revoked {
+		t.Errorf("Expected urn:uuid:revoked to be revoked, got %v, %v", revoked, err)
+	}
+	entry, err := loaded.CheckStatus("urn:uuid:active")
+	if err != nil || entry.Status != StatusActive {
+		t.Errorf("Expected urn:uuid:active to be active, got %v", err)
+	}
+}
+
+func TestLoadSignedRegistryWrongKey(t *testing.T) {
+	_, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	r := NewRegistry()
+	r.Register("urn:uuid:1", "did:key:issuer", "did:key:subject")
+	data, _ := r.ExportSigned(issuerPriv)
+
+	if _, _, err := LoadSignedRegistry(data, otherPub, SignedRegistryOptions{}); err != ErrRegistrySignatureInvalid {
+		t.Errorf("Expected ErrRegistrySignatureInvalid, got %v", err)
+	}
+}
+
+func TestLoadSignedRegistryTampered(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	r := NewRegistry()
+	r.Register("urn:uuid:1", "did:key:issuer", "did:key:subject")
+	r.Revoke("urn:uuid:1", "compromised")
+	data, _ := r.ExportSigned(issuerPriv)
+
+	// A mirror un-revokes the credential
+	var envelope signedRegistry
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		t.Fatalf("Failed to parse envelope: %v", err)
+	}
+	var payload signedRegistryPayload
+	json.Unmarshal(envelope.Payload, &payload)
+	payload.Entries["urn:uuid:1"].Status = StatusActive
+	envelope.Payload, _ = json.Marshal(payload)
+	tampered, _ := json.Marshal(envelope)
+
+	if _, _, err := LoadSignedRegistry(tampered, issuerPub, SignedRegistryOptions{}); err != ErrRegistrySignatureInvalid {
+		t.Errorf("Expected ErrRegistrySignatureInvalid, got %v", err)
+	}
+}
+
+func TestLoadSignedRegistryInvalid(t *testing.T) {
+	issuerPub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name string
+		data string
+		want error
+	}{
+		{"not json", "not a registry", ErrInvalidSignedRegistry},
+		{"unsigned registry", `{"urn:uuid:1":{"credentialId":"urn:uuid:1","status":"active"}}`, ErrInvalidSignedRegistry},
+		{"future version", `{"format":"veriglob-signed-registry","version":99}`, ErrUnsupportedRegistryFormat},
+		{"missing signature", `{"format":"veriglob-signed-registry","version":1,"payload":"e30="}`, ErrRegistrySignatureInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := LoadSignedRegistry([]byte(tt.data), issuerPub, SignedRegistryOptions{}); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadSignedRegistryAge(t *testing.T) {
+	issuerPub, issuerPriv, issuerDID := signedTestIssuer(t)
+
+	r := NewRegistry()
+	r.Register("urn:uuid:1", issuerDID, "did:key:subject")
+	data, _ := r.ExportSigned(issuerPriv)
+	_, signedAt, err := LoadSignedRegistry(data, issuerPub, SignedRegistryOptions{})
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts SignedRegistryOptions
+		want error
+	}{
+		{"within default max age", SignedRegistryOptions{Now: signedAt.Add(time.Hour)}, nil},
+		{"older than default max age", SignedRegistryOptions{Now: signedAt.Add(DefaultSignedRegistryMaxAge + time.Second)}, ErrSignedRegistryStale},
+		{"older than max age", SignedRegistryOptions{MaxAge: time.Minute, Now: signedAt.Add(2 * time.Minute)}, ErrSignedRegistryStale},
+		{"rolled back", SignedRegistryOptions{NotBefore: signedAt.Add(time.Second)}, ErrSignedRegistryStale},
+		{"same snapshot again", SignedRegistryOptions{NotBefore: signedAt}, nil},
+		{"signed in the future", SignedRegistryOptions{Now: signedAt.Add(-time.Hour)}, ErrInvalidSignedRegistry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := LoadSignedRegistry(data, issuerPub, tt.opts); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadSignedRegistryIssuerMismatch(t *testing.T) {
+	issuerPub, issuerPriv, issuerDID := signedTestIssuer(t)
+
+	// The issuer's key signs an entry claiming another issuer's credential
+	r := NewRegistry()
+	r.Register("urn:uuid:own", issuerDID, "did:key:subject")
+	r.Register("urn:uuid:other", "did:key:zOtherIssuer", "did:key:subject")
+	r.Revoke("urn:uuid:other", "forged")
+	data, _ := r.ExportSigned(issuerPriv)
+
+	if _, _, err := LoadSignedRegistry(data, issuerPub, SignedRegistryOptions{}); !errors.Is(err, ErrRegistryIssuerMismatch) {
+		t.Errorf("Expected ErrRegistryIssuerMismatch, got %v", err)
+	}
+
+	// An explicit IssuerDID, e.g. a did:web whose key signed the registry
+	r = NewRegistry()
+	r.Register("urn:uuid:1", "did:web:issuer.example", "did:key:subject")
+	data, _ = r.ExportSigned(issuerPriv)
+	if _, _, err := LoadSignedRegistry(data, issuerPub, SignedRegistryOptions{IssuerDID: "did:web:issuer.example"}); err != nil {
+		t.Errorf("Expected the did:web registry to load, got %v", err)
+	}
+	if _, _, err := LoadSignedRegistry(data, issuerPub, SignedRegistryOptions{}); !errors.Is(err, ErrRegistryIssuerMismatch) {
+		t.Errorf("Expected ErrRegistryIssuerMismatch without IssuerDID, got %v", err)
+	}
+}
+
+func TestExportSignedInvalidKey(t *testing.T) {
+	if _, err := NewRegistry().ExportSigned(nil); err != ErrInvalidSignedRegistry {
+		t.Errorf("Expected ErrInvalidSignedRegistry, got %v", err)
+	}
+}
@@ -0,0 +1,310 @@
+package revocation
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/veriglob/veriglob-core/internal/resolver"
+	"github.com/veriglob/veriglob-core/internal/vc"
+)
+
+var (
+	ErrStatusListFull          = errors.New("status list has no free indexes")
+	ErrStatusIndexRange        = errors.New("status list index out of range")
+	ErrInvalidStatusList       = errors.New("invalid status list")
+	ErrNotStatusListCredential = errors.New("credential is not a status list credential")
+	ErrNoStatusListEntry       = errors.New("credential has no status list entry")
+	ErrStatusListTooLarge      = errors.New("status list too large")
+	ErrStatusListMismatch      = errors.New("credential status does not point at this status list")
+	ErrListIssuerMismatch      = errors.New("status list not issued by the credential's issuer")
+)
+
+const (
+	// CredentialTypeStatusList is the credential type of a published status list
+	CredentialTypeStatusList = "StatusList2021Credential"
+
+	// StatusPurposeRevocation is the only status purpose lists are checked for
+	StatusPurposeRevocation = "revocation"
+
+	// DefaultStatusListSize is the W3C recommended minimum (16KB) which gives
+	// holders herd privacy
+	DefaultStatusListSize = 131072
+
+	// MaxStatusListSize is the largest list, in indexes, DecodeStatusList
+	// accepts, so a small published list cannot decompress without bound
+	MaxStatusListSize = 1 << 23
+)
+
+// StatusList is a StatusList2021 bitstring where bit N is 1 if the
+// credential allocated index N is revoked. Bit 0 is the most significant
+// bit of the first byte.
+type StatusList struct {
+	mu   sync.Mutex
+	bits []byte
+	next int
+}
+
+// PublishedStatusList is the list carried by a verified status list
+// credential, with the URL credentials point at it by
+type PublishedStatusList struct {
+	// URL is the list credential's id, which a credential's entry names as
+	// its statusListCredential
+	URL string
+	// Issuer is the DID that issued the list
+	Issuer  string
+	Purpose string
+	List    *StatusList
+}
+
+// StatusListOptions configures VerifyStatusListCredential
+type StatusListOptions struct {
+	// IssuerDID is the DID the list must be issued by: the issuer of the
+	// credentials it is checked for. Required.
+	IssuerDID string
+	// Resolver resolves IssuerDID to the key the list must be signed with;
+	// nil uses the default did:key resolver
+	Resolver *resolver.Resolver
+}
+
+// StatusListSubject is the credentialSubject of a status list credential
+type StatusListSubject struct {
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	StatusPurpose string `json:"statusPurpose"`
+	EncodedList   string `json:"encodedList"`
+}
+
+func (s StatusListSubject) GetID() string          { return s.ID }
+func (s StatusListSubject) CredentialType() string { return CredentialTypeStatusList }
+
+// NewStatusList creates an empty status list with room for size credentials
+func NewStatusList(size int) *StatusList {
+	return &StatusList{
+		bits: make([]byte, (size+7)/8),
+	}
+}
+
+// Size returns the number of indexes in the list
+func (l *StatusList) Size() int {
+	return len(l.bits) * 8
+}
+
+// AllocateStatusIndex reserves the next free index for a newly issued credential
+func (l *StatusList) AllocateStatusIndex() (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.next >= len(l.bits)*8 {
+		return 0, ErrStatusListFull
+	}
+	index := l.next
+	l.next++
+	return index, nil
+}
+
+// Next returns the index AllocateStatusIndex hands out next. An issuer
+// persists it with Encode's output and passes both to RestoreStatusList on
+// restart, so no index is allocated twice.
+func (l *StatusList) Next() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.next
+}
+
+// SetRevoked flips the bit for index
+func (l *StatusList) SetRevoked(index int, revoked bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if index < 0 || index >= len(l.bits)*8 {
+		return ErrStatusIndexRange
+	}
+
+	mask := byte(0x80 >> (index % 8))
+	if revoked {
+		l.bits[index/8] |= mask
+	} else {
+		l.bits[index/8] &^= mask
+	}
+	return nil
+}
+
+// IsRevoked reports whether the bit for index is set
+func (l *StatusList) IsRevoked(index int) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if index < 0 || index >= len(l.bits)*8 {
+		return false, ErrStatusIndexRange
+	}
+	return l.bits[index/8]&(0x80>>(index%8)) != 0, nil
+}
+
+// Status reports the revocation status for index
+func (l *StatusList) Status(index int) (Status, error) {
+	revoked, err := l.IsRevoked(index)
+	if err != nil {
+		return "", err
+	}
+	if revoked {
+		return StatusRevoked, nil
+	}
+	return StatusActive, nil
+}
+
+// Encode returns the gzip-compressed, base64url-encoded bitstring
+func (l *StatusList) Encode() (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(l.bits); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecodeStatusList parses an encoded bitstring produced by Encode. A list of
+// more than MaxStatusListSize indexes returns ErrStatusListTooLarge.
+func DecodeStatusList(encoded string) (*StatusList, error) {
+	compressed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidStatusList
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, ErrInvalidStatusList
+	}
+	defer zr.Close()
+
+	// Zeros compress well, so bound the output rather than the input
+	bits, err := io.ReadAll(io.LimitReader(zr, MaxStatusListSize/8+1))
+	if err != nil {
+		return nil, ErrInvalidStatusList
+	}
+	if len(bits) > MaxStatusListSize/8 {
+		return nil, fmt.Errorf("%w: more than %d indexes", ErrStatusListTooLarge, MaxStatusListSize)
+	}
+
+	// Decoded lists are read-only snapshots; allocation starts past the end
+	return &StatusList{bits: bits, next: len(bits) * 8}, nil
+}
+
+// RestoreStatusList resumes an issuer's own list from its encoded bitstring
+// and the Next index saved with it, so allocation continues where it left
+// off. next must be within the list.
+func RestoreStatusList(encoded string, next int) (*StatusList, error) {
+	list, err := DecodeStatusList(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if next < 0 || next > list.Size() {
+		return nil, fmt.Errorf("%w: next index %d outside a list of %d", ErrInvalidStatusList, next, list.Size())
+	}
+	list.next = next
+	return list, nil
+}
+
+// IssueStatusListCredential signs the status list as a credential for hosting at listURL
+func IssueStatusListCredential(listURL, issuerDID string, privateKey ed25519.PrivateKey, list *StatusList) (string, error) {
+	encoded, err := list.Encode()
+	if err != nil {
+		return "", err
+	}
+
+	subject := StatusListSubject{
+		ID:            listURL + "#list",
+		Type:          "StatusList2021",
+		StatusPurpose: StatusPurposeRevocation,
+		EncodedList:   encoded,
+	}
+
+	return vc.IssueVCWithStatus(issuerDID, listURL, privateKey, subject, listURL, nil)
+}
+
+// VerifyStatusListCredential verifies a status list credential and returns
+// the decoded list with its URL. The list must be issued by opts.IssuerDID
+// and signed with a key that DID resolves to, or ErrListIssuerMismatch is
+// returned; issuerPublicKey may be nil to use the resolved key. A list for
+// any purpose other than revocation returns ErrInvalidStatusList.
+func VerifyStatusListCredential(token string, issuerPublicKey ed25519.PublicKey, opts StatusListOptions) (*PublishedStatusList, error) {
+	if opts.IssuerDID == "" {
+		return nil, fmt.Errorf("%w: no expected issuer", ErrListIssuerMismatch)
+	}
+	claims, err := vc.VerifyVCWithOptions(token, issuerPublicKey, vc.VerifyOptions{
+		ExpectedIssuer: opts.IssuerDID,
+		Resolver:       opts.Resolver,
+	})
+	if errors.Is(err, vc.ErrIssuerKeyMismatch) {
+		return nil, fmt.Errorf("%w: %w", ErrListIssuerMismatch, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	isStatusList := false
+	for _, t := range claims.VC.Type {
+		if t == CredentialTypeStatusList {
+			isStatusList = true
+		}
+	}
+	if !isStatusList {
+		return nil, ErrNotStatusListCredential
+	}
+
+	subject, ok := claims.VC.CredentialSubject.(map[string]interface{})
+	if !ok {
+		return nil, ErrInvalidStatusList
+	}
+	encoded, ok := subject["encodedList"].(string)
+	if !ok {
+		return nil, ErrInvalidStatusList
+	}
+	purpose, _ := subject["statusPurpose"].(string)
+	if purpose != StatusPurposeRevocation {
+		return nil, fmt.Errorf("%w: status purpose %q", ErrInvalidStatusList, purpose)
+	}
+	listURL := claims.GetCredentialID()
+	if listURL == "" {
+		return nil, fmt.Errorf("%w: list credential has no id", ErrInvalidStatusList)
+	}
+
+	list, err := DecodeStatusList(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return &PublishedStatusList{URL: listURL, Issuer: claims.Issuer, Purpose: purpose, List: list}, nil
+}
+
+// CheckStatusListEntry reports the status of a credential that carries a
+// StatusList2021Entry, using an already-verified status list. An entry that
+// names another list, or a purpose other than the list's, returns
+// ErrStatusListMismatch rather than a status read from the wrong bits, and a
+// credential from another issuer returns ErrListIssuerMismatch.
+func CheckStatusListEntry(claims *vc.VCClaims, list *PublishedStatusList) (Status, error) {
+	listURL, index, ok := claims.StatusListEntry()
+	if !ok {
+		return "", ErrNoStatusListEntry
+	}
+	if claims.Issuer != list.Issuer {
+		return "", fmt.Errorf("%w: credential from %s, list from %s", ErrListIssuerMismatch, claims.Issuer, list.Issuer)
+	}
+	if listURL != list.URL {
+		return "", fmt.Errorf("%w: entry names %s, list is %s", ErrStatusListMismatch, listURL, list.URL)
+	}
+	if purpose := claims.VC.CredentialStatus.StatusPurpose; purpose != list.Purpose {
+		return "", fmt.Errorf("%w: entry purpose %q, list purpose %q", ErrStatusListMismatch, purpose, list.Purpose)
+	}
+	return list.List.Status(index)
+}
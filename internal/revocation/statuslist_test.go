@@ -0,0 +1,298 @@
+package revocation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+	"github.com/veriglob/veriglob-core/internal/vc"
+	"github.com/veriglob/veriglob-core/internal/vc/vctest"
+)
+
+func TestStatusListAllocateAndRevoke(t *testing.T) {
+	list := NewStatusList(16)
+
+	if list.Size() != 16 {
+		t.Errorf("Expected size 16, got %d", list.Size())
+	}
+
+	for want := 0; want < 16; want++ {
+		got, err := list.AllocateStatusIndex()
+		if err != nil {
+			t.Fatalf("AllocateStatusIndex failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("Expected index %d, got %d", want, got)
+		}
+	}
+
+	if _, err := list.AllocateStatusIndex(); err != ErrStatusListFull {
+		t.Errorf("Expected ErrStatusListFull, got %v", err)
+	}
+
+	if err := list.SetRevoked(9, true); err != nil {
+		t.Fatalf("SetRevoked failed: %v", err)
+	}
+
+	for i := 0; i < 16; i++ {
+		revoked, _ := list.IsRevoked(i)
+		if revoked != (i == 9) {
+			t.Errorf("Index %d: revoked = %v", i, revoked)
+		}
+	}
+
+	// Bit 0 is the most significant bit of the first byte
+	list.SetRevoked(0, true)
+	if list.bits[0] != 0x80 || list.bits[1] != 0x40 {
+		t.Errorf("Unexpected bit layout: %08b %08b", list.bits[0], list.bits[1])
+	}
+
+	list.SetRevoked(9, false)
+	if status, _ := list.Status(9); status != StatusActive {
+		t.Errorf("Expected status %s after clearing, got %s", StatusActive, status)
+	}
+
+	if err := list.SetRevoked(16, true); err != ErrStatusIndexRange {
+		t.Errorf("Expected ErrStatusIndexRange, got %v", err)
+	}
+}
+
+func TestStatusListEncodeDecode(t *testing.T) {
+	list := NewStatusList(DefaultStatusListSize)
+	list.SetRevoked(42, true)
+	list.SetRevoked(DefaultStatusListSize-1, true)
+
+	encoded, err := list.Encode()
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	// A mostly empty 16KB list compresses to a small payload
+	if len(encoded) > 1024 {
+		t.Errorf("Encoded list is unexpectedly large: %d bytes", len(encoded))
+	}
+
+	decoded, err := DecodeStatusList(encoded)
+	if err != nil {
+		t.Fatalf("DecodeStatusList failed: %v", err)
+	}
+
+	for _, i := range []int{0, 41, 42, 43, DefaultStatusListSize - 1} {
+		want, _ := list.IsRevoked(i)
+		got, _ := decoded.IsRevoked(i)
+		if got != want {
+			t.Errorf("Index %d: decoded %v, want %v", i, got, want)
+		}
+	}
+
+	if _, err := DecodeStatusList("not base64!"); err != ErrInvalidStatusList {
+		t.Errorf("Expected ErrInvalidStatusList, got %v", err)
+	}
+}
+
+func TestDecodeStatusListSizeLimit(t *testing.T) {
+	// An empty list is all zeros and compresses to almost nothing
+	atLimit, _ := NewStatusList(MaxStatusListSize).Encode()
+	list, err := DecodeStatusList(atLimit)
+	if err != nil {
+		t.Fatalf("Expected a list of exactly the limit to decode, got %v", err)
+	}
+	if list.Size() != MaxStatusListSize {
+		t.Errorf("Expected size %d, got %d", MaxStatusListSize, list.Size())
+	}
+
+	bomb, _ := NewStatusList(MaxStatusListSize + 8).Encode()
+	if _, err := DecodeStatusList(bomb); !errors.Is(err, ErrStatusListTooLarge) {
+		t.Errorf("Expected ErrStatusListTooLarge, got %v", err)
+	}
+}
+
+func TestStatusListRestore(t *testing.T) {
+	list := NewStatusList(16)
+	for i := 0; i < 3; i++ {
+		list.AllocateStatusIndex()
+	}
+	list.SetRevoked(1, true)
+
+	// What an issuer persists before a restart
+	encoded, _ := list.Encode()
+	next := list.Next()
+
+	restored, err := RestoreStatusList(encoded, next)
+	if err != nil {
+		t.Fatalf("RestoreStatusList failed: %v", err)
+	}
+	index, err := restored.AllocateStatusIndex()
+	if err != nil {
+		t.Fatalf("AllocateStatusIndex failed: %v", err)
+	}
+	if index != 3 {
+		t.Errorf("Expected allocation to resume at 3, got %d", index)
+	}
+	if revoked, _ := restored.IsRevoked(1); !revoked {
+		t.Error("Expected the revoked index to stay revoked")
+	}
+
+	for _, bad := range []int{-1, 17} {
+		if _, err := RestoreStatusList(encoded, bad); !errors.Is(err, ErrInvalidStatusList) {
+			t.Errorf("Expected ErrInvalidStatusList for next %d, got %v", bad, err)
+		}
+	}
+}
+
+func TestStatusListCredentialRoundTrip(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	issuer, _ := did.CreateDIDKey(issuerPub)
+	issuerDID := issuer.DID
+	listURL := "https://issuer.example/status/1"
+
+	list := NewStatusList(DefaultStatusListSize)
+
+	// Issue two credentials with status list entries
+	issue := func() (*vc.VCClaims, int) {
+		index, err := list.AllocateStatusIndex()
+		if err != nil {
+			t.Fatalf("AllocateStatusIndex failed: %v", err)
+		}
+		token, err := vc.IssueVCWithStatus(issuerDID, "did:key:zSubject", issuerPriv,
+			vctest.IdentitySubject("did:key:zSubject"), "", vc.NewStatusList2021Entry(listURL, index))
+		if err != nil {
+			t.Fatalf("IssueVCWithStatus failed: %v", err)
+		}
+		claims, err := vc.VerifyVC(token, issuerPub)
+		if err != nil {
+			t.Fatalf("VerifyVC failed: %v", err)
+		}
+		return claims, index
+	}
+
+	kept, _ := issue()
+	revoked, revokedIndex := issue()
+
+	gotURL, gotIndex, ok := revoked.StatusListEntry()
+	if !ok || gotURL != listURL || gotIndex != revokedIndex {
+		t.Errorf("Unexpected status list entry: %s %d %v", gotURL, gotIndex, ok)
+	}
+
+	list.SetRevoked(revokedIndex, true)
+
+	token, err := IssueStatusListCredential(listURL, issuerDID, issuerPriv, list)
+	if err != nil {
+		t.Fatalf("IssueStatusListCredential failed: %v", err)
+	}
+
+	published, err := VerifyStatusListCredential(token, issuerPub, StatusListOptions{IssuerDID: issuerDID})
+	if err != nil {
+		t.Fatalf("VerifyStatusListCredential failed: %v", err)
+	}
+
+	if status, _ := CheckStatusListEntry(kept, published); status != StatusActive {
+		t.Errorf("Expected kept credential to be %s, got %s", StatusActive, status)
+	}
+	if status, _ := CheckStatusListEntry(revoked, published); status != StatusRevoked {
+		t.Errorf("Expected revoked credential to be %s, got %s", StatusRevoked, status)
+	}
+
+	// A regular credential is not a status list
+	regular, _ := vc.IssueVC(issuerDID, "did:key:zSubject", issuerPriv, vctest.IdentitySubject("did:key:zSubject"))
+	if _, err := VerifyStatusListCredential(regular, issuerPub, StatusListOptions{IssuerDID: issuerDID}); err != ErrNotStatusListCredential {
+		t.Errorf("Expected ErrNotStatusListCredential, got %v", err)
+	}
+
+	regularClaims, _ := vc.VerifyVC(regular, issuerPub)
+	if _, err := CheckStatusListEntry(regularClaims, published); err != ErrNoStatusListEntry {
+		t.Errorf("Expected ErrNoStatusListEntry, got %v", err)
+	}
+}
+
+func TestCheckStatusListEntryMismatch(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	issuer, _ := did.CreateDIDKey(issuerPub)
+	issuerDID := issuer.DID
+	listA := "https://issuer.example/status/a"
+	listB := "https://issuer.example/status/b"
+
+	issue := func(status *vc.CredentialStatus) *vc.VCClaims {
+		token, err := vc.IssueVCWithStatus(issuerDID, "did:key:zSubject", issuerPriv,
+			vctest.IdentitySubject("did:key:zSubject"), "", status)
+		if err != nil {
+			t.Fatalf("IssueVCWithStatus failed: %v", err)
+		}
+		claims, _ := vc.VerifyVC(token, issuerPub)
+		return claims
+	}
+	onListA := issue(vc.NewStatusList2021Entry(listA, 3))
+	suspension := vc.NewStatusList2021Entry(listB, 3)
+	suspension.StatusPurpose = "suspension"
+	suspendable := issue(suspension)
+
+	token, _ := IssueStatusListCredential(listB, issuerDID, issuerPriv, NewStatusList(16))
+	published, err := VerifyStatusListCredential(token, issuerPub, StatusListOptions{IssuerDID: issuerDID})
+	if err != nil {
+		t.Fatalf("VerifyStatusListCredential failed: %v", err)
+	}
+	if published.URL != listB || published.Purpose != StatusPurposeRevocation {
+		t.Errorf("Expected list %s for revocation, got %s for %s", listB, published.URL, published.Purpose)
+	}
+
+	// Index 3 is clear in list B, which says nothing about list A
+	if _, err := CheckStatusListEntry(onListA, published); !errors.Is(err, ErrStatusListMismatch) {
+		t.Errorf("Expected ErrStatusListMismatch for another list, got %v", err)
+	}
+	if _, err := CheckStatusListEntry(suspendable, published); !errors.Is(err, ErrStatusListMismatch) {
+		t.Errorf("Expected ErrStatusListMismatch for another purpose, got %v", err)
+	}
+
+	// A list published for another purpose is not a revocation list
+	encoded, _ := NewStatusList(16).Encode()
+	suspensionList, _ := vc.IssueVCWithStatus(issuerDID, listB, issuerPriv, StatusListSubject{
+		ID:            listB + "#list",
+		Type:          "StatusList2021",
+		StatusPurpose: "suspension",
+		EncodedList:   encoded,
+	}, listB, nil)
+	if _, err := VerifyStatusListCredential(suspensionList, issuerPub, StatusListOptions{IssuerDID: issuerDID}); !errors.Is(err, ErrInvalidStatusList) {
+		t.Errorf("Expected ErrInvalidStatusList for a suspension list, got %v", err)
+	}
+}
+
+func TestVerifyStatusListCredentialIssuer(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, otherPriv, _ := ed25519.GenerateKey(rand.Reader)
+	issuer, _ := did.CreateDIDKey(issuerPub)
+	other, _ := did.CreateDIDKey(otherPub)
+	listURL := "https://issuer.example/status/1"
+
+	// Another issuer's list, checked with that issuer's own key
+	othersList, _ := IssueStatusListCredential(listURL, other.DID, otherPriv, NewStatusList(16))
+	if _, err := VerifyStatusListCredential(othersList, otherPub, StatusListOptions{IssuerDID: issuer.DID}); !errors.Is(err, ErrListIssuerMismatch) {
+		t.Errorf("Expected ErrListIssuerMismatch for another issuer's list, got %v", err)
+	}
+
+	// Signed by another key while naming the issuer
+	forged, _ := IssueStatusListCredential(listURL, issuer.DID, otherPriv, NewStatusList(16))
+	if _, err := VerifyStatusListCredential(forged, otherPub, StatusListOptions{IssuerDID: issuer.DID}); !errors.Is(err, ErrListIssuerMismatch) {
+		t.Errorf("Expected ErrListIssuerMismatch for a key the issuer does not hold, got %v", err)
+	}
+
+	if _, err := VerifyStatusListCredential(othersList, otherPub, StatusListOptions{}); !errors.Is(err, ErrListIssuerMismatch) {
+		t.Errorf("Expected ErrListIssuerMismatch without an expected issuer, got %v", err)
+	}
+
+	// The issuer's own list, with the key resolved from its DID
+	token, _ := IssueStatusListCredential(listURL, issuer.DID, issuerPriv, NewStatusList(16))
+	published, err := VerifyStatusListCredential(token, nil, StatusListOptions{IssuerDID: issuer.DID})
+	if err != nil {
+		t.Fatalf("VerifyStatusListCredential failed: %v", err)
+	}
+
+	// A credential from another issuer cannot be checked against it
+	cred, _ := vc.IssueVCWithStatus(other.DID, "did:key:zSubject", otherPriv,
+		vctest.IdentitySubject("did:key:zSubject"), "", vc.NewStatusList2021Entry(listURL, 0))
+	claims, _ := vc.VerifyVC(cred, otherPub)
+	if _, err := CheckStatusListEntry(claims, published); !errors.Is(err, ErrListIssuerMismatch) {
+		t.Errorf("Expected ErrListIssuerMismatch for another issuer's credential, got %v", err)
+	}
+}
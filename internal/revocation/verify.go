@@ -0,0 +1,81 @@
+package revocation
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+
+	"github.com/veriglob/veriglob-core/internal/vc"
+)
+
+var (
+	ErrCredentialSuspended = errors.New("credential is suspended")
+)
+
+// StatusChecker looks up the revocation status of a credential by ID.
+// *Registry and *ShardedRegistry implement it, and RemoteRegistry.WithContext
+// adapts a remote registry to it.
+type StatusChecker interface {
+	CheckStatus(credentialID string) (*Entry, error)
+}
+
+// WithContext returns a StatusChecker that looks up status remotely under ctx
+func (r *RemoteRegistry) WithContext(ctx context.Context) StatusChecker {
+	return remoteChecker{registry: r, ctx: ctx}
+}
+
+// remoteChecker binds a RemoteRegistry to a context
+type remoteChecker struct {
+	registry *RemoteRegistry
+	ctx      context.Context
+}
+
+func (c remoteChecker) CheckStatus(credentialID string) (*Entry, error) {
+	return c.registry.CheckStatus(c.ctx, credentialID)
+}
+
+// VerifyVCWithStatus verifies a credential like vc.VerifyVC and resolves the
+// credentialStatus its issuer embedded against checker, returning the claims
+// with the status. A revoked credential returns ErrCredentialRevoked and a
+// suspended one ErrCredentialSuspended, both with the claims and status.
+//
+// Only RevocationRegistry2024 entries are resolved here; a StatusList2021Entry
+// is checked with VerifyStatusListCredential and CheckStatusListEntry. A
+// credential without a supported status, or one the registry does not track,
+// returns an empty status, as does a nil checker. Any other lookup error,
+// e.g. ErrRegistryUnavailable, is returned with the claims so the caller can
+// fail open or closed.
+func VerifyVCWithStatus(tokenString string, publicKey ed25519.PublicKey, checker StatusChecker) (*vc.VCClaims, Status, error) {
+	claims, err := vc.VerifyVC(tokenString, publicKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	status := claims.VC.CredentialStatus
+	if checker == nil || status == nil || status.Type != vc.StatusTypeRevocationRegistry {
+		return claims, "", nil
+	}
+	credentialID := status.ID
+	if credentialID == "" {
+		credentialID = claims.GetCredentialID()
+	}
+	if credentialID == "" {
+		return claims, "", nil
+	}
+
+	entry, err := checker.CheckStatus(credentialID)
+	switch {
+	case errors.Is(err, ErrCredentialNotFound):
+		return claims, "", nil
+	case err != nil:
+		return claims, "", err
+	}
+
+	switch entry.Status {
+	case StatusRevoked:
+		return claims, entry.Status, ErrCredentialRevoked
+	case StatusSuspended:
+		return claims, entry.Status, ErrCredentialSuspended
+	}
+	return claims, entry.Status, nil
+}
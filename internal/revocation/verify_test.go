@@ -0,0 +1,115 @@
+package revocation
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/vc"
+	"github.com/veriglob/veriglob-core/internal/vc/vctest"
+)
+
+func TestVerifyVCWithStatus(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	issue := func(id string) string {
+		t.Helper()
+		token, err := vc.IssueVCWithID("did:key:issuer", "did:key:subject", priv, vctest.IdentitySubject("did:key:subject"), id)
+		if err != nil {
+			t.Fatalf("IssueVCWithID failed: %v", err)
+		}
+		return token
+	}
+
+	registry := NewRegistry()
+	for _, id := range []string{"urn:uuid:active", "urn:uuid:revoked", "urn:uuid:suspended"} {
+		registry.Register(id, "did:key:issuer", "did:key:subject")
+	}
+	registry.Revoke("urn:uuid:revoked", "key compromise")
+	registry.Suspend("urn:uuid:suspended", "under review")
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus Status
+		wantErr    error
+	}{
+		{"active", "urn:uuid:active", StatusActive, nil},
+		{"revoked", "urn:uuid:revoked", StatusRevoked, ErrCredentialRevoked},
+		{"suspended", "urn:uuid:suspended", StatusSuspended, ErrCredentialSuspended},
+		{"untracked", "urn:uuid:unknown", "", nil},
+		{"no status", "", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, status, err := VerifyVCWithStatus(issue(tt.id), pub, registry)
+			if err != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("Expected status %q, got %q", tt.wantStatus, status)
+			}
+			if claims == nil || claims.Subject != "did:key:subject" {
+				t.Errorf("Expected the verified claims, got %+v", claims)
+			}
+		})
+	}
+
+	// A bad signature fails before any lookup
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	if claims, _, err := VerifyVCWithStatus(issue("urn:uuid:revoked"), other, registry); err == nil || claims != nil {
+		t.Errorf("Expected a signature error without claims, got %v", err)
+	}
+
+	// Without a checker only the signature is verified
+	if _, status, err := VerifyVCWithStatus(issue("urn:uuid:revoked"), pub, nil); err != nil || status != "" {
+		t.Errorf("Expected no status check without a checker, got %q, %v", status, err)
+	}
+}
+
+func TestVerifyVCWithStatusUnsupportedType(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	registry := NewRegistry()
+	registry.Register("urn:uuid:listed", "did:key:issuer", "did:key:subject")
+	registry.Revoke("urn:uuid:listed", "test")
+
+	// A status list entry is not looked up in the registry
+	token, err := vc.IssueVCWithStatus("did:key:issuer", "did:key:subject", priv, vctest.IdentitySubject("did:key:subject"),
+		"urn:uuid:listed", vc.NewStatusList2021Entry("https://issuer.example/status/1", 3))
+	if err != nil {
+		t.Fatalf("IssueVCWithStatus failed: %v", err)
+	}
+	if _, status, err := VerifyVCWithStatus(token, pub, registry); err != nil || status != "" {
+		t.Errorf("Expected a status list entry to be skipped, got %q, %v", status, err)
+	}
+}
+
+func TestVerifyVCWithStatusRemote(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	local := NewRegistry()
+	local.Register("urn:uuid:revoked", "did:key:issuer", "did:key:subject")
+	local.Revoke("urn:uuid:revoked", "test")
+
+	var hits int32
+	srv := newStatusServer(t, local, &hits)
+	defer srv.Close()
+
+	token, _ := vc.IssueVCWithID("did:key:issuer", "did:key:subject", priv, vctest.IdentitySubject("did:key:subject"), "urn:uuid:revoked")
+	remote := NewRemoteRegistry(srv.URL, time.Minute)
+	if _, status, err := VerifyVCWithStatus(token, pub, remote.WithContext(context.Background())); err != ErrCredentialRevoked || status != StatusRevoked {
+		t.Errorf("Expected ErrCredentialRevoked from the remote registry, got %q, %v", status, err)
+	}
+
+	// An unreachable registry is reported, not treated as active
+	srv.Close()
+	down := NewRemoteRegistry(srv.URL, time.Minute)
+	claims, _, err := VerifyVCWithStatus(token, pub, down.WithContext(context.Background()))
+	if !errors.Is(err, ErrRegistryUnavailable) {
+		t.Errorf("Expected ErrRegistryUnavailable, got %v", err)
+	}
+	if claims == nil {
+		t.Error("Expected the verified claims with a lookup error")
+	}
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// failingWriter writes the first limit bytes and then fails, like a disk filling up mid-write
+type failingWriter struct {
+	w     io.Writer
+	limit int
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > f.limit {
+		n, _ := f.w.Write(p[:f.limit])
+		return n, errors.New("simulated write failure")
+	}
+	return f.w.Write(p)
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "file.json")
+
+	if err := writeFileAtomic(path, []byte("first"), 0600); err != nil {
+		t.Fatalf("writeFileAtomic failed: %v", err)
+	}
+	if err := writeFileAtomic(path, []byte("second"), 0600); err != nil {
+		t.Fatalf("writeFileAtomic overwrite failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(data) != "second" {
+		t.Errorf("Expected 'second', got %q", data)
+	}
+
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestWalletSavePartialWriteKeepsPreviousFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+	pass := "atomicpass"
+
+	wallet, _ := CreateWallet(path, pass)
+	pub, priv := generateTestKeypair(t)
+	wallet.SetKeys(pub, priv, "did:key:atomic")
+
+	before, _ := os.ReadFile(path)
+
+	// Fail partway through the next save
+	wrapTempWriter = func(w io.Writer) io.Writer { return &failingWriter{w: w, limit: 16} }
+	defer func() { wrapTempWriter = func(w io.Writer) io.Writer { return w } }()
+
+	if err := wallet.AddCredential(StoredCredential{ID: "lost-cred"}); err == nil {
+		t.Fatal("Expected save to fail")
+	}
+
+	after, _ := os.ReadFile(path)
+	if string(before) != string(after) {
+		t.Error("Wallet file changed after a failed save")
+	}
+
+	// No temp files should be left behind
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only the wallet file in the directory, found %d entries", len(entries))
+	}
+
+	// The previous wallet is still intact and openable
+	reopened, err := OpenWallet(path, pass)
+	if err != nil {
+		t.Fatalf("Failed to open wallet after failed save: %v", err)
+	}
+	if reopened.GetDID() != "did:key:atomic" {
+		t.Errorf("Expected DID did:key:atomic, got %s", reopened.GetDID())
+	}
+}
@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrInvalidBackup            = errors.New("invalid wallet backup")
+	ErrUnsupportedBackupVersion = errors.New("unsupported wallet backup version")
+)
+
+const (
+	// BackupFormat identifies a wallet backup blob
+	BackupFormat = "veriglob-wallet-backup"
+
+	// backupVersion is the current backup blob format
+	backupVersion = 1
+)
+
+// walletBackup is the portable, encrypted form of a wallet. Everything but
+// the nonce and ciphertext is authenticated as GCM additional data, so a
+// modified header fails to decrypt just like a modified payload.
+type walletBackup struct {
+	Format     string        `json:"format"`
+	Version    int           `json:"version"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	KDF        string        `json:"kdf"`
+	KDFParams  *Argon2Params `json:"kdfParams"`
+	Salt       []byte        `json:"salt"`
+	Nonce      []byte        `json:"nonce"`
+	Ciphertext []byte        `json:"ciphertext"`
+}
+
+// additionalData returns the authenticated header of the backup
+func (b *walletBackup) additionalData() ([]byte, error) {
+	header := *b
+	header.Nonce = nil
+	header.Ciphertext = nil
+	return json.Marshal(header)
+}
+
+// ExportEncrypted returns the wallet data encrypted under password, which is
+// independent of the wallet passphrase. The blob can be restored with
+// ImportWallet; tampering with any part of it makes the import fail.
+func (w *Wallet) ExportEncrypted(password string) ([]byte, error) {
+	if w.locked {
+		return nil, ErrWalletLocked
+	}
+	if len(password) < MinPassphraseLength {
+		return nil, ErrPassphraseLength
+	}
+
+	plaintext, err := json.Marshal(w.data)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(plaintext)
+
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	params := DefaultArgon2Params
+	backup := walletBackup{
+		Format:    BackupFormat,
+		Version:   backupVersion,
+		CreatedAt: time.Now().UTC(),
+		KDF:       KDFArgon2id,
+		KDFParams: &params,
+		Salt:      salt,
+	}
+
+	gcm, err := backupCipher(password, &backup)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	aad, err := backup.additionalData()
+	if err != nil {
+		return nil, err
+	}
+
+	backup.Nonce = nonce
+	backup.Ciphertext = gcm.Seal(nil, nonce, plaintext, aad)
+
+	return json.MarshalIndent(backup, "", "  ")
+}
+
+// ImportWallet restores a blob produced by ExportEncrypted into a new wallet
+// at path. The restored wallet is encrypted under the backup password; use
+// ChangePassphrase to set a different one. A wrong password or a modified
+// blob returns ErrInvalidPassword.
+func ImportWallet(path string, blob []byte, password string) (*Wallet, error) {
+	store := NewFileStore(path)
+	exists, err := storeHasWallet(store)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrWalletExists
+	}
+
+	var backup walletBackup
+	if err := json.Unmarshal(blob, &backup); err != nil || backup.Format != BackupFormat {
+		return nil, ErrInvalidBackup
+	}
+	if backup.Version != backupVersion {
+		return nil, ErrUnsupportedBackupVersion
+	}
+
+	gcm, err := backupCipher(password, &backup)
+	if err != nil {
+		return nil, err
+	}
+	if len(backup.Nonce) != gcm.NonceSize() {
+		return nil, ErrInvalidBackup
+	}
+
+	aad, err := backup.additionalData()
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, backup.Nonce, backup.Ciphertext, aad)
+	if err != nil {
+		return nil, ErrInvalidPassword
+	}
+
+	var walletData WalletData
+	err = json.Unmarshal(plaintext, &walletData)
+	clear(plaintext)
+	if err != nil {
+		return nil, ErrInvalidBackup
+	}
+
+	return restoreWallet(store, password, &walletData)
+}
+
+// ImportWalletData creates a new wallet at path, encrypted under passphrase,
+// from the JSON returned by ExportUnsafePlaintext. It is the restore side of
+// a plaintext export, e.g. when migrating a wallet between tools. An
+// existing wallet at path returns ErrWalletExists, a passphrase shorter than
+// MinPassphraseLength returns ErrPassphraseLength, and data that is not a
+// wallet export returns ErrInvalidBackup.
+func ImportWalletData(path, passphrase string, data []byte) (*Wallet, error) {
+	store := NewFileStore(path)
+	exists, err := storeHasWallet(store)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrWalletExists
+	}
+	if len(passphrase) < MinPassphraseLength {
+		return nil, ErrPassphraseLength
+	}
+
+	var walletData WalletData
+	if err := json.Unmarshal(data, &walletData); err != nil || walletData.Version == 0 {
+		return nil, ErrInvalidBackup
+	}
+
+	return restoreWallet(store, passphrase, &walletData)
+}
+
+// restoreWallet saves imported wallet data to store under passphrase,
+// upgrading older data formats on the way
+func restoreWallet(store WalletStore, passphrase string, walletData *WalletData) (*Wallet, error) {
+	migrateIdentities(walletData)
+	if walletData.Credentials == nil {
+		walletData.Credentials = make(map[string]StoredCredential)
+	}
+
+	w := &Wallet{
+		store:      store,
+		passphrase: []byte(passphrase),
+		data:       walletData,
+		kdfParams:  DefaultArgon2Params,
+	}
+	if err := w.Save(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// backupCipher derives the backup key from password and returns its AEAD
+func backupCipher(password string, backup *walletBackup) (cipher.AEAD, error) {
+	if backup.KDF != KDFArgon2id {
+		return nil, ErrUnsupportedKDF
+	}
+	if backup.KDFParams == nil || backup.KDFParams.Validate() != nil {
+		return nil, ErrInvalidKDFParams
+	}
+
+	secret := []byte(password)
+	key, err := deriveKey(secret, &encryptedWallet{
+		KDF:       backup.KDF,
+		KDFParams: backup.KDFParams,
+		Salt:      backup.Salt,
+	})
+	clear(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	clear(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportEncryptedRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	wallet, _ := CreateWallet(filepath.Join(tmpDir, "wallet.json"), "wallet-pass")
+	pub, priv := generateTestKeypair(t)
+	wallet.SetKeys(pub, priv, "did:key:backup-test")
+	wallet.AddCredential(StoredCredential{ID: "backup-cred", Type: "TestCredential"})
+
+	blob, err := wallet.ExportEncrypted("backup-password")
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	if strings.Contains(string(blob), "did:key:backup-test") || strings.Contains(string(blob), "backup-cred") {
+		t.Error("Expected the backup not to contain plaintext wallet data")
+	}
+
+	restorePath := filepath.Join(tmpDir, "restored", "wallet.json")
+	restored, err := ImportWallet(restorePath, blob, "backup-password")
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+	if restored.GetDID() != "did:key:backup-test" {
+		t.Errorf("Expected DID did:key:backup-test, got %s", restored.GetDID())
+	}
+	gotPub, gotPriv, err := restored.GetKeys()
+	if err != nil || !pub.Equal(gotPub) || !priv.Equal(gotPriv) {
+		t.Errorf("Expected restored keys to match, got err %v", err)
+	}
+	if _, err := restored.GetCredential("backup-cred"); err != nil {
+		t.Errorf("Expected restored credential, got %v", err)
+	}
+
+	// The restored wallet is saved under the backup password
+	if _, err := OpenWallet(restorePath, "backup-password"); err != nil {
+		t.Errorf("Expected restored wallet to open, got %v", err)
+	}
+}
+
+func TestExportEncryptedShortPassword(t *testing.T) {
+	wallet, _ := CreateWallet(filepath.Join(t.TempDir(), "wallet.json"), "wallet-pass")
+
+	if _, err := wallet.ExportEncrypted("short"); err != ErrPassphraseLength {
+		t.Errorf("Expected ErrPassphraseLength, got %v", err)
+	}
+}
+
+func TestImportWalletWrongPassword(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	wallet, _ := CreateWallet(filepath.Join(tmpDir, "wallet.json"), "wallet-pass")
+	blob, _ := wallet.ExportEncrypted("backup-password")
+
+	_, err := ImportWallet(filepath.Join(tmpDir, "restored.json"), blob, "wrong-password")
+	if err != ErrInvalidPassword {
+		t.Errorf("Expected ErrInvalidPassword, got %v", err)
+	}
+}
+
+func TestImportWalletTampered(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	wallet, _ := CreateWallet(filepath.Join(tmpDir, "wallet.json"), "wallet-pass")
+	blob, _ := wallet.ExportEncrypted("backup-password")
+
+	var backup walletBackup
+	if err := json.Unmarshal(blob, &backup); err != nil {
+		t.Fatalf("Failed to parse backup: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(b *walletBackup)
+	}{
+		{"ciphertext", func(b *walletBackup) { b.Ciphertext[0] ^= 0xff }},
+		{"header", func(b *walletBackup) { b.CreatedAt = b.CreatedAt.Add(1) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := backup
+			tampered.Ciphertext = append([]byte(nil), backup.Ciphertext...)
+			tt.tamper(&tampered)
+			data, _ := json.Marshal(tampered)
+
+			_, err := ImportWallet(filepath.Join(tmpDir, tt.name+".json"), data, "backup-password")
+			if err != ErrInvalidPassword {
+				t.Errorf("Expected ErrInvalidPassword, got %v", err)
+			}
+		})
+	}
+}
+
+func TestImportWalletInvalidBlob(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name string
+		blob string
+		want error
+	}{
+		{"not json", "not a backup", ErrInvalidBackup},
+		{"wrong format", `{"format":"something-else","version":1}`, ErrInvalidBackup},
+		{"future version", `{"format":"veriglob-wallet-backup","version":99}`, ErrUnsupportedBackupVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ImportWallet(filepath.Join(tmpDir, "wallet.json"), []byte(tt.blob), "backup-password")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestImportWalletExistingPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallet.json")
+
+	wallet, _ := CreateWallet(path, "wallet-pass")
+	blob, _ := wallet.ExportEncrypted("backup-password")
+
+	if _, err := ImportWallet(path, blob, "backup-password"); err != ErrWalletExists {
+		t.Errorf("Expected ErrWalletExists, got %v", err)
+	}
+}
+
+func TestImportWalletDataRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	wallet, _ := CreateWallet(filepath.Join(tmpDir, "wallet.json"), "wallet-pass")
+	personalPub, personalPriv := generateTestKeypair(t)
+	workPub, workPriv := generateTestKeypair(t)
+	wallet.AddIdentity("personal", personalPub, personalPriv, "did:key:z6MkPersonal")
+	wallet.AddIdentity("work", workPub, workPriv, "did:key:z6MkWork")
+	wallet.SetDefaultIdentity("work")
+	wallet.AddCredential(StoredCredential{ID: "export-cred", Type: "TestCredential", Identity: "personal"})
+
+	data, err := wallet.ExportUnsafePlaintext()
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	restorePath := filepath.Join(tmpDir, "migrated", "wallet.json")
+	restored, err := ImportWalletData(restorePath, "new-wallet-pass", data)
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	// The restored wallet is encrypted at rest and opens under the new passphrase
+	raw, _ := os.ReadFile(restorePath)
+	if strings.Contains(string(raw), "export-cred") {
+		t.Error("Expected the imported wallet to be encrypted")
+	}
+	reopened, err := OpenWallet(restorePath, "new-wallet-pass")
+	if err != nil {
+		t.Fatalf("Expected the imported wallet to open, got %v", err)
+	}
+
+	for _, w := range []*Wallet{restored, reopened} {
+		if w.DefaultIdentity() != "work" {
+			t.Errorf("Expected default identity work, got %s", w.DefaultIdentity())
+		}
+		personal, err := w.GetIdentity("personal")
+		if err != nil || personal.DID != "did:key:z6MkPersonal" || !bytes.Equal(personal.Keys.PrivateKey, personalPriv) {
+			t.Errorf("Expected the personal identity keys to survive, got err %v", err)
+		}
+		gotPub, gotPriv, err := w.GetKeys()
+		if err != nil || !workPub.Equal(gotPub) || !workPriv.Equal(gotPriv) {
+			t.Errorf("Expected the work identity keys to survive, got err %v", err)
+		}
+		cred, err := w.GetCredential("export-cred")
+		if err != nil || cred.Type != "TestCredential" || cred.Identity != "personal" {
+			t.Errorf("Expected the credential to survive, got %+v, %v", cred, err)
+		}
+	}
+}
+
+func TestImportWalletDataErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	wallet, _ := CreateWallet(path, "wallet-pass")
+	data, _ := wallet.ExportUnsafePlaintext()
+
+	if _, err := ImportWalletData(path, "wallet-pass", data); err != ErrWalletExists {
+		t.Errorf("Expected ErrWalletExists, got %v", err)
+	}
+
+	newPath := filepath.Join(tmpDir, "new.json")
+	if _, err := ImportWalletData(newPath, "short", data); err != ErrPassphraseLength {
+		t.Errorf("Expected ErrPassphraseLength, got %v", err)
+	}
+	for _, bad := range []string{"not json", "{}", `{"version": "2"}`} {
+		if _, err := ImportWalletData(newPath, "wallet-pass", []byte(bad)); err != ErrInvalidBackup {
+			t.Errorf("Expected ErrInvalidBackup for %q, got %v", bad, err)
+		}
+	}
+	if _, err := os.Stat(newPath); !os.IsNotExist(err) {
+		t.Error("Expected no wallet to be written for a failed import")
+	}
+}
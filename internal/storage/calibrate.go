@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// calibrationRounds is how many single-pass derivations CalibrateKDF
+	// times; the fastest is used, as the others include scheduling noise
+	calibrationRounds = 3
+
+	// maxCalibratedTime bounds the passes CalibrateKDF returns, so a coarse
+	// clock cannot produce a wallet that never unlocks in practice
+	maxCalibratedTime = maxArgon2Time
+)
+
+// CalibrateKDF times Argon2id on this machine and returns parameters that
+// take about target to derive a wallet key, e.g. 500ms. Memory and threads
+// stay at DefaultArgon2Params and the number of passes is scaled to fit, but
+// never below DefaultArgon2Params.Time, so a slow device gets the default
+// cost rather than a weaker one. A target that is not positive returns
+// ErrInvalidKDFParams.
+func CalibrateKDF(target time.Duration) (Argon2Params, error) {
+	params := DefaultArgon2Params
+	if target <= 0 {
+		return Argon2Params{}, ErrInvalidKDFParams
+	}
+	if err := params.Validate(); err != nil {
+		return Argon2Params{}, err
+	}
+
+	passphrase := []byte("veriglob-kdf-calibration")
+	salt := make([]byte, saltSize)
+	var fastest time.Duration
+	for i := 0; i < calibrationRounds; i++ {
+		start := time.Now()
+		argon2.IDKey(passphrase, salt, 1, params.Memory, params.Threads, keySize)
+		if elapsed := time.Since(start); i == 0 || elapsed < fastest {
+			fastest = elapsed
+		}
+	}
+
+	passes := uint32(maxCalibratedTime)
+	if fastest > 0 {
+		passes = uint32(min(int64(target/fastest), maxCalibratedTime))
+	}
+	params.Time = max(passes, DefaultArgon2Params.Time)
+	return params, nil
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalibrateKDF(t *testing.T) {
+	if _, err := CalibrateKDF(0); err != ErrInvalidKDFParams {
+		t.Errorf("Expected ErrInvalidKDFParams, got %v", err)
+	}
+
+	short, err := CalibrateKDF(time.Millisecond)
+	if err != nil {
+		t.Fatalf("CalibrateKDF failed: %v", err)
+	}
+	if short.Time < DefaultArgon2Params.Time {
+		t.Errorf("Expected at least %d passes, got %d", DefaultArgon2Params.Time, short.Time)
+	}
+	if short.Memory != DefaultArgon2Params.Memory || short.Threads != DefaultArgon2Params.Threads {
+		t.Errorf("Expected default memory and threads, got %+v", short)
+	}
+
+	long, err := CalibrateKDF(500 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("CalibrateKDF failed: %v", err)
+	}
+	if long.Time <= short.Time {
+		t.Errorf("Expected a longer target to need more passes, got %d and %d", short.Time, long.Time)
+	}
+	if err := long.Validate(); err != nil {
+		t.Errorf("Expected valid parameters, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/revocation"
+	"github.com/veriglob/veriglob-core/internal/vc"
+)
+
+// WalletInfo contains metadata about a wallet for API responses
+type WalletInfo struct {
+	// ID is not set by Info; applications managing several wallets can use
+	// it to tell them apart
+	ID              string
+	DID             string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	CredentialCount int
+}
+
+// CredentialInfo contains metadata about a credential for API responses
+type CredentialInfo struct {
+	ID               string
+	Type             string
+	IssuerDID        string
+	IssuerDisplay    *vc.IssuerDisplay
+	SubjectDID       string
+	IssuedAt         time.Time
+	ExpiresAt        time.Time
+	Status           string
+	RevocationReason string
+	// Claims holds the verified claims when the info came from verification
+	Claims *vc.VCClaims
+	// IssuerResolved reports whether the issuer key was resolved from a DID
+	// rather than taken from a hex key supplied with the credential
+	IssuerResolved bool
+}
+
+// Info returns the wallet's metadata: the DID of the default identity, when
+// the wallet was created and last saved, and how many credentials it holds
+func (w *Wallet) Info() WalletInfo {
+	return WalletInfo{
+		DID:             w.GetDID(),
+		CreatedAt:       w.data.CreatedAt,
+		UpdatedAt:       w.data.UpdatedAt,
+		CredentialCount: len(w.data.Credentials),
+	}
+}
+
+// CredentialInfo returns the metadata of a stored credential, decoded from
+// its token without checking the signature; Claims is left nil. When status
+// is set the credential's revocation status is looked up in it. A credential
+// the registry does not track has an empty Status, and any other lookup
+// error is returned together with the info.
+func (w *Wallet) CredentialInfo(id string, status revocation.StatusChecker) (*CredentialInfo, error) {
+	cred, err := w.GetCredential(id)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &CredentialInfo{
+		ID:            cred.ID,
+		Type:          cred.Type,
+		IssuerDID:     cred.IssuerDID,
+		IssuerDisplay: cred.IssuerDisplay,
+		IssuedAt:      cred.IssuedAt,
+		ExpiresAt:     cred.ExpiresAt,
+	}
+	// The registry knows the credential by the ID in its token, which may
+	// differ from the ID it was stored under
+	statusID := cred.ID
+	if claims, err := vc.UnverifiedClaims(cred.Token); err == nil {
+		if credType := claims.CredentialType(); credType != "" {
+			info.Type = credType
+		}
+		info.IssuerDID = claims.Issuer
+		info.IssuerDisplay = claims.IssuerDisplay()
+		info.SubjectDID = claims.Subject
+		info.IssuedAt = claims.IssuedAt
+		info.ExpiresAt = claims.ExpiresAt
+		if credentialID := claims.GetCredentialID(); credentialID != "" {
+			statusID = credentialID
+		}
+	}
+
+	if status == nil {
+		return info, nil
+	}
+	entry, err := status.CheckStatus(statusID)
+	switch {
+	case errors.Is(err, revocation.ErrCredentialNotFound):
+		return info, nil
+	case err != nil:
+		return info, err
+	}
+	info.Status = string(entry.Status)
+	info.RevocationReason = entry.Reason
+	return info, nil
+}
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/revocation"
+	"github.com/veriglob/veriglob-core/internal/vc"
+)
+
+func TestWalletInfo(t *testing.T) {
+	wallet, _ := CreateWallet(filepath.Join(t.TempDir(), "wallet.json"), "pass")
+	pub, priv := generateTestKeypair(t)
+	wallet.SetKeys(pub, priv, "did:key:zHolder")
+	wallet.AddCredential(StoredCredential{ID: "cred1"})
+	wallet.AddCredential(StoredCredential{ID: "cred2"})
+
+	info := wallet.Info()
+	if info.DID != "did:key:zHolder" {
+		t.Errorf("Expected DID did:key:zHolder, got %s", info.DID)
+	}
+	if info.CredentialCount != 2 {
+		t.Errorf("Expected 2 credentials, got %d", info.CredentialCount)
+	}
+	if info.CreatedAt.IsZero() || info.UpdatedAt.Before(info.CreatedAt) {
+		t.Errorf("Expected created %v to precede updated %v", info.CreatedAt, info.UpdatedAt)
+	}
+}
+
+func TestWalletCredentialInfo(t *testing.T) {
+	wallet, _ := CreateWallet(filepath.Join(t.TempDir(), "wallet.json"), "pass")
+	_, issuerPriv := generateTestKeypair(t)
+	subject := vc.EducationSubject{ID: "did:key:zHolder", InstitutionName: "University of Technology"}
+	token, err := vc.IssueVCWithOptions("did:key:zIssuer", "did:key:zHolder", issuerPriv, subject, vc.IssueOptions{
+		CredentialID: "urn:uuid:degree",
+		Lifetime:     30 * 24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Failed to issue credential: %v", err)
+	}
+	claims, _ := vc.UnverifiedClaims(token)
+	wallet.AddCredential(StoredCredential{Token: token})
+	// The same credential stored under another ID is still looked up by its own
+	wallet.AddCredential(StoredCredential{ID: "copy", Token: token})
+
+	registry := revocation.NewRegistry()
+	registry.Register("urn:uuid:degree", "did:key:zIssuer", "did:key:zHolder")
+
+	info, err := wallet.CredentialInfo("urn:uuid:degree", registry)
+	if err != nil {
+		t.Fatalf("CredentialInfo failed: %v", err)
+	}
+	if info.ID != "urn:uuid:degree" || info.Type != vc.CredentialTypeEducation {
+		t.Errorf("Expected urn:uuid:degree of type %s, got %s of type %s", vc.CredentialTypeEducation, info.ID, info.Type)
+	}
+	if info.IssuerDID != "did:key:zIssuer" || info.SubjectDID != "did:key:zHolder" {
+		t.Errorf("Expected issuer did:key:zIssuer and subject did:key:zHolder, got %s and %s", info.IssuerDID, info.SubjectDID)
+	}
+	if !info.IssuedAt.Equal(claims.IssuedAt) || !info.ExpiresAt.Equal(claims.ExpiresAt) {
+		t.Errorf("Expected dates %v to %v, got %v to %v", claims.IssuedAt, claims.ExpiresAt, info.IssuedAt, info.ExpiresAt)
+	}
+	if info.Status != string(revocation.StatusActive) {
+		t.Errorf("Expected status active, got %q", info.Status)
+	}
+	if info.Claims != nil {
+		t.Error("Expected no claims for an unverified credential")
+	}
+
+	registry.Revoke("urn:uuid:degree", "Degree rescinded")
+	info, err = wallet.CredentialInfo("copy", registry)
+	if err != nil {
+		t.Fatalf("CredentialInfo failed: %v", err)
+	}
+	if info.ID != "copy" || info.Status != string(revocation.StatusRevoked) || info.RevocationReason != "Degree rescinded" {
+		t.Errorf("Expected copy to be revoked with its reason, got %s %q %q", info.ID, info.Status, info.RevocationReason)
+	}
+
+	// Without a registry, or one that does not track the credential, the status is empty
+	if info, _ := wallet.CredentialInfo("urn:uuid:degree", nil); info.Status != "" {
+		t.Errorf("Expected no status without a registry, got %q", info.Status)
+	}
+	info, err = wallet.CredentialInfo("urn:uuid:degree", revocation.NewRegistry())
+	if err != nil || info.Status != "" {
+		t.Errorf("Expected no status for an untracked credential, got %q, %v", info.Status, err)
+	}
+
+	if _, err := wallet.CredentialInfo("missing", nil); err == nil {
+		t.Error("Expected an error for a credential that is not stored")
+	}
+}
+
+func TestWalletIssuerDisplay(t *testing.T) {
+	wallet, _ := CreateWallet(filepath.Join(t.TempDir(), "wallet.json"), "pass")
+	_, issuerPriv := generateTestKeypair(t)
+	subject := vc.EducationSubject{ID: "did:key:zHolder", InstitutionName: "University of Technology"}
+	display := &vc.IssuerDisplay{Name: "University of Technology", Logo: "https://uot.example/logo.png"}
+
+	branded, _ := vc.IssueVCWithOptions("did:key:zIssuer", "did:key:zHolder", issuerPriv, subject, vc.IssueOptions{
+		CredentialID:  "urn:uuid:branded",
+		IssuerDisplay: display,
+	})
+	plain, _ := vc.IssueVCWithOptions("did:key:zIssuer", "did:key:zHolder", issuerPriv, subject, vc.IssueOptions{
+		CredentialID: "urn:uuid:plain",
+	})
+	wallet.AddCredential(StoredCredential{Token: branded})
+	wallet.AddCredential(StoredCredential{Token: plain})
+
+	for _, cred := range wallet.ListCredentials() {
+		switch cred.ID {
+		case "urn:uuid:branded":
+			if !reflect.DeepEqual(cred.IssuerDisplay, display) {
+				t.Errorf("Expected listed display %+v, got %+v", display, cred.IssuerDisplay)
+			}
+		case "urn:uuid:plain":
+			if cred.IssuerDisplay != nil {
+				t.Errorf("Expected no display, got %+v", cred.IssuerDisplay)
+			}
+		}
+	}
+
+	info, err := wallet.CredentialInfo("urn:uuid:branded", nil)
+	if err != nil {
+		t.Fatalf("CredentialInfo failed: %v", err)
+	}
+	if !reflect.DeepEqual(info.IssuerDisplay, display) {
+		t.Errorf("Expected info display %+v, got %+v", display, info.IssuerDisplay)
+	}
+	if info.IssuerDID != "did:key:zIssuer" {
+		t.Errorf("Expected issuer did:key:zIssuer, got %s", info.IssuerDID)
+	}
+}
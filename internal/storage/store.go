@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// WalletStore persists a wallet's encrypted contents, e.g. to a file, to
+// memory, or to an object store. The wallet encrypts before Write and
+// decrypts after Read, so a store only ever sees ciphertext.
+type WalletStore interface {
+	// Read returns the stored contents, or ErrWalletNotFound if nothing
+	// has been written
+	Read() ([]byte, error)
+	// Write replaces the stored contents. A failed Write must leave the
+	// previous contents readable.
+	Write(data []byte) error
+}
+
+// FileStore keeps a wallet in a file, the backend of CreateWallet and
+// OpenWallet. Writes go to a temporary file that is renamed over the
+// wallet, so a crash never leaves a partial file.
+type FileStore struct {
+	Path string
+}
+
+// NewFileStore creates a store for the wallet file at path
+func NewFileStore(path string) *FileStore {
+	return &FileStore{Path: path}
+}
+
+// Read returns the file contents, or ErrWalletNotFound if there is no file
+func (s *FileStore) Read() ([]byte, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrWalletNotFound
+	}
+	return data, err
+}
+
+// Write atomically replaces the file, readable only by its owner, creating
+// its directory if needed
+func (s *FileStore) Write(data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return err
+	}
+	return writeFileAtomic(s.Path, data, 0600)
+}
+
+// MemoryStore keeps a wallet in memory, for tests and for services that
+// hold a wallet only for their own lifetime. It is safe for concurrent use.
+type MemoryStore struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Read returns a copy of the stored contents, or ErrWalletNotFound if
+// nothing has been written
+func (s *MemoryStore) Read() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data == nil {
+		return nil, ErrWalletNotFound
+	}
+	return append([]byte(nil), s.data...), nil
+}
+
+// Write replaces the stored contents with a copy of data
+func (s *MemoryStore) Write(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = append([]byte{}, data...)
+	return nil
+}
+
+// storeHasWallet reports whether a store already holds a wallet. Errors
+// other than ErrWalletNotFound are returned, so a store that cannot be
+// read is not mistaken for an empty one.
+func storeHasWallet(store WalletStore) (bool, error) {
+	_, err := store.Read()
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, ErrWalletNotFound):
+		return false, nil
+	default:
+		return false, err
+	}
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemoryStoreWallet(t *testing.T) {
+	t.Parallel()
+	store := NewMemoryStore()
+
+	if _, err := OpenWalletWithStore(store, "passphrase"); err != ErrWalletNotFound {
+		t.Errorf("Expected ErrWalletNotFound for an empty store, got %v", err)
+	}
+
+	wallet, err := CreateWalletWithStore(store, "passphrase", WalletOptions{})
+	if err != nil {
+		t.Fatalf("CreateWalletWithStore failed: %v", err)
+	}
+	pub, priv := generateTestKeypair(t)
+	if err := wallet.SetKeys(pub, priv, "did:key:zHolder"); err != nil {
+		t.Fatalf("SetKeys failed: %v", err)
+	}
+	if err := wallet.AddCredential(StoredCredential{ID: "cred-1", Type: "IdentityCredential", Token: "token"}); err != nil {
+		t.Fatalf("AddCredential failed: %v", err)
+	}
+
+	if _, err := CreateWalletWithStore(store, "passphrase", WalletOptions{}); err != ErrWalletExists {
+		t.Errorf("Expected ErrWalletExists, got %v", err)
+	}
+	if _, err := OpenWalletWithStore(store, "wrong passphrase"); err != ErrInvalidPassword {
+		t.Errorf("Expected ErrInvalidPassword, got %v", err)
+	}
+
+	reopened, err := OpenWalletWithStore(store, "passphrase")
+	if err != nil {
+		t.Fatalf("OpenWalletWithStore failed: %v", err)
+	}
+	if reopened.GetDID() != "did:key:zHolder" {
+		t.Errorf("Expected DID did:key:zHolder, got %s", reopened.GetDID())
+	}
+	if _, err := reopened.GetCredential("cred-1"); err != nil {
+		t.Errorf("Expected cred-1 in the reopened wallet, got %v", err)
+	}
+
+	// Only ciphertext reaches the store
+	data, _ := store.Read()
+	if bytes.Contains(data, []byte("did:key:zHolder")) {
+		t.Error("Expected the stored wallet to be encrypted")
+	}
+}
+
+func TestMemoryStoreCopies(t *testing.T) {
+	t.Parallel()
+	store := NewMemoryStore()
+
+	data := []byte("ciphertext")
+	store.Write(data)
+	data[0] = 'X'
+	read, _ := store.Read()
+	if string(read) != "ciphertext" {
+		t.Errorf("Expected the store to keep its own copy, got %q", read)
+	}
+
+	read[0] = 'Y'
+	again, _ := store.Read()
+	if string(again) != "ciphertext" {
+		t.Errorf("Expected Read to return a copy, got %q", again)
+	}
+}
+
+func TestNewWalletWithStore(t *testing.T) {
+	t.Parallel()
+	store := NewMemoryStore()
+
+	// An empty store gets a new wallet
+	wallet, err := NewWalletWithStore(store, "passphrase")
+	if err != nil {
+		t.Fatalf("NewWalletWithStore failed: %v", err)
+	}
+	wallet.AddCredential(StoredCredential{ID: "cred-1", Token: "token"})
+
+	// A store with a wallet is opened
+	opened, err := NewWalletWithStore(store, "passphrase")
+	if err != nil {
+		t.Fatalf("NewWalletWithStore failed on reopen: %v", err)
+	}
+	if len(opened.ListCredentials()) != 1 {
+		t.Errorf("Expected the existing wallet with 1 credential, got %d", len(opened.ListCredentials()))
+	}
+	if _, err := NewWalletWithStore(store, "wrong passphrase"); err != ErrInvalidPassword {
+		t.Errorf("Expected ErrInvalidPassword, got %v", err)
+	}
+}
+
+// brokenStore fails every read and write
+type brokenStore struct{ err error }
+
+func (s brokenStore) Read() ([]byte, error) { return nil, s.err }
+func (s brokenStore) Write([]byte) error    { return s.err }
+
+func TestWalletStoreErrors(t *testing.T) {
+	t.Parallel()
+	errStore := errors.New("store unavailable")
+
+	// An unreadable store is not mistaken for an empty one
+	if _, err := NewWalletWithStore(brokenStore{errStore}, "passphrase"); err != errStore {
+		t.Errorf("Expected the store error, got %v", err)
+	}
+	if _, err := CreateWalletWithStore(brokenStore{errStore}, "passphrase", WalletOptions{}); err != errStore {
+		t.Errorf("Expected the store error, got %v", err)
+	}
+
+	// A failed write surfaces from Save
+	store := NewMemoryStore()
+	wallet, _ := CreateWalletWithStore(store, "passphrase", WalletOptions{})
+	wallet.store = brokenStore{errStore}
+	if err := wallet.AddCredential(StoredCredential{ID: "cred-1", Token: "token"}); err != errStore {
+		t.Errorf("Expected the store error from AddCredential, got %v", err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	t.Parallel()
+	store := NewFileStore(filepath.Join(t.TempDir(), "nested", "wallet.json"))
+
+	if _, err := store.Read(); err != ErrWalletNotFound {
+		t.Errorf("Expected ErrWalletNotFound for a missing file, got %v", err)
+	}
+	if err := store.Write([]byte("ciphertext")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	data, err := store.Read()
+	if err != nil || string(data) != "ciphertext" {
+		t.Errorf("Expected ciphertext, got %q (%v)", data, err)
+	}
+
+	// The file constructors use the same format, so either opens the other's wallet
+	path := filepath.Join(t.TempDir(), "wallet.json")
+	if _, err := CreateWallet(path, "passphrase"); err != nil {
+		t.Fatalf("CreateWallet failed: %v", err)
+	}
+	if _, err := OpenWalletWithStore(NewFileStore(path), "passphrase"); err != nil {
+		t.Errorf("Expected OpenWalletWithStore to open a CreateWallet file, got %v", err)
+	}
+}
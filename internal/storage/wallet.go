@@ -9,10 +9,15 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
-	"os"
-	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/veriglob/veriglob-core/internal/crypto"
+	"github.com/veriglob/veriglob-core/internal/did"
+	"github.com/veriglob/veriglob-core/internal/presentation"
+	"github.com/veriglob/veriglob-core/internal/vc"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/pbkdf2"
 )
 
@@ -21,29 +26,129 @@ var (
 	ErrWalletExists     = errors.New("wallet already exists")
 	ErrInvalidPassword  = errors.New("invalid password")
 	ErrCredentialExists = errors.New("credential already exists")
+	ErrUnsupportedKDF   = errors.New("unsupported wallet key derivation function")
+	ErrInvalidKDFParams = errors.New("invalid wallet key derivation parameters")
+	ErrIdentityExists   = errors.New("identity already exists")
+	ErrIdentityNotFound = errors.New("identity not found")
+	ErrPassphraseLength = errors.New("passphrase is too short")
+	ErrWalletLocked     = errors.New("wallet is locked")
+	ErrNoKeys           = errors.New("no keys stored in wallet")
+	ErrKeyNotFound      = errors.New("no key for this DID in wallet")
 )
 
 const (
+	// pbkdf2Iterations is the work factor of legacy PBKDF2 wallets whose
+	// header does not record one
 	pbkdf2Iterations = 100000
 	saltSize         = 32
 	keySize          = 32
+
+	// MinPassphraseLength is the shortest passphrase accepted for a wallet
+	MinPassphraseLength = 8
+
+	// walletVersion is the current payload format; version 1 held a single
+	// top-level DID and key pair
+	walletVersion = 2
+
+	// DefaultIdentityLabel is the label given to the identity migrated from
+	// a version 1 wallet, or created by SetKeys on an empty wallet
+	DefaultIdentityLabel = "default"
+)
+
+// Key derivation functions recorded in the on-disk header
+const (
+	KDFPBKDF2   = "pbkdf2"
+	KDFArgon2id = "argon2id"
 )
 
-// Wallet stores keys and credentials
+// Argon2Params are the tunable Argon2id cost parameters
+type Argon2Params struct {
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"` // KiB
+	Threads uint8  `json:"threads"`
+}
+
+// DefaultArgon2Params are used for newly created and migrated wallets
+var DefaultArgon2Params = Argon2Params{
+	Time:    3,
+	Memory:  64 * 1024,
+	Threads: 4,
+}
+
+// Upper bounds on Argon2Params. The parameters are read from a wallet or
+// backup header before anything is authenticated, so without them a
+// tampered file could make opening it exhaust the machine's memory or CPU.
+const (
+	maxArgon2Time    = 1000
+	maxArgon2Memory  = 1024 * 1024 // KiB, i.e. 1 GiB
+	maxArgon2Threads = 64
+)
+
+// Validate checks the parameters are usable: at least one pass and one
+// thread, and at least 8 KiB of memory per thread. At most 1000 passes,
+// 1 GiB of memory and 64 threads are allowed.
+func (p Argon2Params) Validate() error {
+	if p.Time == 0 || p.Threads == 0 || p.Memory < 8*uint32(p.Threads) {
+		return ErrInvalidKDFParams
+	}
+	if p.Time > maxArgon2Time || p.Memory > maxArgon2Memory || p.Threads > maxArgon2Threads {
+		return ErrInvalidKDFParams
+	}
+	return nil
+}
+
+// WalletOptions configures CreateWalletWithOptions
+type WalletOptions struct {
+	// KDFParams sets the Argon2id cost of unlocking the wallet, e.g. from
+	// CalibrateKDF; zero uses DefaultArgon2Params. The parameters are stored
+	// in the file header, so OpenWallet needs no options.
+	KDFParams Argon2Params
+}
+
+// Wallet stores keys and credentials, persisted encrypted to its store. The
+// passphrase is kept as a byte slice so Lock can overwrite it.
 type Wallet struct {
-	path       string
+	store      WalletStore
 	data       *WalletData
-	passphrase string
+	passphrase []byte
+	kdf        string
+	kdfParams  Argon2Params
+	locked     bool
 }
 
 // WalletData is the serializable wallet structure
 type WalletData struct {
-	Version     int                         `json:"version"`
-	CreatedAt   time.Time                   `json:"createdAt"`
-	UpdatedAt   time.Time                   `json:"updatedAt"`
-	DID         string                      `json:"did"`
-	Keys        KeyPair                     `json:"keys"`
-	Credentials map[string]StoredCredential `json:"credentials"`
+	Version         int                         `json:"version"`
+	CreatedAt       time.Time                   `json:"createdAt"`
+	UpdatedAt       time.Time                   `json:"updatedAt"`
+	Identities      []Identity                  `json:"identities,omitempty"`
+	DefaultIdentity string                      `json:"defaultIdentity,omitempty"`
+	Credentials     map[string]StoredCredential `json:"credentials"`
+	Presentations   []PresentationRecord        `json:"presentations,omitempty"`
+
+	// DID and Keys are the version 1 single identity. They are moved into
+	// Identities when the wallet is opened and are not written by this version.
+	DID  string   `json:"did,omitempty"`
+	Keys *KeyPair `json:"keys,omitempty"`
+}
+
+// Identity is a named persona in the wallet with its own DID and key pair
+type Identity struct {
+	Label     string    `json:"label"`
+	DID       string    `json:"did"`
+	Keys      KeyPair   `json:"keys"`
+	CreatedAt time.Time `json:"createdAt"`
+	// PreviousKeys are the keys RotateKey retired, oldest first, kept so
+	// credentials issued to their DIDs can still be presented
+	PreviousKeys []RetiredKey `json:"previousKeys,omitempty"`
+}
+
+// RetiredKey is a key pair an identity no longer uses by default, with the
+// DID it was known by
+type RetiredKey struct {
+	DID       string    `json:"did"`
+	Keys      KeyPair   `json:"keys"`
+	RetiredAt time.Time `json:"retiredAt"`
 }
 
 // KeyPair stores the public and private keys
@@ -62,33 +167,91 @@ type StoredCredential struct {
 	IssuedAt        time.Time `json:"issuedAt"`
 	ExpiresAt       time.Time `json:"expiresAt"`
 	StoredAt        time.Time `json:"storedAt"`
+	Identity        string    `json:"identity,omitempty"` // label of the holding identity; empty means the default
+	// IssuerDisplay is the issuer's display name, logo and description
+	// from the token, if it carries them
+	IssuerDisplay *vc.IssuerDisplay `json:"issuerDisplay,omitempty"`
+}
+
+// Expired reports whether the credential has expired as of now. A zero
+// ExpiresAt, as on credentials stored by older versions, never expires.
+func (c StoredCredential) Expired(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && !now.Before(c.ExpiresAt)
 }
 
-// encryptedWallet is the on-disk format
+// PresentationRecord is an entry in the wallet's presentation history
+type PresentationRecord struct {
+	ID            string    `json:"id"`
+	Audience      string    `json:"audience"`
+	CredentialIDs []string  `json:"credentialIds"`
+	PresentedAt   time.Time `json:"presentedAt"`
+}
+
+// encryptedWallet is the on-disk format. Wallets written before Argon2id
+// support have no kdf field and use PBKDF2, with pbkdf2Iterations unless
+// iterations is set.
 type encryptedWallet struct {
-	Salt       []byte `json:"salt"`
-	Nonce      []byte `json:"nonce"`
-	Ciphertext []byte `json:"ciphertext"`
+	KDF        string        `json:"kdf,omitempty"`
+	KDFParams  *Argon2Params `json:"kdfParams,omitempty"`
+	Iterations int           `json:"iterations,omitempty"`
+	Salt       []byte        `json:"salt"`
+	Nonce      []byte        `json:"nonce"`
+	Ciphertext []byte        `json:"ciphertext"`
 }
 
-// CreateWallet creates a new wallet with the given passphrase
+// CreateWallet creates a new wallet file with the given passphrase
 func CreateWallet(path, passphrase string) (*Wallet, error) {
-	if _, err := os.Stat(path); err == nil {
-		return nil, ErrWalletExists
+	return CreateWalletWithOptions(path, passphrase, WalletOptions{})
+}
+
+// CreateWalletWithOptions creates a new wallet file with the given
+// passphrase, encrypted with the key derivation cost in opts. Parameters that
+// fail Argon2Params.Validate return ErrInvalidKDFParams.
+func CreateWalletWithOptions(path, passphrase string, opts WalletOptions) (*Wallet, error) {
+	return CreateWalletWithStore(NewFileStore(path), passphrase, opts)
+}
+
+// NewWalletWithStore opens the wallet held by store, or creates one with
+// default options if the store is empty, e.g. a MemoryStore in a test or a
+// service that keeps its wallet in an object store
+func NewWalletWithStore(store WalletStore, passphrase string) (*Wallet, error) {
+	exists, err := storeHasWallet(store)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return OpenWalletWithStore(store, passphrase)
+	}
+	return CreateWalletWithStore(store, passphrase, WalletOptions{})
+}
+
+// CreateWalletWithStore creates a new wallet in store, as
+// CreateWalletWithOptions does in a file. A store that already holds a
+// wallet returns ErrWalletExists.
+func CreateWalletWithStore(store WalletStore, passphrase string, opts WalletOptions) (*Wallet, error) {
+	params := opts.KDFParams
+	if params == (Argon2Params{}) {
+		params = DefaultArgon2Params
+	}
+	if err := params.Validate(); err != nil {
+		return nil, err
 	}
 
-	// Create directory if needed
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0700); err != nil {
+	exists, err := storeHasWallet(store)
+	if err != nil {
 		return nil, err
 	}
+	if exists {
+		return nil, ErrWalletExists
+	}
 
 	now := time.Now()
 	w := &Wallet{
-		path:       path,
-		passphrase: passphrase,
+		store:      store,
+		passphrase: []byte(passphrase),
+		kdfParams:  params,
 		data: &WalletData{
-			Version:     1,
+			Version:     walletVersion,
 			CreatedAt:   now,
 			UpdatedAt:   now,
 			Credentials: make(map[string]StoredCredential),
@@ -102,13 +265,15 @@ func CreateWallet(path, passphrase string) (*Wallet, error) {
 	return w, nil
 }
 
-// OpenWallet opens an existing wallet
+// OpenWallet opens an existing wallet file
 func OpenWallet(path, passphrase string) (*Wallet, error) {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return nil, ErrWalletNotFound
-	}
+	return OpenWalletWithStore(NewFileStore(path), passphrase)
+}
 
-	data, err := os.ReadFile(path)
+// OpenWalletWithStore opens the wallet held by store. An empty store returns
+// ErrWalletNotFound and a wrong passphrase ErrInvalidPassword.
+func OpenWalletWithStore(store WalletStore, passphrase string) (*Wallet, error) {
+	data, err := store.Read()
 	if err != nil {
 		return nil, err
 	}
@@ -119,44 +284,156 @@ func OpenWallet(path, passphrase string) (*Wallet, error) {
 	}
 
 	// Derive key from passphrase
-	key := pbkdf2.Key([]byte(passphrase), ew.Salt, pbkdf2Iterations, keySize, sha256.New)
+	secret := []byte(passphrase)
+	key, err := deriveKey(secret, &ew)
+	if err != nil {
+		clear(secret)
+		return nil, err
+	}
 
 	// Decrypt
 	block, err := aes.NewCipher(key)
+	clear(key)
 	if err != nil {
+		clear(secret)
 		return nil, err
 	}
 
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
+		clear(secret)
 		return nil, err
 	}
 
 	plaintext, err := gcm.Open(nil, ew.Nonce, ew.Ciphertext, nil)
 	if err != nil {
+		clear(secret)
 		return nil, ErrInvalidPassword
 	}
 
+	// The decoded wallet holds its own copies of the keys, so the decrypted
+	// JSON is wiped straight away
 	var walletData WalletData
-	if err := json.Unmarshal(plaintext, &walletData); err != nil {
+	err = json.Unmarshal(plaintext, &walletData)
+	clear(plaintext)
+	if err != nil {
+		clear(secret)
 		return nil, err
 	}
+	migrateIdentities(&walletData)
 
-	return &Wallet{
-		path:       path,
-		passphrase: passphrase,
+	w := &Wallet{
+		store:      store,
+		passphrase: secret,
 		data:       &walletData,
-	}, nil
+		kdfParams:  DefaultArgon2Params,
+	}
+	w.kdf = ew.KDF
+	if w.kdf == "" {
+		w.kdf = KDFPBKDF2
+	}
+	if ew.KDFParams != nil {
+		w.kdfParams = *ew.KDFParams
+	}
+
+	return w, nil
 }
 
-// Save encrypts and saves the wallet to disk
+// migrateIdentities moves a version 1 single identity into the identity list.
+// The upgraded format is written on the next Save.
+func migrateIdentities(data *WalletData) {
+	if data.Version >= walletVersion {
+		return
+	}
+
+	if data.DID != "" || data.Keys != nil {
+		id := Identity{
+			Label:     DefaultIdentityLabel,
+			DID:       data.DID,
+			CreatedAt: data.CreatedAt,
+		}
+		if data.Keys != nil {
+			id.Keys = *data.Keys
+		}
+		data.Identities = append(data.Identities, id)
+		data.DefaultIdentity = DefaultIdentityLabel
+	}
+
+	data.DID = ""
+	data.Keys = nil
+	data.Version = walletVersion
+}
+
+// deriveKey derives the encryption key using the KDF recorded in the wallet header
+func deriveKey(passphrase []byte, ew *encryptedWallet) ([]byte, error) {
+	switch ew.KDF {
+	case "", KDFPBKDF2:
+		iterations := ew.Iterations
+		if iterations < 0 {
+			return nil, ErrInvalidKDFParams
+		}
+		if iterations == 0 {
+			iterations = pbkdf2Iterations
+		}
+		return pbkdf2.Key(passphrase, ew.Salt, iterations, keySize, sha256.New), nil
+	case KDFArgon2id:
+		p := ew.KDFParams
+		if p == nil || p.Validate() != nil {
+			return nil, ErrInvalidKDFParams
+		}
+		return argon2.IDKey(passphrase, ew.Salt, p.Time, p.Memory, p.Threads, keySize), nil
+	default:
+		return nil, ErrUnsupportedKDF
+	}
+}
+
+// NeedsKDFMigration reports whether the wallet file still uses the legacy PBKDF2 KDF
+func (w *Wallet) NeedsKDFMigration() bool {
+	return w.kdf == KDFPBKDF2
+}
+
+// KDFParams returns the Argon2id parameters the wallet is saved with
+func (w *Wallet) KDFParams() Argon2Params {
+	return w.kdfParams
+}
+
+// SetKDFParams re-encrypts the wallet with a new Argon2id cost, e.g. after
+// CalibrateKDF on a faster device. The old parameters are kept if the save fails.
+func (w *Wallet) SetKDFParams(params Argon2Params) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	previous := w.kdfParams
+	w.kdfParams = params
+	if err := w.Save(); err != nil {
+		w.kdfParams = previous
+		return err
+	}
+	return nil
+}
+
+// MigrateKDF re-encrypts a legacy PBKDF2 wallet with Argon2id. Any Save
+// performs the same upgrade; this makes it explicit.
+func (w *Wallet) MigrateKDF() error {
+	if !w.NeedsKDFMigration() {
+		return nil
+	}
+	return w.Save()
+}
+
+// Save encrypts the wallet and writes it to its store. A locked wallet cannot
+// be saved, since its keys and passphrase have been wiped.
 func (w *Wallet) Save() error {
+	if w.locked {
+		return ErrWalletLocked
+	}
 	w.data.UpdatedAt = time.Now()
 
 	plaintext, err := json.Marshal(w.data)
 	if err != nil {
 		return err
 	}
+	defer clear(plaintext)
 
 	// Generate salt
 	salt := make([]byte, saltSize)
@@ -164,11 +441,21 @@ func (w *Wallet) Save() error {
 		return err
 	}
 
-	// Derive key from passphrase
-	key := pbkdf2.Key([]byte(w.passphrase), salt, pbkdf2Iterations, keySize, sha256.New)
+	// Derive key from passphrase (always Argon2id; legacy wallets are upgraded here)
+	params := w.kdfParams
+	header := encryptedWallet{
+		KDF:       KDFArgon2id,
+		KDFParams: &params,
+		Salt:      salt,
+	}
+	key, err := deriveKey(w.passphrase, &header)
+	if err != nil {
+		return err
+	}
 
 	// Encrypt
 	block, err := aes.NewCipher(key)
+	clear(key)
 	if err != nil {
 		return err
 	}
@@ -185,54 +472,284 @@ func (w *Wallet) Save() error {
 
 	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
 
-	ew := encryptedWallet{
-		Salt:       salt,
-		Nonce:      nonce,
-		Ciphertext: ciphertext,
+	header.Nonce = nonce
+	header.Ciphertext = ciphertext
+
+	data, err := json.Marshal(header)
+	if err != nil {
+		return err
 	}
 
-	data, err := json.Marshal(ew)
+	if err := w.store.Write(data); err != nil {
+		return err
+	}
+	w.kdf = KDFArgon2id
+	return nil
+}
+
+// ChangePassphrase re-encrypts the wallet under a new passphrase. The old
+// passphrase is checked against the stored wallet before anything is written.
+func (w *Wallet) ChangePassphrase(oldPassphrase, newPassphrase string) error {
+	if w.locked {
+		return ErrWalletLocked
+	}
+	if len(newPassphrase) < MinPassphraseLength {
+		return ErrPassphraseLength
+	}
+	check, err := OpenWalletWithStore(w.store, oldPassphrase)
 	if err != nil {
 		return err
 	}
+	check.Lock()
+
+	previous := w.passphrase
+	w.passphrase = []byte(newPassphrase)
+	if err := w.Save(); err != nil {
+		clear(w.passphrase)
+		w.passphrase = previous
+		return err
+	}
+	clear(previous)
+	return nil
+}
+
+// Lock overwrites the private keys of every identity and the cached
+// passphrase with zeros. Afterwards GetKeys, GetIdentity, ListIdentities,
+// Save and the exports return ErrWalletLocked; open the wallet again with
+// OpenWallet to use its keys. Key slices returned by GetKeys or GetIdentity
+// share memory with the wallet and are wiped too. Credentials and the
+// default DID can still be read.
+func (w *Wallet) Lock() {
+	for i := range w.data.Identities {
+		clear(w.data.Identities[i].Keys.PrivateKey)
+		for j := range w.data.Identities[i].PreviousKeys {
+			clear(w.data.Identities[i].PreviousKeys[j].Keys.PrivateKey)
+		}
+	}
+	if w.data.Keys != nil {
+		clear(w.data.Keys.PrivateKey)
+	}
+	clear(w.passphrase)
+	w.passphrase = nil
+	w.locked = true
+}
 
-	return os.WriteFile(w.path, data, 0600)
+// Locked reports whether Lock has been called
+func (w *Wallet) Locked() bool {
+	return w.locked
 }
 
-// SetKeys stores the key pair in the wallet
+// SetKeys stores the key pair of the default identity, creating it if the
+// wallet has no identities yet
 func (w *Wallet) SetKeys(pub ed25519.PublicKey, priv ed25519.PrivateKey, did string) error {
-	w.data.DID = did
-	w.data.Keys = KeyPair{
-		PublicKey:  pub,
-		PrivateKey: priv,
+	if id := w.defaultIdentity(); id != nil {
+		id.DID = did
+		id.Keys = KeyPair{
+			PublicKey:  pub,
+			PrivateKey: priv,
+		}
+		return w.Save()
 	}
-	return w.Save()
+	return w.AddIdentity(DefaultIdentityLabel, pub, priv, did)
 }
 
-// GetKeys retrieves the key pair from the wallet
+// GetKeys retrieves the key pair of the default identity
 func (w *Wallet) GetKeys() (ed25519.PublicKey, ed25519.PrivateKey, error) {
-	if len(w.data.Keys.PublicKey) == 0 {
-		return nil, nil, errors.New("no keys stored in wallet")
+	if w.locked {
+		return nil, nil, ErrWalletLocked
+	}
+	id := w.defaultIdentity()
+	if id == nil || len(id.Keys.PublicKey) == 0 {
+		return nil, nil, ErrNoKeys
+	}
+	return ed25519.PublicKey(id.Keys.PublicKey),
+		ed25519.PrivateKey(id.Keys.PrivateKey), nil
+}
+
+// GetKeyForDID retrieves the key pair of any identity's current or retired
+// DID, e.g. to present a credential issued before RotateKey. A DID the
+// wallet holds no key for returns ErrKeyNotFound.
+func (w *Wallet) GetKeyForDID(did string) (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	if w.locked {
+		return nil, nil, ErrWalletLocked
+	}
+	for _, id := range w.data.Identities {
+		keys := []RetiredKey{{DID: id.DID, Keys: id.Keys}}
+		for _, k := range append(keys, id.PreviousKeys...) {
+			if k.DID == did && len(k.Keys.PublicKey) != 0 {
+				return ed25519.PublicKey(k.Keys.PublicKey),
+					ed25519.PrivateKey(k.Keys.PrivateKey), nil
+			}
+		}
+	}
+	return nil, nil, ErrKeyNotFound
+}
+
+// RotateKey gives the default identity a fresh key pair and its did:key,
+// and returns the new DID. The old key and DID move to the identity's
+// PreviousKeys, so GetKeys signs with the new key while GetKeyForDID still
+// finds the old one. Stored credentials are kept; they stay issued to the
+// old DID until the issuer reissues them to the new one.
+func (w *Wallet) RotateKey() (string, error) {
+	if w.locked {
+		return "", ErrWalletLocked
+	}
+	id := w.defaultIdentity()
+	if id == nil || len(id.Keys.PublicKey) == 0 {
+		return "", ErrNoKeys
 	}
-	return ed25519.PublicKey(w.data.Keys.PublicKey),
-		ed25519.PrivateKey(w.data.Keys.PrivateKey), nil
+
+	pub, priv, err := crypto.GenerateEd25519Keypair()
+	if err != nil {
+		return "", err
+	}
+	didKey, err := did.CreateDIDKey(pub)
+	if err != nil {
+		return "", err
+	}
+
+	previous := *id
+	id.PreviousKeys = append(id.PreviousKeys, RetiredKey{
+		DID:       id.DID,
+		Keys:      id.Keys,
+		RetiredAt: time.Now(),
+	})
+	id.DID = didKey.DID
+	id.Keys = KeyPair{PublicKey: pub, PrivateKey: priv}
+	if err := w.Save(); err != nil {
+		*id = previous
+		return "", err
+	}
+	return didKey.DID, nil
 }
 
-// GetDID returns the wallet's DID
+// GetDID returns the DID of the default identity
 func (w *Wallet) GetDID() string {
-	return w.data.DID
+	if id := w.defaultIdentity(); id != nil {
+		return id.DID
+	}
+	return ""
 }
 
-// AddCredential stores a credential in the wallet
+// AddIdentity adds a named identity to the wallet. The first identity added
+// becomes the default.
+func (w *Wallet) AddIdentity(label string, pub ed25519.PublicKey, priv ed25519.PrivateKey, did string) error {
+	if label == "" {
+		return errors.New("identity label is required")
+	}
+	if w.findIdentity(label) != nil {
+		return ErrIdentityExists
+	}
+
+	w.data.Identities = append(w.data.Identities, Identity{
+		Label: label,
+		DID:   did,
+		Keys: KeyPair{
+			PublicKey:  pub,
+			PrivateKey: priv,
+		},
+		CreatedAt: time.Now(),
+	})
+	if w.data.DefaultIdentity == "" {
+		w.data.DefaultIdentity = label
+	}
+	return w.Save()
+}
+
+// GetIdentity returns the identity with the given label
+func (w *Wallet) GetIdentity(label string) (*Identity, error) {
+	if w.locked {
+		return nil, ErrWalletLocked
+	}
+	id := w.findIdentity(label)
+	if id == nil {
+		return nil, ErrIdentityNotFound
+	}
+	found := *id
+	return &found, nil
+}
+
+// ListIdentities returns the wallet's identities in the order they were added
+func (w *Wallet) ListIdentities() ([]Identity, error) {
+	if w.locked {
+		return nil, ErrWalletLocked
+	}
+	ids := make([]Identity, len(w.data.Identities))
+	copy(ids, w.data.Identities)
+	return ids, nil
+}
+
+// SetDefaultIdentity selects the identity used by GetKeys and GetDID
+func (w *Wallet) SetDefaultIdentity(label string) error {
+	if w.findIdentity(label) == nil {
+		return ErrIdentityNotFound
+	}
+	w.data.DefaultIdentity = label
+	return w.Save()
+}
+
+// DefaultIdentity returns the label of the default identity
+func (w *Wallet) DefaultIdentity() string {
+	return w.data.DefaultIdentity
+}
+
+func (w *Wallet) defaultIdentity() *Identity {
+	return w.findIdentity(w.data.DefaultIdentity)
+}
+
+func (w *Wallet) findIdentity(label string) *Identity {
+	for i := range w.data.Identities {
+		if w.data.Identities[i].Label == label {
+			return &w.data.Identities[i]
+		}
+	}
+	return nil
+}
+
+// AddCredential stores a credential in the wallet. A zero IssuedAt,
+// ExpiresAt, Type, IssuerDID or IssuerDisplay, or an empty ID, is filled in
+// from the token's claims; the signature is not checked.
 func (w *Wallet) AddCredential(cred StoredCredential) error {
+	fillFromToken(&cred)
 	if _, exists := w.data.Credentials[cred.ID]; exists {
 		return ErrCredentialExists
 	}
+	if cred.Identity != "" && w.findIdentity(cred.Identity) == nil {
+		return ErrIdentityNotFound
+	}
 	cred.StoredAt = time.Now()
 	w.data.Credentials[cred.ID] = cred
 	return w.Save()
 }
 
+// fillFromToken copies the credential's metadata from its token claims into
+// any unset fields. Tokens that do not decode are left alone.
+func fillFromToken(cred *StoredCredential) {
+	md, err := vc.InspectVC(cred.Token)
+	if err != nil {
+		return
+	}
+
+	if cred.ID == "" {
+		cred.ID = md.ID
+	}
+	if cred.Type == "" {
+		cred.Type = md.Type
+	}
+	if cred.IssuerDID == "" {
+		cred.IssuerDID = md.Issuer
+	}
+	if cred.IssuerDisplay == nil {
+		cred.IssuerDisplay = md.IssuerDisplay
+	}
+	if cred.IssuedAt.IsZero() {
+		cred.IssuedAt = md.IssuedAt
+	}
+	if cred.ExpiresAt.IsZero() {
+		cred.ExpiresAt = md.ExpiresAt
+	}
+}
+
 // GetCredential retrieves a credential by ID
 func (w *Wallet) GetCredential(id string) (*StoredCredential, error) {
 	cred, exists := w.data.Credentials[id]
@@ -242,15 +759,137 @@ func (w *Wallet) GetCredential(id string) (*StoredCredential, error) {
 	return &cred, nil
 }
 
-// ListCredentials returns all stored credentials
+// ListCredentials returns all stored credentials, newest first
 func (w *Wallet) ListCredentials() []StoredCredential {
 	creds := make([]StoredCredential, 0, len(w.data.Credentials))
 	for _, c := range w.data.Credentials {
 		creds = append(creds, c)
 	}
+	sortCredentials(creds)
+	return creds
+}
+
+// ListCredentialsForIdentity returns the credentials held by an identity.
+// Credentials without an identity label belong to the default identity.
+func (w *Wallet) ListCredentialsForIdentity(label string) []StoredCredential {
+	var creds []StoredCredential
+	for _, c := range w.data.Credentials {
+		owner := c.Identity
+		if owner == "" {
+			owner = w.data.DefaultIdentity
+		}
+		if owner == label {
+			creds = append(creds, c)
+		}
+	}
+	sortCredentials(creds)
 	return creds
 }
 
+// CredentialFilter selects stored credentials. Zero-valued fields match everything.
+type CredentialFilter struct {
+	Type      string // exact credential type
+	IssuerDID string // exact issuer DID
+	// ExcludeExpired drops credentials whose ExpiresAt has passed.
+	// Credentials without an expiration are never expired.
+	ExcludeExpired bool
+	// Query is a case-insensitive substring matched against the ID and type
+	Query string
+	// Now is the time used for expiry checks; zero uses the current time
+	Now time.Time
+}
+
+// Matches reports whether a credential passes the filter
+func (f CredentialFilter) Matches(c StoredCredential) bool {
+	if f.Type != "" && c.Type != f.Type {
+		return false
+	}
+	if f.IssuerDID != "" && c.IssuerDID != f.IssuerDID {
+		return false
+	}
+	if f.ExcludeExpired {
+		now := f.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		if c.Expired(now) {
+			return false
+		}
+	}
+	if f.Query != "" {
+		q := strings.ToLower(f.Query)
+		if !strings.Contains(strings.ToLower(c.ID), q) && !strings.Contains(strings.ToLower(c.Type), q) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindCredentials returns the credentials matching filter, newest first
+func (w *Wallet) FindCredentials(filter CredentialFilter) []StoredCredential {
+	var creds []StoredCredential
+	for _, c := range w.data.Credentials {
+		if filter.Matches(c) {
+			creds = append(creds, c)
+		}
+	}
+	sortCredentials(creds)
+	return creds
+}
+
+// GetCredentialsByType returns copies of the credentials of one type, e.g.
+// EducationCredential, newest first
+func (w *Wallet) GetCredentialsByType(credType string) []StoredCredential {
+	return w.FindCredentials(CredentialFilter{Type: credType})
+}
+
+// GetCredentialsByIssuer returns copies of the credentials issued by a DID,
+// newest first
+func (w *Wallet) GetCredentialsByIssuer(issuerDID string) []StoredCredential {
+	return w.FindCredentials(CredentialFilter{IssuerDID: issuerDID})
+}
+
+// FindDuplicates returns the stored credentials that assert the same content
+// as token, by vc.VCClaims.Digest, newest first, even if their IDs differ,
+// e.g. a credential the issuer sent twice. Signatures are not checked, so
+// this is a hint for the holder rather than proof. A token that does not
+// decode has no duplicates.
+func (w *Wallet) FindDuplicates(token string) []StoredCredential {
+	digest, err := contentDigest(token)
+	if err != nil {
+		return nil
+	}
+
+	var creds []StoredCredential
+	for _, c := range w.data.Credentials {
+		if d, err := contentDigest(c.Token); err == nil && d == digest {
+			creds = append(creds, c)
+		}
+	}
+	sortCredentials(creds)
+	return creds
+}
+
+// contentDigest returns the content digest of a token's unverified claims
+func contentDigest(token string) (string, error) {
+	claims, err := vc.UnverifiedClaims(token)
+	if err != nil {
+		return "", err
+	}
+	return claims.Digest()
+}
+
+// sortCredentials orders credentials by StoredAt, newest first, breaking
+// ties by ID so listings are stable between runs
+func sortCredentials(creds []StoredCredential) {
+	sort.Slice(creds, func(i, j int) bool {
+		if !creds[i].StoredAt.Equal(creds[j].StoredAt) {
+			return creds[i].StoredAt.After(creds[j].StoredAt)
+		}
+		return creds[i].ID < creds[j].ID
+	})
+}
+
 // RemoveCredential removes a credential by ID
 func (w *Wallet) RemoveCredential(id string) error {
 	if _, exists := w.data.Credentials[id]; !exists {
@@ -260,7 +899,86 @@ func (w *Wallet) RemoveCredential(id string) error {
 	return w.Save()
 }
 
-// Export returns the wallet data as JSON (for backup)
-func (w *Wallet) Export() ([]byte, error) {
+// ListExpired returns copies of the credentials that have expired as of now,
+// newest first, so they can be reviewed before PruneExpired removes them
+func (w *Wallet) ListExpired(now time.Time) []StoredCredential {
+	var creds []StoredCredential
+	for _, c := range w.data.Credentials {
+		if c.Expired(now) {
+			creds = append(creds, c)
+		}
+	}
+	sortCredentials(creds)
+	return creds
+}
+
+// PruneExpired removes the credentials that have expired as of now and
+// returns how many were removed. Credentials without an expiration are kept.
+// The wallet is only saved if something was removed.
+func (w *Wallet) PruneExpired(now time.Time) (int, error) {
+	removed := make(map[string]StoredCredential)
+	for id, c := range w.data.Credentials {
+		if c.Expired(now) {
+			removed[id] = c
+			delete(w.data.Credentials, id)
+		}
+	}
+	if len(removed) == 0 {
+		return 0, nil
+	}
+
+	if err := w.Save(); err != nil {
+		// Keep memory in line with the file that was not rewritten
+		for id, c := range removed {
+			w.data.Credentials[id] = c
+		}
+		return 0, err
+	}
+	return len(removed), nil
+}
+
+// RecordPresentation appends a presentation to the wallet's history
+func (w *Wallet) RecordPresentation(record PresentationRecord) error {
+	if record.PresentedAt.IsZero() {
+		record.PresentedAt = time.Now()
+	}
+	w.data.Presentations = append(w.data.Presentations, record)
+	return w.Save()
+}
+
+// ListPresentations returns the presentation history, oldest first
+func (w *Wallet) ListPresentations() []PresentationRecord {
+	records := make([]PresentationRecord, len(w.data.Presentations))
+	copy(records, w.data.Presentations)
+	return records
+}
+
+// QRPresentation builds a presentation of a single stored credential, signed
+// with the wallet's keys, and compact-encodes it for display as a QR code
+func (w *Wallet) QRPresentation(credID, audience, nonce string) (string, error) {
+	cred, err := w.GetCredential(credID)
+	if err != nil {
+		return "", err
+	}
+
+	_, priv, err := w.GetKeys()
+	if err != nil {
+		return "", err
+	}
+
+	token, err := presentation.CreatePresentation(w.GetDID(), priv, []string{cred.Token}, audience, nonce)
+	if err != nil {
+		return "", err
+	}
+
+	return presentation.EncodeQRPayload(token)
+}
+
+// ExportUnsafePlaintext returns the wallet data, private keys included, as
+// unencrypted JSON. Prefer ExportEncrypted for backups.
+func (w *Wallet) ExportUnsafePlaintext() ([]byte, error) {
+	if w.locked {
+		return nil, ErrWalletLocked
+	}
 	return json.MarshalIndent(w.data, "", "  ")
 }
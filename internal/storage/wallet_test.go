@@ -1,14 +1,29 @@
 package storage
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/ed25519"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/veriglob/veriglob-core/internal/presentation"
+	"github.com/veriglob/veriglob-core/internal/vc"
+	"golang.org/x/crypto/pbkdf2"
 )
 
+func init() {
+	// Keep Argon2id cheap so the suite stays fast; production defaults are much higher
+	DefaultArgon2Params = Argon2Params{Time: 1, Memory: 8 * 1024, Threads: 1}
+}
+
 func generateTestKeypair(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
 	pub, priv, err := ed25519.GenerateKey(rand.Reader)
 	if err != nil {
@@ -222,6 +237,196 @@ func TestWalletListCredentials(t *testing.T) {
 	}
 }
 
+// addCredentialAt adds a credential and backdates its StoredAt, which
+// AddCredential always sets to the current time
+func addCredentialAt(t *testing.T, wallet *Wallet, cred StoredCredential, storedAt time.Time) {
+	t.Helper()
+	if err := wallet.AddCredential(cred); err != nil {
+		t.Fatalf("Failed to add credential: %v", err)
+	}
+	c := wallet.data.Credentials[cred.ID]
+	c.StoredAt = storedAt
+	wallet.data.Credentials[cred.ID] = c
+}
+
+func TestWalletAddCredentialFillsFromToken(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	wallet, _ := CreateWallet(path, "pass")
+	issuerPub, issuerPriv := generateTestKeypair(t)
+	subject := vc.EducationSubject{ID: "did:key:zHolder", InstitutionName: "University of Technology"}
+	token, err := vc.IssueVCWithOptions("did:key:zIssuer", "did:key:zHolder", issuerPriv, subject, vc.IssueOptions{
+		CredentialID: "urn:uuid:degree",
+		Lifetime:     30 * 24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Failed to issue credential: %v", err)
+	}
+	claims, _ := vc.VerifyVC(token, issuerPub)
+
+	if err := wallet.AddCredential(StoredCredential{Token: token}); err != nil {
+		t.Fatalf("Failed to add credential: %v", err)
+	}
+
+	stored, err := wallet.GetCredential("urn:uuid:degree")
+	if err != nil {
+		t.Fatalf("Expected credential stored under its token ID, got %v", err)
+	}
+	if !stored.ExpiresAt.Equal(claims.ExpiresAt) {
+		t.Errorf("Expected ExpiresAt %v, got %v", claims.ExpiresAt, stored.ExpiresAt)
+	}
+	if !stored.IssuedAt.Equal(claims.IssuedAt) {
+		t.Errorf("Expected IssuedAt %v, got %v", claims.IssuedAt, stored.IssuedAt)
+	}
+	if stored.Type != vc.CredentialTypeEducation {
+		t.Errorf("Expected type %s, got %s", vc.CredentialTypeEducation, stored.Type)
+	}
+	if stored.IssuerDID != "did:key:zIssuer" {
+		t.Errorf("Expected issuer did:key:zIssuer, got %s", stored.IssuerDID)
+	}
+
+	// Fields that are already set are kept
+	expiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	wallet.AddCredential(StoredCredential{ID: "explicit", Type: "CustomType", ExpiresAt: expiry, Token: token})
+	explicit, _ := wallet.GetCredential("explicit")
+	if explicit.Type != "CustomType" || !explicit.ExpiresAt.Equal(expiry) {
+		t.Errorf("Expected explicit fields to be kept, got type %s expiry %v", explicit.Type, explicit.ExpiresAt)
+	}
+}
+
+func TestWalletFindDuplicates(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	wallet, _ := CreateWallet(path, "pass")
+	_, issuerPriv := generateTestKeypair(t)
+	subject := vc.EducationSubject{ID: "did:key:zHolder", InstitutionName: "University of Technology"}
+	issue := func(id string, subject vc.EducationSubject) string {
+		token, err := vc.IssueVCWithID("did:key:zIssuer", "did:key:zHolder", issuerPriv, subject, id)
+		if err != nil {
+			t.Fatalf("Failed to issue credential: %v", err)
+		}
+		return token
+	}
+
+	wallet.AddCredential(StoredCredential{Token: issue("urn:uuid:degree", subject)})
+	other := subject
+	other.InstitutionName = "Technical College"
+	wallet.AddCredential(StoredCredential{Token: issue("urn:uuid:diploma", other)})
+
+	// The issuer sends the same degree again under a new ID
+	dups := wallet.FindDuplicates(issue("urn:uuid:degree-resent", subject))
+	if len(dups) != 1 || dups[0].ID != "urn:uuid:degree" {
+		t.Errorf("Expected [urn:uuid:degree], got %v", dups)
+	}
+
+	if dups := wallet.FindDuplicates("not-a-token"); len(dups) != 0 {
+		t.Errorf("Expected no duplicates for a malformed token, got %v", dups)
+	}
+}
+
+func TestWalletGetCredentialsByTypeAndIssuer(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	wallet, _ := CreateWallet(path, "pass")
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	addCredentialAt(t, wallet, StoredCredential{ID: "degree", Type: "EducationCredential", IssuerDID: "did:key:zUniversity"}, base)
+	addCredentialAt(t, wallet, StoredCredential{ID: "diploma", Type: "EducationCredential", IssuerDID: "did:key:zCollege"}, base.Add(time.Hour))
+	addCredentialAt(t, wallet, StoredCredential{ID: "staff", Type: "EmploymentCredential", IssuerDID: "did:key:zUniversity"}, base)
+
+	byType := wallet.GetCredentialsByType("EducationCredential")
+	if len(byType) != 2 || byType[0].ID != "diploma" || byType[1].ID != "degree" {
+		t.Errorf("Expected [diploma degree], got %v", byType)
+	}
+
+	byIssuer := wallet.GetCredentialsByIssuer("did:key:zUniversity")
+	if len(byIssuer) != 2 || byIssuer[0].ID != "degree" || byIssuer[1].ID != "staff" {
+		t.Errorf("Expected [degree staff], got %v", byIssuer)
+	}
+
+	if creds := wallet.GetCredentialsByType("MembershipCredential"); len(creds) != 0 {
+		t.Errorf("Expected no membership credentials, got %v", creds)
+	}
+
+	// The results are copies
+	byType[0].Type = "Tampered"
+	if c, _ := wallet.GetCredential("diploma"); c.Type != "EducationCredential" {
+		t.Errorf("Expected stored credential to be unchanged, got type %s", c.Type)
+	}
+}
+
+func TestWalletListCredentialsStableOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	wallet, _ := CreateWallet(path, "pass")
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	addCredentialAt(t, wallet, StoredCredential{ID: "old"}, base)
+	addCredentialAt(t, wallet, StoredCredential{ID: "new"}, base.Add(time.Hour))
+	addCredentialAt(t, wallet, StoredCredential{ID: "b-same"}, base)
+	addCredentialAt(t, wallet, StoredCredential{ID: "a-same"}, base)
+
+	want := []string{"new", "a-same", "b-same", "old"}
+	for run := 0; run < 5; run++ {
+		creds := wallet.ListCredentials()
+		for i, c := range creds {
+			if c.ID != want[i] {
+				t.Fatalf("Expected order %v, got %s at position %d", want, c.ID, i)
+			}
+		}
+	}
+}
+
+func TestWalletFindCredentials(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	wallet, _ := CreateWallet(path, "pass")
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	addCredentialAt(t, wallet, StoredCredential{
+		ID: "urn:uuid:passport", Type: "IdentityCredential", IssuerDID: "did:key:z6MkGov",
+		ExpiresAt: now.Add(24 * time.Hour),
+	}, now.Add(-3*time.Hour))
+	addCredentialAt(t, wallet, StoredCredential{
+		ID: "urn:uuid:degree", Type: "EducationCredential", IssuerDID: "did:key:z6MkUni",
+	}, now.Add(-2*time.Hour))
+	addCredentialAt(t, wallet, StoredCredential{
+		ID: "urn:uuid:old-badge", Type: "MembershipCredential", IssuerDID: "did:key:z6MkGov",
+		ExpiresAt: now.Add(-time.Hour),
+	}, now.Add(-time.Hour))
+
+	tests := []struct {
+		name   string
+		filter CredentialFilter
+		want   []string
+	}{
+		{"all", CredentialFilter{}, []string{"urn:uuid:old-badge", "urn:uuid:degree", "urn:uuid:passport"}},
+		{"by type", CredentialFilter{Type: "EducationCredential"}, []string{"urn:uuid:degree"}},
+		{"by issuer", CredentialFilter{IssuerDID: "did:key:z6MkGov"}, []string{"urn:uuid:old-badge", "urn:uuid:passport"}},
+		{"not expired", CredentialFilter{ExcludeExpired: true, Now: now}, []string{"urn:uuid:degree", "urn:uuid:passport"}},
+		{"query id", CredentialFilter{Query: "PASS"}, []string{"urn:uuid:passport"}},
+		{"query type", CredentialFilter{Query: "membership"}, []string{"urn:uuid:old-badge"}},
+		{"combined", CredentialFilter{IssuerDID: "did:key:z6MkGov", ExcludeExpired: true, Now: now}, []string{"urn:uuid:passport"}},
+		{"no match", CredentialFilter{Type: "EmploymentCredential"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wallet.FindCredentials(tt.filter)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d credentials, got %d", len(tt.want), len(got))
+			}
+			for i, c := range got {
+				if c.ID != tt.want[i] {
+					t.Errorf("Expected %s at position %d, got %s", tt.want[i], i, c.ID)
+				}
+			}
+		})
+	}
+}
+
 func TestWalletRemoveCredential(t *testing.T) {
 	tmpDir := t.TempDir()
 	path := filepath.Join(tmpDir, "wallet.json")
@@ -262,7 +467,7 @@ func TestWalletExport(t *testing.T) {
 	wallet.SetKeys(pub, priv, "did:key:export-test")
 	wallet.AddCredential(StoredCredential{ID: "export-cred"})
 
-	data, err := wallet.Export()
+	data, err := wallet.ExportUnsafePlaintext()
 	if err != nil {
 		t.Fatalf("Failed to export: %v", err)
 	}
@@ -338,6 +543,341 @@ func TestWalletEncryption(t *testing.T) {
 	}
 }
 
+func TestWalletRecordPresentation(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	wallet, _ := CreateWallet(path, "pass")
+
+	// Empty initially
+	if len(wallet.ListPresentations()) != 0 {
+		t.Errorf("Expected 0 presentations, got %d", len(wallet.ListPresentations()))
+	}
+
+	record := PresentationRecord{
+		ID:            "urn:uuid:vp-1",
+		Audience:      "did:key:verifier",
+		CredentialIDs: []string{"urn:uuid:cred-1", "urn:uuid:cred-2"},
+	}
+
+	if err := wallet.RecordPresentation(record); err != nil {
+		t.Fatalf("Failed to record presentation: %v", err)
+	}
+
+	records := wallet.ListPresentations()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 presentation, got %d", len(records))
+	}
+
+	if records[0].ID != record.ID {
+		t.Errorf("Expected ID %s, got %s", record.ID, records[0].ID)
+	}
+
+	if records[0].Audience != record.Audience {
+		t.Errorf("Expected audience %s, got %s", record.Audience, records[0].Audience)
+	}
+
+	if len(records[0].CredentialIDs) != 2 {
+		t.Errorf("Expected 2 credential IDs, got %d", len(records[0].CredentialIDs))
+	}
+
+	if records[0].PresentedAt.IsZero() {
+		t.Error("PresentedAt should be set")
+	}
+}
+
+func TestWalletListPresentationsPersistence(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+	pass := "historytest"
+
+	w1, _ := CreateWallet(path, pass)
+	w1.RecordPresentation(PresentationRecord{ID: "vp-1", Audience: "aud-1"})
+	w1.RecordPresentation(PresentationRecord{ID: "vp-2", Audience: "aud-2"})
+
+	w2, err := OpenWallet(path, pass)
+	if err != nil {
+		t.Fatalf("Failed to reopen wallet: %v", err)
+	}
+
+	records := w2.ListPresentations()
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 presentations, got %d", len(records))
+	}
+
+	// History is kept in the order presentations were made
+	if records[0].ID != "vp-1" || records[1].ID != "vp-2" {
+		t.Errorf("Unexpected presentation order: %s, %s", records[0].ID, records[1].ID)
+	}
+}
+
+func TestWalletQRPresentation(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	wallet, _ := CreateWallet(path, "pass")
+	pub, priv := generateTestKeypair(t)
+	wallet.SetKeys(pub, priv, "did:key:qr-holder")
+	wallet.AddCredential(StoredCredential{ID: "qr-cred", Token: "v4.public.qr-credential-token"})
+
+	payload, err := wallet.QRPresentation("qr-cred", "did:key:verifier", "qr-nonce")
+	if err != nil {
+		t.Fatalf("Failed to create QR presentation: %v", err)
+	}
+
+	if len(payload) > presentation.MaxQRAlphanumericLength {
+		t.Errorf("Payload length %d exceeds QR capacity", len(payload))
+	}
+
+	token, err := presentation.DecodeCompact(payload)
+	if err != nil {
+		t.Fatalf("Failed to decode QR payload: %v", err)
+	}
+
+	claims, err := presentation.VerifyPresentation(token, pub, "did:key:verifier", "qr-nonce")
+	if err != nil {
+		t.Fatalf("Failed to verify decoded presentation: %v", err)
+	}
+
+	if claims.VP.Holder != "did:key:qr-holder" {
+		t.Errorf("Expected holder did:key:qr-holder, got %s", claims.VP.Holder)
+	}
+
+	if len(claims.VP.VerifiableCredential) != 1 || claims.VP.VerifiableCredential[0] != "v4.public.qr-credential-token" {
+		t.Errorf("Unexpected embedded credentials: %v", claims.VP.VerifiableCredential)
+	}
+}
+
+func TestWalletQRPresentationErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	wallet, _ := CreateWallet(path, "pass")
+	wallet.AddCredential(StoredCredential{ID: "qr-cred", Token: "v4.public.token"})
+
+	// No keys stored yet
+	if _, err := wallet.QRPresentation("qr-cred", "aud", "nonce"); err == nil {
+		t.Error("Expected error when wallet has no keys")
+	}
+
+	pub, priv := generateTestKeypair(t)
+	wallet.SetKeys(pub, priv, "did:key:holder")
+
+	if _, err := wallet.QRPresentation("missing", "aud", "nonce"); err == nil {
+		t.Error("Expected error for unknown credential")
+	}
+}
+
+// writeLegacyWallet writes a wallet in the pre-Argon2id on-disk format
+func writeLegacyWallet(t *testing.T, path, passphrase string, data *WalletData) {
+	writePBKDF2Wallet(t, path, passphrase, 0, data)
+}
+
+// writePBKDF2Wallet writes a PBKDF2 wallet, recording iterations in the
+// header unless it is zero
+func writePBKDF2Wallet(t *testing.T, path, passphrase string, iterations int, data *WalletData) {
+	plaintext, _ := json.Marshal(data)
+
+	salt := make([]byte, saltSize)
+	rand.Read(salt)
+	work := iterations
+	if work == 0 {
+		work = pbkdf2Iterations
+	}
+	key := pbkdf2.Key([]byte(passphrase), salt, work, keySize, sha256.New)
+
+	block, _ := aes.NewCipher(key)
+	gcm, _ := cipher.NewGCM(block)
+	nonce := make([]byte, gcm.NonceSize())
+	rand.Read(nonce)
+
+	raw, _ := json.Marshal(encryptedWallet{
+		Iterations: iterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	})
+	if err := os.WriteFile(path, raw, 0600); err != nil {
+		t.Fatalf("Failed to write legacy wallet: %v", err)
+	}
+}
+
+func readHeader(t *testing.T, path string) encryptedWallet {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read wallet file: %v", err)
+	}
+	var ew encryptedWallet
+	if err := json.Unmarshal(raw, &ew); err != nil {
+		t.Fatalf("Failed to parse wallet file: %v", err)
+	}
+	return ew
+}
+
+func TestWalletUsesArgon2id(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	CreateWallet(path, "pass")
+
+	ew := readHeader(t, path)
+	if ew.KDF != KDFArgon2id {
+		t.Errorf("Expected kdf %s, got %q", KDFArgon2id, ew.KDF)
+	}
+	if ew.KDFParams == nil || *ew.KDFParams != DefaultArgon2Params {
+		t.Errorf("Expected default Argon2 params in header, got %+v", ew.KDFParams)
+	}
+}
+
+func TestOpenLegacyPBKDF2Wallet(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+	pass := "legacypass"
+
+	writeLegacyWallet(t, path, pass, &WalletData{
+		Version:     1,
+		DID:         "did:key:legacy",
+		Credentials: map[string]StoredCredential{"old-cred": {ID: "old-cred"}},
+	})
+
+	wallet, err := OpenWallet(path, pass)
+	if err != nil {
+		t.Fatalf("Failed to open legacy wallet: %v", err)
+	}
+	if wallet.GetDID() != "did:key:legacy" {
+		t.Errorf("Expected DID did:key:legacy, got %s", wallet.GetDID())
+	}
+	if !wallet.NeedsKDFMigration() {
+		t.Error("Legacy wallet should need KDF migration")
+	}
+
+	if _, err := OpenWallet(path, "wrongpass"); err != ErrInvalidPassword {
+		t.Errorf("Expected ErrInvalidPassword for legacy wallet, got %v", err)
+	}
+
+	if err := wallet.MigrateKDF(); err != nil {
+		t.Fatalf("Failed to migrate wallet: %v", err)
+	}
+	if wallet.NeedsKDFMigration() {
+		t.Error("Wallet should not need migration after MigrateKDF")
+	}
+	if ew := readHeader(t, path); ew.KDF != KDFArgon2id {
+		t.Errorf("Expected migrated kdf %s, got %q", KDFArgon2id, ew.KDF)
+	}
+
+	migrated, err := OpenWallet(path, pass)
+	if err != nil {
+		t.Fatalf("Failed to open migrated wallet: %v", err)
+	}
+	if _, err := migrated.GetCredential("old-cred"); err != nil {
+		t.Errorf("Credential lost during migration: %v", err)
+	}
+}
+
+func TestOpenPBKDF2WalletWithIterations(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	writePBKDF2Wallet(t, path, "legacypass", 1000, &WalletData{
+		Version:     walletVersion,
+		Credentials: map[string]StoredCredential{},
+	})
+	if _, err := OpenWallet(path, "legacypass"); err != nil {
+		t.Fatalf("Expected the recorded iteration count to be used, got %v", err)
+	}
+}
+
+func TestCreateWalletWithKDFParams(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+	params := Argon2Params{Time: 2, Memory: 16 * 1024, Threads: 2}
+
+	if _, err := CreateWalletWithOptions(path, "testpassword123", WalletOptions{
+		KDFParams: Argon2Params{Time: 1, Memory: 8, Threads: 2},
+	}); err != ErrInvalidKDFParams {
+		t.Errorf("Expected ErrInvalidKDFParams, got %v", err)
+	}
+
+	if _, err := CreateWalletWithOptions(path, "testpassword123", WalletOptions{KDFParams: params}); err != nil {
+		t.Fatalf("CreateWalletWithOptions failed: %v", err)
+	}
+	if ew := readHeader(t, path); ew.KDFParams == nil || *ew.KDFParams != params {
+		t.Errorf("Expected params %+v in header, got %+v", params, ew.KDFParams)
+	}
+
+	// The stored parameters are used to open, and kept on the next save
+	wallet, err := OpenWallet(path, "testpassword123")
+	if err != nil {
+		t.Fatalf("Failed to open wallet: %v", err)
+	}
+	if wallet.KDFParams() != params {
+		t.Errorf("Expected params %+v, got %+v", params, wallet.KDFParams())
+	}
+	if err := wallet.Save(); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	if ew := readHeader(t, path); *ew.KDFParams != params {
+		t.Errorf("Expected params %+v after save, got %+v", params, ew.KDFParams)
+	}
+
+	if err := wallet.SetKDFParams(Argon2Params{}); err != ErrInvalidKDFParams {
+		t.Errorf("Expected ErrInvalidKDFParams, got %v", err)
+	}
+	if err := wallet.SetKDFParams(DefaultArgon2Params); err != nil {
+		t.Fatalf("SetKDFParams failed: %v", err)
+	}
+	if ew := readHeader(t, path); *ew.KDFParams != DefaultArgon2Params {
+		t.Errorf("Expected params %+v after SetKDFParams, got %+v", DefaultArgon2Params, ew.KDFParams)
+	}
+	if _, err := OpenWallet(path, "testpassword123"); err != nil {
+		t.Errorf("Failed to open re-encrypted wallet: %v", err)
+	}
+}
+
+func TestOpenWalletUnsupportedKDF(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	os.WriteFile(path, []byte(`{"kdf":"scrypt","salt":"","nonce":"","ciphertext":""}`), 0600)
+	if _, err := OpenWallet(path, "pass"); err != ErrUnsupportedKDF {
+		t.Errorf("Expected ErrUnsupportedKDF, got %v", err)
+	}
+
+	os.WriteFile(path, []byte(`{"kdf":"argon2id","kdfParams":{"time":1,"memory":8192,"threads":0},"salt":"","nonce":"","ciphertext":""}`), 0600)
+	if _, err := OpenWallet(path, "pass"); err != ErrInvalidKDFParams {
+		t.Errorf("Expected ErrInvalidKDFParams, got %v", err)
+	}
+}
+
+func TestOpenWalletKDFParamsBounded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallet.json")
+
+	// A tampered header must not make opening the wallet derive a key at
+	// any cost it names; each is rejected before Argon2id runs
+	tests := []struct {
+		name   string
+		params string
+	}{
+		{"too many passes", `{"time":4294967295,"memory":65536,"threads":4}`},
+		{"too much memory", `{"time":1,"memory":4294967295,"threads":4}`},
+		{"too many threads", `{"time":1,"memory":65536,"threads":255}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.WriteFile(path, []byte(`{"kdf":"argon2id","kdfParams":`+tt.params+`,"salt":"","nonce":"","ciphertext":""}`), 0600)
+			if _, err := OpenWallet(path, "pass"); err != ErrInvalidKDFParams {
+				t.Errorf("Expected ErrInvalidKDFParams, got %v", err)
+			}
+		})
+	}
+
+	if _, err := CreateWalletWithOptions(path+".new", "pass", WalletOptions{
+		KDFParams: Argon2Params{Time: 1, Memory: maxArgon2Memory + 1, Threads: 4},
+	}); err != ErrInvalidKDFParams {
+		t.Errorf("Expected ErrInvalidKDFParams creating a wallet over the cap, got %v", err)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
 }
@@ -350,3 +890,356 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestWalletMultipleIdentities(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	wallet, _ := CreateWallet(path, "pass")
+	personalPub, personalPriv := generateTestKeypair(t)
+	workPub, workPriv := generateTestKeypair(t)
+
+	if err := wallet.AddIdentity("personal", personalPub, personalPriv, "did:key:z6MkPersonal"); err != nil {
+		t.Fatalf("Failed to add personal identity: %v", err)
+	}
+	if err := wallet.AddIdentity("work", workPub, workPriv, "did:key:z6MkWork"); err != nil {
+		t.Fatalf("Failed to add work identity: %v", err)
+	}
+	if err := wallet.AddIdentity("work", workPub, workPriv, "did:key:z6MkWork"); err != ErrIdentityExists {
+		t.Errorf("Expected ErrIdentityExists, got %v", err)
+	}
+
+	// The first identity is the default
+	if wallet.GetDID() != "did:key:z6MkPersonal" {
+		t.Errorf("Expected default DID did:key:z6MkPersonal, got %s", wallet.GetDID())
+	}
+
+	if err := wallet.SetDefaultIdentity("work"); err != nil {
+		t.Fatalf("Failed to set default identity: %v", err)
+	}
+	if err := wallet.SetDefaultIdentity("missing"); err != ErrIdentityNotFound {
+		t.Errorf("Expected ErrIdentityNotFound, got %v", err)
+	}
+
+	reopened, err := OpenWallet(path, "pass")
+	if err != nil {
+		t.Fatalf("Failed to reopen wallet: %v", err)
+	}
+
+	ids, err := reopened.ListIdentities()
+	if err != nil {
+		t.Fatalf("Failed to list identities: %v", err)
+	}
+	if len(ids) != 2 || ids[0].Label != "personal" || ids[1].Label != "work" {
+		t.Fatalf("Expected identities [personal work], got %v", ids)
+	}
+	gotPub, _, err := reopened.GetKeys()
+	if err != nil {
+		t.Fatalf("Failed to get keys: %v", err)
+	}
+	if !workPub.Equal(gotPub) {
+		t.Error("Expected GetKeys to return the work identity keys")
+	}
+}
+
+func TestWalletRotateKey(t *testing.T) {
+	store := NewMemoryStore()
+	wallet, _ := CreateWalletWithStore(store, "passphrase", WalletOptions{})
+	if _, err := wallet.RotateKey(); err != ErrNoKeys {
+		t.Errorf("Expected ErrNoKeys without keys, got %v", err)
+	}
+
+	oldPub, oldPriv := generateTestKeypair(t)
+	wallet.SetKeys(oldPub, oldPriv, "did:key:zOld")
+	wallet.AddCredential(StoredCredential{ID: "cred-1", Token: "token"})
+
+	newDID, err := wallet.RotateKey()
+	if err != nil {
+		t.Fatalf("RotateKey failed: %v", err)
+	}
+	if newDID == "did:key:zOld" || !strings.HasPrefix(newDID, "did:key:z") {
+		t.Errorf("Expected a new did:key, got %s", newDID)
+	}
+	if wallet.GetDID() != newDID {
+		t.Errorf("Expected GetDID %s, got %s", newDID, wallet.GetDID())
+	}
+	pub, _, _ := wallet.GetKeys()
+	if pub.Equal(oldPub) {
+		t.Error("Expected GetKeys to return the new key")
+	}
+
+	// Reopened from the store, the retired key is still there
+	reopened, err := OpenWalletWithStore(store, "passphrase")
+	if err != nil {
+		t.Fatalf("OpenWalletWithStore failed: %v", err)
+	}
+	retiredPub, retiredPriv, err := reopened.GetKeyForDID("did:key:zOld")
+	if err != nil {
+		t.Fatalf("GetKeyForDID failed for the old DID: %v", err)
+	}
+	if !retiredPub.Equal(oldPub) || !retiredPriv.Equal(oldPriv) {
+		t.Error("Expected the retired key pair for the old DID")
+	}
+	if current, _, err := reopened.GetKeyForDID(newDID); err != nil || !current.Equal(pub) {
+		t.Errorf("Expected the current key for the new DID, got %v", err)
+	}
+	if _, _, err := reopened.GetKeyForDID("did:key:zUnknown"); err != ErrKeyNotFound {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+	if _, err := reopened.GetCredential("cred-1"); err != nil {
+		t.Errorf("Expected credentials to be kept, got %v", err)
+	}
+
+	// Rotating again retires the second key too, oldest first
+	if _, err := reopened.RotateKey(); err != nil {
+		t.Fatalf("Second RotateKey failed: %v", err)
+	}
+	id, _ := reopened.GetIdentity(DefaultIdentityLabel)
+	if len(id.PreviousKeys) != 2 || id.PreviousKeys[0].DID != "did:key:zOld" || id.PreviousKeys[1].DID != newDID {
+		t.Errorf("Expected previous keys [did:key:zOld %s], got %+v", newDID, id.PreviousKeys)
+	}
+
+	reopened.Lock()
+	if !bytes.Equal(retiredPriv, make([]byte, len(retiredPriv))) {
+		t.Error("Expected Lock to wipe retired private keys")
+	}
+	if _, _, err := reopened.GetKeyForDID("did:key:zOld"); err != ErrWalletLocked {
+		t.Errorf("Expected ErrWalletLocked, got %v", err)
+	}
+	if _, err := reopened.RotateKey(); err != ErrWalletLocked {
+		t.Errorf("Expected ErrWalletLocked, got %v", err)
+	}
+}
+
+func TestWalletCredentialsForIdentity(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	wallet, _ := CreateWallet(path, "pass")
+	pub, priv := generateTestKeypair(t)
+	wallet.AddIdentity("personal", pub, priv, "did:key:z6MkPersonal")
+	wallet.AddIdentity("work", pub, priv, "did:key:z6MkWork")
+
+	wallet.AddCredential(StoredCredential{ID: "unlabeled"})
+	wallet.AddCredential(StoredCredential{ID: "badge", Identity: "work"})
+
+	if err := wallet.AddCredential(StoredCredential{ID: "orphan", Identity: "missing"}); err != ErrIdentityNotFound {
+		t.Errorf("Expected ErrIdentityNotFound, got %v", err)
+	}
+
+	personal := wallet.ListCredentialsForIdentity("personal")
+	if len(personal) != 1 || personal[0].ID != "unlabeled" {
+		t.Errorf("Expected unlabeled credential to belong to the default identity, got %v", personal)
+	}
+	work := wallet.ListCredentialsForIdentity("work")
+	if len(work) != 1 || work[0].ID != "badge" {
+		t.Errorf("Expected badge credential for work identity, got %v", work)
+	}
+}
+
+func TestOpenV1SingleIdentityWallet(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+	pub, priv := generateTestKeypair(t)
+
+	writeLegacyWallet(t, path, "pass", &WalletData{
+		Version:     1,
+		DID:         "did:key:z6MkLegacy",
+		Keys:        &KeyPair{PublicKey: pub, PrivateKey: priv},
+		Credentials: map[string]StoredCredential{},
+	})
+
+	wallet, err := OpenWallet(path, "pass")
+	if err != nil {
+		t.Fatalf("Failed to open v1 wallet: %v", err)
+	}
+
+	ids, err := wallet.ListIdentities()
+	if err != nil {
+		t.Fatalf("Failed to list identities: %v", err)
+	}
+	if len(ids) != 1 || ids[0].Label != DefaultIdentityLabel {
+		t.Fatalf("Expected a single %q identity, got %v", DefaultIdentityLabel, ids)
+	}
+	gotPub, _, err := wallet.GetKeys()
+	if err != nil || !pub.Equal(gotPub) {
+		t.Errorf("Expected migrated keys, got err %v", err)
+	}
+
+	// The next save writes the version 2 layout
+	if err := wallet.Save(); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	exported, _ := wallet.ExportUnsafePlaintext()
+	var data map[string]interface{}
+	json.Unmarshal(exported, &data)
+	if _, ok := data["keys"]; ok {
+		t.Error("Expected top-level keys to be removed after migration")
+	}
+	if data["version"] != float64(walletVersion) {
+		t.Errorf("Expected version %d, got %v", walletVersion, data["version"])
+	}
+}
+
+func TestWalletChangePassphrase(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	wallet, _ := CreateWallet(path, "old-passphrase")
+	wallet.AddCredential(StoredCredential{ID: "cred-1"})
+	stored, _ := wallet.GetCredential("cred-1")
+	saltBefore := readHeader(t, path).Salt
+
+	if err := wallet.ChangePassphrase("wrong-passphrase", "new-passphrase"); err != ErrInvalidPassword {
+		t.Errorf("Expected ErrInvalidPassword for wrong old passphrase, got %v", err)
+	}
+	if err := wallet.ChangePassphrase("old-passphrase", "short"); err != ErrPassphraseLength {
+		t.Errorf("Expected ErrPassphraseLength, got %v", err)
+	}
+	if _, err := OpenWallet(path, "old-passphrase"); err != nil {
+		t.Fatalf("Rejected changes must leave the old passphrase working: %v", err)
+	}
+
+	if err := wallet.ChangePassphrase("old-passphrase", "new-passphrase"); err != nil {
+		t.Fatalf("ChangePassphrase failed: %v", err)
+	}
+
+	if _, err := OpenWallet(path, "old-passphrase"); err != ErrInvalidPassword {
+		t.Errorf("Expected old passphrase to be rejected, got %v", err)
+	}
+	reopened, err := OpenWallet(path, "new-passphrase")
+	if err != nil {
+		t.Fatalf("Failed to open with new passphrase: %v", err)
+	}
+
+	cred, err := reopened.GetCredential("cred-1")
+	if err != nil {
+		t.Fatalf("Credential lost: %v", err)
+	}
+	if !cred.StoredAt.Equal(stored.StoredAt) {
+		t.Errorf("Expected StoredAt %v to be preserved, got %v", stored.StoredAt, cred.StoredAt)
+	}
+	if string(readHeader(t, path).Salt) == string(saltBefore) {
+		t.Error("Expected a fresh salt after changing passphrase")
+	}
+}
+
+func TestWalletPruneExpired(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	wallet, _ := CreateWallet(path, "pass")
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	wallet.AddCredential(StoredCredential{ID: "expired", Type: "TestCredential", ExpiresAt: now.Add(-time.Hour)})
+	wallet.AddCredential(StoredCredential{ID: "expires-now", Type: "TestCredential", ExpiresAt: now})
+	wallet.AddCredential(StoredCredential{ID: "valid", Type: "TestCredential", ExpiresAt: now.Add(time.Hour)})
+	// Older entries have no expiration and are never pruned
+	wallet.AddCredential(StoredCredential{ID: "no-expiry", Type: "TestCredential"})
+
+	expired := wallet.ListExpired(now)
+	if len(expired) != 2 {
+		t.Fatalf("Expected 2 expired credentials, got %d", len(expired))
+	}
+	if len(wallet.ListCredentials()) != 4 {
+		t.Error("Expected ListExpired not to remove anything")
+	}
+
+	removed, err := wallet.PruneExpired(now)
+	if err != nil {
+		t.Fatalf("Failed to prune: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Expected 2 credentials removed, got %d", removed)
+	}
+
+	// The removal is persisted
+	reopened, err := OpenWallet(path, "pass")
+	if err != nil {
+		t.Fatalf("Failed to reopen wallet: %v", err)
+	}
+	for _, id := range []string{"expired", "expires-now"} {
+		if _, err := reopened.GetCredential(id); err == nil {
+			t.Errorf("Expected %s to be pruned", id)
+		}
+	}
+	for _, id := range []string{"valid", "no-expiry"} {
+		if _, err := reopened.GetCredential(id); err != nil {
+			t.Errorf("Expected %s to be kept, got %v", id, err)
+		}
+	}
+
+	removed, err = reopened.PruneExpired(now)
+	if err != nil || removed != 0 {
+		t.Errorf("Expected nothing left to prune, got %d, %v", removed, err)
+	}
+}
+
+func TestWalletLock(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "wallet.json")
+
+	created, _ := CreateWallet(path, "testpassword123")
+	pub, priv := generateTestKeypair(t)
+	original := make(ed25519.PrivateKey, len(priv))
+	copy(original, priv)
+	if err := created.SetKeys(pub, priv, "did:key:z6MkTest"); err != nil {
+		t.Fatalf("Failed to set keys: %v", err)
+	}
+
+	wallet, err := OpenWallet(path, "testpassword123")
+	if err != nil {
+		t.Fatalf("Failed to open wallet: %v", err)
+	}
+	_, gotPriv, err := wallet.GetKeys()
+	if err != nil {
+		t.Fatalf("Failed to get keys: %v", err)
+	}
+
+	wallet.Lock()
+	if !wallet.Locked() {
+		t.Error("Expected wallet to report locked")
+	}
+	for i, b := range gotPriv {
+		if b != 0 {
+			t.Fatalf("Expected private key to be zeroed, got byte %d = %#x", i, b)
+		}
+	}
+	if len(wallet.passphrase) != 0 {
+		t.Error("Expected passphrase to be cleared")
+	}
+
+	if _, _, err := wallet.GetKeys(); err != ErrWalletLocked {
+		t.Errorf("Expected ErrWalletLocked from GetKeys, got %v", err)
+	}
+	if err := wallet.Save(); err != ErrWalletLocked {
+		t.Errorf("Expected ErrWalletLocked from Save, got %v", err)
+	}
+	if _, err := wallet.ExportEncrypted("backup-password"); err != ErrWalletLocked {
+		t.Errorf("Expected ErrWalletLocked from ExportEncrypted, got %v", err)
+	}
+	if _, err := wallet.ExportUnsafePlaintext(); err != ErrWalletLocked {
+		t.Errorf("Expected ErrWalletLocked from ExportUnsafePlaintext, got %v", err)
+	}
+	if _, err := wallet.GetIdentity(DefaultIdentityLabel); err != ErrWalletLocked {
+		t.Errorf("Expected ErrWalletLocked from GetIdentity, got %v", err)
+	}
+	if _, err := wallet.ListIdentities(); err != ErrWalletLocked {
+		t.Errorf("Expected ErrWalletLocked from ListIdentities, got %v", err)
+	}
+	if wallet.GetDID() != "did:key:z6MkTest" {
+		t.Errorf("Expected DID to stay readable, got %s", wallet.GetDID())
+	}
+
+	// The file was not overwritten, so reopening restores the keys
+	reopened, err := OpenWallet(path, "testpassword123")
+	if err != nil {
+		t.Fatalf("Failed to reopen wallet: %v", err)
+	}
+	_, reopenedPriv, err := reopened.GetKeys()
+	if err != nil {
+		t.Fatalf("Failed to get keys: %v", err)
+	}
+	if !original.Equal(reopenedPriv) {
+		t.Error("Expected reopened wallet to have the original private key")
+	}
+}
@@ -0,0 +1,243 @@
+package vc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidCSVHeader = errors.New("CSV header must name an id column")
+)
+
+// CSVListSeparator separates the items of a list field (e.g. roles) in one CSV cell
+const CSVListSeparator = ";"
+
+// Registrar records issued credentials for revocation tracking.
+// *revocation.Registry implements it.
+type Registrar interface {
+	Register(credentialID, issuerDID, subjectDID string) error
+}
+
+// CSVOptions configures batch issuance from CSV
+type CSVOptions struct {
+	// Issue carries the validity options applied to every credential; its
+	// CredentialID is ignored since each row gets a fresh one
+	Issue IssueOptions
+	// Registry, when set, registers each issued credential
+	Registry Registrar
+}
+
+// IssuedCredential is a credential issued from one CSV row
+type IssuedCredential struct {
+	Line         int // line of the row in the CSV input
+	SubjectDID   string
+	CredentialID string
+	Token        string
+}
+
+// CSVRowError is the failure of a single CSV row
+type CSVRowError struct {
+	Line int
+	Err  error
+}
+
+func (e *CSVRowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *CSVRowError) Unwrap() error {
+	return e.Err
+}
+
+// IssueFromCSV issues one credential of a registered type per CSV row. The
+// header row names the subject fields by their JSON names and must include
+// "id", the subject DID. Empty cells are omitted, and list fields split on
+// CSVListSeparator. A bad row is reported as a *CSVRowError and does not stop
+// the remaining rows.
+func IssueFromCSV(
+	reader io.Reader,
+	typeName string,
+	issuerDID string,
+	key ed25519.PrivateKey,
+	opts CSVOptions,
+) ([]IssuedCredential, []error) {
+	factory, ok := LookupSubjectType(typeName)
+	if !ok {
+		return nil, []error{fmt.Errorf("%w: %s", ErrUnknownCredentialType, typeName)}
+	}
+	fieldTypes := subjectFieldTypes(factory)
+
+	r := csv.NewReader(reader)
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, []error{fmt.Errorf("%w: %v", ErrInvalidCSVHeader, err)}
+	}
+	idColumn := -1
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+		if header[i] == "id" {
+			idColumn = i
+		}
+	}
+	if idColumn < 0 {
+		return nil, []error{ErrInvalidCSVHeader}
+	}
+
+	var issued []IssuedCredential
+	var errs []error
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				// The input itself failed; no further rows can be read
+				errs = append(errs, err)
+				break
+			}
+			errs = append(errs, &CSVRowError{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		}
+		line, _ := r.FieldPos(0)
+
+		cred, err := issueCSVRow(header, record, fieldTypes, typeName, issuerDID, key, opts)
+		if err != nil {
+			errs = append(errs, &CSVRowError{Line: line, Err: err})
+			continue
+		}
+		cred.Line = line
+		issued = append(issued, *cred)
+	}
+
+	return issued, errs
+}
+
+func issueCSVRow(
+	header, record []string,
+	fieldTypes map[string]reflect.Type,
+	typeName, issuerDID string,
+	key ed25519.PrivateKey,
+	opts CSVOptions,
+) (*IssuedCredential, error) {
+	fields := make(map[string]interface{}, len(header))
+	for i, name := range header {
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			continue
+		}
+		converted, err := convertCSVValue(value, fieldTypes[name])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidSubjectFields, name, err)
+		}
+		fields[name] = converted
+	}
+
+	subjectDID, _ := fields["id"].(string)
+	if subjectDID == "" {
+		return nil, fmt.Errorf("%w: id", ErrMissingRequiredField)
+	}
+
+	credID, err := generateCredentialID()
+	if err != nil {
+		return nil, err
+	}
+	issueOpts := opts.Issue
+	issueOpts.CredentialID = credID
+
+	token, err := IssueTyped(typeName, fields, issuerDID, subjectDID, key, issueOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Registry != nil {
+		if err := opts.Registry.Register(credID, issuerDID, subjectDID); err != nil {
+			return nil, fmt.Errorf("registering credential: %w", err)
+		}
+	}
+
+	return &IssuedCredential{SubjectDID: subjectDID, CredentialID: credID, Token: token}, nil
+}
+
+// subjectFieldTypes maps a subject type's JSON field names to their Go types
+func subjectFieldTypes(factory SubjectFactory) map[string]reflect.Type {
+	types := make(map[string]reflect.Type)
+
+	t := reflect.TypeOf(factory())
+	if t == nil {
+		return types
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return types
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n, _, _ := strings.Cut(tag, ","); n != "" {
+				name = n
+			}
+		}
+		types[name] = f.Type
+	}
+	return types
+}
+
+// convertCSVValue parses a cell into the kind its subject field expects.
+// Unknown columns stay strings and are rejected by NewSubjectFromMap.
+func convertCSVValue(value string, t reflect.Type) (interface{}, error) {
+	if t == nil {
+		return value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(value, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(value, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(value, 64)
+	case reflect.Slice:
+		items := strings.Split(value, CSVListSeparator)
+		for i := range items {
+			items[i] = strings.TrimSpace(items[i])
+		}
+		return items, nil
+	default:
+		return value, nil
+	}
+}
+
+// generateCredentialID creates a urn:uuid credential ID in the same form as
+// revocation.GenerateCredentialID
+func generateCredentialID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return "urn:uuid:" + hex.EncodeToString(bytes[:4]) + "-" +
+		hex.EncodeToString(bytes[4:6]) + "-" +
+		hex.EncodeToString(bytes[6:8]) + "-" +
+		hex.EncodeToString(bytes[8:10]) + "-" +
+		hex.EncodeToString(bytes[10:]), nil
+}
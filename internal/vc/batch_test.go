@@ -0,0 +1,94 @@
+package vc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type recordingRegistrar struct {
+	registered map[string]string // credential ID -> subject DID
+}
+
+func (r *recordingRegistrar) Register(credentialID, issuerDID, subjectDID string) error {
+	r.registered[credentialID] = subjectDID
+	return nil
+}
+
+func TestIssueFromCSV(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	registry := &recordingRegistrar{registered: make(map[string]string)}
+
+	input := strings.Join([]string{
+		"id,organizationName,startDate,roles,activeMember",
+		"did:key:zAlice,PDA,2024-01-01,member;speaker,true",
+		"did:key:zBob,PDA,2024-02-01,,false",
+		"did:key:zCarol,PDA",                        // wrong column count
+		"did:key:zDave,PDA,2024-03-01,member,maybe", // not a bool
+		",PDA,2024-03-01,member,true",               // no subject DID
+		"did:key:zErin,PDA,2024-04-01,member,true",
+	}, "\n")
+
+	issued, errs := IssueFromCSV(strings.NewReader(input), CredentialTypeMembership, "did:key:zIssuer", issuerPriv, CSVOptions{Registry: registry})
+
+	if len(issued) != 3 {
+		t.Fatalf("Expected 3 issued credentials, got %d", len(issued))
+	}
+	if len(errs) != 3 {
+		t.Fatalf("Expected 3 row errors, got %d: %v", len(errs), errs)
+	}
+
+	wantLines := []int{4, 5, 6}
+	for i, err := range errs {
+		var rowErr *CSVRowError
+		if !errors.As(err, &rowErr) {
+			t.Fatalf("Expected *CSVRowError, got %T", err)
+		}
+		if rowErr.Line != wantLines[i] {
+			t.Errorf("Expected error on line %d, got line %d", wantLines[i], rowErr.Line)
+		}
+	}
+	if !errors.Is(errs[1], ErrInvalidSubjectFields) {
+		t.Errorf("Expected ErrInvalidSubjectFields for a bad bool, got %v", errs[1])
+	}
+	if !errors.Is(errs[2], ErrMissingRequiredField) {
+		t.Errorf("Expected ErrMissingRequiredField for a missing id, got %v", errs[2])
+	}
+
+	for _, cred := range issued {
+		if registry.registered[cred.CredentialID] != cred.SubjectDID {
+			t.Errorf("Expected %s to be registered for %s", cred.CredentialID, cred.SubjectDID)
+		}
+
+		claims, err := VerifyVC(cred.Token, issuerPub)
+		if err != nil {
+			t.Fatalf("VerifyVC failed: %v", err)
+		}
+		if claims.Subject != cred.SubjectDID || claims.GetCredentialID() != cred.CredentialID {
+			t.Errorf("Expected claims for %s/%s, got %s/%s", cred.SubjectDID, cred.CredentialID, claims.Subject, claims.GetCredentialID())
+		}
+	}
+
+	claims, _ := VerifyVC(issued[0].Token, issuerPub)
+	subject, _ := claims.TypedSubject()
+	membership := subject.(*MembershipSubject)
+	if len(membership.Roles) != 2 || membership.Roles[1] != "speaker" || !membership.ActiveMember {
+		t.Errorf("Expected roles [member speaker] and active member, got %v / %v", membership.Roles, membership.ActiveMember)
+	}
+}
+
+func TestIssueFromCSVInvalidInput(t *testing.T) {
+	_, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	_, errs := IssueFromCSV(strings.NewReader("name,startDate\nAlice,2024-01-01\n"), CredentialTypeMembership, "did:key:zIssuer", issuerPriv, CSVOptions{})
+	if len(errs) != 1 || !errors.Is(errs[0], ErrInvalidCSVHeader) {
+		t.Errorf("Expected ErrInvalidCSVHeader, got %v", errs)
+	}
+
+	_, errs = IssueFromCSV(strings.NewReader("id\ndid:key:zAlice\n"), "UnregisteredCredential", "did:key:zIssuer", issuerPriv, CSVOptions{})
+	if len(errs) != 1 || !errors.Is(errs[0], ErrUnknownCredentialType) {
+		t.Errorf("Expected ErrUnknownCredentialType, got %v", errs)
+	}
+}
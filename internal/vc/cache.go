@@ -0,0 +1,119 @@
+package vc
+
+import (
+	"container/list"
+	"crypto/ed25519"
+	"sync"
+	"time"
+)
+
+// DefaultVerifierCacheSize is the number of credentials a CachedVerifier
+// holds when NewCachedVerifier is given a size that is not positive
+const DefaultVerifierCacheSize = 1024
+
+// CachedVerifier verifies credentials like VerifyVC, remembering the claims
+// of the ones that verified so that checking the same token against the same
+// key again skips the signature check, e.g. in a gateway that sees one
+// long-lived credential many times a minute. It is a least-recently-used
+// cache and is safe for concurrent use.
+type CachedVerifier struct {
+	mu      sync.Mutex
+	size    int
+	now     func() time.Time
+	order   *list.List // front is most recently used
+	entries map[verifierKey]*list.Element
+}
+
+// verifierKey identifies a token verified against a key
+type verifierKey struct {
+	token string
+	key   string
+}
+
+// verifiedEntry is a verified credential and when it stops being valid
+type verifiedEntry struct {
+	key       verifierKey
+	claims    *VCClaims
+	expiresAt time.Time
+}
+
+// NewCachedVerifier creates a verifier that caches the claims of up to size
+// verified credentials, evicting the least recently used. A credential is
+// cached until its own exp, plus DefaultLeeway as VerifyVC allows; failed
+// verifications are never cached.
+func NewCachedVerifier(size int) *CachedVerifier {
+	if size <= 0 {
+		size = DefaultVerifierCacheSize
+	}
+	return &CachedVerifier{
+		size:    size,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[verifierKey]*list.Element),
+	}
+}
+
+// Verify verifies a PASETO v4 public token like VerifyVC. A cached
+// credential is checked against the clock again and returns
+// ErrCredentialExpired once past its exp. The returned claims are shared
+// with later calls for the same token and must not be modified.
+func (v *CachedVerifier) Verify(tokenString string, publicKey ed25519.PublicKey) (*VCClaims, error) {
+	key := verifierKey{token: tokenString, key: string(publicKey)}
+	if claims, ok, err := v.get(key); ok {
+		return claims, err
+	}
+
+	claims, err := VerifyVC(tokenString, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	v.put(key, claims)
+	return claims, nil
+}
+
+// get returns the cached claims for key, dropping them with
+// ErrCredentialExpired if the credential has expired since it was cached
+func (v *CachedVerifier) get(key verifierKey) (*VCClaims, bool, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	elem, ok := v.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*verifiedEntry)
+	if !v.now().Before(entry.expiresAt) {
+		v.order.Remove(elem)
+		delete(v.entries, key)
+		return nil, true, credentialError(ErrTokenExpired)
+	}
+	v.order.MoveToFront(elem)
+	return entry.claims, true, nil
+}
+
+// put caches verified claims, evicting the least recently used when full
+func (v *CachedVerifier) put(key verifierKey, claims *VCClaims) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	entry := &verifiedEntry{key: key, claims: claims, expiresAt: claims.ExpiresAt.Add(DefaultLeeway)}
+	if elem, ok := v.entries[key]; ok {
+		elem.Value = entry
+		v.order.MoveToFront(elem)
+		return
+	}
+
+	v.entries[key] = v.order.PushFront(entry)
+	if v.order.Len() > v.size {
+		oldest := v.order.Back()
+		v.order.Remove(oldest)
+		delete(v.entries, oldest.Value.(*verifiedEntry).key)
+	}
+}
+
+// len returns the number of cached credentials, including expired ones not yet dropped
+func (v *CachedVerifier) len() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.order.Len()
+}
@@ -0,0 +1,103 @@
+package vc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+)
+
+func issueCacheTestVC(tb testing.TB, priv ed25519.PrivateKey, subject string, lifetime time.Duration) string {
+	tb.Helper()
+	token, err := IssueVCWithOptions("did:key:zIssuer", subject, priv,
+		testIdentitySubject(subject), IssueOptions{Lifetime: lifetime})
+	if err != nil {
+		tb.Fatalf("IssueVCWithOptions failed: %v", err)
+	}
+	return token
+}
+
+func TestCachedVerifier(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	token := issueCacheTestVC(t, priv, "did:key:zAlice", time.Hour)
+
+	v := NewCachedVerifier(2)
+	first, err := v.Verify(token, pub)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	second, err := v.Verify(token, pub)
+	if err != nil {
+		t.Fatalf("Verify failed on a cache hit: %v", err)
+	}
+	if second != first {
+		t.Error("Expected the cached claims on the second call")
+	}
+
+	// The key is part of the cache key, so a wrong key is not served the claims
+	if _, err := v.Verify(token, otherPub); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid, got %v", err)
+	}
+	if v.len() != 1 {
+		t.Errorf("Expected 1 cached credential, got %d", v.len())
+	}
+
+	// Least recently used is evicted
+	v.Verify(issueCacheTestVC(t, priv, "did:key:zBob", time.Hour), pub)
+	v.Verify(token, pub)
+	v.Verify(issueCacheTestVC(t, priv, "did:key:zCarol", time.Hour), pub)
+	if v.len() != 2 {
+		t.Errorf("Expected 2 cached credentials, got %d", v.len())
+	}
+	if _, ok, _ := v.get(verifierKey{token: token, key: string(pub)}); !ok {
+		t.Error("Expected the recently used credential to stay cached")
+	}
+}
+
+func TestCachedVerifierExpiry(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	token := issueCacheTestVC(t, priv, "did:key:zAlice", 10*time.Minute)
+
+	v := NewCachedVerifier(0)
+	claims, err := v.Verify(token, pub)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	// Within the leeway the cached credential is still served
+	v.now = func() time.Time { return claims.ExpiresAt.Add(DefaultLeeway / 2) }
+	if _, err := v.Verify(token, pub); err != nil {
+		t.Errorf("Expected the credential within leeway, got %v", err)
+	}
+
+	v.now = func() time.Time { return claims.ExpiresAt.Add(DefaultLeeway) }
+	if _, err := v.Verify(token, pub); !errors.Is(err, ErrCredentialExpired) {
+		t.Errorf("Expected ErrCredentialExpired, got %v", err)
+	}
+	if v.len() != 0 {
+		t.Errorf("Expected the expired credential to be dropped, got %d cached", v.len())
+	}
+}
+
+func BenchmarkVerifyVC(b *testing.B) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	token := issueCacheTestVC(b, priv, "did:key:zAlice", time.Hour)
+
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := VerifyVC(token, pub); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		v := NewCachedVerifier(0)
+		for i := 0; i < b.N; i++ {
+			if _, err := v.Verify(token, pub); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
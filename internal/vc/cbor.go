@@ -0,0 +1,276 @@
+package vc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+var (
+	ErrUnsupportedEncoding = errors.New("unsupported claim encoding")
+	ErrMalformedCBORClaim  = errors.New("malformed CBOR claim")
+)
+
+// Encoding is how the vc and vp claims are serialized inside a token
+type Encoding string
+
+// Claim encodings
+const (
+	// EncodingJSON writes the claim as a JSON object. It is the default.
+	EncodingJSON Encoding = "json"
+	// EncodingCBOR writes the claim as a base64url string holding its CBOR
+	// encoding, with well-known member names replaced by small integers.
+	// Verifiers detect it from the claim being a string.
+	EncodingCBOR Encoding = "cbor"
+)
+
+// cborKeys numbers the member names replaced in CBOR claims. The numbers are
+// part of the wire format: never renumber an entry, only append new ones.
+var cborKeys = map[string]uint64{
+	// Credential and presentation bodies
+	"@context":             1,
+	"id":                   2,
+	"type":                 3,
+	"issuer":               4,
+	"issuanceDate":         5,
+	"expirationDate":       6,
+	"credentialSubject":    7,
+	"credentialStatus":     8,
+	"credentialSchema":     9,
+	"refreshService":       10,
+	"holder":               11,
+	"verifiableCredential": 12,
+	"statusPurpose":        13,
+	"statusListIndex":      14,
+	"statusListCredential": 15,
+
+	// IdentityCredential subjects
+	"givenName":     16,
+	"familyName":    17,
+	"dateOfBirth":   18,
+	"nationality":   19,
+	"documentType":  20,
+	"documentId":    21,
+	"placeOfBirth":  22,
+	"gender":        23,
+	"address":       24,
+	"verifiedAt":    25,
+	"verifiedLevel": 26,
+}
+
+// cborTokenTag marks a v4.public token embedded in a CBOR claim, e.g. a
+// credential in a presentation, stored as [payload bytes, rest of the token]
+// rather than as base64url text. The tag number is unregistered and part of
+// the wire format.
+const cborTokenTag = 0x7667
+
+// cborNames maps the numbers in cborKeys back to member names
+var cborNames = func() map[uint64]string {
+	names := make(map[uint64]string, len(cborKeys))
+	for name, key := range cborKeys {
+		names[key] = name
+	}
+	return names
+}()
+
+var (
+	cborEnc = func() cbor.EncMode {
+		mode, err := cbor.CoreDetEncOptions().EncMode()
+		if err != nil {
+			panic(err)
+		}
+		return mode
+	}()
+	cborDec = func() cbor.DecMode {
+		mode, err := cbor.DecOptions{DupMapKey: cbor.DupMapKeyEnforcedAPF}.DecMode()
+		if err != nil {
+			panic(err)
+		}
+		return mode
+	}()
+)
+
+// EncodeClaim re-encodes a claim given as JSON in the given encoding. An
+// empty encoding or EncodingJSON returns the claim unchanged.
+func EncodeClaim(claim json.RawMessage, enc Encoding) (json.RawMessage, error) {
+	switch enc {
+	case "", EncodingJSON:
+		return claim, nil
+	case EncodingCBOR:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedEncoding, enc)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(claim))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	compact, err := compactValue(value)
+	if err != nil {
+		return nil, err
+	}
+	data, err := cborEnc.Marshal(compact)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(data))
+}
+
+// DecodeClaim returns a claim written by EncodeClaim as JSON, whichever
+// encoding it was written in
+func DecodeClaim(claim json.RawMessage) (json.RawMessage, error) {
+	claim = bytes.TrimSpace(claim)
+	if len(claim) == 0 || claim[0] != '"' {
+		return claim, nil
+	}
+
+	var encoded string
+	if err := json.Unmarshal(claim, &encoded); err != nil {
+		return nil, ErrMalformedCBORClaim
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrMalformedCBORClaim
+	}
+	var value interface{}
+	if err := cborDec.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedCBORClaim, err)
+	}
+	expanded, err := expandValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(expanded)
+}
+
+// compactValue converts a decoded JSON value for CBOR, numbering the member
+// names in cborKeys
+func compactValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[interface{}]interface{}, len(v))
+		for name, member := range v {
+			compact, err := compactValue(member)
+			if err != nil {
+				return nil, err
+			}
+			if key, ok := cborKeys[name]; ok {
+				out[key] = compact
+			} else {
+				out[name] = compact
+			}
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			compact, err := compactValue(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = compact
+		}
+		return out, nil
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		return v.Float64()
+	case string:
+		return compactToken(v), nil
+	default:
+		return v, nil
+	}
+}
+
+// compactToken stores a v4.public token as cborTokenTag, so its payload is
+// not base64url encoded twice; any other string is returned unchanged
+func compactToken(s string) interface{} {
+	const header = "v4.public."
+	if !strings.HasPrefix(s, header) {
+		return s
+	}
+	encoded := s[len(header):]
+	end := strings.IndexAny(encoded, "."+SDSeparator)
+	if end < 0 {
+		end = len(encoded)
+	}
+	// Only a canonical encoding comes back out unchanged
+	payload, err := base64.RawURLEncoding.Strict().DecodeString(encoded[:end])
+	if err != nil {
+		return s
+	}
+	return cbor.Tag{Number: cborTokenTag, Content: []interface{}{payload, encoded[end:]}}
+}
+
+// expandValue converts a decoded CBOR value back for JSON, restoring the
+// member names in cborKeys
+func expandValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, member := range v {
+			var name string
+			switch k := key.(type) {
+			case string:
+				name = k
+			case uint64:
+				var ok bool
+				if name, ok = cborNames[k]; !ok {
+					return nil, fmt.Errorf("%w: unknown key %d", ErrMalformedCBORClaim, k)
+				}
+			default:
+				return nil, fmt.Errorf("%w: unsupported key %v", ErrMalformedCBORClaim, key)
+			}
+			if _, dup := out[name]; dup {
+				return nil, fmt.Errorf("%w: duplicate key %q", ErrMalformedCBORClaim, name)
+			}
+			expanded, err := expandValue(member)
+			if err != nil {
+				return nil, err
+			}
+			out[name] = expanded
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			expanded, err := expandValue(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = expanded
+		}
+		return out, nil
+	case cbor.Tag:
+		return expandToken(v)
+	case nil, bool, string, uint64, int64, float64:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported value of type %T", ErrMalformedCBORClaim, value)
+	}
+}
+
+// expandToken restores a token stored by compactToken
+func expandToken(tag cbor.Tag) (string, error) {
+	parts, ok := tag.Content.([]interface{})
+	if tag.Number != cborTokenTag || !ok || len(parts) != 2 {
+		return "", fmt.Errorf("%w: unsupported tag %d", ErrMalformedCBORClaim, tag.Number)
+	}
+	payload, ok := parts[0].([]byte)
+	if !ok {
+		return "", fmt.Errorf("%w: malformed token", ErrMalformedCBORClaim)
+	}
+	rest, ok := parts[1].(string)
+	if !ok {
+		return "", fmt.Errorf("%w: malformed token", ErrMalformedCBORClaim)
+	}
+	return "v4.public." + base64.RawURLEncoding.EncodeToString(payload) + rest, nil
+}
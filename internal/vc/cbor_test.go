@@ -0,0 +1,146 @@
+package vc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+)
+
+// typicalIdentityToken issues an identity credential with a credential ID,
+// the full set of common identity fields and did:key DIDs, in enc
+func typicalIdentityToken(t *testing.T, priv ed25519.PrivateKey, enc Encoding) string {
+	t.Helper()
+	issuer, _ := did.CreateDIDKey(priv.Public().(ed25519.PublicKey))
+	subjectPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	subject, _ := did.CreateDIDKey(subjectPub)
+
+	token, err := IssueVCWithOptions(issuer.DID, subject.DID, priv, IdentitySubject{
+		ID:           subject.DID,
+		GivenName:    "Alice",
+		FamilyName:   "Doe",
+		DateOfBirth:  "1990-01-01",
+		Nationality:  "NL",
+		DocumentType: "passport",
+		DocumentID:   "NX1234567",
+	}, IssueOptions{CredentialID: "urn:uuid:3f8b2c1e-7d4a-4e6b-9c2f-1a5d8e7b6c40", Encoding: enc})
+	if err != nil {
+		t.Fatalf("IssueVCWithOptions failed: %v", err)
+	}
+	return token
+}
+
+func TestEncodeClaimRoundTrip(t *testing.T) {
+	claim := json.RawMessage(`{"@context":["https://www.w3.org/2018/credentials/v1"],"type":["VerifiableCredential"],` +
+		`"credentialSubject":{"id":"did:key:z1","givenName":"Alice","custom":{"n":42,"f":1.5,"ok":true,"none":null}}}`)
+
+	for _, enc := range []Encoding{"", EncodingJSON, EncodingCBOR} {
+		encoded, err := EncodeClaim(claim, enc)
+		if err != nil {
+			t.Fatalf("EncodeClaim(%q) failed: %v", enc, err)
+		}
+		if enc == EncodingCBOR && encoded[0] != '"' {
+			t.Errorf("Expected a CBOR claim to be a JSON string, got %s", encoded)
+		}
+		decoded, err := DecodeClaim(encoded)
+		if err != nil {
+			t.Fatalf("DecodeClaim(%q) failed: %v", enc, err)
+		}
+
+		var want, got interface{}
+		json.Unmarshal(claim, &want)
+		json.Unmarshal(decoded, &got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %s to round-trip, got %s", enc, decoded)
+		}
+	}
+
+	if _, err := EncodeClaim(claim, "xml"); !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Errorf("Expected ErrUnsupportedEncoding, got %v", err)
+	}
+}
+
+func TestDecodeClaimMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		claim string
+	}{
+		{"not base64url", `"not base64!"`},
+		{"truncated CBOR", `"oQ"`},
+		{"unknown integer key", `"oRgg9Q"`}, // {32: true}
+		{"duplicate key", `"ogJhYWJpZGFi"`}, // {2: "a", "id": "b"}
+		{"byte string value", `"oWFhQQA"`},  // {"a": h'00'}
+		{"tagged value", `"oWFhwRoAAAAA"`},  // {"a": 1(0)}
+		{"non-string key", `"oaEBAfU"`},     // {{1: 1}: true}
+		{"trailing data", `"oQL1AA"`},       // {2: true} 0
+		{"unterminated JSON string", `"oQL1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeClaim(json.RawMessage(tt.claim))
+			if !errors.Is(err, ErrMalformedCBORClaim) {
+				t.Errorf("Expected ErrMalformedCBORClaim, got %s, %v", decoded, err)
+			}
+		})
+	}
+}
+
+func TestIssueAndVerifyCBORCredential(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+
+	jsonToken := typicalIdentityToken(t, priv, EncodingJSON)
+	cborToken := typicalIdentityToken(t, priv, EncodingCBOR)
+
+	jsonClaims, err := VerifyVC(jsonToken, pub)
+	if err != nil {
+		t.Fatalf("VerifyVC failed for JSON: %v", err)
+	}
+	cborClaims, err := VerifyVC(cborToken, pub)
+	if err != nil {
+		t.Fatalf("VerifyVC failed for CBOR: %v", err)
+	}
+
+	jsonClaims.VC.CredentialSubject.(map[string]interface{})["id"] = nil
+	cborClaims.VC.CredentialSubject.(map[string]interface{})["id"] = nil
+	if !reflect.DeepEqual(cborClaims.VC, jsonClaims.VC) {
+		t.Errorf("Expected the same vc claim in both encodings, got %+v and %+v", cborClaims.VC, jsonClaims.VC)
+	}
+	if cborClaims.CredentialType() != CredentialTypeIdentity {
+		t.Errorf("Expected %s, got %s", CredentialTypeIdentity, cborClaims.CredentialType())
+	}
+
+	// The unverified helpers detect the encoding too
+	if err := CheckWellFormed(cborToken); err != nil {
+		t.Errorf("Expected a CBOR credential to be well-formed, got %v", err)
+	}
+	unverified, err := UnverifiedClaims(cborToken)
+	if err != nil {
+		t.Fatalf("UnverifiedClaims failed: %v", err)
+	}
+	if unverified.VC.ID != "urn:uuid:3f8b2c1e-7d4a-4e6b-9c2f-1a5d8e7b6c40" {
+		t.Errorf("Expected the credential ID, got %q", unverified.VC.ID)
+	}
+
+	_, err = IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", priv,
+		testIdentitySubject("did:key:zSubject"), IssueOptions{Encoding: "xml"})
+	if !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Errorf("Expected ErrUnsupportedEncoding, got %v", err)
+	}
+}
+
+func TestCBORCredentialSize(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+
+	jsonToken := typicalIdentityToken(t, priv, EncodingJSON)
+	cborToken := typicalIdentityToken(t, priv, EncodingCBOR)
+	t.Logf("identity credential: JSON %d bytes, CBOR %d bytes (%.0f%% smaller)",
+		len(jsonToken), len(cborToken), 100*(1-float64(len(cborToken))/float64(len(jsonToken))))
+
+	if len(cborToken) >= len(jsonToken) {
+		t.Errorf("Expected the CBOR token to be smaller, got %d >= %d bytes", len(cborToken), len(jsonToken))
+	}
+}
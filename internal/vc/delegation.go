@@ -0,0 +1,178 @@
+package vc
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/veriglob/veriglob-core/internal/resolver"
+)
+
+var (
+	ErrDelegationRootRequired = errors.New("delegation root authority is required")
+	ErrInvalidDelegation      = errors.New("delegation chain link is not a valid delegation credential")
+	ErrDelegationBroken       = errors.New("delegation chain does not lead from the root authority to the issuer")
+	ErrDelegationScope        = errors.New("delegation does not cover the credential type")
+	ErrRedelegationNotAllowed = errors.New("delegate may not delegate its authority further")
+	ErrDelegationTooDeep      = errors.New("delegation chain is too long")
+	ErrDelegationAfterIssue   = errors.New("delegation was granted after the credential it authorizes")
+)
+
+// DefaultMaxDelegationDepth is how many links a delegation chain may have
+// when DelegationOptions sets no limit
+const DefaultMaxDelegationDepth = 5
+
+// DelegationSubject is the subject of a DelegationCredential: the delegator
+// (the credential's issuer) grants the delegate named by ID the authority to
+// issue credentials in its name
+type DelegationSubject struct {
+	ID string `json:"id"`
+	// CredentialTypes limits the credential types the delegate may issue;
+	// empty allows any type
+	CredentialTypes []string `json:"credentialTypes,omitempty"`
+	// MayDelegate allows the delegate to pass the authority on to others
+	MayDelegate bool `json:"mayDelegate,omitempty"`
+}
+
+func (s DelegationSubject) GetID() string          { return s.ID }
+func (s DelegationSubject) CredentialType() string { return CredentialTypeDelegation }
+
+// Validate checks the delegate is named
+func (s DelegationSubject) Validate() error {
+	return requireFields(requiredField{"id", s.ID})
+}
+
+// Allows reports whether the delegation covers a credential type
+func (s DelegationSubject) Allows(credentialType string) bool {
+	return len(s.CredentialTypes) == 0 || slices.Contains(s.CredentialTypes, credentialType)
+}
+
+// DelegationOptions configures VerifyDelegationChain
+type DelegationOptions struct {
+	// RootDID is the authority every chain must start from, e.g. the
+	// organization DID the verifier trusts
+	RootDID string
+	// Resolver resolves the issuer key of each link; nil uses the default
+	// did:key resolver
+	Resolver *resolver.Resolver
+	// MaxDepth bounds the number of links; zero uses DefaultMaxDelegationDepth
+	MaxDepth int
+}
+
+// VerifyDelegationChain checks that the issuer of an already verified
+// credential holds authority delegated from opts.RootDID. A credential
+// issued by the root itself needs no chain. Otherwise the chain is walked
+// from the issuer up: each link must be a DelegationCredential with a valid
+// signature from its issuer, name the DID below it as its subject, allow the
+// credential's type, and have been issued no later than what it authorizes.
+// Every link but the one issued to the credential's issuer must also allow
+// further delegation, and the topmost link must be issued by the root. It
+// returns the authority path from the root down to the credential's issuer.
+func VerifyDelegationChain(claims *VCClaims, opts DelegationOptions) ([]string, error) {
+	if opts.RootDID == "" {
+		return nil, ErrDelegationRootRequired
+	}
+	maxDepth := opts.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDelegationDepth
+	}
+	if len(claims.DelegationChain) > maxDepth {
+		return nil, ErrDelegationTooDeep
+	}
+	res := opts.Resolver
+	if res == nil {
+		res = resolver.NewResolver()
+	}
+
+	credentialType := claims.CredentialType()
+	delegate := claims.Issuer
+	issuedAt := claims.IssuedAt
+	path := []string{delegate}
+	for i := len(claims.DelegationChain) - 1; i >= 0; i-- {
+		link, subject, err := verifyDelegationLink(claims.DelegationChain[i], res)
+		if err != nil {
+			return nil, fmt.Errorf("delegation link %d: %w", i, err)
+		}
+		if subject.ID != delegate || link.Subject != delegate {
+			return nil, fmt.Errorf("%w: link %d delegates to %s, not %s", ErrDelegationBroken, i, subject.ID, delegate)
+		}
+		if !subject.Allows(credentialType) {
+			return nil, fmt.Errorf("%w: link %d does not allow %s", ErrDelegationScope, i, credentialType)
+		}
+		// Any link above the first was used to issue another delegation
+		if i < len(claims.DelegationChain)-1 && !subject.MayDelegate {
+			return nil, fmt.Errorf("%w: link %d", ErrRedelegationNotAllowed, i)
+		}
+		if link.IssuedAt.After(issuedAt) {
+			return nil, fmt.Errorf("%w: link %d", ErrDelegationAfterIssue, i)
+		}
+
+		delegate = link.Issuer
+		issuedAt = link.IssuedAt
+		path = append(path, delegate)
+	}
+
+	if delegate != opts.RootDID {
+		return nil, fmt.Errorf("%w: chain starts at %s", ErrDelegationBroken, delegate)
+	}
+	slices.Reverse(path)
+	return path, nil
+}
+
+// VerifyDelegatedVC verifies a credential against the key its issuer DID
+// resolves to, then its delegation chain up to opts.RootDID
+func VerifyDelegatedVC(tokenString string, opts DelegationOptions) (*VCClaims, error) {
+	res := opts.Resolver
+	if res == nil {
+		res = resolver.NewResolver()
+	}
+	issuer, err := UnverifiedIssuer(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	issuerKey, err := res.Resolve(issuer)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := VerifyVC(tokenString, issuerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Resolver = res
+	if _, err := VerifyDelegationChain(claims, opts); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// verifyDelegationLink verifies one delegation credential against its
+// issuer's resolved key and decodes its subject. Links carry no chain of
+// their own; the chain on the delegated credential covers every level.
+func verifyDelegationLink(token string, res *resolver.Resolver) (*VCClaims, *DelegationSubject, error) {
+	issuer, err := UnverifiedIssuer(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	issuerKey, err := res.Resolve(issuer)
+	if err != nil {
+		return nil, nil, err
+	}
+	claims, err := VerifyVC(token, issuerKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if claims.CredentialType() != CredentialTypeDelegation || len(claims.DelegationChain) > 0 {
+		return nil, nil, ErrInvalidDelegation
+	}
+
+	subject, err := claims.TypedSubject()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidDelegation, err)
+	}
+	delegation, ok := subject.(*DelegationSubject)
+	if !ok || delegation.ID == "" {
+		return nil, nil, ErrInvalidDelegation
+	}
+	return claims, delegation, nil
+}
@@ -0,0 +1,143 @@
+package vc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+)
+
+type delegationParty struct {
+	did  string
+	priv ed25519.PrivateKey
+}
+
+func newDelegationParty(t *testing.T) delegationParty {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	d, _ := did.CreateDIDKey(pub)
+	return delegationParty{did: d.DID, priv: priv}
+}
+
+func delegate(t *testing.T, from, to delegationParty, subject DelegationSubject, chain ...string) string {
+	subject.ID = to.did
+	token, err := IssueVCWithOptions(from.did, to.did, from.priv, subject, IssueOptions{DelegationChain: chain})
+	if err != nil {
+		t.Fatalf("Failed to issue delegation: %v", err)
+	}
+	return token
+}
+
+func TestVerifyDelegationChain(t *testing.T) {
+	org := newDelegationParty(t)
+	dept := newDelegationParty(t)
+	team := newDelegationParty(t)
+	holder := newDelegationParty(t)
+
+	// org -> dept (may delegate) -> team, both scoped to education credentials
+	orgToDept := delegate(t, org, dept, DelegationSubject{CredentialTypes: []string{CredentialTypeEducation}, MayDelegate: true})
+	deptToTeam := delegate(t, dept, team, DelegationSubject{CredentialTypes: []string{CredentialTypeEducation}})
+
+	subject := EducationSubject{ID: holder.did, InstitutionName: "Example University"}
+	token, err := IssueVCWithOptions(team.did, holder.did, team.priv, subject, IssueOptions{
+		DelegationChain: []string{orgToDept, deptToTeam},
+	})
+	if err != nil {
+		t.Fatalf("Failed to issue: %v", err)
+	}
+
+	claims, err := VerifyDelegatedVC(token, DelegationOptions{RootDID: org.did})
+	if err != nil {
+		t.Fatalf("VerifyDelegatedVC failed: %v", err)
+	}
+	if len(claims.DelegationChain) != 2 {
+		t.Fatalf("Expected a chain of 2 links, got %d", len(claims.DelegationChain))
+	}
+
+	path, err := VerifyDelegationChain(claims, DelegationOptions{RootDID: org.did})
+	if err != nil {
+		t.Fatalf("VerifyDelegationChain failed: %v", err)
+	}
+	if want := []string{org.did, dept.did, team.did}; !slices.Equal(path, want) {
+		t.Errorf("Expected authority path %v, got %v", want, path)
+	}
+
+	// Another root does not authorize the chain
+	if _, err := VerifyDelegationChain(claims, DelegationOptions{RootDID: holder.did}); !errors.Is(err, ErrDelegationBroken) {
+		t.Errorf("Expected ErrDelegationBroken for another root, got %v", err)
+	}
+	if _, err := VerifyDelegationChain(claims, DelegationOptions{RootDID: org.did, MaxDepth: 1}); err != ErrDelegationTooDeep {
+		t.Errorf("Expected ErrDelegationTooDeep, got %v", err)
+	}
+	if _, err := VerifyDelegationChain(claims, DelegationOptions{}); err != ErrDelegationRootRequired {
+		t.Errorf("Expected ErrDelegationRootRequired, got %v", err)
+	}
+
+	// The root needs no chain
+	direct, _ := IssueVC(org.did, holder.did, org.priv, subject)
+	if _, err := VerifyDelegatedVC(direct, DelegationOptions{RootDID: org.did}); err != nil {
+		t.Errorf("Expected a credential from the root to verify, got %v", err)
+	}
+}
+
+func TestVerifyDelegationChainRejects(t *testing.T) {
+	org := newDelegationParty(t)
+	dept := newDelegationParty(t)
+	team := newDelegationParty(t)
+	holder := newDelegationParty(t)
+	education := EducationSubject{ID: holder.did, InstitutionName: "Example University"}
+
+	issue := func(issuer delegationParty, subject CredentialSubject, chain ...string) *VCClaims {
+		token, err := IssueVCWithOptions(issuer.did, holder.did, issuer.priv, subject, IssueOptions{DelegationChain: chain})
+		if err != nil {
+			t.Fatalf("Failed to issue: %v", err)
+		}
+		claims, err := UnverifiedClaims(token)
+		if err != nil {
+			t.Fatalf("UnverifiedClaims failed: %v", err)
+		}
+		return claims
+	}
+	opts := DelegationOptions{RootDID: org.did}
+
+	// A department that may not delegate cannot authorize a team
+	noRedelegation := delegate(t, org, dept, DelegationSubject{})
+	deptToTeam := delegate(t, dept, team, DelegationSubject{})
+	if _, err := VerifyDelegationChain(issue(team, education, noRedelegation, deptToTeam), opts); !errors.Is(err, ErrRedelegationNotAllowed) {
+		t.Errorf("Expected ErrRedelegationNotAllowed, got %v", err)
+	}
+
+	// A delegation scoped to education does not cover identity credentials
+	scoped := delegate(t, org, dept, DelegationSubject{CredentialTypes: []string{CredentialTypeEducation}})
+	if _, err := VerifyDelegationChain(issue(dept, testIdentitySubject(holder.did), scoped), opts); !errors.Is(err, ErrDelegationScope) {
+		t.Errorf("Expected ErrDelegationScope, got %v", err)
+	}
+
+	// A link delegating to someone other than the issuer breaks the chain
+	if _, err := VerifyDelegationChain(issue(team, education, scoped), opts); !errors.Is(err, ErrDelegationBroken) {
+		t.Errorf("Expected ErrDelegationBroken, got %v", err)
+	}
+
+	// A self-issued delegation does not reach the root
+	selfIssued := delegate(t, dept, dept, DelegationSubject{})
+	if _, err := VerifyDelegationChain(issue(dept, education, selfIssued), opts); !errors.Is(err, ErrDelegationBroken) {
+		t.Errorf("Expected ErrDelegationBroken for a self-issued link, got %v", err)
+	}
+
+	// A credential of another type is not a delegation
+	notDelegation, _ := IssueVC(org.did, dept.did, org.priv, EducationSubject{ID: dept.did, InstitutionName: "Org"})
+	if _, err := VerifyDelegationChain(issue(dept, education, notDelegation), opts); !errors.Is(err, ErrInvalidDelegation) {
+		t.Errorf("Expected ErrInvalidDelegation, got %v", err)
+	}
+
+	// A tampered link fails its signature check
+	tampered := scoped[:len(scoped)-4] + "AAAA"
+	if _, err := VerifyDelegationChain(issue(dept, education, tampered), opts); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid for a tampered link, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package vc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// DigestAlgSHA256 prefixes the hex SHA-256 of fingerprints and digests
+const DigestAlgSHA256 = "sha256"
+
+// Fingerprint returns a stable hash of a credential token, "sha256:" and the
+// hex SHA-256 of its canonical form: surrounding whitespace is trimmed and the
+// disclosures of a selective disclosure credential sorted, so the same
+// credential stored or presented in a different order fingerprints the same.
+// For a plain token this is the digest a presentation's CredentialReference
+// carries.
+//
+// A fingerprint identifies one signed credential: re-issuing or re-signing the
+// same claims gives a new one. Use it to cache verification results or to
+// reference an exact token; use VCClaims.Digest to find credentials that say
+// the same thing. The signature is not checked, but a token that is not a
+// credential returns ErrMalformedToken.
+func Fingerprint(tokenString string) (string, error) {
+	tokenString = strings.TrimSpace(tokenString)
+	if err := CheckWellFormed(tokenString); err != nil {
+		return "", err
+	}
+
+	if IsSDCredential(tokenString) {
+		token, disclosures, err := SplitSDCredential(tokenString)
+		if err != nil {
+			return "", ErrMalformedToken
+		}
+		disclosures = append([]string(nil), disclosures...)
+		sort.Strings(disclosures)
+		tokenString = joinSDCredential(token, disclosures)
+	}
+	return hashDigest([]byte(tokenString)), nil
+}
+
+// Digest returns a content address for the credential, "sha256:" and the hex
+// SHA-256 of the canonical JSON of what it asserts: the issuer, subject,
+// holder binding, and the credential body's contexts, types, issuer, subject
+// claims and schema. The identifiers, dates, status entry, refresh service and
+// delegation chain are left out, so two issuances of the same claims about
+// the same subject share a digest even though their IDs, lifetimes and
+// signatures differ, e.g. for a wallet to spot a credential it already holds.
+// Call it on verified claims; claims from UnverifiedClaims may be forged.
+func (c *VCClaims) Digest() (string, error) {
+	content := struct {
+		Issuer            string            `json:"iss"`
+		Subject           string            `json:"sub"`
+		Confirmation      *Confirmation     `json:"cnf,omitempty"`
+		Context           []string          `json:"@context,omitempty"`
+		Type              []string          `json:"type"`
+		VCIssuer          string            `json:"issuer,omitempty"`
+		CredentialSubject interface{}       `json:"credentialSubject"`
+		CredentialSchema  *CredentialSchema `json:"credentialSchema,omitempty"`
+	}{
+		Issuer:            c.Issuer,
+		Subject:           c.Subject,
+		Confirmation:      c.Confirmation,
+		Context:           c.VC.Context,
+		Type:              c.VC.Type,
+		VCIssuer:          c.VC.Issuer,
+		CredentialSubject: c.VC.CredentialSubject,
+		CredentialSchema:  c.VC.CredentialSchema,
+	}
+
+	canonical, err := canonicalJSON(content)
+	if err != nil {
+		return "", err
+	}
+	return hashDigest(canonical), nil
+}
+
+// canonicalJSON encodes v with object keys sorted at every level and numbers
+// as written, so equal content encodes to equal bytes however it was built
+func canonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decoding into generic values and encoding again sorts map keys
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
+
+// hashDigest returns "sha256:" and the hex SHA-256 of data
+func hashDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return DigestAlgSHA256 + ":" + hex.EncodeToString(sum[:])
+}
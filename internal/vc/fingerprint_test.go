@@ -0,0 +1,178 @@
+package vc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	subject := testIdentitySubject("did:key:zSubject")
+
+	token, err := IssueVC("did:key:zIssuer", "did:key:zSubject", priv, subject)
+	if err != nil {
+		t.Fatalf("IssueVC failed: %v", err)
+	}
+	fp, err := Fingerprint(token)
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+
+	// A plain token hashes as is
+	sum := sha256.Sum256([]byte(token))
+	if want := "sha256:" + hex.EncodeToString(sum[:]); fp != want {
+		t.Errorf("Expected %s, got %s", want, fp)
+	}
+
+	// Stable across calls and surrounding whitespace
+	for _, variant := range []string{token, " " + token + "\n"} {
+		if got, _ := Fingerprint(variant); got != fp {
+			t.Errorf("Expected fingerprint %s for %q, got %s", fp, variant, got)
+		}
+	}
+
+	// Re-issuing the same claims is a different credential
+	again, _ := IssueVCWithID("did:key:zIssuer", "did:key:zSubject", priv, subject, "urn:uuid:again")
+	if got, _ := Fingerprint(again); got == fp {
+		t.Error("Expected a re-issued credential to have a different fingerprint")
+	}
+
+	for _, malformed := range []string{"", "not-a-token", "v4.public.bogus"} {
+		if _, err := Fingerprint(malformed); !errors.Is(err, ErrMalformedToken) {
+			t.Errorf("Expected ErrMalformedToken for %q, got %v", malformed, err)
+		}
+	}
+}
+
+func TestFingerprintSDCredential(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	credential, err := IssueSDVC("did:key:zIssuer", "did:key:zSubject", priv, testIdentitySubject("did:key:zSubject"), IssueOptions{})
+	if err != nil {
+		t.Fatalf("IssueSDVC failed: %v", err)
+	}
+
+	// The same disclosures in another order are the same credential
+	forward, _ := SelectDisclosures(credential, "givenName", "dateOfBirth")
+	reversed, _ := SelectDisclosures(credential, "dateOfBirth", "givenName")
+	fpForward, err := Fingerprint(forward)
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+	if fpReversed, _ := Fingerprint(reversed); fpReversed != fpForward {
+		t.Errorf("Expected disclosure order not to matter, got %s and %s", fpForward, fpReversed)
+	}
+
+	// Disclosing less is not
+	fewer, _ := SelectDisclosures(credential, "dateOfBirth")
+	if fpFewer, _ := Fingerprint(fewer); fpFewer == fpForward {
+		t.Error("Expected different disclosures to give a different fingerprint")
+	}
+}
+
+func TestFingerprintCollisions(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	seen := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		id := fmt.Sprintf("urn:uuid:%d", i)
+		token, err := IssueVCWithID("did:key:zIssuer", "did:key:zSubject", priv, testIdentitySubject("did:key:zSubject"), id)
+		if err != nil {
+			t.Fatalf("IssueVCWithID failed: %v", err)
+		}
+		fp, err := Fingerprint(token)
+		if err != nil {
+			t.Fatalf("Fingerprint failed: %v", err)
+		}
+		if seen[fp] {
+			t.Fatalf("Expected distinct fingerprints, got %s twice", fp)
+		}
+		seen[fp] = true
+	}
+}
+
+func TestVCClaimsDigest(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	subject := testIdentitySubject("did:key:zSubject")
+
+	digestOf := func(token string) string {
+		t.Helper()
+		claims, err := VerifyVC(token, issuerPub)
+		if err != nil {
+			t.Fatalf("VerifyVC failed: %v", err)
+		}
+		digest, err := claims.Digest()
+		if err != nil {
+			t.Fatalf("Digest failed: %v", err)
+		}
+		return digest
+	}
+
+	first, _ := IssueVCWithID("did:key:zIssuer", "did:key:zSubject", issuerPriv, subject, "urn:uuid:first")
+	digest := digestOf(first)
+	if !strings.HasPrefix(digest, "sha256:") || len(digest) != len("sha256:")+2*sha256.Size {
+		t.Errorf("Expected a sha256 hex digest, got %s", digest)
+	}
+
+	// A new ID, lifetime and signature do not change the content
+	second, _ := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", issuerPriv, subject, IssueOptions{
+		CredentialID: "urn:uuid:second",
+		Lifetime:     DefaultValidity / 2,
+	})
+	if got := digestOf(second); got != digest {
+		t.Errorf("Expected the same digest for the same content, got %s and %s", digest, got)
+	}
+
+	// Any change to what is asserted does
+	changed := subject
+	changed.GivenName = "Alicia"
+	third, _ := IssueVCWithID("did:key:zIssuer", "did:key:zSubject", issuerPriv, changed, "urn:uuid:third")
+	if got := digestOf(third); got == digest {
+		t.Error("Expected a different digest for a different subject claim")
+	}
+	otherIssuer, _ := IssueVCWithID("did:key:zOther", "did:key:zSubject", issuerPriv, subject, "urn:uuid:fourth")
+	if got := digestOf(otherIssuer); got == digest {
+		t.Error("Expected a different digest for a different issuer")
+	}
+}
+
+func TestVCClaimsDigestCanonical(t *testing.T) {
+	fromStruct := &VCClaims{
+		Issuer:  "did:key:zIssuer",
+		Subject: "did:key:zSubject",
+		VC: VerifiableCredential{
+			Type:              []string{"VerifiableCredential", CredentialTypeIdentity},
+			CredentialSubject: testIdentitySubject("did:key:zSubject"),
+		},
+	}
+	// The same subject as decoded JSON, built in a different key order
+	fromMap := &VCClaims{
+		Issuer:  "did:key:zIssuer",
+		Subject: "did:key:zSubject",
+		VC: VerifiableCredential{
+			Type: []string{"VerifiableCredential", CredentialTypeIdentity},
+			CredentialSubject: map[string]interface{}{
+				"dateOfBirth": "1990-01-01",
+				"familyName":  "Doe",
+				"givenName":   "Alice",
+				"id":          "did:key:zSubject",
+			},
+		},
+	}
+
+	a, err := fromStruct.Digest()
+	if err != nil {
+		t.Fatalf("Digest failed: %v", err)
+	}
+	b, err := fromMap.Digest()
+	if err != nil {
+		t.Fatalf("Digest failed: %v", err)
+	}
+	if a != b {
+		t.Errorf("Expected equal digests for equal content, got %s and %s", a, b)
+	}
+}
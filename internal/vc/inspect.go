@@ -0,0 +1,79 @@
+package vc
+
+import (
+	"strings"
+	"time"
+)
+
+// VCMetadata is what a credential says about itself, read without checking
+// its signature. None of it can be trusted: anyone can mint a token with any
+// issuer and dates. Use it to route, index or display a credential, and
+// VerifyVC before acting on it.
+type VCMetadata struct {
+	// ID is the jti claim, or the vc id if there is no jti
+	ID      string
+	Issuer  string
+	Subject string
+	// IssuerDisplay is the issuer's display name, logo and description, if
+	// the credential carries them
+	IssuerDisplay *IssuerDisplay
+	// Type is the specific credential type, and Types every type listed
+	Type      string
+	Types     []string
+	IssuedAt  time.Time
+	NotBefore time.Time // zero if the credential is valid from issuance
+	ExpiresAt time.Time
+	// Format is the signature suite name, e.g. SuitePasetoV4, or the JWS alg
+	// of a JWS no suite here handles
+	Format string
+	// SelectiveDisclosure reports whether disclosures are appended to the token
+	SelectiveDisclosure bool
+}
+
+// Expired reports whether the credential claims to have expired as of now
+func (m *VCMetadata) Expired(now time.Time) bool {
+	return !m.ExpiresAt.IsZero() && !now.Before(m.ExpiresAt)
+}
+
+// InspectVC reads a credential's ID, issuer, subject, type and dates from its
+// payload WITHOUT verifying the signature, for a wallet or gateway that has
+// no issuer key yet. The result is untrusted; see VCMetadata. A token that
+// is not a well-formed credential returns ErrMalformedToken.
+func InspectVC(tokenString string) (*VCMetadata, error) {
+	claims, err := UnverifiedClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	format := SuitePasetoV4
+	token, _, _ := strings.Cut(tokenString, SDSeparator)
+	if isJWS(token) {
+		encoded, _, _ := strings.Cut(token, ".")
+		header, err := decodeJWSHeader(encoded)
+		if err != nil {
+			return nil, err
+		}
+		switch header.Alg {
+		case JWSAlgES256:
+			format = SuiteES256JWS
+		case JWSAlgEdDSA:
+			format = SuiteEdDSAJWS
+		default:
+			format = header.Alg
+		}
+	}
+
+	return &VCMetadata{
+		ID:                  claims.GetCredentialID(),
+		Issuer:              claims.Issuer,
+		Subject:             claims.Subject,
+		IssuerDisplay:       claims.IssuerDisplay(),
+		Type:                claims.CredentialType(),
+		Types:               claims.VC.Type,
+		IssuedAt:            claims.IssuedAt,
+		NotBefore:           claims.NotBefore,
+		ExpiresAt:           claims.ExpiresAt,
+		Format:              format,
+		SelectiveDisclosure: IsSDCredential(tokenString),
+	}, nil
+}
@@ -0,0 +1,95 @@
+package vc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInspectVC(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	notBefore := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	token, err := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", priv, testIdentitySubject("did:key:zSubject"), IssueOptions{
+		CredentialID: "urn:uuid:inspect",
+		NotBefore:    notBefore,
+	})
+	if err != nil {
+		t.Fatalf("Failed to issue: %v", err)
+	}
+
+	md, err := InspectVC(token)
+	if err != nil {
+		t.Fatalf("InspectVC failed: %v", err)
+	}
+	if md.ID != "urn:uuid:inspect" {
+		t.Errorf("Expected ID urn:uuid:inspect, got %s", md.ID)
+	}
+	if md.Issuer != "did:key:zIssuer" || md.Subject != "did:key:zSubject" {
+		t.Errorf("Expected issuer and subject from the token, got %s and %s", md.Issuer, md.Subject)
+	}
+	if md.Type != CredentialTypeIdentity || len(md.Types) != 2 {
+		t.Errorf("Expected type %s of 2 types, got %s of %v", CredentialTypeIdentity, md.Type, md.Types)
+	}
+	if !md.NotBefore.Equal(notBefore) {
+		t.Errorf("Expected not before %v, got %v", notBefore, md.NotBefore)
+	}
+	if md.IssuedAt.IsZero() || !md.ExpiresAt.After(md.IssuedAt) {
+		t.Errorf("Expected issuance before expiry, got %v and %v", md.IssuedAt, md.ExpiresAt)
+	}
+	if md.Format != SuitePasetoV4 || md.SelectiveDisclosure {
+		t.Errorf("Expected a plain %s credential, got %s (sd %v)", SuitePasetoV4, md.Format, md.SelectiveDisclosure)
+	}
+	if md.Expired(time.Now()) || !md.Expired(md.ExpiresAt) {
+		t.Error("Expected the credential to expire exactly at its expiry")
+	}
+}
+
+func TestInspectVCDoesNotVerify(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	token, _ := IssueVCWithID("did:key:zIssuer", "did:key:zSubject", priv, testIdentitySubject("did:key:zSubject"), "urn:uuid:tampered")
+
+	// A corrupted signature still inspects; only verification would catch it
+	tampered := token[:len(token)-4] + "AAAA"
+	md, err := InspectVC(tampered)
+	if err != nil {
+		t.Fatalf("InspectVC failed: %v", err)
+	}
+	if md.ID != "urn:uuid:tampered" {
+		t.Errorf("Expected ID urn:uuid:tampered, got %s", md.ID)
+	}
+
+	if _, err := InspectVC("v4.public.not-a-token"); !errors.Is(err, ErrMalformedToken) {
+		t.Errorf("Expected ErrMalformedToken, got %v", err)
+	}
+}
+
+func TestInspectVCFormats(t *testing.T) {
+	priv := generateP256Key(t)
+	jws, err := IssueVCWithID("did:key:zIssuer", "did:key:zSubject", priv, testIdentitySubject("did:key:zSubject"), "urn:uuid:es256")
+	if err != nil {
+		t.Fatalf("Failed to issue: %v", err)
+	}
+	md, err := InspectVC(jws)
+	if err != nil {
+		t.Fatalf("InspectVC failed: %v", err)
+	}
+	if md.Format != SuiteES256JWS || md.ID != "urn:uuid:es256" {
+		t.Errorf("Expected %s credential urn:uuid:es256, got %s credential %s", SuiteES256JWS, md.Format, md.ID)
+	}
+
+	_, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	sd, err := IssueSDVC("did:key:zIssuer", "did:key:zSubject", edPriv, testIdentitySubject("did:key:zSubject"), IssueOptions{})
+	if err != nil {
+		t.Fatalf("Failed to issue: %v", err)
+	}
+	md, err = InspectVC(sd)
+	if err != nil {
+		t.Fatalf("InspectVC failed: %v", err)
+	}
+	if !md.SelectiveDisclosure || md.Format != SuitePasetoV4 {
+		t.Errorf("Expected a selective disclosure %s credential, got %s (sd %v)", SuitePasetoV4, md.Format, md.SelectiveDisclosure)
+	}
+}
@@ -0,0 +1,82 @@
+package vc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+var ErrInvalidIssuerDisplay = errors.New("invalid issuer display metadata")
+
+// IssuerDisplay describes the issuer for display, e.g. a wallet showing the
+// issuer's name and logo instead of its DID. It is carried in the vc claim,
+// which then writes issuer as an object holding the issuer DID as its id.
+type IssuerDisplay struct {
+	Name string `json:"name,omitempty"`
+	// Logo is the URL of the issuer's logo: https, or a data: URI so the
+	// wallet need not fetch it
+	Logo        string `json:"logo,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Validate checks the display has a name and that a logo is an https or
+// data: URL
+func (d *IssuerDisplay) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("%w: missing name", ErrInvalidIssuerDisplay)
+	}
+	if d.Logo != "" {
+		u, err := url.Parse(d.Logo)
+		if err != nil || (u.Scheme != "data" && (u.Scheme != "https" || u.Host == "")) {
+			return fmt.Errorf("%w: logo must be an https or data: URL", ErrInvalidIssuerDisplay)
+		}
+	}
+	return nil
+}
+
+// IssuerDisplay returns the issuer's display metadata, or nil if the
+// credential carries none. Metadata whose id is not the iss DID is ignored.
+// Like the rest of the claims, it is only as trustworthy as the verification
+// that produced them.
+func (c *VCClaims) IssuerDisplay() *IssuerDisplay {
+	if c.VC.IssuerDisplay == nil || c.VC.Issuer != c.Issuer {
+		return nil
+	}
+	return c.VC.IssuerDisplay
+}
+
+// issuerObject is the vc issuer written as an object
+type issuerObject struct {
+	ID string `json:"id"`
+	IssuerDisplay
+}
+
+// encodeIssuer returns the vc issuer member: the DID as a string, or an
+// object with the DID as its id when there is metadata
+func encodeIssuer(did string, display *IssuerDisplay) interface{} {
+	if display == nil {
+		if did == "" {
+			return nil
+		}
+		return did
+	}
+	return issuerObject{ID: did, IssuerDisplay: *display}
+}
+
+// decodeIssuer reads the vc issuer member in either shape
+func decodeIssuer(raw json.RawMessage) (string, *IssuerDisplay, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil, nil
+	}
+	if raw[0] == '"' {
+		var did string
+		err := json.Unmarshal(raw, &did)
+		return did, nil, err
+	}
+	var obj issuerObject
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return "", nil, fmt.Errorf("issuer must be a DID or an object: %w", err)
+	}
+	return obj.ID, &obj.IssuerDisplay, nil
+}
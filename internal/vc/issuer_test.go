@@ -0,0 +1,147 @@
+package vc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestIssuerShapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		issuer  string
+		display *IssuerDisplay
+		wantErr bool
+	}{
+		{"absent", `{"type":["VerifiableCredential"]}`, "", nil, false},
+		{"null", `{"type":["VerifiableCredential"],"issuer":null}`, "", nil, false},
+		{"string", `{"type":["VerifiableCredential"],"issuer":"did:key:zIssuer"}`, "did:key:zIssuer", nil, false},
+		{
+			"object",
+			`{"type":["VerifiableCredential"],"issuer":{"id":"did:key:zIssuer","name":"Acme University","logo":"https://acme.example/logo.png"}}`,
+			"did:key:zIssuer",
+			&IssuerDisplay{Name: "Acme University", Logo: "https://acme.example/logo.png"},
+			false,
+		},
+		{"number", `{"type":["VerifiableCredential"],"issuer":42}`, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v VerifiableCredential
+			err := json.Unmarshal([]byte(tt.body), &v)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got issuer %q", v.Issuer)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if v.Issuer != tt.issuer {
+				t.Errorf("Expected issuer %q, got %q", tt.issuer, v.Issuer)
+			}
+			if !reflect.DeepEqual(v.IssuerDisplay, tt.display) {
+				t.Errorf("Expected display %+v, got %+v", tt.display, v.IssuerDisplay)
+			}
+
+			// Each shape is written back the way it was read
+			data, err := json.Marshal(v)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			var got, want map[string]interface{}
+			json.Unmarshal(data, &got)
+			json.Unmarshal([]byte(tt.body), &want)
+			if !reflect.DeepEqual(got["issuer"], want["issuer"]) {
+				t.Errorf("Expected issuer %v, got %s", want["issuer"], data)
+			}
+		})
+	}
+}
+
+func TestIssueWithIssuerDisplay(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	display := &IssuerDisplay{
+		Name:        "Acme University",
+		Logo:        "https://acme.example/logo.png",
+		Description: "Registrar of Acme University",
+	}
+
+	for _, enc := range []Encoding{EncodingJSON, EncodingCBOR} {
+		token, err := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", priv,
+			testIdentitySubject("did:key:zSubject"), IssueOptions{IssuerDisplay: display, Encoding: enc})
+		if err != nil {
+			t.Fatalf("IssueVCWithOptions(%s) failed: %v", enc, err)
+		}
+		claims, err := VerifyVC(token, pub)
+		if err != nil {
+			t.Fatalf("VerifyVC(%s) failed: %v", enc, err)
+		}
+		if claims.VC.Issuer != "did:key:zIssuer" {
+			t.Errorf("Expected vc issuer did:key:zIssuer, got %q", claims.VC.Issuer)
+		}
+		if !reflect.DeepEqual(claims.IssuerDisplay(), display) {
+			t.Errorf("Expected display %+v, got %+v", display, claims.IssuerDisplay())
+		}
+
+		md, err := InspectVC(token)
+		if err != nil {
+			t.Fatalf("InspectVC failed: %v", err)
+		}
+		if !reflect.DeepEqual(md.IssuerDisplay, display) {
+			t.Errorf("Expected inspected display %+v, got %+v", display, md.IssuerDisplay)
+		}
+	}
+
+	// Without display the vc claim has no issuer, as before
+	token, _ := IssueVC("did:key:zIssuer", "did:key:zSubject", priv, testIdentitySubject("did:key:zSubject"))
+	claims, _ := VerifyVC(token, pub)
+	if claims.VC.Issuer != "" || claims.IssuerDisplay() != nil {
+		t.Errorf("Expected no vc issuer, got %q with %+v", claims.VC.Issuer, claims.IssuerDisplay())
+	}
+
+	// Metadata naming another DID than iss is not surfaced
+	claims.VC.Issuer = "did:key:zOther"
+	claims.VC.IssuerDisplay = display
+	if claims.IssuerDisplay() != nil {
+		t.Error("Expected display for another DID to be ignored")
+	}
+}
+
+func TestIssuerDisplayValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		display IssuerDisplay
+		wantErr bool
+	}{
+		{"name only", IssuerDisplay{Name: "Acme"}, false},
+		{"https logo", IssuerDisplay{Name: "Acme", Logo: "https://acme.example/logo.png"}, false},
+		{"data logo", IssuerDisplay{Name: "Acme", Logo: "data:image/png;base64,iVBORw0KGgo="}, false},
+		{"missing name", IssuerDisplay{Logo: "https://acme.example/logo.png"}, true},
+		{"http logo", IssuerDisplay{Name: "Acme", Logo: "http://acme.example/logo.png"}, true},
+		{"relative logo", IssuerDisplay{Name: "Acme", Logo: "/logo.png"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.display.Validate()
+			if tt.wantErr != (err != nil) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidIssuerDisplay) {
+				t.Errorf("Expected ErrInvalidIssuerDisplay, got %v", err)
+			}
+		})
+	}
+
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	_, err := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", priv,
+		testIdentitySubject("did:key:zSubject"), IssueOptions{IssuerDisplay: &IssuerDisplay{}})
+	if !errors.Is(err, ErrInvalidIssuerDisplay) {
+		t.Errorf("Expected ErrInvalidIssuerDisplay, got %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+package vc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// JWSAlgES256 is the JWS algorithm of credentials signed with a P-256 key
+const JWSAlgES256 = "ES256"
+
+// p256FieldSize is the byte length of each of r and s in an ES256 signature
+const p256FieldSize = 32
+
+// jwsHeader is the protected header of a compact JWS
+type jwsHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// jwtClaims are the registered claims of a JWT, with NumericDate times as
+// JWT requires
+type jwtClaims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub,omitempty"`
+	Audience  string `json:"aud,omitempty"`
+	JTI       string `json:"jti,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	NotBefore int64  `json:"nbf,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// unixTime converts a NumericDate, where 0 means the claim is absent
+func unixTime(seconds int64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+// ES256Suite signs compact JWS tokens (JWT-VCs) with a P-256 key, for
+// verifiers in the JOSE ecosystem. Only PrivateKey is needed to sign and
+// PublicKey to verify.
+type ES256Suite struct {
+	PrivateKey *ecdsa.PrivateKey
+	PublicKey  *ecdsa.PublicKey
+	// Leeway is the clock skew tolerated on exp and nbf; zero uses
+	// DefaultLeeway and a negative value none
+	Leeway time.Duration
+}
+
+// NewES256Suite creates an ES256 suite; either key may be nil
+func NewES256Suite(privateKey *ecdsa.PrivateKey, publicKey *ecdsa.PublicKey) *ES256Suite {
+	return &ES256Suite{PrivateKey: privateKey, PublicKey: publicKey}
+}
+
+// Name returns SuiteES256JWS
+func (s *ES256Suite) Name() string { return SuiteES256JWS }
+
+// Sign encodes the claims as a compact JWS with typ JWT
+func (s *ES256Suite) Sign(claims *TokenClaims) (string, error) {
+	if s.PrivateKey == nil {
+		return "", ErrSuiteKeyMissing
+	}
+	if s.PrivateKey.Curve != elliptic.P256() {
+		return "", ErrUnsupportedKey
+	}
+
+	return signJWT(claims, jwsHeader{Alg: JWSAlgES256, Typ: "JWT"}, func(signingInput []byte) ([]byte, error) {
+		digest := sha256.Sum256(signingInput)
+		r, sig, err := ecdsa.Sign(rand.Reader, s.PrivateKey, digest[:])
+		if err != nil {
+			return nil, err
+		}
+
+		// JWS encodes the signature as fixed-size big-endian r || s, not ASN.1
+		signature := make([]byte, 2*p256FieldSize)
+		r.FillBytes(signature[:p256FieldSize])
+		sig.FillBytes(signature[p256FieldSize:])
+		return signature, nil
+	})
+}
+
+// Verify checks a compact JWS's ES256 signature, expiry and nbf and returns its
+// claims. Only ES256 is accepted.
+func (s *ES256Suite) Verify(tokenString string) (*TokenClaims, error) {
+	if s.PublicKey == nil || s.PublicKey.Curve != elliptic.P256() {
+		return nil, ErrUnsupportedKey
+	}
+
+	return verifyJWT(tokenString, JWSAlgES256, s.Leeway, func(signingInput, signature []byte) bool {
+		if len(signature) != 2*p256FieldSize {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:p256FieldSize])
+		sig := new(big.Int).SetBytes(signature[p256FieldSize:])
+		digest := sha256.Sum256(signingInput)
+		return ecdsa.Verify(s.PublicKey, digest[:], r, sig)
+	})
+}
+
+// signJWT encodes the claims as a JWT payload and signs it as a compact JWS
+// with the given header, using sign over the JWS signing input
+func signJWT(claims *TokenClaims, header jwsHeader, sign func(signingInput []byte) ([]byte, error)) (string, error) {
+	registered := jwtClaims{
+		Issuer:    claims.Issuer,
+		Subject:   claims.Subject,
+		Audience:  claims.Audience,
+		JTI:       claims.JTI,
+		IssuedAt:  claims.IssuedAt.Unix(),
+		ExpiresAt: claims.ExpiresAt.Unix(),
+	}
+	if !claims.NotBefore.IsZero() {
+		registered.NotBefore = claims.NotBefore.Unix()
+	}
+	registeredJSON, err := json.Marshal(registered)
+	if err != nil {
+		return "", err
+	}
+
+	// Merge the registered claims over the custom ones into one object
+	all := make(map[string]json.RawMessage, len(claims.Custom)+len(registeredClaims))
+	for name, value := range claims.Custom {
+		all[name] = value
+	}
+	if err := json.Unmarshal(registeredJSON, &all); err != nil {
+		return "", err
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(all)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signature, err := sign([]byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// verifyJWT checks a compact JWS signed with alg and decodes its payload as
+// JWT claims, rejecting tokens outside their validity period by more than
+// leeway. Only alg is accepted, so a token cannot pick a weaker algorithm.
+func verifyJWT(tokenString, alg string, leeway time.Duration, verify func(signingInput, signature []byte) bool) (*TokenClaims, error) {
+	_, payload, err := verifyJWS(tokenString, alg, verify)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := decodeJWTClaims(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkValidity(claims, leeway); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// verifyJWS checks a compact JWS signed with alg and returns its header and
+// decoded payload
+func verifyJWS(tokenString, alg string, verify func(signingInput, signature []byte) bool) (*jwsHeader, []byte, error) {
+	parts := strings.Split(tokenString, ".")
+	if len(parts) != 3 {
+		return nil, nil, ErrMalformedToken
+	}
+
+	header, err := decodeJWSHeader(parts[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	if header.Alg != alg {
+		return nil, nil, fmt.Errorf("%w: unsupported alg %q", ErrTokenSignatureInvalid, header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !verify([]byte(parts[0]+"."+parts[1]), signature) {
+		return nil, nil, ErrTokenSignatureInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, ErrMalformedToken
+	}
+	return header, payload, nil
+}
+
+// decodeJWSHeader decodes the base64url protected header of a compact JWS
+func decodeJWSHeader(encoded string) (*jwsHeader, error) {
+	headerJSON, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	var header jwsHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, ErrMalformedToken
+	}
+	return &header, nil
+}
+
+// decodeJWTClaims decodes a JWT payload into TokenClaims
+func decodeJWTClaims(payload []byte) (*TokenClaims, error) {
+	var registered jwtClaims
+	if err := json.Unmarshal(payload, &registered); err != nil {
+		return nil, ErrMalformedToken
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	claims := &TokenClaims{
+		Issuer:    registered.Issuer,
+		Subject:   registered.Subject,
+		Audience:  registered.Audience,
+		JTI:       registered.JTI,
+		IssuedAt:  unixTime(registered.IssuedAt),
+		NotBefore: unixTime(registered.NotBefore),
+		ExpiresAt: unixTime(registered.ExpiresAt),
+	}
+	claims.setCustom(raw)
+	return claims, nil
+}
+
+// VerifyVCES256 verifies a credential issued as an ES256 compact JWS with
+// a P-256 issuer key, e.g. one resolved from a did:key:zDn... DID, and
+// returns its claims. It applies the same expiry and not-before checks as
+// VerifyVC.
+func VerifyVCES256(tokenString string, publicKey *ecdsa.PublicKey) (*VCClaims, error) {
+	return VerifyVCWithSuite(tokenString, NewES256Suite(nil, publicKey))
+}
+
+// isJWS reports whether a token looks like a compact JWS rather than a PASETO
+func isJWS(tokenString string) bool {
+	return !strings.HasPrefix(tokenString, "v4.") && strings.Count(tokenString, ".") == 2
+}
+
+// jwsPayload returns the decoded payload of a compact JWS without checking
+// the signature
+func jwsPayload(tokenString string) ([]byte, error) {
+	parts := strings.Split(tokenString, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	return payload, nil
+}
@@ -0,0 +1,138 @@
+package vc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+	"github.com/veriglob/veriglob-core/internal/resolver"
+)
+
+func generateP256Key(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	return priv
+}
+
+func TestIssueAndVerifyVCES256(t *testing.T) {
+	priv := generateP256Key(t)
+	issuerDID, _ := did.CreateDIDKeyP256(&priv.PublicKey)
+
+	token, err := IssueVCWithID(issuerDID.DID, "did:key:zSubject", priv, testIdentitySubject("did:key:zSubject"), "urn:uuid:es256")
+	if err != nil {
+		t.Fatalf("Failed to issue: %v", err)
+	}
+	if strings.HasPrefix(token, "v4.") || strings.Count(token, ".") != 2 {
+		t.Fatalf("Expected a compact JWS, got %s", token)
+	}
+
+	// The issuer key resolves from the P-256 did:key
+	key, err := resolver.ResolveDIDPublicKey(issuerDID.DID)
+	if err != nil {
+		t.Fatalf("Failed to resolve issuer: %v", err)
+	}
+	issuerKey, err := key.P256()
+	if err != nil {
+		t.Fatalf("Failed to read P-256 key: %v", err)
+	}
+
+	claims, err := VerifyVCES256(token, issuerKey)
+	if err != nil {
+		t.Fatalf("Failed to verify: %v", err)
+	}
+	if claims.Issuer != issuerDID.DID {
+		t.Errorf("Expected issuer %s, got %s", issuerDID.DID, claims.Issuer)
+	}
+	if claims.GetCredentialID() != "urn:uuid:es256" {
+		t.Errorf("Expected credential ID urn:uuid:es256, got %s", claims.GetCredentialID())
+	}
+	if claims.VC.Type[1] != CredentialTypeIdentity {
+		t.Errorf("Expected type %s, got %v", CredentialTypeIdentity, claims.VC.Type)
+	}
+
+	issuer, err := UnverifiedIssuer(token)
+	if err != nil || issuer != issuerDID.DID {
+		t.Errorf("Expected unverified issuer %s, got %s, %v", issuerDID.DID, issuer, err)
+	}
+	unverified, err := UnverifiedClaims(token)
+	if err != nil || !unverified.ExpiresAt.Equal(claims.ExpiresAt) {
+		t.Errorf("Expected unverified claims to match, got %v", err)
+	}
+}
+
+func TestVerifyVCES256Rejects(t *testing.T) {
+	priv := generateP256Key(t)
+	other := generateP256Key(t)
+
+	token, _ := IssueVC("did:key:zIssuer", "did:key:zSubject", priv, testIdentitySubject("did:key:zSubject"))
+	parts := strings.Split(token, ".")
+
+	noneHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	tampered := []byte(parts[1])
+	tampered[10] ^= 0x01
+
+	tests := []struct {
+		name  string
+		token string
+		key   *ecdsa.PublicKey
+		want  error
+	}{
+		{"wrong key", token, &other.PublicKey, ErrSignatureInvalid},
+		{"tampered payload", parts[0] + "." + string(tampered) + "." + parts[2], &priv.PublicKey, ErrSignatureInvalid},
+		{"alg none", noneHeader + "." + parts[1] + ".", &priv.PublicKey, ErrSignatureInvalid},
+		{"not a JWS", "v4.public.abc", &priv.PublicKey, ErrMalformedToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := VerifyVCES256(tt.token, tt.key); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestVerifyVCES256Expired(t *testing.T) {
+	priv := generateP256Key(t)
+
+	// IssueVC refuses to sign an already expired credential, so sign the claims directly
+	expired, err := NewES256Suite(priv, nil).Sign(&TokenClaims{
+		Issuer:    "did:key:zIssuer",
+		IssuedAt:  time.Now().Add(-2 * time.Hour),
+		ExpiresAt: time.Now().Add(-time.Hour),
+		Custom:    map[string]json.RawMessage{"vc": json.RawMessage(`{"type":["VerifiableCredential"]}`)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	if _, err := VerifyVCES256(expired, &priv.PublicKey); !errors.Is(err, ErrCredentialExpired) {
+		t.Errorf("Expected ErrCredentialExpired, got %v", err)
+	}
+
+	future, _ := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", priv, testIdentitySubject("did:key:zSubject"), IssueOptions{
+		NotBefore: time.Now().Add(time.Hour),
+	})
+	if _, err := VerifyVCES256(future, &priv.PublicKey); err != ErrNotYetValid {
+		t.Errorf("Expected ErrNotYetValid, got %v", err)
+	}
+}
+
+func TestIssueVCUnsupportedKey(t *testing.T) {
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+
+	for _, key := range []interface{}{"not a key", p384} {
+		if _, err := IssueVC("did:key:zIssuer", "did:key:zSubject", key, testIdentitySubject("did:key:zSubject")); err != ErrUnsupportedKey {
+			t.Errorf("Expected ErrUnsupportedKey, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package vc
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"time"
+)
+
+// JWSAlgEdDSA is the JWS algorithm of Ed25519-signed JWTs (RFC 8037)
+const JWSAlgEdDSA = "EdDSA"
+
+// SuiteEdDSAJWS is the name of the EdDSA JWS signature suite
+const SuiteEdDSAJWS = "eddsa-jws"
+
+// EdDSASuite signs compact JWS tokens with an Ed25519 key, the JWT-VC form
+// most SSI verifiers understand. KeyID, when set, is sent as the kid header.
+type EdDSASuite struct {
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+	KeyID      string
+	// Leeway is the clock skew tolerated on exp and nbf; zero uses
+	// DefaultLeeway and a negative value none
+	Leeway time.Duration
+}
+
+// NewEdDSASuite creates an EdDSA suite; either key and keyID may be empty
+func NewEdDSASuite(privateKey ed25519.PrivateKey, publicKey ed25519.PublicKey, keyID string) *EdDSASuite {
+	return &EdDSASuite{PrivateKey: privateKey, PublicKey: publicKey, KeyID: keyID}
+}
+
+// Name returns SuiteEdDSAJWS
+func (s *EdDSASuite) Name() string { return SuiteEdDSAJWS }
+
+// Sign encodes the claims as a compact JWS with typ JWT
+func (s *EdDSASuite) Sign(claims *TokenClaims) (string, error) {
+	if s.PrivateKey == nil {
+		return "", ErrSuiteKeyMissing
+	}
+	if len(s.PrivateKey) != ed25519.PrivateKeySize {
+		return "", ErrUnsupportedKey
+	}
+
+	header := jwsHeader{Alg: JWSAlgEdDSA, Typ: "JWT", Kid: s.KeyID}
+	return signJWT(claims, header, func(signingInput []byte) ([]byte, error) {
+		return ed25519.Sign(s.PrivateKey, signingInput), nil
+	})
+}
+
+// Verify checks a compact JWS's EdDSA signature, expiry and nbf and returns its
+// claims. Only EdDSA is accepted.
+func (s *EdDSASuite) Verify(tokenString string) (*TokenClaims, error) {
+	if s.PublicKey == nil {
+		return nil, ErrSuiteKeyMissing
+	}
+	if len(s.PublicKey) != ed25519.PublicKeySize {
+		return nil, ErrUnsupportedKey
+	}
+
+	return verifyJWT(tokenString, JWSAlgEdDSA, s.Leeway, func(signingInput, signature []byte) bool {
+		return ed25519.Verify(s.PublicKey, signingInput, signature)
+	})
+}
+
+// IssueVCAsJWT issues a credential as an EdDSA-signed JWT-VC, the vc-jwt
+// representation of the W3C VC Data Model, for verifiers that do not read
+// PASETO. The vc claim is a standalone W3C credential as with StrictW3C, nbf
+// carries the issuance date as vc-jwt requires, and the kid header names the
+// issuer's issuerDID#key-1 verification method. Other options apply as with
+// IssueVCWithOptions; opts.Suite and opts.Encoding are ignored.
+func IssueVCAsJWT(
+	issuerDID string,
+	subjectDID string,
+	privateKey ed25519.PrivateKey,
+	subject CredentialSubject,
+	opts IssueOptions,
+) (string, error) {
+	opts.StrictW3C = true
+	opts.Encoding = EncodingJSON
+	if opts.NotBefore.IsZero() {
+		opts.NotBefore = time.Now()
+	}
+	opts.Suite = NewEdDSASuite(privateKey, nil, issuerDID+"#key-1")
+	return IssueVCWithOptions(issuerDID, subjectDID, nil, subject, opts)
+}
+
+// VerifyVCFromJWT verifies a credential issued by IssueVCAsJWT, or any
+// EdDSA JWT-VC, against the issuer's Ed25519 key and returns its claims. A
+// kid header naming a verification method of a DID other than the issuer
+// returns ErrVerificationMethodMismatch.
+func VerifyVCFromJWT(tokenString string, publicKey ed25519.PublicKey) (*VCClaims, error) {
+	claims, err := VerifyVCWithSuite(tokenString, NewEdDSASuite(nil, publicKey, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	// The signature checked out, so the header is authentic
+	encodedHeader, _, _ := strings.Cut(tokenString, ".")
+	header, err := decodeJWSHeader(encodedHeader)
+	if err != nil {
+		return nil, err
+	}
+	if header.Kid != "" {
+		controller, _, _ := strings.Cut(header.Kid, "#")
+		if controller != claims.Issuer {
+			return nil, ErrVerificationMethodMismatch
+		}
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,183 @@
+package vc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+)
+
+// RFC 8037 appendix A.4: an Ed25519 JWS produced by an external
+// implementation. Ed25519 is deterministic, so signing the same input with
+// the same key must reproduce it byte for byte.
+const (
+	rfc8037Seed  = "nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"
+	rfc8037Pub   = "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
+	rfc8037Token = "eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc.hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg"
+)
+
+func TestVerifyJWSExternalFixture(t *testing.T) {
+	seed, _ := base64.RawURLEncoding.DecodeString(rfc8037Seed)
+	pubBytes, _ := base64.RawURLEncoding.DecodeString(rfc8037Pub)
+	priv := ed25519.NewKeyFromSeed(seed)
+	pub := ed25519.PublicKey(pubBytes)
+
+	_, payload, err := verifyJWS(rfc8037Token, JWSAlgEdDSA, func(signingInput, signature []byte) bool {
+		return ed25519.Verify(pub, signingInput, signature)
+	})
+	if err != nil {
+		t.Fatalf("Failed to verify the RFC 8037 JWS: %v", err)
+	}
+	if string(payload) != "Example of Ed25519 signing" {
+		t.Errorf("Expected the RFC 8037 payload, got %q", payload)
+	}
+
+	signingInput := rfc8037Token[:strings.LastIndex(rfc8037Token, ".")]
+	signature := base64.RawURLEncoding.EncodeToString(ed25519.Sign(priv, []byte(signingInput)))
+	if signingInput+"."+signature != rfc8037Token {
+		t.Errorf("Expected signing to reproduce the RFC 8037 JWS, got %s", signingInput+"."+signature)
+	}
+}
+
+// externalJWTVC is an EdDSA JWT-VC in the shape of the W3C VC Data Model
+// vc-jwt examples, signed outside Go with OpenSSL 3 (openssl pkeyutl -sign
+// -rawin) using the RFC 8037 key above. Its issuer is that key's did:key, and
+// the kid names the key by its multibase fragment, as did:key documents do.
+const (
+	externalJWTVCIssuer = "did:key:z6MktwupdmLXVVqTzCw4i46r4uGyosGXRnR3XjN4Zq7oMMsw"
+	externalJWTVC       = "eyJhbGciOiJFZERTQSIsInR5cCI6IkpXVCIsImtpZCI6ImRpZDprZXk6ejZNa3R3dXBkbUxYVlZxVHpDdzRpNDZyNHVHeW9zR1hSblIzWGpONFpxN29NTXN3I3o2TWt0d3VwZG1MWFZWcVR6Q3c0aTQ2cjR1R3lvc0dYUm5SM1hqTjRacTdvTU1zdyJ9.eyJpc3MiOiJkaWQ6a2V5Ono2TWt0d3VwZG1MWFZWcVR6Q3c0aTQ2cjR1R3lvc0dYUm5SM1hqTjRacTdvTU1zdyIsInN1YiI6ImRpZDpleGFtcGxlOmViZmViMWY3MTJlYmM2ZjFjMjc2ZTEyZWMyMSIsImp0aSI6Imh0dHA6Ly9leGFtcGxlLmVkdS9jcmVkZW50aWFscy8zNzMyIiwibmJmIjoxNzA0MDY3MjAwLCJpYXQiOjE3MDQwNjcyMDAsImV4cCI6NDEwMjQ0NDgwMCwidmMiOnsiQGNvbnRleHQiOlsiaHR0cHM6Ly93d3cudzMub3JnLzIwMTgvY3JlZGVudGlhbHMvdjEiLCJodHRwczovL3d3dy53My5vcmcvMjAxOC9jcmVkZW50aWFscy9leGFtcGxlcy92MSJdLCJ0eXBlIjpbIlZlcmlmaWFibGVDcmVkZW50aWFsIiwiVW5pdmVyc2l0eURlZ3JlZUNyZWRlbnRpYWwiXSwiY3JlZGVudGlhbFN1YmplY3QiOnsiZGVncmVlIjp7InR5cGUiOiJCYWNoZWxvckRlZ3JlZSIsIm5hbWUiOiJCYWNoZWxvciBvZiBTY2llbmNlIGFuZCBBcnRzIn19fX0.bn8mQ3k-3mZqQFOFUTwpdbZcMjQfQD_d3xNZU-Wtjs0yOqeqZ7QPsRXmP295FcKdSqknKbBbUaK407KWN4PjDw"
+)
+
+func TestVerifyVCFromJWTExternalFixture(t *testing.T) {
+	pubBytes, _ := base64.RawURLEncoding.DecodeString(rfc8037Pub)
+	pub := ed25519.PublicKey(pubBytes)
+
+	// The issuer's did:key resolves to the RFC 8037 key
+	issuer, err := did.CreateDIDKey(pub)
+	if err != nil || issuer.DID != externalJWTVCIssuer {
+		t.Fatalf("Expected the RFC 8037 key to be %s, got %v (%v)", externalJWTVCIssuer, issuer, err)
+	}
+
+	claims, err := VerifyVCFromJWT(externalJWTVC, pub)
+	if err != nil {
+		t.Fatalf("Failed to verify the external JWT-VC: %v", err)
+	}
+	if claims.Issuer != externalJWTVCIssuer || claims.Subject != "did:example:ebfeb1f712ebc6f1c276e12ec21" {
+		t.Errorf("Expected issuer %s and the example subject, got %s and %s", externalJWTVCIssuer, claims.Issuer, claims.Subject)
+	}
+	if claims.GetCredentialID() != "http://example.edu/credentials/3732" {
+		t.Errorf("Expected the example credential ID, got %s", claims.GetCredentialID())
+	}
+	if !claims.IssuedAt.Equal(time.Unix(1704067200, 0)) {
+		t.Errorf("Expected iat 2024-01-01, got %v", claims.IssuedAt)
+	}
+	if len(claims.VC.Type) != 2 || claims.VC.Type[1] != "UniversityDegreeCredential" {
+		t.Errorf("Expected a UniversityDegreeCredential, got %v", claims.VC.Type)
+	}
+	subject, ok := claims.VC.CredentialSubject.(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected the credential subject as an object, got %T", claims.VC.CredentialSubject)
+	}
+	if degree, _ := subject["degree"].(map[string]interface{}); degree["type"] != "BachelorDegree" {
+		t.Errorf("Expected a BachelorDegree subject, got %v", subject)
+	}
+
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := VerifyVCFromJWT(externalJWTVC, otherPub); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid with another key, got %v", err)
+	}
+}
+
+func TestIssueVCAsJWTRoundTrip(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	issuer, _ := did.CreateDIDKey(pub)
+
+	token, err := IssueVCAsJWT(issuer.DID, "did:key:zSubject", priv, testIdentitySubject("did:key:zSubject"), IssueOptions{CredentialID: "urn:uuid:jwt"})
+	if err != nil {
+		t.Fatalf("Failed to issue: %v", err)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Expected a compact JWS, got %s", token)
+	}
+	header, err := decodeJWSHeader(parts[0])
+	if err != nil {
+		t.Fatalf("Failed to decode header: %v", err)
+	}
+	if header.Alg != JWSAlgEdDSA || header.Typ != "JWT" || header.Kid != issuer.DID+"#key-1" {
+		t.Errorf("Expected EdDSA JWT header with kid %s#key-1, got %+v", issuer.DID, header)
+	}
+
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var raw struct {
+		NotBefore int64 `json:"nbf"`
+		VC        struct {
+			Context []string `json:"@context"`
+			Type    []string `json:"type"`
+		} `json:"vc"`
+	}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if raw.NotBefore == 0 {
+		t.Error("Expected nbf to carry the issuance date")
+	}
+	if len(raw.VC.Context) == 0 || raw.VC.Context[0] != CredentialsContextV1 {
+		t.Errorf("Expected vc @context %s, got %v", CredentialsContextV1, raw.VC.Context)
+	}
+
+	claims, err := VerifyVCFromJWT(token, pub)
+	if err != nil {
+		t.Fatalf("Failed to verify: %v", err)
+	}
+	if claims.Issuer != issuer.DID || claims.GetCredentialID() != "urn:uuid:jwt" {
+		t.Errorf("Expected issuer %s and ID urn:uuid:jwt, got %s and %s", issuer.DID, claims.Issuer, claims.GetCredentialID())
+	}
+	if err := claims.VC.ValidateW3C(); err != nil {
+		t.Errorf("Expected a conformant vc claim, got %v", err)
+	}
+
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := VerifyVCFromJWT(token, otherPub); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid, got %v", err)
+	}
+
+	// A PASETO credential is not a JWT-VC
+	pasetoToken, _ := IssueVC(issuer.DID, "did:key:zSubject", priv, testIdentitySubject("did:key:zSubject"))
+	if _, err := VerifyVCFromJWT(pasetoToken, pub); err == nil {
+		t.Error("Expected a PASETO token to be rejected")
+	}
+}
+
+func TestVerifyVCFromJWTKeyIDMismatch(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+
+	token, err := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", nil, testIdentitySubject("did:key:zSubject"), IssueOptions{
+		Suite: NewEdDSASuite(priv, nil, "did:key:zSomeoneElse#key-1"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to issue: %v", err)
+	}
+	if _, err := VerifyVCFromJWT(token, pub); err != ErrVerificationMethodMismatch {
+		t.Errorf("Expected ErrVerificationMethodMismatch, got %v", err)
+	}
+}
+
+func TestEdDSASuiteExpired(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	token, _ := NewEdDSASuite(priv, nil, "").Sign(&TokenClaims{
+		Issuer:    "did:key:zIssuer",
+		IssuedAt:  time.Now().Add(-2 * time.Hour),
+		ExpiresAt: time.Now().Add(-time.Hour),
+	})
+	if _, err := NewEdDSASuite(nil, pub, "").Verify(token); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Expected ErrTokenExpired, got %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package vc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+)
+
+var (
+	ErrNoCredentialSchema    = errors.New("credential has no credentialSchema")
+	ErrUnsupportedSchemaType = errors.New("unsupported credential schema type")
+	ErrSchemaUnavailable     = errors.New("credential schema unavailable")
+	ErrSchemaValidation      = errors.New("credential subject does not match its schema")
+)
+
+// CredentialSchemaTypeJSONSchema is the credentialSchema type for a JSON Schema document
+const CredentialSchemaTypeJSONSchema = "JsonSchemaValidator2018"
+
+// maxSchemaSize bounds the schema document read from the network
+const maxSchemaSize = 1 << 20
+
+// CredentialSchema points verifiers at the schema the credential subject
+// conforms to
+type CredentialSchema struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// jsonSchema is the subset of JSON Schema checked by ValidateAgainstSchema
+type jsonSchema struct {
+	Type       interface{}            `json:"type"` // a type name or a list of them
+	Required   []string               `json:"required"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Items      *jsonSchema            `json:"items"`
+	Enum       []interface{}          `json:"enum"`
+}
+
+// ValidateAgainstSchema fetches the credential's JsonSchemaValidator2018
+// schema with client (nil uses http.DefaultClient) and validates every
+// credential subject against it. Only the type, required, properties, items
+// and enum keywords are checked; other keywords are ignored.
+func ValidateAgainstSchema(ctx context.Context, claims *VCClaims, client *http.Client) error {
+	ref := claims.VC.CredentialSchema
+	if ref == nil {
+		return ErrNoCredentialSchema
+	}
+	if ref.Type != CredentialSchemaTypeJSONSchema {
+		return fmt.Errorf("%w: %s", ErrUnsupportedSchemaType, ref.Type)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	schema, err := fetchSchema(ctx, client, ref.ID)
+	if err != nil {
+		return err
+	}
+
+	subjects := claims.Subjects()
+	if len(subjects) == 0 {
+		return fmt.Errorf("%w: missing credentialSubject", ErrSchemaValidation)
+	}
+	for _, subject := range subjects {
+		if err := schema.validate(subject, "credentialSubject"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchSchema downloads and parses a JSON Schema document
+func fetchSchema(ctx context.Context, client *http.Client, schemaURL string) (*jsonSchema, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, schemaURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSchemaUnavailable, err)
+	}
+	req.Header.Set("Accept", "application/schema+json, application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSchemaUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrSchemaUnavailable, resp.StatusCode)
+	}
+
+	var schema jsonSchema
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxSchemaSize)).Decode(&schema); err != nil {
+		return nil, fmt.Errorf("%w: invalid schema: %v", ErrSchemaUnavailable, err)
+	}
+	return &schema, nil
+}
+
+// validate checks value, found at path, against the schema
+func (s *jsonSchema) validate(value interface{}, path string) error {
+	if s == nil {
+		return nil
+	}
+
+	if s.Type != nil && !s.allowsType(value) {
+		return fmt.Errorf("%w: %s must be of type %v", ErrSchemaValidation, path, s.Type)
+	}
+
+	if len(s.Enum) > 0 {
+		allowed := false
+		for _, e := range s.Enum {
+			if reflect.DeepEqual(e, value) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: %s is not one of %v", ErrSchemaValidation, path, s.Enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%w: %s.%s is required", ErrSchemaValidation, path, name)
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if field, ok := v[name]; ok {
+				if err := s.Properties[name].validate(field, path+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// allowsType reports whether value matches the schema's type keyword
+func (s *jsonSchema) allowsType(value interface{}) bool {
+	switch t := s.Type.(type) {
+	case string:
+		return matchesType(t, value)
+	case []interface{}:
+		for _, name := range t {
+			if name, ok := name.(string); ok && matchesType(name, value) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// matchesType reports whether a decoded JSON value is of a JSON Schema type
+func matchesType(typeName string, value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return typeName == "null"
+	case bool:
+		return typeName == "boolean"
+	case string:
+		return typeName == "string"
+	case float64:
+		return typeName == "number" || (typeName == "integer" && v == math.Trunc(v))
+	case []interface{}:
+		return typeName == "array"
+	case map[string]interface{}:
+		return typeName == "object"
+	default:
+		return false
+	}
+}
@@ -0,0 +1,123 @@
+package vc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const identitySchemaJSON = `{
+	"$schema": "http://json-schema.org/draft-07/schema#",
+	"type": "object",
+	"required": ["id", "givenName", "familyName", "dateOfBirth"],
+	"properties": {
+		"id": {"type": "string"},
+		"givenName": {"type": "string"},
+		"familyName": {"type": "string"},
+		"dateOfBirth": {"type": "string"},
+		"verifiedLevel": {"type": "string", "enum": ["low", "substantial", "high"]}
+	}
+}`
+
+func newSchemaServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/schemas/identity.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/schema+json")
+		w.Write([]byte(identitySchemaJSON))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func issueWithSchema(t *testing.T, subject CredentialSubject, schema *CredentialSchema) *VCClaims {
+	t.Helper()
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	token, err := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", issuerPriv, subject, IssueOptions{CredentialSchema: schema})
+	if err != nil {
+		t.Fatalf("IssueVCWithOptions failed: %v", err)
+	}
+	claims, err := VerifyVC(token, issuerPub)
+	if err != nil {
+		t.Fatalf("VerifyVC failed: %v", err)
+	}
+	return claims
+}
+
+func TestCredentialSchemaRoundTrip(t *testing.T) {
+	schema := &CredentialSchema{ID: "https://issuer.example.com/schemas/identity.json", Type: CredentialSchemaTypeJSONSchema}
+
+	claims := issueWithSchema(t, testIdentitySubject("did:key:zSubject"), schema)
+	if claims.VC.CredentialSchema == nil || *claims.VC.CredentialSchema != *schema {
+		t.Errorf("Expected credential schema %v, got %v", schema, claims.VC.CredentialSchema)
+	}
+
+	claims = issueWithSchema(t, testIdentitySubject("did:key:zSubject"), nil)
+	if claims.VC.CredentialSchema != nil {
+		t.Errorf("Expected no credential schema, got %v", claims.VC.CredentialSchema)
+	}
+	data, _ := json.Marshal(claims.VC)
+	var body map[string]interface{}
+	json.Unmarshal(data, &body)
+	if _, ok := body["credentialSchema"]; ok {
+		t.Errorf("Expected credentialSchema to be omitted, got %s", data)
+	}
+}
+
+func TestValidateAgainstSchema(t *testing.T) {
+	server := newSchemaServer(t)
+	schema := &CredentialSchema{ID: server.URL + "/schemas/identity.json", Type: CredentialSchemaTypeJSONSchema}
+
+	valid := testIdentitySubject("did:key:zSubject")
+	valid.VerifiedLevel = "high"
+	claims := issueWithSchema(t, valid, schema)
+	if err := ValidateAgainstSchema(context.Background(), claims, server.Client()); err != nil {
+		t.Errorf("Expected subject to match its schema, got %v", err)
+	}
+
+	invalid := testIdentitySubject("did:key:zSubject")
+	invalid.VerifiedLevel = "unverified"
+	claims = issueWithSchema(t, invalid, schema)
+	if err := ValidateAgainstSchema(context.Background(), claims, server.Client()); !errors.Is(err, ErrSchemaValidation) {
+		t.Errorf("Expected ErrSchemaValidation for a value outside the enum, got %v", err)
+	}
+
+	// A required field removed after issuance, e.g. by a different stack
+	claims = issueWithSchema(t, testIdentitySubject("did:key:zSubject"), schema)
+	claims.VC.CredentialSubject = map[string]interface{}{"id": "did:key:zSubject", "givenName": "Alice"}
+	if err := ValidateAgainstSchema(context.Background(), claims, server.Client()); !errors.Is(err, ErrSchemaValidation) {
+		t.Errorf("Expected ErrSchemaValidation for a missing required field, got %v", err)
+	}
+}
+
+func TestValidateAgainstSchemaErrors(t *testing.T) {
+	server := newSchemaServer(t)
+
+	tests := []struct {
+		name   string
+		schema *CredentialSchema
+		want   error
+	}{
+		{"no schema", nil, ErrNoCredentialSchema},
+		{"unsupported type", &CredentialSchema{ID: server.URL + "/schemas/identity.json", Type: "ShaclValidator2017"}, ErrUnsupportedSchemaType},
+		{"schema not found", &CredentialSchema{ID: server.URL + "/schemas/missing.json", Type: CredentialSchemaTypeJSONSchema}, ErrSchemaUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := issueWithSchema(t, testIdentitySubject("did:key:zSubject"), tt.schema)
+			if err := ValidateAgainstSchema(context.Background(), claims, server.Client()); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
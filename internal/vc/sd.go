@@ -0,0 +1,280 @@
+package vc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrInvalidDisclosure   = errors.New("invalid selective disclosure")
+	ErrDisclosureMismatch  = errors.New("disclosure does not match any digest in the credential")
+	ErrDuplicateDisclosure = errors.New("claim disclosed more than once")
+	ErrDisclosureNotFound  = errors.New("credential has no disclosure for the claim")
+	ErrNotSDCredential     = errors.New("credential is not a selective disclosure credential")
+)
+
+const (
+	// SDSeparator separates the signed token from its disclosures, as in SD-JWT
+	SDSeparator = "~"
+
+	// SDAlgSHA256 is the digest algorithm of disclosures, named as in SD-JWT
+	SDAlgSHA256 = "sha-256"
+
+	// sdSaltSize is the number of random bytes salting each disclosure
+	sdSaltSize = 16
+)
+
+// Disclosure is one selectively disclosable claim: a salted name and value.
+// The credential signs only its digest, so the claim stays hidden until the
+// holder hands over the disclosure.
+type Disclosure struct {
+	Salt  string
+	Name  string
+	Value json.RawMessage
+	// Encoded is the base64url JSON array [salt, name, value] the digest covers
+	Encoded string
+}
+
+// Digest returns the base64url SHA-256 digest of the encoded disclosure
+func (d *Disclosure) Digest() string {
+	sum := sha256.Sum256([]byte(d.Encoded))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// newDisclosure salts a claim and encodes it
+func newDisclosure(name string, value json.RawMessage) (*Disclosure, error) {
+	saltBytes := make([]byte, sdSaltSize)
+	if _, err := rand.Read(saltBytes); err != nil {
+		return nil, err
+	}
+	salt := base64.RawURLEncoding.EncodeToString(saltBytes)
+
+	encoded, err := json.Marshal([]interface{}{salt, name, value})
+	if err != nil {
+		return nil, err
+	}
+	return &Disclosure{
+		Salt:    salt,
+		Name:    name,
+		Value:   value,
+		Encoded: base64.RawURLEncoding.EncodeToString(encoded),
+	}, nil
+}
+
+// ParseDisclosure decodes an encoded disclosure
+func ParseDisclosure(encoded string) (*Disclosure, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidDisclosure
+	}
+	var parts []json.RawMessage
+	if err := json.Unmarshal(raw, &parts); err != nil || len(parts) != 3 {
+		return nil, ErrInvalidDisclosure
+	}
+
+	d := &Disclosure{Value: parts[2], Encoded: encoded}
+	if err := json.Unmarshal(parts[0], &d.Salt); err != nil {
+		return nil, ErrInvalidDisclosure
+	}
+	if err := json.Unmarshal(parts[1], &d.Name); err != nil || d.Name == "" {
+		return nil, ErrInvalidDisclosure
+	}
+	return d, nil
+}
+
+// sdSubject is the signed credentialSubject of a selective disclosure
+// credential: the subject ID in the clear and a digest per hidden claim
+type sdSubject struct {
+	ID      string   `json:"id,omitempty"`
+	Digests []string `json:"_sd"`
+	Alg     string   `json:"_sd_alg"`
+
+	credentialType string
+}
+
+func (s *sdSubject) GetID() string          { return s.ID }
+func (s *sdSubject) CredentialType() string { return s.credentialType }
+
+// IssueSDVC issues a selective disclosure credential. Every subject claim
+// except id is replaced in the signed credential by the digest of a salted
+// disclosure, and the disclosures are appended to the token in the SD-JWT
+// combined format <token>~<disclosure>~...~. The holder strips the ones it
+// does not want to reveal with SelectDisclosures, e.g. presenting only
+// dateOfBirth of an IdentitySubject. The subject is validated, and opts
+// applied, as with IssueVCWithOptions.
+func IssueSDVC(
+	issuerDID string,
+	subjectDID string,
+	privateKey interface{},
+	subject CredentialSubject,
+	opts IssueOptions,
+) (string, error) {
+	if v, ok := subject.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return "", err
+		}
+	}
+
+	subjectJSON, err := json.Marshal(subject)
+	if err != nil {
+		return "", err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(subjectJSON, &fields); err != nil {
+		return "", err
+	}
+
+	sd := &sdSubject{ID: subject.GetID(), Alg: SDAlgSHA256, credentialType: subject.CredentialType()}
+	disclosures := make([]string, 0, len(fields))
+	for name, value := range fields {
+		if name == "id" {
+			continue
+		}
+		d, err := newDisclosure(name, value)
+		if err != nil {
+			return "", err
+		}
+		sd.Digests = append(sd.Digests, d.Digest())
+		disclosures = append(disclosures, d.Encoded)
+	}
+	// Sorted digests do not reveal the order of the claims
+	sort.Strings(sd.Digests)
+
+	token, err := IssueVCWithSubjects(issuerDID, subjectDID, privateKey, []CredentialSubject{sd}, opts)
+	if err != nil {
+		return "", err
+	}
+	return joinSDCredential(token, disclosures), nil
+}
+
+// joinSDCredential builds the combined format from a token and disclosures
+func joinSDCredential(token string, disclosures []string) string {
+	var b strings.Builder
+	b.WriteString(token)
+	b.WriteString(SDSeparator)
+	for _, d := range disclosures {
+		b.WriteString(d)
+		b.WriteString(SDSeparator)
+	}
+	return b.String()
+}
+
+// IsSDCredential reports whether a credential is in the combined
+// selective disclosure format
+func IsSDCredential(credential string) bool {
+	return strings.Contains(credential, SDSeparator)
+}
+
+// SplitSDCredential separates a combined selective disclosure credential
+// into its signed token and encoded disclosures
+func SplitSDCredential(credential string) (string, []string, error) {
+	parts := strings.Split(credential, SDSeparator)
+	if len(parts) < 2 || parts[0] == "" || parts[len(parts)-1] != "" {
+		return "", nil, ErrNotSDCredential
+	}
+	return parts[0], parts[1 : len(parts)-1], nil
+}
+
+// SelectDisclosures returns the credential with only the disclosures of the
+// named claims, for the holder to present. A name the credential has no
+// disclosure for returns ErrDisclosureNotFound.
+func SelectDisclosures(credential string, names ...string) (string, error) {
+	token, encoded, err := SplitSDCredential(credential)
+	if err != nil {
+		return "", err
+	}
+
+	byName := make(map[string]string, len(encoded))
+	for _, e := range encoded {
+		d, err := ParseDisclosure(e)
+		if err != nil {
+			return "", err
+		}
+		byName[d.Name] = e
+	}
+
+	selected := make([]string, 0, len(names))
+	for _, name := range names {
+		e, ok := byName[name]
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrDisclosureNotFound, name)
+		}
+		selected = append(selected, e)
+	}
+	return joinSDCredential(token, selected), nil
+}
+
+// VerifySDVC verifies a selective disclosure credential against the issuer's
+// Ed25519 key. See VerifySDVCWithSuite.
+func VerifySDVC(credential string, publicKey ed25519.PublicKey) (*VCClaims, error) {
+	return VerifySDVCWithSuite(credential, NewPasetoV4Suite(nil, publicKey))
+}
+
+// VerifySDVCWithSuite verifies the signed token of a selective disclosure
+// credential and checks each presented disclosure against its digests. The
+// returned claims' credentialSubject holds the subject id and the disclosed
+// claims only. A disclosure the issuer did not sign returns
+// ErrDisclosureMismatch, and one presented twice ErrDuplicateDisclosure.
+func VerifySDVCWithSuite(credential string, suite SignatureSuite) (*VCClaims, error) {
+	token, encoded, err := SplitSDCredential(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := VerifyVCWithSuite(token, suite)
+	if err != nil {
+		return nil, err
+	}
+
+	subjectJSON, err := json.Marshal(claims.VC.CredentialSubject)
+	if err != nil {
+		return nil, err
+	}
+	var signed struct {
+		ID      string   `json:"id"`
+		Digests []string `json:"_sd"`
+		Alg     string   `json:"_sd_alg"`
+	}
+	if err := json.Unmarshal(subjectJSON, &signed); err != nil || signed.Alg != SDAlgSHA256 {
+		return nil, ErrNotSDCredential
+	}
+	digests := make(map[string]bool, len(signed.Digests))
+	for _, digest := range signed.Digests {
+		digests[digest] = false
+	}
+
+	subject := map[string]interface{}{}
+	if signed.ID != "" {
+		subject["id"] = signed.ID
+	}
+	for _, e := range encoded {
+		d, err := ParseDisclosure(e)
+		if err != nil {
+			return nil, err
+		}
+		used, ok := digests[d.Digest()]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrDisclosureMismatch, d.Name)
+		}
+		if _, exists := subject[d.Name]; used || exists {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateDisclosure, d.Name)
+		}
+		digests[d.Digest()] = true
+
+		var value interface{}
+		if err := json.Unmarshal(d.Value, &value); err != nil {
+			return nil, ErrInvalidDisclosure
+		}
+		subject[d.Name] = value
+	}
+
+	claims.VC.CredentialSubject = subject
+	return claims, nil
+}
@@ -0,0 +1,121 @@
+package vc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func issueTestSDVC(t *testing.T) (string, ed25519.PublicKey) {
+	t.Helper()
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	subject := testIdentitySubject("did:key:zSubject")
+	subject.Nationality = "NL"
+
+	credential, err := IssueSDVC("did:key:zIssuer", "did:key:zSubject", priv, subject, IssueOptions{CredentialID: "urn:uuid:sd"})
+	if err != nil {
+		t.Fatalf("Failed to issue: %v", err)
+	}
+	return credential, pub
+}
+
+func TestIssueSDVCHidesClaims(t *testing.T) {
+	credential, _ := issueTestSDVC(t)
+
+	token, disclosures, err := SplitSDCredential(credential)
+	if err != nil {
+		t.Fatalf("Failed to split: %v", err)
+	}
+	// givenName, familyName, dateOfBirth and nationality
+	if len(disclosures) != 4 {
+		t.Errorf("Expected 4 disclosures, got %d", len(disclosures))
+	}
+
+	claims, err := UnverifiedClaims(token)
+	if err != nil {
+		t.Fatalf("Failed to decode token: %v", err)
+	}
+	subjectJSON, _ := json.Marshal(claims.VC.CredentialSubject)
+	if strings.Contains(string(subjectJSON), "1990-01-01") || strings.Contains(string(subjectJSON), "Alice") {
+		t.Errorf("Expected the signed subject to hide claim values, got %s", subjectJSON)
+	}
+	if !strings.Contains(string(subjectJSON), `"_sd_alg":"sha-256"`) {
+		t.Errorf("Expected _sd_alg sha-256, got %s", subjectJSON)
+	}
+
+	// The combined form is still readable without verification
+	if issuer, err := UnverifiedIssuer(credential); err != nil || issuer != "did:key:zIssuer" {
+		t.Errorf("Expected issuer did:key:zIssuer, got %q (%v)", issuer, err)
+	}
+}
+
+func TestSelectDisclosuresAndVerify(t *testing.T) {
+	credential, pub := issueTestSDVC(t)
+
+	presented, err := SelectDisclosures(credential, "dateOfBirth")
+	if err != nil {
+		t.Fatalf("Failed to select: %v", err)
+	}
+
+	claims, err := VerifySDVC(presented, pub)
+	if err != nil {
+		t.Fatalf("Failed to verify: %v", err)
+	}
+	subject, ok := claims.VC.CredentialSubject.(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected a subject map, got %T", claims.VC.CredentialSubject)
+	}
+	if subject["dateOfBirth"] != "1990-01-01" || subject["id"] != "did:key:zSubject" {
+		t.Errorf("Expected id and dateOfBirth, got %v", subject)
+	}
+	if _, ok := subject["givenName"]; ok || len(subject) != 2 {
+		t.Errorf("Expected only id and dateOfBirth, got %v", subject)
+	}
+	if claims.GetCredentialID() != "urn:uuid:sd" {
+		t.Errorf("Expected credential ID urn:uuid:sd, got %s", claims.GetCredentialID())
+	}
+
+	// Disclosing nothing still proves the credential
+	bare, _ := SelectDisclosures(credential)
+	if _, err := VerifySDVC(bare, pub); err != nil {
+		t.Errorf("Expected a credential with no disclosures to verify, got %v", err)
+	}
+
+	if _, err := SelectDisclosures(credential, "ssn"); !errors.Is(err, ErrDisclosureNotFound) {
+		t.Errorf("Expected ErrDisclosureNotFound, got %v", err)
+	}
+}
+
+func TestVerifySDVCRejectsForgedDisclosures(t *testing.T) {
+	credential, pub := issueTestSDVC(t)
+	token, disclosures, _ := SplitSDCredential(credential)
+
+	forgedJSON, _ := json.Marshal([]interface{}{"salt", "dateOfBirth", "2015-01-01"})
+	forged := base64.RawURLEncoding.EncodeToString(forgedJSON)
+
+	tests := []struct {
+		name        string
+		disclosures []string
+		want        error
+	}{
+		{"unsigned claim", []string{forged}, ErrDisclosureMismatch},
+		{"duplicate", []string{disclosures[0], disclosures[0]}, ErrDuplicateDisclosure},
+		{"not base64", []string{"!!!"}, ErrInvalidDisclosure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			combined := joinSDCredential(token, tt.disclosures)
+			if _, err := VerifySDVC(combined, pub); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := VerifySDVC(token, pub); err != ErrNotSDCredential {
+		t.Errorf("Expected ErrNotSDCredential, got %v", err)
+	}
+}
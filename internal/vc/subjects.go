@@ -1,11 +1,17 @@
 package vc
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 // Credential type constants
 const (
 	CredentialTypeIdentity   = "IdentityCredential"
 	CredentialTypeEducation  = "EducationCredential"
 	CredentialTypeEmployment = "EmploymentCredential"
 	CredentialTypeMembership = "MembershipCredential"
+	CredentialTypeDelegation = "DelegationCredential"
 )
 
 // CredentialSubject is the interface all credential subjects must implement
@@ -33,6 +39,16 @@ type IdentitySubject struct {
 func (s IdentitySubject) GetID() string          { return s.ID }
 func (s IdentitySubject) CredentialType() string { return CredentialTypeIdentity }
 
+// Validate checks the required identity fields are present
+func (s IdentitySubject) Validate() error {
+	return requireFields(
+		requiredField{"id", s.ID},
+		requiredField{"givenName", s.GivenName},
+		requiredField{"familyName", s.FamilyName},
+		requiredField{"dateOfBirth", s.DateOfBirth},
+	)
+}
+
 // EducationSubject represents educational credentials
 type EducationSubject struct {
 	ID              string `json:"id"`
@@ -51,6 +67,14 @@ type EducationSubject struct {
 func (s EducationSubject) GetID() string          { return s.ID }
 func (s EducationSubject) CredentialType() string { return CredentialTypeEducation }
 
+// Validate checks the required education fields are present
+func (s EducationSubject) Validate() error {
+	return requireFields(
+		requiredField{"id", s.ID},
+		requiredField{"institutionName", s.InstitutionName},
+	)
+}
+
 // EmploymentSubject represents employment credentials
 type EmploymentSubject struct {
 	ID              string `json:"id"`
@@ -68,6 +92,16 @@ type EmploymentSubject struct {
 func (s EmploymentSubject) GetID() string          { return s.ID }
 func (s EmploymentSubject) CredentialType() string { return CredentialTypeEmployment }
 
+// Validate checks the required employment fields are present
+func (s EmploymentSubject) Validate() error {
+	return requireFields(
+		requiredField{"id", s.ID},
+		requiredField{"employerName", s.EmployerName},
+		requiredField{"jobTitle", s.JobTitle},
+		requiredField{"startDate", s.StartDate},
+	)
+}
+
 // MembershipSubject represents organization membership credentials
 type MembershipSubject struct {
 	ID               string   `json:"id"`
@@ -85,3 +119,74 @@ type MembershipSubject struct {
 
 func (s MembershipSubject) GetID() string          { return s.ID }
 func (s MembershipSubject) CredentialType() string { return CredentialTypeMembership }
+
+// Validate checks the required membership fields are present
+func (s MembershipSubject) Validate() error {
+	return requireFields(
+		requiredField{"id", s.ID},
+		requiredField{"organizationName", s.OrganizationName},
+		requiredField{"startDate", s.StartDate},
+	)
+}
+
+// GenericSubject is a subject of a credential type this package does not
+// define, e.g. a professional license. Its claims are issued as given, next
+// to the subject id, so integrators need no Go type per credential type.
+type GenericSubject struct {
+	ID     string
+	Type   string
+	Claims map[string]interface{}
+}
+
+// NewGenericSubject creates a subject of the given credential type
+func NewGenericSubject(id, credentialType string, claims map[string]interface{}) GenericSubject {
+	return GenericSubject{ID: id, Type: credentialType, Claims: claims}
+}
+
+func (s GenericSubject) GetID() string          { return s.ID }
+func (s GenericSubject) CredentialType() string { return s.Type }
+
+// Validate checks the subject has an id and a type, and that its claims do
+// not redefine either
+func (s GenericSubject) Validate() error {
+	if err := requireFields(
+		requiredField{"id", s.ID},
+		requiredField{"type", s.Type},
+	); err != nil {
+		return err
+	}
+	if s.Type == "VerifiableCredential" {
+		return fmt.Errorf("%w: type must name a specific credential type", ErrInvalidSubjectFields)
+	}
+	if id, ok := s.Claims["id"]; ok && id != s.ID {
+		return fmt.Errorf("%w: claims set a different id", ErrInvalidSubjectFields)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the claims as a flat object with the subject id
+func (s GenericSubject) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]interface{}, len(s.Claims)+1)
+	for name, value := range s.Claims {
+		fields[name] = value
+	}
+	fields["id"] = s.ID
+	return json.Marshal(fields)
+}
+
+// GenericSubject returns the credential's subject as a GenericSubject of
+// the credential's type, whatever that type is
+func (c *VCClaims) GenericSubject() (GenericSubject, error) {
+	fields, err := c.SubjectMap()
+	if err != nil {
+		return GenericSubject{}, err
+	}
+	id, _ := fields["id"].(string)
+	claims := make(map[string]interface{}, len(fields))
+	for name, value := range fields {
+		if name != "id" {
+			claims[name] = value
+		}
+	}
+	return GenericSubject{ID: id, Type: c.CredentialType(), Claims: claims}, nil
+}
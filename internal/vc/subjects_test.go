@@ -1,6 +1,10 @@
 package vc
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -56,3 +60,102 @@ func TestCredentialSubjects(t *testing.T) {
 		})
 	}
 }
+
+func TestIssueRejectsMissingRequiredFields(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name      string
+		subject   CredentialSubject
+		wantField string
+	}{
+		{"identity without id", IdentitySubject{GivenName: "Alice", FamilyName: "Doe", DateOfBirth: "1990-01-01"}, "id"},
+		{"identity without given name", IdentitySubject{ID: "did:key:zSubject", FamilyName: "Doe", DateOfBirth: "1990-01-01"}, "givenName"},
+		{"identity without family name", IdentitySubject{ID: "did:key:zSubject", GivenName: "Alice", DateOfBirth: "1990-01-01"}, "familyName"},
+		{"identity without date of birth", IdentitySubject{ID: "did:key:zSubject", GivenName: "Alice", FamilyName: "Doe"}, "dateOfBirth"},
+		{"education without institution", EducationSubject{ID: "did:key:zSubject"}, "institutionName"},
+		{"employment without employer", EmploymentSubject{ID: "did:key:zSubject", JobTitle: "Engineer", StartDate: "2021-06-01"}, "employerName"},
+		{"employment without job title", EmploymentSubject{ID: "did:key:zSubject", EmployerName: "Tech Corp", StartDate: "2021-06-01"}, "jobTitle"},
+		{"employment without start date", EmploymentSubject{ID: "did:key:zSubject", EmployerName: "Tech Corp", JobTitle: "Engineer"}, "startDate"},
+		{"membership without organization", MembershipSubject{ID: "did:key:zSubject", StartDate: "2024-01-01"}, "organizationName"},
+		{"membership without start date", MembershipSubject{ID: "did:key:zSubject", OrganizationName: "Developers Association"}, "startDate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", priv, tt.subject, IssueOptions{})
+			if !errors.Is(err, ErrMissingRequiredField) {
+				t.Fatalf("Expected ErrMissingRequiredField, got %v", err)
+			}
+			if !strings.HasSuffix(err.Error(), ": "+tt.wantField) {
+				t.Errorf("Expected error naming %s, got %v", tt.wantField, err)
+			}
+			if token != "" {
+				t.Error("Expected no token for an invalid subject")
+			}
+		})
+	}
+}
+
+func TestIssueGenericSubject(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	subject := NewGenericSubject("did:key:zSubject", "ProfessionalLicenseCredential", map[string]interface{}{
+		"licenseNumber": "PL-1234",
+		"profession":    "Pharmacist",
+		"active":        true,
+	})
+
+	token, err := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", priv, subject, IssueOptions{})
+	if err != nil {
+		t.Fatalf("Failed to issue: %v", err)
+	}
+
+	claims, err := VerifyVC(token, pub)
+	if err != nil {
+		t.Fatalf("Failed to verify: %v", err)
+	}
+	if claims.CredentialType() != "ProfessionalLicenseCredential" {
+		t.Errorf("Expected ProfessionalLicenseCredential, got %s", claims.CredentialType())
+	}
+	if claims.IsKnownType() {
+		t.Error("Expected an unregistered type")
+	}
+
+	got, err := claims.GenericSubject()
+	if err != nil {
+		t.Fatalf("Failed to read subject: %v", err)
+	}
+	if got.ID != "did:key:zSubject" || got.Type != "ProfessionalLicenseCredential" {
+		t.Errorf("Expected id and type to round-trip, got %s and %s", got.ID, got.Type)
+	}
+	if got.Claims["licenseNumber"] != "PL-1234" || got.Claims["active"] != true {
+		t.Errorf("Expected claims to round-trip, got %v", got.Claims)
+	}
+	if _, ok := got.Claims["id"]; ok {
+		t.Error("Expected id to be kept out of Claims")
+	}
+	if subject, _ := claims.SubjectMap(); subject["id"] != "did:key:zSubject" {
+		t.Error("Expected reading the generic subject to leave the credential untouched")
+	}
+}
+
+func TestGenericSubjectValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject GenericSubject
+		want    error
+	}{
+		{"missing id", NewGenericSubject("", "CustomCredential", nil), ErrMissingRequiredField},
+		{"missing type", NewGenericSubject("did:key:zSubject", "", nil), ErrMissingRequiredField},
+		{"base type", NewGenericSubject("did:key:zSubject", "VerifiableCredential", nil), ErrInvalidSubjectFields},
+		{"conflicting id", NewGenericSubject("did:key:zSubject", "CustomCredential", map[string]interface{}{"id": "did:key:zOther"}), ErrInvalidSubjectFields},
+	}
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := IssueVC("did:key:zIssuer", "did:key:zSubject", priv, tt.subject); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
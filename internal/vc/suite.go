@@ -0,0 +1,190 @@
+package vc
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+)
+
+var (
+	ErrTokenSignatureInvalid = errors.New("token signature is invalid")
+	ErrTokenExpired          = errors.New("token expired")
+	ErrTokenNotYetValid      = errors.New("token is not valid yet")
+	ErrSuiteKeyMissing       = errors.New("signature suite has no key for this operation")
+)
+
+// Signature suite names
+const (
+	SuitePasetoV4 = "paseto-v4-public"
+	SuiteES256JWS = "es256-jws"
+)
+
+// DefaultLeeway is the clock skew tolerated when checking a token's exp and
+// nbf, so a verifier whose clock is slightly off from the signer's does not
+// reject a token just issued or just about to expire
+const DefaultLeeway = time.Minute
+
+// TokenClaims are the claims of a signed token, independent of its encoding.
+// Each suite encodes the registered time claims the way its format requires.
+type TokenClaims struct {
+	Issuer    string
+	Subject   string
+	Audience  string
+	JTI       string
+	IssuedAt  time.Time
+	NotBefore time.Time // zero omits the claim
+	ExpiresAt time.Time
+	// Custom holds every other claim as JSON, e.g. vc, vp, cnf or nonce
+	Custom map[string]json.RawMessage
+}
+
+// SignatureSuite signs claims into a token and verifies tokens back into
+// claims. Verify checks the signature, expiry and nbf; callers check
+// everything else. A failed signature wraps ErrTokenSignatureInvalid, an
+// expired token ErrTokenExpired and one whose nbf is in the future
+// ErrTokenNotYetValid.
+type SignatureSuite interface {
+	Name() string
+	Sign(claims *TokenClaims) (string, error)
+	Verify(token string) (*TokenClaims, error)
+}
+
+// registeredClaims are the claim names TokenClaims holds as fields
+var registeredClaims = []string{"iss", "sub", "aud", "jti", "iat", "nbf", "exp"}
+
+// checkValidity checks a token's exp and nbf against the clock, allowing
+// leeway either way. Zero leeway uses DefaultLeeway and a negative one
+// tolerates no skew.
+func checkValidity(claims *TokenClaims, leeway time.Duration) error {
+	switch {
+	case leeway == 0:
+		leeway = DefaultLeeway
+	case leeway < 0:
+		leeway = 0
+	}
+	now := time.Now()
+
+	// A missing exp is the zero time and so counts as expired
+	if !now.Before(claims.ExpiresAt.Add(leeway)) {
+		return ErrTokenExpired
+	}
+	// NBF is optional
+	if !claims.NotBefore.IsZero() && now.Add(leeway).Before(claims.NotBefore) {
+		return ErrTokenNotYetValid
+	}
+	return nil
+}
+
+// setCustom decodes every claim that is not registered into claims.Custom
+func (c *TokenClaims) setCustom(raw map[string]json.RawMessage) {
+	for _, name := range registeredClaims {
+		delete(raw, name)
+	}
+	c.Custom = raw
+}
+
+// PasetoV4Suite signs PASETO v4 public tokens with Ed25519. It is the
+// default suite. Only PrivateKey is needed to sign and PublicKey to verify.
+type PasetoV4Suite struct {
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+	// Leeway is the clock skew tolerated on exp and nbf; zero uses
+	// DefaultLeeway and a negative value none
+	Leeway time.Duration
+}
+
+// NewPasetoV4Suite creates a PASETO v4 suite; either key may be nil
+func NewPasetoV4Suite(privateKey ed25519.PrivateKey, publicKey ed25519.PublicKey) *PasetoV4Suite {
+	return &PasetoV4Suite{PrivateKey: privateKey, PublicKey: publicKey}
+}
+
+// Name returns SuitePasetoV4
+func (s *PasetoV4Suite) Name() string { return SuitePasetoV4 }
+
+// Sign encodes the claims as a v4.public token
+func (s *PasetoV4Suite) Sign(claims *TokenClaims) (string, error) {
+	if s.PrivateKey == nil {
+		return "", ErrSuiteKeyMissing
+	}
+	secretKey, err := paseto.NewV4AsymmetricSecretKeyFromBytes(s.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	token := paseto.NewToken()
+	for name, value := range claims.Custom {
+		if err := token.Set(name, value); err != nil {
+			return "", err
+		}
+	}
+	token.SetIssuer(claims.Issuer)
+	token.SetSubject(claims.Subject)
+	if claims.Audience != "" {
+		token.SetAudience(claims.Audience)
+	}
+	if claims.JTI != "" {
+		token.SetJti(claims.JTI)
+	}
+	token.SetIssuedAt(claims.IssuedAt)
+	token.SetExpiration(claims.ExpiresAt)
+	if !claims.NotBefore.IsZero() {
+		token.SetNotBefore(claims.NotBefore)
+	}
+
+	return token.V4Sign(secretKey, nil), nil
+}
+
+// Verify checks a v4.public token's signature, expiry and nbf and returns its
+// claims
+func (s *PasetoV4Suite) Verify(tokenString string) (*TokenClaims, error) {
+	if s.PublicKey == nil {
+		return nil, ErrSuiteKeyMissing
+	}
+	pasetoPublicKey, err := paseto.NewV4AsymmetricPublicKeyFromBytes(s.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Expiry is checked below, with leeway
+	token, err := paseto.NewParserWithoutExpiryCheck().ParseV4Public(pasetoPublicKey, tokenString, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenSignatureInvalid, err)
+	}
+
+	var registered struct {
+		Issuer    string    `json:"iss"`
+		Subject   string    `json:"sub"`
+		Audience  string    `json:"aud"`
+		JTI       string    `json:"jti"`
+		IssuedAt  time.Time `json:"iat"`
+		NotBefore time.Time `json:"nbf"`
+		ExpiresAt time.Time `json:"exp"`
+	}
+	claimsJSON := token.ClaimsJSON()
+	if err := json.Unmarshal(claimsJSON, &registered); err != nil {
+		return nil, ErrMalformedToken
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(claimsJSON, &raw); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	claims := &TokenClaims{
+		Issuer:    registered.Issuer,
+		Subject:   registered.Subject,
+		Audience:  registered.Audience,
+		JTI:       registered.JTI,
+		IssuedAt:  registered.IssuedAt,
+		NotBefore: registered.NotBefore,
+		ExpiresAt: registered.ExpiresAt,
+	}
+	if err := checkValidity(claims, s.Leeway); err != nil {
+		return nil, err
+	}
+	claims.setCustom(raw)
+	return claims, nil
+}
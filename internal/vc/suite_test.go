@@ -0,0 +1,176 @@
+package vc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPasetoV4SuiteRoundTrip(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	suite := NewPasetoV4Suite(priv, pub)
+	if suite.Name() != SuitePasetoV4 {
+		t.Errorf("Expected %s, got %s", SuitePasetoV4, suite.Name())
+	}
+
+	now := time.Now().Truncate(time.Second)
+	token, err := suite.Sign(&TokenClaims{
+		Issuer:    "did:key:zIssuer",
+		Subject:   "did:key:zSubject",
+		Audience:  "did:key:zVerifier",
+		JTI:       "urn:uuid:suite",
+		IssuedAt:  now,
+		ExpiresAt: now.Add(time.Hour),
+		Custom:    map[string]json.RawMessage{"nonce": json.RawMessage(`"abc"`)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	if !strings.HasPrefix(token, "v4.public.") {
+		t.Errorf("Expected a v4.public token, got %s", token)
+	}
+
+	claims, err := suite.Verify(token)
+	if err != nil {
+		t.Fatalf("Failed to verify: %v", err)
+	}
+	if claims.Issuer != "did:key:zIssuer" || claims.Audience != "did:key:zVerifier" || claims.JTI != "urn:uuid:suite" {
+		t.Errorf("Expected registered claims to round-trip, got %+v", claims)
+	}
+	if !claims.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("Expected exp %v, got %v", now.Add(time.Hour), claims.ExpiresAt)
+	}
+	if string(claims.Custom["nonce"]) != `"abc"` {
+		t.Errorf("Expected nonce claim \"abc\", got %s", claims.Custom["nonce"])
+	}
+	if _, ok := claims.Custom["iss"]; ok {
+		t.Error("Expected registered claims to be left out of Custom")
+	}
+}
+
+func TestSignatureSuiteErrors(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	claims := &TokenClaims{Issuer: "did:key:zIssuer", IssuedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+
+	if _, err := NewPasetoV4Suite(nil, pub).Sign(claims); err != ErrSuiteKeyMissing {
+		t.Errorf("Expected ErrSuiteKeyMissing, got %v", err)
+	}
+	token, _ := NewPasetoV4Suite(priv, nil).Sign(claims)
+	if _, err := NewPasetoV4Suite(priv, nil).Verify(token); err != ErrSuiteKeyMissing {
+		t.Errorf("Expected ErrSuiteKeyMissing, got %v", err)
+	}
+	if _, err := NewPasetoV4Suite(nil, otherPub).Verify(token); !errors.Is(err, ErrTokenSignatureInvalid) {
+		t.Errorf("Expected ErrTokenSignatureInvalid, got %v", err)
+	}
+
+	expired, _ := NewPasetoV4Suite(priv, nil).Sign(&TokenClaims{
+		Issuer:    "did:key:zIssuer",
+		IssuedAt:  time.Now().Add(-2 * time.Hour),
+		ExpiresAt: time.Now().Add(-time.Hour),
+	})
+	if _, err := NewPasetoV4Suite(nil, pub).Verify(expired); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Expected ErrTokenExpired, got %v", err)
+	}
+}
+
+func TestIssueVCWithSuite(t *testing.T) {
+	priv := generateP256Key(t)
+
+	// The suite signs, so no private key argument is needed
+	token, err := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", nil, testIdentitySubject("did:key:zSubject"), IssueOptions{
+		CredentialID: "urn:uuid:suite",
+		Suite:        NewES256Suite(priv, nil),
+	})
+	if err != nil {
+		t.Fatalf("Failed to issue: %v", err)
+	}
+	if !isJWS(token) {
+		t.Fatalf("Expected a compact JWS, got %s", token)
+	}
+
+	claims, err := VerifyVCWithSuite(token, NewES256Suite(nil, &priv.PublicKey))
+	if err != nil {
+		t.Fatalf("Failed to verify: %v", err)
+	}
+	if claims.GetCredentialID() != "urn:uuid:suite" {
+		t.Errorf("Expected credential ID urn:uuid:suite, got %s", claims.GetCredentialID())
+	}
+
+	// A suite of the wrong kind reports an invalid signature
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := VerifyVCWithSuite(token, NewPasetoV4Suite(nil, pub)); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid, got %v", err)
+	}
+}
+
+func TestSuiteLeeway(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	p256 := generateP256Key(t)
+
+	suites := []struct {
+		name   string
+		signer SignatureSuite
+		suite  func(leeway time.Duration) SignatureSuite
+	}{
+		{SuitePasetoV4, NewPasetoV4Suite(priv, nil), func(l time.Duration) SignatureSuite {
+			return &PasetoV4Suite{PublicKey: pub, Leeway: l}
+		}},
+		{SuiteES256JWS, NewES256Suite(p256, nil), func(l time.Duration) SignatureSuite {
+			return &ES256Suite{PublicKey: &p256.PublicKey, Leeway: l}
+		}},
+		{"eddsa-jws", NewEdDSASuite(priv, nil, ""), func(l time.Duration) SignatureSuite {
+			return &EdDSASuite{PublicKey: pub, Leeway: l}
+		}},
+	}
+
+	tests := []struct {
+		name      string
+		expiresIn time.Duration
+		notBefore time.Duration // from now; zero omits nbf
+		leeway    time.Duration
+		want      error
+	}{
+		{"expired within leeway", -30 * time.Second, 0, 60 * time.Second, nil},
+		{"expired beyond leeway", -90 * time.Second, 0, 60 * time.Second, ErrCredentialExpired},
+		{"expired within default leeway", -30 * time.Second, 0, 0, nil},
+		{"expired without leeway", -30 * time.Second, 0, -1, ErrCredentialExpired},
+		{"nbf within leeway", time.Hour, 30 * time.Second, 60 * time.Second, nil},
+		{"nbf beyond leeway", time.Hour, 90 * time.Second, 60 * time.Second, ErrNotYetValid},
+		{"nbf without leeway", time.Hour, 30 * time.Second, -1, ErrNotYetValid},
+	}
+
+	for _, s := range suites {
+		for _, tt := range tests {
+			t.Run(s.name+"/"+tt.name, func(t *testing.T) {
+				now := time.Now()
+				claims := &TokenClaims{
+					Issuer:    "did:key:zIssuer",
+					Subject:   "did:key:zSubject",
+					IssuedAt:  now.Add(-time.Hour),
+					ExpiresAt: now.Add(tt.expiresIn),
+					Custom:    map[string]json.RawMessage{"vc": json.RawMessage(`{"type":["VerifiableCredential"]}`)},
+				}
+				if tt.notBefore != 0 {
+					claims.NotBefore = now.Add(tt.notBefore)
+				}
+				token, err := s.signer.Sign(claims)
+				if err != nil {
+					t.Fatalf("Sign failed: %v", err)
+				}
+
+				_, err = VerifyVCWithSuite(token, s.suite(tt.leeway))
+				if tt.want == nil && err != nil {
+					t.Errorf("Expected success, got %v", err)
+				}
+				if tt.want != nil && !errors.Is(err, tt.want) {
+					t.Errorf("Expected %v, got %v", tt.want, err)
+				}
+			})
+		}
+	}
+}
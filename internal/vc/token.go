@@ -1,36 +1,193 @@
 package vc
 
 import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
 	"crypto/ed25519"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
-	"aidanwoods.dev/go-paseto"
+	"github.com/mr-tron/base58"
+	"github.com/veriglob/veriglob-core/internal/resolver"
+)
+
+var (
+	ErrVerificationMethodMismatch = errors.New("credential not signed by expected verification method")
+	ErrTypeNotPublished           = errors.New("credential type not published by issuer")
+	ErrNotACredential             = errors.New("token is a verifiable presentation, not a credential")
+	ErrMalformedToken             = errors.New("malformed credential token")
+	ErrValidityOutOfBounds        = errors.New("credential validity period out of bounds")
+	ErrConflictingExpiry          = errors.New("set either ExpiresAt or Lifetime, not both")
+	ErrNotYetValid                = errors.New("credential is not valid yet")
+	ErrNoSubjects                 = errors.New("at least one credential subject is required")
+	ErrMixedSubjectTypes          = errors.New("credential subjects must share one credential type")
+	ErrMissingCredentialID        = errors.New("credential has no ID and cannot be revocation-checked")
+	ErrIssuerKeyMismatch          = errors.New("credential issuer does not match the verification key")
+	ErrSignatureInvalid           = errors.New("credential signature is invalid")
+	ErrCredentialExpired          = errors.New("credential expired")
+	ErrInvalidHolderKey           = errors.New("holder key must be an Ed25519 public key")
+	ErrNotW3CConformant           = errors.New("credential body is not a conformant W3C credential")
+	ErrUnsupportedKey             = errors.New("private key must be ed25519.PrivateKey or a P-256 *ecdsa.PrivateKey")
+)
+
+// VerifyOptions configures optional checks performed by VerifyVCWithOptions
+type VerifyOptions struct {
+	// ExpectedVerificationMethod pins verification to a single verification
+	// method ID (e.g. did:key:z...#key-1). The token must be signed by that
+	// method's key and issued by the DID controlling it.
+	ExpectedVerificationMethod string
+
+	// MetadataResolver, when set, is used to fetch the issuer's published
+	// metadata; every credential type must be one the issuer lists.
+	MetadataResolver *resolver.Resolver
+
+	// Context bounds the MetadataResolver fetch; nil uses
+	// context.Background().
+	Context context.Context
+
+	// RequireCredentialID rejects credentials with neither a jti nor a vc.id,
+	// since they cannot be checked for revocation.
+	RequireCredentialID bool
+
+	// ExpectedIssuer requires the credential's iss to be this DID and the
+	// signing key to be the one the DID resolves to.
+	ExpectedIssuer string
+
+	// Resolver resolves ExpectedIssuer and ExpectedVerificationMethod; nil
+	// uses the default did:key resolver.
+	Resolver *resolver.Resolver
+
+	// TrustedIssuers, when set, rejects credentials whose issuer it does not
+	// trust for the credential's types with ErrUntrustedIssuer.
+	TrustedIssuers *TrustList
+
+	// Leeway is the clock skew tolerated on the credential's expiry and nbf;
+	// zero uses DefaultLeeway and a negative value tolerates none.
+	Leeway time.Duration
+}
+
+// DefaultValidity is how long a credential is valid when no expiration is given
+const DefaultValidity = 365 * 24 * time.Hour
+
+// CredentialsContextV1 is the base JSON-LD context of W3C VC Data Model 1.1
+const CredentialsContextV1 = "https://www.w3.org/2018/credentials/v1"
+
+// Credential status types
+const (
+	StatusTypeRevocationRegistry = "RevocationRegistry2024"
+	StatusTypeStatusList2021     = "StatusList2021Entry"
 )
 
 // CredentialStatus contains revocation check information
 type CredentialStatus struct {
 	ID   string `json:"id"`
 	Type string `json:"type"`
+
+	// StatusList2021Entry fields
+	StatusPurpose        string `json:"statusPurpose,omitempty"`
+	StatusListIndex      string `json:"statusListIndex,omitempty"`
+	StatusListCredential string `json:"statusListCredential,omitempty"`
+}
+
+// NewStatusList2021Entry builds a credentialStatus pointing at bit index of the status list at listURL
+func NewStatusList2021Entry(listURL string, index int) *CredentialStatus {
+	return &CredentialStatus{
+		ID:                   fmt.Sprintf("%s#%d", listURL, index),
+		Type:                 StatusTypeStatusList2021,
+		StatusPurpose:        "revocation",
+		StatusListIndex:      strconv.Itoa(index),
+		StatusListCredential: listURL,
+	}
 }
 
 // VCClaims represents a PASETO Verifiable Credential
 type VCClaims struct {
-	Issuer    string               `json:"iss"`
-	Subject   string               `json:"sub"`
-	JTI       string               `json:"jti"`
-	IssuedAt  time.Time            `json:"iat"`
-	ExpiresAt time.Time            `json:"exp"`
-	VC        VerifiableCredential `json:"vc"`
+	Issuer       string               `json:"iss"`
+	Subject      string               `json:"sub"`
+	JTI          string               `json:"jti"`
+	IssuedAt     time.Time            `json:"iat"`
+	NotBefore    time.Time            `json:"nbf,omitempty"`
+	ExpiresAt    time.Time            `json:"exp"`
+	Confirmation *Confirmation        `json:"cnf,omitempty"`
+	VC           VerifiableCredential `json:"vc"`
+	// DelegationChain holds the delegation credentials that give the issuer
+	// its authority, from the root authority down; see VerifyDelegationChain
+	DelegationChain []string `json:"delegationChain,omitempty"`
+}
+
+// Confirmation is the cnf claim binding a credential to the holder key that
+// must sign any presentation of it
+type Confirmation struct {
+	PublicKeyBase58 string `json:"publicKeyBase58"`
+}
+
+// BoundKey returns the holder key the credential is bound to via cnf
+func (c *VCClaims) BoundKey() (ed25519.PublicKey, bool) {
+	if c.Confirmation == nil {
+		return nil, false
+	}
+	key, err := base58.Decode(c.Confirmation.PublicKeyBase58)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, false
+	}
+	return ed25519.PublicKey(key), true
 }
 
 // VerifiableCredential payload
 type VerifiableCredential struct {
+	Context           []string          `json:"@context,omitempty"`
 	ID                string            `json:"id,omitempty"`
 	Type              []string          `json:"type"`
+	Issuer            string            `json:"issuer,omitempty"`
+	IssuanceDate      string            `json:"issuanceDate,omitempty"`
+	ExpirationDate    string            `json:"expirationDate,omitempty"`
 	CredentialSubject interface{}       `json:"credentialSubject"`
 	CredentialStatus  *CredentialStatus `json:"credentialStatus,omitempty"`
+	CredentialSchema  *CredentialSchema `json:"credentialSchema,omitempty"`
+	RefreshService    *RefreshService   `json:"refreshService,omitempty"`
+	// IssuerDisplay, when set, is written into issuer, which becomes an
+	// object with Issuer as its id
+	IssuerDisplay *IssuerDisplay `json:"-"`
+}
+
+// MarshalJSON writes issuer as an object when there is issuer metadata
+func (v VerifiableCredential) MarshalJSON() ([]byte, error) {
+	type plain VerifiableCredential
+	return json.Marshal(struct {
+		plain
+		Issuer interface{} `json:"issuer,omitempty"`
+	}{plain(v), encodeIssuer(v.Issuer, v.IssuerDisplay)})
+}
+
+// UnmarshalJSON decodes a vc claim in either encoding; see EncodeClaim. The
+// issuer may be a DID or an object carrying IssuerDisplay.
+func (v *VerifiableCredential) UnmarshalJSON(data []byte) error {
+	data, err := DecodeClaim(data)
+	if err != nil {
+		return err
+	}
+	type plain VerifiableCredential
+	wire := struct {
+		*plain
+		Issuer json.RawMessage `json:"issuer,omitempty"`
+	}{plain: (*plain)(v)}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	v.Issuer, v.IssuerDisplay, err = decodeIssuer(wire.Issuer)
+	return err
+}
+
+// RefreshService tells the holder where to obtain a fresh copy of a credential
+type RefreshService struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
 }
 
 // IssueVC creates and signs a PASETO v4 public Verifiable Credential
@@ -51,112 +208,583 @@ func IssueVCWithID(
 	subject CredentialSubject,
 	credentialID string,
 ) (string, error) {
-	edKey, ok := privateKey.(ed25519.PrivateKey)
-	if !ok {
-		return "", errors.New("private key must be ed25519.PrivateKey")
+	return IssueVCWithOptions(issuerDID, subjectDID, privateKey, subject, IssueOptions{CredentialID: credentialID})
+}
+
+// IssueVCWithStatus creates and signs a PASETO v4 public Verifiable Credential
+// with a specific credential ID and credentialStatus (e.g. a StatusList2021Entry)
+func IssueVCWithStatus(
+	issuerDID string,
+	subjectDID string,
+	privateKey interface{},
+	subject CredentialSubject,
+	credentialID string,
+	status *CredentialStatus,
+) (string, error) {
+	return signVC(issuerDID, subjectDID, privateKey, []CredentialSubject{subject}, credentialID, status, IssueOptions{})
+}
+
+// IssueVCWithOptions creates and signs a PASETO v4 public Verifiable Credential.
+// A nil opts.Status with a credential ID gets a registry credentialStatus, as
+// with IssueVCWithID. A subject implementing Validator is validated before
+// signing, so e.g. an identity without a family name returns
+// ErrMissingRequiredField. An expiration outside the validity bounds returns
+// ErrValidityOutOfBounds.
+func IssueVCWithOptions(
+	issuerDID string,
+	subjectDID string,
+	privateKey interface{},
+	subject CredentialSubject,
+	opts IssueOptions,
+) (string, error) {
+	return IssueVCWithSubjects(issuerDID, subjectDID, privateKey, []CredentialSubject{subject}, opts)
+}
+
+// IssueVCWithSubjects issues a credential naming several subjects, e.g. both
+// parties on a marriage certificate. With more than one subject,
+// credentialSubject is serialized as an array; with one it stays an object.
+// All subjects must have the same credential type.
+func IssueVCWithSubjects(
+	issuerDID string,
+	subjectDID string,
+	privateKey interface{},
+	subjects []CredentialSubject,
+	opts IssueOptions,
+) (string, error) {
+	status := opts.Status
+	if status == nil && opts.CredentialID != "" {
+		status = &CredentialStatus{
+			ID:   opts.CredentialID,
+			Type: StatusTypeRevocationRegistry,
+		}
 	}
+	return signVC(issuerDID, subjectDID, privateKey, subjects, opts.CredentialID, status, opts)
+}
 
-	secretKey, err := paseto.NewV4AsymmetricSecretKeyFromBytes(edKey)
-	if err != nil {
-		return "", err
+// signVC signs a credential with exactly the given status. Only the validity,
+// HolderKey, StrictW3C, CredentialSchema, RefreshService, IssuerDisplay,
+// DelegationChain, Suite and Encoding fields of opts are used.
+func signVC(
+	issuerDID string,
+	subjectDID string,
+	privateKey interface{},
+	subjects []CredentialSubject,
+	credentialID string,
+	status *CredentialStatus,
+	opts IssueOptions,
+) (string, error) {
+	if len(subjects) == 0 {
+		return "", ErrNoSubjects
+	}
+	credentialType := subjects[0].CredentialType()
+	for _, subject := range subjects[1:] {
+		if subject.CredentialType() != credentialType {
+			return "", ErrMixedSubjectTypes
+		}
+	}
+	for _, subject := range subjects {
+		if v, ok := subject.(Validator); ok {
+			if err := v.Validate(); err != nil {
+				return "", err
+			}
+		}
 	}
 
 	now := time.Now()
 
+	if !opts.ExpiresAt.IsZero() && opts.Lifetime > 0 {
+		return "", ErrConflictingExpiry
+	}
+
+	validFrom := now
+	if !opts.NotBefore.IsZero() {
+		validFrom = opts.NotBefore
+	}
+
+	expiresAt := opts.ExpiresAt
+	if expiresAt.IsZero() {
+		lifetime := opts.Lifetime
+		if lifetime <= 0 {
+			lifetime = DefaultValidity
+		}
+		expiresAt = validFrom.Add(lifetime)
+	}
+	if !expiresAt.After(validFrom) {
+		return "", fmt.Errorf("%w: expires before it becomes valid", ErrValidityOutOfBounds)
+	}
+
+	validity := expiresAt.Sub(now)
+	if validity <= 0 ||
+		(opts.MinValidity > 0 && validity < opts.MinValidity) ||
+		(opts.MaxValidity > 0 && validity > opts.MaxValidity) {
+		return "", fmt.Errorf("%w: valid for %s", ErrValidityOutOfBounds, validity.Round(time.Second))
+	}
+
+	suite := opts.Suite
+	if suite == nil {
+		var err error
+		if suite, err = suiteForKey(privateKey); err != nil {
+			return "", err
+		}
+	}
+
 	vc := VerifiableCredential{
 		Type: []string{
 			"VerifiableCredential",
-			subject.CredentialType(),
+			credentialType,
 		},
-		CredentialSubject: subject,
+		CredentialSubject: subjects[0],
+	}
+	if len(subjects) > 1 {
+		vc.CredentialSubject = subjects
 	}
 
 	// Add credential ID and status if provided
-	if credentialID != "" {
-		vc.ID = credentialID
-		vc.CredentialStatus = &CredentialStatus{
-			ID:   credentialID,
-			Type: "RevocationRegistry2024",
+	vc.ID = credentialID
+	vc.CredentialStatus = status
+	vc.CredentialSchema = opts.CredentialSchema
+	vc.RefreshService = opts.RefreshService
+
+	if opts.IssuerDisplay != nil {
+		if err := opts.IssuerDisplay.Validate(); err != nil {
+			return "", err
 		}
+		vc.Issuer = issuerDID
+		vc.IssuerDisplay = opts.IssuerDisplay
 	}
 
-	vcClaims := VCClaims{
-		Issuer:    issuerDID,
-		Subject:   subjectDID,
-		JTI:       credentialID,
-		IssuedAt:  now,
-		ExpiresAt: now.Add(365 * 24 * time.Hour),
-		VC:        vc,
+	if opts.StrictW3C {
+		// Make the vc body a self-describing credential on its own
+		vc.Context = []string{CredentialsContextV1}
+		vc.Issuer = issuerDID
+		vc.IssuanceDate = validFrom.UTC().Format(time.RFC3339)
+		vc.ExpirationDate = expiresAt.UTC().Format(time.RFC3339)
+		if vc.ID == "" {
+			var err error
+			if vc.ID, err = generateCredentialID(); err != nil {
+				return "", err
+			}
+		}
 	}
 
-	token := paseto.NewToken()
-	token.SetIssuer(vcClaims.Issuer)
-	token.SetSubject(vcClaims.Subject)
-	token.SetIssuedAt(vcClaims.IssuedAt)
-	token.SetExpiration(vcClaims.ExpiresAt)
-
-	if credentialID != "" {
-		token.SetString("jti", credentialID)
+	vcClaims := VCClaims{
+		Issuer:          issuerDID,
+		Subject:         subjectDID,
+		JTI:             credentialID,
+		IssuedAt:        now,
+		NotBefore:       opts.NotBefore,
+		ExpiresAt:       expiresAt,
+		VC:              vc,
+		DelegationChain: opts.DelegationChain,
+	}
+	if opts.HolderKey != nil {
+		if len(opts.HolderKey) != ed25519.PublicKeySize {
+			return "", ErrInvalidHolderKey
+		}
+		vcClaims.Confirmation = &Confirmation{PublicKeyBase58: base58.Encode(opts.HolderKey)}
 	}
 
-	vcJSON, err := json.Marshal(vcClaims.VC)
+	claims, err := vcClaims.tokenClaims()
 	if err != nil {
 		return "", err
 	}
-	if err := token.Set("vc", json.RawMessage(vcJSON)); err != nil {
+	if claims.Custom["vc"], err = EncodeClaim(claims.Custom["vc"], opts.Encoding); err != nil {
 		return "", err
 	}
+	return suite.Sign(claims)
+}
 
-	return token.V4Sign(secretKey, nil), nil
+// suiteForKey picks the signature suite for an issuer key: PASETO v4 for
+// Ed25519 and ES256 JWS for P-256
+func suiteForKey(privateKey interface{}) (SignatureSuite, error) {
+	switch key := privateKey.(type) {
+	case ed25519.PrivateKey:
+		return NewPasetoV4Suite(key, nil), nil
+	case *ecdsa.PrivateKey:
+		return NewES256Suite(key, nil), nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
 }
 
-// VerifyVC verifies a PASETO v4 public token and returns the claims
-func VerifyVC(tokenString string, publicKey ed25519.PublicKey) (*VCClaims, error) {
-	pasetoPublicKey, err := paseto.NewV4AsymmetricPublicKeyFromBytes(publicKey)
+// tokenClaims converts credential claims to suite-neutral token claims
+func (c *VCClaims) tokenClaims() (*TokenClaims, error) {
+	vcJSON, err := json.Marshal(c.VC)
 	if err != nil {
 		return nil, err
 	}
+	claims := &TokenClaims{
+		Issuer:    c.Issuer,
+		Subject:   c.Subject,
+		JTI:       c.JTI,
+		IssuedAt:  c.IssuedAt,
+		NotBefore: c.NotBefore,
+		ExpiresAt: c.ExpiresAt,
+		Custom:    map[string]json.RawMessage{"vc": vcJSON},
+	}
+	if c.Confirmation != nil {
+		cnfJSON, err := json.Marshal(c.Confirmation)
+		if err != nil {
+			return nil, err
+		}
+		claims.Custom["cnf"] = cnfJSON
+	}
+	if len(c.DelegationChain) > 0 {
+		chainJSON, err := json.Marshal(c.DelegationChain)
+		if err != nil {
+			return nil, err
+		}
+		claims.Custom["delegationChain"] = chainJSON
+	}
+	return claims, nil
+}
 
-	parser := paseto.NewParser()
-	token, err := parser.ParseV4Public(pasetoPublicKey, tokenString, nil)
-	if err != nil {
-		return nil, err
+// vcClaimsFromToken converts token claims back to credential claims,
+// decoding the cnf and vc claims
+func vcClaimsFromToken(tc *TokenClaims) (*VCClaims, error) {
+	claims := &VCClaims{
+		Issuer:    tc.Issuer,
+		Subject:   tc.Subject,
+		JTI:       tc.JTI,
+		IssuedAt:  tc.IssuedAt,
+		NotBefore: tc.NotBefore,
+		ExpiresAt: tc.ExpiresAt,
 	}
+	if cnf, ok := tc.Custom["cnf"]; ok {
+		claims.Confirmation = &Confirmation{}
+		if err := json.Unmarshal(cnf, claims.Confirmation); err != nil {
+			return nil, ErrMalformedToken
+		}
+	}
+	if chain, ok := tc.Custom["delegationChain"]; ok {
+		if err := json.Unmarshal(chain, &claims.DelegationChain); err != nil {
+			return nil, ErrMalformedToken
+		}
+	}
+	if err := json.Unmarshal(tc.Custom["vc"], &claims.VC); err != nil {
+		return nil, ErrMalformedToken
+	}
+	return claims, nil
+}
 
-	claims := &VCClaims{}
+// VerifyVC verifies a PASETO v4 public token and returns the claims
+func VerifyVC(tokenString string, publicKey ed25519.PublicKey) (*VCClaims, error) {
+	return VerifyVCWithSuite(tokenString, NewPasetoV4Suite(nil, publicKey))
+}
 
-	claims.Issuer, err = token.GetIssuer()
-	if err != nil {
-		return nil, err
+// VerifyVCAny verifies a credential against each key in turn, e.g. the
+// current and retired keys of an issuer from resolver.ResolveAllKeys, and
+// returns the claims for the first key whose signature matches. Once a
+// signature matches, any other failure such as expiry is returned rather
+// than trying further keys. If no key matches, ErrSignatureInvalid is
+// returned.
+func VerifyVCAny(tokenString string, keys []ed25519.PublicKey) (*VCClaims, error) {
+	for _, key := range keys {
+		claims, err := VerifyVC(tokenString, key)
+		if err == nil || !errors.Is(err, ErrSignatureInvalid) {
+			return claims, err
+		}
 	}
+	return nil, ErrSignatureInvalid
+}
 
-	claims.Subject, err = token.GetSubject()
+// VerifyVCWithSuite verifies a credential with the given signature suite
+// and returns its claims. An invalid signature returns ErrSignatureInvalid,
+// an expired credential ErrCredentialExpired and one whose nbf is in the
+// future ErrNotYetValid, whichever suite is used. The suite's Leeway sets the
+// clock skew tolerated.
+func VerifyVCWithSuite(tokenString string, suite SignatureSuite) (*VCClaims, error) {
+	tc, err := suite.Verify(tokenString)
 	if err != nil {
-		return nil, err
+		return nil, credentialError(err)
 	}
 
-	claims.IssuedAt, err = token.GetIssuedAt()
-	if err != nil {
+	// A VP token verifies against the same key type; catch it before the
+	// missing vc claim produces a confusing error
+	if tc.Custom["vc"] == nil {
+		if tc.Custom["vp"] != nil {
+			return nil, ErrNotACredential
+		}
+		return nil, ErrMalformedToken
+	}
+	if tc.Issuer == "" || tc.IssuedAt.IsZero() {
+		return nil, ErrMalformedToken
+	}
+
+	return vcClaimsFromToken(tc)
+}
+
+// credentialError maps a suite verification failure to ErrCredentialExpired,
+// ErrNotYetValid or ErrSignatureInvalid. Expiry and signature failures keep
+// the original error in the chain.
+func credentialError(err error) error {
+	switch {
+	case errors.Is(err, ErrTokenExpired):
+		return fmt.Errorf("%w: %w", ErrCredentialExpired, err)
+	case errors.Is(err, ErrTokenNotYetValid):
+		return ErrNotYetValid
+	case errors.Is(err, ErrTokenSignatureInvalid):
+		return fmt.Errorf("%w: %w", ErrSignatureInvalid, err)
+	default:
+		return err
+	}
+}
+
+// UnverifiedIssuer reads the issuer DID from a token without checking its
+// signature, so the issuer key can be resolved before calling VerifyVC.
+// The result must not be trusted until the token is verified.
+func UnverifiedIssuer(tokenString string) (string, error) {
+	var claims struct {
+		Issuer string `json:"iss"`
+	}
+	if err := decodeUnverified(tokenString, &claims); err != nil || claims.Issuer == "" {
+		return "", ErrMalformedToken
+	}
+	return claims.Issuer, nil
+}
+
+// UnverifiedClaims decodes a credential's claims without checking its
+// signature, e.g. to show a stored credential's dates. The claims must not be
+// trusted; use VerifyVC for that.
+func UnverifiedClaims(tokenString string) (*VCClaims, error) {
+	if err := CheckWellFormed(tokenString); err != nil {
 		return nil, err
 	}
+	if isJWS(tokenString) {
+		payload, err := jwsPayload(tokenString)
+		if err != nil {
+			return nil, err
+		}
+		tc, err := decodeJWTClaims(payload)
+		if err != nil {
+			return nil, err
+		}
+		return vcClaimsFromToken(tc)
+	}
+	var claims VCClaims
+	if err := decodeUnverified(tokenString, &claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+	return &claims, nil
+}
+
+// CheckWellFormed reports whether a token is structurally a credential: a
+// v4.public PASETO or ES256 JWS whose payload has an issuer and a vc object,
+// in either encoding. It does not check the signature; use VerifyVC for that.
+func CheckWellFormed(tokenString string) error {
+	var claims struct {
+		Issuer string          `json:"iss"`
+		VC     json.RawMessage `json:"vc"`
+	}
+	if err := decodeUnverified(tokenString, &claims); err != nil {
+		return ErrMalformedToken
+	}
+	body, err := DecodeClaim(claims.VC)
+	if err != nil || claims.Issuer == "" || len(body) == 0 || body[0] != '{' {
+		return ErrMalformedToken
+	}
+	return nil
+}
+
+// decodeUnverified decodes the JSON payload of a v4.public token or a
+// compact JWS into v without checking the signature. Selective disclosures
+// appended to the token are ignored.
+func decodeUnverified(tokenString string, v interface{}) error {
+	tokenString, _, _ = strings.Cut(tokenString, SDSeparator)
+	if isJWS(tokenString) {
+		payload, err := jwsPayload(tokenString)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(payload, v)
+	}
+
+	const header = "v4.public."
+	if !strings.HasPrefix(tokenString, header) {
+		return ErrMalformedToken
+	}
+
+	payload := strings.TrimPrefix(tokenString, header)
+	if i := strings.IndexByte(payload, '.'); i >= 0 {
+		payload = payload[:i] // drop the footer
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil || len(raw) <= ed25519.SignatureSize {
+		return ErrMalformedToken
+	}
+
+	return json.Unmarshal(raw[:len(raw)-ed25519.SignatureSize], v)
+}
+
+// VerifyVCExpectingIssuer verifies a credential that must be issued by
+// expectedIssuerDID. The DID is resolved and its key must equal publicKey,
+// if one is given; a mismatch, or a credential claiming another issuer,
+// returns ErrIssuerKeyMismatch.
+func VerifyVCExpectingIssuer(tokenString string, publicKey ed25519.PublicKey, expectedIssuerDID string) (*VCClaims, error) {
+	return VerifyVCWithOptions(tokenString, publicKey, VerifyOptions{ExpectedIssuer: expectedIssuerDID})
+}
 
-	claims.ExpiresAt, err = token.GetExpiration()
+// VerifyVCWithOptions verifies a PASETO v4 public token, applying the given options.
+// When ExpectedIssuer or ExpectedVerificationMethod is set, publicKey may be
+// nil; if provided it must match the resolved key.
+func VerifyVCWithOptions(tokenString string, publicKey ed25519.PublicKey, opts VerifyOptions) (*VCClaims, error) {
+	res := opts.Resolver
+	if res == nil {
+		res = resolver.NewResolver()
+	}
+
+	if opts.ExpectedIssuer != "" {
+		issuerKey, err := res.Resolve(opts.ExpectedIssuer)
+		if err != nil {
+			return nil, err
+		}
+		if publicKey != nil && !bytes.Equal(publicKey, issuerKey) {
+			return nil, ErrIssuerKeyMismatch
+		}
+		publicKey = issuerKey
+	}
+
+	if opts.ExpectedVerificationMethod != "" {
+		pinnedKey, err := res.ResolveVerificationMethod(opts.ExpectedVerificationMethod)
+		if err != nil {
+			return nil, err
+		}
+		if publicKey != nil && !bytes.Equal(publicKey, pinnedKey) {
+			return nil, ErrVerificationMethodMismatch
+		}
+		publicKey = pinnedKey
+	}
+
+	claims, err := VerifyVCWithSuite(tokenString, &PasetoV4Suite{PublicKey: publicKey, Leeway: opts.Leeway})
 	if err != nil {
 		return nil, err
 	}
 
-	// JTI is optional
-	claims.JTI, _ = token.GetString("jti")
+	if opts.ExpectedVerificationMethod != "" {
+		controller, _, _ := strings.Cut(opts.ExpectedVerificationMethod, "#")
+		if claims.Issuer != controller {
+			return nil, ErrVerificationMethodMismatch
+		}
+	}
 
-	var vc VerifiableCredential
-	if err := token.Get("vc", &vc); err != nil {
-		return nil, err
+	if opts.ExpectedIssuer != "" && claims.Issuer != opts.ExpectedIssuer {
+		return nil, ErrIssuerKeyMismatch
+	}
+
+	if opts.RequireCredentialID && claims.GetCredentialID() == "" {
+		return nil, ErrMissingCredentialID
+	}
+
+	if opts.TrustedIssuers != nil {
+		// The list trusts DIDs, so the key must be one the issuer DID holds;
+		// otherwise any key could sign a credential naming a trusted iss
+		if err := checkIssuerKey(res, claims.Issuer, publicKey); err != nil {
+			return nil, err
+		}
+		if err := opts.TrustedIssuers.Check(claims); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.MetadataResolver != nil {
+		ctx := opts.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		md, err := opts.MetadataResolver.ResolveIssuerMetadata(ctx, claims.Issuer)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range claims.VC.Type {
+			if t == "VerifiableCredential" {
+				continue
+			}
+			if !md.IssuesType(t) {
+				return nil, ErrTypeNotPublished
+			}
+		}
 	}
-	claims.VC = vc
 
 	return claims, nil
 }
 
+// checkIssuerKey returns ErrIssuerKeyMismatch unless key is one the issuer
+// DID has signed credentials with, current or rotated away from
+func checkIssuerKey(res *resolver.Resolver, issuerDID string, key ed25519.PublicKey) error {
+	keys, err := res.ResolveAllKeys(issuerDID)
+	if err != nil {
+		return fmt.Errorf("resolving issuer %s: %w", issuerDID, err)
+	}
+	for _, k := range keys {
+		if k.Equal(key) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrIssuerKeyMismatch, issuerDID)
+}
+
+// ValidateW3C checks the credential body against the core shape of the W3C
+// VC Data Model 1.1: the base context first, a VerifiableCredential type, an
+// issuer, RFC 3339 dates, and a subject. Bodies issued with StrictW3C pass.
+func (v *VerifiableCredential) ValidateW3C() error {
+	if len(v.Context) == 0 || v.Context[0] != CredentialsContextV1 {
+		return fmt.Errorf("%w: @context must start with %s", ErrNotW3CConformant, CredentialsContextV1)
+	}
+
+	hasBaseType := false
+	for _, t := range v.Type {
+		if t == "VerifiableCredential" {
+			hasBaseType = true
+		}
+	}
+	if !hasBaseType {
+		return fmt.Errorf("%w: type must include VerifiableCredential", ErrNotW3CConformant)
+	}
+
+	if v.Issuer == "" {
+		return fmt.Errorf("%w: missing issuer", ErrNotW3CConformant)
+	}
+	if _, err := time.Parse(time.RFC3339, v.IssuanceDate); err != nil {
+		return fmt.Errorf("%w: issuanceDate: %v", ErrNotW3CConformant, err)
+	}
+	if v.ExpirationDate != "" {
+		if _, err := time.Parse(time.RFC3339, v.ExpirationDate); err != nil {
+			return fmt.Errorf("%w: expirationDate: %v", ErrNotW3CConformant, err)
+		}
+	}
+	if v.ID != "" && !strings.Contains(v.ID, ":") {
+		return fmt.Errorf("%w: id must be a URI", ErrNotW3CConformant)
+	}
+	if v.CredentialSubject == nil {
+		return fmt.Errorf("%w: missing credentialSubject", ErrNotW3CConformant)
+	}
+	return nil
+}
+
+// StatusListEntry returns the status list URL and bit index when the
+// credential uses a StatusList2021Entry status
+func (c *VCClaims) StatusListEntry() (string, int, bool) {
+	status := c.VC.CredentialStatus
+	if status == nil || status.Type != StatusTypeStatusList2021 {
+		return "", 0, false
+	}
+	index, err := strconv.Atoi(status.StatusListIndex)
+	if err != nil || index < 0 {
+		return "", 0, false
+	}
+	return status.StatusListCredential, index, true
+}
+
+// RefreshDue reports whether the credential names a refresh service and
+// expires within window of now, so a wallet can prompt the holder to renew it
+func (c *VCClaims) RefreshDue(now time.Time, window time.Duration) bool {
+	if c.VC.RefreshService == nil || c.ExpiresAt.IsZero() {
+		return false
+	}
+	return !now.Add(window).Before(c.ExpiresAt)
+}
+
 // GetCredentialID returns the credential ID from claims (for revocation checks)
 func (c *VCClaims) GetCredentialID() string {
 	if c.JTI != "" {
@@ -1,12 +1,24 @@
 package vc
 
 import (
+	"context"
 	"crypto/ed25519"
 	"crypto/rand"
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/veriglob/veriglob-core/internal/did"
+	"github.com/veriglob/veriglob-core/internal/resolver"
 )
 
+// testIdentitySubject returns an identity subject with every required field set
+func testIdentitySubject(id string) IdentitySubject {
+	return IdentitySubject{ID: id, GivenName: "Alice", FamilyName: "Doe", DateOfBirth: "1990-01-01"}
+}
+
 func TestIssueAndVerifyVC(t *testing.T) {
 	// Generate Issuer Keys
 	issuerPub, issuerPriv, err := ed25519.GenerateKey(rand.Reader)
@@ -74,3 +86,611 @@ func TestIssueVC_InvalidKey(t *testing.T) {
 		t.Error("Expected error for invalid private key, got nil")
 	}
 }
+
+func TestVerifyVCFailureReasons(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	subject := testIdentitySubject("did:key:zSubject")
+
+	token, _ := IssueVC("did:key:zIssuer", "did:key:zSubject", issuerPriv, subject)
+	if _, err := VerifyVC(token, otherPub); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid for the wrong key, got %v", err)
+	}
+	if _, err := VerifyVC("v4.public.garbage", issuerPub); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid for a garbage token, got %v", err)
+	}
+
+	// Signed with a past expiration
+	secretKey, _ := paseto.NewV4AsymmetricSecretKeyFromBytes(issuerPriv)
+	expired := paseto.NewToken()
+	expired.SetIssuer("did:key:zIssuer")
+	expired.SetSubject("did:key:zSubject")
+	expired.SetIssuedAt(time.Now().Add(-2 * time.Hour))
+	expired.SetExpiration(time.Now().Add(-time.Hour))
+	expired.Set("vc", VerifiableCredential{Type: []string{"VerifiableCredential"}, CredentialSubject: subject})
+
+	_, err := VerifyVC(expired.V4Sign(secretKey, nil), issuerPub)
+	if !errors.Is(err, ErrCredentialExpired) {
+		t.Errorf("Expected ErrCredentialExpired, got %v", err)
+	}
+	if errors.Is(err, ErrSignatureInvalid) {
+		t.Error("An expired credential must not report an invalid signature")
+	}
+}
+
+func TestVerifyVCWithOptions_PinnedVerificationMethod(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+
+	token, err := IssueVC(issuerDID.DID, "did:key:zSubject", issuerPriv, testIdentitySubject("did:key:zSubject"))
+	if err != nil {
+		t.Fatalf("IssueVC failed: %v", err)
+	}
+
+	// Pinning the issuer's own method succeeds without passing a key
+	opts := VerifyOptions{ExpectedVerificationMethod: issuerDID.DID + "#key-1"}
+	claims, err := VerifyVCWithOptions(token, nil, opts)
+	if err != nil {
+		t.Fatalf("VerifyVCWithOptions failed: %v", err)
+	}
+	if claims.Issuer != issuerDID.DID {
+		t.Errorf("Issuer mismatch. Got %s, want %s", claims.Issuer, issuerDID.DID)
+	}
+
+	// Passing a matching key alongside the pinned method is allowed
+	if _, err := VerifyVCWithOptions(token, issuerPub, opts); err != nil {
+		t.Errorf("VerifyVCWithOptions with matching key failed: %v", err)
+	}
+}
+
+func TestVerifyVCWithOptions_WrongVerificationMethod(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	otherDID, _ := did.CreateDIDKey(otherPub)
+
+	token, _ := IssueVC(issuerDID.DID, "did:key:zSubject", issuerPriv, testIdentitySubject("did:key:zSubject"))
+
+	// Method that does not exist in the issuer's DID document
+	_, err := VerifyVCWithOptions(token, nil, VerifyOptions{ExpectedVerificationMethod: issuerDID.DID + "#key-2"})
+	if err != resolver.ErrVerificationMethodNotFound {
+		t.Errorf("Expected ErrVerificationMethodNotFound, got %v", err)
+	}
+
+	// Method belonging to a different key
+	_, err = VerifyVCWithOptions(token, nil, VerifyOptions{ExpectedVerificationMethod: otherDID.DID + "#key-1"})
+	if err == nil {
+		t.Error("Expected error when pinning another DID's verification method")
+	}
+
+	// Supplied key disagrees with the pinned method
+	_, err = VerifyVCWithOptions(token, otherPub, VerifyOptions{ExpectedVerificationMethod: issuerDID.DID + "#key-1"})
+	if err != ErrVerificationMethodMismatch {
+		t.Errorf("Expected ErrVerificationMethodMismatch, got %v", err)
+	}
+}
+
+func TestVerifyVCExpectingIssuer(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	issuerDID, _ := did.CreateDIDKey(issuerPub)
+	otherPub, otherPriv, _ := ed25519.GenerateKey(rand.Reader)
+	otherDID, _ := did.CreateDIDKey(otherPub)
+	subject := testIdentitySubject("did:key:zSubject")
+
+	token, _ := IssueVC(issuerDID.DID, "did:key:zSubject", issuerPriv, subject)
+
+	// Matching key, or no key at all, verifies
+	if _, err := VerifyVCExpectingIssuer(token, issuerPub, issuerDID.DID); err != nil {
+		t.Errorf("Expected verification with matching key, got %v", err)
+	}
+	if _, err := VerifyVCExpectingIssuer(token, nil, issuerDID.DID); err != nil {
+		t.Errorf("Expected verification with resolved key, got %v", err)
+	}
+
+	// Key from a different DID than the expected issuer
+	if _, err := VerifyVCExpectingIssuer(token, otherPub, issuerDID.DID); err != ErrIssuerKeyMismatch {
+		t.Errorf("Expected ErrIssuerKeyMismatch for wrong key, got %v", err)
+	}
+
+	// Credential claims issuer A but is signed by B
+	forged, _ := IssueVC(issuerDID.DID, "did:key:zSubject", otherPriv, subject)
+	if _, err := VerifyVCExpectingIssuer(forged, nil, issuerDID.DID); err == nil {
+		t.Error("Expected a credential signed by another key to fail")
+	}
+	if _, err := VerifyVCExpectingIssuer(forged, otherPub, otherDID.DID); err != ErrIssuerKeyMismatch {
+		t.Errorf("Expected ErrIssuerKeyMismatch for a mismatched iss claim, got %v", err)
+	}
+}
+
+func TestVerifyVCWithOptions_RequireCredentialID(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	subject := testIdentitySubject("did:key:zSubject")
+
+	noID, _ := IssueVC("did:key:zIssuer", "did:key:zSubject", issuerPriv, subject)
+	withID, _ := IssueVCWithID("did:key:zIssuer", "did:key:zSubject", issuerPriv, subject, "urn:uuid:cred-1")
+
+	// Off by default: ID-less credentials are accepted
+	if _, err := VerifyVCWithOptions(noID, issuerPub, VerifyOptions{}); err != nil {
+		t.Errorf("Expected ID-less credential to verify without the option, got %v", err)
+	}
+
+	opts := VerifyOptions{RequireCredentialID: true}
+	if _, err := VerifyVCWithOptions(noID, issuerPub, opts); err != ErrMissingCredentialID {
+		t.Errorf("Expected ErrMissingCredentialID, got %v", err)
+	}
+	claims, err := VerifyVCWithOptions(withID, issuerPub, opts)
+	if err != nil {
+		t.Fatalf("Expected credential with ID to verify, got %v", err)
+	}
+	if claims.GetCredentialID() != "urn:uuid:cred-1" {
+		t.Errorf("Expected credential ID urn:uuid:cred-1, got %s", claims.GetCredentialID())
+	}
+}
+
+func TestVerifyVCWithOptions_Leeway(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	// IssueVC refuses to sign an already expired credential, so sign the claims directly
+	expiredAgo := func(d time.Duration) string {
+		token, err := NewPasetoV4Suite(issuerPriv, nil).Sign(&TokenClaims{
+			Issuer:    "did:key:zIssuer",
+			Subject:   "did:key:zSubject",
+			IssuedAt:  time.Now().Add(-time.Hour),
+			ExpiresAt: time.Now().Add(-d),
+			Custom:    map[string]json.RawMessage{"vc": json.RawMessage(`{"type":["VerifiableCredential"]}`)},
+		})
+		if err != nil {
+			t.Fatalf("Failed to sign: %v", err)
+		}
+		return token
+	}
+
+	opts := VerifyOptions{Leeway: 60 * time.Second}
+	if _, err := VerifyVCWithOptions(expiredAgo(30*time.Second), issuerPub, opts); err != nil {
+		t.Errorf("Expected a credential 30s past expiry to verify with 60s leeway, got %v", err)
+	}
+	if _, err := VerifyVCWithOptions(expiredAgo(90*time.Second), issuerPub, opts); !errors.Is(err, ErrCredentialExpired) {
+		t.Errorf("Expected ErrCredentialExpired 90s past expiry, got %v", err)
+	}
+}
+
+func TestVerifyVCWithOptions_IssuerPublishedTypes(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	issuerDID := "did:key:zIssuer"
+
+	// The issuer only publishes identity credentials
+	metadata := resolver.NewResolverWithMetadataFetcher(func(_ context.Context, did string) ([]byte, error) {
+		return []byte(`{"issuer":"` + did + `","credentialTypes":["` + CredentialTypeIdentity + `"]}`), nil
+	})
+	opts := VerifyOptions{MetadataResolver: metadata}
+
+	published, _ := IssueVC(issuerDID, "did:key:zSubject", issuerPriv, testIdentitySubject("did:key:zSubject"))
+	if _, err := VerifyVCWithOptions(published, issuerPub, opts); err != nil {
+		t.Errorf("Expected published type to verify, got %v", err)
+	}
+
+	// Validly signed, but of a type the issuer never issues
+	forged, _ := IssueVC(issuerDID, "did:key:zSubject", issuerPriv, EducationSubject{ID: "did:key:zSubject", InstitutionName: "University of Technology"})
+	if _, err := VerifyVCWithOptions(forged, issuerPub, opts); err != ErrTypeNotPublished {
+		t.Errorf("Expected ErrTypeNotPublished, got %v", err)
+	}
+}
+
+func TestVerifyVCRejectsPresentationToken(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+
+	// Shaped like presentation.CreatePresentation output (that package imports vc)
+	secretKey, _ := paseto.NewV4AsymmetricSecretKeyFromBytes(priv)
+	token := paseto.NewToken()
+	token.SetIssuer("did:key:zHolder")
+	token.SetSubject("did:key:zHolder")
+	token.SetAudience("did:key:zVerifier")
+	token.SetIssuedAt(time.Now())
+	token.SetExpiration(time.Now().Add(15 * time.Minute))
+	token.SetString("nonce", "nonce")
+	token.Set("vp", map[string]interface{}{"holder": "did:key:zHolder", "verifiableCredential": []string{"v4.public.test"}})
+	vpToken := token.V4Sign(secretKey, nil)
+
+	_, err := VerifyVC(vpToken, pub)
+	if err != ErrNotACredential {
+		t.Errorf("Expected ErrNotACredential, got %v", err)
+	}
+}
+
+func TestUnverifiedIssuer(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+
+	token, _ := IssueVC("did:key:zIssuer", "did:key:zSubject", priv, testIdentitySubject("did:key:zSubject"))
+	issuer, err := UnverifiedIssuer(token)
+	if err != nil {
+		t.Fatalf("UnverifiedIssuer failed: %v", err)
+	}
+	if issuer != "did:key:zIssuer" {
+		t.Errorf("Expected issuer did:key:zIssuer, got %s", issuer)
+	}
+
+	for _, bad := range []string{"", "v4.local.abc", "v4.public.!!!", "v4.public.YWJj"} {
+		if _, err := UnverifiedIssuer(bad); err != ErrMalformedToken {
+			t.Errorf("Expected ErrMalformedToken for %q, got %v", bad, err)
+		}
+	}
+}
+
+func TestCheckWellFormed(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+
+	token, _ := IssueVC("did:key:zIssuer", "did:key:zSubject", priv, testIdentitySubject("did:key:zSubject"))
+	if err := CheckWellFormed(token); err != nil {
+		t.Errorf("Expected issued credential to be well-formed, got %v", err)
+	}
+
+	// Well-formed PASETO without a vc claim
+	noVC := paseto.NewToken()
+	noVC.SetIssuer("did:key:zIssuer")
+	secretKey, _ := paseto.NewV4AsymmetricSecretKeyFromBytes(priv)
+	if err := CheckWellFormed(noVC.V4Sign(secretKey, nil)); err != ErrMalformedToken {
+		t.Errorf("Expected ErrMalformedToken for a token without vc, got %v", err)
+	}
+
+	for _, bad := range []string{"cred", "v4.public.!!!", "v4.public.YWJj"} {
+		if err := CheckWellFormed(bad); err != ErrMalformedToken {
+			t.Errorf("Expected ErrMalformedToken for %q, got %v", bad, err)
+		}
+	}
+}
+
+func TestIssueVCWithOptionsValidityBounds(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	subject := testIdentitySubject("did:key:zSubject")
+	bounds := IssueOptions{MinValidity: time.Hour, MaxValidity: 30 * 24 * time.Hour}
+
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		wantErr   bool
+	}{
+		{"within bounds", time.Now().Add(7 * 24 * time.Hour), false},
+		{"below minimum", time.Now().Add(10 * time.Minute), true},
+		{"above maximum", time.Now().Add(100 * 365 * 24 * time.Hour), true},
+		{"already expired", time.Now().Add(-time.Hour), true},
+		{"default validity exceeds maximum", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := bounds
+			opts.ExpiresAt = tt.expiresAt
+
+			token, err := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", priv, subject, opts)
+			if tt.wantErr {
+				if !errors.Is(err, ErrValidityOutOfBounds) {
+					t.Errorf("Expected ErrValidityOutOfBounds, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("IssueVCWithOptions failed: %v", err)
+			}
+
+			claims, err := VerifyVC(token, pub)
+			if err != nil {
+				t.Fatalf("VerifyVC failed: %v", err)
+			}
+			if !claims.ExpiresAt.Equal(tt.expiresAt.Truncate(time.Second)) {
+				t.Errorf("Expected expiration %v, got %v", tt.expiresAt, claims.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestIssueVCWithOptionsUnboundedByDefault(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+
+	_, err := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", priv, testIdentitySubject("did:key:zSubject"), IssueOptions{
+		ExpiresAt: time.Now().Add(100 * 365 * 24 * time.Hour),
+	})
+	if err != nil {
+		t.Errorf("Expected no bounds by default, got %v", err)
+	}
+}
+
+func TestIssueVCWithOptionsLifetime(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	subject := testIdentitySubject("did:key:zSubject")
+
+	token, err := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", priv, subject, IssueOptions{Lifetime: 30 * time.Minute})
+	if err != nil {
+		t.Fatalf("IssueVCWithOptions failed: %v", err)
+	}
+
+	claims, err := VerifyVC(token, pub)
+	if err != nil {
+		t.Fatalf("VerifyVC failed: %v", err)
+	}
+	if got := claims.ExpiresAt.Sub(claims.IssuedAt); got < 30*time.Minute-time.Second || got > 30*time.Minute+time.Second {
+		t.Errorf("Expected a 30 minute lifetime, got %v", got)
+	}
+
+	// A short-lived credential stops verifying once its lifetime has passed,
+	// when no clock skew is tolerated
+	short, _ := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", priv, subject, IssueOptions{Lifetime: time.Second})
+	time.Sleep(1100 * time.Millisecond)
+	if _, err := VerifyVCWithOptions(short, pub, VerifyOptions{Leeway: -1}); err == nil {
+		t.Error("Expected expired credential to fail verification")
+	}
+}
+
+func TestIssueVCWithOptionsNotBefore(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	subject := testIdentitySubject("did:key:zSubject")
+	notBefore := time.Now().Add(time.Hour)
+
+	token, err := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", priv, subject, IssueOptions{
+		NotBefore: notBefore,
+		Lifetime:  24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("IssueVCWithOptions failed: %v", err)
+	}
+	if _, err := VerifyVC(token, pub); err != ErrNotYetValid {
+		t.Errorf("Expected ErrNotYetValid, got %v", err)
+	}
+
+	_, err = IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", priv, subject, IssueOptions{
+		NotBefore: notBefore,
+		ExpiresAt: notBefore.Add(-time.Minute),
+	})
+	if !errors.Is(err, ErrValidityOutOfBounds) {
+		t.Errorf("Expected ErrValidityOutOfBounds for expiry before nbf, got %v", err)
+	}
+
+	_, err = IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", priv, subject, IssueOptions{
+		ExpiresAt: notBefore,
+		Lifetime:  time.Hour,
+	})
+	if err != ErrConflictingExpiry {
+		t.Errorf("Expected ErrConflictingExpiry, got %v", err)
+	}
+}
+
+func TestIssueVCWithSubjects(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+
+	partners := []CredentialSubject{
+		MembershipSubject{ID: "did:key:zPartnerA", OrganizationName: "Registry Office", StartDate: "2024-06-01"},
+		MembershipSubject{ID: "did:key:zPartnerB", OrganizationName: "Registry Office", StartDate: "2024-06-01"},
+	}
+
+	token, err := IssueVCWithSubjects("did:key:zIssuer", "did:key:zPartnerA", priv, partners, IssueOptions{})
+	if err != nil {
+		t.Fatalf("IssueVCWithSubjects failed: %v", err)
+	}
+
+	claims, err := VerifyVC(token, pub)
+	if err != nil {
+		t.Fatalf("VerifyVC failed: %v", err)
+	}
+	if _, isArray := claims.VC.CredentialSubject.([]interface{}); !isArray {
+		t.Errorf("Expected credentialSubject array on the wire, got %T", claims.VC.CredentialSubject)
+	}
+
+	subjects := claims.Subjects()
+	if len(subjects) != 2 {
+		t.Fatalf("Expected 2 subjects, got %d", len(subjects))
+	}
+	if subjects[0]["id"] != "did:key:zPartnerA" || subjects[1]["id"] != "did:key:zPartnerB" {
+		t.Errorf("Unexpected subject IDs: %v, %v", subjects[0]["id"], subjects[1]["id"])
+	}
+
+	// A single subject stays an object for compatibility
+	single, _ := IssueVCWithSubjects("did:key:zIssuer", "did:key:zPartnerA", priv, partners[:1], IssueOptions{})
+	claims, _ = VerifyVC(single, pub)
+	if _, isObject := claims.VC.CredentialSubject.(map[string]interface{}); !isObject {
+		t.Errorf("Expected credentialSubject object for one subject, got %T", claims.VC.CredentialSubject)
+	}
+	if subjects := claims.Subjects(); len(subjects) != 1 || subjects[0]["id"] != "did:key:zPartnerA" {
+		t.Errorf("Expected one normalized subject, got %v", subjects)
+	}
+}
+
+func TestIssueVCWithSubjectsErrors(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+
+	if _, err := IssueVCWithSubjects("did:key:zIssuer", "did:key:zSubject", priv, nil, IssueOptions{}); err != ErrNoSubjects {
+		t.Errorf("Expected ErrNoSubjects, got %v", err)
+	}
+
+	mixed := []CredentialSubject{testIdentitySubject("did:key:zA"), MembershipSubject{ID: "did:key:zB"}}
+	if _, err := IssueVCWithSubjects("did:key:zIssuer", "did:key:zSubject", priv, mixed, IssueOptions{}); err != ErrMixedSubjectTypes {
+		t.Errorf("Expected ErrMixedSubjectTypes, got %v", err)
+	}
+}
+
+func TestIssueVCWithHolderKeyBinding(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	holderPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	subject := testIdentitySubject("did:key:zSubject")
+
+	token, err := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", issuerPriv, subject, IssueOptions{HolderKey: holderPub})
+	if err != nil {
+		t.Fatalf("IssueVCWithOptions failed: %v", err)
+	}
+	claims, err := VerifyVC(token, issuerPub)
+	if err != nil {
+		t.Fatalf("VerifyVC failed: %v", err)
+	}
+	bound, ok := claims.BoundKey()
+	if !ok || !bound.Equal(holderPub) {
+		t.Errorf("Expected credential bound to the holder key, got %v", claims.Confirmation)
+	}
+
+	unbound, _ := IssueVC("did:key:zIssuer", "did:key:zSubject", issuerPriv, subject)
+	claims, _ = VerifyVC(unbound, issuerPub)
+	if _, ok := claims.BoundKey(); ok || claims.Confirmation != nil {
+		t.Error("Expected no cnf claim without a holder key")
+	}
+
+	if _, err := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", issuerPriv, subject, IssueOptions{HolderKey: holderPub[:8]}); err != ErrInvalidHolderKey {
+		t.Errorf("Expected ErrInvalidHolderKey, got %v", err)
+	}
+}
+
+func TestIssueVCStrictW3C(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	subject := testIdentitySubject("did:key:zSubject")
+
+	token, err := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", issuerPriv, subject, IssueOptions{StrictW3C: true})
+	if err != nil {
+		t.Fatalf("IssueVCWithOptions failed: %v", err)
+	}
+
+	// Extract the vc body as a standalone document
+	var payload struct {
+		VC json.RawMessage `json:"vc"`
+	}
+	if err := decodeUnverified(token, &payload); err != nil {
+		t.Fatalf("Failed to decode token payload: %v", err)
+	}
+	var body map[string]interface{}
+	json.Unmarshal(payload.VC, &body)
+	for _, key := range []string{"@context", "id", "type", "issuer", "issuanceDate", "expirationDate", "credentialSubject"} {
+		if _, ok := body[key]; !ok {
+			t.Errorf("Expected vc body to contain %q", key)
+		}
+	}
+
+	var standalone VerifiableCredential
+	if err := json.Unmarshal(payload.VC, &standalone); err != nil {
+		t.Fatalf("Failed to decode vc body: %v", err)
+	}
+	if err := standalone.ValidateW3C(); err != nil {
+		t.Errorf("Expected strict body to validate, got %v", err)
+	}
+	if standalone.Issuer != "did:key:zIssuer" {
+		t.Errorf("Expected issuer did:key:zIssuer, got %s", standalone.Issuer)
+	}
+
+	claims, err := VerifyVC(token, issuerPub)
+	if err != nil {
+		t.Fatalf("VerifyVC failed: %v", err)
+	}
+	if claims.VC.IssuanceDate != claims.IssuedAt.UTC().Format(time.RFC3339) {
+		t.Errorf("Expected issuanceDate to match iat, got %s and %v", claims.VC.IssuanceDate, claims.IssuedAt)
+	}
+
+	// The default body relies on the token claims and is not standalone
+	compact, _ := IssueVC("did:key:zIssuer", "did:key:zSubject", issuerPriv, subject)
+	claims, _ = VerifyVC(compact, issuerPub)
+	if err := claims.VC.ValidateW3C(); !errors.Is(err, ErrNotW3CConformant) {
+		t.Errorf("Expected ErrNotW3CConformant for the default body, got %v", err)
+	}
+}
+
+func TestRefreshServiceRoundTrip(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	subject := testIdentitySubject("did:key:zSubject")
+	refresh := &RefreshService{ID: "https://issuer.example.com/refresh/cred-1", Type: "ManualRefreshService2018"}
+
+	token, err := IssueVCWithOptions("did:key:zIssuer", "did:key:zSubject", issuerPriv, subject, IssueOptions{
+		Lifetime:       24 * time.Hour,
+		RefreshService: refresh,
+	})
+	if err != nil {
+		t.Fatalf("IssueVCWithOptions failed: %v", err)
+	}
+	claims, err := VerifyVC(token, issuerPub)
+	if err != nil {
+		t.Fatalf("VerifyVC failed: %v", err)
+	}
+	if claims.VC.RefreshService == nil || *claims.VC.RefreshService != *refresh {
+		t.Errorf("Expected refresh service %v, got %v", refresh, claims.VC.RefreshService)
+	}
+
+	now := time.Now()
+	if claims.RefreshDue(now, time.Hour) {
+		t.Error("Expected refresh not due a day before expiry with a one hour window")
+	}
+	if !claims.RefreshDue(now, 48*time.Hour) {
+		t.Error("Expected refresh due when expiry falls within the window")
+	}
+
+	// Omitted from the JSON when empty
+	data, _ := json.Marshal(VerifiableCredential{Type: []string{"VerifiableCredential"}, CredentialSubject: subject})
+	var body map[string]interface{}
+	json.Unmarshal(data, &body)
+	if _, ok := body["refreshService"]; ok {
+		t.Errorf("Expected refreshService to be omitted, got %s", data)
+	}
+
+	unrefreshable, _ := IssueVC("did:key:zIssuer", "did:key:zSubject", issuerPriv, subject)
+	claims, _ = VerifyVC(unrefreshable, issuerPub)
+	if claims.VC.RefreshService != nil || claims.RefreshDue(now, 10*365*24*time.Hour) {
+		t.Error("Expected no refresh service on a credential issued without one")
+	}
+
+	// Round-trips through JSON unchanged
+	data, _ = json.Marshal(VerifiableCredential{Type: []string{"VerifiableCredential"}, RefreshService: refresh})
+	var decoded VerifiableCredential
+	json.Unmarshal(data, &decoded)
+	if decoded.RefreshService == nil || *decoded.RefreshService != *refresh {
+		t.Errorf("Expected refresh service to round-trip, got %s", data)
+	}
+}
+
+func TestUnverifiedClaims(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	token, _ := IssueVCWithID("did:key:zIssuer", "did:key:zSubject", priv, testIdentitySubject("did:key:zSubject"), "urn:uuid:unverified")
+
+	claims, err := UnverifiedClaims(token)
+	if err != nil {
+		t.Fatalf("UnverifiedClaims failed: %v", err)
+	}
+	if claims.Issuer != "did:key:zIssuer" || claims.GetCredentialID() != "urn:uuid:unverified" {
+		t.Errorf("Unexpected claims: issuer %s, ID %s", claims.Issuer, claims.GetCredentialID())
+	}
+	if claims.CredentialType() != CredentialTypeIdentity {
+		t.Errorf("Expected type %s, got %s", CredentialTypeIdentity, claims.CredentialType())
+	}
+	if claims.ExpiresAt.IsZero() || claims.IssuedAt.IsZero() {
+		t.Error("Expected iat and exp to be decoded")
+	}
+
+	if _, err := UnverifiedClaims("v4.public.not-a-token"); err != ErrMalformedToken {
+		t.Errorf("Expected ErrMalformedToken, got %v", err)
+	}
+}
+
+func TestVerifyVCAny(t *testing.T) {
+	oldPub, oldPriv, _ := ed25519.GenerateKey(rand.Reader)
+	newPub, newPriv, _ := ed25519.GenerateKey(rand.Reader)
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	subject := testIdentitySubject("did:key:zSubject")
+
+	// A credential signed before the issuer rotated still verifies
+	before, _ := IssueVC("did:web:issuer.example", "did:key:zSubject", oldPriv, subject)
+	after, _ := IssueVC("did:web:issuer.example", "did:key:zSubject", newPriv, subject)
+	keys := []ed25519.PublicKey{newPub, oldPub}
+	for _, token := range []string{before, after} {
+		if _, err := VerifyVCAny(token, keys); err != nil {
+			t.Errorf("Expected VerifyVCAny to succeed, got %v", err)
+		}
+	}
+
+	if _, err := VerifyVCAny(before, []ed25519.PublicKey{newPub, otherPub}); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid without the signing key, got %v", err)
+	}
+	if _, err := VerifyVCAny(before, nil); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid without keys, got %v", err)
+	}
+
+	// A matching key with an expired credential reports the expiry
+	secretKey, _ := paseto.NewV4AsymmetricSecretKeyFromBytes(oldPriv)
+	expired := paseto.NewToken()
+	expired.SetIssuer("did:web:issuer.example")
+	expired.SetIssuedAt(time.Now().Add(-2 * time.Hour))
+	expired.SetExpiration(time.Now().Add(-time.Hour))
+	expired.Set("vc", VerifiableCredential{Type: []string{"VerifiableCredential"}, CredentialSubject: subject})
+	if _, err := VerifyVCAny(expired.V4Sign(secretKey, nil), keys); !errors.Is(err, ErrCredentialExpired) {
+		t.Errorf("Expected ErrCredentialExpired, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package vc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/veriglob/veriglob-core/internal/resolver"
+)
+
+var (
+	ErrUntrustedIssuer  = errors.New("untrusted issuer")
+	ErrInvalidTrustList = errors.New("invalid trust list")
+)
+
+// TrustList is a verifier's allowlist of issuer DIDs. An issuer is trusted
+// for every credential type, or only for the types it is listed with. Once
+// any issuer is listed for a type, only the issuers listed for that type may
+// issue it: trusting a university DID for EducationCredential stops issuers
+// trusted for everything from issuing education credentials.
+type TrustList struct {
+	anyType map[string]bool            // issuers trusted for every unscoped type
+	byType  map[string]map[string]bool // credential type -> trusted issuers
+}
+
+// NewTrustList creates an empty trust list, which trusts no issuer
+func NewTrustList() *TrustList {
+	return &TrustList{
+		anyType: make(map[string]bool),
+		byType:  make(map[string]map[string]bool),
+	}
+}
+
+// Trust adds an issuer DID, trusted for the given credential types, or for
+// every type not scoped to other issuers when none are given
+func (t *TrustList) Trust(issuerDID string, credentialTypes ...string) {
+	if len(credentialTypes) == 0 {
+		t.anyType[issuerDID] = true
+		return
+	}
+	for _, ct := range credentialTypes {
+		if t.byType[ct] == nil {
+			t.byType[ct] = make(map[string]bool)
+		}
+		t.byType[ct][issuerDID] = true
+	}
+}
+
+// Trusts reports whether the issuer may issue a credential of the given type
+func (t *TrustList) Trusts(issuerDID, credentialType string) bool {
+	if scoped, ok := t.byType[credentialType]; ok {
+		return scoped[issuerDID]
+	}
+	return t.anyType[issuerDID]
+}
+
+// Check returns ErrUntrustedIssuer unless the credential's issuer is trusted
+// for each of its types other than VerifiableCredential, so an extra type
+// cannot smuggle a credential past a type the issuer is not trusted for. It
+// only looks at the claims; verify the signature first.
+func (t *TrustList) Check(claims *VCClaims) error {
+	types := 0
+	for _, ct := range claims.VC.Type {
+		if ct == "VerifiableCredential" {
+			continue
+		}
+		types++
+		if !t.Trusts(claims.Issuer, ct) {
+			return fmt.Errorf("%w: %s is not trusted to issue %s", ErrUntrustedIssuer, claims.Issuer, ct)
+		}
+	}
+	if types == 0 && !t.anyType[claims.Issuer] {
+		return fmt.Errorf("%w: %s", ErrUntrustedIssuer, claims.Issuer)
+	}
+	return nil
+}
+
+// ParseTrustList reads a trust list file: one issuer DID per line, optionally
+// followed by whitespace and a comma-separated list of the credential types
+// it is trusted for. Blank lines and lines starting with # are ignored.
+//
+//	# Trusted for everything not scoped below
+//	did:web:gov.example
+//	did:web:university.example EducationCredential
+func ParseTrustList(data []byte) (*TrustList, error) {
+	t := NewTrustList()
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if resolver.ValidateDID(fields[0]) != nil {
+			return nil, fmt.Errorf("%w: line %d: %q", ErrInvalidTrustList, n, line)
+		}
+		var types []string
+		if len(fields) > 1 {
+			for _, ct := range strings.Split(strings.Join(fields[1:], " "), ",") {
+				ct = strings.TrimSpace(ct)
+				if ct == "" || strings.Contains(ct, " ") {
+					return nil, fmt.Errorf("%w: line %d: %q", ErrInvalidTrustList, n, line)
+				}
+				types = append(types, ct)
+			}
+		}
+		t.Trust(fields[0], types...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTrustList, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,113 @@
+package vc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/veriglob/veriglob-core/internal/did"
+)
+
+func TestTrustList(t *testing.T) {
+	trust := NewTrustList()
+	trust.Trust("did:web:gov.example")
+	trust.Trust("did:web:uni.example", CredentialTypeEducation)
+	trust.Trust("did:web:hr.example", CredentialTypeEmployment, CredentialTypeMembership)
+
+	tests := []struct {
+		name    string
+		issuer  string
+		types   []string
+		trusted bool
+	}{
+		{"trusted for all types", "did:web:gov.example", []string{"VerifiableCredential", CredentialTypeIdentity}, true},
+		{"scoped type", "did:web:uni.example", []string{"VerifiableCredential", CredentialTypeEducation}, true},
+		{"outside its scope", "did:web:uni.example", []string{"VerifiableCredential", CredentialTypeIdentity}, false},
+		{"type scoped to another issuer", "did:web:gov.example", []string{"VerifiableCredential", CredentialTypeEducation}, false},
+		{"second scoped type", "did:web:hr.example", []string{"VerifiableCredential", CredentialTypeMembership}, true},
+		{"extra untrusted type", "did:web:uni.example", []string{"VerifiableCredential", CredentialTypeEducation, CredentialTypeIdentity}, false},
+		{"unlisted issuer", "did:web:evil.example", []string{"VerifiableCredential", CredentialTypeIdentity}, false},
+		{"no specific type", "did:web:gov.example", []string{"VerifiableCredential"}, true},
+		{"no specific type from scoped issuer", "did:web:uni.example", []string{"VerifiableCredential"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &VCClaims{Issuer: tt.issuer, VC: VerifiableCredential{Type: tt.types}}
+			err := trust.Check(claims)
+			if tt.trusted && err != nil {
+				t.Errorf("Expected trusted, got %v", err)
+			}
+			if !tt.trusted && !errors.Is(err, ErrUntrustedIssuer) {
+				t.Errorf("Expected ErrUntrustedIssuer, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseTrustList(t *testing.T) {
+	trust, err := ParseTrustList([]byte(`
+# Trusted for everything not scoped below
+did:web:gov.example
+did:web:uni.example   EducationCredential, MembershipCredential
+`))
+	if err != nil {
+		t.Fatalf("ParseTrustList failed: %v", err)
+	}
+	if !trust.Trusts("did:web:gov.example", CredentialTypeIdentity) {
+		t.Error("Expected gov.example to be trusted for identity credentials")
+	}
+	if !trust.Trusts("did:web:uni.example", CredentialTypeEducation) || trust.Trusts("did:web:uni.example", CredentialTypeIdentity) {
+		t.Error("Expected uni.example to be trusted for education credentials only")
+	}
+
+	for _, bad := range []string{"gov.example", "did:web:a.example EducationCredential extra", "did:web:a.example ,"} {
+		if _, err := ParseTrustList([]byte(bad)); !errors.Is(err, ErrInvalidTrustList) {
+			t.Errorf("Expected ErrInvalidTrustList for %q, got %v", bad, err)
+		}
+	}
+}
+
+func TestVerifyVCWithOptionsTrustedIssuers(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	issuer, _ := did.CreateDIDKey(issuerPub)
+	token, err := IssueVC(issuer.DID, "did:key:zSubject", issuerPriv, testIdentitySubject("did:key:zSubject"))
+	if err != nil {
+		t.Fatalf("IssueVC failed: %v", err)
+	}
+
+	trust := NewTrustList()
+	trust.Trust(issuer.DID, CredentialTypeIdentity)
+	if _, err := VerifyVCWithOptions(token, issuerPub, VerifyOptions{TrustedIssuers: trust}); err != nil {
+		t.Errorf("Expected a trusted issuer to verify, got %v", err)
+	}
+
+	trust = NewTrustList()
+	trust.Trust("did:key:zOther")
+	if _, err := VerifyVCWithOptions(token, issuerPub, VerifyOptions{TrustedIssuers: trust}); !errors.Is(err, ErrUntrustedIssuer) {
+		t.Errorf("Expected ErrUntrustedIssuer, got %v", err)
+	}
+}
+
+func TestVerifyVCWithOptionsTrustedIssuersForgedIssuer(t *testing.T) {
+	trustedPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	trusted, _ := did.CreateDIDKey(trustedPub)
+	trust := NewTrustList()
+	trust.Trust(trusted.DID)
+
+	// An attacker signs with their own key but names the trusted issuer
+	attackerPub, attackerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	forged, err := IssueVC(trusted.DID, "did:key:zSubject", attackerPriv, testIdentitySubject("did:key:zSubject"))
+	if err != nil {
+		t.Fatalf("IssueVC failed: %v", err)
+	}
+
+	if _, err := VerifyVCWithOptions(forged, attackerPub, VerifyOptions{TrustedIssuers: trust}); !errors.Is(err, ErrIssuerKeyMismatch) {
+		t.Errorf("Expected ErrIssuerKeyMismatch, got %v", err)
+	}
+	// Without a trust list the caller vouches for the key, as before
+	if _, err := VerifyVCWithOptions(forged, attackerPub, VerifyOptions{}); err != nil {
+		t.Errorf("Expected the signature to verify without a trust list, got %v", err)
+	}
+}
@@ -0,0 +1,298 @@
+package vc
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownCredentialType = errors.New("unknown credential type")
+	ErrTypeAlreadyRegistered = errors.New("credential type already registered")
+	ErrInvalidSubjectFields  = errors.New("invalid credential subject fields")
+	ErrMissingRequiredField  = errors.New("missing required credential subject field")
+	ErrSubjectFactoryNotPtr  = errors.New("subject factory must return a pointer")
+	ErrSubjectTypeMismatch   = errors.New("credential type does not match the subject type")
+	ErrInvalidSubjectTarget  = errors.New("invalid credential subject decode target")
+)
+
+// SubjectFactory returns a new, empty subject (as a pointer) for a registered credential type
+type SubjectFactory func() CredentialSubject
+
+// Validator is implemented by subjects that can check their own required fields
+type Validator interface {
+	Validate() error
+}
+
+// IssueOptions carries optional parameters for issuance
+type IssueOptions struct {
+	// CredentialID sets the credential ID (and a registry credentialStatus)
+	CredentialID string
+	// Status overrides the credentialStatus, e.g. a StatusList2021Entry
+	Status *CredentialStatus
+	// NotBefore delays when the credential becomes valid (the nbf claim)
+	NotBefore time.Time
+	// ExpiresAt sets an absolute expiration. Lifetime sets one relative to
+	// NotBefore, or to issuance if NotBefore is unset. Set at most one; with
+	// neither, DefaultValidity applies.
+	ExpiresAt time.Time
+	Lifetime  time.Duration
+	// MinValidity and MaxValidity bound how long the credential may be
+	// valid from issuance; zero leaves that side unbounded
+	MinValidity time.Duration
+	MaxValidity time.Duration
+	// HolderKey, when set, binds the credential to this key through the cnf
+	// claim; presentations of it must be signed by the key. Pass a key
+	// derived for one verifier to make presentations unlinkable.
+	HolderKey ed25519.PublicKey
+	// StrictW3C makes the vc claim a standalone W3C credential, adding
+	// @context, issuer, issuanceDate, expirationDate and an id (generated if
+	// CredentialID is empty) for verifiers that read the body on its own
+	StrictW3C bool
+	// RefreshService, when set, tells holders where to renew the credential
+	RefreshService *RefreshService
+	// CredentialSchema, when set, references the schema the subject conforms to
+	CredentialSchema *CredentialSchema
+	// IssuerDisplay, when set, gives wallets the issuer's name, logo and
+	// description to display; the vc issuer becomes an object carrying them
+	IssuerDisplay *IssuerDisplay
+	// Suite, when set, signs the credential instead of the suite picked from
+	// the private key, which may then be nil
+	Suite SignatureSuite
+	// DelegationChain attaches the delegation credentials that authorize the
+	// issuer, ordered from the one issued by the root authority down to the
+	// one issued to the issuer
+	DelegationChain []string
+	// Encoding serializes the vc claim; empty uses EncodingJSON. EncodingCBOR
+	// makes the token smaller for constrained verifiers.
+	Encoding Encoding
+}
+
+var (
+	subjectTypesMu sync.RWMutex
+	subjectTypes   = map[string]SubjectFactory{
+		CredentialTypeIdentity:   func() CredentialSubject { return &IdentitySubject{} },
+		CredentialTypeEducation:  func() CredentialSubject { return &EducationSubject{} },
+		CredentialTypeEmployment: func() CredentialSubject { return &EmploymentSubject{} },
+		CredentialTypeMembership: func() CredentialSubject { return &MembershipSubject{} },
+		CredentialTypeDelegation: func() CredentialSubject { return &DelegationSubject{} },
+	}
+)
+
+// RegisterSubjectType makes a credential type available to IssueTyped
+func RegisterSubjectType(typeName string, factory SubjectFactory) error {
+	subjectTypesMu.Lock()
+	defer subjectTypesMu.Unlock()
+
+	if _, exists := subjectTypes[typeName]; exists {
+		return ErrTypeAlreadyRegistered
+	}
+	subjectTypes[typeName] = factory
+	return nil
+}
+
+// LookupSubjectType returns the factory for a registered credential type
+func LookupSubjectType(typeName string) (SubjectFactory, bool) {
+	subjectTypesMu.RLock()
+	defer subjectTypesMu.RUnlock()
+
+	factory, ok := subjectTypes[typeName]
+	return factory, ok
+}
+
+// NewSubjectFromMap constructs the registered subject type from a field map,
+// rejecting unknown fields and running the subject's validation
+func NewSubjectFromMap(typeName string, fields map[string]interface{}) (CredentialSubject, error) {
+	factory, ok := LookupSubjectType(typeName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCredentialType, typeName)
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := factory()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(subject); err != nil {
+		if _, isUnmarshalTarget := err.(*json.InvalidUnmarshalError); isUnmarshalTarget {
+			return nil, ErrSubjectFactoryNotPtr
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSubjectFields, err)
+	}
+
+	if v, ok := subject.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return subject, nil
+}
+
+// IssueTyped issues a credential of a registered type from a field map. The
+// subject ID defaults to subjectDID when the map does not set "id".
+func IssueTyped(
+	typeName string,
+	fields map[string]interface{},
+	issuerDID string,
+	subjectDID string,
+	privateKey ed25519.PrivateKey,
+	opts IssueOptions,
+) (string, error) {
+	withID := make(map[string]interface{}, len(fields)+1)
+	for k, v := range fields {
+		withID[k] = v
+	}
+	if _, ok := withID["id"]; !ok {
+		withID["id"] = subjectDID
+	}
+
+	subject, err := NewSubjectFromMap(typeName, withID)
+	if err != nil {
+		return "", err
+	}
+
+	return IssueVCWithOptions(issuerDID, subjectDID, privateKey, subject, opts)
+}
+
+// CredentialType returns the credential's specific type, i.e. the first
+// type other than "VerifiableCredential", or "" if there is none
+func (c *VCClaims) CredentialType() string {
+	for _, t := range c.VC.Type {
+		if t != "VerifiableCredential" {
+			return t
+		}
+	}
+	return ""
+}
+
+// IsKnownType reports whether the credential's type is registered. Credentials
+// of unknown types still verify; their subject is only available as a map.
+func (c *VCClaims) IsKnownType() bool {
+	_, ok := LookupSubjectType(c.CredentialType())
+	return ok
+}
+
+// SubjectMap returns the credential subject as a generic field map. Use
+// Subjects for credentials that may name more than one subject.
+func (c *VCClaims) SubjectMap() (map[string]interface{}, error) {
+	if m, ok := c.VC.CredentialSubject.(map[string]interface{}); ok {
+		return m, nil
+	}
+
+	data, err := json.Marshal(c.VC.CredentialSubject)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSubjectFields, err)
+	}
+	return m, nil
+}
+
+// Subjects returns every credential subject as a field map, whether
+// credentialSubject was a single object or an array on the wire
+func (c *VCClaims) Subjects() []map[string]interface{} {
+	data, err := json.Marshal(c.VC.CredentialSubject)
+	if err != nil {
+		return nil
+	}
+
+	var many []map[string]interface{}
+	if err := json.Unmarshal(data, &many); err == nil {
+		return many
+	}
+
+	var one map[string]interface{}
+	if err := json.Unmarshal(data, &one); err != nil || one == nil {
+		return nil
+	}
+	return []map[string]interface{}{one}
+}
+
+// TypedSubject decodes the credential subject into its registered type.
+// It returns ErrUnknownCredentialType when IsKnownType is false.
+func (c *VCClaims) TypedSubject() (CredentialSubject, error) {
+	typeName := c.CredentialType()
+	factory, ok := LookupSubjectType(typeName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCredentialType, typeName)
+	}
+
+	// Verification is lenient: extra fields from newer issuers are ignored
+	subject := factory()
+	if err := c.decodeSubject(subject, typeName); err != nil {
+		return nil, err
+	}
+	return subject, nil
+}
+
+// DecodeSubjectInto decodes the credential subject into dst, a non-nil
+// pointer to a subject struct such as &IdentitySubject{}. The credential's
+// type must be dst's CredentialType, otherwise ErrSubjectTypeMismatch is
+// returned. Like TypedSubject it ignores fields dst does not have.
+func (c *VCClaims) DecodeSubjectInto(dst CredentialSubject) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return ErrInvalidSubjectTarget
+	}
+	return c.decodeSubject(dst, dst.CredentialType())
+}
+
+// DecodeSubject decodes the credential subject into the subject type T, e.g.
+// DecodeSubject[IdentitySubject](claims). T is the struct type itself, not a
+// pointer to it. The credential's type must be T's CredentialType, otherwise
+// ErrSubjectTypeMismatch is returned. Use TypedSubject when the type is not
+// known in advance.
+func DecodeSubject[T CredentialSubject](c *VCClaims) (T, error) {
+	var subject T
+	if reflect.TypeFor[T]().Kind() == reflect.Pointer {
+		return subject, ErrInvalidSubjectTarget
+	}
+	if err := c.decodeSubject(&subject, subject.CredentialType()); err != nil {
+		return subject, err
+	}
+	return subject, nil
+}
+
+// decodeSubject checks the credential is of typeName and unmarshals its
+// subject into dst
+func (c *VCClaims) decodeSubject(dst any, typeName string) error {
+	if got := c.CredentialType(); got != typeName {
+		return fmt.Errorf("%w: credential is %s, not %s", ErrSubjectTypeMismatch, got, typeName)
+	}
+
+	data, err := json.Marshal(c.VC.CredentialSubject)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSubjectFields, err)
+	}
+	return nil
+}
+
+// requiredField pairs a subject field's JSON name with its value
+type requiredField struct {
+	name  string
+	value string
+}
+
+// requireFields returns ErrMissingRequiredField naming the first empty field
+func requireFields(fields ...requiredField) error {
+	for _, f := range fields {
+		if f.value == "" {
+			return fmt.Errorf("%w: %s", ErrMissingRequiredField, f.name)
+		}
+	}
+	return nil
+}
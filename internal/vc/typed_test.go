@@ -0,0 +1,234 @@
+package vc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func TestIssueTypedBuiltInTypes(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+	subjectDID := "did:key:zSubject"
+
+	tests := []struct {
+		typeName string
+		fields   map[string]interface{}
+		check    string
+	}{
+		{
+			typeName: CredentialTypeIdentity,
+			fields:   map[string]interface{}{"givenName": "Alice", "familyName": "Doe", "dateOfBirth": "1990-01-01"},
+			check:    "givenName",
+		},
+		{
+			typeName: CredentialTypeEducation,
+			fields:   map[string]interface{}{"institutionName": "University of Technology", "creditsEarned": 120},
+			check:    "institutionName",
+		},
+		{
+			typeName: CredentialTypeEmployment,
+			fields:   map[string]interface{}{"employerName": "Tech Corp", "jobTitle": "Engineer", "startDate": "2021-06-01", "currentEmployee": true},
+			check:    "employerName",
+		},
+		{
+			typeName: CredentialTypeMembership,
+			fields:   map[string]interface{}{"organizationName": "PDA", "startDate": "2024-01-01", "roles": []string{"member"}},
+			check:    "organizationName",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typeName, func(t *testing.T) {
+			token, err := IssueTyped(tt.typeName, tt.fields, "did:key:zIssuer", subjectDID, issuerPriv, IssueOptions{CredentialID: "urn:uuid:typed"})
+			if err != nil {
+				t.Fatalf("IssueTyped failed: %v", err)
+			}
+
+			claims, err := VerifyVC(token, issuerPub)
+			if err != nil {
+				t.Fatalf("VerifyVC failed: %v", err)
+			}
+
+			if claims.VC.Type[1] != tt.typeName {
+				t.Errorf("Expected type %s, got %s", tt.typeName, claims.VC.Type[1])
+			}
+
+			subject := claims.VC.CredentialSubject.(map[string]interface{})
+			if subject["id"] != subjectDID {
+				t.Errorf("Expected subject id %s, got %v", subjectDID, subject["id"])
+			}
+			if subject[tt.check] != tt.fields[tt.check] {
+				t.Errorf("Expected %s = %v, got %v", tt.check, tt.fields[tt.check], subject[tt.check])
+			}
+
+			if claims.GetCredentialID() != "urn:uuid:typed" {
+				t.Errorf("Expected credential ID urn:uuid:typed, got %s", claims.GetCredentialID())
+			}
+		})
+	}
+}
+
+func TestIssueTypedValidationFailure(t *testing.T) {
+	_, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	// Missing a required field
+	_, err := IssueTyped(CredentialTypeIdentity, map[string]interface{}{"givenName": "Alice"}, "did:key:zIssuer", "did:key:zSubject", issuerPriv, IssueOptions{})
+	if !errors.Is(err, ErrMissingRequiredField) {
+		t.Errorf("Expected ErrMissingRequiredField, got %v", err)
+	}
+
+	// Field not defined on the type
+	_, err = IssueTyped(CredentialTypeEducation, map[string]interface{}{"institutionName": "U", "favoriteColor": "blue"}, "did:key:zIssuer", "did:key:zSubject", issuerPriv, IssueOptions{})
+	if !errors.Is(err, ErrInvalidSubjectFields) {
+		t.Errorf("Expected ErrInvalidSubjectFields for unknown field, got %v", err)
+	}
+
+	// Wrong field type
+	_, err = IssueTyped(CredentialTypeEducation, map[string]interface{}{"institutionName": "U", "creditsEarned": "many"}, "did:key:zIssuer", "did:key:zSubject", issuerPriv, IssueOptions{})
+	if !errors.Is(err, ErrInvalidSubjectFields) {
+		t.Errorf("Expected ErrInvalidSubjectFields for wrong field type, got %v", err)
+	}
+
+	// Unregistered type
+	_, err = IssueTyped("NoSuchCredential", map[string]interface{}{}, "did:key:zIssuer", "did:key:zSubject", issuerPriv, IssueOptions{})
+	if !errors.Is(err, ErrUnknownCredentialType) {
+		t.Errorf("Expected ErrUnknownCredentialType, got %v", err)
+	}
+}
+
+type loyaltySubject struct {
+	ID     string `json:"id"`
+	Points int    `json:"points"`
+}
+
+func (s loyaltySubject) GetID() string          { return s.ID }
+func (s loyaltySubject) CredentialType() string { return "LoyaltyCredential" }
+
+func TestRegisterSubjectType(t *testing.T) {
+	_, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	if err := RegisterSubjectType("LoyaltyCredential", func() CredentialSubject { return &loyaltySubject{} }); err != nil {
+		t.Fatalf("RegisterSubjectType failed: %v", err)
+	}
+
+	if err := RegisterSubjectType(CredentialTypeIdentity, func() CredentialSubject { return &loyaltySubject{} }); err != ErrTypeAlreadyRegistered {
+		t.Errorf("Expected ErrTypeAlreadyRegistered, got %v", err)
+	}
+
+	if _, err := IssueTyped("LoyaltyCredential", map[string]interface{}{"points": 250}, "did:key:zIssuer", "did:key:zSubject", issuerPriv, IssueOptions{}); err != nil {
+		t.Errorf("IssueTyped with registered custom type failed: %v", err)
+	}
+}
+
+type parkingPermitSubject struct {
+	ID   string `json:"id"`
+	Zone string `json:"zone"`
+}
+
+func (s parkingPermitSubject) GetID() string          { return s.ID }
+func (s parkingPermitSubject) CredentialType() string { return "ParkingPermitCredential" }
+
+func TestVerifyVCUnknownTypeIsUntyped(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	token, err := IssueVC("did:key:zIssuer", "did:key:zSubject", issuerPriv, parkingPermitSubject{ID: "did:key:zSubject", Zone: "B"})
+	if err != nil {
+		t.Fatalf("IssueVC failed: %v", err)
+	}
+
+	claims, err := VerifyVC(token, issuerPub)
+	if err != nil {
+		t.Fatalf("VerifyVC failed for unknown type: %v", err)
+	}
+
+	if claims.CredentialType() != "ParkingPermitCredential" {
+		t.Errorf("Expected type ParkingPermitCredential, got %s", claims.CredentialType())
+	}
+	if claims.IsKnownType() {
+		t.Error("Expected ParkingPermitCredential to be an unknown type")
+	}
+
+	fields, err := claims.SubjectMap()
+	if err != nil {
+		t.Fatalf("SubjectMap failed: %v", err)
+	}
+	if fields["zone"] != "B" {
+		t.Errorf("Expected zone B, got %v", fields["zone"])
+	}
+
+	if _, err := claims.TypedSubject(); !errors.Is(err, ErrUnknownCredentialType) {
+		t.Errorf("Expected ErrUnknownCredentialType, got %v", err)
+	}
+}
+
+func TestVerifyVCKnownTypeTypedSubject(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	token, _ := IssueVC("did:key:zIssuer", "did:key:zSubject", issuerPriv, testIdentitySubject("did:key:zSubject"))
+	claims, err := VerifyVC(token, issuerPub)
+	if err != nil {
+		t.Fatalf("VerifyVC failed: %v", err)
+	}
+
+	if !claims.IsKnownType() {
+		t.Error("Expected IdentityCredential to be a known type")
+	}
+
+	subject, err := claims.TypedSubject()
+	if err != nil {
+		t.Fatalf("TypedSubject failed: %v", err)
+	}
+	identity, ok := subject.(*IdentitySubject)
+	if !ok {
+		t.Fatalf("Expected *IdentitySubject, got %T", subject)
+	}
+	if identity.GivenName != "Alice" {
+		t.Errorf("Expected given name Alice, got %s", identity.GivenName)
+	}
+}
+
+func TestDecodeSubject(t *testing.T) {
+	issuerPub, issuerPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	token, _ := IssueVC("did:key:zIssuer", "did:key:zSubject", issuerPriv, testIdentitySubject("did:key:zSubject"))
+	claims, err := VerifyVC(token, issuerPub)
+	if err != nil {
+		t.Fatalf("VerifyVC failed: %v", err)
+	}
+
+	identity, err := DecodeSubject[IdentitySubject](claims)
+	if err != nil {
+		t.Fatalf("DecodeSubject failed: %v", err)
+	}
+	if identity.ID != "did:key:zSubject" || identity.GivenName != "Alice" {
+		t.Errorf("Expected Alice's subject, got %+v", identity)
+	}
+
+	var into IdentitySubject
+	if err := claims.DecodeSubjectInto(&into); err != nil {
+		t.Fatalf("DecodeSubjectInto failed: %v", err)
+	}
+	if into != identity {
+		t.Errorf("Expected DecodeSubjectInto to match DecodeSubject, got %+v", into)
+	}
+
+	// The subject type must match the credential type
+	if _, err := DecodeSubject[EducationSubject](claims); !errors.Is(err, ErrSubjectTypeMismatch) {
+		t.Errorf("Expected ErrSubjectTypeMismatch, got %v", err)
+	}
+	if err := claims.DecodeSubjectInto(&EmploymentSubject{}); !errors.Is(err, ErrSubjectTypeMismatch) {
+		t.Errorf("Expected ErrSubjectTypeMismatch, got %v", err)
+	}
+
+	if _, err := DecodeSubject[*IdentitySubject](claims); err != ErrInvalidSubjectTarget {
+		t.Errorf("Expected ErrInvalidSubjectTarget for a pointer type, got %v", err)
+	}
+	if err := claims.DecodeSubjectInto(identity); err != ErrInvalidSubjectTarget {
+		t.Errorf("Expected ErrInvalidSubjectTarget for a non-pointer, got %v", err)
+	}
+	var nilSubject *IdentitySubject
+	if err := claims.DecodeSubjectInto(nilSubject); err != ErrInvalidSubjectTarget {
+		t.Errorf("Expected ErrInvalidSubjectTarget for a nil pointer, got %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+// Package vctest provides credential fixtures for tests outside package vc
+package vctest
+
+import "github.com/veriglob/veriglob-core/internal/vc"
+
+// IdentitySubject returns an identity subject for id with every required
+// field set, so it passes validation at issuance
+func IdentitySubject(id string) vc.IdentitySubject {
+	return vc.IdentitySubject{ID: id, GivenName: "Alice", FamilyName: "Doe", DateOfBirth: "1990-01-01"}
+}
@@ -0,0 +1,241 @@
+package veriglob
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/presentation"
+	"github.com/veriglob/veriglob-core/internal/resolver"
+	"github.com/veriglob/veriglob-core/internal/revocation"
+	"github.com/veriglob/veriglob-core/internal/vc"
+)
+
+var (
+	ErrInvalidCredentialFile   = errors.New("invalid credential file")
+	ErrInvalidPresentationFile = errors.New("invalid presentation file")
+)
+
+// PartyKey names an issuer or holder in a credential or presentation file
+// by DID and, optionally, a hex-encoded Ed25519 public key
+type PartyKey struct {
+	DID       string `json:"did"`
+	PublicKey string `json:"publicKey,omitempty"`
+}
+
+// CredentialFile is the credential JSON the issuer CLI writes
+type CredentialFile struct {
+	CredentialID string   `json:"credentialId,omitempty"`
+	Issuer       PartyKey `json:"issuer"`
+	Token        string   `json:"token"`
+}
+
+// PresentationFile is the presentation JSON the holder CLI writes
+type PresentationFile struct {
+	Holder       PartyKey `json:"holder"`
+	Audience     string   `json:"audience"`
+	Nonce        string   `json:"nonce"`
+	Presentation string   `json:"presentation"`
+}
+
+// CredentialFileOptions configures VerifyCredentialFile
+type CredentialFileOptions struct {
+	// Resolver resolves issuer DIDs; nil uses the default resolver
+	Resolver *Resolver
+	// Status, when set, is consulted for the credential's revocation status
+	Status StatusChecker
+	// VerificationMethod pins verification to one verification method ID
+	// (e.g. did:key:z...#key-1)
+	VerificationMethod string
+	// TrustedIssuers, when set, rejects credentials from issuers it does not
+	// trust for the credential's types with ErrUntrustedIssuer
+	TrustedIssuers *TrustList
+	// Leeway is the clock skew tolerated on the credential's expiry and nbf;
+	// zero uses DefaultLeeway and a negative value none
+	Leeway time.Duration
+}
+
+// PresentationFileOptions configures VerifyPresentationFile
+type PresentationFileOptions struct {
+	// ExpectedAudience and ExpectedNonce default to the file's values, which
+	// only shows the presentation matches what its holder wrote. A service
+	// should pass the audience and nonce it issued.
+	ExpectedAudience string
+	ExpectedNonce    string
+	// Credentials configures the embedded credential checks; its Resolver
+	// also resolves the holder DID
+	Credentials CredentialCheckOptions
+}
+
+// ParseCredentialFile decodes the credential JSON the issuer CLI writes. Data
+// that is not a JSON object is taken as a bare credential token.
+func ParseCredentialFile(data []byte) (*CredentialFile, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '{' {
+		return &CredentialFile{Token: string(data)}, nil
+	}
+
+	var file CredentialFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentialFile, err)
+	}
+	if file.Token == "" {
+		return nil, fmt.Errorf("%w: no token", ErrInvalidCredentialFile)
+	}
+	return &file, nil
+}
+
+// ParsePresentationFile decodes the presentation JSON the holder CLI writes
+func ParsePresentationFile(data []byte) (*PresentationFile, error) {
+	var file PresentationFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPresentationFile, err)
+	}
+	if file.Presentation == "" {
+		return nil, fmt.Errorf("%w: no presentation", ErrInvalidPresentationFile)
+	}
+	return &file, nil
+}
+
+// VerifyCredentialFile verifies a credential file the way the verifier CLI
+// does. The issuer key is resolved from the file's issuer DID, falling back
+// to its hex public key; a file naming neither is checked against the key
+// the credential's own issuer DID resolves to, or opts.VerificationMethod.
+// A file naming an issuer DID other than the credential's iss returns
+// ErrIssuerKeyMismatch. A hex key is only as trustworthy as the file it came
+// from and ties the credential to no DID: CredentialInfo.IssuerResolved is
+// false and IssuerDID is left empty, so the claimed issuer is only in
+// Claims.Issuer. When opts.Status is set, a revoked or suspended credential
+// returns its info together with ErrCredentialNotActive.
+func VerifyCredentialFile(data []byte, opts CredentialFileOptions) (*CredentialInfo, error) {
+	file, err := ParseCredentialFile(data)
+	if err != nil {
+		return nil, err
+	}
+	res := opts.Resolver
+	if res == nil {
+		res = resolver.NewResolver()
+	}
+
+	key, resolved, err := file.Issuer.key(res)
+	if err != nil {
+		return nil, fmt.Errorf("issuer key: %w", err)
+	}
+	if key == nil {
+		// Without key material the key comes from a DID either way: the
+		// pinned verification method, or the issuer the credential names
+		if opts.VerificationMethod == "" {
+			issuer, err := vc.UnverifiedIssuer(file.Token)
+			if err != nil {
+				return nil, err
+			}
+			if key, err = res.Resolve(issuer); err != nil {
+				return nil, fmt.Errorf("issuer key: %w", err)
+			}
+		}
+		resolved = true
+	}
+
+	claims, err := vc.VerifyVCWithOptions(file.Token, key, vc.VerifyOptions{
+		ExpectedVerificationMethod: opts.VerificationMethod,
+		Resolver:                   res,
+		TrustedIssuers:             opts.TrustedIssuers,
+		Leeway:                     opts.Leeway,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The key the file's DID resolves to only vouches for that DID
+	if file.Issuer.DID != "" && file.Issuer.DID != claims.Issuer {
+		return nil, fmt.Errorf("%w: file names %s, credential names %s", ErrIssuerKeyMismatch, file.Issuer.DID, claims.Issuer)
+	}
+
+	info := &CredentialInfo{
+		ID:             claims.GetCredentialID(),
+		Type:           claims.CredentialType(),
+		SubjectDID:     claims.Subject,
+		IssuedAt:       claims.IssuedAt,
+		ExpiresAt:      claims.ExpiresAt,
+		Claims:         claims,
+		IssuerResolved: resolved,
+	}
+	if resolved {
+		info.IssuerDID = claims.Issuer
+	}
+	if info.ID == "" || opts.Status == nil {
+		return info, nil
+	}
+
+	entry, err := opts.Status.CheckStatus(info.ID)
+	switch {
+	case errors.Is(err, revocation.ErrCredentialNotFound):
+		return info, nil
+	case err != nil:
+		return info, err
+	}
+	info.Status = string(entry.Status)
+	info.RevocationReason = entry.Reason
+	if entry.Status == revocation.StatusRevoked || entry.Status == revocation.StatusSuspended {
+		return info, presentation.ErrCredentialNotActive
+	}
+	return info, nil
+}
+
+// VerifyPresentationFile verifies a presentation file and each embedded
+// credential the way the verifier CLI does. The holder key is resolved from
+// the file's holder DID, falling back to its hex public key.
+func VerifyPresentationFile(data []byte, opts PresentationFileOptions) (*VPClaims, CredentialResults, error) {
+	file, err := ParsePresentationFile(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	res := opts.Credentials.Resolver
+	if res == nil {
+		res = resolver.NewResolver()
+	}
+
+	holderKey, _, err := file.Holder.key(res)
+	if err != nil {
+		return nil, nil, fmt.Errorf("holder key: %w", err)
+	}
+	if holderKey == nil {
+		return nil, nil, fmt.Errorf("%w: no holder DID or public key", ErrInvalidPresentationFile)
+	}
+
+	audience := opts.ExpectedAudience
+	if audience == "" {
+		audience = file.Audience
+	}
+	nonce := opts.ExpectedNonce
+	if nonce == "" {
+		nonce = file.Nonce
+	}
+	return presentation.VerifyPresentationWithCredentials(file.Presentation, holderKey, audience, nonce, opts.Credentials)
+}
+
+// key returns the party's key: the one its DID resolves to, or else its hex
+// public key. It reports whether the key was resolved, and returns the
+// resolution error when the DID fails to resolve and there is no hex key.
+func (p PartyKey) key(res *Resolver) (ed25519.PublicKey, bool, error) {
+	var resolveErr error
+	if p.DID != "" {
+		key, err := res.Resolve(p.DID)
+		if err == nil {
+			return key, true, nil
+		}
+		resolveErr = err
+	}
+
+	if p.PublicKey != "" {
+		raw, err := hex.DecodeString(p.PublicKey)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, false, fmt.Errorf("invalid public key %q", p.PublicKey)
+		}
+		return ed25519.PublicKey(raw), false, nil
+	}
+	return nil, false, resolveErr
+}
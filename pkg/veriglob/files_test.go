@@ -0,0 +1,150 @@
+package veriglob
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestVerifyCredentialFile(t *testing.T) {
+	h := NewTestHarness()
+	token, err := h.IssueIdentity(IdentitySubject{GivenName: "Alice", FamilyName: "Doe", DateOfBirth: "1990-01-01"})
+	if err != nil {
+		t.Fatalf("IssueIdentity failed: %v", err)
+	}
+
+	withDID, _ := json.Marshal(CredentialFile{Issuer: PartyKey{DID: h.Issuer.DID}, Token: token})
+	info, err := VerifyCredentialFile(withDID, CredentialFileOptions{Status: h.Registry})
+	if err != nil {
+		t.Fatalf("VerifyCredentialFile failed: %v", err)
+	}
+	if info.IssuerDID != h.Issuer.DID || info.SubjectDID != h.Holder.DID || info.Type != CredentialTypeIdentity {
+		t.Errorf("Unexpected credential info %+v", info)
+	}
+	if !info.IssuerResolved || info.Claims == nil || info.ID == "" {
+		t.Errorf("Expected a resolved issuer, claims and an ID, got %+v", info)
+	}
+	if info.Status != "active" {
+		t.Errorf("Expected status active, got %q", info.Status)
+	}
+
+	// A hex key is used as given, and a bare token resolves its own issuer
+	withKey, _ := json.Marshal(CredentialFile{Issuer: PartyKey{PublicKey: hex.EncodeToString(h.Issuer.PublicKey)}, Token: token})
+	if info, err := VerifyCredentialFile(withKey, CredentialFileOptions{}); err != nil || info.IssuerResolved || info.IssuerDID != "" {
+		t.Errorf("Expected a hex key to verify unresolved and without an issuer DID, got %v", err)
+	}
+	if info, err := VerifyCredentialFile([]byte(token+"\n"), CredentialFileOptions{}); err != nil || !info.IssuerResolved {
+		t.Errorf("Expected a bare token to verify against its issuer, got %v", err)
+	}
+
+	// Another issuer's key fails the signature check
+	wrongKey, _ := json.Marshal(CredentialFile{Issuer: PartyKey{PublicKey: hex.EncodeToString(h.Holder.PublicKey)}, Token: token})
+	if _, err := VerifyCredentialFile(wrongKey, CredentialFileOptions{}); !errors.Is(err, ErrCredentialSignatureInvalid) {
+		t.Errorf("Expected ErrCredentialSignatureInvalid, got %v", err)
+	}
+
+	if err := h.Registry.Revoke(info.ID, "compromised"); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	info, err = VerifyCredentialFile(withDID, CredentialFileOptions{Status: h.Registry})
+	if !errors.Is(err, ErrCredentialNotActive) {
+		t.Fatalf("Expected ErrCredentialNotActive, got %v", err)
+	}
+	if info == nil || info.Status != "revoked" || info.RevocationReason != "compromised" {
+		t.Errorf("Expected the revoked credential's info, got %+v", info)
+	}
+}
+
+func TestVerifyCredentialFileForgedTrustedIssuer(t *testing.T) {
+	h := NewTestHarness()
+	trust := NewTrustList()
+	trust.Trust(h.Issuer.DID)
+
+	// The attacker's token names the trusted issuer but is signed with the
+	// attacker's key, which the file supplies
+	attackerPub, attackerPriv, _ := GenerateEd25519Keypair()
+	forged, err := IssueVC(h.Issuer.DID, h.Holder.DID, attackerPriv, IdentitySubject{
+		ID: h.Holder.DID, GivenName: "Mallory", FamilyName: "Doe", DateOfBirth: "1990-01-01",
+	})
+	if err != nil {
+		t.Fatalf("IssueVC failed: %v", err)
+	}
+	file, _ := json.Marshal(CredentialFile{Issuer: PartyKey{PublicKey: hex.EncodeToString(attackerPub)}, Token: forged})
+	if _, err := VerifyCredentialFile(file, CredentialFileOptions{TrustedIssuers: trust}); !errors.Is(err, ErrIssuerKeyMismatch) {
+		t.Errorf("Expected ErrIssuerKeyMismatch, got %v", err)
+	}
+}
+
+func TestVerifyCredentialFileIssuerMismatch(t *testing.T) {
+	h := NewTestHarness()
+
+	// The attacker signs with their own did:key but names another issuer
+	attackerPub, attackerPriv, _ := GenerateEd25519Keypair()
+	attacker, _ := CreateDIDKey(attackerPub)
+	forged, err := IssueVC(h.Issuer.DID, h.Holder.DID, attackerPriv, IdentitySubject{
+		ID: h.Holder.DID, GivenName: "Mallory", FamilyName: "Doe", DateOfBirth: "1990-01-01",
+	})
+	if err != nil {
+		t.Fatalf("IssueVC failed: %v", err)
+	}
+	file, _ := json.Marshal(CredentialFile{Issuer: PartyKey{DID: attacker.DID}, Token: forged})
+	if _, err := VerifyCredentialFile(file, CredentialFileOptions{}); !errors.Is(err, ErrIssuerKeyMismatch) {
+		t.Errorf("Expected ErrIssuerKeyMismatch, got %v", err)
+	}
+}
+
+func TestVerifyCredentialFileInvalid(t *testing.T) {
+	for _, data := range []string{"", "{}", `{"token": 1}`} {
+		if _, err := VerifyCredentialFile([]byte(data), CredentialFileOptions{}); !errors.Is(err, ErrInvalidCredentialFile) {
+			t.Errorf("Expected ErrInvalidCredentialFile for %q, got %v", data, err)
+		}
+	}
+
+	badKey, _ := json.Marshal(CredentialFile{Issuer: PartyKey{PublicKey: "zz"}, Token: "v4.public.x"})
+	if _, err := VerifyCredentialFile(badKey, CredentialFileOptions{}); err == nil {
+		t.Error("Expected an invalid hex key to fail")
+	}
+	unresolvable, _ := json.Marshal(CredentialFile{Issuer: PartyKey{DID: "did:web:issuer.example"}, Token: "v4.public.x"})
+	if _, err := VerifyCredentialFile(unresolvable, CredentialFileOptions{}); !errors.Is(err, ErrUnsupportedMethod) {
+		t.Errorf("Expected the resolution error, got %v", err)
+	}
+}
+
+func TestVerifyPresentationFile(t *testing.T) {
+	h := NewTestHarness()
+	token, _ := h.IssueIdentity(IdentitySubject{GivenName: "Alice", FamilyName: "Doe", DateOfBirth: "1990-01-01"})
+	vp, nonce, err := h.Present(token)
+	if err != nil {
+		t.Fatalf("Present failed: %v", err)
+	}
+
+	data, _ := json.Marshal(PresentationFile{
+		Holder:       PartyKey{DID: h.Holder.DID},
+		Audience:     h.Verifier.DID,
+		Nonce:        nonce,
+		Presentation: vp,
+	})
+	claims, results, err := VerifyPresentationFile(data, PresentationFileOptions{
+		Credentials: CredentialCheckOptions{Status: h.Registry, RequireHolderBinding: true},
+	})
+	if err != nil {
+		t.Fatalf("VerifyPresentationFile failed: %v", err)
+	}
+	if claims.VP.Holder != h.Holder.DID || !results.AllValid() || len(results) != 1 {
+		t.Errorf("Expected one valid credential from %s, got %v", h.Holder.DID, results)
+	}
+
+	// The verifier's own expectations override the file
+	if _, _, err := VerifyPresentationFile(data, PresentationFileOptions{ExpectedNonce: "other-nonce"}); !errors.Is(err, ErrNonceMismatch) {
+		t.Errorf("Expected ErrNonceMismatch, got %v", err)
+	}
+
+	noHolder, _ := json.Marshal(PresentationFile{Presentation: vp})
+	if _, _, err := VerifyPresentationFile(noHolder, PresentationFileOptions{}); !errors.Is(err, ErrInvalidPresentationFile) {
+		t.Errorf("Expected ErrInvalidPresentationFile without a holder key, got %v", err)
+	}
+	if _, _, err := VerifyPresentationFile([]byte("{}"), PresentationFileOptions{}); !errors.Is(err, ErrInvalidPresentationFile) {
+		t.Errorf("Expected ErrInvalidPresentationFile, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package veriglob
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHarnessIssuePresentVerify(t *testing.T) {
+	h := NewTestHarness()
+
+	cred, err := h.IssueIdentity(IdentitySubject{GivenName: "Alice", FamilyName: "Doe", DateOfBirth: "1990-01-01"})
+	if err != nil {
+		t.Fatalf("IssueIdentity failed: %v", err)
+	}
+
+	vp, nonce, err := h.Present(cred)
+	if err != nil {
+		t.Fatalf("Present failed: %v", err)
+	}
+
+	claims, err := h.Verify(vp, nonce)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	if claims.VP.Holder != h.Holder.DID {
+		t.Errorf("Expected holder %s, got %s", h.Holder.DID, claims.VP.Holder)
+	}
+}
+
+func TestHarnessVerifyRejectsRevoked(t *testing.T) {
+	h := NewTestHarness()
+
+	cred, _ := h.IssueIdentity(IdentitySubject{GivenName: "Bob", FamilyName: "Doe", DateOfBirth: "1990-01-01"})
+	claims, _ := VerifyVC(cred, h.Issuer.PublicKey)
+	h.Registry.Revoke(claims.GetCredentialID(), "test")
+
+	vp, nonce, _ := h.Present(cred)
+	if _, err := h.Verify(vp, nonce); !errors.Is(err, ErrCredentialRevoked) {
+		t.Errorf("Expected ErrCredentialRevoked, got %v", err)
+	}
+}
+
+func TestHarnessVerifyRejectsWrongNonce(t *testing.T) {
+	h := NewTestHarness()
+
+	cred, _ := h.IssueIdentity(IdentitySubject{GivenName: "Carol", FamilyName: "Doe", DateOfBirth: "1990-01-01"})
+	vp, _, _ := h.Present(cred)
+
+	if _, err := h.Verify(vp, "some-other-nonce"); err == nil {
+		t.Error("Expected error for wrong nonce")
+	}
+}
@@ -0,0 +1,132 @@
+package veriglob
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"time"
+
+	"github.com/veriglob/veriglob-core/internal/presentation"
+	"github.com/veriglob/veriglob-core/internal/resolver"
+)
+
+// PresentationVerifyOptions configures VerifyPresentationFull
+type PresentationVerifyOptions struct {
+	// ExpectedAudience, ExpectedNonce and ExpectedDomain are compared with the
+	// presentation's claims; an empty value matches anything
+	ExpectedAudience string
+	ExpectedNonce    string
+	ExpectedDomain   string
+	// Credentials configures the embedded credential checks. Its Resolver and
+	// Status are replaced by the arguments of VerifyPresentationFull.
+	Credentials CredentialCheckOptions
+}
+
+// PresentationVerificationResult is the full report on a presentation: the
+// holder proof, each claim check, and the outcome of every embedded credential
+type PresentationVerificationResult struct {
+	// HolderValid reports whether the presentation was signed by the holder key
+	// (and, with RequireProofPurpose, the key authenticates the holder DID)
+	HolderValid   bool
+	Holder        string
+	AudienceMatch bool
+	NonceMatch    bool
+	DomainMatch   bool
+	Expired       bool
+	ExpiresAt     time.Time
+	// Claims is nil when the presentation could not be verified
+	Claims      *VPClaims
+	Credentials CredentialResults
+	// Err is the first reason the presentation itself is not valid
+	Err error
+}
+
+// Valid reports whether the presentation and every embedded credential verified
+func (r *PresentationVerificationResult) Valid() bool {
+	return r.HolderValid && r.AudienceMatch && r.NonceMatch && r.DomainMatch &&
+		!r.Expired && r.Credentials.AllValid()
+}
+
+// VerifyPresentationFull verifies the holder proof of a presentation and each
+// embedded credential, and reports every outcome rather than stopping at the
+// first failure. Issuer keys are resolved with r, or the default resolver when
+// r is nil, and each credential's status is looked up in registry when it is
+// not nil. Status proofs attached to the presentation are checked against
+// each issuer key, subject to opts.Credentials.StatusProofMaxAge, so an
+// offline verifier can pass a nil registry. A mismatched audience, nonce or
+// domain is reported in the result, as are a bad signature and expiry;
+// embedded credentials are verified only when the holder proof is, and each
+// expired one is flagged in its result. With
+// opts.Credentials.RejectExpiredCredentials, an expired credential also sets
+// Err to presentation.ErrExpiredCredential. An error is returned only when
+// the token is not a presentation at all.
+func VerifyPresentationFull(
+	tokenString string,
+	holderPublicKey ed25519.PublicKey,
+	r *Resolver,
+	registry StatusChecker,
+	opts PresentationVerifyOptions,
+) (*PresentationVerificationResult, error) {
+	result := &PresentationVerificationResult{}
+
+	claims, err := presentation.VerifyPresentation(tokenString, holderPublicKey, "", "")
+	switch {
+	case err == nil:
+	case errors.Is(err, presentation.ErrPresentationExpired):
+		// Expiry is only checked once the signature has verified
+		result.HolderValid = true
+		result.Expired = true
+		result.Err = err
+		return result, nil
+	case errors.Is(err, presentation.ErrSignatureInvalid):
+		result.Err = err
+		return result, nil
+	default:
+		return nil, err
+	}
+
+	result.Claims = claims
+	result.Holder = claims.VP.Holder
+	result.ExpiresAt = claims.ExpiresAt
+	result.HolderValid = true
+	result.AudienceMatch = opts.ExpectedAudience == "" || claims.Audience == opts.ExpectedAudience
+	result.NonceMatch = opts.ExpectedNonce == "" || claims.Nonce == opts.ExpectedNonce
+	result.DomainMatch = opts.ExpectedDomain == "" || claims.Domain == opts.ExpectedDomain
+
+	checks := opts.Credentials
+	checks.Resolver = r
+	checks.Status = registry
+
+	if checks.RequireHolderBinding {
+		if err := presentation.CheckHolderKey(claims.VP.Holder, holderPublicKey, r); err != nil {
+			result.HolderValid = false
+			result.Err = err
+			return result, nil
+		}
+	}
+	if checks.RequireProofPurpose {
+		proofResolver := r
+		if proofResolver == nil {
+			proofResolver = resolver.NewResolver()
+		}
+		if err := proofResolver.VerifyProofPurpose(claims.Issuer, holderPublicKey, resolver.ProofPurposeAuthentication); err != nil {
+			result.HolderValid = false
+			result.Err = err
+			return result, nil
+		}
+	}
+
+	switch {
+	case !result.AudienceMatch:
+		result.Err = presentation.ErrAudienceMismatch
+	case !result.NonceMatch:
+		result.Err = presentation.ErrNonceMismatch
+	case !result.DomainMatch:
+		result.Err = presentation.ErrDomainMismatch
+	}
+
+	result.Credentials = presentation.VerifyEmbeddedCredentials(claims, holderPublicKey, checks)
+	if checks.RejectExpiredCredentials && result.Err == nil {
+		result.Err = result.Credentials.ExpiredError()
+	}
+	return result, nil
+}
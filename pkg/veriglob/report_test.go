@@ -0,0 +1,189 @@
+package veriglob
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifyPresentationFull(t *testing.T) {
+	h := NewTestHarness()
+
+	good, err := h.IssueIdentity(IdentitySubject{GivenName: "Alice", FamilyName: "Doe", DateOfBirth: "1990-01-01"})
+	if err != nil {
+		t.Fatalf("IssueIdentity failed: %v", err)
+	}
+	revoked, err := h.IssueIdentity(IdentitySubject{GivenName: "Alice", FamilyName: "Doe", DateOfBirth: "1990-01-01"})
+	if err != nil {
+		t.Fatalf("IssueIdentity failed: %v", err)
+	}
+	claims, err := UnverifiedClaims(revoked)
+	if err != nil {
+		t.Fatalf("UnverifiedClaims failed: %v", err)
+	}
+	if err := h.Registry.Revoke(claims.GetCredentialID(), "test"); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+
+	vp, nonce, err := h.Present(good, revoked)
+	if err != nil {
+		t.Fatalf("Present failed: %v", err)
+	}
+
+	result, err := VerifyPresentationFull(vp, h.Holder.PublicKey, nil, h.Registry, PresentationVerifyOptions{
+		ExpectedAudience: h.Verifier.DID,
+		ExpectedNonce:    nonce,
+	})
+	if err != nil {
+		t.Fatalf("VerifyPresentationFull failed: %v", err)
+	}
+
+	if !result.HolderValid || !result.AudienceMatch || !result.NonceMatch || result.Expired {
+		t.Errorf("Expected a valid holder proof, got %+v", result)
+	}
+	if result.Holder != h.Holder.DID {
+		t.Errorf("Expected holder %s, got %s", h.Holder.DID, result.Holder)
+	}
+	if len(result.Credentials) != 2 {
+		t.Fatalf("Expected 2 credential results, got %d", len(result.Credentials))
+	}
+	if !result.Credentials[0].Valid {
+		t.Errorf("Expected first credential to be valid, got %v", result.Credentials[0].Err)
+	}
+	if !result.Credentials[1].Revoked() {
+		t.Errorf("Expected second credential to be revoked, got status %q", result.Credentials[1].Status)
+	}
+	if result.Valid() {
+		t.Error("Expected presentation with a revoked credential not to be valid")
+	}
+}
+
+func TestVerifyPresentationFullReportsMismatches(t *testing.T) {
+	h := NewTestHarness()
+
+	cred, err := h.IssueIdentity(IdentitySubject{GivenName: "Alice", FamilyName: "Doe", DateOfBirth: "1990-01-01"})
+	if err != nil {
+		t.Fatalf("IssueIdentity failed: %v", err)
+	}
+	vp, _, err := h.Present(cred)
+	if err != nil {
+		t.Fatalf("Present failed: %v", err)
+	}
+
+	result, err := VerifyPresentationFull(vp, h.Holder.PublicKey, nil, nil, PresentationVerifyOptions{
+		ExpectedAudience: h.Verifier.DID,
+		ExpectedNonce:    "other-nonce",
+	})
+	if err != nil {
+		t.Fatalf("VerifyPresentationFull failed: %v", err)
+	}
+	if !result.AudienceMatch || result.NonceMatch {
+		t.Errorf("Expected only the nonce to mismatch, got %+v", result)
+	}
+	if !errors.Is(result.Err, ErrNonceMismatch) {
+		t.Errorf("Expected ErrNonceMismatch, got %v", result.Err)
+	}
+	if !result.Credentials.AllValid() {
+		t.Error("Expected the credential to verify despite the nonce mismatch")
+	}
+	if result.Valid() {
+		t.Error("Expected presentation with a mismatched nonce not to be valid")
+	}
+
+	// A key other than the holder's fails the holder proof
+	result, err = VerifyPresentationFull(vp, h.Verifier.PublicKey, nil, nil, PresentationVerifyOptions{})
+	if err != nil {
+		t.Fatalf("VerifyPresentationFull failed: %v", err)
+	}
+	if result.HolderValid {
+		t.Error("Expected holder proof to fail with the wrong key")
+	}
+	if !errors.Is(result.Err, ErrPresentationSignatureInvalid) {
+		t.Errorf("Expected ErrPresentationSignatureInvalid, got %v", result.Err)
+	}
+	if result.Credentials != nil {
+		t.Errorf("Expected no credential results, got %d", len(result.Credentials))
+	}
+}
+
+func TestVerifyPresentationFullOffline(t *testing.T) {
+	h := NewTestHarness()
+
+	cred, err := h.IssueIdentity(IdentitySubject{GivenName: "Alice", FamilyName: "Doe", DateOfBirth: "1990-01-01"})
+	if err != nil {
+		t.Fatalf("IssueIdentity failed: %v", err)
+	}
+	claims, _ := UnverifiedClaims(cred)
+	if err := h.Registry.Revoke(claims.GetCredentialID(), "test"); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	proof, err := h.Registry.StatusProof(claims.GetCredentialID(), h.Issuer.PrivateKey)
+	if err != nil {
+		t.Fatalf("StatusProof failed: %v", err)
+	}
+
+	vp, err := CreatePresentationWithOptions(h.Holder.DID, h.Holder.PrivateKey, []string{cred}, h.Verifier.DID, "nonce", CreateOptions{
+		StatusProofs: []*StatusProof{proof},
+	})
+	if err != nil {
+		t.Fatalf("CreatePresentationWithOptions failed: %v", err)
+	}
+
+	// No registry: the attached proof reports the revocation
+	result, err := VerifyPresentationFull(vp, h.Holder.PublicKey, nil, nil, PresentationVerifyOptions{
+		Credentials: CredentialCheckOptions{RequireStatusProof: true},
+	})
+	if err != nil {
+		t.Fatalf("VerifyPresentationFull failed: %v", err)
+	}
+	if !result.Credentials[0].Revoked() || !result.Credentials[0].StatusFromProof {
+		t.Errorf("Expected the credential revoked by its status proof, got %+v", result.Credentials[0])
+	}
+}
+
+func TestVerifyPresentationFullExpiredCredential(t *testing.T) {
+	h := NewTestHarness()
+
+	good, err := h.IssueIdentity(IdentitySubject{GivenName: "Alice", FamilyName: "Doe", DateOfBirth: "1990-01-01"})
+	if err != nil {
+		t.Fatalf("IssueIdentity failed: %v", err)
+	}
+	// Issuance refuses an expiry in the past, so sign the lapsed credential directly
+	expired, err := NewPasetoV4Suite(h.Issuer.PrivateKey, nil).Sign(&TokenClaims{
+		Issuer:    h.Issuer.DID,
+		Subject:   h.Holder.DID,
+		IssuedAt:  time.Now().Add(-60 * 24 * time.Hour),
+		ExpiresAt: time.Now().Add(-30 * 24 * time.Hour),
+		Custom:    map[string]json.RawMessage{"vc": json.RawMessage(`{"type":["VerifiableCredential","IdentityCredential"]}`)},
+	})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	vp, nonce, err := h.Present(good, expired)
+	if err != nil {
+		t.Fatalf("Present failed: %v", err)
+	}
+	opts := PresentationVerifyOptions{ExpectedAudience: h.Verifier.DID, ExpectedNonce: nonce}
+
+	result, err := VerifyPresentationFull(vp, h.Holder.PublicKey, nil, h.Registry, opts)
+	if err != nil {
+		t.Fatalf("VerifyPresentationFull failed: %v", err)
+	}
+	if result.Credentials[0].Expired || !result.Credentials[1].Expired {
+		t.Errorf("Expected only the second credential flagged expired, got %+v", result.Credentials)
+	}
+	if result.Err != nil || result.Valid() {
+		t.Errorf("Expected an invalid report without a presentation error, got %v", result.Err)
+	}
+
+	opts.Credentials.RejectExpiredCredentials = true
+	result, err = VerifyPresentationFull(vp, h.Holder.PublicKey, nil, h.Registry, opts)
+	if err != nil {
+		t.Fatalf("VerifyPresentationFull failed: %v", err)
+	}
+	if !errors.Is(result.Err, ErrExpiredCredential) {
+		t.Errorf("Expected ErrExpiredCredential, got %v", result.Err)
+	}
+}
@@ -4,11 +4,16 @@
 package veriglob
 
 import (
+	"context"
+	"crypto/ecdsa"
 	"crypto/ed25519"
+	"io"
+	"net/http"
 	"time"
 
 	"github.com/veriglob/veriglob-core/internal/crypto"
 	"github.com/veriglob/veriglob-core/internal/did"
+	"github.com/veriglob/veriglob-core/internal/httpclient"
 	"github.com/veriglob/veriglob-core/internal/presentation"
 	"github.com/veriglob/veriglob-core/internal/resolver"
 	"github.com/veriglob/veriglob-core/internal/revocation"
@@ -21,13 +26,34 @@ import (
 // DID types
 type (
 	DIDKey             = did.DIDKey
+	DIDWeb             = did.DIDWeb
 	DIDDocument        = did.DIDDocument
 	VerificationMethod = did.VerificationMethod
+	KeyType            = did.KeyType
+)
+
+// Key type constants
+const (
+	KeyTypeEd25519   = did.KeyTypeEd25519
+	KeyTypeSecp256k1 = did.KeyTypeSecp256k1
+	KeyTypeP256      = did.KeyTypeP256
+)
+
+// WellKnownDIDPath is where a did:web DID without path segments publishes its DID document
+const WellKnownDIDPath = did.WellKnownDIDPath
+
+// did:web errors
+var (
+	ErrInvalidDomain = did.ErrInvalidDomain
+	ErrInvalidPath   = did.ErrInvalidPath
 )
 
 // Credential types
 type (
 	VCClaims             = vc.VCClaims
+	VerifyOptions        = vc.VerifyOptions
+	IssueOptions         = vc.IssueOptions
+	SubjectFactory       = vc.SubjectFactory
 	VerifiableCredential = vc.VerifiableCredential
 	CredentialStatus     = vc.CredentialStatus
 	CredentialSubject    = vc.CredentialSubject
@@ -35,6 +61,26 @@ type (
 	EducationSubject     = vc.EducationSubject
 	EmploymentSubject    = vc.EmploymentSubject
 	MembershipSubject    = vc.MembershipSubject
+	GenericSubject       = vc.GenericSubject
+	CSVOptions           = vc.CSVOptions
+	IssuedCredential     = vc.IssuedCredential
+	CSVRowError          = vc.CSVRowError
+	Confirmation         = vc.Confirmation
+	RefreshService       = vc.RefreshService
+	CredentialSchema     = vc.CredentialSchema
+	SignatureSuite       = vc.SignatureSuite
+	TokenClaims          = vc.TokenClaims
+	PasetoV4Suite        = vc.PasetoV4Suite
+	ES256Suite           = vc.ES256Suite
+	EdDSASuite           = vc.EdDSASuite
+	Disclosure           = vc.Disclosure
+	VCMetadata           = vc.VCMetadata
+	DelegationSubject    = vc.DelegationSubject
+	DelegationOptions    = vc.DelegationOptions
+	TrustList            = vc.TrustList
+	Encoding             = vc.Encoding
+	CachedVerifier       = vc.CachedVerifier
+	IssuerDisplay        = vc.IssuerDisplay
 )
 
 // Credential type constants
@@ -43,39 +89,226 @@ const (
 	CredentialTypeEducation  = vc.CredentialTypeEducation
 	CredentialTypeEmployment = vc.CredentialTypeEmployment
 	CredentialTypeMembership = vc.CredentialTypeMembership
+	CredentialTypeDelegation = vc.CredentialTypeDelegation
+	CredentialsContextV1     = vc.CredentialsContextV1
+
+	DefaultMaxDelegationDepth = vc.DefaultMaxDelegationDepth
+
+	CredentialSchemaTypeJSONSchema = vc.CredentialSchemaTypeJSONSchema
+
+	SuitePasetoV4 = vc.SuitePasetoV4
+	SuiteES256JWS = vc.SuiteES256JWS
+	SuiteEdDSAJWS = vc.SuiteEdDSAJWS
+
+	EncodingJSON = vc.EncodingJSON
+	EncodingCBOR = vc.EncodingCBOR
 )
 
 // Presentation types
 type (
-	VPClaims               = presentation.VPClaims
-	VerifiablePresentation = presentation.VerifiablePresentation
+	VPClaims                = presentation.VPClaims
+	VerifiablePresentation  = presentation.VerifiablePresentation
+	CredentialResult        = presentation.CredentialResult
+	CredentialResults       = presentation.CredentialResults
+	PhaseTimings            = presentation.PhaseTimings
+	PhaseRecorder           = presentation.PhaseRecorder
+	LDPresentation          = presentation.LDPresentation
+	LDVerifyOptions         = presentation.LDVerifyOptions
+	MultiHolderPresentation = presentation.MultiHolderPresentation
+	HolderPortion           = presentation.HolderPortion
+	Proof                   = presentation.Proof
+	CredentialCheckOptions  = presentation.CredentialCheckOptions
+	CreateOptions           = presentation.CreateOptions
+	RevocationRequest       = presentation.RevocationRequest
+	StatusChecker           = presentation.StatusChecker
+	AgePredicate            = presentation.AgePredicate
+	NonceStore              = presentation.NonceStore
+	MemoryNonceStore        = presentation.MemoryNonceStore
+	CredentialReference     = presentation.CredentialReference
+	CredentialFetcher       = presentation.CredentialFetcher
+)
+
+// Verification errors
+var (
+	ErrNotACredential        = vc.ErrNotACredential
+	ErrNotAPresentation      = presentation.ErrNotAPresentation
+	ErrCredentialNotActive   = presentation.ErrCredentialNotActive
+	ErrExpiredCredential     = presentation.ErrExpiredCredential
+	ErrHolderSubjectMismatch = presentation.ErrHolderSubjectMismatch
+	ErrCredentialIndex       = presentation.ErrCredentialIndex
+	ErrChallengeMismatch     = presentation.ErrChallengeMismatch
+	ErrDomainMismatch        = presentation.ErrDomainMismatch
+	ErrMalformedCredential   = presentation.ErrMalformedCredential
+	ErrNotARevocationRequest = presentation.ErrNotARevocationRequest
+	ErrNotCredentialSubject  = presentation.ErrNotCredentialSubject
+	ErrAudienceMismatch      = presentation.ErrAudienceMismatch
+	ErrNonceMismatch         = presentation.ErrNonceMismatch
+	ErrPresentationExpired   = presentation.ErrPresentationExpired
+	ErrHolderKeyMismatch     = presentation.ErrHolderKeyMismatch
+	ErrHolderNotSigner       = presentation.ErrHolderNotSigner
+	ErrInvalidTTL            = presentation.ErrInvalidTTL
+	ErrPredicateNotSatisfied = presentation.ErrPredicateNotSatisfied
+	ErrNoAgePredicate        = presentation.ErrNoAgePredicate
+	ErrNotIdentityCredential = presentation.ErrNotIdentityCredential
+	ErrInvalidDateOfBirth    = presentation.ErrInvalidDateOfBirth
+	ErrNonceReplayed         = presentation.ErrNonceReplayed
+	ErrNotEncrypted          = presentation.ErrNotEncrypted
+	ErrDecryptionFailed      = presentation.ErrDecryptionFailed
+	ErrMissingProof          = presentation.ErrMissingProof
+	ErrInvalidProof          = presentation.ErrInvalidProof
+	ErrNoHolders             = presentation.ErrNoHolders
+	ErrDuplicateHolder       = presentation.ErrDuplicateHolder
+	ErrHolderNotIncluded     = presentation.ErrHolderNotIncluded
+	ErrMissingBinding        = presentation.ErrMissingBinding
+
+	ErrInvalidReference         = presentation.ErrInvalidReference
+	ErrReferenceNotFetched      = presentation.ErrReferenceNotFetched
+	ErrCredentialDigestMismatch = presentation.ErrCredentialDigestMismatch
+
+	ErrDelegationRootRequired = vc.ErrDelegationRootRequired
+	ErrInvalidDelegation      = vc.ErrInvalidDelegation
+	ErrDelegationBroken       = vc.ErrDelegationBroken
+	ErrDelegationScope        = vc.ErrDelegationScope
+	ErrRedelegationNotAllowed = vc.ErrRedelegationNotAllowed
+	ErrDelegationTooDeep      = vc.ErrDelegationTooDeep
+	ErrDelegationAfterIssue   = vc.ErrDelegationAfterIssue
+
+	ErrPresentationSignatureInvalid = presentation.ErrSignatureInvalid
+	ErrCredentialSignatureInvalid   = vc.ErrSignatureInvalid
+	ErrCredentialExpired            = vc.ErrCredentialExpired
+	ErrTokenSignatureInvalid        = vc.ErrTokenSignatureInvalid
+	ErrTokenExpired                 = vc.ErrTokenExpired
+	ErrTokenNotYetValid             = vc.ErrTokenNotYetValid
+
+	ErrUntrustedIssuer  = vc.ErrUntrustedIssuer
+	ErrInvalidTrustList = vc.ErrInvalidTrustList
+)
+
+// Subject decoding errors
+var (
+	ErrUnknownCredentialType = vc.ErrUnknownCredentialType
+	ErrInvalidSubjectFields  = vc.ErrInvalidSubjectFields
+	ErrMissingRequiredField  = vc.ErrMissingRequiredField
+	ErrSubjectTypeMismatch   = vc.ErrSubjectTypeMismatch
+	ErrInvalidSubjectTarget  = vc.ErrInvalidSubjectTarget
+)
+
+// Issuance errors
+var (
+	ErrValidityOutOfBounds = vc.ErrValidityOutOfBounds
+	ErrConflictingExpiry   = vc.ErrConflictingExpiry
+	ErrNotYetValid         = vc.ErrNotYetValid
+	ErrNoSubjects          = vc.ErrNoSubjects
+	ErrMixedSubjectTypes   = vc.ErrMixedSubjectTypes
+	ErrInvalidCSVHeader    = vc.ErrInvalidCSVHeader
+	ErrMissingCredentialID = vc.ErrMissingCredentialID
+	ErrIssuerKeyMismatch   = vc.ErrIssuerKeyMismatch
+	ErrInvalidHolderKey    = vc.ErrInvalidHolderKey
+	ErrNotW3CConformant    = vc.ErrNotW3CConformant
+	ErrUnsupportedKey      = vc.ErrUnsupportedKey
+	ErrSuiteKeyMissing     = vc.ErrSuiteKeyMissing
+	ErrUnsupportedEncoding = vc.ErrUnsupportedEncoding
+	ErrMalformedCBORClaim  = vc.ErrMalformedCBORClaim
+
+	ErrInvalidIssuerDisplay = vc.ErrInvalidIssuerDisplay
+
+	ErrInvalidDisclosure   = vc.ErrInvalidDisclosure
+	ErrDisclosureMismatch  = vc.ErrDisclosureMismatch
+	ErrDuplicateDisclosure = vc.ErrDuplicateDisclosure
+	ErrDisclosureNotFound  = vc.ErrDisclosureNotFound
+	ErrNotSDCredential     = vc.ErrNotSDCredential
+
+	ErrNoCredentialSchema    = vc.ErrNoCredentialSchema
+	ErrUnsupportedSchemaType = vc.ErrUnsupportedSchemaType
+	ErrSchemaUnavailable     = vc.ErrSchemaUnavailable
+	ErrSchemaValidation      = vc.ErrSchemaValidation
 )
 
 // Revocation types
 type (
-	RevocationRegistry = revocation.Registry
-	RevocationEntry    = revocation.Entry
-	RevocationStatus   = revocation.Status
+	RevocationRegistry  = revocation.Registry
+	RevocationEntry     = revocation.Entry
+	RevocationStatus    = revocation.Status
+	StatusList          = revocation.StatusList
+	RemoteRegistry      = revocation.RemoteRegistry
+	ShardedRegistry     = revocation.ShardedRegistry
+	RegistryListOptions = revocation.ListOptions
+	StatusProof         = revocation.StatusProof
+	StatusStatement     = revocation.StatusStatement
 )
 
+// PublishedStatusList is a status list read from a verified status list credential
+type PublishedStatusList = revocation.PublishedStatusList
+
+// StatusListOptions configures VerifyStatusListCredential
+type StatusListOptions = revocation.StatusListOptions
+
+// SignedRegistryOptions configures LoadSignedRegistry
+type SignedRegistryOptions = revocation.SignedRegistryOptions
+
 // Revocation status constants
 const (
-	StatusActive  = revocation.StatusActive
-	StatusRevoked = revocation.StatusRevoked
+	StatusActive    = revocation.StatusActive
+	StatusRevoked   = revocation.StatusRevoked
+	StatusSuspended = revocation.StatusSuspended
 )
 
 // Revocation errors
 var (
-	ErrCredentialNotFound = revocation.ErrCredentialNotFound
-	ErrAlreadyRevoked     = revocation.ErrAlreadyRevoked
+	ErrCredentialNotFound        = revocation.ErrCredentialNotFound
+	ErrAlreadyRevoked            = revocation.ErrAlreadyRevoked
+	ErrCredentialRevoked         = revocation.ErrCredentialRevoked
+	ErrCredentialSuspended       = revocation.ErrCredentialSuspended
+	ErrAlreadySuspended          = revocation.ErrAlreadySuspended
+	ErrNotSuspended              = revocation.ErrNotSuspended
+	ErrRevokedIsPermanent        = revocation.ErrRevokedIsPermanent
+	ErrRegistryUnavailable       = revocation.ErrRegistryUnavailable
+	ErrInvalidListOptions        = revocation.ErrInvalidListOptions
+	ErrInvalidSignedRegistry     = revocation.ErrInvalidSignedRegistry
+	ErrRegistrySignatureInvalid  = revocation.ErrRegistrySignatureInvalid
+	ErrUnsupportedRegistryFormat = revocation.ErrUnsupportedRegistryFormat
+	ErrUnauthorizedRevoker       = revocation.ErrUnauthorizedRevoker
+	ErrStaleRevocation           = revocation.ErrStaleRevocation
+	ErrSignedRegistryStale       = revocation.ErrSignedRegistryStale
+	ErrRegistryIssuerMismatch    = revocation.ErrRegistryIssuerMismatch
+
+	ErrInvalidStatusProof          = revocation.ErrInvalidStatusProof
+	ErrStatusProofSignatureInvalid = revocation.ErrStatusProofSignatureInvalid
+	ErrStatusProofStale            = revocation.ErrStatusProofStale
+	ErrStatusProofMissing          = presentation.ErrStatusProofMissing
+	ErrStatusProofMismatch         = presentation.ErrStatusProofMismatch
 )
 
+// DefaultStatusProofMaxAge is how old a status proof may be when the verifier sets no policy
+const DefaultStatusProofMaxAge = revocation.DefaultStatusProofMaxAge
+
+// DefaultSignedRegistryMaxAge is how old a signed registry may be when the verifier sets no policy
+const DefaultSignedRegistryMaxAge = revocation.DefaultSignedRegistryMaxAge
+
+// DefaultLeeway is the clock skew verification tolerates on expiry and nbf when none is set
+const DefaultLeeway = vc.DefaultLeeway
+
+// DefaultVerifierCacheSize is the CachedVerifier size used when NewCachedVerifier is given none
+const DefaultVerifierCacheSize = vc.DefaultVerifierCacheSize
+
+// SignedRevocationMaxAge is how long RevokeSigned accepts a signed revocation after its timestamp
+const SignedRevocationMaxAge = revocation.SignedRevocationMaxAge
+
 // Wallet types
 type (
-	Wallet           = storage.Wallet
-	WalletData       = storage.WalletData
-	KeyPair          = storage.KeyPair
-	StoredCredential = storage.StoredCredential
+	Wallet             = storage.Wallet
+	WalletData         = storage.WalletData
+	KeyPair            = storage.KeyPair
+	Identity           = storage.Identity
+	RetiredKey         = storage.RetiredKey
+	StoredCredential   = storage.StoredCredential
+	PresentationRecord = storage.PresentationRecord
+	CredentialFilter   = storage.CredentialFilter
+	WalletOptions      = storage.WalletOptions
+	Argon2Params       = storage.Argon2Params
+	WalletStore        = storage.WalletStore
+	FileStore          = storage.FileStore
+	MemoryStore        = storage.MemoryStore
 )
 
 // Wallet errors
@@ -84,10 +317,27 @@ var (
 	ErrWalletExists     = storage.ErrWalletExists
 	ErrInvalidPassword  = storage.ErrInvalidPassword
 	ErrCredentialExists = storage.ErrCredentialExists
+	ErrIdentityExists   = storage.ErrIdentityExists
+	ErrIdentityNotFound = storage.ErrIdentityNotFound
+	ErrPassphraseLength = storage.ErrPassphraseLength
+	ErrWalletLocked     = storage.ErrWalletLocked
+	ErrNoKeys           = storage.ErrNoKeys
+	ErrKeyNotFound      = storage.ErrKeyNotFound
+	ErrInvalidKDFParams = storage.ErrInvalidKDFParams
+
+	ErrInvalidBackup            = storage.ErrInvalidBackup
+	ErrUnsupportedBackupVersion = storage.ErrUnsupportedBackupVersion
 )
 
-// Resolver type
-type Resolver = resolver.Resolver
+// Resolver types
+type (
+	Resolver          = resolver.Resolver
+	ResolvedPublicKey = resolver.PublicKey
+	IssuerMetadata    = resolver.IssuerMetadata
+	MetadataFetcher   = resolver.MetadataFetcher
+	StaticStore       = resolver.StaticStore
+	HTTPOptions       = httpclient.Options
+)
 
 // ============================================================================
 // Crypto Functions
@@ -98,6 +348,41 @@ func GenerateEd25519Keypair() (ed25519.PublicKey, ed25519.PrivateKey, error) {
 	return crypto.GenerateEd25519Keypair()
 }
 
+// GenerateSecp256k1Keypair generates a new secp256k1 key pair (compressed public key, private scalar)
+func GenerateSecp256k1Keypair() ([]byte, []byte, error) {
+	return crypto.GenerateSecp256k1Keypair()
+}
+
+// GenerateP256Keypair generates a new ECDSA P-256 key pair, as used by WebAuthn authenticators
+func GenerateP256Keypair() (*ecdsa.PublicKey, *ecdsa.PrivateKey, error) {
+	return crypto.GenerateP256Keypair()
+}
+
+// KeypairFromSeed deterministically derives an Ed25519 key pair from a 32-byte seed
+func KeypairFromSeed(seed []byte) (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	return crypto.KeypairFromSeed(seed)
+}
+
+// GenerateMnemonic creates a BIP39 recovery phrase with the given entropy (256 bits = 24 words)
+func GenerateMnemonic(bits int) (string, error) {
+	return crypto.GenerateMnemonic(bits)
+}
+
+// MnemonicToSeed returns the BIP39 seed for a recovery phrase
+func MnemonicToSeed(phrase, passphrase string) ([]byte, error) {
+	return crypto.MnemonicToSeed(phrase, passphrase)
+}
+
+// KeypairFromMnemonic derives the Ed25519 key pair for a recovery phrase
+func KeypairFromMnemonic(phrase, passphrase string) (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	return crypto.KeypairFromMnemonic(phrase, passphrase)
+}
+
+// DeriveKeypair derives a per-context (e.g. per-verifier) keypair from a master key
+func DeriveKeypair(master ed25519.PrivateKey, context string) (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	return crypto.DeriveKeypair(master, context)
+}
+
 // ============================================================================
 // DID Functions
 // ============================================================================
@@ -107,6 +392,31 @@ func CreateDIDKey(pub ed25519.PublicKey) (*DIDKey, error) {
 	return did.CreateDIDKey(pub)
 }
 
+// CreateDIDKeySecp256k1 generates a did:key from a secp256k1 public key
+func CreateDIDKeySecp256k1(pub []byte) (*DIDKey, error) {
+	return did.CreateDIDKeySecp256k1(pub)
+}
+
+// CreateDIDKeyP256 generates a did:key from an ECDSA P-256 public key
+func CreateDIDKeyP256(pub *ecdsa.PublicKey) (*DIDKey, error) {
+	return did.CreateDIDKeyP256(pub)
+}
+
+// WebDID returns the canonical did:web identifier of a domain and optional path
+func WebDID(domain, path string) (string, error) {
+	return did.WebDID(domain, path)
+}
+
+// CreateDIDWeb builds the did:web identity of a domain and optional path for the key of a did:key
+func CreateDIDWeb(domain, path string, key *DIDKey) (*DIDWeb, error) {
+	return did.CreateDIDWeb(domain, path, key)
+}
+
+// CreateDIDWebEd25519 builds the did:web identity of a domain and optional path for an Ed25519 public key
+func CreateDIDWebEd25519(domain, path string, pub ed25519.PublicKey) (*DIDWeb, error) {
+	return did.CreateDIDWebEd25519(domain, path, pub)
+}
+
 // ============================================================================
 // Resolver Functions
 // ============================================================================
@@ -116,6 +426,93 @@ func NewResolver() *Resolver {
 	return resolver.NewResolver()
 }
 
+// NewResolverWithMetadataFetcher creates a resolver that can also resolve issuer metadata
+func NewResolverWithMetadataFetcher(fetch MetadataFetcher) *Resolver {
+	return resolver.NewResolverWithMetadataFetcher(fetch)
+}
+
+// NewStaticStore creates an empty store of preloaded, trusted DID documents
+func NewStaticStore() *StaticStore {
+	return resolver.NewStaticStore()
+}
+
+// NewResolverWithStaticStore creates a resolver that consults store before any DID method
+func NewResolverWithStaticStore(store *StaticStore) *Resolver {
+	return resolver.NewResolverWithStaticStore(store)
+}
+
+// NewResolverWithCache creates a resolver that caches up to size resolved DID documents for ttl
+func NewResolverWithCache(size int, ttl time.Duration) *Resolver {
+	return resolver.NewResolverWithCache(size, ttl)
+}
+
+// NewWebMetadataFetcher fetches did:web issuer metadata over HTTPS, retrying transient failures until the fetch's ctx is done
+func NewWebMetadataFetcher(opts HTTPOptions) MetadataFetcher {
+	return resolver.NewWebMetadataFetcher(opts)
+}
+
+// WebMetadataURL returns where a did:web issuer publishes its metadata
+func WebMetadataURL(issuerDID string) (string, error) {
+	return resolver.WebMetadataURL(issuerDID)
+}
+
+// DID resolution errors
+var (
+	ErrInvalidDID        = resolver.ErrInvalidDID
+	ErrUnsupportedMethod = resolver.ErrUnsupportedMethod
+)
+
+// ValidateDID checks a DID against the DID Core syntax, returning ErrInvalidDID if it does not match
+func ValidateDID(didStr string) error {
+	return resolver.ValidateDID(didStr)
+}
+
+// DIDURL is a DID with an optional query and fragment
+type DIDURL = resolver.DIDURL
+
+// ParseDIDURL splits a DID URL such as did:key:z...#key-1 into its base DID, query and fragment
+func ParseDIDURL(s string) (*DIDURL, error) {
+	return resolver.ParseDIDURL(s)
+}
+
+// ResolveAllKeys returns every Ed25519 key a DID has signed with, newest first, following its key rotation history
+func ResolveAllKeys(didStr string) ([]ed25519.PublicKey, error) {
+	return resolver.ResolveAllKeys(didStr)
+}
+
+// ResolveDIDDocument resolves a DID to its full DID document
+func ResolveDIDDocument(didStr string) (*DIDDocument, error) {
+	return resolver.ResolveDIDDocument(didStr)
+}
+
+// DefaultCacheSize is the resolver cache size used when NewResolverWithCache is given none
+const DefaultCacheSize = resolver.DefaultCacheSize
+
+// DefaultMetadataTTL is how long a resolver caches issuer metadata before fetching it again
+const DefaultMetadataTTL = resolver.DefaultMetadataTTL
+
+// Proof purposes checked against a DID document's verification relationships
+const (
+	ProofPurposeAuthentication  = resolver.ProofPurposeAuthentication
+	ProofPurposeAssertionMethod = resolver.ProofPurposeAssertionMethod
+)
+
+// ErrInvalidProofPurpose is returned when a key is not listed for the proof purpose it was used for
+var ErrInvalidProofPurpose = resolver.ErrInvalidProofPurpose
+
+// VerifyDIDProofPurpose checks that key is listed under purpose in the DID's document
+func VerifyDIDProofPurpose(didStr string, key ed25519.PublicKey, purpose string) error {
+	return resolver.VerifyDIDProofPurpose(didStr, key, purpose)
+}
+
+// WellKnownIssuerMetadataPath is where an issuer hosts its metadata document
+const WellKnownIssuerMetadataPath = resolver.WellKnownIssuerMetadataPath
+
+// FetchIssuerMetadata retrieves an issuer's published metadata without caching
+func FetchIssuerMetadata(ctx context.Context, issuerDID string, fetch MetadataFetcher) (*IssuerMetadata, error) {
+	return resolver.FetchIssuerMetadata(ctx, issuerDID, fetch)
+}
+
 // ============================================================================
 // Credential Functions
 // ============================================================================
@@ -130,11 +527,177 @@ func IssueVCWithID(issuerDID, subjectDID string, privateKey interface{}, subject
 	return vc.IssueVCWithID(issuerDID, subjectDID, privateKey, subject, credentialID)
 }
 
+// IssueVCWithStatus creates and signs a Verifiable Credential with a specific credentialStatus
+func IssueVCWithStatus(issuerDID, subjectDID string, privateKey interface{}, subject CredentialSubject, credentialID string, status *CredentialStatus) (string, error) {
+	return vc.IssueVCWithStatus(issuerDID, subjectDID, privateKey, subject, credentialID, status)
+}
+
+// NewStatusList2021Entry builds a credentialStatus pointing at a bit in a status list
+func NewStatusList2021Entry(listURL string, index int) *CredentialStatus {
+	return vc.NewStatusList2021Entry(listURL, index)
+}
+
+// IssueVCWithOptions creates and signs a Verifiable Credential, enforcing any validity bounds in opts
+func IssueVCWithOptions(issuerDID, subjectDID string, privateKey interface{}, subject CredentialSubject, opts IssueOptions) (string, error) {
+	return vc.IssueVCWithOptions(issuerDID, subjectDID, privateKey, subject, opts)
+}
+
+// IssueVCWithSubjects issues a credential naming several subjects of the same type
+func IssueVCWithSubjects(issuerDID, subjectDID string, privateKey interface{}, subjects []CredentialSubject, opts IssueOptions) (string, error) {
+	return vc.IssueVCWithSubjects(issuerDID, subjectDID, privateKey, subjects, opts)
+}
+
+// IssueTyped issues a credential of a registered type from a field map
+func IssueTyped(typeName string, fields map[string]interface{}, issuerDID, subjectDID string, privateKey ed25519.PrivateKey, opts IssueOptions) (string, error) {
+	return vc.IssueTyped(typeName, fields, issuerDID, subjectDID, privateKey, opts)
+}
+
+// IssueFromCSV issues one credential of a registered type per CSV row, collecting per-row errors
+func IssueFromCSV(reader io.Reader, typeName, issuerDID string, privateKey ed25519.PrivateKey, opts CSVOptions) ([]IssuedCredential, []error) {
+	return vc.IssueFromCSV(reader, typeName, issuerDID, privateKey, opts)
+}
+
+// NewGenericSubject creates a subject of a credential type this package does not define
+func NewGenericSubject(id, credentialType string, claims map[string]interface{}) GenericSubject {
+	return vc.NewGenericSubject(id, credentialType, claims)
+}
+
+// RegisterSubjectType makes a custom credential type available to IssueTyped
+func RegisterSubjectType(typeName string, factory SubjectFactory) error {
+	return vc.RegisterSubjectType(typeName, factory)
+}
+
+// VerifyVCES256 verifies a credential issued as an ES256 JWS with a P-256 key
+func VerifyVCES256(tokenString string, publicKey *ecdsa.PublicKey) (*VCClaims, error) {
+	return vc.VerifyVCES256(tokenString, publicKey)
+}
+
+// NewPasetoV4Suite creates the default PASETO v4 signature suite; either key may be nil
+func NewPasetoV4Suite(privateKey ed25519.PrivateKey, publicKey ed25519.PublicKey) *PasetoV4Suite {
+	return vc.NewPasetoV4Suite(privateKey, publicKey)
+}
+
+// NewES256Suite creates an ES256 JWS signature suite; either key may be nil
+func NewES256Suite(privateKey *ecdsa.PrivateKey, publicKey *ecdsa.PublicKey) *ES256Suite {
+	return vc.NewES256Suite(privateKey, publicKey)
+}
+
+// NewEdDSASuite creates an EdDSA JWS signature suite; either key and keyID may be empty
+func NewEdDSASuite(privateKey ed25519.PrivateKey, publicKey ed25519.PublicKey, keyID string) *EdDSASuite {
+	return vc.NewEdDSASuite(privateKey, publicKey, keyID)
+}
+
+// IssueVCAsJWT issues a credential as an EdDSA-signed JWT-VC for verifiers that do not read PASETO
+func IssueVCAsJWT(issuerDID, subjectDID string, privateKey ed25519.PrivateKey, subject CredentialSubject, opts IssueOptions) (string, error) {
+	return vc.IssueVCAsJWT(issuerDID, subjectDID, privateKey, subject, opts)
+}
+
+// VerifyVCFromJWT verifies an EdDSA JWT-VC against the issuer's Ed25519 key
+func VerifyVCFromJWT(tokenString string, publicKey ed25519.PublicKey) (*VCClaims, error) {
+	return vc.VerifyVCFromJWT(tokenString, publicKey)
+}
+
+// IssueSDVC issues a selective disclosure credential with every subject claim but id hidden behind a salted digest
+func IssueSDVC(issuerDID, subjectDID string, privateKey interface{}, subject CredentialSubject, opts IssueOptions) (string, error) {
+	return vc.IssueSDVC(issuerDID, subjectDID, privateKey, subject, opts)
+}
+
+// SelectDisclosures keeps only the disclosures of the named claims in a selective disclosure credential
+func SelectDisclosures(credential string, names ...string) (string, error) {
+	return vc.SelectDisclosures(credential, names...)
+}
+
+// VerifySDVC verifies a selective disclosure credential and returns its disclosed claims
+func VerifySDVC(credential string, publicKey ed25519.PublicKey) (*VCClaims, error) {
+	return vc.VerifySDVC(credential, publicKey)
+}
+
+// VerifyVCWithSuite verifies a credential with the given signature suite
+func VerifyVCWithSuite(tokenString string, suite SignatureSuite) (*VCClaims, error) {
+	return vc.VerifyVCWithSuite(tokenString, suite)
+}
+
 // VerifyVC verifies a PASETO v4 public token and returns the claims
 func VerifyVC(tokenString string, publicKey ed25519.PublicKey) (*VCClaims, error) {
 	return vc.VerifyVC(tokenString, publicKey)
 }
 
+// NewCachedVerifier creates a verifier that caches up to size verified credentials until their expiry
+func NewCachedVerifier(size int) *CachedVerifier {
+	return vc.NewCachedVerifier(size)
+}
+
+// VerifyVCWithStatus verifies a credential and resolves its embedded credentialStatus against checker, returning ErrCredentialRevoked or ErrCredentialSuspended for an inactive credential
+func VerifyVCWithStatus(tokenString string, publicKey ed25519.PublicKey, checker StatusChecker) (*VCClaims, RevocationStatus, error) {
+	return revocation.VerifyVCWithStatus(tokenString, publicKey, checker)
+}
+
+// VerifyVCAny verifies a credential against each key in turn, e.g. an issuer's current and retired keys
+func VerifyVCAny(tokenString string, keys []ed25519.PublicKey) (*VCClaims, error) {
+	return vc.VerifyVCAny(tokenString, keys)
+}
+
+// VerifyVCExpectingIssuer verifies a credential that must be issued, and signed, by expectedIssuerDID
+func VerifyVCExpectingIssuer(tokenString string, publicKey ed25519.PublicKey, expectedIssuerDID string) (*VCClaims, error) {
+	return vc.VerifyVCExpectingIssuer(tokenString, publicKey, expectedIssuerDID)
+}
+
+// VerifyVCWithOptions verifies a PASETO v4 public token, applying the given options
+func VerifyVCWithOptions(tokenString string, publicKey ed25519.PublicKey, opts VerifyOptions) (*VCClaims, error) {
+	return vc.VerifyVCWithOptions(tokenString, publicKey, opts)
+}
+
+// VerifyDelegatedVC verifies a credential against its issuer's resolved key and its delegation chain up to opts.RootDID
+func VerifyDelegatedVC(tokenString string, opts DelegationOptions) (*VCClaims, error) {
+	return vc.VerifyDelegatedVC(tokenString, opts)
+}
+
+// VerifyDelegationChain checks a verified credential's issuer holds authority delegated from opts.RootDID, returning the authority path
+func VerifyDelegationChain(claims *VCClaims, opts DelegationOptions) ([]string, error) {
+	return vc.VerifyDelegationChain(claims, opts)
+}
+
+// NewTrustList creates an empty issuer allowlist, which trusts no issuer
+func NewTrustList() *TrustList {
+	return vc.NewTrustList()
+}
+
+// ParseTrustList reads an issuer allowlist: one DID per line, optionally followed by the credential types it is trusted for
+func ParseTrustList(data []byte) (*TrustList, error) {
+	return vc.ParseTrustList(data)
+}
+
+// InspectVC reads a credential's ID, issuer, subject, type and dates without verifying its signature; the result is untrusted
+func InspectVC(tokenString string) (*VCMetadata, error) {
+	return vc.InspectVC(tokenString)
+}
+
+// Fingerprint returns the sha256 hash of a credential token's canonical form,
+// identifying one signed credential; use VCClaims.Digest to compare content
+func Fingerprint(tokenString string) (string, error) {
+	return vc.Fingerprint(tokenString)
+}
+
+// UnverifiedClaims decodes a credential's claims without checking its signature
+func UnverifiedClaims(tokenString string) (*VCClaims, error) {
+	return vc.UnverifiedClaims(tokenString)
+}
+
+// UnverifiedIssuer returns the issuer of a credential or presentation token without checking its signature
+func UnverifiedIssuer(tokenString string) (string, error) {
+	return vc.UnverifiedIssuer(tokenString)
+}
+
+// DecodeSubject decodes a credential's subject into the subject type T, e.g. DecodeSubject[IdentitySubject](claims)
+func DecodeSubject[T CredentialSubject](claims *VCClaims) (T, error) {
+	return vc.DecodeSubject[T](claims)
+}
+
+// ValidateAgainstSchema fetches a credential's JSON Schema and validates its subjects against it
+func ValidateAgainstSchema(ctx context.Context, claims *VCClaims, client *http.Client) error {
+	return vc.ValidateAgainstSchema(ctx, claims, client)
+}
+
 // ============================================================================
 // Presentation Functions
 // ============================================================================
@@ -144,11 +707,147 @@ func CreatePresentation(holderDID string, holderPrivateKey ed25519.PrivateKey, c
 	return presentation.CreatePresentation(holderDID, holderPrivateKey, credentials, audience, nonce)
 }
 
+// CreatePresentationWithOptions creates a signed Verifiable Presentation, optionally validating each credential's structure
+func CreatePresentationWithOptions(holderDID string, holderPrivateKey ed25519.PrivateKey, credentials []string, audience, nonce string, opts CreateOptions) (string, error) {
+	return presentation.CreatePresentationWithOptions(holderDID, holderPrivateKey, credentials, audience, nonce, opts)
+}
+
+// CreatePresentationWithReferences creates a presentation that carries some credentials inline and references others by URL and digest
+func CreatePresentationWithReferences(holderDID string, holderPrivateKey ed25519.PrivateKey, credentials []string, references []CredentialReference, audience, nonce string, opts CreateOptions) (string, error) {
+	return presentation.CreatePresentationWithReferences(holderDID, holderPrivateKey, credentials, references, audience, nonce, opts)
+}
+
+// NewCredentialReference references a credential token published at id
+func NewCredentialReference(id, token string) CredentialReference {
+	return presentation.NewCredentialReference(id, token)
+}
+
+// CredentialDigest returns the sha256 digest a CredentialReference uses for a credential token
+func CredentialDigest(token string) string {
+	return presentation.CredentialDigest(token)
+}
+
+// NewHTTPCredentialFetcher returns a CredentialFetcher that downloads referenced credentials over HTTPS
+func NewHTTPCredentialFetcher(ctx context.Context, opts HTTPOptions) CredentialFetcher {
+	return presentation.NewHTTPCredentialFetcher(ctx, opts)
+}
+
 // VerifyPresentation verifies a PASETO VP token and returns the claims
 func VerifyPresentation(tokenString string, holderPublicKey ed25519.PublicKey, expectedAudience, expectedNonce string) (*VPClaims, error) {
 	return presentation.VerifyPresentation(tokenString, holderPublicKey, expectedAudience, expectedNonce)
 }
 
+// VerifyPresentationForDomain verifies a presentation token, also checking its domain when expectedDomain is not empty
+func VerifyPresentationForDomain(tokenString string, holderPublicKey ed25519.PublicKey, expectedAudience, expectedNonce, expectedDomain string) (*VPClaims, error) {
+	return presentation.VerifyPresentationForDomain(tokenString, holderPublicKey, expectedAudience, expectedNonce, expectedDomain)
+}
+
+// CreateEncryptedPresentation creates a signed presentation and encrypts it to the verifier's Ed25519 key as a PASETO v4 local token
+func CreateEncryptedPresentation(holderDID string, holderPrivateKey ed25519.PrivateKey, verifierPublicKey ed25519.PublicKey, credentials []string, audience, nonce string, opts CreateOptions) (string, error) {
+	return presentation.CreateEncryptedPresentation(holderDID, holderPrivateKey, verifierPublicKey, credentials, audience, nonce, opts)
+}
+
+// DecryptPresentation decrypts an encrypted presentation with the verifier's private key, returning the signed presentation inside
+func DecryptPresentation(tokenString string, verifierPrivateKey ed25519.PrivateKey) (string, error) {
+	return presentation.DecryptPresentation(tokenString, verifierPrivateKey)
+}
+
+// VerifyEncryptedPresentation decrypts an encrypted presentation and verifies the signed presentation inside
+func VerifyEncryptedPresentation(tokenString string, verifierPrivateKey ed25519.PrivateKey, holderPublicKey ed25519.PublicKey, expectedAudience, expectedNonce string) (*VPClaims, error) {
+	return presentation.VerifyEncryptedPresentation(tokenString, verifierPrivateKey, holderPublicKey, expectedAudience, expectedNonce)
+}
+
+// NewMemoryNonceStore creates an in-memory store of consumed presentation nonces
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return presentation.NewMemoryNonceStore()
+}
+
+// VerifyPresentationOnce verifies a presentation and consumes its nonce, rejecting replays with ErrNonceReplayed
+func VerifyPresentationOnce(tokenString string, holderPublicKey ed25519.PublicKey, store NonceStore, expectedAudience, expectedNonce string) (*VPClaims, error) {
+	return presentation.VerifyPresentationOnce(tokenString, holderPublicKey, store, expectedAudience, expectedNonce)
+}
+
+// AgeOver reports whether someone born on dateOfBirth (YYYY-MM-DD) is at least minAge years old on the given day
+func AgeOver(dateOfBirth string, minAge int, on time.Time) (bool, error) {
+	return presentation.AgeOver(dateOfBirth, minAge, on)
+}
+
+// CreateAgePresentation creates a presentation asserting only that the holder is at least minAge years old
+func CreateAgePresentation(holderDID string, holderPrivateKey ed25519.PrivateKey, credential string, minAge int, audience, nonce string) (string, error) {
+	return presentation.CreateAgePresentation(holderDID, holderPrivateKey, credential, minAge, audience, nonce)
+}
+
+// VerifyAgePresentation verifies an age presentation and recomputes the age from its identity credential
+func VerifyAgePresentation(tokenString string, holderPublicKey ed25519.PublicKey, expectedAudience, expectedNonce string, minAge int, opts CredentialCheckOptions) (*VPClaims, error) {
+	return presentation.VerifyAgePresentation(tokenString, holderPublicKey, expectedAudience, expectedNonce, minAge, opts)
+}
+
+// VerifyPresentationWithSuite verifies a presentation signed with the given signature suite
+func VerifyPresentationWithSuite(tokenString string, suite SignatureSuite, expectedAudience, expectedNonce, expectedDomain string) (*VPClaims, error) {
+	return presentation.VerifyPresentationWithSuite(tokenString, suite, expectedAudience, expectedNonce, expectedDomain)
+}
+
+// CheckHolderKey reports whether key is one the holder DID resolves to
+func CheckHolderKey(holderDID string, key ed25519.PublicKey, r *Resolver) error {
+	return presentation.CheckHolderKey(holderDID, key, r)
+}
+
+// VerifyPresentationWithCredentials verifies a presentation and each embedded
+// credential, resolving issuer keys from their DIDs
+func VerifyPresentationWithCredentials(tokenString string, holderPublicKey ed25519.PublicKey, expectedAudience, expectedNonce string, opts CredentialCheckOptions) (*VPClaims, CredentialResults, error) {
+	return presentation.VerifyPresentationWithCredentials(tokenString, holderPublicKey, expectedAudience, expectedNonce, opts)
+}
+
+// CreateLDPresentation creates a JSON presentation with an embedded proof bound to a domain and challenge
+func CreateLDPresentation(holderDID string, holderPrivateKey ed25519.PrivateKey, credentials []string, domain, challenge string) (*LDPresentation, error) {
+	return presentation.CreateLDPresentation(holderDID, holderPrivateKey, credentials, domain, challenge)
+}
+
+// NewMultiHolderPresentation creates an unsigned presentation of several holders' credentials for each holder to sign
+func NewMultiHolderPresentation(portions []HolderPortion) (*MultiHolderPresentation, error) {
+	return presentation.NewMultiHolderPresentation(portions)
+}
+
+// VerifyMultiHolderPresentation checks every holder's proof and that each credential belongs to the holder it is listed under
+func VerifyMultiHolderPresentation(vp *MultiHolderPresentation, expectedDomain, expectedChallenge string, opts CredentialCheckOptions) (CredentialResults, error) {
+	return presentation.VerifyMultiHolderPresentation(vp, expectedDomain, expectedChallenge, opts)
+}
+
+// VerifyLDPresentation checks a JSON presentation's proof, its age, domain, and challenge
+func VerifyLDPresentation(vp *LDPresentation, holderPublicKey ed25519.PublicKey, expectedDomain, expectedChallenge string) error {
+	return presentation.VerifyLDPresentation(vp, holderPublicKey, expectedDomain, expectedChallenge)
+}
+
+// VerifyLDPresentationWithOptions checks a JSON presentation like VerifyLDPresentation with a custom proof age
+func VerifyLDPresentationWithOptions(vp *LDPresentation, holderPublicKey ed25519.PublicKey, expectedDomain, expectedChallenge string, opts LDVerifyOptions) error {
+	return presentation.VerifyLDPresentationWithOptions(vp, holderPublicKey, expectedDomain, expectedChallenge, opts)
+}
+
+// CreateRevocationRequest signs a holder's request that the issuer revoke one of the holder's credentials
+func CreateRevocationRequest(holderDID string, holderPrivateKey ed25519.PrivateKey, credentialID string) (string, error) {
+	return presentation.CreateRevocationRequest(holderDID, holderPrivateKey, credentialID)
+}
+
+// VerifyRevocationRequest checks a revocation request's signature and that the requester is the credential's subject
+func VerifyRevocationRequest(tokenString string, status StatusChecker, res *Resolver) (*RevocationRequest, error) {
+	return presentation.VerifyRevocationRequest(tokenString, status, res)
+}
+
+// DecodeCompactPresentation decodes a compact (QR) payload back into a presentation token
+func DecodeCompactPresentation(payload string) (string, error) {
+	return presentation.DecodeCompact(payload)
+}
+
+// SplitQRFrames splits a compact payload too large for one QR code into numbered frames
+func SplitQRFrames(payload string, frameLength int) ([]string, error) {
+	return presentation.SplitQRFrames(payload, frameLength)
+}
+
+// JoinQRFrames reassembles a compact payload from its scanned QR frames, in any order
+func JoinQRFrames(frames []string) (string, error) {
+	return presentation.JoinQRFrames(frames)
+}
+
 // GenerateNonce creates a random nonce for challenge-response
 func GenerateNonce() (string, error) {
 	return presentation.GenerateNonce()
@@ -168,11 +867,71 @@ func NewRevocationRegistryWithFile(path string) (*RevocationRegistry, error) {
 	return revocation.NewRegistryWithFile(path)
 }
 
+// NewStatusList creates an empty StatusList2021 bitstring with room for size credentials
+func NewStatusList(size int) *StatusList {
+	return revocation.NewStatusList(size)
+}
+
+// RestoreStatusList resumes an issuer's status list from its encoded bitstring and saved Next index
+func RestoreStatusList(encoded string, next int) (*StatusList, error) {
+	return revocation.RestoreStatusList(encoded, next)
+}
+
+// IssueStatusListCredential signs a status list as a credential for hosting at listURL
+func IssueStatusListCredential(listURL, issuerDID string, privateKey ed25519.PrivateKey, list *StatusList) (string, error) {
+	return revocation.IssueStatusListCredential(listURL, issuerDID, privateKey, list)
+}
+
+// VerifyStatusListCredential verifies a status list credential issued by opts.IssuerDID and returns the decoded list
+func VerifyStatusListCredential(token string, issuerPublicKey ed25519.PublicKey, opts StatusListOptions) (*PublishedStatusList, error) {
+	return revocation.VerifyStatusListCredential(token, issuerPublicKey, opts)
+}
+
+// CheckStatusListEntry reports the status of a credential's StatusList2021Entry in a verified list
+func CheckStatusListEntry(claims *VCClaims, list *PublishedStatusList) (RevocationStatus, error) {
+	return revocation.CheckStatusListEntry(claims, list)
+}
+
+// LoadSignedRegistry verifies a registry exported with ExportSigned and loads its entries, returning when it was signed
+func LoadSignedRegistry(data []byte, issuerPublicKey ed25519.PublicKey, opts SignedRegistryOptions) (*RevocationRegistry, time.Time, error) {
+	return revocation.LoadSignedRegistry(data, issuerPublicKey, opts)
+}
+
+// NewShardedRegistry opens a registry that stores each issuer's entries in its own file under dir
+func NewShardedRegistry(dir string) (*ShardedRegistry, error) {
+	return revocation.NewShardedRegistry(dir)
+}
+
+// NewRemoteRegistry creates a client for an HTTP revocation service
+func NewRemoteRegistry(baseURL string, ttl time.Duration) *RemoteRegistry {
+	return revocation.NewRemoteRegistry(baseURL, ttl)
+}
+
+// NewRemoteRegistryWithOptions creates a client for an HTTP revocation service with custom retry and rate limit settings
+func NewRemoteRegistryWithOptions(baseURL string, ttl time.Duration, opts HTTPOptions) *RemoteRegistry {
+	return revocation.NewRemoteRegistryWithOptions(baseURL, ttl, opts)
+}
+
 // GenerateCredentialID creates a unique credential ID
 func GenerateCredentialID() (string, error) {
 	return revocation.GenerateCredentialID()
 }
 
+// DeriveCredentialID returns a credential ID that is stable for the same issuer, subject, type and salt
+func DeriveCredentialID(issuerDID, subjectDID, credType string, salt []byte) string {
+	return revocation.DeriveCredentialID(issuerDID, subjectDID, credType, salt)
+}
+
+// SignRevocation signs a revocation of a credential with the issuer's key, for RevokeSigned
+func SignRevocation(credentialID, reason string, signedAt time.Time, issuerPrivateKey ed25519.PrivateKey) []byte {
+	return revocation.SignRevocation(credentialID, reason, signedAt, issuerPrivateKey)
+}
+
+// SignedRevocationMessage returns the canonical bytes an issuer signs to revoke a credential
+func SignedRevocationMessage(credentialID, reason string, signedAt time.Time) []byte {
+	return revocation.SignedRevocationMessage(credentialID, reason, signedAt)
+}
+
 // ============================================================================
 // Wallet Functions
 // ============================================================================
@@ -182,32 +941,62 @@ func CreateWallet(path, passphrase string) (*Wallet, error) {
 	return storage.CreateWallet(path, passphrase)
 }
 
+// CreateWalletWithOptions creates a new wallet with the given passphrase and key derivation cost
+func CreateWalletWithOptions(path, passphrase string, opts WalletOptions) (*Wallet, error) {
+	return storage.CreateWalletWithOptions(path, passphrase, opts)
+}
+
+// CalibrateKDF returns Argon2id parameters that take about target to unlock a wallet on this machine
+func CalibrateKDF(target time.Duration) (Argon2Params, error) {
+	return storage.CalibrateKDF(target)
+}
+
 // OpenWallet opens an existing wallet
 func OpenWallet(path, passphrase string) (*Wallet, error) {
 	return storage.OpenWallet(path, passphrase)
 }
 
+// NewFileStore creates a wallet store backed by the file at path
+func NewFileStore(path string) *FileStore {
+	return storage.NewFileStore(path)
+}
+
+// NewMemoryStore creates an empty in-memory wallet store, e.g. for tests
+func NewMemoryStore() *MemoryStore {
+	return storage.NewMemoryStore()
+}
+
+// NewWalletWithStore opens the wallet held by store, or creates one if the store is empty
+func NewWalletWithStore(store WalletStore, passphrase string) (*Wallet, error) {
+	return storage.NewWalletWithStore(store, passphrase)
+}
+
+// CreateWalletWithStore creates a new wallet in store with the given passphrase and key derivation cost
+func CreateWalletWithStore(store WalletStore, passphrase string, opts WalletOptions) (*Wallet, error) {
+	return storage.CreateWalletWithStore(store, passphrase, opts)
+}
+
+// OpenWalletWithStore opens the wallet held by store
+func OpenWalletWithStore(store WalletStore, passphrase string) (*Wallet, error) {
+	return storage.OpenWalletWithStore(store, passphrase)
+}
+
+// ImportWallet restores an encrypted wallet backup to a new wallet at path
+func ImportWallet(path string, blob []byte, password string) (*Wallet, error) {
+	return storage.ImportWallet(path, blob, password)
+}
+
+// ImportWalletData creates a new encrypted wallet at path from the JSON returned by ExportUnsafePlaintext
+func ImportWalletData(path, passphrase string, data []byte) (*Wallet, error) {
+	return storage.ImportWalletData(path, passphrase, data)
+}
+
 // ============================================================================
 // Helper Types for API
 // ============================================================================
 
 // CredentialInfo contains metadata about a credential for API responses
-type CredentialInfo struct {
-	ID               string
-	Type             string
-	IssuerDID        string
-	SubjectDID       string
-	IssuedAt         time.Time
-	ExpiresAt        time.Time
-	Status           string
-	RevocationReason string
-}
+type CredentialInfo = storage.CredentialInfo
 
 // WalletInfo contains metadata about a wallet for API responses
-type WalletInfo struct {
-	ID              string
-	DID             string
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
-	CredentialCount int
-}
+type WalletInfo = storage.WalletInfo
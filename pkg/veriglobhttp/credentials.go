@@ -0,0 +1,147 @@
+package veriglobhttp
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/veriglob/veriglob-core/pkg/veriglob"
+)
+
+// issueCredential handles POST /credentials: it issues a credential of a
+// registered type under the key store's identity and registers it
+func (h *handler) issueCredential(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Keys == nil {
+		writeJSON(w, http.StatusNotImplemented, ErrorResponse{Error: "issuance is not configured"})
+		return
+	}
+
+	var req IssueCredentialRequest
+	if err := h.decodeRequest(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	if req.Type == "" {
+		writeError(w, badRequest("type is required"))
+		return
+	}
+	if req.Subject == nil {
+		writeError(w, badRequest("subject is required"))
+		return
+	}
+	subjectDID, _ := req.Subject["id"].(string)
+	if subjectDID == "" {
+		writeError(w, badRequest("subject id is required"))
+		return
+	}
+	if err := veriglob.ValidateDID(subjectDID); err != nil {
+		writeError(w, badRequest("subject id: %v", err))
+		return
+	}
+
+	issuerDID, key, err := h.opts.Keys.IssuerKey(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	credentialID, err := veriglob.GenerateCredentialID()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	opts := veriglob.IssueOptions{CredentialID: credentialID}
+	if req.ExpiresAt != nil {
+		opts.ExpiresAt = *req.ExpiresAt
+	}
+	token, err := veriglob.IssueTyped(req.Type, req.Subject, issuerDID, subjectDID, key, opts)
+	if err != nil {
+		writeError(w, issueError(err))
+		return
+	}
+
+	// A credential that cannot be revoked is not handed out
+	if h.opts.Registry != nil {
+		if err := h.opts.Registry.Register(credentialID, issuerDID, subjectDID); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusCreated, IssueCredentialResponse{
+		CredentialID: credentialID,
+		Type:         req.Type,
+		Issuer:       issuerDID,
+		Subject:      subjectDID,
+		Token:        token,
+	})
+}
+
+// issueError maps an issuance failure caused by the request to a 400
+func issueError(err error) error {
+	for _, target := range []error{
+		veriglob.ErrUnknownCredentialType,
+		veriglob.ErrInvalidSubjectFields,
+		veriglob.ErrMissingRequiredField,
+		veriglob.ErrValidityOutOfBounds,
+		veriglob.ErrConflictingExpiry,
+	} {
+		if errors.Is(err, target) {
+			return badRequest("%v", err)
+		}
+	}
+	return err
+}
+
+// verifyCredential handles POST /credentials/verify: the issuer key is
+// resolved from the credential's issuer DID and its status looked up in the
+// registry
+func (h *handler) verifyCredential(w http.ResponseWriter, r *http.Request) {
+	var req VerifyCredentialRequest
+	if err := h.decodeRequest(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	token := strings.TrimSpace(req.Token)
+	if token == "" {
+		writeError(w, badRequest("token is required"))
+		return
+	}
+	// Only a bare token is accepted, never a credential file carrying the
+	// key to check it against
+	if token[0] == '{' {
+		writeError(w, badRequest("token must be a credential token"))
+		return
+	}
+
+	fileOpts := veriglob.CredentialFileOptions{
+		Resolver:       h.opts.Resolver,
+		TrustedIssuers: h.opts.TrustedIssuers,
+	}
+	if h.opts.Registry != nil {
+		fileOpts.Status = h.opts.Registry
+	}
+	info, err := veriglob.VerifyCredentialFile([]byte(token), fileOpts)
+	if errors.Is(err, veriglob.ErrRegistryUnavailable) {
+		writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	resp := VerifyCredentialResponse{Valid: err == nil}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	if info != nil {
+		resp.CredentialID = info.ID
+		resp.Type = info.Type
+		resp.Issuer = info.IssuerDID
+		resp.Subject = info.SubjectDID
+		resp.IssuedAt = info.IssuedAt
+		resp.ExpiresAt = info.ExpiresAt
+		resp.Status = info.Status
+		if info.Claims != nil {
+			resp.CredentialSubject = info.Claims.VC.CredentialSubject
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
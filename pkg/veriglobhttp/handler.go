@@ -0,0 +1,138 @@
+// Package veriglobhttp serves credential issuance, verification and
+// revocation over HTTP, for services that would rather call an endpoint than
+// link the library:
+//
+//	POST /credentials               issue a credential
+//	POST /credentials/verify        verify a credential
+//	POST /presentations/verify      verify a presentation and its credentials
+//	POST /revocations               revoke a credential
+//
+// Requests and responses are JSON. The handler does no authentication;
+// mount it behind the service's own, since anyone who can reach
+// /credentials or /revocations can issue or revoke under the issuer's DID.
+package veriglobhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/veriglob/veriglob-core/pkg/veriglob"
+)
+
+// DefaultMaxBodyBytes is the largest request body accepted when
+// Options.MaxBodyBytes is unset
+const DefaultMaxBodyBytes = 1 << 20
+
+// Registry records issued credentials and their revocation status.
+// *veriglob.RevocationRegistry and *veriglob.ShardedRegistry implement it.
+type Registry interface {
+	veriglob.StatusChecker
+	Register(credentialID, issuerDID, subjectDID string) error
+	Revoke(credentialID, reason string) error
+}
+
+// Options configures the handler
+type Options struct {
+	// Keys is the issuer identity; without it POST /credentials returns 501
+	Keys KeyStore
+	// Registry, when set, records issued credentials and is consulted when
+	// verifying; without it POST /revocations returns 501
+	Registry Registry
+	// Resolver resolves issuer and holder DIDs; nil uses the default resolver
+	Resolver *veriglob.Resolver
+	// TrustedIssuers, when set, fails credentials from issuers it does not
+	// trust for the credential's types
+	TrustedIssuers *veriglob.TrustList
+	// Nonces, when set, consumes the nonce of each valid presentation so it
+	// cannot be replayed
+	Nonces veriglob.NonceStore
+	// MaxBodyBytes limits request bodies; zero uses DefaultMaxBodyBytes
+	MaxBodyBytes int64
+}
+
+// handler serves the REST endpoints
+type handler struct {
+	opts Options
+	mux  *http.ServeMux
+}
+
+// NewHandler returns an http.Handler serving the Veriglob endpoints. Mount it
+// at the root, or under a prefix with http.StripPrefix.
+func NewHandler(opts Options) http.Handler {
+	if opts.Resolver == nil {
+		opts.Resolver = veriglob.NewResolver()
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+
+	h := &handler{opts: opts, mux: http.NewServeMux()}
+	h.mux.HandleFunc("POST /credentials", h.issueCredential)
+	h.mux.HandleFunc("POST /credentials/verify", h.verifyCredential)
+	h.mux.HandleFunc("POST /presentations/verify", h.verifyPresentation)
+	h.mux.HandleFunc("POST /revocations", h.revoke)
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// requestError is a client error with its HTTP status
+type requestError struct {
+	status int
+	msg    string
+}
+
+func (e *requestError) Error() string { return e.msg }
+
+// badRequest returns a 400 error
+func badRequest(format string, args ...interface{}) error {
+	return &requestError{status: http.StatusBadRequest, msg: fmt.Sprintf(format, args...)}
+}
+
+// decodeRequest reads a JSON body into v, rejecting other content types,
+// unknown fields, trailing data, and bodies over the size limit
+func (h *handler) decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		if mediaType, _, err := mime.ParseMediaType(ct); err != nil || mediaType != "application/json" {
+			return &requestError{status: http.StatusUnsupportedMediaType, msg: "content type must be application/json"}
+		}
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.opts.MaxBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return &requestError{status: http.StatusRequestEntityTooLarge, msg: "request body too large"}
+		}
+		return badRequest("invalid JSON body: %v", err)
+	}
+	if decoder.Decode(&struct{}{}) != io.EOF {
+		return badRequest("invalid JSON body: unexpected data after the object")
+	}
+	return nil
+}
+
+// writeJSON writes v with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an ErrorResponse, using the status of a requestError
+// and 500 otherwise. Internal errors are not described to the client.
+func writeError(w http.ResponseWriter, err error) {
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		writeJSON(w, reqErr.status, ErrorResponse{Error: reqErr.msg})
+		return
+	}
+	writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "internal error"})
+}
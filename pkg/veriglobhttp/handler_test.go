@@ -0,0 +1,252 @@
+package veriglobhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/veriglob/veriglob-core/pkg/veriglob"
+)
+
+// newTestServer serves a handler issuing as the harness issuer into its registry
+func newTestServer(t *testing.T, h *veriglob.TestHarness, opts Options) *httptest.Server {
+	t.Helper()
+	if opts.Keys == nil {
+		opts.Keys = NewStaticKeyStore(h.Issuer.DID, h.Issuer.PrivateKey)
+	}
+	if opts.Registry == nil {
+		opts.Registry = h.Registry
+	}
+	srv := httptest.NewServer(NewHandler(opts))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// post sends body as JSON and decodes the response into out
+func post(t *testing.T, url string, body interface{}, out interface{}) int {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("POST %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("Decoding response of %s failed: %v", url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestIssueAndVerifyCredential(t *testing.T) {
+	h := veriglob.NewTestHarness()
+	srv := newTestServer(t, h, Options{})
+
+	var issued IssueCredentialResponse
+	status := post(t, srv.URL+"/credentials", IssueCredentialRequest{
+		Type: veriglob.CredentialTypeIdentity,
+		Subject: map[string]interface{}{
+			"id":          h.Holder.DID,
+			"givenName":   "Alice",
+			"familyName":  "Doe",
+			"dateOfBirth": "1990-01-01",
+		},
+	}, &issued)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d", status)
+	}
+	if issued.Issuer != h.Issuer.DID || issued.Subject != h.Holder.DID || issued.CredentialID == "" {
+		t.Errorf("Unexpected issue response: %+v", issued)
+	}
+	if _, err := h.Registry.CheckStatus(issued.CredentialID); err != nil {
+		t.Errorf("Expected the credential to be registered, got %v", err)
+	}
+
+	var verified VerifyCredentialResponse
+	if status := post(t, srv.URL+"/credentials/verify", VerifyCredentialRequest{Token: issued.Token}, &verified); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if !verified.Valid || verified.Issuer != h.Issuer.DID || verified.Status != string(veriglob.StatusActive) {
+		t.Errorf("Expected a valid active credential, got %+v", verified)
+	}
+
+	// Revoking it is reflected in verification
+	var revoked RevokeResponse
+	if status := post(t, srv.URL+"/revocations", RevokeRequest{CredentialID: issued.CredentialID, Reason: "test"}, &revoked); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if revoked.Status != string(veriglob.StatusRevoked) {
+		t.Errorf("Expected status revoked, got %s", revoked.Status)
+	}
+	post(t, srv.URL+"/credentials/verify", VerifyCredentialRequest{Token: issued.Token}, &verified)
+	if verified.Valid || verified.Status != string(veriglob.StatusRevoked) {
+		t.Errorf("Expected a revoked credential to be invalid, got %+v", verified)
+	}
+
+	var errResp ErrorResponse
+	if status := post(t, srv.URL+"/revocations", RevokeRequest{CredentialID: issued.CredentialID}, &errResp); status != http.StatusConflict {
+		t.Errorf("Expected 409 revoking twice, got %d", status)
+	}
+	if status := post(t, srv.URL+"/revocations", RevokeRequest{CredentialID: "urn:uuid:unknown"}, &errResp); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown credential, got %d", status)
+	}
+}
+
+func TestVerifyCredentialInvalid(t *testing.T) {
+	h := veriglob.NewTestHarness()
+	srv := newTestServer(t, h, Options{})
+
+	var verified VerifyCredentialResponse
+	if status := post(t, srv.URL+"/credentials/verify", VerifyCredentialRequest{Token: "v4.public.bogus"}, &verified); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if verified.Valid || verified.Error == "" {
+		t.Errorf("Expected an invalid credential with an error, got %+v", verified)
+	}
+
+	// A credential file would let the caller supply the issuer key
+	file := `{"issuer": {"did": "did:key:z6Mk", "publicKey": "00"}, "token": "v4.public.x"}`
+	var errResp ErrorResponse
+	if status := post(t, srv.URL+"/credentials/verify", VerifyCredentialRequest{Token: file}, &errResp); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a credential file, got %d", status)
+	}
+}
+
+func TestVerifyPresentation(t *testing.T) {
+	h := veriglob.NewTestHarness()
+	nonces := veriglob.NewMemoryNonceStore()
+	srv := newTestServer(t, h, Options{Nonces: nonces})
+
+	credential, err := h.IssueIdentity(veriglob.IdentitySubject{GivenName: "Alice", FamilyName: "Doe", DateOfBirth: "1990-01-01"})
+	if err != nil {
+		t.Fatalf("IssueIdentity failed: %v", err)
+	}
+	token, nonce, err := h.Present(credential)
+	if err != nil {
+		t.Fatalf("Present failed: %v", err)
+	}
+	req := VerifyPresentationRequest{Presentation: token, Audience: h.Verifier.DID, Nonce: nonce}
+
+	var resp VerifyPresentationResponse
+	if status := post(t, srv.URL+"/presentations/verify", req, &resp); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if !resp.Valid || resp.Holder != h.Holder.DID || len(resp.Credentials) != 1 || !resp.Credentials[0].Valid {
+		t.Fatalf("Expected a valid presentation, got %+v", resp)
+	}
+	if resp.Credentials[0].Type != veriglob.CredentialTypeIdentity {
+		t.Errorf("Expected type %s, got %s", veriglob.CredentialTypeIdentity, resp.Credentials[0].Type)
+	}
+
+	// The nonce is used up
+	post(t, srv.URL+"/presentations/verify", req, &resp)
+	if resp.Valid || !strings.Contains(resp.Error, veriglob.ErrNonceReplayed.Error()) {
+		t.Errorf("Expected a replayed presentation to be rejected, got %+v", resp)
+	}
+
+	// A presentation made for another verifier is not accepted
+	token, nonce, _ = h.Present(credential)
+	post(t, srv.URL+"/presentations/verify", VerifyPresentationRequest{Presentation: token, Audience: "did:key:zOther", Nonce: nonce}, &resp)
+	if resp.Valid || resp.Error != veriglob.ErrAudienceMismatch.Error() {
+		t.Errorf("Expected an audience mismatch, got %+v", resp)
+	}
+}
+
+func TestRequestValidation(t *testing.T) {
+	h := veriglob.NewTestHarness()
+	srv := newTestServer(t, h, Options{MaxBodyBytes: 1024})
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		ctype  string
+		status int
+	}{
+		{"malformed JSON", "/credentials", `{"type":`, "application/json", http.StatusBadRequest},
+		{"unknown field", "/credentials/verify", `{"token": "x", "extra": 1}`, "application/json", http.StatusBadRequest},
+		{"trailing data", "/credentials/verify", `{"token": "x"} {}`, "application/json", http.StatusBadRequest},
+		{"wrong content type", "/credentials/verify", `{"token": "x"}`, "text/plain", http.StatusUnsupportedMediaType},
+		{"too large", "/credentials/verify", `{"token": "` + strings.Repeat("a", 2048) + `"}`, "application/json", http.StatusRequestEntityTooLarge},
+		{"missing type", "/credentials", `{"subject": {"id": "did:key:z6MkTest"}}`, "application/json", http.StatusBadRequest},
+		{"missing subject id", "/credentials", `{"type": "IdentityCredential", "subject": {}}`, "application/json", http.StatusBadRequest},
+		{"invalid subject id", "/credentials", `{"type": "IdentityCredential", "subject": {"id": "not a did"}}`, "application/json", http.StatusBadRequest},
+		{"unknown type", "/credentials", `{"type": "NoSuchCredential", "subject": {"id": "did:key:z6MkTest"}}`, "application/json", http.StatusBadRequest},
+		{"missing required field", "/credentials", `{"type": "IdentityCredential", "subject": {"id": "did:key:z6MkTest"}}`, "application/json", http.StatusBadRequest},
+		{"missing token", "/credentials/verify", `{}`, "application/json", http.StatusBadRequest},
+		{"missing nonce", "/presentations/verify", `{"presentation": "v4.public.x", "audience": "did:key:z6MkVerifier"}`, "application/json", http.StatusBadRequest},
+		{"missing credential ID", "/revocations", `{"reason": "test"}`, "application/json", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(srv.URL+tt.path, tt.ctype, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("POST failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected %d, got %d", tt.status, resp.StatusCode)
+			}
+			var errResp ErrorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil || errResp.Error == "" {
+				t.Errorf("Expected a JSON error body, got %v", err)
+			}
+		})
+	}
+
+	// Only POST is routed
+	resp, err := http.Get(srv.URL + "/credentials")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", resp.StatusCode)
+	}
+}
+
+func TestUnconfiguredEndpoints(t *testing.T) {
+	srv := httptest.NewServer(NewHandler(Options{}))
+	defer srv.Close()
+
+	var errResp ErrorResponse
+	if status := post(t, srv.URL+"/credentials", IssueCredentialRequest{}, &errResp); status != http.StatusNotImplemented {
+		t.Errorf("Expected 501 without a key store, got %d", status)
+	}
+	if status := post(t, srv.URL+"/revocations", RevokeRequest{CredentialID: "urn:uuid:x"}, &errResp); status != http.StatusNotImplemented {
+		t.Errorf("Expected 501 without a registry, got %d", status)
+	}
+}
+
+func TestWalletKeyStore(t *testing.T) {
+	wallet, err := veriglob.CreateWalletWithStore(veriglob.NewMemoryStore(), "passphrase", veriglob.WalletOptions{})
+	if err != nil {
+		t.Fatalf("CreateWalletWithStore failed: %v", err)
+	}
+	store := NewWalletKeyStore(wallet)
+	if _, _, err := store.IssuerKey(t.Context()); err == nil {
+		t.Error("Expected an error for a wallet without keys")
+	}
+
+	h := veriglob.NewTestHarness()
+	wallet.SetKeys(h.Issuer.PublicKey, h.Issuer.PrivateKey, h.Issuer.DID)
+	issuerDID, key, err := store.IssuerKey(t.Context())
+	if err != nil || issuerDID != h.Issuer.DID || !key.Equal(h.Issuer.PrivateKey) {
+		t.Errorf("Expected the wallet identity, got %s (%v)", issuerDID, err)
+	}
+
+	newDID, err := store.RotateKey()
+	if err != nil {
+		t.Fatalf("RotateKey failed: %v", err)
+	}
+	if issuerDID, _, _ := store.IssuerKey(t.Context()); issuerDID != newDID || newDID == h.Issuer.DID {
+		t.Errorf("Expected the rotated DID %s, got %s", newDID, issuerDID)
+	}
+}
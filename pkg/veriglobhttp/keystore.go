@@ -0,0 +1,75 @@
+package veriglobhttp
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"sync"
+
+	"github.com/veriglob/veriglob-core/pkg/veriglob"
+)
+
+var (
+	ErrNoIssuerKey = errors.New("no issuer key configured")
+)
+
+// KeyStore provides the identity credentials are issued under. It is called
+// for every issuance, so an implementation can rotate keys or fetch them from
+// a KMS without restarting the handler.
+type KeyStore interface {
+	IssuerKey(ctx context.Context) (issuerDID string, privateKey ed25519.PrivateKey, err error)
+}
+
+// StaticKeyStore issues under one fixed DID and key
+type StaticKeyStore struct {
+	DID        string
+	PrivateKey ed25519.PrivateKey
+}
+
+// NewStaticKeyStore creates a key store for a fixed issuer DID and key
+func NewStaticKeyStore(issuerDID string, privateKey ed25519.PrivateKey) *StaticKeyStore {
+	return &StaticKeyStore{DID: issuerDID, PrivateKey: privateKey}
+}
+
+// IssuerKey returns the configured DID and key
+func (s *StaticKeyStore) IssuerKey(ctx context.Context) (string, ed25519.PrivateKey, error) {
+	if s.DID == "" || len(s.PrivateKey) != ed25519.PrivateKeySize {
+		return "", nil, ErrNoIssuerKey
+	}
+	return s.DID, s.PrivateKey, nil
+}
+
+// WalletKeyStore issues under the default identity of an open wallet, so
+// rotating the wallet's key takes effect on the next issuance. The wallet
+// must not be changed by anything other than the key store's own methods
+// while the handler is serving.
+type WalletKeyStore struct {
+	mu     sync.Mutex
+	wallet *veriglob.Wallet
+}
+
+// NewWalletKeyStore creates a key store backed by an open wallet
+func NewWalletKeyStore(wallet *veriglob.Wallet) *WalletKeyStore {
+	return &WalletKeyStore{wallet: wallet}
+}
+
+// IssuerKey returns the wallet's default DID and key. A locked wallet
+// returns veriglob.ErrWalletLocked.
+func (s *WalletKeyStore) IssuerKey(ctx context.Context) (string, ed25519.PrivateKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, priv, err := s.wallet.GetKeys()
+	if err != nil {
+		return "", nil, err
+	}
+	return s.wallet.GetDID(), priv, nil
+}
+
+// RotateKey replaces the wallet's signing key, keeping the old one for
+// verifying credentials it issued, and returns the new issuer DID
+func (s *WalletKeyStore) RotateKey() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.wallet.RotateKey()
+}
@@ -0,0 +1,95 @@
+package veriglobhttp
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/veriglob/veriglob-core/pkg/veriglob"
+)
+
+// verifyPresentation handles POST /presentations/verify: the holder key is
+// resolved from the presentation's holder DID, every embedded credential is
+// verified and must belong to the holder, and the nonce is consumed when
+// Options.Nonces is set
+func (h *handler) verifyPresentation(w http.ResponseWriter, r *http.Request) {
+	var req VerifyPresentationRequest
+	if err := h.decodeRequest(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	token := strings.TrimSpace(req.Presentation)
+	switch {
+	case token == "":
+		writeError(w, badRequest("presentation is required"))
+		return
+	case req.Audience == "":
+		writeError(w, badRequest("audience is required"))
+		return
+	case req.Nonce == "":
+		writeError(w, badRequest("nonce is required"))
+		return
+	}
+
+	invalid := func(err error) {
+		writeJSON(w, http.StatusOK, VerifyPresentationResponse{Error: err.Error()})
+	}
+
+	holderDID, err := veriglob.UnverifiedIssuer(token)
+	if err != nil {
+		invalid(veriglob.ErrNotAPresentation)
+		return
+	}
+	holderKey, err := h.opts.Resolver.Resolve(holderDID)
+	if err != nil {
+		invalid(err)
+		return
+	}
+
+	var status veriglob.StatusChecker
+	if h.opts.Registry != nil {
+		status = h.opts.Registry
+	}
+	result, err := veriglob.VerifyPresentationFull(token, holderKey, h.opts.Resolver, status, veriglob.PresentationVerifyOptions{
+		ExpectedAudience: req.Audience,
+		ExpectedNonce:    req.Nonce,
+		ExpectedDomain:   req.Domain,
+		Credentials: veriglob.CredentialCheckOptions{
+			RequireHolderBinding: true,
+			TrustedIssuers:       h.opts.TrustedIssuers,
+		},
+	})
+	if err != nil {
+		invalid(err)
+		return
+	}
+	for _, c := range result.Credentials {
+		if errors.Is(c.Err, veriglob.ErrRegistryUnavailable) {
+			writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{Error: c.Err.Error()})
+			return
+		}
+	}
+
+	resp := VerifyPresentationResponse{
+		Valid:     result.Valid(),
+		Holder:    result.Holder,
+		ExpiresAt: result.ExpiresAt,
+	}
+	for _, c := range result.Credentials {
+		resp.Credentials = append(resp.Credentials, credentialResponse(c))
+	}
+	switch {
+	case result.Err != nil:
+		resp.Valid = false
+		resp.Error = result.Err.Error()
+	case !resp.Valid:
+		resp.Error = "presentation contains an invalid credential"
+	case h.opts.Nonces != nil:
+		// Only a presentation that verified uses up its nonce
+		if err := h.opts.Nonces.Consume(result.Claims.Nonce, result.Claims.NonceExpiry()); err != nil {
+			resp.Valid = false
+			resp.Error = err.Error()
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
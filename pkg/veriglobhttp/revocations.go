@@ -0,0 +1,45 @@
+package veriglobhttp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/veriglob/veriglob-core/pkg/veriglob"
+)
+
+// revoke handles POST /revocations: it revokes a credential in the registry
+func (h *handler) revoke(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Registry == nil {
+		writeJSON(w, http.StatusNotImplemented, ErrorResponse{Error: "revocation is not configured"})
+		return
+	}
+
+	var req RevokeRequest
+	if err := h.decodeRequest(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	if req.CredentialID == "" {
+		writeError(w, badRequest("credentialId is required"))
+		return
+	}
+
+	err := h.opts.Registry.Revoke(req.CredentialID, req.Reason)
+	switch {
+	case err == nil:
+	case errors.Is(err, veriglob.ErrCredentialNotFound):
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	case errors.Is(err, veriglob.ErrAlreadyRevoked):
+		writeJSON(w, http.StatusConflict, ErrorResponse{Error: err.Error()})
+		return
+	default:
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, RevokeResponse{
+		CredentialID: req.CredentialID,
+		Status:       string(veriglob.StatusRevoked),
+	})
+}
@@ -0,0 +1,112 @@
+package veriglobhttp
+
+import (
+	"time"
+
+	"github.com/veriglob/veriglob-core/pkg/veriglob"
+)
+
+// IssueCredentialRequest is the body of POST /credentials
+type IssueCredentialRequest struct {
+	// Type is a registered credential type, e.g. IdentityCredential
+	Type string `json:"type"`
+	// Subject holds the subject claims of that type; its "id" is the
+	// subject DID
+	Subject map[string]interface{} `json:"subject"`
+	// ExpiresAt, when set, replaces the default validity period
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// IssueCredentialResponse is the 201 response of POST /credentials
+type IssueCredentialResponse struct {
+	CredentialID string `json:"credentialId"`
+	Type         string `json:"type"`
+	Issuer       string `json:"issuer"`
+	Subject      string `json:"subject"`
+	Token        string `json:"token"`
+}
+
+// VerifyCredentialRequest is the body of POST /credentials/verify
+type VerifyCredentialRequest struct {
+	Token string `json:"token"`
+}
+
+// VerifyCredentialResponse reports whether a credential verified. The
+// credential fields are set when its signature verified, even if it has
+// since been revoked.
+type VerifyCredentialResponse struct {
+	Valid        bool      `json:"valid"`
+	Error        string    `json:"error,omitempty"`
+	CredentialID string    `json:"credentialId,omitempty"`
+	Type         string    `json:"type,omitempty"`
+	Issuer       string    `json:"issuer,omitempty"`
+	Subject      string    `json:"subject,omitempty"`
+	IssuedAt     time.Time `json:"issuedAt,omitzero"`
+	ExpiresAt    time.Time `json:"expiresAt,omitzero"`
+	// Expired is set on a presentation's credential whose signature verified
+	// but which has expired
+	Expired bool `json:"expired,omitempty"`
+	// Status is the registry status, empty if the credential is not tracked
+	Status            string      `json:"status,omitempty"`
+	CredentialSubject interface{} `json:"credentialSubject,omitempty"`
+}
+
+// VerifyPresentationRequest is the body of POST /presentations/verify. The
+// audience and nonce are the values the verifier gave the holder and are
+// required, so a presentation made for someone else is not accepted.
+type VerifyPresentationRequest struct {
+	Presentation string `json:"presentation"`
+	Audience     string `json:"audience"`
+	Nonce        string `json:"nonce"`
+	// Domain, when set, must match the presentation's domain claim
+	Domain string `json:"domain,omitempty"`
+}
+
+// VerifyPresentationResponse reports whether a presentation and every
+// credential in it verified
+type VerifyPresentationResponse struct {
+	Valid       bool                       `json:"valid"`
+	Error       string                     `json:"error,omitempty"`
+	Holder      string                     `json:"holder,omitempty"`
+	ExpiresAt   time.Time                  `json:"expiresAt,omitzero"`
+	Credentials []VerifyCredentialResponse `json:"credentials,omitempty"`
+}
+
+// RevokeRequest is the body of POST /revocations
+type RevokeRequest struct {
+	CredentialID string `json:"credentialId"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// RevokeResponse is the response of POST /revocations
+type RevokeResponse struct {
+	CredentialID string `json:"credentialId"`
+	Status       string `json:"status"`
+}
+
+// ErrorResponse is the body of every 4xx and 5xx response
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// credentialResponse converts one embedded credential result
+func credentialResponse(r veriglob.CredentialResult) VerifyCredentialResponse {
+	resp := VerifyCredentialResponse{
+		Valid:        r.Valid,
+		CredentialID: r.CredentialID,
+		Issuer:       r.Issuer,
+		Subject:      r.Subject,
+		ExpiresAt:    r.ExpiresAt,
+		Expired:      r.Expired,
+		Status:       string(r.Status),
+	}
+	if r.Err != nil {
+		resp.Error = r.Err.Error()
+	}
+	if r.Claims != nil {
+		resp.Type = r.Claims.CredentialType()
+		resp.IssuedAt = r.Claims.IssuedAt
+		resp.CredentialSubject = r.Claims.VC.CredentialSubject
+	}
+	return resp
+}